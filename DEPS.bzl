@@ -6569,10 +6569,10 @@ def go_deps():
         name = "com_github_pkg_sftp",
         build_file_proto_mode = "disable_global",
         importpath = "github.com/pkg/sftp",
-        sha256 = "4e30f0455865434be7b83d4010ab97667217dafd0017caa651faafa2cc6aed64",
-        strip_prefix = "github.com/pkg/sftp@v1.10.1",
+        sha256 = "16dd30372f347f270d4afa26e34cdd666c584247c683659e6e54709027d9c28a",
+        strip_prefix = "github.com/pkg/sftp@v1.13.5",
         urls = [
-            "https://storage.googleapis.com/cockroach-godeps/gomod/github.com/pkg/sftp/com_github_pkg_sftp-v1.10.1.zip",
+            "https://storage.googleapis.com/cockroach-godeps/gomod/github.com/pkg/sftp/com_github_pkg_sftp-v1.13.5.zip",
         ],
     )
     go_repository(
@@ -137,6 +137,11 @@ func doAlterBackupPlan(
 		return errors.New("invalid base backup specified")
 	}
 
+	kmsURIs := append(append([]string(nil), newKms...), oldKms...)
+	if err := checkKMSURIPrivileges(ctx, p, kmsURIs, "ALTER BACKUP"); err != nil {
+		return err
+	}
+
 	baseStore, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, backup, p.User())
 	if err != nil {
 		return errors.Wrapf(err, "failed to open backup storage location")
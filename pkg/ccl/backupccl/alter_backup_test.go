@@ -9,13 +9,17 @@
 package backupccl
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/stretchr/testify/require"
@@ -210,3 +214,43 @@ func TestAlterBackupIncremental(t *testing.T) {
 	sqlDB.Exec(t, query)
 	sqlDB.ExecRowsAffected(t, 2, "SELECT * FROM bank")
 }
+
+// TestAlterBackupRestoreFileKMS tests that key rotation through ALTER BACKUP
+// works with the file-based KMS, which, unlike the cloud KMSes, can be
+// exercised without any credentials.
+func TestAlterBackupRestoreFileKMS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, dirCleanup := testutils.TempDir(t)
+	defer dirCleanup()
+	writeKey := func(name string, b byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, bytes.Repeat([]byte{b}, 32), 0600))
+		return fmt.Sprintf("'file://%s'", path)
+	}
+	oldURI := writeKey("old.key", 'o')
+	newURI := writeKey("new.key", 'n')
+
+	const userfile = "'userfile:///a'"
+	const numAccounts = 1
+
+	_, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, "INSERT INTO bank (id, balance, payload) VALUES (2, 500, 'test1'), (3, 200, 'test2')")
+	sqlDB.Exec(t, fmt.Sprintf("BACKUP TABLE bank INTO %s WITH KMS = %s", userfile, oldURI))
+
+	// The new key cannot open the backup until it is added.
+	sqlDB.ExpectErr(t, "one of the provided URIs was not used when encrypting the base BACKUP",
+		fmt.Sprintf("SHOW BACKUP LATEST IN %s WITH KMS = %s", userfile, newURI))
+
+	sqlDB.Exec(t, fmt.Sprintf("ALTER BACKUP LATEST IN %s ADD NEW_KMS = %s WITH OLD_KMS = %s",
+		userfile, newURI, oldURI))
+
+	for _, uri := range []string{newURI, oldURI} {
+		sqlDB.Exec(t, "DROP TABLE bank")
+		sqlDB.Exec(t, fmt.Sprintf("RESTORE TABLE bank FROM LATEST IN %s WITH KMS = %s", userfile, uri))
+		sqlDB.ExecRowsAffected(t, 3, "SELECT * FROM bank")
+	}
+}
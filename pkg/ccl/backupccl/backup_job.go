@@ -774,6 +774,14 @@ func (b *backupResumer) readManifestOnResume(
 
 func (b *backupResumer) maybeNotifyScheduledJobCompletion(
 	ctx context.Context, jobStatus jobs.Status, exec *sql.ExecutorConfig,
+) error {
+	return maybeNotifyScheduledJobCompletion(ctx, b.job, jobStatus, exec)
+}
+
+// maybeNotifyScheduledJobCompletion notifies the schedule that created job, if
+// any, that the job has terminated with jobStatus.
+func maybeNotifyScheduledJobCompletion(
+	ctx context.Context, job *jobs.Job, jobStatus jobs.Status, exec *sql.ExecutorConfig,
 ) error {
 	env := scheduledjobs.ProdJobSchedulerEnv
 	if knobs, ok := exec.DistSQLSrv.TestingKnobs.JobsTestingKnobs.(*jobs.TestingKnobs); ok {
@@ -783,7 +791,7 @@ func (b *backupResumer) maybeNotifyScheduledJobCompletion(
 	}
 
 	err := exec.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
-		// We cannot rely on b.job containing created_by_id because on job
+		// We cannot rely on job containing created_by_id because on job
 		// resumption the registry does not populate the resumer's CreatedByInfo.
 		datums, err := exec.InternalExecutor.QueryRowEx(
 			ctx,
@@ -793,20 +801,20 @@ func (b *backupResumer) maybeNotifyScheduledJobCompletion(
 			fmt.Sprintf(
 				"SELECT created_by_id FROM %s WHERE id=$1 AND created_by_type=$2",
 				env.SystemJobsTableName()),
-			b.job.ID(), jobs.CreatedByScheduledJobs)
+			job.ID(), jobs.CreatedByScheduledJobs)
 		if err != nil {
 			return errors.Wrap(err, "schedule info lookup")
 		}
 		if datums == nil {
-			// Not a scheduled backup.
+			// Not a scheduled job.
 			return nil
 		}
 
 		scheduleID := int64(tree.MustBeDInt(datums[0]))
 		if err := jobs.NotifyJobTermination(
-			ctx, env, b.job.ID(), jobStatus, b.job.Details(), scheduleID, exec.InternalExecutor, txn); err != nil {
+			ctx, env, job.ID(), jobStatus, job.Details(), scheduleID, exec.InternalExecutor, txn); err != nil {
 			return errors.Wrapf(err,
-				"failed to notify schedule %d of completion of job %d", scheduleID, b.job.ID())
+				"failed to notify schedule %d of completion of job %d", scheduleID, job.ID())
 		}
 		return nil
 	})
@@ -303,6 +303,7 @@ func checkPrivilegesForBackup(
 	p sql.PlanHookState,
 	targetDescs []catalog.Descriptor,
 	to []string,
+	kmsURIs []string,
 ) error {
 	hasAdmin, err := p.HasAdminRole(ctx)
 	if err != nil {
@@ -362,7 +363,37 @@ func checkPrivilegesForBackup(
 				conf.Provider.String())
 		}
 	}
+	return checkKMSURIPrivileges(ctx, p, kmsURIs, "BACKUP")
+}
 
+// checkKMSURIPrivileges checks that the user is allowed to use the given KMS
+// URIs in the named statement. KMS URIs which access the KMS implicitly as the
+// node, such as through its machine account or its filesystem, are restricted
+// to admin users, the same way as external storage URIs are.
+func checkKMSURIPrivileges(
+	ctx context.Context, p sql.PlanHookState, kmsURIs []string, stmt string,
+) error {
+	for _, uri := range kmsURIs {
+		explicit, err := cloud.KMSAccessIsWithExplicitAuth(uri)
+		if err != nil {
+			return err
+		}
+		if explicit {
+			continue
+		}
+		if p.ExecCfg().ExternalIODirConfig.EnableNonAdminImplicitAndArbitraryOutbound {
+			continue
+		}
+		hasAdmin, err := p.HasAdminRole(ctx)
+		if err != nil {
+			return err
+		}
+		if !hasAdmin {
+			return pgerror.Newf(
+				pgcode.InsufficientPrivilege,
+				"only users with the admin role are allowed to %s with the specified KMS URI", stmt)
+		}
+	}
 	return nil
 }
 
@@ -560,7 +591,7 @@ func backupPlanHook(
 		}
 
 		// Check BACKUP privileges.
-		err = checkPrivilegesForBackup(ctx, backupStmt, p, targetDescs, to)
+		err = checkPrivilegesForBackup(ctx, backupStmt, p, targetDescs, to, encryptionParams.RawKmsUris)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,846 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// historicalReadMounts lists the backups that are mounted as a source for
+// reads below the GC threshold.
+var historicalReadMounts = func() *settings.StringSetting {
+	s := settings.RegisterStringSetting(
+		settings.SystemOnly,
+		"bulkio.backup.historical_reads.mounts",
+		"comma-separated URIs of full backups (e.g. 'nodelocal://1/backups/2022/06/01-120000.00') "+
+			"whose chains of incremental backups are used to serve AS OF SYSTEM TIME reads "+
+			"below the GC threshold; only unencrypted backups are supported",
+		"",
+	)
+	// The URIs may contain credentials for the external storage.
+	s.SetReportable(false)
+	return s
+}()
+
+// historicalReadMaxBatchMemory bounds the memory used to evaluate a single
+// batch against a mounted backup.
+var historicalReadMaxBatchMemory = settings.RegisterByteSizeSetting(
+	settings.SystemOnly,
+	"bulkio.backup.historical_reads.max_batch_memory",
+	"maximum memory used to evaluate a single batch of reads against a mounted backup",
+	64<<20,
+	settings.PositiveInt,
+)
+
+// historicalReadMountRefreshInterval is how often the chain of a mounted
+// backup is re-resolved to discover incremental backups appended to it.
+const historicalReadMountRefreshInterval = 10 * time.Minute
+
+// backupReadSource is a kvcoord.HistoricalReadSource that serves reads from
+// the backups listed in historicalReadMounts.
+//
+// Batches are evaluated against iterators over the files of the mounted backup
+// that overlap the batch's spans. The files are read directly from external
+// storage as the user on whose behalf the batch is sent, and nothing is
+// downloaded or cached beyond the batch. The memory used to evaluate a batch is
+// accounted for, and bounded by historicalReadMaxBatchMemory. Reads of the
+// catalog are served from the descriptors in the manifests of the backup, see
+// catalogKVs.
+type backupReadSource struct {
+	execCfg *sql.ExecutorConfig
+
+	mu struct {
+		syncutil.Mutex
+		// setting is the value of historicalReadMounts that mounts were resolved
+		// from, and resolved is when that happened.
+		setting  string
+		resolved time.Time
+		mounts   []*mountedBackup
+		closed   bool
+	}
+}
+
+var _ kvcoord.HistoricalReadSource = (*backupReadSource)(nil)
+
+func newBackupReadSource(execCfg *sql.ExecutorConfig) *backupReadSource {
+	s := &backupReadSource{execCfg: execCfg}
+	execCfg.DistSQLSrv.Stopper.AddCloser(stop.CloserFn(s.close))
+	return s
+}
+
+// mountedBackup is a resolved chain of backups mounted for historical reads.
+type mountedBackup struct {
+	uri          string
+	defaultURIs  []string
+	manifests    []backuppb.BackupManifest
+	localityInfo []jobspb.RestoreDetails_BackupLocalityInfo
+	// mem accounts for the manifests for as long as the backup is mounted.
+	mem mon.BoundAccount
+}
+
+// Send implements the kvcoord.HistoricalReadSource interface.
+func (s *backupReadSource) Send(
+	ctx context.Context, ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, error) {
+	user, ok := kvcoord.HistoricalReadUserFromContext(ctx)
+	if !ok {
+		// Only batches sent on behalf of a SQL user are served, so that external
+		// storage is never accessed with the privileges of the node.
+		return nil, nil
+	}
+	setting := historicalReadMounts.Get(&s.execCfg.Settings.SV)
+	if setting == "" {
+		return nil, nil
+	}
+	readTS := ba.Timestamp
+	if ba.Txn != nil {
+		readTS = ba.Txn.ReadTimestamp
+	}
+	spans := make([]roachpb.Span, 0, len(ba.Requests))
+	for _, ru := range ba.Requests {
+		spans = append(spans, ru.GetInner().Header().Span())
+	}
+	catalog := isCatalogRead(s.execCfg.Codec, spans)
+	if !canServeFromBackup(&ba, catalog) {
+		return nil, nil
+	}
+
+	// The user must be allowed to access the mounted backups before they are
+	// resolved, and then any other storage their chains refer to.
+	access := storageAccessChecker{execCfg: s.execCfg, user: user}
+	if err := access.check(ctx, splitMountURIs(setting)); err != nil {
+		return nil, err
+	}
+	mounts, err := s.getMounts(ctx, setting, user)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mounts {
+		if catalog {
+			// The catalog is served from the manifests of the chain, so no
+			// storage beyond the mounted backups themselves is accessed.
+			layers, ok := m.layersCovering(readTS, nil /* spans */)
+			if !ok {
+				continue
+			}
+			kvs, err := catalogKVs(s.execCfg.Codec, &m.manifests[layers-1])
+			if err != nil {
+				return nil, err
+			}
+			if !recordsKeys(kvs, spans) {
+				continue
+			}
+			log.VEventf(ctx, 2, "serving catalog batch at %s from backup %s",
+				readTS, backuputils.RedactURIForErrorMessage(m.uri))
+			return evaluateCatalogBatch(ctx, s.execCfg, ba, readTS, kvs)
+		}
+		layers, ok := m.layersCovering(readTS, spans)
+		if !ok {
+			continue
+		}
+		if err := access.check(ctx, m.storageURIs(layers)); err != nil {
+			return nil, err
+		}
+		log.VEventf(ctx, 2, "serving batch at %s from backup %s",
+			readTS, backuputils.RedactURIForErrorMessage(m.uri))
+		return m.evaluate(ctx, s.execCfg, user, ba, readTS, spans, layers)
+	}
+	return nil, nil
+}
+
+// storageAccessChecker checks that a user is allowed to read from external
+// storage URIs, in the same way that BACKUP and RESTORE check the URIs passed
+// to them: URIs which access the storage implicitly, with the privileges of the
+// node, are restricted to users with the admin role.
+type storageAccessChecker struct {
+	execCfg *sql.ExecutorConfig
+	user    username.SQLUsername
+	// checkedAdmin is set once hasAdmin has been looked up.
+	checkedAdmin, hasAdmin bool
+}
+
+func (c *storageAccessChecker) check(ctx context.Context, uris []string) error {
+	if c.execCfg.ExternalIODirConfig.EnableNonAdminImplicitAndArbitraryOutbound {
+		return nil
+	}
+	for _, uri := range uris {
+		conf, err := cloud.ExternalStorageConfFromURI(uri, c.user)
+		if err != nil {
+			return err
+		}
+		if conf.AccessIsWithExplicitAuth() {
+			continue
+		}
+		if !c.checkedAdmin {
+			if c.hasAdmin, err = c.userHasAdminRole(ctx); err != nil {
+				return err
+			}
+			c.checkedAdmin = true
+		}
+		if !c.hasAdmin {
+			return pgerror.Newf(
+				pgcode.InsufficientPrivilege,
+				"only users with the admin role are allowed to read from backups mounted at the specified %s URI",
+				conf.Provider.String())
+		}
+	}
+	return nil
+}
+
+func (c *storageAccessChecker) userHasAdminRole(ctx context.Context) (bool, error) {
+	if c.user.IsRootUser() || c.user.IsNodeUser() {
+		return true, nil
+	}
+	row, err := c.execCfg.InternalExecutor.QueryRowEx(
+		ctx, "backup-read-check-is-admin", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: c.user},
+		"SELECT crdb_internal.is_admin()")
+	if err != nil {
+		return false, err
+	}
+	if row == nil {
+		return false, errors.AssertionFailedf("expected 1 row, got 0")
+	}
+	isAdmin, ok := tree.AsDBool(row[0])
+	if !ok {
+		return false, errors.AssertionFailedf("expected bool, got %T", row[0])
+	}
+	return bool(isAdmin), nil
+}
+
+func splitMountURIs(setting string) []string {
+	var uris []string
+	for _, uri := range strings.Split(setting, ",") {
+		if uri = strings.TrimSpace(uri); uri != "" {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// canServeFromBackup returns whether a batch can be evaluated against a mounted
+// backup. Reads of the catalog are limited to point reads, and to the exports
+// with which the lease manager reads the history of a descriptor.
+func canServeFromBackup(ba *roachpb.BatchRequest, catalog bool) bool {
+	for _, ru := range ba.Requests {
+		switch t := ru.GetInner().(type) {
+		case *roachpb.GetRequest:
+			if t.KeyLocking != lock.None {
+				return false
+			}
+		case *roachpb.ScanRequest:
+			if catalog || t.KeyLocking != lock.None {
+				return false
+			}
+		case *roachpb.ReverseScanRequest:
+			if catalog || t.KeyLocking != lock.None {
+				return false
+			}
+		case *roachpb.ExportRequest:
+			if !catalog || len(ba.Requests) > 1 ||
+				t.MVCCFilter != roachpb.MVCCFilter_All || !t.ReturnSST {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// getMounts returns the backups mounted through the given value of
+// historicalReadMounts, resolving their chains as the given user if the setting
+// changed or the last resolution is older than
+// historicalReadMountRefreshInterval.
+func (s *backupReadSource) getMounts(
+	ctx context.Context, setting string, user username.SQLUsername,
+) ([]*mountedBackup, error) {
+	s.mu.Lock()
+	if setting == s.mu.setting &&
+		timeutil.Since(s.mu.resolved) < historicalReadMountRefreshInterval {
+		mounts := s.mu.mounts
+		s.mu.Unlock()
+		return mounts, nil
+	}
+	s.mu.Unlock()
+
+	// Resolving the chains reads their manifests from external storage, so it
+	// is done without holding the lock. Batches which find the mounts stale at
+	// the same time resolve them concurrently, and the last resolution wins.
+	var mounts []*mountedBackup
+	for _, uri := range splitMountURIs(setting) {
+		m, err := s.resolveMount(ctx, uri, user)
+		if err != nil {
+			closeMounts(ctx, mounts)
+			return nil, errors.Wrapf(err, "mounting backup %s", backuputils.RedactURIForErrorMessage(uri))
+		}
+		mounts = append(mounts, m)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.closed {
+		closeMounts(ctx, mounts)
+		return nil, errors.New("backup read source closed")
+	}
+	closeMounts(ctx, s.mu.mounts)
+	s.mu.setting = setting
+	s.mu.resolved = timeutil.Now()
+	s.mu.mounts = mounts
+	return mounts, nil
+}
+
+func closeMounts(ctx context.Context, mounts []*mountedBackup) {
+	for _, m := range mounts {
+		m.mem.Close(ctx)
+	}
+}
+
+// resolveMount resolves the chain of backups whose full backup is at uri.
+func (s *backupReadSource) resolveMount(
+	ctx context.Context, uri string, user username.SQLUsername,
+) (*mountedBackup, error) {
+	mkStore := s.execCfg.DistSQLSrv.ExternalStorageFromURI
+	baseStore, err := mkStore(ctx, uri, user)
+	if err != nil {
+		return nil, errors.Wrap(err, "make storage")
+	}
+	defer baseStore.Close()
+
+	collection, subdir := backupdest.CollectionAndSubdir(uri, "")
+	incDirs, err := backupdest.ResolveIncrementalsBackupLocation(
+		ctx, user, s.execCfg, nil /* explicitIncrementalCollections */, []string{collection}, subdir,
+	)
+	if err != nil {
+		if !errors.Is(err, cloud.ErrListingUnsupported) {
+			return nil, err
+		}
+		log.Warningf(ctx, "storage sink %s does not support listing, only mounting the base backup",
+			backuputils.RedactURIForErrorMessage(uri))
+	}
+
+	m := &mountedBackup{uri: uri, mem: s.execCfg.RootMemoryMonitor.MakeBoundAccount()}
+	m.defaultURIs, m.manifests, m.localityInfo, _, err = resolveBackupManifests(
+		ctx, &m.mem, []cloud.ExternalStorage{baseStore}, mkStore, []string{uri}, incDirs,
+		hlc.Timestamp{}, nil /* encryption */, user,
+	)
+	if err != nil {
+		m.mem.Close(ctx)
+		return nil, err
+	}
+	return m, nil
+}
+
+// layersCovering returns the number of layers of the mounted chain required to
+// read the given spans at the given timestamp, and false if the chain cannot
+// serve such a read.
+func (m *mountedBackup) layersCovering(readTS hlc.Timestamp, spans []roachpb.Span) (int, bool) {
+	_, manifests, _, err := validateEndTimeAndTruncate(
+		m.defaultURIs, m.manifests, m.localityInfo, readTS,
+	)
+	if err != nil {
+		return 0, false
+	}
+	var covered roachpb.SpanGroup
+	covered.Add(manifests[len(manifests)-1].Spans...)
+	if !covered.Encloses(spans...) {
+		return 0, false
+	}
+	return len(manifests), true
+}
+
+// storageURIs returns the URIs of the storage holding the files of the first
+// layers of the chain.
+func (m *mountedBackup) storageURIs(layers int) []string {
+	uris := append([]string(nil), m.defaultURIs[:layers]...)
+	for layer := 0; layer < layers && layer < len(m.localityInfo); layer++ {
+		for _, uri := range m.localityInfo[layer].URIsByOriginalLocalityKV {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// evaluate evaluates the batch against the files of the first layers of the
+// chain that overlap the spans.
+func (m *mountedBackup) evaluate(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user username.SQLUsername,
+	ba roachpb.BatchRequest,
+	readTS hlc.Timestamp,
+	spans []roachpb.Span,
+	layers int,
+) (*roachpb.BatchResponse, error) {
+	var files []backupReadFile
+	seen := make(map[backupReadFile]struct{})
+	for layer := 0; layer < layers; layer++ {
+		for i := range m.manifests[layer].Files {
+			f := &m.manifests[layer].Files[i]
+			if !overlapsAny(f.Span, spans) {
+				continue
+			}
+			rf := backupReadFile{uri: m.defaultURIs[layer], path: f.Path}
+			if layer < len(m.localityInfo) {
+				if uri, ok := m.localityInfo[layer].URIsByOriginalLocalityKV[f.LocalityKV]; ok {
+					rf.uri = uri
+				}
+			}
+			// Several entries in a manifest may refer to the same file.
+			if _, ok := seen[rf]; ok {
+				continue
+			}
+			seen[rf] = struct{}{}
+			files = append(files, rf)
+		}
+	}
+
+	iter, closeFiles, err := openBackupFiles(ctx, execCfg.DistSQLSrv.ExternalStorageFromURI, user, files)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFiles()
+
+	memMon := startBatchMonitor(ctx, execCfg)
+	defer memMon.Stop(ctx)
+	acc := memMon.MakeBoundAccount()
+	defer acc.Close(ctx)
+	return evaluateHistoricalBatch(ctx, iter, &acc, ba, readTS)
+}
+
+// startBatchMonitor starts the monitor for the memory used to evaluate a batch
+// against a mounted backup. The caller must stop it.
+func startBatchMonitor(ctx context.Context, execCfg *sql.ExecutorConfig) *mon.BytesMonitor {
+	memMon := mon.NewMonitorInheritWithLimit(
+		"backup-historical-read", historicalReadMaxBatchMemory.Get(&execCfg.Settings.SV),
+		execCfg.RootMemoryMonitor,
+	)
+	memMon.Start(ctx, execCfg.RootMemoryMonitor, mon.BoundAccount{})
+	return memMon
+}
+
+// backupReadFile identifies a file of a mounted backup.
+type backupReadFile struct {
+	uri, path string
+}
+
+// openBackupFiles returns an iterator over the contents of the given files,
+// which reads them directly from external storage as the given user. When
+// files contain the same key and timestamp, the later file takes precedence.
+// The returned function closes the files and must be called once the iterator
+// is no longer used.
+func openBackupFiles(
+	ctx context.Context,
+	mkStore cloud.ExternalStorageFromURIFactory,
+	user username.SQLUsername,
+	files []backupReadFile,
+) (storage.SimpleMVCCIterator, func(), error) {
+	stores := make(map[string]cloud.ExternalStorage)
+	var iters []storage.SimpleMVCCIterator
+	closeFiles := func() {
+		for _, iter := range iters {
+			iter.Close()
+		}
+		for _, store := range stores {
+			if err := store.Close(); err != nil {
+				log.Warningf(ctx, "closing external storage: %v", err)
+			}
+		}
+	}
+	for _, f := range files {
+		store, ok := stores[f.uri]
+		if !ok {
+			var err error
+			if store, err = mkStore(ctx, f.uri, user); err != nil {
+				closeFiles()
+				return nil, nil, errors.Wrapf(err, "opening %s", backuputils.RedactURIForErrorMessage(f.uri))
+			}
+			stores[f.uri] = store
+		}
+		iter, err := storageccl.ExternalSSTReader(ctx, store, f.path, nil /* encryption */)
+		if err != nil {
+			closeFiles()
+			return nil, nil, errors.Wrapf(err, "reading %s from %s",
+				f.path, backuputils.RedactURIForErrorMessage(f.uri))
+		}
+		iters = append(iters, iter)
+	}
+	return storage.MakeMultiIterator(iters), closeFiles, nil
+}
+
+func overlapsAny(span roachpb.Span, spans []roachpb.Span) bool {
+	for _, sp := range spans {
+		if sp.EndKey == nil {
+			if span.ContainsKey(sp.Key) {
+				return true
+			}
+		} else if span.Overlaps(sp) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateHistoricalBatch evaluates a batch of Get, Scan and ReverseScan
+// requests at the given timestamp against the data of the iterator. Limits on
+// the batch are applied across its requests in the same way that replicas apply
+// them. The memory used to evaluate the batch is accounted for in acc.
+func evaluateHistoricalBatch(
+	ctx context.Context,
+	iter storage.SimpleMVCCIterator,
+	acc *mon.BoundAccount,
+	ba roachpb.BatchRequest,
+	readTS hlc.Timestamp,
+) (*roachpb.BatchResponse, error) {
+	// NB: the caller owns the iterator, so the ReadAsOfIterator, which would
+	// close it, is never closed.
+	asOf := storage.NewReadAsOfIterator(iter, readTS)
+	br := &roachpb.BatchResponse{}
+	maxKeys, targetBytes := ba.MaxSpanRequestKeys, ba.TargetBytes
+	for _, ru := range ba.Requests {
+		var reply roachpb.Response
+		switch req := ru.GetInner().(type) {
+		case *roachpb.GetRequest:
+			resp := &roachpb.GetResponse{}
+			reply = resp
+			if maxKeys < 0 || targetBytes < 0 {
+				resp.ResumeSpan = &roachpb.Span{Key: req.Key}
+				resp.ResumeReason = roachpb.RESUME_KEY_LIMIT
+				if maxKeys >= 0 {
+					resp.ResumeReason = roachpb.RESUME_BYTE_LIMIT
+				}
+				break
+			}
+			kv, ok, err := getHistorical(asOf, req.Key)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				resp.Value = &kv.Value
+				resp.NumKeys = 1
+				resp.NumBytes = int64(len(kv.Value.RawBytes))
+			}
+
+		case *roachpb.ScanRequest:
+			resp := &roachpb.ScanResponse{}
+			reply = resp
+			res, err := scanHistorical(ctx, asOf, acc, ba.Header, req.Span(), req.ScanFormat,
+				maxKeys, targetBytes, false /* reverse */)
+			if err != nil {
+				return nil, err
+			}
+			resp.Rows, resp.BatchResponses = res.kvs, res.batchResponses
+			res.setResponseHeader(&resp.ResponseHeader)
+
+		case *roachpb.ReverseScanRequest:
+			resp := &roachpb.ReverseScanResponse{}
+			reply = resp
+			res, err := scanHistorical(ctx, asOf, acc, ba.Header, req.Span(), req.ScanFormat,
+				maxKeys, targetBytes, true /* reverse */)
+			if err != nil {
+				return nil, err
+			}
+			resp.Rows, resp.BatchResponses = res.kvs, res.batchResponses
+			res.setResponseHeader(&resp.ResponseHeader)
+
+		default:
+			return nil, errors.AssertionFailedf("unsupported request %s", ru.GetInner().Method())
+		}
+		br.Add(reply)
+
+		// Track the remaining limits as replica evaluation does: exhausting a
+		// limit sets it to -1 so that the remaining requests return only resume
+		// spans.
+		h := reply.Header()
+		if maxKeys != 0 && h.NumKeys > 0 {
+			if h.NumKeys < maxKeys {
+				maxKeys -= h.NumKeys
+			} else {
+				maxKeys = -1
+			}
+		}
+		if targetBytes > 0 {
+			if h.ResumeReason == roachpb.RESUME_BYTE_LIMIT || targetBytes <= h.NumBytes {
+				targetBytes = -1
+			} else {
+				targetBytes -= h.NumBytes
+			}
+		}
+	}
+
+	if ba.Txn != nil {
+		br.Txn = ba.Txn.Clone()
+	}
+	br.Timestamp = readTS
+	return br, nil
+}
+
+// visibleKV decodes the key and value the iterator is positioned on. It returns
+// false if the value is a tombstone.
+func visibleKV(iter storage.SimpleMVCCIterator) (roachpb.KeyValue, bool, error) {
+	key := iter.UnsafeKey()
+	v, err := storage.DecodeMVCCValue(iter.UnsafeValue())
+	if err != nil {
+		return roachpb.KeyValue{}, false, errors.Wrapf(err, "decoding mvcc value: %v", key)
+	}
+	if v.IsTombstone() {
+		return roachpb.KeyValue{}, false, nil
+	}
+	return roachpb.KeyValue{
+		Key: append(roachpb.Key(nil), key.Key...),
+		Value: roachpb.Value{
+			RawBytes:  append([]byte(nil), v.Value.RawBytes...),
+			Timestamp: key.Timestamp,
+		},
+	}, true, nil
+}
+
+func getHistorical(
+	asOf *storage.ReadAsOfIterator, key roachpb.Key,
+) (roachpb.KeyValue, bool, error) {
+	asOf.SeekGE(storage.MVCCKey{Key: key})
+	if ok, err := asOf.Valid(); err != nil || !ok {
+		return roachpb.KeyValue{}, false, err
+	}
+	if !asOf.UnsafeKey().Key.Equal(key) {
+		return roachpb.KeyValue{}, false, nil
+	}
+	return visibleKV(asOf)
+}
+
+// historicalScanResult is the result of a scan of a mounted backup.
+type historicalScanResult struct {
+	// kvs holds the result of a KEY_VALUES scan, and batchResponses that of a
+	// BATCH_RESPONSE scan.
+	kvs             []roachpb.KeyValue
+	batchResponses  [][]byte
+	numKeys         int64
+	numBytes        int64
+	resumeSpan      *roachpb.Span
+	resumeReason    roachpb.ResumeReason
+	resumeNextBytes int64
+}
+
+func (r *historicalScanResult) setResponseHeader(h *roachpb.ResponseHeader) {
+	h.NumKeys = r.numKeys
+	h.NumBytes = r.numBytes
+	if r.resumeSpan != nil {
+		h.ResumeSpan = r.resumeSpan
+		h.ResumeReason = r.resumeReason
+		h.ResumeNextBytes = r.resumeNextBytes
+	}
+}
+
+// historicalKVSize returns the size of a key-value pair in a scan result, as
+// computed by MVCCScan.
+func historicalKVSize(kv roachpb.KeyValue) int64 {
+	const kvLenSize = 8
+	return int64(kvLenSize + storage.MVCCKey{Key: kv.Key, Timestamp: kv.Value.Timestamp}.Len() +
+		len(kv.Value.RawBytes))
+}
+
+// scanHistorical scans the visible key-value pairs of the span, applying the
+// limits and options of the header in the same way as MVCCScan does with
+// TargetBytesAvoidExcess. Reverse scans need to read the whole span before
+// they can return its last keys, so their memory use is bounded by the size of
+// the span rather than the limits.
+func scanHistorical(
+	ctx context.Context,
+	asOf *storage.ReadAsOfIterator,
+	acc *mon.BoundAccount,
+	h roachpb.Header,
+	span roachpb.Span,
+	format roachpb.ScanFormat,
+	maxKeys, targetBytes int64,
+	reverse bool,
+) (historicalScanResult, error) {
+	var res historicalScanResult
+	wholeRows := h.WholeRowsOfSize > 1
+
+	// add adds the key-value pair to the result, unless a limit is reached. It
+	// returns false once the scan must stop.
+	add := func(kv roachpb.KeyValue) (bool, error) {
+		size := historicalKVSize(kv)
+		var reason roachpb.ResumeReason
+		if targetBytes > 0 && res.numBytes+size > targetBytes {
+			reason = roachpb.RESUME_BYTE_LIMIT
+		} else if maxKeys > 0 && int64(len(res.kvs)) >= maxKeys {
+			reason = roachpb.RESUME_KEY_LIMIT
+		}
+		if reason != 0 {
+			resumeKey := kv.Key
+			if !h.AllowEmpty && (len(res.kvs) == 0 || (wholeRows && sameRow(res.kvs[0].Key, kv.Key))) {
+				// The first key, and with it the first row, is returned even if it
+				// exceeds the limits.
+				reason = 0
+			} else if wholeRows {
+				// Don't return a partial last row.
+				for n := len(res.kvs); n > 0 && sameRow(res.kvs[n-1].Key, kv.Key); n-- {
+					resumeKey = res.kvs[n-1].Key
+					res.numBytes -= historicalKVSize(res.kvs[n-1])
+					res.kvs = res.kvs[:n-1]
+				}
+			}
+			if reason != 0 {
+				res.resumeReason = reason
+				if reason == roachpb.RESUME_BYTE_LIMIT {
+					res.resumeNextBytes = size
+				}
+				if reverse {
+					res.resumeSpan = &roachpb.Span{Key: span.Key, EndKey: resumeKey.Next()}
+				} else {
+					res.resumeSpan = &roachpb.Span{Key: resumeKey, EndKey: span.EndKey}
+				}
+				return false, nil
+			}
+		}
+		if err := acc.Grow(ctx, size); err != nil {
+			return false, err
+		}
+		res.kvs = append(res.kvs, kv)
+		res.numBytes += size
+		return true, nil
+	}
+
+	// visit calls fn with the visible key-value pairs of the span in increasing
+	// key order, until it returns false.
+	visit := func(fn func(roachpb.KeyValue) (bool, error)) error {
+		for asOf.SeekGE(storage.MVCCKey{Key: span.Key}); ; asOf.Next() {
+			if ok, err := asOf.Valid(); err != nil {
+				return err
+			} else if !ok || asOf.UnsafeKey().Key.Compare(span.EndKey) >= 0 {
+				return nil
+			}
+			kv, ok, err := visibleKV(asOf)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if more, err := fn(kv); err != nil || !more {
+				return err
+			}
+		}
+	}
+
+	if !reverse {
+		if err := visit(add); err != nil {
+			return historicalScanResult{}, err
+		}
+	} else {
+		var all []roachpb.KeyValue
+		if err := visit(func(kv roachpb.KeyValue) (bool, error) {
+			if err := acc.Grow(ctx, historicalKVSize(kv)); err != nil {
+				return false, err
+			}
+			all = append(all, kv)
+			return true, nil
+		}); err != nil {
+			return historicalScanResult{}, err
+		}
+		for i := len(all) - 1; i >= 0; i-- {
+			if more, err := add(all[i]); err != nil {
+				return historicalScanResult{}, err
+			} else if !more {
+				break
+			}
+		}
+	}
+
+	res.numKeys = int64(len(res.kvs))
+	switch format {
+	case roachpb.KEY_VALUES:
+	case roachpb.BATCH_RESPONSE:
+		// Encode the key-value pairs in the format produced by MVCCScanToBytes.
+		if err := acc.Grow(ctx, res.numBytes); err != nil {
+			return historicalScanResult{}, err
+		}
+		repr := make([]byte, 0, res.numBytes)
+		var lens [8]byte
+		for _, kv := range res.kvs {
+			key := storage.EncodeMVCCKey(storage.MVCCKey{Key: kv.Key, Timestamp: kv.Value.Timestamp})
+			binary.LittleEndian.PutUint32(lens[:4], uint32(len(kv.Value.RawBytes)))
+			binary.LittleEndian.PutUint32(lens[4:], uint32(len(key)))
+			repr = append(repr, lens[:]...)
+			repr = append(repr, key...)
+			repr = append(repr, kv.Value.RawBytes...)
+		}
+		res.kvs, res.batchResponses = nil, [][]byte{repr}
+	default:
+		return historicalScanResult{}, errors.AssertionFailedf("unknown scan format %d", format)
+	}
+	return res, nil
+}
+
+// sameRow returns whether the keys belong to the same SQL row.
+func sameRow(a, b roachpb.Key) bool {
+	prefix := rowPrefix(a)
+	return prefix != nil && bytes.Equal(prefix, rowPrefix(b))
+}
+
+// rowPrefix returns the prefix of the key shared by all the keys of its SQL
+// row, or nil if the key is not part of a SQL row.
+func rowPrefix(key roachpb.Key) []byte {
+	n, err := keys.GetRowPrefixLength(key)
+	if err != nil || n <= 0 || n >= len(key) {
+		return nil
+	}
+	return key[:n]
+}
+
+func (s *backupReadSource) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.closed = true
+	closeMounts(context.Background(), s.mu.mounts)
+	s.mu.mounts = nil
+}
+
+func init() {
+	server.NewHistoricalReadSource = func(execCfg *sql.ExecutorConfig) kvcoord.HistoricalReadSource {
+		// Backups are mounted through a system-only setting, so tenants never
+		// have a source.
+		if !execCfg.Codec.ForSystemTenant() {
+			return nil
+		}
+		return newBackupReadSource(execCfg)
+	}
+}
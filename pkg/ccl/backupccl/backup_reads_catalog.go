@@ -0,0 +1,242 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// Backups don't contain the KVs of system.descriptor and system.namespace, but
+// AS OF SYSTEM TIME queries need to resolve names and descriptors at their
+// timestamp before they read any data. Once the history of those tables has
+// been garbage collected, these reads are served from the descriptors recorded
+// in the manifests of a mounted backup, from which the KVs of the two tables
+// are reconstructed.
+
+// catalogKV is a KV of system.descriptor or system.namespace. A value which is
+// not present is a deletion tombstone.
+type catalogKV struct {
+	key   storage.MVCCKey
+	value roachpb.Value
+}
+
+// isCatalogRead returns whether the spans only read from system.descriptor and
+// system.namespace.
+func isCatalogRead(codec keys.SQLCodec, spans []roachpb.Span) bool {
+	var catalog roachpb.SpanGroup
+	for _, id := range []uint32{keys.DescriptorTableID, keys.NamespaceTableID} {
+		prefix := codec.TablePrefix(id)
+		catalog.Add(roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()})
+	}
+	return catalog.Encloses(spans...)
+}
+
+// catalogKVs returns the catalog KVs which reflect the descriptors recorded by
+// the manifest: each of their revisions if the backup has revision history, or
+// otherwise the descriptors as of its end time. The KVs are sorted, and a name
+// is removed from system.namespace when its descriptor is renamed or dropped.
+func catalogKVs(
+	codec keys.SQLCodec, manifest *backuppb.BackupManifest,
+) ([]catalogKV, error) {
+	revs := append([]backuppb.BackupManifest_DescriptorRevision(nil), manifest.DescriptorChanges...)
+	if len(revs) == 0 {
+		for i := range manifest.Descriptors {
+			raw := &manifest.Descriptors[i]
+			desc := descbuilder.NewBuilder(raw).BuildImmutable()
+			rev := backuppb.BackupManifest_DescriptorRevision{
+				ID: desc.GetID(), Time: desc.GetModificationTime(), Desc: raw,
+			}
+			if rev.Time.IsEmpty() {
+				rev.Time = manifest.EndTime
+			}
+			revs = append(revs, rev)
+		}
+	}
+	sort.SliceStable(revs, func(i, j int) bool {
+		if revs[i].ID != revs[j].ID {
+			return revs[i].ID < revs[j].ID
+		}
+		return revs[i].Time.Less(revs[j].Time)
+	})
+
+	var kvs []catalogKV
+	var nameKey roachpb.Key
+	for i := range revs {
+		rev := &revs[i]
+		if i == 0 || rev.ID != revs[i-1].ID {
+			nameKey = nil
+		}
+		descKV := catalogKV{key: storage.MVCCKey{
+			Key: catalogkeys.MakeDescMetadataKey(codec, rev.ID), Timestamp: rev.Time,
+		}}
+		var newNameKey roachpb.Key
+		if rev.Desc != nil {
+			if err := descKV.value.SetProto(rev.Desc); err != nil {
+				return nil, err
+			}
+			if desc := descbuilder.NewBuilder(rev.Desc).BuildImmutable(); !desc.Dropped() {
+				newNameKey = catalogkeys.EncodeNameKey(codec, desc)
+				// Databases without a descriptor for their public schema have a
+				// name for the synthetic one.
+				if db, ok := desc.(catalog.DatabaseDescriptor); ok && !db.HasPublicSchemaWithDescriptor() {
+					publicKV := catalogKV{key: storage.MVCCKey{
+						Key:       catalogkeys.MakeSchemaNameKey(codec, rev.ID, tree.PublicSchema),
+						Timestamp: rev.Time,
+					}}
+					publicKV.value.SetInt(keys.PublicSchemaID)
+					kvs = append(kvs, publicKV)
+				}
+			}
+		}
+		kvs = append(kvs, descKV)
+		if newNameKey.Equal(nameKey) {
+			continue
+		}
+		if nameKey != nil {
+			kvs = append(kvs, catalogKV{key: storage.MVCCKey{Key: nameKey, Timestamp: rev.Time}})
+		}
+		if newNameKey != nil {
+			nameKV := catalogKV{key: storage.MVCCKey{Key: newNameKey, Timestamp: rev.Time}}
+			nameKV.value.SetInt(int64(rev.ID))
+			kvs = append(kvs, nameKV)
+		}
+		nameKey = newNameKey
+	}
+
+	// A name may be removed from one descriptor and given to another one at
+	// the same timestamp, in which case the name is kept.
+	sort.SliceStable(kvs, func(i, j int) bool { return kvs[i].key.Less(kvs[j].key) })
+	out := 0
+	for _, kv := range kvs {
+		if out > 0 && kv.key.Equal(kvs[out-1].key) {
+			if kv.value.IsPresent() {
+				kvs[out-1] = kv
+			}
+			continue
+		}
+		kvs[out] = kv
+		out++
+	}
+	return kvs[:out], nil
+}
+
+// recordsKeys returns whether the catalog KVs contain the key read by each of
+// the spans, which read a single key. Reads of the descriptors and names which
+// the backup does not record are not served, as the backup can't tell whether
+// they existed at the time of the read.
+func recordsKeys(kvs []catalogKV, spans []roachpb.Span) bool {
+	for _, sp := range spans {
+		if sp.EndKey != nil && !sp.EndKey.Equal(sp.Key.PrefixEnd()) {
+			return false
+		}
+		i := sort.Search(len(kvs), func(i int) bool {
+			return kvs[i].key.Key.Compare(sp.Key) >= 0
+		})
+		if i == len(kvs) || !kvs[i].key.Key.Equal(sp.Key) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeCatalogSST returns an SST holding the catalog KVs for which include
+// returns true, or nil if there are none.
+func writeCatalogSST(
+	ctx context.Context, st *cluster.Settings, kvs []catalogKV, include func(catalogKV) bool,
+) ([]byte, error) {
+	var f storage.MemFile
+	sst := storage.MakeBackupSSTWriter(ctx, st, &f)
+	defer sst.Close()
+	empty := true
+	for _, kv := range kvs {
+		if !include(kv) {
+			continue
+		}
+		if err := sst.PutMVCC(kv.key, storage.MVCCValue{Value: kv.value}); err != nil {
+			return nil, err
+		}
+		empty = false
+	}
+	if empty {
+		return nil, nil
+	}
+	if err := sst.Finish(); err != nil {
+		return nil, err
+	}
+	return f.Data(), nil
+}
+
+// evaluateCatalogBatch evaluates a batch of reads of the catalog at the given
+// timestamp against the catalog KVs of a mounted backup. The batch either
+// consists of Get requests, or of a single Export request.
+func evaluateCatalogBatch(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	ba roachpb.BatchRequest,
+	readTS hlc.Timestamp,
+	kvs []catalogKV,
+) (*roachpb.BatchResponse, error) {
+	memMon := startBatchMonitor(ctx, execCfg)
+	defer memMon.Stop(ctx)
+	acc := memMon.MakeBoundAccount()
+	defer acc.Close(ctx)
+
+	if export, ok := ba.Requests[0].GetInner().(*roachpb.ExportRequest); ok {
+		span := export.Span()
+		sst, err := writeCatalogSST(ctx, execCfg.Settings, kvs, func(kv catalogKV) bool {
+			return span.ContainsKey(kv.key.Key) &&
+				export.StartTime.Less(kv.key.Timestamp) && kv.key.Timestamp.LessEq(readTS)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := acc.Grow(ctx, int64(len(sst))); err != nil {
+			return nil, err
+		}
+		resp := &roachpb.ExportResponse{}
+		if sst != nil {
+			resp.Files = []roachpb.ExportResponse_File{{Span: span, SST: sst}}
+		}
+		br := &roachpb.BatchResponse{}
+		br.Add(resp)
+		br.Timestamp = readTS
+		return br, nil
+	}
+
+	sst, err := writeCatalogSST(ctx, execCfg.Settings, kvs, func(catalogKV) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	if err := acc.Grow(ctx, int64(len(sst))); err != nil {
+		return nil, err
+	}
+	if sst == nil {
+		return nil, errors.AssertionFailedf("no catalog KVs to serve batch from")
+	}
+	iter, err := storage.NewMemSSTIterator(sst, false /* verify */)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	return evaluateHistoricalBatch(ctx, iter, &acc, ba, readTS)
+}
@@ -0,0 +1,366 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBackupHistoricalReads verifies that AS OF SYSTEM TIME reads below the GC
+// threshold are served from a mounted backup, and only to users which are
+// allowed to access the storage of the backup.
+func TestBackupHistoricalReads(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tc, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, 0, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE DATABASE test`)
+	sqlDB.Exec(t, `CREATE TABLE test.t (k INT PRIMARY KEY, v STRING)`)
+	sqlDB.Exec(t, `INSERT INTO test.t VALUES (1, 'a'), (2, 'b'), (3, 'c')`)
+	sqlDB.Exec(t, `CREATE USER testuser`)
+	sqlDB.Exec(t, `GRANT SELECT ON test.t TO testuser`)
+	var asOf string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&asOf)
+	sqlDB.Exec(t, `UPDATE test.t SET v = 'z' WHERE k = 1`)
+	sqlDB.Exec(t, `DELETE FROM test.t WHERE k = 2`)
+	// Change the descriptor of the table, so that the version of the
+	// descriptor at asOf has to be read from the catalog.
+	sqlDB.Exec(t, `ALTER TABLE test.t ADD COLUMN w INT`)
+
+	const collection = localFoo + "/mount"
+	sqlDB.Exec(t, `BACKUP DATABASE test INTO $1 WITH revision_history`, collection)
+	var subdir string
+	sqlDB.QueryRow(t, `SHOW BACKUPS IN $1`, collection).Scan(&subdir)
+
+	query := fmt.Sprintf(`SELECT k, v FROM test.t AS OF SYSTEM TIME %s ORDER BY k`, asOf)
+	const gcErr = "must be after replica GC threshold"
+
+	// Garbage collect the revisions visible at asOf, including those of the
+	// catalog, so that the names and descriptors at asOf are also resolved from
+	// the backup.
+	sqlDB.Exec(t, `ALTER TABLE test.t CONFIGURE ZONE USING gc.ttlseconds = 1`)
+	sqlDB.Exec(t, `ALTER DATABASE system CONFIGURE ZONE USING gc.ttlseconds = 1`)
+	testutils.SucceedsSoon(t, func() error {
+		runGCWithTrace(t, sqlDB, true /* skipShouldQueue */, "test", "t")
+		runGCWithTrace(t, sqlDB, true /* skipShouldQueue */, "system", "descriptor")
+		runGCWithTrace(t, sqlDB, true /* skipShouldQueue */, "system", "namespace")
+		if _, err := sqlDB.DB.ExecContext(context.Background(), query); !testutils.IsError(err, gcErr) {
+			return errors.Newf("expected GC threshold error, got %v", err)
+		}
+		return nil
+	})
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup.historical_reads.mounts = $1`, collection+subdir)
+	expected := [][]string{{"1", "a"}, {"2", "b"}, {"3", "c"}}
+	sqlDB.CheckQueryResults(t, query, expected)
+
+	// The backup is stored on the node's local filesystem, so users without
+	// the admin role may not read from it.
+	pgURL, cleanup := sqlutils.PGUrl(t, tc.Server(0).ServingSQLAddr(),
+		"TestBackupHistoricalReads-testuser", url.User("testuser"))
+	defer cleanup()
+	testuser, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testuser.Close())
+	}()
+	_, err = testuser.Exec(query)
+	require.True(t, testutils.IsError(err, gcErr), "%v", err)
+
+	sqlDB.Exec(t, `GRANT admin TO testuser`)
+	sqlutils.MakeSQLRunner(testuser).CheckQueryResults(t, query, expected)
+}
+
+func TestEvaluateHistoricalBatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	eng := storage.NewDefaultInMemForTesting()
+	defer eng.Close()
+
+	ts := func(wall int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wall} }
+	put := func(key string, wall int64, value string) {
+		require.NoError(t, storage.MVCCPut(ctx, eng, nil /* ms */, roachpb.Key(key), ts(wall),
+			hlc.ClockTimestamp{}, roachpb.MakeValueFromString(value), nil /* txn */))
+	}
+	put("a", 1, "a1")
+	put("a", 3, "a3")
+	put("b", 1, "b1")
+	put("c", 2, "c2")
+	require.NoError(t, storage.MVCCDelete(ctx, eng, nil /* ms */, roachpb.Key("b"), ts(2),
+		hlc.ClockTimestamp{}, nil /* txn */))
+
+	keysOf := func(rows []roachpb.KeyValue) []string {
+		var res []string
+		for _, kv := range rows {
+			res = append(res, string(kv.Key))
+		}
+		return res
+	}
+	span := roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")}
+	evaluate := func(ba roachpb.BatchRequest, budget int64) (*roachpb.BatchResponse, error) {
+		iter := eng.NewMVCCIterator(storage.MVCCKeyIterKind, storage.IterOptions{UpperBound: roachpb.KeyMax})
+		defer iter.Close()
+		mm := mon.NewMonitorWithLimit(
+			"test-mm", mon.MemoryResource, budget,
+			nil, nil,
+			1 /* increment */, 100,
+			cluster.MakeTestingClusterSettings())
+		mm.Start(ctx, nil, mon.MakeStandaloneBudget(budget))
+		defer mm.Stop(ctx)
+		acc := mm.MakeBoundAccount()
+		defer acc.Close(ctx)
+		return evaluateHistoricalBatch(ctx, iter, &acc, ba, ba.Timestamp)
+	}
+	const unlimited = 1 << 30
+
+	// Reads observe the versions visible at the batch timestamp.
+	var ba roachpb.BatchRequest
+	ba.Timestamp = ts(2)
+	ba.Add(roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */))
+	ba.Add(roachpb.NewScan(span.Key, span.EndKey, false /* forUpdate */))
+	br, err := evaluate(ba, unlimited)
+	require.NoError(t, err)
+	require.Equal(t, ts(2), br.Timestamp)
+	val, err := br.Responses[0].GetGet().Value.GetBytes()
+	require.NoError(t, err)
+	require.Equal(t, "a1", string(val))
+	require.Equal(t, []string{"a", "c"}, keysOf(br.Responses[1].GetScan().Rows))
+
+	// Key limits apply across the requests in the batch.
+	ba = roachpb.BatchRequest{}
+	ba.Timestamp = ts(3)
+	ba.MaxSpanRequestKeys = 1
+	ba.Add(roachpb.NewReverseScan(span.Key, span.EndKey, false /* forUpdate */))
+	ba.Add(roachpb.NewScan(span.Key, span.EndKey, false /* forUpdate */))
+	br, err = evaluate(ba, unlimited)
+	require.NoError(t, err)
+	rev := br.Responses[0].GetReverseScan()
+	require.Equal(t, []string{"c"}, keysOf(rev.Rows))
+	require.Equal(t, roachpb.RESUME_KEY_LIMIT, rev.ResumeReason)
+	require.NotNil(t, rev.ResumeSpan)
+	scan := br.Responses[1].GetScan()
+	require.Empty(t, scan.Rows)
+	require.Equal(t, roachpb.RESUME_KEY_LIMIT, scan.ResumeReason)
+	require.Equal(t, &span, scan.ResumeSpan)
+
+	// Results can also be returned in the BATCH_RESPONSE format.
+	ba = roachpb.BatchRequest{}
+	ba.Timestamp = ts(3)
+	scanReq := roachpb.NewScan(span.Key, span.EndKey, false /* forUpdate */).(*roachpb.ScanRequest)
+	scanReq.ScanFormat = roachpb.BATCH_RESPONSE
+	ba.Add(scanReq)
+	br, err = evaluate(ba, unlimited)
+	require.NoError(t, err)
+	scan = br.Responses[0].GetScan()
+	require.Nil(t, scan.Rows)
+	require.Equal(t, int64(2), scan.NumKeys)
+	var batchKeys []string
+	for _, batch := range scan.BatchResponses {
+		require.NoError(t, storage.MVCCScanDecodeKeyValues([][]byte{batch},
+			func(key storage.MVCCKey, rawBytes []byte) error {
+				batchKeys = append(batchKeys, string(key.Key))
+				return nil
+			}))
+	}
+	require.Equal(t, []string{"a", "c"}, batchKeys)
+
+	// The memory used to evaluate the batch is bounded.
+	ba = roachpb.BatchRequest{}
+	ba.Timestamp = ts(3)
+	ba.Add(roachpb.NewScan(span.Key, span.EndKey, false /* forUpdate */))
+	_, err = evaluate(ba, 1)
+	require.True(t, testutils.IsError(err, "memory budget exceeded"), "%v", err)
+}
+
+func TestMountedBackupLayersCovering(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ts := func(wall int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wall} }
+	sp := func(start, end string) roachpb.Span {
+		return roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)}
+	}
+	m := &mountedBackup{
+		defaultURIs: []string{"full", "inc1", "inc2"},
+		manifests: []backuppb.BackupManifest{
+			{
+				EndTime:           ts(10),
+				MVCCFilter:        backuppb.MVCCFilter_All,
+				RevisionStartTime: ts(5),
+				Spans:             []roachpb.Span{sp("a", "m")},
+			},
+			{
+				StartTime:  ts(10),
+				EndTime:    ts(20),
+				MVCCFilter: backuppb.MVCCFilter_All,
+				Spans:      []roachpb.Span{sp("a", "m")},
+			},
+			{
+				StartTime: ts(20),
+				EndTime:   ts(30),
+				Spans:     []roachpb.Span{sp("a", "z")},
+			},
+		},
+		localityInfo: make([]jobspb.RestoreDetails_BackupLocalityInfo, 3),
+	}
+
+	for _, tc := range []struct {
+		ts     hlc.Timestamp
+		spans  []roachpb.Span
+		layers int
+		ok     bool
+	}{
+		// Revision history of the full backup only starts at 5.
+		{ts: ts(4), spans: []roachpb.Span{sp("a", "b")}},
+		{ts: ts(7), spans: []roachpb.Span{sp("a", "b")}, layers: 1, ok: true},
+		{ts: ts(15), spans: []roachpb.Span{sp("a", "b")}, layers: 2, ok: true},
+		// Point reads are covered by their key.
+		{ts: ts(15), spans: []roachpb.Span{{Key: roachpb.Key("c")}}, layers: 2, ok: true},
+		// Spans outside of the backed up spans are not covered.
+		{ts: ts(15), spans: []roachpb.Span{sp("a", "n")}},
+		// The last layer has no revision history, so only its end time can be
+		// read.
+		{ts: ts(25), spans: []roachpb.Span{sp("a", "n")}},
+		{ts: ts(30), spans: []roachpb.Span{sp("a", "n")}, layers: 3, ok: true},
+		// Reads after the end of the chain are not covered.
+		{ts: ts(35), spans: []roachpb.Span{sp("a", "b")}},
+	} {
+		layers, ok := m.layersCovering(tc.ts, tc.spans)
+		require.Equal(t, tc.ok, ok, "ts %s spans %s", tc.ts, tc.spans)
+		require.Equal(t, tc.layers, layers, "ts %s spans %s", tc.ts, tc.spans)
+	}
+}
+
+func TestBackupCatalogKVs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	codec := keys.SystemSQLCodec
+
+	ts := func(wall int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wall} }
+	db := &descpb.Descriptor{Union: &descpb.Descriptor_Database{
+		Database: &descpb.DatabaseDescriptor{ID: 100, Name: "db", Version: 1},
+	}}
+	table := func(name string, state descpb.DescriptorState) *descpb.Descriptor {
+		return &descpb.Descriptor{Union: &descpb.Descriptor_Table{Table: &descpb.TableDescriptor{
+			ID: 101, Name: name, ParentID: 100, UnexposedParentSchemaID: keys.PublicSchemaID,
+			Version: 1, State: state, FormatVersion: descpb.InterleavedFormatVersion,
+		}}}
+	}
+	// The table is renamed from t to u at 20, and dropped at 30.
+	manifest := backuppb.BackupManifest{
+		EndTime:    ts(40),
+		MVCCFilter: backuppb.MVCCFilter_All,
+		DescriptorChanges: []backuppb.BackupManifest_DescriptorRevision{
+			{ID: 100, Time: ts(10), Desc: db},
+			{ID: 101, Time: ts(10), Desc: table("t", descpb.DescriptorState_PUBLIC)},
+			{ID: 101, Time: ts(20), Desc: table("u", descpb.DescriptorState_PUBLIC)},
+			{ID: 101, Time: ts(30), Desc: table("u", descpb.DescriptorState_DROP)},
+		},
+	}
+	kvs, err := catalogKVs(codec, &manifest)
+	require.NoError(t, err)
+
+	dbName := catalogkeys.MakeDatabaseNameKey(codec, "db")
+	publicName := catalogkeys.MakeSchemaNameKey(codec, 100, "public")
+	tName := catalogkeys.MakePublicObjectNameKey(codec, 100, "t")
+	uName := catalogkeys.MakePublicObjectNameKey(codec, 100, "u")
+	tableDesc := catalogkeys.MakeDescMetadataKey(codec, 101)
+	otherName := catalogkeys.MakePublicObjectNameKey(codec, 100, "other")
+
+	// Only reads of single keys recorded by the backup are served.
+	require.True(t, recordsKeys(kvs, []roachpb.Span{{Key: dbName}, {Key: uName}}))
+	require.True(t, recordsKeys(kvs, []roachpb.Span{{Key: tableDesc, EndKey: tableDesc.PrefixEnd()}}))
+	require.False(t, recordsKeys(kvs, []roachpb.Span{{Key: dbName}, {Key: otherName}}))
+	require.False(t, recordsKeys(kvs, []roachpb.Span{{Key: dbName, EndKey: uName}}))
+
+	sst, err := writeCatalogSST(ctx, st, kvs, func(catalogKV) bool { return true })
+	require.NoError(t, err)
+	get := func(readTS hlc.Timestamp, key roachpb.Key) *roachpb.Value {
+		iter, err := storage.NewMemSSTIterator(sst, false /* verify */)
+		require.NoError(t, err)
+		defer iter.Close()
+		mm := mon.NewMonitorWithLimit(
+			"test-mm", mon.MemoryResource, 1<<30,
+			nil, nil,
+			1 /* increment */, 100,
+			st)
+		mm.Start(ctx, nil, mon.MakeStandaloneBudget(1<<30))
+		defer mm.Stop(ctx)
+		acc := mm.MakeBoundAccount()
+		defer acc.Close(ctx)
+		var ba roachpb.BatchRequest
+		ba.Timestamp = readTS
+		ba.Add(roachpb.NewGet(key, false /* forUpdate */))
+		br, err := evaluateHistoricalBatch(ctx, iter, &acc, ba, readTS)
+		require.NoError(t, err)
+		return br.Responses[0].GetGet().Value
+	}
+	getID := func(readTS hlc.Timestamp, key roachpb.Key) int64 {
+		v := get(readTS, key)
+		if v == nil {
+			return 0
+		}
+		id, err := v.GetInt()
+		require.NoError(t, err)
+		return id
+	}
+
+	require.Equal(t, int64(100), getID(ts(15), dbName))
+	require.Equal(t, int64(keys.PublicSchemaID), getID(ts(15), publicName))
+	require.Equal(t, int64(101), getID(ts(15), tName))
+	require.Equal(t, int64(0), getID(ts(15), uName))
+	require.Equal(t, int64(0), getID(ts(25), tName))
+	require.Equal(t, int64(101), getID(ts(25), uName))
+	require.Equal(t, int64(0), getID(ts(35), uName))
+	require.Equal(t, int64(0), getID(ts(5), dbName))
+
+	for _, tc := range []struct {
+		readTS hlc.Timestamp
+		name   string
+		state  descpb.DescriptorState
+	}{
+		{readTS: ts(15), name: "t", state: descpb.DescriptorState_PUBLIC},
+		{readTS: ts(25), name: "u", state: descpb.DescriptorState_PUBLIC},
+		{readTS: ts(35), name: "u", state: descpb.DescriptorState_DROP},
+	} {
+		v := get(tc.readTS, tableDesc)
+		require.NotNil(t, v)
+		var desc descpb.Descriptor
+		require.NoError(t, v.GetProto(&desc))
+		require.Equal(t, tc.name, desc.GetTable().Name)
+		require.Equal(t, tc.state, desc.GetTable().State)
+	}
+}
@@ -10,7 +10,9 @@ package backupccl
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"hash"
 	io "io"
 	"sort"
 
@@ -55,6 +57,9 @@ type fileSSTSink struct {
 	cancel  func()
 	out     io.WriteCloser
 	outName string
+	// outSum records the checksum and size of the bytes written to the
+	// current output file, as they are stored (i.e. after any encryption).
+	outSum *checksummingWriter
 
 	flushedFiles    []backuppb.BackupManifest_File
 	flushedSize     int64
@@ -185,8 +190,14 @@ func (s *fileSSTSink) flushFile(ctx context.Context) error {
 		log.Warningf(ctx, "failed to close write in fileSSTSink: % #v", pretty.Formatter(err))
 		return errors.Wrap(err, "writing SST")
 	}
+	checksum, size := s.outSum.h.Sum(nil), s.outSum.n
+	for i := range s.flushedFiles {
+		s.flushedFiles[i].Checksum = checksum
+		s.flushedFiles[i].FileSize = size
+	}
 	s.outName = ""
 	s.out = nil
+	s.outSum = nil
 
 	progDetails := backuppb.BackupManifest_Progress{
 		RevStartTime:   s.flushedRevStart,
@@ -222,6 +233,8 @@ func (s *fileSSTSink) open(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	s.outSum = &checksummingWriter{WriteCloser: w, h: sha256.New()}
+	w = s.outSum
 	if s.conf.enc != nil {
 		var err error
 		w, err = storageccl.EncryptingWriter(w, s.conf.enc.Key)
@@ -329,3 +342,19 @@ func generateUniqueSSTName(nodeID base.SQLInstanceID) string {
 	// common file/bucket browse UIs.
 	return fmt.Sprintf("data/%d.sst", builtins.GenerateUniqueInt(nodeID))
 }
+
+// checksummingWriter is an io.WriteCloser that hashes and counts the bytes
+// written through it.
+type checksummingWriter struct {
+	io.WriteCloser
+	h hash.Hash
+	n int64
+}
+
+func (w *checksummingWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	// hash.Hash.Write never returns an error.
+	_, _ = w.h.Write(p[:n])
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,227 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// restoreDryRunHeader is the header for RESTORE ... WITH dry_run, which reports
+// one row per object the restore would create.
+var restoreDryRunHeader = colinfo.ResultColumns{
+	{Name: "object_type", Typ: types.String},
+	{Name: "object_name", Typ: types.String},
+	{Name: "backup_id", Typ: types.Int},
+	{Name: "restore_id", Typ: types.Int},
+	{Name: "spans", Typ: types.Int},
+	{Name: "estimated_bytes", Typ: types.Int},
+}
+
+// restoreDryRunRow describes an object that a restore would create.
+type restoreDryRunRow struct {
+	objectType string
+	name       string
+	backupID   uint64
+	restoreID  uint64
+	// spans are the spans of the object's data in the backup, if any.
+	spans []roachpb.Span
+	// estimatedBytes is the logical size of the backup files overlapping spans.
+	// A file that overlaps several objects is counted for each of them.
+	estimatedBytes int64
+}
+
+// planRestoreDryRun describes the databases, schemas, types, tables and tenants
+// a restore would create. It must be called before the descriptors are
+// rewritten, as it reports their IDs and spans as they appear in the backup.
+// Objects that are remapped to existing ones in the cluster are not reported.
+// It also checks that the backups cover the spans to restore, which the
+// restore job would otherwise only find out once it starts.
+func planRestoreDryRun(
+	ctx context.Context,
+	manifests []backuppb.BackupManifest,
+	endTime hlc.Timestamp,
+	rewrites jobspb.DescRewriteMap,
+	databases []*dbdesc.Mutable,
+	schemas []*schemadesc.Mutable,
+	tables []*tabledesc.Mutable,
+	typs []*typedesc.Mutable,
+	tenants []descpb.TenantInfoWithUsage,
+	oldTenantID *roachpb.TenantID,
+) ([]restoreDryRunRow, error) {
+	lastBackupIndex, err := getBackupIndexAtTime(manifests, endTime)
+	if err != nil {
+		return nil, err
+	}
+	// backupCodec is the codec that was used to encode the keys in the backup,
+	// determined the same way as in the restore job.
+	backupCodec := keys.SystemSQLCodec
+	if latest := manifests[lastBackupIndex]; len(latest.Spans) != 0 && !latest.HasTenants() {
+		_, backupTenantID, err := keys.DecodeTenantPrefix(latest.Spans[0].Key)
+		if err != nil {
+			return nil, err
+		}
+		backupCodec = keys.MakeSQLCodec(backupTenantID)
+	}
+
+	var rows []restoreDryRunRow
+	addDescs := func(objectType string, descs []catalog.Descriptor) {
+		sort.Sort(catalog.Descriptors(descs))
+		for _, desc := range descs {
+			rw, ok := rewrites[desc.GetID()]
+			if !ok || rw.ToExisting {
+				continue
+			}
+			row := restoreDryRunRow{
+				objectType: objectType,
+				name:       desc.GetName(),
+				backupID:   uint64(desc.GetID()),
+				restoreID:  uint64(rw.ID),
+			}
+			if table, ok := desc.(catalog.TableDescriptor); ok {
+				row.spans = spansForAllRestoreTableIndexes(
+					backupCodec, []catalog.TableDescriptor{table}, nil /* revs */)
+			}
+			rows = append(rows, row)
+		}
+	}
+	{
+		descs := make([]catalog.Descriptor, len(databases))
+		for i := range databases {
+			descs[i] = databases[i]
+		}
+		addDescs("database", descs)
+	}
+	{
+		descs := make([]catalog.Descriptor, len(schemas))
+		for i := range schemas {
+			descs[i] = schemas[i]
+		}
+		addDescs("schema", descs)
+	}
+	{
+		descs := make([]catalog.Descriptor, len(typs))
+		for i := range typs {
+			descs[i] = typs[i]
+		}
+		addDescs("type", descs)
+	}
+	{
+		descs := make([]catalog.Descriptor, len(tables))
+		for i := range tables {
+			descs[i] = tables[i]
+		}
+		addDescs("table", descs)
+	}
+	for _, tenant := range tenants {
+		from := roachpb.MakeTenantID(tenant.ID)
+		if oldTenantID != nil {
+			from = *oldTenantID
+		}
+		prefix := keys.MakeTenantPrefix(from)
+		rows = append(rows, restoreDryRunRow{
+			objectType: "tenant",
+			name:       strconv.FormatUint(tenant.ID, 10),
+			backupID:   from.ToUint64(),
+			restoreID:  tenant.ID,
+			spans:      []roachpb.Span{{Key: prefix, EndKey: prefix.PrefixEnd()}},
+		})
+	}
+
+	var spans []roachpb.Span
+	for _, row := range rows {
+		spans = append(spans, row.spans...)
+	}
+	if err := checkCoverage(ctx, spans, manifests); err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		for _, m := range manifests {
+			for _, f := range m.Files {
+				for _, sp := range rows[i].spans {
+					if sp.Overlaps(f.Span) {
+						rows[i].estimatedBytes += f.EntryCounts.DataSize
+						break
+					}
+				}
+			}
+		}
+	}
+	return rows, nil
+}
+
+// reportRestoreDryRun validates the rewritten descriptors of a dry run restore
+// and sends the rows describing the objects it would create to resultsCh.
+func reportRestoreDryRun(
+	ctx context.Context,
+	p sql.PlanHookState,
+	rows []restoreDryRunRow,
+	databases []*dbdesc.Mutable,
+	schemas []*schemadesc.Mutable,
+	tables []*tabledesc.Mutable,
+	typs []*typedesc.Mutable,
+	resultsCh chan<- tree.Datums,
+) error {
+	var toValidate []catalog.Descriptor
+	for _, desc := range databases {
+		toValidate = append(toValidate, desc)
+	}
+	for _, desc := range schemas {
+		toValidate = append(toValidate, desc)
+	}
+	for _, desc := range typs {
+		toValidate = append(toValidate, desc)
+	}
+	for _, desc := range tables {
+		toValidate = append(toValidate, desc)
+	}
+	// Cross-references can't be validated since the restored descriptors are
+	// never written, but each descriptor must be valid on its own.
+	if err := sql.DescsTxn(ctx, p.ExecCfg(), func(
+		ctx context.Context, txn *kv.Txn, col *descs.Collection,
+	) error {
+		return col.Validate(
+			ctx, txn, catalog.NoValidationTelemetry, catalog.ValidationLevelSelfOnly, toValidate...)
+	}); err != nil {
+		return errors.Wrap(err, "validating restored descriptors")
+	}
+
+	for _, row := range rows {
+		resultsCh <- tree.Datums{
+			tree.NewDString(row.objectType),
+			tree.NewDString(row.name),
+			tree.NewDInt(tree.DInt(row.backupID)),
+			tree.NewDInt(tree.DInt(row.restoreID)),
+			tree.NewDInt(tree.DInt(len(row.spans))),
+			tree.NewDInt(tree.DInt(row.estimatedBytes)),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRestoreDryRun checks that RESTORE ... WITH dry_run reports the objects a
+// restore would create, surfaces the errors the restore would hit, and writes
+// nothing.
+func TestRestoreDryRun(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 100
+	_, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, localFoo)
+
+	countNamespace := func() string {
+		return sqlDB.QueryStr(t, `SELECT count(*) FROM system.namespace`)[0][0]
+	}
+	namespaceBefore := countNamespace()
+
+	rows := sqlDB.QueryStr(t, fmt.Sprintf(
+		`RESTORE DATABASE data FROM LATEST IN '%s' WITH dry_run, new_db_name = 'data2'`, localFoo))
+	var sawDB bool
+	var tableID string
+	for _, row := range rows {
+		switch objectType, name := row[0], row[1]; objectType {
+		case "database":
+			require.Equal(t, "data2", name)
+			sawDB = true
+		case "table":
+			require.Equal(t, "bank", name)
+			require.Equal(t, "1", row[4])
+			estimatedBytes, err := strconv.Atoi(row[5])
+			require.NoError(t, err)
+			require.Greater(t, estimatedBytes, 0)
+			tableID = row[3]
+		}
+		require.NotEqual(t, row[2], row[3], "%s %s should be restored with a new ID", row[0], row[1])
+	}
+	require.True(t, sawDB)
+	require.NotEmpty(t, tableID)
+
+	// Nothing was written, so the database can still be restored under that name
+	// and the dry run reported the IDs the restore allocates.
+	require.Equal(t, namespaceBefore, countNamespace())
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM [SHOW DATABASES] WHERE database_name = 'data2'`, [][]string{{"0"}})
+	sqlDB.Exec(t, fmt.Sprintf(
+		`RESTORE DATABASE data FROM LATEST IN '%s' WITH new_db_name = 'data2'`, localFoo))
+	sqlDB.CheckQueryResults(t, `SELECT 'data2.bank'::REGCLASS::INT`, [][]string{{tableID}})
+
+	t.Run("name collision", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `relation "bank" already exists`, fmt.Sprintf(
+			`RESTORE TABLE data.bank FROM LATEST IN '%s' WITH dry_run`, localFoo))
+		sqlDB.ExpectErr(t, `database "data" already exists`, fmt.Sprintf(
+			`RESTORE DATABASE data FROM LATEST IN '%s' WITH dry_run`, localFoo))
+	})
+
+	t.Run("detached", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `cannot use "dry_run" option with detached restores`, fmt.Sprintf(
+			`RESTORE DATABASE data FROM LATEST IN '%s' WITH dry_run, detached`, localFoo))
+	})
+}
@@ -70,6 +70,7 @@ const (
 	restoreOptSkipLocalitiesCheck       = "skip_localities_check"
 	restoreOptDebugPauseOn              = "debug_pause_on"
 	restoreOptAsTenant                  = "tenant"
+	restoreOptDryRun                    = "dry_run"
 
 	// The temporary database system tables will be restored into for full
 	// cluster backups.
@@ -135,7 +136,12 @@ func maybeFilterMissingViews(
 }
 
 func synthesizePGTempSchema(
-	ctx context.Context, p sql.PlanHookState, schemaName string, dbID descpb.ID,
+	ctx context.Context,
+	p sql.PlanHookState,
+	schemaName string,
+	dbID descpb.ID,
+	generateID func() (descpb.ID, error),
+	dryRun bool,
 ) (descpb.ID, error) {
 	var synthesizedSchemaID descpb.ID
 	err := sql.DescsTxn(ctx, p.ExecCfg(), func(ctx context.Context, txn *kv.Txn, col *descs.Collection) error {
@@ -148,8 +154,8 @@ func synthesizePGTempSchema(
 			return errors.Newf("attempted to synthesize temp schema during RESTORE but found"+
 				" another schema already using the same schema key %s", schemaName)
 		}
-		synthesizedSchemaID, err = descidgen.GenerateUniqueDescID(ctx, p.ExecCfg().DB, p.ExecCfg().Codec)
-		if err != nil {
+		synthesizedSchemaID, err = generateID()
+		if err != nil || dryRun {
 			return err
 		}
 		return p.CreateSchemaNamespaceEntry(ctx, catalogkeys.MakeSchemaNameKey(p.ExecCfg().Codec, dbID, schemaName), synthesizedSchemaID)
@@ -288,13 +294,41 @@ func allocateDescriptorRewrites(
 
 	needsNewParentIDs := make(map[string][]descpb.ID)
 
+	// A dry run must not leak descriptor IDs, so instead of incrementing the
+	// generator it hands out the IDs the generator would produce next from a
+	// local counter.
+	generateID := func() (descpb.ID, error) {
+		return descidgen.GenerateUniqueDescID(ctx, p.ExecCfg().DB, p.ExecCfg().Codec)
+	}
+	var nextDryRunID descpb.ID
+	if opts.DryRun {
+		var err error
+		nextDryRunID, err = descidgen.PeekNextUniqueDescID(ctx, p.ExecCfg().DB, p.ExecCfg().Codec)
+		if err != nil {
+			return nil, err
+		}
+		generateID = func() (descpb.ID, error) {
+			id := nextDryRunID
+			nextDryRunID++
+			return id, nil
+		}
+	}
+
 	// Increment the DescIDSequenceKey so that it is higher than both the max desc ID
 	// in the backup and current max desc ID in the restoring cluster. This generator
 	// keeps produced the next descriptor ID.
 	var tempSysDBID descpb.ID
 	if descriptorCoverage == tree.AllDescriptors || restoreSystemUsers {
 		var err error
-		if descriptorCoverage == tree.AllDescriptors {
+		if descriptorCoverage == tree.AllDescriptors && opts.DryRun {
+			if newValue := descpb.ID(maxDescIDInBackup + 1); newValue > nextDryRunID {
+				nextDryRunID = newValue
+			}
+			tempSysDBID, err = generateID()
+			if err != nil {
+				return nil, err
+			}
+		} else if descriptorCoverage == tree.AllDescriptors {
 			// Restore the key which generates descriptor IDs.
 			if err = p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 				v, err := txn.Get(ctx, p.ExecCfg().Codec.DescIDSequenceKey())
@@ -319,12 +353,12 @@ func allocateDescriptorRewrites(
 			}); err != nil {
 				return nil, err
 			}
-			tempSysDBID, err = descidgen.GenerateUniqueDescID(ctx, p.ExecCfg().DB, p.ExecCfg().Codec)
+			tempSysDBID, err = generateID()
 			if err != nil {
 				return nil, err
 			}
 		} else if restoreSystemUsers {
-			tempSysDBID, err = descidgen.GenerateUniqueDescID(ctx, p.ExecCfg().DB, p.ExecCfg().Codec)
+			tempSysDBID, err = generateID()
 			if err != nil {
 				return nil, err
 			}
@@ -384,7 +418,8 @@ func allocateDescriptorRewrites(
 					// which the cluster was started.
 					schemaName := sql.TemporarySchemaNameForRestorePrefix +
 						strconv.Itoa(synthesizedTempSchemaCount)
-					synthesizedSchemaID, err = synthesizePGTempSchema(ctx, p, schemaName, table.GetParentID())
+					synthesizedSchemaID, err = synthesizePGTempSchema(
+						ctx, p, schemaName, table.GetParentID(), generateID, opts.DryRun)
 					if err != nil {
 						return nil, err
 					}
@@ -403,8 +438,12 @@ func allocateDescriptorRewrites(
 	// Fail fast if the necessary databases don't exist or are otherwise
 	// incompatible with this restore.
 	if err := sql.DescsTxn(ctx, p.ExecCfg(), func(ctx context.Context, txn *kv.Txn, col *descs.Collection) error {
-		// Check that any DBs being restored do _not_ exist.
+		// Check that any DBs being restored do _not_ exist. The default databases
+		// are dropped before a full cluster restore, which a dry run skips.
 		for name := range restoreDBNames {
+			if opts.DryRun && descriptorCoverage == tree.AllDescriptors && isDefaultUserDB(name) {
+				continue
+			}
 			dbID, err := col.Direct().LookupDatabaseID(ctx, txn, name)
 			if err != nil {
 				return err
@@ -681,7 +720,7 @@ func allocateDescriptorRewrites(
 		if descriptorCoverage == tree.AllDescriptors {
 			newID = db.GetID()
 		} else {
-			newID, err = descidgen.GenerateUniqueDescID(ctx, p.ExecCfg().DB, p.ExecCfg().Codec)
+			newID, err = generateID()
 			if err != nil {
 				return nil, err
 			}
@@ -752,7 +791,7 @@ func allocateDescriptorRewrites(
 	// Generate new IDs for the schemas, tables, and types that need to be
 	// remapped.
 	for _, desc := range descriptorsToRemap {
-		id, err := descidgen.GenerateUniqueDescID(ctx, p.ExecCfg().DB, p.ExecCfg().Codec)
+		id, err := generateID()
 		if err != nil {
 			return nil, err
 		}
@@ -823,6 +862,17 @@ func dropDefaultUserDBs(ctx context.Context, execCfg *sql.ExecutorConfig) error
 	})
 }
 
+// isDefaultUserDB returns whether name is one of the databases that are present
+// in a new cluster and dropped by dropDefaultUserDBs.
+func isDefaultUserDB(name string) bool {
+	for _, defaultDB := range catalogkeys.DefaultUserDBs {
+		if name == defaultDB {
+			return true
+		}
+	}
+	return false
+}
+
 func resolveTargetDB(
 	ctx context.Context,
 	txn *kv.Txn,
@@ -1033,6 +1083,11 @@ func restorePlanHook(
 		return nil, nil, nil, false, err
 	}
 
+	if restoreStmt.Options.DryRun && restoreStmt.Options.Detached {
+		return nil, nil, nil, false, errors.Errorf(
+			"cannot use %q option with detached restores", restoreOptDryRun)
+	}
+
 	fromFns := make([]func() ([]string, error), len(restoreStmt.From))
 	for i := range restoreStmt.From {
 		fromFn, err := p.TypeAsStringArray(ctx, tree.Exprs(restoreStmt.From[i]), "RESTORE")
@@ -1154,7 +1209,8 @@ func restorePlanHook(
 		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
 		defer span.Finish()
 
-		if !(p.ExtendedEvalContext().TxnIsSingleStmt || restoreStmt.Options.Detached) {
+		if !(p.ExtendedEvalContext().TxnIsSingleStmt || restoreStmt.Options.Detached ||
+			restoreStmt.Options.DryRun) {
 			return errors.Errorf("RESTORE cannot be used inside a multi-statement transaction without DETACHED option")
 		}
 
@@ -1171,7 +1227,15 @@ func restorePlanHook(
 			}
 		}
 
-		if err := checkPrivilegesForRestore(ctx, restoreStmt, p, from); err != nil {
+		var kms []string
+		if kmsFn != nil {
+			kms, err = kmsFn()
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := checkPrivilegesForRestore(ctx, restoreStmt, p, from, kms); err != nil {
 			return err
 		}
 
@@ -1192,14 +1256,6 @@ func restorePlanHook(
 			}
 		}
 
-		var kms []string
-		if kmsFn != nil {
-			kms, err = kmsFn()
-			if err != nil {
-				return err
-			}
-		}
-
 		var intoDB string
 		if intoDBFn != nil {
 			intoDB, err = intoDBFn()
@@ -1244,11 +1300,18 @@ func restorePlanHook(
 	if restoreStmt.Options.Detached {
 		return fn, jobs.DetachedJobExecutionResultHeader, nil, false, nil
 	}
+	if restoreStmt.Options.DryRun {
+		return fn, restoreDryRunHeader, nil, false, nil
+	}
 	return fn, jobs.BulkJobExecutionResultHeader, nil, false, nil
 }
 
 func checkPrivilegesForRestore(
-	ctx context.Context, restoreStmt *tree.Restore, p sql.PlanHookState, from [][]string,
+	ctx context.Context,
+	restoreStmt *tree.Restore,
+	p sql.PlanHookState,
+	from [][]string,
+	kmsURIs []string,
 ) error {
 	hasAdmin, err := p.HasAdminRole(ctx)
 	if err != nil {
@@ -1299,7 +1362,7 @@ func checkPrivilegesForRestore(
 			}
 		}
 	}
-	return nil
+	return checkKMSURIPrivileges(ctx, p, kmsURIs, "RESTORE")
 }
 
 func checkClusterRegions(
@@ -1716,7 +1779,7 @@ func doRestorePlan(
 	// databases that are present in a new cluster.
 	// This is done so that they can be restored the same way any other user
 	// defined database would be restored from the backup.
-	if restoreStmt.DescriptorCoverage == tree.AllDescriptors {
+	if restoreStmt.DescriptorCoverage == tree.AllDescriptors && !restoreStmt.Options.DryRun {
 		if err := dropDefaultUserDBs(ctx, p.ExecCfg()); err != nil {
 			return err
 		}
@@ -1776,6 +1839,18 @@ func doRestorePlan(
 		types = append(types, desc)
 	}
 
+	// The dry run report is planned before the descriptors are rewritten, since
+	// it needs their IDs and spans as they appear in the backup.
+	var dryRunRows []restoreDryRunRow
+	if restoreStmt.Options.DryRun {
+		dryRunRows, err = planRestoreDryRun(
+			ctx, mainBackupManifests, endTime, descriptorRewrites, databases, schemas, tables,
+			types, tenants, oldTenantID)
+		if err != nil {
+			return err
+		}
+	}
+
 	// We attempt to rewrite ID's in the collected type and table descriptors
 	// to catch errors during this process here, rather than in the job itself.
 	if err := rewrite.TableDescs(tables, descriptorRewrites, intoDB); err != nil {
@@ -1794,6 +1869,10 @@ func doRestorePlan(
 		revalidateIndexes[i].TableID = descriptorRewrites[revalidateIndexes[i].TableID].ID
 	}
 
+	if restoreStmt.Options.DryRun {
+		return reportRestoreDryRun(ctx, p, dryRunRows, databases, schemas, tables, types, resultsCh)
+	}
+
 	// Collect telemetry.
 	collectTelemetry := func() {
 		telemetry.Count("restore.total.started")
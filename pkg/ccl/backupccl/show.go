@@ -293,6 +293,11 @@ func showBackupPlanHook(
 		if err := checkShowBackupURIPrivileges(ctx, p, dest); err != nil {
 			return err
 		}
+		if kms, ok := opts[backupOptEncKMS]; ok {
+			if err := checkKMSURIPrivileges(ctx, p, []string{kms}, "SHOW BACKUP"); err != nil {
+				return err
+			}
+		}
 
 		fullyResolvedDest := dest
 		if subdir != "" {
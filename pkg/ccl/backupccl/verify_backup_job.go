@@ -0,0 +1,414 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// verifyBackupMaxProblems is the number of problems after which a verification
+// job stops looking for more.
+const verifyBackupMaxProblems = 100
+
+// verifyBackupCheckpointInterval is how often a verification job persists its
+// progress.
+const verifyBackupCheckpointInterval = 10 * time.Second
+
+var errVerifyBackupTooManyProblems = errors.New("too many problems")
+
+type verifyBackupResumer struct {
+	job *jobs.Job
+
+	progress jobspb.VerifyBackupProgress
+}
+
+var _ jobs.Resumer = &verifyBackupResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *verifyBackupResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+	details := r.job.Details().(jobspb.VerifyBackupDetails)
+
+	mem := execCfg.RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+
+	mkStore := execCfg.DistSQLSrv.ExternalStorageFromURI
+	manifests, memSize, err := loadBackupManifests(ctx, &mem, details.URIs, p.User(), mkStore,
+		details.Encryption)
+	if err != nil {
+		return err
+	}
+	defer mem.Shrink(ctx, memSize)
+
+	var encKey []byte
+	if details.Encryption != nil {
+		encKey, err = backupencryption.GetEncryptionKey(ctx, details.Encryption, execCfg.Settings,
+			execCfg.ExternalIODirConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Verification always starts over from the beginning, so discard any
+	// progress from a previous attempt.
+	v := &backupVerifier{
+		job:       r.job,
+		mem:       &mem,
+		user:      p.User(),
+		mkStore:   mkStore,
+		details:   details,
+		manifests: manifests,
+		encKey:    encKey,
+	}
+	verifyErr := v.verify(ctx)
+	if verifyErr != nil && !errors.Is(verifyErr, errVerifyBackupTooManyProblems) {
+		return verifyErr
+	}
+	if err := v.checkpoint(ctx, true /* force */); err != nil {
+		return err
+	}
+	r.progress = v.progress
+
+	if n := len(v.progress.Problems); n > 0 {
+		var buf strings.Builder
+		if verifyErr != nil {
+			fmt.Fprintf(&buf, "verification stopped after finding %d problems in the backup:", n)
+		} else {
+			fmt.Fprintf(&buf, "verification found %d problems in the backup:", n)
+		}
+		for i := range v.progress.Problems {
+			if i == 10 {
+				fmt.Fprintf(&buf, "\n\t... and %d more", n-i)
+				break
+			}
+			buf.WriteString("\n\t")
+			buf.WriteString(describeVerifyBackupProblem(v.progress.Problems[i]))
+		}
+		return jobs.MarkAsPermanentJobError(errors.Newf("%s", buf.String()))
+	}
+	return maybeNotifyScheduledJobCompletion(ctx, r.job, jobs.StatusSucceeded, execCfg)
+}
+
+// ReportResults implements the jobs.JobResultsReporter interface.
+func (r *verifyBackupResumer) ReportResults(
+	ctx context.Context, resultsCh chan<- tree.Datums,
+) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(r.job.ID())),
+		tree.NewDString(string(jobs.StatusSucceeded)),
+		tree.NewDInt(tree.DInt(r.progress.FilesVerified)),
+		tree.NewDInt(tree.DInt(r.progress.BytesVerified)),
+		tree.NewDInt(tree.DInt(r.progress.KeysVerified)),
+	}:
+		return nil
+	}
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. Verification does not
+// modify the backup, so there is nothing to clean up beyond notifying the
+// schedule that created the job, if any.
+func (r *verifyBackupResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
+	if err := maybeNotifyScheduledJobCompletion(ctx, r.job, jobs.StatusFailed,
+		execCtx.(sql.JobExecContext).ExecCfg()); err != nil {
+		log.Errorf(ctx, "failed to notify job %d on completion of OnFailOrCancel: %+v",
+			r.job.ID(), err)
+	}
+	return nil //nolint:returnerrcheck
+}
+
+func describeVerifyBackupProblem(p jobspb.VerifyBackupProgress_Problem) string {
+	if p.Path == "" {
+		return fmt.Sprintf("%s: %s: %s", p.Type, p.Backup, p.Detail)
+	}
+	return fmt.Sprintf("%s: %s/%s: %s", p.Type, strings.TrimSuffix(p.Backup, "/"), p.Path, p.Detail)
+}
+
+// backupVerifier checks the files of a chain of backups.
+type backupVerifier struct {
+	job       *jobs.Job
+	mem       *mon.BoundAccount
+	user      username.SQLUsername
+	mkStore   cloud.ExternalStorageFromURIFactory
+	details   jobspb.VerifyBackupDetails
+	manifests []backuppb.BackupManifest
+	encKey    []byte
+
+	progress       jobspb.VerifyBackupProgress
+	totalFiles     int
+	lastCheckpoint time.Time
+}
+
+func (v *backupVerifier) verify(ctx context.Context) error {
+	pathsByLayer := make([][]string, len(v.manifests))
+	for layer := range v.manifests {
+		seen := make(map[string]struct{})
+		for _, f := range v.manifests[layer].Files {
+			if _, ok := seen[f.Path]; !ok {
+				seen[f.Path] = struct{}{}
+				pathsByLayer[layer] = append(pathsByLayer[layer], f.Path)
+			}
+		}
+		v.totalFiles += len(pathsByLayer[layer])
+	}
+	v.lastCheckpoint = timeutil.Now()
+
+	last := v.manifests[len(v.manifests)-1]
+	if err := checkCoverage(ctx, last.Spans, v.manifests); err != nil {
+		if err := v.addProblem(jobspb.VerifyBackupProgress_Problem_COVERAGE,
+			v.details.URIs[len(v.details.URIs)-1], "", err.Error()); err != nil {
+			return err
+		}
+	}
+
+	for layer := range v.manifests {
+		if err := v.verifyLayer(ctx, layer, pathsByLayer[layer]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *backupVerifier) verifyLayer(ctx context.Context, layer int, paths []string) error {
+	manifest := &v.manifests[layer]
+	defaultURI := v.details.URIs[layer]
+
+	var manifestSpans roachpb.SpanGroup
+	manifestSpans.Add(manifest.Spans...)
+	filesByPath := make(map[string][]*backuppb.BackupManifest_File, len(paths))
+	for i := range manifest.Files {
+		f := &manifest.Files[i]
+		if !manifestSpans.Encloses(f.Span) {
+			if err := v.addProblem(jobspb.VerifyBackupProgress_Problem_COVERAGE, defaultURI, f.Path,
+				fmt.Sprintf("file span %s is not within the spans of the backup", f.Span)); err != nil {
+				return err
+			}
+		}
+		filesByPath[f.Path] = append(filesByPath[f.Path], f)
+	}
+
+	defaultStore, err := v.mkStore(ctx, defaultURI, v.user)
+	if err != nil {
+		return err
+	}
+	defer defaultStore.Close()
+	localityURIs := make(map[string]string)
+	localityStores := make(map[string]cloud.ExternalStorage)
+	defer func() {
+		for _, store := range localityStores {
+			if err := store.Close(); err != nil {
+				log.Warningf(ctx, "close export storage failed %v", err)
+			}
+		}
+	}()
+	if layer < len(v.details.BackupLocalityInfo) {
+		for locality, uri := range v.details.BackupLocalityInfo[layer].URIsByOriginalLocalityKV {
+			store, err := v.mkStore(ctx, uri, v.user)
+			if err != nil {
+				return err
+			}
+			localityStores[locality] = store
+			localityURIs[locality] = uri
+		}
+	}
+
+	for _, path := range paths {
+		files := filesByPath[path]
+		store, uri := defaultStore, defaultURI
+		if s, ok := localityStores[files[0].LocalityKV]; ok {
+			store, uri = s, localityURIs[files[0].LocalityKV]
+		}
+		if err := v.verifyFile(ctx, store, uri, path, files); err != nil {
+			return err
+		}
+		v.progress.FilesVerified++
+		if err := v.checkpoint(ctx, false /* force */); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyFile checks a single SST against the files in the manifest that refer
+// to it.
+func (v *backupVerifier) verifyFile(
+	ctx context.Context,
+	store cloud.ExternalStorage,
+	uri string,
+	path string,
+	files []*backuppb.BackupManifest_File,
+) error {
+	r, err := store.ReadFile(ctx, path)
+	if err != nil {
+		if errors.Is(err, cloud.ErrFileDoesNotExist) {
+			return v.addProblem(jobspb.VerifyBackupProgress_Problem_MISSING, uri, path, err.Error())
+		}
+		return err
+	}
+	raw, err := mon.ReadAll(ctx, r, v.mem)
+	if closeErr := r.Close(ctx); closeErr != nil {
+		log.Warningf(ctx, "failed to close %s: %v", path, closeErr)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", path)
+	}
+	defer v.mem.Shrink(ctx, int64(cap(raw)))
+	v.progress.BytesVerified += int64(len(raw))
+
+	// All files that share a path record the same checksum and size.
+	if expected := files[0].FileSize; expected != 0 && expected != int64(len(raw)) {
+		return v.addProblem(jobspb.VerifyBackupProgress_Problem_SIZE_MISMATCH, uri, path,
+			fmt.Sprintf("expected %d bytes, found %d", expected, len(raw)))
+	}
+	if expected := files[0].Checksum; len(expected) > 0 {
+		if actual := sha256.Sum256(raw); !bytes.Equal(expected, actual[:]) {
+			return v.addProblem(jobspb.VerifyBackupProgress_Problem_CHECKSUM_MISMATCH, uri, path,
+				fmt.Sprintf("expected sha256 %x, found %x", expected, actual))
+		}
+	}
+
+	data := raw
+	if v.encKey != nil {
+		data, err = storageccl.DecryptFile(ctx, raw, v.encKey, v.mem)
+		if err != nil {
+			return v.addProblem(jobspb.VerifyBackupProgress_Problem_UNREADABLE, uri, path,
+				errors.Wrap(err, "decrypting").Error())
+		}
+		defer v.mem.Shrink(ctx, int64(cap(data)))
+	}
+
+	iter, err := storage.NewMemSSTIterator(data, false /* verify */)
+	if err != nil {
+		return v.addProblem(jobspb.VerifyBackupProgress_Problem_UNREADABLE, uri, path, err.Error())
+	}
+	defer iter.Close()
+
+	// Files that share an SST hold disjoint spans of it, so walk them in key
+	// order alongside the SST's keys, recomputing the data size of each.
+	sort.Slice(files, func(i, j int) bool { return files[i].Span.Key.Compare(files[j].Span.Key) < 0 })
+	dataSizes := make([]int64, len(files))
+	var outside int64
+	var firstOutside roachpb.Key
+	cur := 0
+	for iter.SeekGE(storage.MVCCKey{Key: keys.MinKey}); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			return v.addProblem(jobspb.VerifyBackupProgress_Problem_UNREADABLE, uri, path, err.Error())
+		} else if !ok {
+			break
+		}
+		k := iter.UnsafeKey()
+		v.progress.KeysVerified++
+		for cur < len(files) && k.Key.Compare(files[cur].Span.EndKey) >= 0 {
+			cur++
+		}
+		if cur == len(files) || !files[cur].Span.ContainsKey(k.Key) {
+			if outside == 0 {
+				firstOutside = k.Key.Clone()
+			}
+			outside++
+			continue
+		}
+		dataSizes[cur] += int64(len(k.Key) + len(iter.UnsafeValue()))
+	}
+
+	if outside > 0 {
+		if err := v.addProblem(jobspb.VerifyBackupProgress_Problem_KEY_OUTSIDE_SPAN, uri, path,
+			fmt.Sprintf("%d keys are outside the spans recorded for the file, starting at %s",
+				outside, firstOutside)); err != nil {
+			return err
+		}
+	}
+	for i, f := range files {
+		if dataSizes[i] != f.EntryCounts.DataSize {
+			if err := v.addProblem(jobspb.VerifyBackupProgress_Problem_STATS_MISMATCH, uri, path,
+				fmt.Sprintf("span %s has %d bytes of data, expected %d",
+					f.Span, dataSizes[i], f.EntryCounts.DataSize)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addProblem records a problem found in the backup at uri. It returns
+// errVerifyBackupTooManyProblems once verifyBackupMaxProblems problems have
+// been recorded.
+func (v *backupVerifier) addProblem(
+	typ jobspb.VerifyBackupProgress_Problem_Type, uri, path, detail string,
+) error {
+	redacted, err := cloud.SanitizeExternalStorageURI(uri, nil /* extraParams */)
+	if err != nil {
+		return err
+	}
+	v.progress.Problems = append(v.progress.Problems, jobspb.VerifyBackupProgress_Problem{
+		Type:   typ,
+		Backup: redacted,
+		Path:   path,
+		Detail: detail,
+	})
+	if len(v.progress.Problems) >= verifyBackupMaxProblems {
+		return errVerifyBackupTooManyProblems
+	}
+	return nil
+}
+
+// checkpoint persists the verifier's progress, if verifyBackupCheckpointInterval
+// has elapsed since it last did so or force is set.
+func (v *backupVerifier) checkpoint(ctx context.Context, force bool) error {
+	if !force && timeutil.Since(v.lastCheckpoint) < verifyBackupCheckpointInterval {
+		return nil
+	}
+	v.lastCheckpoint = timeutil.Now()
+	var fraction float32 = 1
+	if v.totalFiles > 0 {
+		fraction = float32(v.progress.FilesVerified) / float32(v.totalFiles)
+	}
+	return v.job.FractionProgressed(ctx, nil, /* txn */
+		func(ctx context.Context, details jobspb.ProgressDetails) float32 {
+			*details.(*jobspb.Progress_VerifyBackup).VerifyBackup = v.progress
+			return fraction
+		},
+	)
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeVerifyBackup,
+		func(job *jobs.Job, settings *cluster.Settings) jobs.Resumer {
+			return &verifyBackupResumer{job: job}
+		},
+	)
+}
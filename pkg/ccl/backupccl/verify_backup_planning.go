@@ -0,0 +1,389 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	verifyBackupOp          = "VERIFY BACKUP"
+	verifyBackupOptDetached = "detached"
+)
+
+var verifyBackupOptionExpectValues = map[string]sql.KVStringOptValidate{
+	backupOptEncPassphrase:  sql.KVStringOptRequireValue,
+	backupOptEncKMS:         sql.KVStringOptRequireValue,
+	backupOptIncStorage:     sql.KVStringOptRequireValue,
+	verifyBackupOptDetached: sql.KVStringOptRequireNoValue,
+}
+
+// verifyBackupHeader is the header for VERIFY BACKUP results when the
+// statement waits for the verification job to complete.
+var verifyBackupHeader = colinfo.ResultColumns{
+	{Name: "job_id", Typ: types.Int},
+	{Name: "status", Typ: types.String},
+	{Name: "files", Typ: types.Int},
+	{Name: "bytes", Typ: types.Int},
+	{Name: "keys", Typ: types.Int},
+}
+
+// verifyBackupPlanHook implements sql.PlanHookFn.
+func verifyBackupPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	verifyStmt, ok := stmt.(*tree.VerifyBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+	return planVerifyBackup(ctx, p, verifyStmt, nil /* createdBy */)
+}
+
+// planVerifyBackup plans a VERIFY BACKUP statement. If createdBy is set, the
+// verification job is recorded as having been created by it, e.g. by a
+// schedule.
+func planVerifyBackup(
+	ctx context.Context,
+	p sql.PlanHookState,
+	verifyStmt *tree.VerifyBackup,
+	createdBy *jobs.CreatedByInfo,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureBackupEnabled,
+		verifyBackupOp,
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	subdirFn, err := p.TypeAsString(ctx, verifyStmt.Subdir, verifyBackupOp)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	inColFn, err := p.TypeAsStringArray(ctx, tree.Exprs(verifyStmt.InCollection), verifyBackupOp)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	optsFn, err := p.TypeAsStringOpts(ctx, verifyStmt.Options, verifyBackupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	opts, err := optsFn()
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	_, detached := opts[verifyBackupOptDetached]
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, verifyStmt.StatementTag())
+		defer span.Finish()
+
+		if !(p.ExtendedEvalContext().TxnIsSingleStmt || detached) {
+			return errors.Errorf("VERIFY BACKUP cannot be used inside a multi-statement transaction without DETACHED option")
+		}
+
+		subdir, err := subdirFn()
+		if err != nil {
+			return err
+		}
+		dest, err := inColFn()
+		if err != nil {
+			return err
+		}
+		return doVerifyBackupPlan(ctx, p, subdir, dest, opts, createdBy, resultsCh)
+	}
+
+	if detached {
+		return fn, jobs.DetachedJobExecutionResultHeader, nil, false, nil
+	}
+	return fn, verifyBackupHeader, nil, false, nil
+}
+
+func doVerifyBackupPlan(
+	ctx context.Context,
+	p sql.PlanHookState,
+	subdir string,
+	dest []string,
+	opts map[string]string,
+	createdBy *jobs.CreatedByInfo,
+	resultsCh chan<- tree.Datums,
+) error {
+	if err := checkShowBackupURIPrivileges(ctx, p, dest); err != nil {
+		return err
+	}
+	mkStore := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI
+
+	if strings.EqualFold(subdir, backupbase.LatestFileName) {
+		var err error
+		subdir, err = backupdest.ReadLatestFile(ctx, dest[0], mkStore, p.User())
+		if err != nil {
+			return errors.Wrap(err, "read LATEST path")
+		}
+	}
+	fullyResolvedDest, err := backuputils.AppendPaths(dest, subdir)
+	if err != nil {
+		return err
+	}
+	baseStores := make([]cloud.ExternalStorage, len(fullyResolvedDest))
+	for j := range fullyResolvedDest {
+		baseStores[j], err = mkStore(ctx, fullyResolvedDest[j], p.User())
+		if err != nil {
+			return errors.Wrapf(err, "make storage")
+		}
+		defer baseStores[j].Close()
+	}
+
+	encryption, err := resolveVerifyBackupEncryption(ctx, p, baseStores[0], opts)
+	if err != nil {
+		return err
+	}
+
+	var explicitIncPaths []string
+	if incPath, ok := opts[backupOptIncStorage]; ok {
+		if len(dest) > 1 {
+			return errors.New("VERIFY BACKUP on locality aware backups using incremental_location is" +
+				" not supported yet")
+		}
+		explicitIncPaths = append(explicitIncPaths, incPath)
+	}
+	collection, computedSubdir := backupdest.CollectionAndSubdir(dest[0], subdir)
+	incDirs, err := backupdest.ResolveIncrementalsBackupLocation(
+		ctx,
+		p.User(),
+		p.ExecCfg(),
+		explicitIncPaths,
+		[]string{collection},
+		computedSubdir,
+	)
+	if err != nil {
+		if !errors.Is(err, cloud.ErrListingUnsupported) {
+			return err
+		}
+		log.Warningf(ctx, "storage sink %v does not support listing, only verifying the base backup",
+			explicitIncPaths)
+	}
+
+	mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+	defaultURIs, _, localityInfo, memReserved, err := resolveBackupManifests(
+		ctx, &mem, baseStores, mkStore, fullyResolvedDest, incDirs, hlc.Timestamp{}, encryption, p.User(),
+	)
+	defer mem.Shrink(ctx, memReserved)
+	if err != nil {
+		return err
+	}
+
+	description, err := verifyBackupJobDescription(p, computedSubdir, dest, opts)
+	if err != nil {
+		return err
+	}
+	jr := jobs.Record{
+		Description: description,
+		Username:    p.User(),
+		CreatedBy:   createdBy,
+		Details: jobspb.VerifyBackupDetails{
+			URIs:               defaultURIs,
+			BackupLocalityInfo: localityInfo,
+			Encryption:         encryption,
+		},
+		Progress: jobspb.VerifyBackupProgress{},
+	}
+
+	if _, detached := opts[verifyBackupOptDetached]; detached {
+		jobID := p.ExecCfg().JobRegistry.MakeJobID()
+		if _, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(
+			ctx, jr, jobID, p.Txn(),
+		); err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+		telemetry.Count("verify-backup.detached")
+		return nil
+	}
+
+	// We create the job record in the planner's transaction to ensure that
+	// the job record creation happens transactionally.
+	plannerTxn := p.Txn()
+
+	// Construct the job and commit the transaction. Perform this work in a
+	// closure to ensure that the job is cleaned up if an error occurs.
+	var sj *jobs.StartableJob
+	if err := func() (err error) {
+		defer func() {
+			if err == nil || sj == nil {
+				return
+			}
+			if cleanupErr := sj.CleanupOnRollback(ctx); cleanupErr != nil {
+				log.Errorf(ctx, "failed to cleanup job: %v", cleanupErr)
+			}
+		}()
+		jobID := p.ExecCfg().JobRegistry.MakeJobID()
+		if err := p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(ctx, &sj, jobID, plannerTxn, jr); err != nil {
+			return err
+		}
+		// We commit the transaction here so that the job can be started. This is
+		// safe because we're in an implicit transaction. If we were in an explicit
+		// transaction the job would have to be created with the detached option and
+		// would have been handled above.
+		return plannerTxn.Commit(ctx)
+	}(); err != nil {
+		return err
+	}
+	telemetry.Count("verify-backup.total")
+	if err := sj.Start(ctx); err != nil {
+		return err
+	}
+	if err := sj.AwaitCompletion(ctx); err != nil {
+		return err
+	}
+	return sj.ReportExecutionResults(ctx, resultsCh)
+}
+
+// resolveVerifyBackupEncryption returns the encryption options needed to read
+// the backup in baseStore, as specified by the encryption_passphrase or kms
+// option, or nil if neither is set.
+func resolveVerifyBackupEncryption(
+	ctx context.Context, p sql.PlanHookState, baseStore cloud.ExternalStorage, opts map[string]string,
+) (*jobspb.BackupEncryptionOptions, error) {
+	passphrase, hasPassphrase := opts[backupOptEncPassphrase]
+	kms, hasKMS := opts[backupOptEncKMS]
+	if !hasPassphrase && !hasKMS {
+		return nil, nil
+	}
+	if hasPassphrase && hasKMS {
+		return nil, errors.New("cannot have both encryption_passphrase and kms option set")
+	}
+	if hasKMS {
+		if err := checkKMSURIPrivileges(ctx, p, []string{kms}, verifyBackupOp); err != nil {
+			return nil, err
+		}
+	}
+
+	encFiles, err := backupencryption.ReadEncryptionOptions(ctx, baseStore)
+	if err != nil {
+		return nil, err
+	}
+	if hasPassphrase {
+		return &jobspb.BackupEncryptionOptions{
+			Mode: jobspb.EncryptionMode_Passphrase,
+			Key:  storageccl.GenerateKey([]byte(passphrase), encFiles[0].Salt),
+		}, nil
+	}
+
+	env := &backupencryption.BackupKMSEnv{
+		Settings: p.ExecCfg().Settings,
+		Conf:     &p.ExecCfg().ExternalIODirConfig,
+	}
+	var kmsInfo *jobspb.BackupEncryptionOptions_KMSInfo
+	for _, encFile := range encFiles {
+		kmsInfo, err = backupencryption.ValidateKMSURIsAgainstFullBackup(ctx, []string{kms},
+			backupencryption.NewEncryptedDataKeyMapFromProtoMap(encFile.EncryptedDataKeyByKMSMasterKeyID), env)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &jobspb.BackupEncryptionOptions{
+		Mode:    jobspb.EncryptionMode_KMS,
+		KMSInfo: kmsInfo,
+	}, nil
+}
+
+// verifyBackupJobDescription returns the description of a verification job,
+// with secrets redacted from the collection URIs and options.
+func verifyBackupJobDescription(
+	p sql.PlanHookState, subdir string, dest []string, opts map[string]string,
+) (string, error) {
+	node, err := makeRedactedVerifyBackupNode(subdir, dest, opts)
+	if err != nil {
+		return "", err
+	}
+	ann := p.ExtendedEvalContext().Annotations
+	return tree.AsStringWithFQNames(node, ann), nil
+}
+
+// makeRedactedVerifyBackupNode returns a VERIFY BACKUP statement for the given
+// evaluated arguments, with secrets redacted from the collection URIs and
+// options.
+func makeRedactedVerifyBackupNode(
+	subdir string, dest []string, opts map[string]string,
+) (*tree.VerifyBackup, error) {
+	inCollection, err := sanitizeURIList(dest)
+	if err != nil {
+		return nil, err
+	}
+	redactedOpts := make(map[string]string, len(opts))
+	for k, v := range opts {
+		switch k {
+		case backupOptEncPassphrase:
+			v = "redacted"
+		case backupOptEncKMS:
+			if v, err = cloud.RedactKMSURI(v); err != nil {
+				return nil, err
+			}
+		case backupOptIncStorage:
+			if v, err = cloud.SanitizeExternalStorageURI(v, nil /* extraParams */); err != nil {
+				return nil, err
+			}
+		}
+		redactedOpts[k] = v
+	}
+	return &tree.VerifyBackup{
+		Subdir:       tree.NewDString(subdir),
+		InCollection: inCollection,
+		Options:      makeVerifyBackupOptions(redactedOpts),
+	}, nil
+}
+
+// makeVerifyBackupOptions converts evaluated VERIFY BACKUP options back into
+// KVOptions, in a deterministic order.
+func makeVerifyBackupOptions(opts map[string]string) tree.KVOptions {
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvOpts := make(tree.KVOptions, 0, len(keys))
+	for _, k := range keys {
+		opt := tree.KVOption{Key: tree.Name(k)}
+		if verifyBackupOptionExpectValues[k] == sql.KVStringOptRequireValue {
+			opt.Value = tree.NewStrVal(opts[k])
+		}
+		kvOpts = append(kvOpts, opt)
+	}
+	return kvOpts
+}
+
+func init() {
+	sql.AddPlanHook("verify backup", verifyBackupPlanHook)
+}
@@ -0,0 +1,449 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+const scheduleVerifyBackupOp = "CREATE SCHEDULE FOR VERIFY BACKUP"
+
+// createVerifyBackupScheduleHook implements sql.PlanHookFn for
+// CREATE SCHEDULE FOR VERIFY BACKUP.
+func createVerifyBackupScheduleHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	schedule, ok := stmt.(*tree.ScheduledVerifyBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	var labelFn func() (string, error)
+	if schedule.ScheduleLabelSpec.Label != nil {
+		var err error
+		labelFn, err = p.TypeAsString(ctx, schedule.ScheduleLabelSpec.Label, scheduleVerifyBackupOp)
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	if schedule.Recurrence == nil {
+		return nil, nil, nil, false, errors.New("RECURRING must be specified for VERIFY BACKUP schedules")
+	}
+	recurrenceFn, err := p.TypeAsString(ctx, schedule.Recurrence, scheduleVerifyBackupOp)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	subdirFn, err := p.TypeAsString(ctx, schedule.Verify.Subdir, scheduleVerifyBackupOp)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	inColFn, err := p.TypeAsStringArray(
+		ctx, tree.Exprs(schedule.Verify.InCollection), scheduleVerifyBackupOp)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	verifyOptsFn, err := p.TypeAsStringOpts(
+		ctx, schedule.Verify.Options, verifyBackupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	scheduleOptsFn, err := p.TypeAsStringOpts(
+		ctx, schedule.ScheduleOptions, scheduledBackupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		if err := p.RequireAdminRole(ctx, scheduleVerifyBackupOp); err != nil {
+			return err
+		}
+
+		env := sql.JobSchedulerEnv(p.ExecCfg())
+		scheduleLabel := fmt.Sprintf("VERIFY BACKUP %d", env.Now().Unix())
+		if labelFn != nil {
+			label, err := labelFn()
+			if err != nil {
+				return err
+			}
+			scheduleLabel = label
+		}
+		if schedule.ScheduleLabelSpec.IfNotExists {
+			exists, err := checkScheduleAlreadyExists(ctx, p, scheduleLabel)
+			if err != nil {
+				return err
+			}
+			if exists {
+				p.BufferClientNotice(ctx,
+					pgnotice.Newf("schedule %q already exists, skipping", scheduleLabel),
+				)
+				return nil
+			}
+		}
+
+		recurrence, err := computeScheduleRecurrence(env.Now(), recurrenceFn)
+		if err != nil {
+			return err
+		}
+		subdir, err := subdirFn()
+		if err != nil {
+			return err
+		}
+		dest, err := inColFn()
+		if err != nil {
+			return err
+		}
+		verifyOpts, err := verifyOptsFn()
+		if err != nil {
+			return err
+		}
+		scheduleOpts, err := scheduleOptsFn()
+		if err != nil {
+			return err
+		}
+		if _, ignore := scheduleOpts[optIgnoreExistingBackups]; ignore {
+			return errors.Newf("%s is not supported for VERIFY BACKUP schedules", optIgnoreExistingBackups)
+		}
+		if _, updates := scheduleOpts[optUpdatesLastBackupMetric]; updates {
+			return errors.Newf("%s is not supported for VERIFY BACKUP schedules", optUpdatesLastBackupMetric)
+		}
+
+		// The scheduled verification always runs detached.
+		verifyOpts[verifyBackupOptDetached] = ""
+		verifyNode := &tree.VerifyBackup{
+			Subdir:  tree.NewStrVal(subdir),
+			Options: makeVerifyBackupOptions(verifyOpts),
+		}
+		for _, uri := range dest {
+			verifyNode.InCollection = append(verifyNode.InCollection, tree.NewStrVal(uri))
+		}
+
+		// Plan and run the verification under a savepoint to validate the
+		// destination and options, then discard the job it created.
+		if err := dryRunVerifyBackup(ctx, p, verifyNode); err != nil {
+			return errors.Wrapf(err, "failed to dry run verify backup")
+		}
+
+		evalCtx := &p.ExtendedEvalContext().Context
+		firstRun, err := scheduleFirstRun(evalCtx, scheduleOpts)
+		if err != nil {
+			return err
+		}
+		details, err := makeScheduleDetails(scheduleOpts)
+		if err != nil {
+			return err
+		}
+
+		sj := jobs.NewScheduledJob(env)
+		sj.SetScheduleLabel(scheduleLabel)
+		sj.SetOwner(p.User())
+		if err := sj.SetSchedule(recurrence.cron); err != nil {
+			return err
+		}
+		sj.SetScheduleDetails(details)
+		any, err := pbtypes.MarshalAny(&backuppb.ScheduledVerifyBackupExecutionArgs{
+			VerifyStatement: tree.AsStringWithFlags(verifyNode, tree.FmtParsable|tree.FmtShowPasswords),
+		})
+		if err != nil {
+			return err
+		}
+		sj.SetExecutionDetails(
+			tree.ScheduledVerifyBackupExecutor.InternalName(), jobspb.ExecutionArguments{Args: any},
+		)
+		if firstRun != nil {
+			sj.SetNextRun(*firstRun)
+		}
+		if err := sj.Create(ctx, p.ExecCfg().InternalExecutor, p.Txn()); err != nil {
+			return err
+		}
+		telemetry.Count("scheduled-verify-backup.create.success")
+
+		redacted, err := makeRedactedVerifyBackupNode(subdir, dest, verifyOpts)
+		if err != nil {
+			return err
+		}
+		next, err := tree.MakeDTimestampTZ(sj.NextRun(), time.Microsecond)
+		if err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{
+			tree.NewDInt(tree.DInt(sj.ScheduleID())),
+			tree.NewDString(sj.ScheduleLabel()),
+			tree.NewDString("ACTIVE"),
+			next,
+			tree.NewDString(sj.ScheduleExpr()),
+			tree.NewDString(tree.AsString(redacted)),
+		}
+		return nil
+	}
+	return fn, scheduledBackupHeader, nil, false, nil
+}
+
+// dryRunVerifyBackup plans and runs a detached VERIFY BACKUP under a
+// transaction savepoint, and then rolls back to that savepoint.
+func dryRunVerifyBackup(
+	ctx context.Context, p sql.PlanHookState, verifyNode *tree.VerifyBackup,
+) error {
+	sp, err := p.Txn().CreateSavepoint(ctx)
+	if err != nil {
+		return err
+	}
+	err = func() error {
+		fn, _, _, _, err := planVerifyBackup(ctx, p, verifyNode, nil /* createdBy */)
+		if err != nil {
+			return err
+		}
+		return invokeBackup(ctx, fn)
+	}()
+	if rollbackErr := p.Txn().RollbackToSavepoint(ctx, sp); rollbackErr != nil {
+		return rollbackErr
+	}
+	return err
+}
+
+type verifyBackupScheduleMetrics struct {
+	*jobs.ExecutorMetrics
+}
+
+var _ metric.Struct = &verifyBackupScheduleMetrics{}
+
+// MetricStruct implements metric.Struct interface.
+func (m *verifyBackupScheduleMetrics) MetricStruct() {}
+
+// scheduledVerifyBackupExecutor is executed by the job scheduler to start
+// VERIFY BACKUP jobs.
+type scheduledVerifyBackupExecutor struct {
+	metrics verifyBackupScheduleMetrics
+}
+
+var _ jobs.ScheduledJobExecutor = &scheduledVerifyBackupExecutor{}
+
+// ExecuteJob implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledVerifyBackupExecutor) ExecuteJob(
+	ctx context.Context,
+	cfg *scheduledjobs.JobExecutionConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	txn *kv.Txn,
+) error {
+	if err := e.executeVerifyBackup(ctx, cfg, sj, txn); err != nil {
+		e.metrics.NumFailed.Inc(1)
+		return err
+	}
+	e.metrics.NumStarted.Inc(1)
+	return nil
+}
+
+func (e *scheduledVerifyBackupExecutor) executeVerifyBackup(
+	ctx context.Context, cfg *scheduledjobs.JobExecutionConfig, sj *jobs.ScheduledJob, txn *kv.Txn,
+) error {
+	verifyNode, err := extractVerifyBackupStatement(sj)
+	if err != nil {
+		return err
+	}
+
+	// Sanity check: verification should be detached.
+	hasDetached := false
+	for _, opt := range verifyNode.Options {
+		if opt.Key == verifyBackupOptDetached {
+			hasDetached = true
+		}
+	}
+	if !hasDetached {
+		verifyNode.Options = append(verifyNode.Options, tree.KVOption{Key: verifyBackupOptDetached})
+		log.Warningf(ctx, "force setting detached option for verify backup schedule %d",
+			sj.ScheduleID())
+	}
+
+	log.Infof(ctx, "Starting scheduled backup verification %d", sj.ScheduleID())
+
+	hook, cleanup := cfg.PlanHookMaker("exec-verify-backup", txn, sj.Owner())
+	defer cleanup()
+	fn, _, _, _, err := planVerifyBackup(ctx, hook.(sql.PlanHookState), verifyNode,
+		&jobs.CreatedByInfo{
+			Name: jobs.CreatedByScheduledJobs,
+			ID:   sj.ScheduleID(),
+		})
+	if err != nil {
+		return errors.Wrapf(err, "verify backup eval")
+	}
+	return invokeBackup(ctx, fn)
+}
+
+// NotifyJobTermination implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledVerifyBackupExecutor) NotifyJobTermination(
+	ctx context.Context,
+	jobID jobspb.JobID,
+	jobStatus jobs.Status,
+	details jobspb.Details,
+	env scheduledjobs.JobSchedulerEnv,
+	schedule *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+	txn *kv.Txn,
+) error {
+	if jobStatus == jobs.StatusSucceeded {
+		e.metrics.NumSucceeded.Inc(1)
+		log.Infof(ctx, "verify backup job %d scheduled by %d succeeded", jobID, schedule.ScheduleID())
+		return nil
+	}
+
+	e.metrics.NumFailed.Inc(1)
+	err := errors.Errorf(
+		"verify backup job %d scheduled by %d failed with status %s",
+		jobID, schedule.ScheduleID(), jobStatus)
+	log.Errorf(ctx, "verify backup error: %v", err)
+	jobs.DefaultHandleFailedRun(schedule, "verify backup job %d failed with err=%v", jobID, err)
+	return nil
+}
+
+// Metrics implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledVerifyBackupExecutor) Metrics() metric.Struct {
+	return &e.metrics
+}
+
+// GetCreateScheduleStatement implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledVerifyBackupExecutor) GetCreateScheduleStatement(
+	ctx context.Context,
+	env scheduledjobs.JobSchedulerEnv,
+	txn *kv.Txn,
+	descsCol *descs.Collection,
+	sj *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+) (string, error) {
+	verifyNode, err := extractVerifyBackupStatement(sj)
+	if err != nil {
+		return "", err
+	}
+	subdir, dest, opts, err := verifyBackupNodeArgs(verifyNode)
+	if err != nil {
+		return "", err
+	}
+	redacted, err := makeRedactedVerifyBackupNode(subdir, dest, opts)
+	if err != nil {
+		return "", err
+	}
+
+	onError, err := parseOnErrorOption(sj.ScheduleDetails().OnError)
+	if err != nil {
+		return "", err
+	}
+	wait, err := parseOnPreviousRunningOption(sj.ScheduleDetails().Wait)
+	if err != nil {
+		return "", err
+	}
+	firstRunTime := sj.ScheduledRunTime()
+	if firstRunTime.IsZero() {
+		firstRunTime = env.Now()
+	}
+	firstRun, err := tree.MakeDTimestampTZ(firstRunTime, time.Microsecond)
+	if err != nil {
+		return "", err
+	}
+
+	node := &tree.ScheduledVerifyBackup{
+		ScheduleLabelSpec: tree.ScheduleLabelSpec{
+			IfNotExists: false, Label: tree.NewDString(sj.ScheduleLabel()),
+		},
+		Recurrence: tree.NewDString(sj.ScheduleExpr()),
+		Verify:     redacted,
+		ScheduleOptions: tree.KVOptions{
+			{Key: optFirstRun, Value: firstRun},
+			{Key: optOnExecFailure, Value: tree.NewDString(onError)},
+			{Key: optOnPreviousRunning, Value: tree.NewDString(wait)},
+		},
+	}
+	return tree.AsString(node), nil
+}
+
+// extractVerifyBackupStatement returns the tree.VerifyBackup node encoded
+// inside the scheduled job.
+func extractVerifyBackupStatement(sj *jobs.ScheduledJob) (*tree.VerifyBackup, error) {
+	args := &backuppb.ScheduledVerifyBackupExecutionArgs{}
+	if err := pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args); err != nil {
+		return nil, errors.Wrap(err, "un-marshaling args")
+	}
+
+	node, err := parser.ParseOne(args.VerifyStatement)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing verify backup statement")
+	}
+	if verifyNode, ok := node.AST.(*tree.VerifyBackup); ok {
+		return verifyNode, nil
+	}
+	return nil, errors.Newf("unexpect node type %T", node.AST)
+}
+
+// verifyBackupNodeArgs returns the arguments of a VERIFY BACKUP statement
+// that, like those stored in schedules, only contains string literals.
+func verifyBackupNodeArgs(
+	node *tree.VerifyBackup,
+) (subdir string, dest []string, opts map[string]string, _ error) {
+	strVal := func(e tree.Expr) (string, error) {
+		s, ok := e.(*tree.StrVal)
+		if !ok {
+			return "", errors.AssertionFailedf("expected string literal, found %T", e)
+		}
+		return s.RawString(), nil
+	}
+	subdir, err := strVal(node.Subdir)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	for _, e := range node.InCollection {
+		uri, err := strVal(e)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		dest = append(dest, uri)
+	}
+	opts = make(map[string]string, len(node.Options))
+	for _, opt := range node.Options {
+		var v string
+		if opt.Value != nil {
+			if v, err = strVal(opt.Value); err != nil {
+				return "", nil, nil, err
+			}
+		}
+		opts[string(opt.Key)] = v
+	}
+	return subdir, dest, opts, nil
+}
+
+func init() {
+	sql.AddPlanHook("schedule verify backup", createVerifyBackupScheduleHook)
+	jobs.RegisterScheduledJobExecutorFactory(
+		tree.ScheduledVerifyBackupExecutor.InternalName(),
+		func() (jobs.ScheduledJobExecutor, error) {
+			m := jobs.MakeExecutorMetrics(tree.ScheduledVerifyBackupExecutor.InternalName())
+			return &scheduledVerifyBackupExecutor{
+				metrics: verifyBackupScheduleMetrics{
+					ExecutorMetrics: &m,
+				},
+			}, nil
+		})
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifyBackup checks that VERIFY BACKUP succeeds on an intact backup and
+// reports SSTs that have been truncated, corrupted or removed.
+func TestVerifyBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 100
+	_, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, localFoo)
+	verify := fmt.Sprintf(`VERIFY BACKUP FROM LATEST IN '%s'`, localFoo)
+
+	var jobID, files, bytes, keys int64
+	var status string
+	sqlDB.QueryRow(t, verify).Scan(&jobID, &status, &files, &bytes, &keys)
+	require.Equal(t, "succeeded", status)
+	require.Greater(t, files, int64(0))
+	require.Greater(t, bytes, int64(0))
+	require.GreaterOrEqual(t, keys, int64(numAccounts))
+
+	var ssts []string
+	require.NoError(t, filepath.Walk(filepath.Join(dir, "foo"),
+		func(path string, info os.FileInfo, err error) error {
+			if err == nil && strings.HasSuffix(path, ".sst") {
+				ssts = append(ssts, path)
+			}
+			return err
+		}))
+	require.NotEmpty(t, ssts)
+	sst := ssts[0]
+	orig, err := ioutil.ReadFile(sst)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, ioutil.WriteFile(sst, orig, 0644)) }()
+
+	t.Run("size mismatch", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(sst, orig[:len(orig)-1], 0644))
+		sqlDB.ExpectErr(t, "SIZE_MISMATCH", verify)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		corrupt := append([]byte(nil), orig...)
+		corrupt[len(corrupt)/2] ^= 0xff
+		require.NoError(t, ioutil.WriteFile(sst, corrupt, 0644))
+		sqlDB.ExpectErr(t, "CHECKSUM_MISMATCH", verify)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		require.NoError(t, os.Remove(sst))
+		sqlDB.ExpectErr(t, "MISSING", verify)
+	})
+}
+
+// TestCreateVerifyBackupSchedule checks that a VERIFY BACKUP schedule is
+// created with a redacted statement and always runs detached.
+func TestCreateVerifyBackupSchedule(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	_, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, 1, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1 WITH encryption_passphrase = 'abc'`, localFoo)
+
+	var id int64
+	var label, status, schedule, stmt string
+	var firstRun interface{}
+	sqlDB.QueryRow(t, fmt.Sprintf(
+		`CREATE SCHEDULE 'verify' FOR VERIFY BACKUP FROM LATEST IN '%s' WITH encryption_passphrase = 'abc' RECURRING '@daily'`,
+		localFoo,
+	)).Scan(&id, &label, &status, &firstRun, &schedule, &stmt)
+	require.Equal(t, "verify", label)
+	require.Equal(t, "ACTIVE", status)
+	require.Equal(t, "@daily", schedule)
+	require.Contains(t, stmt, "encryption_passphrase = 'redacted'")
+	require.Contains(t, stmt, "detached")
+	require.NotContains(t, stmt, "abc")
+
+	// A schedule with the same label is skipped if IF NOT EXISTS is specified.
+	sqlDB.Exec(t, fmt.Sprintf(
+		`CREATE SCHEDULE IF NOT EXISTS 'verify' FOR VERIFY BACKUP FROM LATEST IN '%s' RECURRING '@daily'`,
+		localFoo,
+	))
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM [SHOW SCHEDULES] WHERE label = 'verify'`, [][]string{{"1"}})
+
+	// The schedule can't be created if the backup can't be verified.
+	sqlDB.ExpectErr(t, "failed to dry run verify backup", fmt.Sprintf(
+		`CREATE SCHEDULE FOR VERIFY BACKUP FROM LATEST IN '%s' WITH encryption_passphrase = 'wrong' RECURRING '@daily'`,
+		localFoo,
+	))
+}
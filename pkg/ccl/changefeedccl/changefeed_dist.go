@@ -90,6 +90,7 @@ func distChangefeedFlow(
 	execCfg := execCtx.ExecCfg()
 	var initialHighWater hlc.Timestamp
 	var trackedSpans []roachpb.Span
+	var rangeFeedFilter *roachpb.RangeFeedFilter
 	{
 		spansTS := details.StatementTime
 		if h := progress.GetHighWater(); h != nil && !h.IsEmpty() {
@@ -135,6 +136,7 @@ func distChangefeedFlow(
 				trackedSpans = append(trackedSpans, d.PrimaryIndexSpan(execCfg.Codec))
 			}
 		}
+		rangeFeedFilter = rangeFeedFilterForTargets(AllTargets(details), tableDescs)
 	}
 
 	var checkpoint jobspb.ChangefeedProgress_Checkpoint
@@ -148,7 +150,46 @@ func distChangefeedFlow(
 	}
 
 	return changefeeddist.StartDistChangefeed(
-		ctx, execCtx, jobID, details, trackedSpans, initialHighWater, checkpoint, resultsCh, distflowKnobs)
+		ctx, execCtx, jobID, details, trackedSpans, rangeFeedFilter, initialHighWater, checkpoint,
+		resultsCh, distflowKnobs)
+}
+
+// rangeFeedFilterForTargets returns a filter restricting the rangefeeds of a
+// changefeed to the column families it watches, or nil if the changefeed
+// watches all the families of any of its tables.
+func rangeFeedFilterForTargets(
+	targets []jobspb.ChangefeedTargetSpecification, tableDescs []catalog.TableDescriptor,
+) *roachpb.RangeFeedFilter {
+	byID := make(map[descpb.ID]catalog.TableDescriptor, len(tableDescs))
+	for _, d := range tableDescs {
+		byID[d.GetID()] = d
+	}
+	var filter roachpb.RangeFeedFilter
+	for _, t := range targets {
+		if t.Type != jobspb.ChangefeedTargetSpecification_COLUMN_FAMILY {
+			return nil
+		}
+		d, ok := byID[t.TableID]
+		if !ok {
+			return nil
+		}
+		found := false
+		for _, fam := range d.GetFamilies() {
+			if fam.Name == t.FamilyName {
+				filter.FamilyIDs = append(filter.FamilyIDs, uint32(fam.ID))
+				found = true
+				break
+			}
+		}
+		if !found {
+			// Let the changefeed surface the missing family itself.
+			return nil
+		}
+	}
+	if len(filter.FamilyIDs) == 0 {
+		return nil
+	}
+	return &filter
 }
 
 func fetchTableDescriptors(
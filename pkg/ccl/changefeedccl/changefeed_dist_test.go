@@ -0,0 +1,82 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeFeedFilterForTargets(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	mkTable := func(id descpb.ID, families ...string) catalog.TableDescriptor {
+		desc := descpb.TableDescriptor{ID: id, Name: "t"}
+		for i, name := range families {
+			desc.Families = append(desc.Families, descpb.ColumnFamilyDescriptor{
+				ID: descpb.FamilyID(i), Name: name,
+			})
+		}
+		return tabledesc.NewBuilder(&desc).BuildImmutableTable()
+	}
+	tables := []catalog.TableDescriptor{
+		mkTable(1, "primary", "a", "b"),
+		mkTable(2, "primary", "c"),
+	}
+	family := func(id descpb.ID, name string) jobspb.ChangefeedTargetSpecification {
+		return jobspb.ChangefeedTargetSpecification{
+			Type: jobspb.ChangefeedTargetSpecification_COLUMN_FAMILY, TableID: id, FamilyName: name,
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		targets  []jobspb.ChangefeedTargetSpecification
+		expected *roachpb.RangeFeedFilter
+	}{
+		{
+			name:     "single family",
+			targets:  []jobspb.ChangefeedTargetSpecification{family(1, "b")},
+			expected: &roachpb.RangeFeedFilter{FamilyIDs: []uint32{2}},
+		},
+		{
+			name:     "families of several tables",
+			targets:  []jobspb.ChangefeedTargetSpecification{family(1, "primary"), family(2, "c")},
+			expected: &roachpb.RangeFeedFilter{FamilyIDs: []uint32{0, 1}},
+		},
+		{
+			name: "whole table",
+			targets: []jobspb.ChangefeedTargetSpecification{
+				family(1, "a"),
+				{Type: jobspb.ChangefeedTargetSpecification_EACH_FAMILY, TableID: 2},
+			},
+		},
+		{
+			name:    "unknown family",
+			targets: []jobspb.ChangefeedTargetSpecification{family(1, "missing")},
+		},
+		{
+			name:    "unknown table",
+			targets: []jobspb.ChangefeedTargetSpecification{family(3, "a")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, rangeFeedFilterForTargets(tc.targets, tables))
+		})
+	}
+}
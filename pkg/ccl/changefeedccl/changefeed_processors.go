@@ -371,6 +371,10 @@ func (ca *changeAggregator) makeKVFeedCfg(
 		return kvfeed.Config{}, err
 	}
 	filters := opts.GetFilters()
+	catchUpScanPriority, err := opts.GetCatchUpScanPriority()
+	if err != nil {
+		return kvfeed.Config{}, err
+	}
 	cfg := ca.flowCtx.Cfg
 
 	var sf schemafeed.SchemaFeed
@@ -402,6 +406,8 @@ func (ca *changeAggregator) makeKVFeedCfg(
 		InitialHighWater:        initialHighWater,
 		EndTime:                 endTime,
 		WithDiff:                filters.WithDiff,
+		RangeFeedFilter:         ca.spec.RangeFeedFilter,
+		CatchUpScanPriority:     catchUpScanPriority,
 		NeedsInitialScan:        needsInitialScan,
 		SchemaChangeEvents:      schemaChange.EventClass,
 		SchemaChangePolicy:      schemaChange.Policy,
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/util/admission/admissionpb"
 	"github.com/cockroachdb/errors"
 )
 
@@ -59,6 +60,10 @@ type VirtualColumnVisibility string
 // initial scan, and the type of initial scan that it will perform
 type InitialScanType int
 
+// CatchUpScanPriority configures the admission control priority of the
+// catch-up scans of the changefeed's rangefeeds.
+type CatchUpScanPriority string
+
 // SinkSpecificJSONConfig is a JSON string that the sink is responsible
 // for parsing, validating, and honoring.
 type SinkSpecificJSONConfig string
@@ -97,6 +102,7 @@ const (
 	OptMetricsScope             = `metrics_label`
 	OptVirtualColumns           = `virtual_columns`
 	OptPrimaryKeyFilter         = `primary_key_filter`
+	OptCatchUpScanPriority      = `catchup_scan_priority`
 
 	OptVirtualColumnsOmitted VirtualColumnVisibility = `omitted`
 	OptVirtualColumnsNull    VirtualColumnVisibility = `null`
@@ -149,6 +155,14 @@ const (
 	OptOnErrorFail  OnErrorType = `fail`
 	OptOnErrorPause OnErrorType = `pause`
 
+	// OptCatchUpScanPriorityLow runs catch-up scans below bulk work, such as
+	// backups and imports.
+	OptCatchUpScanPriorityLow CatchUpScanPriority = `low`
+	// OptCatchUpScanPriorityNormal runs catch-up scans as bulk work, below
+	// foreground traffic. This is the default. Catch-up scans are elastic
+	// work, so there is no option to run them alongside foreground traffic.
+	OptCatchUpScanPriorityNormal CatchUpScanPriority = `normal`
+
 	DeprecatedOptFormatAvro                   = `experimental_avro`
 	DeprecatedSinkSchemeCloudStorageAzure     = `experimental-azure`
 	DeprecatedSinkSchemeCloudStorageGCS       = `experimental-gs`
@@ -165,34 +179,37 @@ const (
 	// Note that this option is only allowed for alter changefeed statements.
 	OptSink = `sink`
 
-	SinkParamCACert                 = `ca_cert`
-	SinkParamClientCert             = `client_cert`
-	SinkParamClientKey              = `client_key`
-	SinkParamFileSize               = `file_size`
-	SinkParamPartitionFormat        = `partition_format`
-	SinkParamSchemaTopic            = `schema_topic`
-	SinkParamTLSEnabled             = `tls_enabled`
-	SinkParamSkipTLSVerify          = `insecure_tls_skip_verify`
-	SinkParamTopicPrefix            = `topic_prefix`
-	SinkParamTopicName              = `topic_name`
-	SinkSchemeCloudStorageAzure     = `azure`
-	SinkSchemeCloudStorageGCS       = `gs`
-	SinkSchemeCloudStorageHTTP      = `http`
-	SinkSchemeCloudStorageHTTPS     = `https`
-	SinkSchemeCloudStorageNodelocal = `nodelocal`
-	SinkSchemeCloudStorageS3        = `s3`
-	SinkSchemeExperimentalSQL       = `experimental-sql`
-	SinkSchemeHTTP                  = `http`
-	SinkSchemeHTTPS                 = `https`
-	SinkSchemeKafka                 = `kafka`
-	SinkSchemeNull                  = `null`
-	SinkSchemeWebhookHTTP           = `webhook-http`
-	SinkSchemeWebhookHTTPS          = `webhook-https`
-	SinkParamSASLEnabled            = `sasl_enabled`
-	SinkParamSASLHandshake          = `sasl_handshake`
-	SinkParamSASLUser               = `sasl_user`
-	SinkParamSASLPassword           = `sasl_password`
-	SinkParamSASLMechanism          = `sasl_mechanism`
+	SinkParamCACert                  = `ca_cert`
+	SinkParamClientCert              = `client_cert`
+	SinkParamClientKey               = `client_key`
+	SinkParamFileSize                = `file_size`
+	SinkParamPartitionFormat         = `partition_format`
+	SinkParamSchemaTopic             = `schema_topic`
+	SinkParamTLSEnabled              = `tls_enabled`
+	SinkParamSkipTLSVerify           = `insecure_tls_skip_verify`
+	SinkParamTopicPrefix             = `topic_prefix`
+	SinkParamTopicName               = `topic_name`
+	SinkSchemeCloudStorageAzure      = `azure`
+	SinkSchemeCloudStorageGCS        = `gs`
+	SinkSchemeCloudStorageHTTP       = `http`
+	SinkSchemeCloudStorageHTTPS      = `https`
+	SinkSchemeCloudStorageNodelocal  = `nodelocal`
+	SinkSchemeCloudStorageS3         = `s3`
+	SinkSchemeCloudStorageSFTP       = `sftp`
+	SinkSchemeCloudStorageWebDAV     = `webdav`
+	SinkSchemeCloudStorageWebDAVHTTP = `webdav+http`
+	SinkSchemeExperimentalSQL        = `experimental-sql`
+	SinkSchemeHTTP                   = `http`
+	SinkSchemeHTTPS                  = `https`
+	SinkSchemeKafka                  = `kafka`
+	SinkSchemeNull                   = `null`
+	SinkSchemeWebhookHTTP            = `webhook-http`
+	SinkSchemeWebhookHTTPS           = `webhook-https`
+	SinkParamSASLEnabled             = `sasl_enabled`
+	SinkParamSASLHandshake           = `sasl_handshake`
+	SinkParamSASLUser                = `sasl_user`
+	SinkParamSASLPassword            = `sasl_password`
+	SinkParamSASLMechanism           = `sasl_mechanism`
 
 	RegistryParamCACert = `ca_cert`
 
@@ -314,6 +331,7 @@ var ChangefeedOptionExpectValues = map[string]OptionPermittedValues{
 	OptMetricsScope:             stringOption,
 	OptVirtualColumns:           enum("omitted", "null"),
 	OptPrimaryKeyFilter:         stringOption,
+	OptCatchUpScanPriority:      enum("low", "normal"),
 }
 
 // CommonOptions is options common to all sinks
@@ -325,7 +343,8 @@ var CommonOptions = makeStringSet(OptCursor, OptEndTime, OptEnvelope,
 	OptSchemaChangeEvents, OptSchemaChangePolicy,
 	OptProtectDataFromGCOnPause, OptOnError,
 	OptInitialScan, OptNoInitialScan, OptInitialScanOnly,
-	OptMinCheckpointFrequency, OptMetricsScope, OptVirtualColumns, Topics, OptPrimaryKeyFilter)
+	OptMinCheckpointFrequency, OptMetricsScope, OptVirtualColumns, Topics, OptPrimaryKeyFilter,
+	OptCatchUpScanPriority)
 
 // SQLValidOptions is options exclusive to SQL sink
 var SQLValidOptions map[string]struct{} = nil
@@ -344,7 +363,7 @@ var PubsubValidOptions = makeStringSet()
 
 // CaseInsensitiveOpts options which supports case Insensitive value
 var CaseInsensitiveOpts = makeStringSet(OptFormat, OptEnvelope, OptCompression, OptSchemaChangeEvents,
-	OptSchemaChangePolicy, OptOnError, OptInitialScan)
+	OptSchemaChangePolicy, OptOnError, OptInitialScan, OptCatchUpScanPriority)
 
 // RedactedOptions are options whose values should be replaced with "redacted" in job descriptions and errors.
 var RedactedOptions = makeStringSet(OptWebhookAuthHeader, SinkParamClientKey)
@@ -854,6 +873,21 @@ func (s StatementOptions) GetOnError() (OnErrorType, error) {
 	return OnErrorType(v), nil
 }
 
+// GetCatchUpScanPriority returns the admission control priority of the
+// catch-up scans of the changefeed's rangefeeds.
+func (s StatementOptions) GetCatchUpScanPriority() (admissionpb.WorkPriority, error) {
+	v, err := s.getEnumValue(OptCatchUpScanPriority)
+	if err != nil {
+		return 0, err
+	}
+	switch CatchUpScanPriority(v) {
+	case OptCatchUpScanPriorityLow:
+		return admissionpb.UserLowPri, nil
+	default:
+		return admissionpb.BulkNormalPri, nil
+	}
+}
+
 func describeEnum(strs ...string) string {
 	switch len(strs) {
 	case 1:
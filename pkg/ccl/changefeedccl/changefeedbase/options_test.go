@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/util/admission/admissionpb"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/stretchr/testify/require"
@@ -30,6 +31,8 @@ func TestOptionsValidations(t *testing.T) {
 	}{
 		{map[string]string{"format": "txt"}, "unknown format"},
 		{map[string]string{"initial_scan": "", "no_initial_scan": ""}, "cannot specify both"},
+		{map[string]string{"catchup_scan_priority": "urgent"}, "unknown catchup_scan_priority"},
+		{map[string]string{"catchup_scan_priority": "high"}, "unknown catchup_scan_priority"},
 	}
 
 	for _, test := range tests {
@@ -40,3 +43,21 @@ func TestOptionsValidations(t *testing.T) {
 	}
 
 }
+
+func TestCatchUpScanPriority(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		input    map[string]string
+		expected admissionpb.WorkPriority
+	}{
+		{map[string]string{}, admissionpb.BulkNormalPri},
+		{map[string]string{"catchup_scan_priority": "low"}, admissionpb.UserLowPri},
+		{map[string]string{"catchup_scan_priority": "NORMAL"}, admissionpb.BulkNormalPri},
+	} {
+		pri, err := MakeStatementOptions(tc.input).GetCatchUpScanPriority()
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, pri, "%v", tc.input)
+	}
+}
@@ -37,6 +37,7 @@ func StartDistChangefeed(
 	jobID jobspb.JobID,
 	details jobspb.ChangefeedDetails,
 	trackedSpans []roachpb.Span,
+	rangeFeedFilter *roachpb.RangeFeedFilter,
 	initialHighWater hlc.Timestamp,
 	checkpoint jobspb.ChangefeedProgress_Checkpoint,
 	resultsCh chan<- tree.Datums,
@@ -89,11 +90,12 @@ func StartDistChangefeed(
 		}
 
 		aggregatorSpecs[i] = &execinfrapb.ChangeAggregatorSpec{
-			Watches:    watches,
-			Checkpoint: aggregatorCheckpoint,
-			Feed:       details,
-			UserProto:  execCtx.User().EncodeProto(),
-			JobID:      jobID,
+			Watches:         watches,
+			Checkpoint:      aggregatorCheckpoint,
+			Feed:            details,
+			UserProto:       execCtx.User().EncodeProto(),
+			JobID:           jobID,
+			RangeFeedFilter: rangeFeedFilter,
 		}
 	}
 
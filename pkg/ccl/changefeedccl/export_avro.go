@@ -0,0 +1,387 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+	"github.com/linkedin/goavro/v2"
+)
+
+// EXPORT INTO AVRO lives alongside the changefeed Avro encoder so that exported
+// files use the same mapping from SQL types to Avro types as changefeeds do.
+
+const (
+	exportAvroFilePatternPart    = "%part%"
+	exportAvroFilePatternDefault = exportAvroFilePatternPart + ".avro"
+
+	// exportAvroRecordName is the name of the Avro record type of exported rows.
+	exportAvroRecordName = "export"
+
+	// exportAvroBlockRows is the number of rows buffered before they are
+	// appended to the object container file as a block.
+	exportAvroBlockRows = 1000
+)
+
+// newExportAvroSchema returns the Avro record schema for rows with the given
+// column names and types.
+func newExportAvroSchema(colNames []string, typs []*types.T) (*avroDataRecord, error) {
+	if len(colNames) != len(typs) {
+		return nil, errors.AssertionFailedf(
+			"expected %d column names, found %d", len(typs), len(colNames))
+	}
+	schema := &avroDataRecord{
+		avroRecord: avroRecord{
+			Name:       exportAvroRecordName,
+			SchemaType: `record`,
+		},
+		fieldIdxByName:   make(map[string]int, len(typs)),
+		colIdxByFieldIdx: make(map[int]int, len(typs)),
+	}
+	for i, typ := range typs {
+		field, err := typeToAvroSchema(typ)
+		if err != nil {
+			return nil, errors.Wrapf(err, "column %s", colNames[i])
+		}
+		field.Name = SQLNameToAvroName(colNames[i])
+		field.Metadata = typ.SQLString()
+		if _, ok := schema.fieldIdxByName[field.Name]; ok {
+			return nil, errors.Newf("duplicate avro field name %s", field.Name)
+		}
+		schema.colIdxByFieldIdx[i] = i
+		schema.fieldIdxByName[field.Name] = i
+		schema.Fields = append(schema.Fields, field)
+	}
+
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	schema.codec, err = goavro.NewCodec(string(schemaJSON))
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// avroExporter writes rows to an Avro object container file, encapsulating the
+// internals to make exporting oblivious for the consumers.
+type avroExporter struct {
+	buf         *bytes.Buffer
+	schema      *avroDataRecord
+	compression string
+	ocf         *goavro.OCFWriter
+	pending     []interface{}
+	// pendingBytes is the size of the binary encoding of the pending rows,
+	// which are not yet reflected in buf.
+	pendingBytes int
+	// scratch is reused to compute the encoded size of rows.
+	scratch []byte
+}
+
+func newAvroExporter(sp execinfrapb.ExportSpec, typs []*types.T) (*avroExporter, error) {
+	schema, err := newExportAvroSchema(sp.ColNames, typs)
+	if err != nil {
+		return nil, err
+	}
+	compression := goavro.CompressionNullLabel
+	switch sp.Format.Compression {
+	case roachpb.IOFileFormat_Snappy:
+		compression = goavro.CompressionSnappyLabel
+	case roachpb.IOFileFormat_Deflate:
+		compression = goavro.CompressionDeflateLabel
+	}
+	return &avroExporter{
+		buf:         bytes.NewBuffer([]byte{}),
+		schema:      schema,
+		compression: compression,
+	}, nil
+}
+
+// Write appends a row to the file. Rows are buffered and written out in
+// blocks of exportAvroBlockRows.
+func (c *avroExporter) Write(row tree.Datums) error {
+	native := make(map[string]interface{}, len(row))
+	for i, d := range row {
+		field := c.schema.Fields[i]
+		encoded, err := encodeExportDatum(field, tree.UnwrapDOidWrapper(d))
+		if err != nil {
+			return err
+		}
+		native[field.Name] = encoded
+	}
+	var err error
+	c.scratch, err = c.schema.codec.BinaryFromNative(c.scratch[:0], native)
+	if err != nil {
+		return err
+	}
+	c.pending = append(c.pending, native)
+	c.pendingBytes += len(c.scratch)
+	if len(c.pending) >= exportAvroBlockRows {
+		return c.Flush()
+	}
+	return nil
+}
+
+// encodeExportDatum encodes d as the value of field. Unlike field.encodeFn, it
+// does not memoize, so the result remains valid while rows are buffered.
+func encodeExportDatum(field *avroSchemaField, d tree.Datum) (interface{}, error) {
+	if d == tree.DNull {
+		return nil, nil
+	}
+	encoded, err := field.encodeDatum(d, nil /* memo */)
+	if err != nil {
+		return nil, err
+	}
+	// Every field is a union of null, its type and, for types with special
+	// values such as Infinity, string.
+	union := field.SchemaType.([]avroSchemaType)
+	key := avroUnionKey(union[1])
+	if _, isString := encoded.(string); isString && len(union) > 2 {
+		key = avroUnionKey(union[2])
+	}
+	return map[string]interface{}{key: encoded}, nil
+}
+
+// Flush appends the buffered rows to the file as a block.
+func (c *avroExporter) Flush() error {
+	if len(c.pending) == 0 {
+		return nil
+	}
+	if err := c.ocf.Append(c.pending); err != nil {
+		return err
+	}
+	c.pending = c.pending[:0]
+	c.pendingBytes = 0
+	return nil
+}
+
+// Close flushes all buffered rows.
+func (c *avroExporter) Close() error {
+	return c.Flush()
+}
+
+// ResetBuffer resets the buffer and starts a new object container file in it.
+func (c *avroExporter) ResetBuffer() error {
+	c.buf.Reset()
+	c.pending = c.pending[:0]
+	c.pendingBytes = 0
+	ocf, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:               c.buf,
+		Codec:           c.schema.codec,
+		CompressionName: c.compression,
+	})
+	if err != nil {
+		return err
+	}
+	c.ocf = ocf
+	return nil
+}
+
+// Bytes results in the slice of bytes.
+func (c *avroExporter) Bytes() []byte {
+	return c.buf.Bytes()
+}
+
+// Len returns the size of the file, including the rows which are buffered
+// but not yet written out. Those count with their uncompressed encoded size,
+// so Len may overestimate the size of a compressed file once it is flushed.
+func (c *avroExporter) Len() int {
+	return c.buf.Len() + c.pendingBytes
+}
+
+func (c *avroExporter) FileName(spec execinfrapb.ExportSpec, part string) string {
+	pattern := exportAvroFilePatternDefault
+	if spec.NamePattern != "" {
+		pattern = spec.NamePattern
+	}
+	return strings.Replace(pattern, exportAvroFilePatternPart, part, -1)
+}
+
+func newAvroWriterProcessor(
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec execinfrapb.ExportSpec,
+	input execinfra.RowSource,
+	output execinfra.RowReceiver,
+) (execinfra.Processor, error) {
+	c := &avroWriter{
+		flowCtx:     flowCtx,
+		processorID: processorID,
+		spec:        spec,
+		input:       input,
+		output:      output,
+	}
+	semaCtx := tree.MakeSemaContext()
+	if err := c.out.Init(&execinfrapb.PostProcessSpec{}, c.OutputTypes(), &semaCtx, flowCtx.NewEvalCtx()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type avroWriter struct {
+	flowCtx     *execinfra.FlowCtx
+	processorID int32
+	spec        execinfrapb.ExportSpec
+	input       execinfra.RowSource
+	out         execinfra.ProcOutputHelper
+	output      execinfra.RowReceiver
+}
+
+var _ execinfra.Processor = &avroWriter{}
+
+func (sp *avroWriter) OutputTypes() []*types.T {
+	res := make([]*types.T, len(colinfo.ExportColumns))
+	for i := range res {
+		res[i] = colinfo.ExportColumns[i].Typ
+	}
+	return res
+}
+
+func (sp *avroWriter) MustBeStreaming() bool {
+	return false
+}
+
+func (sp *avroWriter) Run(ctx context.Context) {
+	ctx, span := tracing.ChildSpan(ctx, "avroWriter")
+	defer span.Finish()
+
+	instanceID := sp.flowCtx.EvalCtx.NodeID.SQLInstanceID()
+	uniqueID := builtins.GenerateUniqueInt(instanceID)
+
+	err := func() error {
+		typs := sp.input.OutputTypes()
+		sp.input.Start(ctx)
+		input := execinfra.MakeNoMetadataRowSource(sp.input, sp.output)
+		alloc := &tree.DatumAlloc{}
+
+		exporter, err := newAvroExporter(sp.spec, typs)
+		if err != nil {
+			return err
+		}
+
+		avroRow := make(tree.Datums, len(typs))
+		chunk := 0
+		done := false
+		for {
+			var rows int64
+			if err := exporter.ResetBuffer(); err != nil {
+				return err
+			}
+			for {
+				// If the file, including the rows not yet written out as a block,
+				// exceeds the target size of an Avro file, we flush before exporting
+				// any additional rows.
+				if int64(exporter.Len()) >= sp.spec.ChunkSize {
+					break
+				}
+				if sp.spec.ChunkRows > 0 && rows >= sp.spec.ChunkRows {
+					break
+				}
+				row, err := input.NextRow()
+				if err != nil {
+					return err
+				}
+				if row == nil {
+					done = true
+					break
+				}
+				rows++
+
+				for i, ed := range row {
+					if err := ed.EnsureDecoded(typs[i], alloc); err != nil {
+						return err
+					}
+					avroRow[i] = ed.Datum
+				}
+				if err := exporter.Write(avroRow); err != nil {
+					return err
+				}
+			}
+			if rows < 1 {
+				break
+			}
+
+			// Close exporter to ensure all buffered rows are written out.
+			if err := exporter.Close(); err != nil {
+				return errors.Wrapf(err, "failed to close exporting exporter")
+			}
+
+			conf, err := cloud.ExternalStorageConfFromURI(sp.spec.Destination, sp.spec.User())
+			if err != nil {
+				return err
+			}
+			es, err := sp.flowCtx.Cfg.ExternalStorage(ctx, conf)
+			if err != nil {
+				return err
+			}
+			defer es.Close()
+
+			part := fmt.Sprintf("n%d.%d", uniqueID, chunk)
+			chunk++
+			filename := exporter.FileName(sp.spec, part)
+
+			size := exporter.Len()
+
+			if err := cloud.WriteFile(ctx, es, filename, bytes.NewReader(exporter.Bytes())); err != nil {
+				return err
+			}
+			res := rowenc.EncDatumRow{
+				rowenc.DatumToEncDatum(
+					types.String,
+					tree.NewDString(filename),
+				),
+				rowenc.DatumToEncDatum(
+					types.Int,
+					tree.NewDInt(tree.DInt(rows)),
+				),
+				rowenc.DatumToEncDatum(
+					types.Int,
+					tree.NewDInt(tree.DInt(size)),
+				),
+			}
+
+			cs, err := sp.out.EmitRow(ctx, res, sp.output)
+			if err != nil {
+				return err
+			}
+			if cs != execinfra.NeedMoreRows {
+				return errors.New("unexpected closure of consumer")
+			}
+			if done {
+				break
+			}
+		}
+
+		return nil
+	}()
+
+	execinfra.DrainAndClose(
+		ctx, sp.output, err, func(context.Context) {} /* pushTrailingMeta */, sp.input)
+}
+
+func init() {
+	rowexec.NewAvroWriterProcessor = newAvroWriterProcessor
+}
@@ -0,0 +1,144 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportAvro checks that rows exported to Avro decode back to the same
+// values using the changefeed type mapping.
+func TestExportAvro(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE foo (i INT PRIMARY KEY, s STRING, d DECIMAL(10,2), a INT[], f FLOAT)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES
+		(1, 'a', 1.50, ARRAY[1, 2], 'Infinity'),
+		(2, NULL, NULL, NULL, 2.5)`)
+	typs := []*types.T{
+		types.Int, types.String, types.MakeDecimal(10, 2), types.IntArray, types.Float,
+	}
+	colNames := []string{"i", "s", "d", "a", "f"}
+	expected := [][]string{
+		{"1", "'a'", "1.50", "ARRAY[1,2]", "'+Inf'"},
+		{"2", "NULL", "NULL", "NULL", "2.5"},
+	}
+
+	for _, compression := range []string{"none", "snappy", "deflate"} {
+		t.Run(compression, func(t *testing.T) {
+			stmt := `EXPORT INTO AVRO $1 FROM SELECT * FROM foo ORDER BY i`
+			if compression != "none" {
+				stmt = `EXPORT INTO AVRO $1 WITH compression = '` + compression + `' FROM SELECT * FROM foo ORDER BY i`
+			}
+			sqlDB.Exec(t, stmt, "nodelocal://0/"+compression)
+
+			files, err := filepath.Glob(filepath.Join(dir, compression, "export*-n*.0.avro"))
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+			content, err := ioutil.ReadFile(files[0])
+			require.NoError(t, err)
+
+			ocf, err := goavro.NewOCFReader(bytes.NewReader(content))
+			require.NoError(t, err)
+			schema, err := newExportAvroSchema(colNames, typs)
+			require.NoError(t, err)
+
+			var actual [][]string
+			for ocf.Scan() {
+				native, err := ocf.Read()
+				require.NoError(t, err)
+				row, err := schema.rowFromNative(native)
+				require.NoError(t, err)
+				var strs []string
+				for _, ed := range row {
+					strs = append(strs, ed.Datum.String())
+				}
+				actual = append(actual, strs)
+			}
+			require.NoError(t, ocf.Err())
+			require.Equal(t, expected, actual)
+		})
+	}
+
+	sqlDB.ExpectErr(t, `unsupported compression codec gzip for avro file format`,
+		`EXPORT INTO AVRO 'nodelocal://0/gzip' WITH compression = gzip FROM SELECT * FROM foo`)
+	sqlDB.ExpectErr(t, `duplicate column name "i"`,
+		`EXPORT INTO AVRO 'nodelocal://0/dup' FROM SELECT i, i FROM foo`)
+}
+
+// TestExportAvroChunkSize checks that the rows buffered before being written
+// out as a block count toward the chunk size, so that files are split at the
+// requested size.
+func TestExportAvroChunkSize(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const chunkSize = 10 << 10
+	// Each file may exceed the chunk size by the last row added to it and by
+	// the framing of its blocks.
+	const slack = 200
+	const numRows = 4000
+	sqlDB.Exec(t, `EXPORT INTO AVRO 'nodelocal://0/chunked' WITH chunk_size = '10KiB'
+		FROM SELECT i, gen_random_uuid()::STRING AS u FROM generate_series(1, $1) AS g(i)`, numRows)
+
+	files, err := filepath.Glob(filepath.Join(dir, "chunked", "*.avro"))
+	require.NoError(t, err)
+	// The rows take up about 45 bytes each, so that they fit in about 20 files
+	// of 10KiB, and well below exportAvroBlockRows rows go to each file.
+	require.Greater(t, len(files), 10)
+
+	var rows, fullFiles int
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		require.Less(t, len(content), chunkSize+slack, file)
+		if len(content) >= chunkSize {
+			fullFiles++
+		}
+
+		ocf, err := goavro.NewOCFReader(bytes.NewReader(content))
+		require.NoError(t, err)
+		for ocf.Scan() {
+			_, err := ocf.Read()
+			require.NoError(t, err)
+			rows++
+		}
+		require.NoError(t, ocf.Err())
+	}
+	require.Equal(t, numRows, rows)
+	// All the files but the last one are cut once they reach the chunk size.
+	require.GreaterOrEqual(t, fullFiles, len(files)-1)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/admission/admissionpb"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -55,6 +56,14 @@ type Config struct {
 	SchemaChangePolicy      changefeedbase.SchemaChangePolicy
 	SchemaFeed              schemafeed.SchemaFeed
 
+	// RangeFeedFilter, if set, restricts the values emitted by the rangefeeds
+	// to those the changefeed is interested in. The filter is evaluated by the
+	// replicas, so filtered values are not sent over the network nor buffered.
+	RangeFeedFilter *roachpb.RangeFeedFilter
+	// CatchUpScanPriority is the admission control priority of the catch-up
+	// scans of the rangefeeds.
+	CatchUpScanPriority admissionpb.WorkPriority
+
 	// If true, the feed will begin with a dump of data at exactly the
 	// InitialHighWater. This is a peculiar behavior. In general the
 	// InitialHighWater is a point in time at which all data is known to have
@@ -108,6 +117,8 @@ func Run(ctx context.Context, cfg Config) error {
 		cfg.SchemaFeed,
 		sc, pff, bf, cfg.Knobs)
 	f.onBackfillCallback = cfg.OnBackfillCallback
+	f.rangeFeedFilter = cfg.RangeFeedFilter
+	f.catchUpScanPriority = cfg.CatchUpScanPriority
 
 	g := ctxgroup.WithContext(ctx)
 	g.GoCtx(cfg.SchemaFeed.Run)
@@ -166,6 +177,8 @@ type kvFeed struct {
 	checkpoint          []roachpb.Span
 	checkpointTimestamp hlc.Timestamp
 	withDiff            bool
+	rangeFeedFilter     *roachpb.RangeFeedFilter
+	catchUpScanPriority admissionpb.WorkPriority
 	withInitialBackfill bool
 	initialHighWater    hlc.Timestamp
 	endTime             hlc.Timestamp
@@ -438,10 +451,12 @@ func (f *kvFeed) runUntilTableEvent(
 
 	g := ctxgroup.WithContext(ctx)
 	physicalCfg := rangeFeedConfig{
-		Spans:    stps,
-		Frontier: resumeFrontier.Frontier(),
-		WithDiff: f.withDiff,
-		Knobs:    f.knobs,
+		Spans:               stps,
+		Frontier:            resumeFrontier.Frontier(),
+		WithDiff:            f.withDiff,
+		Filter:              f.rangeFeedFilter,
+		Knobs:               f.knobs,
+		CatchUpScanPriority: f.catchUpScanPriority,
 	}
 
 	g.GoCtx(func(ctx context.Context) error {
@@ -15,6 +15,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/admission/admissionpb"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/errors"
@@ -27,10 +28,12 @@ type physicalFeedFactory interface {
 }
 
 type rangeFeedConfig struct {
-	Frontier hlc.Timestamp
-	Spans    []kvcoord.SpanTimePair
-	WithDiff bool
-	Knobs    TestingKnobs
+	Frontier            hlc.Timestamp
+	Spans               []kvcoord.SpanTimePair
+	WithDiff            bool
+	Filter              *roachpb.RangeFeedFilter
+	Knobs               TestingKnobs
+	CatchUpScanPriority admissionpb.WorkPriority
 }
 
 type rangefeedFactory func(
@@ -38,6 +41,7 @@ type rangefeedFactory func(
 	spans []kvcoord.SpanTimePair,
 	withDiff bool,
 	eventC chan<- *roachpb.RangeFeedEvent,
+	opts ...kvcoord.RangeFeedOption,
 ) error
 
 type rangefeed struct {
@@ -74,7 +78,13 @@ func (p rangefeedFactory) Run(ctx context.Context, sink kvevent.Writer, cfg rang
 	g := ctxgroup.WithContext(ctx)
 	g.GoCtx(feed.addEventsToBuffer)
 	g.GoCtx(func(ctx context.Context) error {
-		return p(ctx, cfg.Spans, cfg.WithDiff, feed.eventC)
+		opts := []kvcoord.RangeFeedOption{
+			kvcoord.WithCatchUpScanAdmissionPriority(cfg.CatchUpScanPriority),
+		}
+		if cfg.Filter != nil {
+			opts = append(opts, kvcoord.WithRangeFeedFilter(cfg.Filter))
+		}
+		return p(ctx, cfg.Spans, cfg.WithDiff, feed.eventC, opts...)
 	})
 	return g.Wait()
 }
@@ -43,7 +43,9 @@ func isCloudStorageSink(u *url.URL) bool {
 	switch u.Scheme {
 	case changefeedbase.SinkSchemeCloudStorageS3, changefeedbase.SinkSchemeCloudStorageGCS,
 		changefeedbase.SinkSchemeCloudStorageNodelocal, changefeedbase.SinkSchemeCloudStorageHTTP,
-		changefeedbase.SinkSchemeCloudStorageHTTPS, changefeedbase.SinkSchemeCloudStorageAzure:
+		changefeedbase.SinkSchemeCloudStorageHTTPS, changefeedbase.SinkSchemeCloudStorageAzure,
+		changefeedbase.SinkSchemeCloudStorageSFTP, changefeedbase.SinkSchemeCloudStorageWebDAV,
+		changefeedbase.SinkSchemeCloudStorageWebDAVHTTP:
 		return true
 	default:
 		return false
@@ -1716,6 +1716,16 @@ func init() {
 	f.VarP(&debugRecoverExecuteOpts.confirmAction, cliflags.ConfirmActions.Name, cliflags.ConfirmActions.Shorthand,
 		cliflags.ConfirmActions.Usage())
 
+	f = debugRecoverStagePlanCmd.Flags()
+	// NB: The confirmation flag has no shorthand here, as it would clash with
+	// the --port shorthand of client commands.
+	f.Var(&debugRecoverStagePlanOpts.confirmAction, cliflags.ConfirmActions.Name,
+		cliflags.ConfirmActions.Usage())
+	f.BoolVar(&debugRecoverStagePlanOpts.force, "force", false,
+		"replace a different plan already staged on the nodes")
+	f.BoolVar(&debugRecoverStagePlanOpts.remove, "remove", false,
+		"remove the plan staged on the nodes")
+
 	f = debugMergeLogsCmd.Flags()
 	f.Var(flagutil.Time(&debugMergeLogsOpts.from), "from",
 		"time before which messages should be filtered")
@@ -17,6 +17,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -24,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery/loqrecoverypb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -130,6 +132,25 @@ If it was possible to produce and apply the plan, then cluster should
 become operational again. It is not guaranteed that there's no data loss
 and that all database consistency was not compromised.
 
+Alternatively, if the cluster is still running, recovery could be performed
+without stopping it. In that case, steps 1 to 5 are replaced by:
+
+1. Run 'cockroach debug recover collect-info --host=<node>' to collect
+replication state from all reachable nodes through the given node.
+
+2. Run 'cockroach debug recover make-plan' providing the collected file.
+
+3. Run 'cockroach debug recover stage-plan --host=<node>' providing the plan.
+The plan is staged on the nodes which hold the replicas it updates.
+
+4. Restart the nodes listed by the previous step. Each node applies the
+staged plan when it is restarted, before it starts its stores.
+
+5. Run 'cockroach debug recover verify --host=<node>' providing the plan to
+check that the plan was applied on all nodes and that all ranges regained
+quorum. Recovered ranges are reported as unavailable until they
+up-replicate.
+
 Example run:
 
 If we have a cluster of 5 nodes 1-5 where we lost nodes 3 and 4. Each node
@@ -162,6 +183,16 @@ Info:
 [cockroach@node5 ~]$ cockroach debug recover apply-plan --store=/mnt/cockroach-data-1 --store=/mnt/cockroach-data-2 recover-plan.json
 
 Now the cluster could be started again.
+
+The same recovery performed on the running cluster would be:
+
+[cockroach@base ~]$ cockroach debug recover collect-info --host=node1 >info.json
+[cockroach@base ~]$ cockroach debug recover make-plan info.json >recover-plan.json
+[cockroach@base ~]$ cockroach debug recover stage-plan --host=node1 recover-plan.json
+
+Restart node1, then verify the recovery:
+
+[cockroach@base ~]$ cockroach debug recover verify --host=node1 recover-plan.json
 `,
 	RunE: UsageAndErr,
 }
@@ -170,7 +201,9 @@ func init() {
 	debugRecoverCmd.AddCommand(
 		debugRecoverCollectInfoCmd,
 		debugRecoverPlanCmd,
-		debugRecoverExecuteCmd)
+		debugRecoverExecuteCmd,
+		debugRecoverStagePlanCmd,
+		debugRecoverVerifyCmd)
 }
 
 var debugRecoverCollectInfoCmd = &cobra.Command{
@@ -180,6 +213,9 @@ var debugRecoverCollectInfoCmd = &cobra.Command{
 Collect information about replicas by reading data from underlying stores. Store
 locations must be provided using --store flags.
 
+If no --store flags are provided, information is collected from all reachable
+nodes of a running cluster instead, through the node given by --host.
+
 Collected information is written to a destination file if file name is provided,
 or to stdout.
 
@@ -201,17 +237,13 @@ func runDebugDeadReplicaCollect(cmd *cobra.Command, args []string) error {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(cmd.Context())
 
-	var stores []storage.Engine
-	for _, storeSpec := range debugRecoverCollectInfoOpts.Stores.Specs {
-		db, err := OpenEngine(storeSpec.Path, stopper, storage.MustExist, storage.ReadOnly)
-		if err != nil {
-			return errors.Wrapf(err, "failed to open store at path %q, ensure that store path is "+
-				"correct and that it is not used by another process", storeSpec.Path)
-		}
-		stores = append(stores, db)
+	var replicaInfo loqrecoverypb.NodeReplicaInfo
+	var err error
+	if len(debugRecoverCollectInfoOpts.Stores.Specs) == 0 {
+		replicaInfo, err = collectRemoteReplicaInfo(cmd.Context())
+	} else {
+		replicaInfo, err = collectLocalReplicaInfo(cmd.Context(), stopper)
 	}
-
-	replicaInfo, err := loqrecovery.CollectReplicaInfo(cmd.Context(), stores)
 	if err != nil {
 		return err
 	}
@@ -242,6 +274,49 @@ func runDebugDeadReplicaCollect(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func collectLocalReplicaInfo(
+	ctx context.Context, stopper *stop.Stopper,
+) (loqrecoverypb.NodeReplicaInfo, error) {
+	var stores []storage.Engine
+	for _, storeSpec := range debugRecoverCollectInfoOpts.Stores.Specs {
+		db, err := OpenEngine(storeSpec.Path, stopper, storage.MustExist, storage.ReadOnly)
+		if err != nil {
+			return loqrecoverypb.NodeReplicaInfo{}, errors.Wrapf(err,
+				"failed to open store at path %q, ensure that store path is "+
+					"correct and that it is not used by another process", storeSpec.Path)
+		}
+		stores = append(stores, db)
+	}
+	return loqrecovery.CollectReplicaInfo(ctx, stores)
+}
+
+// collectRemoteReplicaInfo collects replica info from all reachable nodes of a
+// running cluster. Info from all nodes is merged into a single
+// NodeReplicaInfo, which is handled by make-plan the same way as the info
+// collected separately from each node.
+func collectRemoteReplicaInfo(ctx context.Context) (loqrecoverypb.NodeReplicaInfo, error) {
+	c, finish, err := getAdminClient(ctx, serverCfg)
+	if err != nil {
+		return loqrecoverypb.NodeReplicaInfo{}, err
+	}
+	defer finish()
+
+	resp, err := c.RecoveryCollectReplicaInfo(ctx, &serverpb.RecoveryCollectReplicaInfoRequest{})
+	if err != nil {
+		return loqrecoverypb.NodeReplicaInfo{}, errors.Wrap(err, "failed to collect replica info")
+	}
+	var replicaInfo loqrecoverypb.NodeReplicaInfo
+	for _, node := range resp.Nodes {
+		replicaInfo.Replicas = append(replicaInfo.Replicas, node.Replicas...)
+	}
+	_, _ = fmt.Fprintf(stderr, "Collected info from %d nodes.\n", len(resp.Nodes))
+	if len(resp.UnreachableNodes) > 0 {
+		_, _ = fmt.Fprintf(stderr, "Failed to collect info from unreachable nodes: %s\n",
+			joinNodeIDs(resp.UnreachableNodes))
+	}
+	return replicaInfo, nil
+}
+
 var debugRecoverPlanCmd = &cobra.Command{
 	Use:   "make-plan [replica-files]",
 	Short: "generate a plan to recover ranges that lost quorum",
@@ -362,6 +437,7 @@ Discarded live replicas: %d
 		_, _ = fmt.Fprintln(stderr, "Found no ranges in need of recovery, nothing to do.")
 		return nil
 	}
+	plan.PlanID = uuid.MakeV4()
 
 	var writer io.Writer = os.Stdout
 	if len(debugRecoverPlanOpts.outputFileName) > 0 {
@@ -385,11 +461,13 @@ Discarded live replicas: %d
 		return errors.Wrap(err, "failed to write recovery plan")
 	}
 
-	_, _ = fmt.Fprint(stderr, "Plan created\nTo complete recovery, distribute the plan to the"+
-		" below nodes and invoke `debug recover apply-plan` on:\n")
+	_, _ = fmt.Fprintf(stderr, "Plan %s created\nTo complete recovery, distribute the plan to the"+
+		" below nodes and invoke `debug recover apply-plan` on:\n", plan.PlanID)
 	for node, stores := range report.UpdatedNodes {
 		_, _ = fmt.Fprintf(stderr, "- node n%d, store(s) %s\n", node, joinStoreIDs(stores))
 	}
+	_, _ = fmt.Fprint(stderr, "If the cluster is running, invoke `debug recover stage-plan` instead"+
+		" and restart the above nodes.\n")
 
 	return nil
 }
@@ -442,16 +520,9 @@ func runDebugExecuteRecoverPlan(cmd *cobra.Command, args []string) error {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(cmd.Context())
 
-	planFile := args[0]
-	data, err := ioutil.ReadFile(planFile)
+	nodeUpdates, err := readPlanFile(args[0])
 	if err != nil {
-		return errors.Wrapf(err, "failed to read plan file %q", planFile)
-	}
-
-	var nodeUpdates loqrecoverypb.ReplicaUpdatePlan
-	jsonpb := protoutil.JSONPb{Indent: "  "}
-	if err = jsonpb.Unmarshal(data, &nodeUpdates); err != nil {
-		return errors.Wrapf(err, "failed to unmarshal plan from file %q", planFile)
+		return err
 	}
 
 	var localNodeID roachpb.NodeID
@@ -537,6 +608,234 @@ func runDebugExecuteRecoverPlan(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+func readPlanFile(planFile string) (loqrecoverypb.ReplicaUpdatePlan, error) {
+	data, err := ioutil.ReadFile(planFile)
+	if err != nil {
+		return loqrecoverypb.ReplicaUpdatePlan{}, errors.Wrapf(err, "failed to read plan file %q", planFile)
+	}
+
+	var plan loqrecoverypb.ReplicaUpdatePlan
+	jsonpb := protoutil.JSONPb{Indent: "  "}
+	if err = jsonpb.Unmarshal(data, &plan); err != nil {
+		return loqrecoverypb.ReplicaUpdatePlan{}, errors.Wrapf(err,
+			"failed to unmarshal plan from file %q", planFile)
+	}
+	return plan, nil
+}
+
+var debugRecoverStagePlanCmd = &cobra.Command{
+	Use:   "stage-plan [plan-file]",
+	Short: "stage recovery plan on the nodes of a running cluster",
+	Long: `
+Stage a plan on the nodes of a running cluster, through the node given by
+--host. The plan is staged on every node which holds replicas updated by the
+plan. Nodes apply the staged plan once restarted, before starting their stores.
+
+A plan could not be staged on nodes where a different plan is already staged,
+unless --force is provided. A staged plan could be removed from all nodes using
+--remove instead of providing a plan.
+
+See debug recover command help for more details on how to use this command.
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDebugStageRecoverPlan,
+}
+
+var debugRecoverStagePlanOpts struct {
+	confirmAction confirmActionFlag
+	force         bool
+	remove        bool
+}
+
+func runDebugStageRecoverPlan(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	req := serverpb.RecoveryStagePlanRequest{
+		AllNodes:  true,
+		ForcePlan: debugRecoverStagePlanOpts.force,
+	}
+	var updatedNodes []roachpb.NodeID
+	switch {
+	case debugRecoverStagePlanOpts.remove && len(args) > 0:
+		return errors.New("a plan file can not be provided with --remove")
+	case debugRecoverStagePlanOpts.remove:
+		_, _ = fmt.Fprintf(stderr, "Staged plan will be removed from all nodes.\n")
+	case len(args) == 0:
+		return errors.New("a plan file must be provided unless --remove is used")
+	default:
+		plan, err := readPlanFile(args[0])
+		if err != nil {
+			return err
+		}
+		if plan.PlanID.Equal(uuid.Nil) {
+			return errors.New("plan has no ID, it must be created by a version supporting staging")
+		}
+		req.Plan = &plan
+
+		nodes := make(map[roachpb.NodeID]struct{})
+		for _, u := range plan.Updates {
+			if _, ok := nodes[u.NodeID()]; !ok {
+				nodes[u.NodeID()] = struct{}{}
+				updatedNodes = append(updatedNodes, u.NodeID())
+			}
+			_, _ = fmt.Fprintf(stderr, "Replica for range r%d:%s on n%d will be updated to %s.\n",
+				u.RangeID, u.StartKey.AsRKey(), u.NodeID(), u.NewReplica)
+		}
+		sort.Slice(updatedNodes, func(i, j int) bool { return updatedNodes[i] < updatedNodes[j] })
+	}
+
+	switch debugRecoverStagePlanOpts.confirmAction {
+	case prompt:
+		_, _ = fmt.Fprintf(stderr, "\nProceed with staging [y/N] ")
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return errors.Wrap(err, "failed to read user input")
+		}
+		_, _ = fmt.Fprintf(stderr, "\n")
+		if len(line) < 1 || (line[0] != 'y' && line[0] != 'Y') {
+			_, _ = fmt.Fprint(stderr, "Aborted at user request\n")
+			return nil
+		}
+	case allYes:
+		// All actions enabled by default.
+	default:
+		return errors.New("Aborted by --confirm option")
+	}
+
+	c, finish, err := getAdminClient(ctx, serverCfg)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	resp, err := c.RecoveryStagePlan(ctx, &req)
+	if err != nil {
+		return errors.Wrap(err, "failed to stage plan")
+	}
+	if len(resp.Errors) > 0 {
+		for _, e := range resp.Errors {
+			_, _ = fmt.Fprintf(stderr, "%s\n", e)
+		}
+		return errors.New("failed to stage plan on all nodes, correct the errors and retry")
+	}
+	if req.Plan == nil {
+		_, _ = fmt.Fprintf(stderr, "Staged plan removed.\n")
+		return nil
+	}
+	_, _ = fmt.Fprintf(stderr, "Plan %s staged. To complete recovery restart nodes %s.\n",
+		req.Plan.PlanID, joinNodeIDs(updatedNodes))
+	return nil
+}
+
+var debugRecoverVerifyCmd = &cobra.Command{
+	Use:   "verify [plan-file]",
+	Short: "verify loss of quorum recovery of a running cluster",
+	Long: `
+Verify the loss of quorum recovery of a running cluster, through the node given
+by --host. The recovery status of every reachable node is reported, along with
+ranges which have not regained quorum.
+
+If a plan is provided, verification fails unless the plan has been applied on
+all nodes it updates. Verification also fails while any ranges have not
+regained quorum. Ranges recovered by the plan are reported until they
+up-replicate.
+
+See debug recover command help for more details on how to use this command.
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDebugVerify,
+}
+
+// maxReportedUnavailableRanges limits the number of unavailable ranges listed
+// by verify.
+const maxReportedUnavailableRanges = 20
+
+func runDebugVerify(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	var plan loqrecoverypb.ReplicaUpdatePlan
+	if len(args) > 0 {
+		var err error
+		if plan, err = readPlanFile(args[0]); err != nil {
+			return err
+		}
+	}
+
+	c, finish, err := getAdminClient(ctx, serverCfg)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	resp, err := c.RecoveryVerify(ctx, &serverpb.RecoveryVerifyRequest{})
+	if err != nil {
+		return errors.Wrap(err, "failed to verify recovery")
+	}
+
+	complete := true
+	statuses := make(map[roachpb.NodeID]loqrecoverypb.NodeRecoveryStatus)
+	for _, status := range resp.Statuses {
+		statuses[status.NodeID] = status
+		switch {
+		case !status.PendingPlanID.Equal(uuid.Nil):
+			_, _ = fmt.Fprintf(stderr, "n%d: plan %s is staged, awaiting node restart\n",
+				status.NodeID, status.PendingPlanID)
+		case status.AppliedPlanID.Equal(uuid.Nil):
+			_, _ = fmt.Fprintf(stderr, "n%d: no plan applied\n", status.NodeID)
+		case status.Error != "":
+			_, _ = fmt.Fprintf(stderr, "n%d: plan %s failed to apply: %s\n",
+				status.NodeID, status.AppliedPlanID, status.Error)
+		default:
+			_, _ = fmt.Fprintf(stderr, "n%d: plan %s applied at %s\n",
+				status.NodeID, status.AppliedPlanID, status.ApplyTimestamp)
+		}
+	}
+	if len(resp.UnreachableNodes) > 0 {
+		_, _ = fmt.Fprintf(stderr, "unreachable nodes: %s\n", joinNodeIDs(resp.UnreachableNodes))
+	}
+
+	if len(plan.Updates) > 0 {
+		for _, u := range plan.Updates {
+			status, ok := statuses[u.NodeID()]
+			if !ok || !status.AppliedPlanID.Equal(plan.PlanID) || status.Error != "" {
+				_, _ = fmt.Fprintf(stderr, "\nPlan %s is not applied on all nodes.\n", plan.PlanID)
+				complete = false
+				break
+			}
+		}
+	}
+
+	if resp.RangeCheckError != "" {
+		_, _ = fmt.Fprintf(stderr, "\nFailed to check range health: %s\n", resp.RangeCheckError)
+		complete = false
+	} else if len(resp.UnavailableRanges) > 0 {
+		_, _ = fmt.Fprintf(stderr, "\nFound %d ranges without quorum:\n", len(resp.UnavailableRanges))
+		for i, r := range resp.UnavailableRanges {
+			if i == maxReportedUnavailableRanges {
+				_, _ = fmt.Fprintf(stderr, "...\n")
+				break
+			}
+			_, _ = fmt.Fprintf(stderr, "r%d: %s %s\n", r.RangeID, r.Span, r.Health)
+		}
+		complete = false
+	}
+
+	if !complete {
+		return errors.New("loss of quorum recovery is not complete")
+	}
+	_, _ = fmt.Fprintf(stderr, "\nAll ranges have quorum.\n")
+	return nil
+}
+
+func joinNodeIDs(nodeIDs []roachpb.NodeID) string {
+	nodeNames := make([]string, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		nodeNames = append(nodeNames, fmt.Sprintf("n%d", id))
+	}
+	return strings.Join(nodeNames, ", ")
+}
+
 func joinStoreIDs(storeIDs []roachpb.StoreID) string {
 	storeNames := make([]string, 0, len(storeIDs))
 	for _, id := range storeIDs {
@@ -554,4 +853,7 @@ func setDebugRecoverContextDefaults() {
 	debugRecoverPlanOpts.deadStoreIDs = nil
 	debugRecoverExecuteOpts.Stores.Specs = nil
 	debugRecoverExecuteOpts.confirmAction = prompt
+	debugRecoverStagePlanOpts.confirmAction = prompt
+	debugRecoverStagePlanOpts.force = false
+	debugRecoverStagePlanOpts.remove = false
 }
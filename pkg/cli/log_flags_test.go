@@ -53,6 +53,24 @@ func TestSetupLogging(t *testing.T) {
 		`redactable: true, ` +
 		`exit-on-error: false, ` +
 		`buffering: NONE}`
+	const defaultSyslogConfig = `syslog-defaults: {` +
+		`facility: user, ` +
+		`tls: false, ` +
+		`unsafe-tls: false, ` +
+		`filter: INFO, ` +
+		`format: syslog, ` +
+		`redactable: true, ` +
+		`exit-on-error: false, ` +
+		`buffering: NONE}`
+	const defaultOTLPConfig = `otlp-defaults: {` +
+		`timeout: 5s, ` +
+		`tls: false, ` +
+		`unsafe-tls: false, ` +
+		`filter: INFO, ` +
+		`format: json, ` +
+		`redactable: true, ` +
+		`exit-on-error: false, ` +
+		`buffering: NONE}`
 	stdFileDefaultsRe := regexp.MustCompile(
 		`file-defaults: \{` +
 			`dir: (?P<path>[^,]+), ` +
@@ -180,6 +198,8 @@ func TestSetupLogging(t *testing.T) {
 		// Shorten the configuration for legibility during reviews of test changes.
 		actual = strings.ReplaceAll(actual, defaultFluentConfig, "<fluentDefaults>")
 		actual = strings.ReplaceAll(actual, defaultHTTPConfig, "<httpDefaults>")
+		actual = strings.ReplaceAll(actual, defaultSyslogConfig, "<syslogDefaults>")
+		actual = strings.ReplaceAll(actual, defaultOTLPConfig, "<otlpDefaults>")
 		actual = stdFileDefaultsRe.ReplaceAllString(actual, "<stdFileDefaults($path)>")
 		actual = fileDefaultsNoMaxSizeRe.ReplaceAllString(actual, "<fileDefaultsNoMaxSize($path)>")
 		actual = strings.ReplaceAll(actual, fileDefaultsNoDir, "<fileDefaultsNoDir>")
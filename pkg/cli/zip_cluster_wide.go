@@ -78,6 +78,7 @@ var debugZipTablesPerCluster = []string{
 	"crdb_internal.cluster_contention_events",
 	"crdb_internal.cluster_distsql_flows",
 	"crdb_internal.cluster_database_privileges",
+	"crdb_internal.cluster_lock_waits",
 	"crdb_internal.cluster_locks",
 	"crdb_internal.cluster_queries",
 	"crdb_internal.cluster_sessions",
@@ -97,6 +98,7 @@ var debugZipTablesPerCluster = []string{
 
 	"crdb_internal.kv_node_liveness",
 	"crdb_internal.kv_node_status",
+	"crdb_internal.kv_storage_quotas",
 	"crdb_internal.kv_store_status",
 
 	"crdb_internal.regions",
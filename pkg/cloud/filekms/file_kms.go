@@ -0,0 +1,168 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package filekms
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	fileScheme = "file"
+
+	// keyLen is the length in bytes of the AES-256 master key held in the key
+	// file.
+	keyLen = 32
+)
+
+// fileKMS wraps and unwraps data keys with an AES-256-GCM master key read from
+// a file on the local filesystem of the node. It exists for testing and for
+// air-gapped deployments which have no access to a real KMS; the key file must
+// be present, with the same contents, on every node that runs the backup or
+// restore.
+//
+// The URI has the form file:///<absolute path to key file>. The key file holds
+// either 32 raw bytes or 64 hex characters, optionally followed by a newline.
+//
+// Since the key file is read with the node's own access to its filesystem, the
+// file KMS is a form of implicit access: it is disabled by
+// --external-io-disable-implicit-credentials and, like nodelocal storage, is
+// restricted to admin users by the BACKUP, RESTORE and SHOW BACKUP statements.
+type fileKMS struct {
+	aead  cipher.AEAD
+	keyID string
+}
+
+var _ cloud.KMS = &fileKMS{}
+
+func init() {
+	cloud.RegisterKMSFromURIFactory(MakeFileKMS, fileScheme)
+	cloud.RegisterKMSImplicitAccessScheme(fileScheme)
+}
+
+// MakeFileKMS is the factory method which returns a configured, ready-to-use
+// file-based KMS object.
+func MakeFileKMS(ctx context.Context, uri string, env cloud.KMSEnv) (cloud.KMS, error) {
+	kmsURI, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if kmsURI.Host != "" {
+		return nil, errors.Newf(
+			"file KMS URI must not specify a host, got %q; use file:///<path>", kmsURI.Host)
+	}
+	if kmsURI.Path == "" {
+		return nil, errors.New("file KMS URI must specify the path of the key file")
+	}
+	if env.KMSConfig().DisableImplicitCredentials {
+		return nil, errors.New(
+			"file KMS disallowed due to --external-io-disable-implicit-credentials flag")
+	}
+
+	// The errors returned to the user mention neither the path nor anything
+	// about the contents of the file, so that the file KMS cannot be used to
+	// probe the filesystem of the node. The details are logged instead.
+	contents, err := ioutil.ReadFile(kmsURI.Path)
+	if err != nil {
+		log.Warningf(ctx, "reading file KMS key %s: %v", kmsURI.Path, err)
+		return nil, errInvalidKeyFile
+	}
+	key, err := parseKey(contents)
+	if err != nil {
+		log.Warningf(ctx, "parsing file KMS key %s: %v", kmsURI.Path, err)
+		return nil, errInvalidKeyFile
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The master key ID is derived from the key material rather than from the
+	// path, so that the key file may live at a different location on the
+	// cluster that restores the backup.
+	fingerprint := sha256.Sum256(key)
+	return &fileKMS{
+		aead:  aead,
+		keyID: hex.EncodeToString(fingerprint[:8]),
+	}, nil
+}
+
+// errInvalidKeyFile is returned when the key file cannot be read or does not
+// hold a valid key.
+var errInvalidKeyFile = errors.WithHintf(
+	errors.New("could not read a valid file KMS key"),
+	"the key file must hold %d raw bytes or %d hex characters; see the logs for details",
+	keyLen, 2*keyLen)
+
+// parseKey accepts either keyLen raw bytes or their hex encoding.
+func parseKey(contents []byte) ([]byte, error) {
+	if len(contents) == keyLen {
+		return contents, nil
+	}
+	trimmed := bytes.TrimSpace(contents)
+	if len(trimmed) == 2*keyLen {
+		key := make([]byte, keyLen)
+		if _, err := hex.Decode(key, trimmed); err == nil {
+			return key, nil
+		}
+	}
+	return nil, errors.Newf(
+		"expected %d raw bytes or %d hex characters, found %d bytes", keyLen, 2*keyLen, len(contents))
+}
+
+// MasterKeyID implements the KMS interface.
+func (k *fileKMS) MasterKeyID() (string, error) {
+	return k.keyID, nil
+}
+
+// Encrypt implements the KMS interface. The returned ciphertext is the random
+// nonce followed by the sealed data.
+func (k *fileKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// Decrypt implements the KMS interface.
+func (k *fileKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	nonceSize := k.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("ciphertext is too short")
+	}
+	plaintext, err := k.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt with file KMS key")
+	}
+	return plaintext, nil
+}
+
+// Close implements the KMS interface.
+func (k *fileKMS) Close() error {
+	return nil
+}
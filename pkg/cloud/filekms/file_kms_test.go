@@ -0,0 +1,134 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package filekms
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecryptFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	env := &cloud.TestKMSEnv{
+		Settings:         cluster.MakeTestingClusterSettings(),
+		ExternalIOConfig: &base.ExternalIODirConfig{},
+	}
+
+	key := bytes.Repeat([]byte{'k'}, keyLen)
+	writeKey := func(name string, contents []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, contents, 0600))
+		return fmt.Sprintf("file://%s", path)
+	}
+	rawURI := writeKey("raw.key", key)
+	hexURI := writeKey("hex.key", []byte(hex.EncodeToString(key)+"\n"))
+	otherURI := writeKey("other.key", bytes.Repeat([]byte{'o'}, keyLen))
+
+	t.Run("raw", func(t *testing.T) {
+		cloud.KMSEncryptDecrypt(t, rawURI, env)
+	})
+
+	t.Run("hex", func(t *testing.T) {
+		cloud.KMSEncryptDecrypt(t, hexURI, env)
+	})
+
+	t.Run("key-id-independent-of-path", func(t *testing.T) {
+		raw, err := cloud.KMSFromURI(ctx, rawURI, env)
+		require.NoError(t, err)
+		hexKMS, err := cloud.KMSFromURI(ctx, hexURI, env)
+		require.NoError(t, err)
+		other, err := cloud.KMSFromURI(ctx, otherURI, env)
+		require.NoError(t, err)
+
+		rawID, err := raw.MasterKeyID()
+		require.NoError(t, err)
+		hexID, err := hexKMS.MasterKeyID()
+		require.NoError(t, err)
+		otherID, err := other.MasterKeyID()
+		require.NoError(t, err)
+		require.Equal(t, rawID, hexID)
+		require.NotEqual(t, rawID, otherID)
+
+		// Data wrapped through one file can be unwrapped through the other.
+		ciphertext, err := raw.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		plaintext, err := hexKMS.Decrypt(ctx, ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, []byte("data key"), plaintext)
+
+		_, err = other.Decrypt(ctx, ciphertext)
+		require.Error(t, err)
+	})
+
+	// The errors mention neither the path nor the contents of the file, so that
+	// a missing file cannot be told apart from an invalid one.
+	t.Run("bad-key", func(t *testing.T) {
+		uri := writeKey("short.key", []byte("too short"))
+		_, err := cloud.KMSFromURI(ctx, uri, env)
+		require.ErrorIs(t, err, errInvalidKeyFile)
+		require.NotContains(t, err.Error(), dir)
+	})
+
+	t.Run("missing-file", func(t *testing.T) {
+		_, err := cloud.KMSFromURI(ctx, fmt.Sprintf("file://%s", filepath.Join(dir, "missing")), env)
+		require.ErrorIs(t, err, errInvalidKeyFile)
+		require.NotContains(t, err.Error(), dir)
+	})
+
+	t.Run("implicit-credentials-disabled", func(t *testing.T) {
+		disabledEnv := &cloud.TestKMSEnv{
+			Settings:         cluster.MakeTestingClusterSettings(),
+			ExternalIOConfig: &base.ExternalIODirConfig{DisableImplicitCredentials: true},
+		}
+		_, err := cloud.KMSFromURI(ctx, rawURI, disabledEnv)
+		require.Regexp(t, "file KMS disallowed", err)
+	})
+
+	t.Run("host", func(t *testing.T) {
+		_, err := cloud.KMSFromURI(ctx, "file://somehost/key", env)
+		require.Error(t, err)
+		require.Regexp(t, "must not specify a host", err)
+	})
+}
+
+func TestFileKMSAccessIsImplicit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		uri      string
+		explicit bool
+	}{
+		{uri: "file:///path/to/key", explicit: false},
+		{uri: "aws:///key?AUTH=implicit&REGION=us-east-1", explicit: false},
+		{uri: "aws:///key?AUTH=specified&REGION=us-east-1", explicit: true},
+	} {
+		explicit, err := cloud.KMSAccessIsWithExplicitAuth(tc.uri)
+		require.NoError(t, err)
+		require.Equal(t, tc.explicit, explicit, tc.uri)
+	}
+}
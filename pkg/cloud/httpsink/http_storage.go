@@ -48,6 +48,10 @@ type httpStorage struct {
 	hosts    []string
 	settings *cluster.Settings
 	ioConf   base.ExternalIODirConfig
+
+	// user and password, if set, are sent as HTTP basic auth credentials with
+	// every request.
+	user, password string
 }
 
 var _ cloud.ExternalStorage = &httpStorage{}
@@ -251,6 +255,9 @@ func (h *httpStorage) req(
 	for key, val := range headers {
 		req.Header.Add(key, val)
 	}
+	if h.user != "" {
+		req.SetBasicAuth(h.user, h.password)
+	}
 
 	resp, err := h.client.Do(req)
 	if err != nil {
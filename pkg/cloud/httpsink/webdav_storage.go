@@ -0,0 +1,320 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package httpsink
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// WebDAVPasswordParam is the query parameter for the password sent, along
+	// with the user in the URI, as HTTP basic auth credentials.
+	WebDAVPasswordParam = "WEBDAV_PASSWORD"
+
+	webdavScheme     = "webdav"
+	webdavHTTPScheme = "webdav+http"
+)
+
+func parseWebDAVURL(
+	_ cloud.ExternalStorageURIContext, uri *url.URL,
+) (roachpb.ExternalStorage, error) {
+	conf := roachpb.ExternalStorage{}
+	if uri.Host == "" {
+		return conf, errors.Errorf("webdav URI must specify the address of the server: %s", uri.String())
+	}
+	var user string
+	if uri.User != nil {
+		if _, ok := uri.User.Password(); ok {
+			return conf, errors.Errorf(
+				"webdav URI must not embed a password; use the %s parameter instead", WebDAVPasswordParam)
+		}
+		user = uri.User.Username()
+	}
+
+	// webdav:// talks to the server over https; webdav+http:// over plain http.
+	base := url.URL{Scheme: "https", Host: uri.Host, Path: uri.Path}
+	if uri.Scheme == webdavHTTPScheme {
+		base.Scheme = "http"
+	}
+
+	conf.Provider = roachpb.ExternalStorageProvider_webdav
+	conf.WebDAVConfig = &roachpb.ExternalStorage_WebDAV{
+		BaseUri:  base.String(),
+		User:     user,
+		Password: uri.Query().Get(WebDAVPasswordParam),
+	}
+	return conf, nil
+}
+
+// webdavStorage is an ExternalStorage backed by a collection on a WebDAV
+// server. Reads, deletes and size lookups are plain HTTP and are served by the
+// embedded httpStorage; writes additionally create any missing parent
+// collections, and listing is implemented with PROPFIND.
+type webdavStorage struct {
+	*httpStorage
+	conf *roachpb.ExternalStorage_WebDAV
+}
+
+var _ cloud.ExternalStorage = &webdavStorage{}
+
+// MakeWebDAVStorage returns an instance of an ExternalStorage backed by a
+// WebDAV server.
+func MakeWebDAVStorage(
+	ctx context.Context, args cloud.ExternalStorageContext, dest roachpb.ExternalStorage,
+) (cloud.ExternalStorage, error) {
+	telemetry.Count("external-io.webdav")
+	if args.IOConf.DisableHTTP {
+		return nil, errors.New("external http access disabled")
+	}
+	conf := dest.WebDAVConfig
+	if conf == nil || conf.BaseUri == "" {
+		return nil, errors.Errorf("webdav storage requested but base URI not provided")
+	}
+
+	client, err := cloud.MakeHTTPClient(args.Settings)
+	if err != nil {
+		return nil, err
+	}
+	uri, err := url.Parse(conf.BaseUri)
+	if err != nil {
+		return nil, err
+	}
+	return &webdavStorage{
+		httpStorage: &httpStorage{
+			base:     uri,
+			client:   client,
+			hosts:    []string{uri.Host},
+			settings: args.Settings,
+			ioConf:   args.IOConf,
+			user:     conf.User,
+			password: conf.Password,
+		},
+		conf: conf,
+	}, nil
+}
+
+func (w *webdavStorage) Conf() roachpb.ExternalStorage {
+	return roachpb.ExternalStorage{
+		Provider:     roachpb.ExternalStorageProvider_webdav,
+		WebDAVConfig: w.conf,
+	}
+}
+
+func (w *webdavStorage) Writer(ctx context.Context, basename string) (io.WriteCloser, error) {
+	return cloud.BackgroundPipe(ctx, func(ctx context.Context, r io.Reader) error {
+		if err := w.ensureCollection(ctx, path.Dir(path.Join(w.base.Path, basename))); err != nil {
+			return err
+		}
+		_, err := w.reqNoBody(ctx, "PUT", basename, r)
+		return err
+	}), nil
+}
+
+func (w *webdavStorage) List(ctx context.Context, prefix, delim string, fn cloud.ListingFn) error {
+	dest := cloud.JoinPathPreservingTrailingSlash(w.base.Path, prefix)
+
+	// Walk the collection containing dest, unless dest itself is a
+	// collection, and filter out anything that does not share the prefix.
+	walkRoot := dest
+	if entries, err := w.propfind(ctx, dest, "0"); err != nil || len(entries) != 1 || !entries[0].isCollection() {
+		walkRoot = path.Dir(dest)
+	}
+
+	var res []string
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		entries, err := w.propfind(ctx, dir, "1")
+		if err != nil {
+			if errors.Is(err, cloud.ErrFileDoesNotExist) {
+				return nil
+			}
+			return err
+		}
+		for _, e := range entries {
+			p, err := e.path()
+			if err != nil {
+				return err
+			}
+			if path.Clean(p) == path.Clean(dir) {
+				// A Depth: 1 PROPFIND includes the collection itself.
+				continue
+			}
+			if e.isCollection() {
+				if err := walk(p); err != nil {
+					return err
+				}
+				continue
+			}
+			if strings.HasPrefix(p, dest) {
+				res = append(res, p)
+			}
+		}
+		return nil
+	}
+	if err := walk(walkRoot); err != nil {
+		return errors.Wrap(err, "unable to list files in webdav storage")
+	}
+
+	// Sort results so that we can group as we go.
+	sort.Strings(res)
+	var prevPrefix string
+	for _, f := range res {
+		f = strings.TrimPrefix(f, dest)
+		if delim != "" {
+			if i := strings.Index(f, delim); i >= 0 {
+				f = f[:i+len(delim)]
+			}
+			if f == prevPrefix {
+				continue
+			}
+			prevPrefix = f
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureCollection creates the collection at the absolute path dir, and any
+// missing ancestors of it, if it does not already exist.
+func (w *webdavStorage) ensureCollection(ctx context.Context, dir string) error {
+	if dir == "/" || dir == "." {
+		return nil
+	}
+	if _, err := w.propfind(ctx, dir, "0"); err == nil {
+		return nil
+	} else if !errors.Is(err, cloud.ErrFileDoesNotExist) {
+		return err
+	}
+	if err := w.ensureCollection(ctx, path.Dir(dir)); err != nil {
+		return err
+	}
+	resp, err := w.do(ctx, "MKCOL", dir, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusCreated, http.StatusMethodNotAllowed:
+		// 405 means the collection was created concurrently.
+		return nil
+	default:
+		return errors.Errorf("error creating webdav collection %s: %s", dir, resp.Status)
+	}
+}
+
+// davResponse is a single response element of a PROPFIND multistatus reply.
+type davResponse struct {
+	Href     string `xml:"DAV: href"`
+	Propstat []struct {
+		Prop struct {
+			ResourceType struct {
+				Collection *struct{} `xml:"DAV: collection"`
+			} `xml:"DAV: resourcetype"`
+		} `xml:"DAV: prop"`
+	} `xml:"DAV: propstat"`
+}
+
+func (r davResponse) isCollection() bool {
+	for _, ps := range r.Propstat {
+		if ps.Prop.ResourceType.Collection != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// path returns the unescaped path of the resource. Servers may return either
+// an absolute path or a full URL as the href.
+func (r davResponse) path() (string, error) {
+	u, err := url.Parse(r.Href)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing webdav href %q", r.Href)
+	}
+	return u.Path, nil
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<D:propfind xmlns:D="DAV:"><D:prop><D:resourcetype/></D:prop></D:propfind>`
+
+// propfind issues a PROPFIND for the absolute path p with the given depth
+// ("0" or "1") and returns the resources described by the reply. A missing
+// resource yields cloud.ErrFileDoesNotExist.
+func (w *webdavStorage) propfind(ctx context.Context, p, depth string) ([]davResponse, error) {
+	resp, err := w.do(ctx, "PROPFIND", p, strings.NewReader(propfindBody), map[string]string{
+		"Depth":        depth,
+		"Content-Type": "application/xml; charset=utf-8",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusMultiStatus:
+	case http.StatusNotFound:
+		return nil, errors.Wrapf(cloud.ErrFileDoesNotExist, "webdav resource %s does not exist", p)
+	default:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, errors.Errorf("error response from server: %s %q", resp.Status, body)
+	}
+
+	var ms struct {
+		Responses []davResponse `xml:"DAV: response"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, errors.Wrap(err, "decoding PROPFIND response")
+	}
+	return ms.Responses, nil
+}
+
+// do issues a request against the absolute path p on the server and returns
+// the response without interpreting its status code.
+func (w *webdavStorage) do(
+	ctx context.Context, method, p string, body io.Reader, headers map[string]string,
+) (*http.Response, error) {
+	dest := *w.base
+	dest.Path = p
+	req, err := http.NewRequestWithContext(ctx, method, dest.String(), body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error constructing request %s %q", method, dest.String())
+	}
+	for key, val := range headers {
+		req.Header.Set(key, val)
+	}
+	if w.user != "" {
+		req.SetBasicAuth(w.user, w.password)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", method, dest.String())
+	}
+	return resp, nil
+}
+
+func init() {
+	cloud.RegisterExternalStorageProvider(roachpb.ExternalStorageProvider_webdav,
+		parseWebDAVURL, MakeWebDAVStorage, cloud.RedactedParams(WebDAVPasswordParam),
+		webdavScheme, webdavHTTPScheme)
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package httpsink
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudtestutils"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/webdav"
+)
+
+func TestPutWebDAV(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tmp, dirCleanup := testutils.TempDir(t)
+	defer dirCleanup()
+
+	const davUser, davPassword = "backup", "hunter2"
+	dav := &webdav.Handler{
+		FileSystem: webdav.Dir(tmp),
+		LockSystem: webdav.NewMemLS(),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != davUser || p != davPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		dav.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	testSettings := cluster.MakeTestingClusterSettings()
+	user := username.RootUserName()
+	ctx := context.Background()
+
+	makeURI := func(path, password string) string {
+		params := url.Values{}
+		params.Set(WebDAVPasswordParam, password)
+		return fmt.Sprintf("%s://%s@%s%s?%s", webdavHTTPScheme, davUser, host, path, params.Encode())
+	}
+
+	t.Run("export-store", func(t *testing.T) {
+		cloudtestutils.CheckExportStore(t, makeURI("/backups", davPassword),
+			false /* skipSingleFile */, user, nil, nil, testSettings)
+		cloudtestutils.CheckListFiles(t, makeURI("/listing-test/basepath", davPassword),
+			user, nil, nil, testSettings)
+	})
+
+	t.Run("bad-password", func(t *testing.T) {
+		conf, err := cloud.ExternalStorageConfFromURI(makeURI("/backups", "wrong"), user)
+		require.NoError(t, err)
+		s, err := cloud.MakeExternalStorage(ctx, conf, base.ExternalIODirConfig{}, testSettings,
+			nil, nil, nil, nil)
+		require.NoError(t, err)
+		defer s.Close()
+		err = cloud.WriteFile(ctx, s, "file", strings.NewReader("data"))
+		require.Error(t, err)
+		require.Regexp(t, "401 Unauthorized", err)
+	})
+
+	t.Run("disable-http", func(t *testing.T) {
+		conf, err := cloud.ExternalStorageConfFromURI(makeURI("/backups", davPassword), user)
+		require.NoError(t, err)
+		_, err = cloud.MakeExternalStorage(ctx, conf, base.ExternalIODirConfig{DisableHTTP: true},
+			testSettings, nil, nil, nil, nil)
+		require.Regexp(t, "external http access disabled", err)
+	})
+
+	t.Run("password-in-uri", func(t *testing.T) {
+		_, err := cloud.ExternalStorageConfFromURI(
+			fmt.Sprintf("webdav://%s:%s@%s/backups", davUser, davPassword, host), user)
+		require.Regexp(t, "must not embed a password", err)
+	})
+}
+
+func TestWebDAVRedactsPassword(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	redacted, err := cloud.SanitizeExternalStorageURI(
+		"webdav://u@host/path?WEBDAV_PASSWORD=secret", nil /* extraParams */)
+	require.NoError(t, err)
+	require.NotContains(t, redacted, "secret")
+}
@@ -19,9 +19,12 @@ import (
 	// Import all the cloud provider packages to register them.
 	_ "github.com/cockroachdb/cockroach/pkg/cloud/amazon"
 	_ "github.com/cockroachdb/cockroach/pkg/cloud/azure"
+	_ "github.com/cockroachdb/cockroach/pkg/cloud/filekms"
 	_ "github.com/cockroachdb/cockroach/pkg/cloud/gcp"
 	_ "github.com/cockroachdb/cockroach/pkg/cloud/httpsink"
 	_ "github.com/cockroachdb/cockroach/pkg/cloud/nodelocal"
 	_ "github.com/cockroachdb/cockroach/pkg/cloud/nullsink"
+	_ "github.com/cockroachdb/cockroach/pkg/cloud/sftpsink"
 	_ "github.com/cockroachdb/cockroach/pkg/cloud/userfile"
+	_ "github.com/cockroachdb/cockroach/pkg/cloud/vault"
 )
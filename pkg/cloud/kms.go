@@ -56,6 +56,41 @@ func RegisterKMSFromURIFactory(factory KMSFromURIFactory, scheme string) {
 	kmsFactoryMap[scheme] = factory
 }
 
+// RegisterKMSRedactedParams is used by KMS implementations whose URIs carry
+// secrets, such as access tokens, to have those query parameters redacted
+// whenever the URI is displayed to a user.
+func RegisterKMSRedactedParams(params map[string]struct{}) {
+	for param := range params {
+		redactedQueryParams[param] = struct{}{}
+	}
+}
+
+// Set of KMS schemes whose keys are accessed implicitly as the node.
+var implicitAccessKMSSchemes = make(map[string]struct{})
+
+// RegisterKMSImplicitAccessScheme is used by KMS implementations which access
+// their keys through something about the node, such as its filesystem, rather
+// than through credentials carried in the URI.
+func RegisterKMSImplicitAccessScheme(scheme string) {
+	implicitAccessKMSSchemes[scheme] = struct{}{}
+}
+
+// KMSAccessIsWithExplicitAuth returns true if the KMS URI carries its own
+// explicit credentials to use for access, as opposed to using something about
+// the node to gain implicit access, such as a VM's machine account or the
+// node's file system. It is the KMS counterpart of
+// roachpb.ExternalStorage.AccessIsWithExplicitAuth.
+func KMSAccessIsWithExplicitAuth(uri string) (bool, error) {
+	kmsURL, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := implicitAccessKMSSchemes[kmsURL.Scheme]; ok {
+		return false, nil
+	}
+	return kmsURL.Query().Get(AuthParam) != AuthParamImplicit, nil
+}
+
 // KMSFromURI is the method used to create a KMS instance from the provided URI.
 func KMSFromURI(ctx context.Context, uri string, env KMSEnv) (KMS, error) {
 	var kmsURL *url.URL
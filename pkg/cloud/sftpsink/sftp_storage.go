@@ -0,0 +1,342 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sftpsink
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/ioctx"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// PasswordParam is the query parameter for the password used to
+	// authenticate against the SFTP server.
+	PasswordParam = "SFTP_PASSWORD"
+	// PrivateKeyParam is the query parameter for the base64-encoded, PEM
+	// formatted private key used to authenticate against the SFTP server.
+	PrivateKeyParam = "SFTP_PRIVATE_KEY"
+	// HostKeyFingerprintParam is the query parameter for the SHA256 fingerprint
+	// of the host key the SFTP server must present, e.g.
+	// "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8".
+	HostKeyFingerprintParam = "SFTP_HOST_KEY_FINGERPRINT"
+	// InsecureIgnoreHostKeyParam is the query parameter which, when set to
+	// "true", disables verification of the server's host key.
+	InsecureIgnoreHostKeyParam = "SFTP_INSECURE_IGNORE_HOST_KEY"
+
+	defaultPort = "22"
+)
+
+func parseSFTPURL(
+	_ cloud.ExternalStorageURIContext, uri *url.URL,
+) (roachpb.ExternalStorage, error) {
+	conf := roachpb.ExternalStorage{}
+	if uri.Host == "" {
+		return conf, errors.Errorf("sftp URI must specify the address of the server: %s", uri.String())
+	}
+	if uri.User == nil || uri.User.Username() == "" {
+		return conf, errors.Errorf("sftp URI must specify a user, as sftp://<user>@<host>/<path>")
+	}
+	if _, ok := uri.User.Password(); ok {
+		return conf, errors.Errorf(
+			"sftp URI must not embed a password; use the %s parameter instead", PasswordParam)
+	}
+
+	var privateKey string
+	if encoded := uri.Query().Get(PrivateKeyParam); encoded != "" {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return conf, errors.Wrapf(err, "decoding value of %s", PrivateKeyParam)
+		}
+		privateKey = string(decoded)
+	}
+
+	var insecureIgnoreHostKey bool
+	if v := uri.Query().Get(InsecureIgnoreHostKeyParam); v != "" {
+		var err error
+		if insecureIgnoreHostKey, err = strconv.ParseBool(v); err != nil {
+			return conf, errors.Wrapf(err, "parsing value of %s", InsecureIgnoreHostKeyParam)
+		}
+	}
+
+	conf.Provider = roachpb.ExternalStorageProvider_sftp
+	conf.SFTPConfig = &roachpb.ExternalStorage_SFTP{
+		Host:                  uri.Host,
+		Path:                  uri.Path,
+		User:                  uri.User.Username(),
+		Password:              uri.Query().Get(PasswordParam),
+		PrivateKey:            privateKey,
+		HostKeyFingerprint:    uri.Query().Get(HostKeyFingerprintParam),
+		InsecureIgnoreHostKey: insecureIgnoreHostKey,
+	}
+	return conf, nil
+}
+
+type sftpStorage struct {
+	conf     *roachpb.ExternalStorage_SFTP
+	ioConf   base.ExternalIODirConfig
+	settings *cluster.Settings
+	prefix   string
+
+	sshClient *ssh.Client
+	client    *sftp.Client
+}
+
+var _ cloud.ExternalStorage = &sftpStorage{}
+
+// MakeSFTPStorage returns an instance of an ExternalStorage backed by a
+// directory on an SFTP server.
+func MakeSFTPStorage(
+	ctx context.Context, args cloud.ExternalStorageContext, dest roachpb.ExternalStorage,
+) (cloud.ExternalStorage, error) {
+	telemetry.Count("external-io.sftp")
+	if args.IOConf.DisableOutbound {
+		return nil, errors.New("external network access is disabled")
+	}
+	conf := dest.SFTPConfig
+	if conf == nil {
+		return nil, errors.Errorf("sftp upload requested but info missing")
+	}
+
+	var auth []ssh.AuthMethod
+	if conf.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(conf.PrivateKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing sftp private key")
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if conf.Password != "" {
+		auth = append(auth, ssh.Password(conf.Password))
+	}
+	if len(auth) == 0 {
+		return nil, errors.Errorf("one of %s or %s must be set", PasswordParam, PrivateKeyParam)
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	switch {
+	case conf.HostKeyFingerprint != "":
+		hostKeyCallback = func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			if got := ssh.FingerprintSHA256(key); got != conf.HostKeyFingerprint {
+				return errors.Errorf(
+					"sftp host key fingerprint mismatch: expected %s, got %s", conf.HostKeyFingerprint, got)
+			}
+			return nil
+		}
+	case conf.InsecureIgnoreHostKey:
+		// nolint:gosec
+		hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, errors.Errorf(
+			"one of %s or %s must be set", HostKeyFingerprintParam, InsecureIgnoreHostKeyParam)
+	}
+
+	addr := conf.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, defaultPort)
+	}
+	sshClient, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            conf.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         cloud.Timeout.Get(&args.Settings.SV),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to sftp server %s", conf.Host)
+	}
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, errors.Wrap(err, "starting sftp session")
+	}
+
+	return &sftpStorage{
+		conf:      conf,
+		ioConf:    args.IOConf,
+		settings:  args.Settings,
+		prefix:    conf.Path,
+		sshClient: sshClient,
+		client:    client,
+	}, nil
+}
+
+func (s *sftpStorage) Conf() roachpb.ExternalStorage {
+	return roachpb.ExternalStorage{
+		Provider:   roachpb.ExternalStorageProvider_sftp,
+		SFTPConfig: s.conf,
+	}
+}
+
+func (s *sftpStorage) ExternalIOConf() base.ExternalIODirConfig {
+	return s.ioConf
+}
+
+func (s *sftpStorage) RequiresExternalIOAccounting() bool { return true }
+
+func (s *sftpStorage) Settings() *cluster.Settings {
+	return s.settings
+}
+
+func (s *sftpStorage) ReadFile(ctx context.Context, basename string) (ioctx.ReadCloserCtx, error) {
+	reader, _, err := s.ReadFileAt(ctx, basename, 0)
+	return reader, err
+}
+
+func (s *sftpStorage) ReadFileAt(
+	ctx context.Context, basename string, offset int64,
+) (ioctx.ReadCloserCtx, int64, error) {
+	f, err := s.client.Open(path.Join(s.prefix, basename))
+	if err != nil {
+		return nil, 0, wrapNotExist(err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, 0, err
+	}
+	if offset != 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			_ = f.Close()
+			return nil, 0, err
+		}
+	}
+	return ioctx.ReadCloserAdapter(f), info.Size(), nil
+}
+
+// Writer writes to a temporary file next to the destination and renames it
+// into place once the write completes, so readers never observe a partially
+// written file.
+func (s *sftpStorage) Writer(ctx context.Context, basename string) (io.WriteCloser, error) {
+	dest := path.Join(s.prefix, basename)
+	return cloud.BackgroundPipe(ctx, func(ctx context.Context, r io.Reader) error {
+		if err := s.client.MkdirAll(path.Dir(dest)); err != nil {
+			return errors.Wrapf(err, "creating directory for %s", dest)
+		}
+		tmp := fmt.Sprintf("%s.%s.tmp", dest, uuid.FastMakeV4().Short())
+		f, err := s.client.Create(tmp)
+		if err != nil {
+			return errors.Wrapf(err, "creating %s", tmp)
+		}
+		if _, err := f.ReadFrom(r); err != nil {
+			_ = f.Close()
+			_ = s.client.Remove(tmp)
+			return errors.Wrapf(err, "writing %s", tmp)
+		}
+		if err := f.Close(); err != nil {
+			_ = s.client.Remove(tmp)
+			return errors.Wrapf(err, "closing %s", tmp)
+		}
+		if err := s.client.PosixRename(tmp, dest); err != nil {
+			_ = s.client.Remove(tmp)
+			return errors.Wrapf(err, "renaming %s to %s", tmp, dest)
+		}
+		return nil
+	}), nil
+}
+
+func (s *sftpStorage) List(ctx context.Context, prefix, delim string, fn cloud.ListingFn) error {
+	dest := cloud.JoinPathPreservingTrailingSlash(s.prefix, prefix)
+
+	// Walk the directory containing dest, unless dest itself is a directory,
+	// and filter out anything that does not share the prefix.
+	walkRoot := dest
+	if info, err := s.client.Stat(dest); err != nil || !info.IsDir() {
+		walkRoot = path.Dir(dest)
+	}
+
+	var res []string
+	walker := s.client.Walk(walkRoot)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			if oserror.IsNotExist(err) {
+				continue
+			}
+			return errors.Wrap(err, "unable to list files in sftp storage")
+		}
+		if walker.Stat().IsDir() || !strings.HasPrefix(walker.Path(), dest) {
+			continue
+		}
+		res = append(res, walker.Path())
+	}
+
+	// Sort results so that we can group as we go.
+	sort.Strings(res)
+	var prevPrefix string
+	for _, f := range res {
+		f = strings.TrimPrefix(f, dest)
+		if delim != "" {
+			if i := strings.Index(f, delim); i >= 0 {
+				f = f[:i+len(delim)]
+			}
+			if f == prevPrefix {
+				continue
+			}
+			prevPrefix = f
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sftpStorage) Delete(ctx context.Context, basename string) error {
+	return wrapNotExist(s.client.Remove(path.Join(s.prefix, basename)))
+}
+
+func (s *sftpStorage) Size(ctx context.Context, basename string) (int64, error) {
+	info, err := s.client.Stat(path.Join(s.prefix, basename))
+	if err != nil {
+		return 0, wrapNotExist(err)
+	}
+	return info.Size(), nil
+}
+
+func (s *sftpStorage) Close() error {
+	return errors.CombineErrors(s.client.Close(), s.sshClient.Close())
+}
+
+// wrapNotExist marks errors indicating a missing file with
+// cloud.ErrFileDoesNotExist.
+func wrapNotExist(err error) error {
+	if err != nil && oserror.IsNotExist(err) {
+		// nolint:errwrap
+		return errors.WithMessagef(
+			errors.Wrap(cloud.ErrFileDoesNotExist, "sftp storage file does not exist"),
+			"%s",
+			err.Error(),
+		)
+	}
+	return err
+}
+
+func init() {
+	cloud.RegisterExternalStorageProvider(roachpb.ExternalStorageProvider_sftp,
+		parseSFTPURL, MakeSFTPStorage, cloud.RedactedParams(PasswordParam, PrivateKeyParam), "sftp")
+}
@@ -0,0 +1,249 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sftpsink
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudtestutils"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/errors"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	testUser     = "backup"
+	testPassword = "hunter2"
+)
+
+// testServer is an in-process SSH server which serves the sftp subsystem out
+// of the local filesystem.
+type testServer struct {
+	addr        string
+	fingerprint string
+	clientKey   []byte
+
+	listener net.Listener
+	wg       sync.WaitGroup
+	mu       struct {
+		sync.Mutex
+		conns []net.Conn
+	}
+}
+
+func startTestServer(t *testing.T) *testServer {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	// Generate a client key pair and authorize its public half.
+	clientPub, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	authorized, err := ssh.NewPublicKey(clientPub)
+	require.NoError(t, err)
+	clientKeyDER, err := x509.MarshalPKCS8PrivateKey(clientPriv)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == testUser && string(pass) == testPassword {
+				return nil, nil
+			}
+			return nil, errors.New("password rejected")
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if c.User() == testUser && string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("public key rejected")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &testServer{
+		addr:        listener.Addr().String(),
+		fingerprint: ssh.FingerprintSHA256(hostSigner.PublicKey()),
+		clientKey:   pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: clientKeyDER}),
+		listener:    listener,
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.mu.conns = append(s.mu.conns, conn)
+			s.mu.Unlock()
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serveConn(conn, config)
+			}()
+		}
+	}()
+	return s
+}
+
+func (s *testServer) serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			_ = newChan.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChan.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				// The payload of a subsystem request is a length-prefixed name.
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+				server, err := sftp.NewServer(channel)
+				if err != nil {
+					_ = channel.Close()
+					return
+				}
+				_ = server.Serve()
+				_ = server.Close()
+			}
+		}()
+	}
+}
+
+func (s *testServer) Stop() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	for _, c := range s.mu.conns {
+		_ = c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func TestPutSFTP(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	srv := startTestServer(t)
+	defer srv.Stop()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	testSettings := cluster.MakeTestingClusterSettings()
+	user := username.RootUserName()
+
+	makeURI := func(path string, params url.Values) string {
+		return fmt.Sprintf("sftp://%s@%s%s?%s", testUser, srv.addr, path, params.Encode())
+	}
+
+	t.Run("password", func(t *testing.T) {
+		params := url.Values{}
+		params.Set(PasswordParam, testPassword)
+		params.Set(HostKeyFingerprintParam, srv.fingerprint)
+
+		cloudtestutils.CheckExportStore(t, makeURI(dir+"/backup", params),
+			false /* skipSingleFile */, user, nil, nil, testSettings)
+		cloudtestutils.CheckListFiles(t, makeURI(dir+"/listing-test/basepath", params),
+			user, nil, nil, testSettings)
+	})
+
+	t.Run("private-key", func(t *testing.T) {
+		params := url.Values{}
+		params.Set(PrivateKeyParam, base64.StdEncoding.EncodeToString(srv.clientKey))
+		params.Set(InsecureIgnoreHostKeyParam, "true")
+
+		cloudtestutils.CheckExportStore(t, makeURI(dir+"/backup-key", params),
+			false /* skipSingleFile */, user, nil, nil, testSettings)
+	})
+
+	ctx := context.Background()
+	open := func(uri string) error {
+		conf, err := cloud.ExternalStorageConfFromURI(uri, user)
+		if err != nil {
+			return err
+		}
+		s, err := cloud.MakeExternalStorage(ctx, conf, base.ExternalIODirConfig{}, testSettings,
+			nil, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+		return s.Close()
+	}
+
+	t.Run("bad-host-key", func(t *testing.T) {
+		params := url.Values{}
+		params.Set(PasswordParam, testPassword)
+		params.Set(HostKeyFingerprintParam, "SHA256:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")
+		require.Regexp(t, "host key fingerprint mismatch", open(makeURI(dir, params)))
+	})
+
+	t.Run("no-host-key", func(t *testing.T) {
+		params := url.Values{}
+		params.Set(PasswordParam, testPassword)
+		require.Regexp(t, "SFTP_HOST_KEY_FINGERPRINT or SFTP_INSECURE_IGNORE_HOST_KEY must be set",
+			open(makeURI(dir, params)))
+	})
+
+	t.Run("bad-password", func(t *testing.T) {
+		params := url.Values{}
+		params.Set(PasswordParam, "wrong")
+		params.Set(HostKeyFingerprintParam, srv.fingerprint)
+		require.Regexp(t, "unable to authenticate", open(makeURI(dir, params)))
+	})
+
+	t.Run("password-in-uri", func(t *testing.T) {
+		_, err := cloud.ExternalStorageConfFromURI(
+			fmt.Sprintf("sftp://%s:%s@%s%s", testUser, testPassword, srv.addr, dir), user)
+		require.Regexp(t, "must not embed a password", err)
+	})
+}
+
+func TestSFTPRedactsSecrets(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	redacted, err := cloud.SanitizeExternalStorageURI(
+		"sftp://u@host/path?SFTP_PASSWORD=secret&SFTP_PRIVATE_KEY=alsosecret", nil /* extraParams */)
+	require.NoError(t, err)
+	require.NotContains(t, redacted, "secret")
+}
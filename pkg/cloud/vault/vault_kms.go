@@ -0,0 +1,265 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/ioctx"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	vaultScheme = "vault"
+
+	// TokenParam is the query parameter for the token used to authenticate
+	// against Vault.
+	TokenParam = "VAULT_TOKEN"
+	// NamespaceParam is the query parameter for the Vault Enterprise namespace
+	// the transit engine is mounted in.
+	NamespaceParam = "VAULT_NAMESPACE"
+	// TLSParam is the query parameter which, when set to "false", makes the KMS
+	// talk to Vault over plain HTTP. It defaults to "true".
+	TLSParam = "VAULT_TLS"
+
+	// tokenEnvVar is consulted for the token when AUTH=implicit.
+	tokenEnvVar = "VAULT_TOKEN"
+
+	// maxResponseBytes bounds how much of a Vault response we will read. Transit
+	// responses carry a single wrapped data key, so this is generous.
+	maxResponseBytes = 1 << 20
+)
+
+// vaultKMS wraps and unwraps data keys using the transit secrets engine of a
+// HashiCorp Vault server.
+//
+// The URI has the form vault://<host>[:<port>]/<transit mount>/<key name>. The
+// ciphertext returned by Encrypt is the opaque "vault:v<N>:..." string
+// produced by Vault, which embeds the key version, so Decrypt continues to
+// work after the key is rotated in Vault.
+type vaultKMS struct {
+	client    *http.Client
+	addr      string
+	mount     string
+	keyName   string
+	token     string
+	namespace string
+}
+
+var _ cloud.KMS = &vaultKMS{}
+
+func init() {
+	cloud.RegisterKMSFromURIFactory(MakeVaultKMS, vaultScheme)
+	cloud.RegisterKMSRedactedParams(cloud.RedactedParams(TokenParam))
+}
+
+type kmsURIParams struct {
+	token     string
+	namespace string
+	auth      string
+	tls       string
+}
+
+func resolveKMSURIParams(kmsURI url.URL) kmsURIParams {
+	return kmsURIParams{
+		token:     kmsURI.Query().Get(TokenParam),
+		namespace: kmsURI.Query().Get(NamespaceParam),
+		auth:      kmsURI.Query().Get(cloud.AuthParam),
+		tls:       kmsURI.Query().Get(TLSParam),
+	}
+}
+
+// MakeVaultKMS is the factory method which returns a configured, ready-to-use
+// Vault transit KMS object.
+func MakeVaultKMS(ctx context.Context, uri string, env cloud.KMSEnv) (cloud.KMS, error) {
+	if env.KMSConfig().DisableOutbound {
+		return nil, errors.New("external IO must be enabled to use Vault KMS")
+	}
+	kmsURI, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if kmsURI.Host == "" {
+		return nil, errors.New("vault KMS URI must specify the address of the Vault server")
+	}
+
+	// The path is <mount>/<key>, where the mount itself may contain slashes.
+	keyPath := strings.Trim(kmsURI.Path, "/")
+	sep := strings.LastIndex(keyPath, "/")
+	if sep <= 0 || sep == len(keyPath)-1 {
+		return nil, errors.Newf(
+			"vault KMS URI path must be of the form /<transit mount>/<key name>, got %q", kmsURI.Path)
+	}
+
+	kmsURIParams := resolveKMSURIParams(*kmsURI)
+
+	httpScheme := "https"
+	switch kmsURIParams.tls {
+	case "", "true":
+	case "false":
+		if env.KMSConfig().DisableHTTP {
+			return nil, errors.New(
+				"plain HTTP disallowed for vault kms due to --external-io-disable-http flag")
+		}
+		httpScheme = "http"
+	default:
+		return nil, errors.Errorf("unsupported value %s for %s", kmsURIParams.tls, TLSParam)
+	}
+
+	// "specified": use the token provided in the URI; error if not present.
+	// "implicit": read the token from the VAULT_TOKEN environment variable of
+	//             the node.
+	// "": default to `specified`.
+	token := kmsURIParams.token
+	switch kmsURIParams.auth {
+	case "", cloud.AuthParamSpecified:
+		if token == "" {
+			return nil, errors.Errorf(
+				"%s is set to '%s', but %s is not set",
+				cloud.AuthParam,
+				cloud.AuthParamSpecified,
+				TokenParam,
+			)
+		}
+	case cloud.AuthParamImplicit:
+		if env.KMSConfig().DisableImplicitCredentials {
+			return nil, errors.New(
+				"implicit credentials disallowed for vault due to --external-io-disable-implicit-credentials flag")
+		}
+		token = os.Getenv(tokenEnvVar)
+		if token == "" {
+			return nil, errors.Errorf(
+				"%s is set to '%s', but the %s environment variable is not set",
+				cloud.AuthParam,
+				cloud.AuthParamImplicit,
+				tokenEnvVar,
+			)
+		}
+	default:
+		return nil, errors.Errorf("unsupported value %s for %s", kmsURIParams.auth, cloud.AuthParam)
+	}
+
+	client, err := cloud.MakeHTTPClient(env.ClusterSettings())
+	if err != nil {
+		return nil, err
+	}
+
+	return &vaultKMS{
+		client:    client,
+		addr:      (&url.URL{Scheme: httpScheme, Host: kmsURI.Host}).String(),
+		mount:     keyPath[:sep],
+		keyName:   keyPath[sep+1:],
+		token:     token,
+		namespace: kmsURIParams.namespace,
+	}, nil
+}
+
+// MasterKeyID implements the KMS interface.
+//
+// The ID deliberately excludes the address of the Vault server so that a
+// backup can still be decrypted after the server is reached through a
+// different hostname.
+func (k *vaultKMS) MasterKeyID() (string, error) {
+	return path.Join(k.mount, k.keyName), nil
+}
+
+// Encrypt implements the KMS interface.
+func (k *vaultKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	req := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(data)}
+	if err := k.do(ctx, "encrypt", req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.Ciphertext == "" {
+		return nil, errors.New("vault returned an empty ciphertext")
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+// Decrypt implements the KMS interface.
+func (k *vaultKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	req := map[string]string{"ciphertext": string(data)}
+	if err := k.do(ctx, "decrypt", req, &resp); err != nil {
+		return nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding plaintext returned by vault")
+	}
+	return plaintext, nil
+}
+
+// Close implements the KMS interface.
+func (k *vaultKMS) Close() error {
+	k.client.CloseIdleConnections()
+	return nil
+}
+
+// do issues a request against the transit endpoint op (encrypt or decrypt)
+// for the configured key and decodes the JSON response into resp.
+func (k *vaultKMS) do(ctx context.Context, op string, req interface{}, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/v1/%s/%s/%s", k.addr, k.mount, op, url.PathEscape(k.keyName))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Vault-Token", k.token)
+	if k.namespace != "" {
+		httpReq.Header.Set("X-Vault-Namespace", k.namespace)
+	}
+
+	httpResp, err := k.client.Do(httpReq)
+	if err != nil {
+		return errors.Wrapf(err, "vault transit %s", op)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := ioctx.ReadAll(ctx, ioctx.ReaderAdapter(io.LimitReader(httpResp.Body, maxResponseBytes)))
+	if err != nil {
+		return errors.Wrapf(err, "reading vault transit %s response", op)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(respBody, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			return errors.Newf("vault transit %s: %s: %s",
+				op, httpResp.Status, strings.Join(vaultErr.Errors, "; "))
+		}
+		return errors.Newf("vault transit %s: %s", op, httpResp.Status)
+	}
+	return errors.Wrapf(json.Unmarshal(respBody, resp), "decoding vault transit %s response", op)
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "s.testtoken"
+
+// fakeTransit is a minimal stand-in for the Vault transit secrets engine. It
+// "encrypts" by tagging the plaintext with the key name, which is enough to
+// verify that the KMS talks to the right endpoint with the right key.
+func fakeTransit(mount string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != testToken {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/"+mount+"/"), "/")
+		if len(parts) != 2 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		op, key := parts[0], parts[1]
+
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var data map[string]string
+		switch op {
+		case "encrypt":
+			data = map[string]string{
+				"ciphertext": fmt.Sprintf("vault:v1:%s:%s", key, req["plaintext"]),
+			}
+		case "decrypt":
+			prefix := fmt.Sprintf("vault:v1:%s:", key)
+			if !strings.HasPrefix(req["ciphertext"], prefix) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"errors":["cipher: message authentication failed"]}`))
+				return
+			}
+			data = map[string]string{"plaintext": strings.TrimPrefix(req["ciphertext"], prefix)}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+}
+
+func TestEncryptDecryptVault(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	srv := fakeTransit("transit")
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	env := &cloud.TestKMSEnv{
+		Settings:         cluster.MakeTestingClusterSettings(),
+		ExternalIOConfig: &base.ExternalIODirConfig{},
+	}
+
+	params := func(kv ...string) string {
+		q := make(url.Values)
+		q.Set(TLSParam, "false")
+		for i := 0; i < len(kv); i += 2 {
+			q.Set(kv[i], kv[i+1])
+		}
+		return q.Encode()
+	}
+
+	t.Run("auth-specified", func(t *testing.T) {
+		uri := fmt.Sprintf("vault://%s/transit/backup-key?%s", host, params(TokenParam, testToken))
+		cloud.KMSEncryptDecrypt(t, uri, env)
+	})
+
+	t.Run("auth-specified-no-token", func(t *testing.T) {
+		uri := fmt.Sprintf("vault://%s/transit/backup-key?%s", host, params())
+		_, err := cloud.KMSFromURI(ctx, uri, env)
+		require.EqualError(t, err, fmt.Sprintf(
+			`%s is set to '%s', but %s is not set`,
+			cloud.AuthParam,
+			cloud.AuthParamSpecified,
+			TokenParam,
+		))
+	})
+
+	t.Run("auth-implicit", func(t *testing.T) {
+		t.Setenv(tokenEnvVar, testToken)
+		uri := fmt.Sprintf("vault://%s/transit/backup-key?%s",
+			host, params(cloud.AuthParam, cloud.AuthParamImplicit))
+		cloud.KMSEncryptDecrypt(t, uri, env)
+
+		_, err := cloud.KMSFromURI(ctx, uri, &cloud.TestKMSEnv{
+			Settings:         cluster.MakeTestingClusterSettings(),
+			ExternalIOConfig: &base.ExternalIODirConfig{DisableImplicitCredentials: true},
+		})
+		require.Error(t, err)
+		require.Regexp(t, "implicit credentials disallowed", err)
+	})
+
+	t.Run("bad-token", func(t *testing.T) {
+		uri := fmt.Sprintf("vault://%s/transit/backup-key?%s", host, params(TokenParam, "wrong"))
+		kms, err := cloud.KMSFromURI(ctx, uri, env)
+		require.NoError(t, err)
+		_, err = kms.Encrypt(ctx, []byte("data key"))
+		require.Error(t, err)
+		require.Regexp(t, "permission denied", err)
+	})
+
+	t.Run("wrong-key", func(t *testing.T) {
+		a, err := cloud.KMSFromURI(ctx,
+			fmt.Sprintf("vault://%s/transit/a?%s", host, params(TokenParam, testToken)), env)
+		require.NoError(t, err)
+		b, err := cloud.KMSFromURI(ctx,
+			fmt.Sprintf("vault://%s/transit/b?%s", host, params(TokenParam, testToken)), env)
+		require.NoError(t, err)
+
+		ciphertext, err := a.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		_, err = b.Decrypt(ctx, ciphertext)
+		require.Error(t, err)
+		require.Regexp(t, "message authentication failed", err)
+	})
+
+	t.Run("master-key-id", func(t *testing.T) {
+		kms, err := cloud.KMSFromURI(ctx,
+			fmt.Sprintf("vault://%s/transit/backup-key?%s", host, params(TokenParam, testToken)), env)
+		require.NoError(t, err)
+		id, err := kms.MasterKeyID()
+		require.NoError(t, err)
+		require.Equal(t, "transit/backup-key", id)
+	})
+
+	t.Run("invalid-uri", func(t *testing.T) {
+		for _, uri := range []string{
+			"vault:///transit/key",
+			fmt.Sprintf("vault://%s/key", host),
+			fmt.Sprintf("vault://%s/transit/", host),
+		} {
+			_, err := cloud.KMSFromURI(ctx, uri+"?"+params(TokenParam, testToken), env)
+			require.Error(t, err, uri)
+		}
+	})
+
+	t.Run("disable-http", func(t *testing.T) {
+		uri := fmt.Sprintf("vault://%s/transit/backup-key?%s", host, params(TokenParam, testToken))
+		_, err := cloud.KMSFromURI(ctx, uri, &cloud.TestKMSEnv{
+			Settings:         cluster.MakeTestingClusterSettings(),
+			ExternalIOConfig: &base.ExternalIODirConfig{DisableHTTP: true},
+		})
+		require.Error(t, err)
+		require.Regexp(t, "plain HTTP disallowed", err)
+	})
+}
+
+func TestVaultTokenRedacted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	redacted, err := cloud.RedactKMSURI("vault://vault.local:8200/transit/key?VAULT_TOKEN=secret")
+	require.NoError(t, err)
+	require.NotContains(t, redacted, "secret")
+}
@@ -380,6 +380,36 @@ const (
 	// AddSSTableTombstones allows writing MVCC point tombstones via AddSSTable.
 	// Previously, SSTs containing these could error.
 	AddSSTableTombstones
+	// CPUBasedRebalancing enables stores to gossip the request CPU time of
+	// their leaseholder replicas, which allows load-based rebalancing and
+	// load-based splitting to use CPU as their objective.
+	CPUBasedRebalancing
+	// MVCCGCRetentionTiers enables GC to downsample the MVCC history below the
+	// GC threshold according to the retention tiers of a range, and ranges to
+	// serve reads at the retained timestamps.
+	MVCCGCRetentionTiers
+	// HotRangesHistoryTable adds system.hot_ranges_history, in which stores
+	// periodically record their hottest and largest ranges.
+	HotRangesHistoryTable
+	// SharedLocks enables locking reads to acquire Shared locks, which nodes
+	// that predate them cannot handle. Until then, SELECT ... FOR SHARE
+	// acquires no locks.
+	SharedLocks
+	// TimeseriesRollupResolutions enables the 1m and 1d time series rollup
+	// resolutions, whose data nodes that predate them would prune as that of an
+	// unknown resolution.
+	TimeseriesRollupResolutions
+	// AutomaticStmtDiagReqs adds the automatic column to the
+	// system.statement_diagnostics_requests table, which marks the requests armed
+	// on behalf of outliers.
+	AutomaticStmtDiagReqs
+	// ReplicatedSharedLocks enables locking reads to acquire replicated Shared
+	// locks, which are stored in the lock table keyspace. Until then, SELECT ...
+	// FOR SHARE acquires unreplicated Shared locks.
+	ReplicatedSharedLocks
+	// ReadOnlyTables enables ALTER TABLE ... SET READ ONLY, which makes the
+	// ranges of a table reject writes through its span configs.
+	ReadOnlyTables
 
 	// *************************************************
 	// Step (1): Add new versions here.
@@ -673,6 +703,38 @@ var versionsSingleton = keyedVersions{
 		Key:     AddSSTableTombstones,
 		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 20},
 	},
+	{
+		Key:     CPUBasedRebalancing,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 22},
+	},
+	{
+		Key:     MVCCGCRetentionTiers,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 24},
+	},
+	{
+		Key:     HotRangesHistoryTable,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 26},
+	},
+	{
+		Key:     SharedLocks,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 28},
+	},
+	{
+		Key:     TimeseriesRollupResolutions,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 30},
+	},
+	{
+		Key:     AutomaticStmtDiagReqs,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 32},
+	},
+	{
+		Key:     ReplicatedSharedLocks,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 34},
+	},
+	{
+		Key:     ReadOnlyTables,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 36},
+	},
 
 	// *************************************************
 	// Step (2): Add new versions here.
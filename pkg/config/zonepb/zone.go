@@ -14,7 +14,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
@@ -22,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
 	"github.com/gogo/protobuf/proto"
 )
@@ -225,6 +229,76 @@ func (c *Constraint) FromString(short string) error {
 	return nil
 }
 
+func (p GCRetentionPolicy) String() string {
+	tiers := make([]string, len(p.Tiers))
+	for i, t := range p.Tiers {
+		tiers[i] = formatRetentionSeconds(t.IntervalSeconds) + ":" + formatRetentionSeconds(t.TTLSeconds)
+	}
+	return strings.Join(tiers, ",")
+}
+
+// FromString populates the retention policy from its shorthand notation, a
+// comma-separated list of "interval:ttl" tiers such as "1h:30d,1d:365d". The
+// empty string is a policy without tiers.
+func (p *GCRetentionPolicy) FromString(short string) error {
+	p.Tiers = nil
+	if len(short) == 0 {
+		return nil
+	}
+	for _, tier := range strings.Split(short, ",") {
+		parts := strings.Split(strings.TrimSpace(tier), ":")
+		if len(parts) != 2 {
+			return errors.Errorf("retention tier needs to be in the form \"interval:ttl\", not %q", tier)
+		}
+		interval, err := parseRetentionSeconds(parts[0])
+		if err != nil {
+			return err
+		}
+		ttl, err := parseRetentionSeconds(parts[1])
+		if err != nil {
+			return err
+		}
+		p.Tiers = append(p.Tiers, GCRetentionTier{IntervalSeconds: interval, TTLSeconds: ttl})
+	}
+	return nil
+}
+
+// parseRetentionSeconds parses a duration of whole seconds which, in addition
+// to the units understood by time.ParseDuration, may be given in days.
+func parseRetentionSeconds(s string) (int32, error) {
+	var d time.Duration
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.ParseInt(days, 10, 32)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid retention duration %q", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, errors.Wrapf(err, "invalid retention duration %q", s)
+		}
+	}
+	if d <= 0 || d%time.Second != 0 || d/time.Second > math.MaxInt32 {
+		return 0, errors.Errorf("retention duration %q must be a positive number of seconds", s)
+	}
+	return int32(d / time.Second), nil
+}
+
+// formatRetentionSeconds formats a duration in the largest unit of
+// parseRetentionSeconds that it is a multiple of.
+func formatRetentionSeconds(seconds int32) string {
+	for _, u := range []struct {
+		suffix  string
+		seconds int32
+	}{{"d", 24 * 60 * 60}, {"h", 60 * 60}, {"m", 60}} {
+		if seconds != 0 && seconds%u.seconds == 0 {
+			return strconv.Itoa(int(seconds/u.seconds)) + u.suffix
+		}
+	}
+	return strconv.Itoa(int(seconds)) + "s"
+}
+
 // NewZoneConfig is the zone configuration used when no custom
 // config has been specified.
 func NewZoneConfig() *ZoneConfig {
@@ -339,6 +413,9 @@ func (z *ZoneConfig) Validate() error {
 		if err := s.Config.Validate(); err != nil {
 			return err
 		}
+		if s.Config.StorageQuotaBytes != nil {
+			return fmt.Errorf("storage_quota_bytes cannot be set on an index or partition")
+		}
 	}
 
 	if z.NumReplicas != nil {
@@ -386,6 +463,22 @@ func (z *ZoneConfig) Validate() error {
 			*z.RangeMinBytes, *z.RangeMaxBytes)
 	}
 
+	if z.StorageQuotaBytes != nil && *z.StorageQuotaBytes < 0 {
+		return fmt.Errorf("StorageQuotaBytes %d less than minimum allowed 0", *z.StorageQuotaBytes)
+	}
+
+	if z.GCRetention != nil {
+		for _, t := range z.GCRetention.Tiers {
+			if t.IntervalSeconds < 60 {
+				return fmt.Errorf("GCRetention interval %ds less than minimum allowed 60s", t.IntervalSeconds)
+			}
+			if t.TTLSeconds <= t.IntervalSeconds {
+				return fmt.Errorf("GCRetention TTL %ds must be greater than interval %ds",
+					t.TTLSeconds, t.IntervalSeconds)
+			}
+		}
+	}
+
 	// Reserve the value 0 to potentially have some special meaning in the future,
 	// such as to disable GC.
 	if z.GC != nil && z.GC.TTLSeconds < 1 {
@@ -560,6 +653,16 @@ func (z *ZoneConfig) InheritFromParent(parent *ZoneConfig) {
 			z.GC = &tempGC
 		}
 	}
+	if z.StorageQuotaBytes == nil {
+		if parent.StorageQuotaBytes != nil {
+			z.StorageQuotaBytes = proto.Int64(*parent.StorageQuotaBytes)
+		}
+	}
+	if z.GCRetention == nil {
+		if parent.GCRetention != nil {
+			z.GCRetention = protoutil.Clone(parent.GCRetention).(*GCRetentionPolicy)
+		}
+	}
 	if z.InheritedConstraints {
 		if !parent.InheritedConstraints {
 			z.Constraints = parent.Constraints
@@ -615,6 +718,16 @@ func (z *ZoneConfig) CopyFromZone(other ZoneConfig, fieldList []tree.Name) {
 				tempGC := *other.GC
 				z.GC = &tempGC
 			}
+		case "storage_quota_bytes":
+			z.StorageQuotaBytes = nil
+			if other.StorageQuotaBytes != nil {
+				z.StorageQuotaBytes = proto.Int64(*other.StorageQuotaBytes)
+			}
+		case "gc.retention_tiers":
+			z.GCRetention = nil
+			if other.GCRetention != nil {
+				z.GCRetention = protoutil.Clone(other.GCRetention).(*GCRetentionPolicy)
+			}
 		case "constraints":
 			z.Constraints = other.Constraints
 			z.InheritedConstraints = other.InheritedConstraints
@@ -722,6 +835,26 @@ func (z *ZoneConfig) DiffWithZone(
 					Field: "gc.ttlseconds",
 				}, nil
 			}
+		case "storage_quota_bytes":
+			if other.StorageQuotaBytes == nil && z.StorageQuotaBytes == nil {
+				continue
+			}
+			if z.StorageQuotaBytes == nil || other.StorageQuotaBytes == nil ||
+				*z.StorageQuotaBytes != *other.StorageQuotaBytes {
+				return false, DiffWithZoneMismatch{
+					Field: "storage_quota_bytes",
+				}, nil
+			}
+		case "gc.retention_tiers":
+			if other.GCRetention == nil && z.GCRetention == nil {
+				continue
+			}
+			if z.GCRetention == nil || other.GCRetention == nil ||
+				!z.GCRetention.Equal(other.GCRetention) {
+				return false, DiffWithZoneMismatch{
+					Field: "gc.retention_tiers",
+				}, nil
+			}
 		case "constraints":
 			if other.Constraints == nil && z.Constraints == nil {
 				continue
@@ -1124,6 +1257,19 @@ func (z *ZoneConfig) toSpanConfig() (roachpb.SpanConfig, error) {
 	if z.NumVoters != nil {
 		sc.NumVoters = *z.NumVoters
 	}
+	// Tables are not subject to a storage quota by default.
+	if z.StorageQuotaBytes != nil {
+		sc.StorageQuotaBytes = *z.StorageQuotaBytes
+	}
+	// The history older than the GC TTL isn't retained by default.
+	if z.GCRetention != nil {
+		for _, t := range z.GCRetention.Tiers {
+			sc.GCPolicy.RetentionTiers = append(sc.GCPolicy.RetentionTiers, roachpb.GCRetentionTier{
+				IntervalSeconds: t.IntervalSeconds,
+				TTLSeconds:      t.TTLSeconds,
+			})
+		}
+	}
 
 	toSpanConfigConstraints := func(src []Constraint) ([]roachpb.Constraint, error) {
 		spanConfigConstraints := make([]roachpb.Constraint, len(src))
@@ -95,6 +95,68 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"is greater than or equal to RangeMaxBytes",
 		},
+		{
+			ZoneConfig{
+				NumReplicas:       proto.Int32(1),
+				RangeMaxBytes:     DefaultZoneConfig().RangeMaxBytes,
+				GC:                &GCPolicy{TTLSeconds: 1},
+				StorageQuotaBytes: proto.Int64(-1),
+			},
+			"StorageQuotaBytes -1 less than minimum allowed",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:       proto.Int32(1),
+				RangeMaxBytes:     DefaultZoneConfig().RangeMaxBytes,
+				GC:                &GCPolicy{TTLSeconds: 1},
+				StorageQuotaBytes: proto.Int64(1 << 30),
+			},
+			"",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(1),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+				Subzones: []Subzone{
+					{IndexID: 1, Config: ZoneConfig{StorageQuotaBytes: proto.Int64(1 << 30)}},
+				},
+			},
+			"storage_quota_bytes cannot be set on an index or partition",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(1),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+				GCRetention: &GCRetentionPolicy{
+					Tiers: []GCRetentionTier{{IntervalSeconds: 30, TTLSeconds: 3600}},
+				},
+			},
+			"GCRetention interval 30s less than minimum allowed 60s",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(1),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+				GCRetention: &GCRetentionPolicy{
+					Tiers: []GCRetentionTier{{IntervalSeconds: 3600, TTLSeconds: 3600}},
+				},
+			},
+			"GCRetention TTL 3600s must be greater than interval 3600s",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(1),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+				GCRetention: &GCRetentionPolicy{
+					Tiers: []GCRetentionTier{{IntervalSeconds: 3600, TTLSeconds: 30 * 86400}},
+				},
+			},
+			"",
+		},
 		{
 			ZoneConfig{
 				NumReplicas:   proto.Int32(1),
@@ -958,6 +1020,44 @@ func TestConstraintsListYAML(t *testing.T) {
 	}
 }
 
+func TestGCRetentionPolicyYAML(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		input     string
+		expected  string
+		expectErr bool
+	}{
+		{input: "''", expected: ""},
+		{input: "1h:30d", expected: "1h:30d"},
+		{input: "'1h:30d, 1d:365d'", expected: "1h:30d,1d:365d"},
+		{input: "90m:1440h", expected: "90m:60d"},
+		{input: "90s:3600s", expected: "90s:1h"},
+		{input: "1h", expectErr: true},
+		{input: "1h:30d:1y", expectErr: true},
+		{input: "1h:30x", expectErr: true},
+		{input: "1.5s:1h", expectErr: true},
+		{input: "0s:1h", expectErr: true},
+		{input: "[1h:30d]", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			var retention GCRetentionPolicy
+			err := yaml.UnmarshalStrict([]byte(tc.input), &retention)
+			if err == nil && tc.expectErr {
+				t.Errorf("expected error, but got retention tiers %s", retention)
+			}
+			if err != nil && !tc.expectErr {
+				t.Errorf("expected success, but got %v", err)
+			}
+			if err == nil && retention.String() != tc.expected {
+				t.Errorf("expected %q, but got %q", tc.expected, retention.String())
+			}
+		})
+	}
+}
+
 func TestMarshalableZoneConfigRoundTrip(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
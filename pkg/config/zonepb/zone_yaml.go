@@ -49,6 +49,23 @@ func (l *LeasePreference) UnmarshalYAML(unmarshal func(interface{}) error) error
 	return nil
 }
 
+var _ yaml.Marshaler = GCRetentionPolicy{}
+var _ yaml.Unmarshaler = &GCRetentionPolicy{}
+
+// MarshalYAML implements yaml.Marshaler.
+func (p GCRetentionPolicy) MarshalYAML() (interface{}, error) {
+	return p.String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *GCRetentionPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var short string
+	if err := unmarshal(&short); err != nil {
+		return err
+	}
+	return p.FromString(short)
+}
+
 var _ yaml.Marshaler = ConstraintsConjunction{}
 var _ yaml.Unmarshaler = &ConstraintsConjunction{}
 
@@ -199,18 +216,20 @@ func (c *ConstraintsList) UnmarshalYAML(unmarshal func(interface{}) error) error
 //
 // TODO(a-robinson,v2.2): Remove the experimental_lease_preferences field.
 type marshalableZoneConfig struct {
-	RangeMinBytes                *int64            `json:"range_min_bytes" yaml:"range_min_bytes"`
-	RangeMaxBytes                *int64            `json:"range_max_bytes" yaml:"range_max_bytes"`
-	GC                           *GCPolicy         `json:"gc"`
-	GlobalReads                  *bool             `json:"global_reads" yaml:"global_reads"`
-	NumReplicas                  *int32            `json:"num_replicas" yaml:"num_replicas"`
-	NumVoters                    *int32            `json:"num_voters" yaml:"num_voters"`
-	Constraints                  ConstraintsList   `json:"constraints" yaml:"constraints,flow"`
-	VoterConstraints             ConstraintsList   `json:"voter_constraints" yaml:"voter_constraints,flow"`
-	LeasePreferences             []LeasePreference `json:"lease_preferences" yaml:"lease_preferences,flow"`
-	ExperimentalLeasePreferences []LeasePreference `json:"experimental_lease_preferences" yaml:"experimental_lease_preferences,flow,omitempty"`
-	Subzones                     []Subzone         `json:"subzones" yaml:"-"`
-	SubzoneSpans                 []SubzoneSpan     `json:"subzone_spans" yaml:"-"`
+	RangeMinBytes                *int64             `json:"range_min_bytes" yaml:"range_min_bytes"`
+	RangeMaxBytes                *int64             `json:"range_max_bytes" yaml:"range_max_bytes"`
+	GC                           *GCPolicy          `json:"gc"`
+	GlobalReads                  *bool              `json:"global_reads" yaml:"global_reads"`
+	NumReplicas                  *int32             `json:"num_replicas" yaml:"num_replicas"`
+	NumVoters                    *int32             `json:"num_voters" yaml:"num_voters"`
+	Constraints                  ConstraintsList    `json:"constraints" yaml:"constraints,flow"`
+	VoterConstraints             ConstraintsList    `json:"voter_constraints" yaml:"voter_constraints,flow"`
+	LeasePreferences             []LeasePreference  `json:"lease_preferences" yaml:"lease_preferences,flow"`
+	StorageQuotaBytes            *int64             `json:"storage_quota_bytes,omitempty" yaml:"storage_quota_bytes,omitempty"`
+	GCRetentionTiers             *GCRetentionPolicy `json:"gc_retention_tiers,omitempty" yaml:"gc_retention_tiers,omitempty"`
+	ExperimentalLeasePreferences []LeasePreference  `json:"experimental_lease_preferences" yaml:"experimental_lease_preferences,flow,omitempty"`
+	Subzones                     []Subzone          `json:"subzones" yaml:"-"`
+	SubzoneSpans                 []SubzoneSpan      `json:"subzone_spans" yaml:"-"`
 }
 
 func zoneConfigToMarshalable(c ZoneConfig) marshalableZoneConfig {
@@ -243,6 +262,13 @@ func zoneConfigToMarshalable(c ZoneConfig) marshalableZoneConfig {
 	if !c.InheritedLeasePreferences {
 		m.LeasePreferences = c.LeasePreferences
 	}
+	if c.StorageQuotaBytes != nil {
+		m.StorageQuotaBytes = proto.Int64(*c.StorageQuotaBytes)
+	}
+	if c.GCRetention != nil {
+		tempGCRetention := *c.GCRetention
+		m.GCRetentionTiers = &tempGCRetention
+	}
 	// We intentionally do not round-trip ExperimentalLeasePreferences. We never
 	// want to return yaml containing it.
 	m.Subzones = c.Subzones
@@ -280,6 +306,13 @@ func zoneConfigFromMarshalable(m marshalableZoneConfig, c ZoneConfig) ZoneConfig
 	if m.LeasePreferences != nil {
 		c.LeasePreferences = m.LeasePreferences
 	}
+	if m.StorageQuotaBytes != nil {
+		c.StorageQuotaBytes = proto.Int64(*m.StorageQuotaBytes)
+	}
+	if m.GCRetentionTiers != nil {
+		tempGCRetention := *m.GCRetentionTiers
+		c.GCRetention = &tempGCRetention
+	}
 
 	// Prefer a provided m.ExperimentalLeasePreferences value over whatever is in
 	// m.LeasePreferences, since we know that m.ExperimentalLeasePreferences can
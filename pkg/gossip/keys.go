@@ -102,6 +102,11 @@ const (
 	// stmtDiagnosticsRequestRegistry listens for notifications and responds by
 	// polling for new requests.
 	KeyGossipStatementDiagnosticsRequestCancellation = "stmt-diag-cancel-req"
+
+	// KeyStorageQuotaUsagePrefix is the key prefix for gossiping the logical
+	// bytes stored by each tenant and table with a storage quota, as seen by
+	// the leaseholders on a store.
+	KeyStorageQuotaUsagePrefix = "storage-quota-usage"
 )
 
 // MakeKey creates a canonical key under which to gossip a piece of
@@ -180,6 +185,12 @@ func StoreIDFromKey(storeKey string) (roachpb.StoreID, error) {
 	return roachpb.StoreID(storeID), nil
 }
 
+// MakeStorageQuotaUsageKey returns the gossip key for the storage quota usage
+// of the given store.
+func MakeStorageQuotaUsageKey(storeID roachpb.StoreID) string {
+	return MakeKey(KeyStorageQuotaUsagePrefix, storeID.String())
+}
+
 // MakeDistSQLNodeVersionKey returns the gossip key for the given store.
 func MakeDistSQLNodeVersionKey(instanceID base.SQLInstanceID) string {
 	return MakeKey(KeyDistSQLNodeVersionKeyPrefix, instanceID.String())
@@ -12,6 +12,8 @@ package jobs
 
 import (
 	"context"
+	"fmt"
+	"runtime/pprof"
 	"strconv"
 	"sync"
 
@@ -405,8 +407,15 @@ func (r *Registry) runJob(
 		}
 	}
 
-	// Run the actual job.
-	err := r.stepThroughStateMachine(ctx, execCtx, resumer, job, status, finalResumeError)
+	// Run the actual job. The job is labeled so that the CPU it uses shows up
+	// in CPU profiles taken with labels. Unlike statements, jobs are long-lived,
+	// so the label is applied unconditionally: a job started before a profile
+	// is taken still needs to be attributed in it.
+	var err error
+	labels := pprof.Labels("job", fmt.Sprintf("%s id=%d", typ, job.ID()))
+	pprof.Do(ctx, labels, func(ctx context.Context) {
+		err = r.stepThroughStateMachine(ctx, execCtx, resumer, job, status, finalResumeError)
+	})
 	// If the context has been canceled, disregard errors for the sake of logging
 	// as presumably they are due to the context cancellation which commonly
 	// happens during shutdown.
@@ -47,6 +47,7 @@ var (
 	_ Details = ImportDetails{}
 	_ Details = StreamReplicationDetails{}
 	_ Details = RowLevelTTLDetails{}
+	_ Details = VerifyBackupDetails{}
 )
 
 // ProgressDetails is a marker interface for job progress details proto structs.
@@ -65,6 +66,7 @@ var (
 	_ ProgressDetails = AutoSpanConfigReconciliationDetails{}
 	_ ProgressDetails = StreamReplicationProgress{}
 	_ ProgressDetails = RowLevelTTLProgress{}
+	_ ProgressDetails = VerifyBackupProgress{}
 )
 
 // Type returns the payload's job type.
@@ -129,6 +131,8 @@ func DetailsType(d isPayload_Details) Type {
 		return TypeStreamReplication
 	case *Payload_RowLevelTTL:
 		return TypeRowLevelTTL
+	case *Payload_VerifyBackup:
+		return TypeVerifyBackup
 	default:
 		panic(errors.AssertionFailedf("Payload.Type called on a payload with an unknown details type: %T", d))
 	}
@@ -173,6 +177,8 @@ func WrapProgressDetails(details ProgressDetails) interface {
 		return &Progress_StreamReplication{StreamReplication: &d}
 	case RowLevelTTLProgress:
 		return &Progress_RowLevelTTL{RowLevelTTL: &d}
+	case VerifyBackupProgress:
+		return &Progress_VerifyBackup{VerifyBackup: &d}
 	default:
 		panic(errors.AssertionFailedf("WrapProgressDetails: unknown details type %T", d))
 	}
@@ -212,6 +218,8 @@ func (p *Payload) UnwrapDetails() Details {
 		return *d.StreamReplication
 	case *Payload_RowLevelTTL:
 		return *d.RowLevelTTL
+	case *Payload_VerifyBackup:
+		return *d.VerifyBackup
 	default:
 		return nil
 	}
@@ -251,6 +259,8 @@ func (p *Progress) UnwrapDetails() ProgressDetails {
 		return *d.StreamReplication
 	case *Progress_RowLevelTTL:
 		return *d.RowLevelTTL
+	case *Progress_VerifyBackup:
+		return *d.VerifyBackup
 	default:
 		return nil
 	}
@@ -303,6 +313,8 @@ func WrapPayloadDetails(details Details) interface {
 		return &Payload_StreamReplication{StreamReplication: &d}
 	case RowLevelTTLDetails:
 		return &Payload_RowLevelTTL{RowLevelTTL: &d}
+	case VerifyBackupDetails:
+		return &Payload_VerifyBackup{VerifyBackup: &d}
 	default:
 		panic(errors.AssertionFailedf("jobs.WrapPayloadDetails: unknown details type %T", d))
 	}
@@ -338,7 +350,7 @@ const (
 func (Type) SafeValue() {}
 
 // NumJobTypes is the number of jobs types.
-const NumJobTypes = 17
+const NumJobTypes = 18
 
 // MarshalJSONPB implements jsonpb.JSONPBMarshaller to  redact sensitive sink URI
 // parameters from ChangefeedDetails.
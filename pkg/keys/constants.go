@@ -84,6 +84,9 @@ var (
 	// LocalRangeGCThresholdSuffix is the suffix for the GC threshold. It keeps
 	// the lgc- ("last GC") representation for backwards compatibility.
 	LocalRangeGCThresholdSuffix = []byte("lgc-")
+	// LocalRangeGCRetentionSuffix is the suffix for the downsampled GC
+	// retention state.
+	LocalRangeGCRetentionSuffix = []byte("rgcr")
 	// LocalRangeAppliedStateSuffix is the suffix for the range applied state
 	// key.
 	LocalRangeAppliedStateSuffix = []byte("rask")
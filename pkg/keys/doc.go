@@ -188,6 +188,7 @@ var _ = [...]interface{}{
 	AbortSpanKey,             // "abc-"
 	RangeGCThresholdKey,      // "lgc-"
 	RangeAppliedStateKey,     // "rask"
+	RangeGCRetentionKey,      // "rgcr"
 	RangeLeaseKey,            // "rll-"
 	RangePriorReadSummaryKey, // "rprs"
 	RangeVersionKey,          // "rver"
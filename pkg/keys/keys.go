@@ -279,6 +279,12 @@ func RangeGCThresholdKey(rangeID roachpb.RangeID) roachpb.Key {
 	return MakeRangeIDPrefixBuf(rangeID).RangeGCThresholdKey()
 }
 
+// RangeGCRetentionKey returns a system-local key for the downsampled GC
+// retention state of the range.
+func RangeGCRetentionKey(rangeID roachpb.RangeID) roachpb.Key {
+	return MakeRangeIDPrefixBuf(rangeID).RangeGCRetentionKey()
+}
+
 // RangeVersionKey returns a system-local for the range version.
 func RangeVersionKey(rangeID roachpb.RangeID) roachpb.Key {
 	return MakeRangeIDPrefixBuf(rangeID).RangeVersionKey()
@@ -1014,6 +1020,12 @@ func (b RangeIDPrefixBuf) RangeGCThresholdKey() roachpb.Key {
 	return append(b.replicatedPrefix(), LocalRangeGCThresholdSuffix...)
 }
 
+// RangeGCRetentionKey returns a system-local key for the downsampled GC
+// retention state.
+func (b RangeIDPrefixBuf) RangeGCRetentionKey() roachpb.Key {
+	return append(b.replicatedPrefix(), LocalRangeGCRetentionSuffix...)
+}
+
 // RangeVersionKey returns a system-local key for the range version.
 func (b RangeIDPrefixBuf) RangeVersionKey() roachpb.Key {
 	return append(b.replicatedPrefix(), LocalRangeVersionSuffix...)
@@ -174,6 +174,7 @@ var (
 		{name: "RangePriorReadSummary", suffix: LocalRangePriorReadSummarySuffix},
 		{name: "RangeStats", suffix: LocalRangeStatsLegacySuffix},
 		{name: "RangeGCThreshold", suffix: LocalRangeGCThresholdSuffix},
+		{name: "RangeGCRetention", suffix: LocalRangeGCRetentionSuffix},
 		{name: "RangeVersion", suffix: LocalRangeVersionSuffix},
 	}
 
@@ -347,6 +347,11 @@ type DistSender struct {
 
 	// Currently executing range feeds.
 	activeRangeFeeds sync.Map // // map[*rangeFeedRegistry]nil
+
+	// historicalReads, if set, holds a historicalReadSourceContainer whose
+	// source serves read-only batches rejected for reading below the GC
+	// threshold. See SetHistoricalReadSource.
+	historicalReads atomic.Value
 }
 
 var _ kv.Sender = &DistSender{}
@@ -822,6 +827,9 @@ func (ds *DistSender) Send(
 		} else {
 			rpl, pErr = ds.divideAndSendBatchToRanges(ctx, ba, rs, isReverse, withCommit, 0 /* batchIdx */)
 		}
+		if pErr != nil {
+			rpl, pErr = ds.maybeSendToHistoricalReadSource(ctx, ba, rpl, pErr)
+		}
 
 		if pErr == errNo1PCTxn {
 			// If we tried to send a single round-trip EndTxn but it looks like
@@ -0,0 +1,93 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvcoord
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// HistoricalReadSource is a source of historical KV data outside of the
+// cluster's ranges, e.g. a chain of backups, that can serve reads at
+// timestamps which the ranges themselves have already garbage collected.
+type HistoricalReadSource interface {
+	// Send evaluates the read-only batch against the source. It returns a nil
+	// response and a nil error if the source does not cover the batch's spans
+	// at the batch's timestamp.
+	Send(ctx context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, error)
+}
+
+type historicalReadUserKey struct{}
+
+// ContextWithHistoricalReadUser returns a context which records that the KV
+// reads sent with it are issued on behalf of the given SQL user. A
+// HistoricalReadSource uses the user to access the data it serves reads from
+// with that user's privileges.
+func ContextWithHistoricalReadUser(ctx context.Context, user username.SQLUsername) context.Context {
+	return context.WithValue(ctx, historicalReadUserKey{}, user)
+}
+
+// HistoricalReadUserFromContext returns the SQL user recorded in the context
+// by ContextWithHistoricalReadUser, if any.
+func HistoricalReadUserFromContext(ctx context.Context) (username.SQLUsername, bool) {
+	user, ok := ctx.Value(historicalReadUserKey{}).(username.SQLUsername)
+	return user, ok
+}
+
+// historicalReadSourceContainer wraps a HistoricalReadSource so that it can be
+// stored in an atomic.Value regardless of the concrete type implementing it.
+type historicalReadSourceContainer struct {
+	src HistoricalReadSource
+}
+
+// SetHistoricalReadSource configures the DistSender to fall back to the
+// provided source for read-only batches which fail because their timestamp is
+// below the GC threshold of the ranges they target.
+func (ds *DistSender) SetHistoricalReadSource(src HistoricalReadSource) {
+	ds.historicalReads.Store(historicalReadSourceContainer{src: src})
+}
+
+func (ds *DistSender) getHistoricalReadSource() HistoricalReadSource {
+	c, _ := ds.historicalReads.Load().(historicalReadSourceContainer)
+	return c.src
+}
+
+// maybeSendToHistoricalReadSource retries a batch against the configured
+// HistoricalReadSource if the batch is read-only and failed with a
+// BatchTimestampBeforeGCError. Otherwise, or if the source cannot serve the
+// batch, the original result is returned.
+func (ds *DistSender) maybeSendToHistoricalReadSource(
+	ctx context.Context, ba roachpb.BatchRequest, br *roachpb.BatchResponse, pErr *roachpb.Error,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	src := ds.getHistoricalReadSource()
+	if src == nil || !ba.IsReadOnly() {
+		return br, pErr
+	}
+	if _, ok := pErr.GetDetail().(*roachpb.BatchTimestampBeforeGCError); !ok {
+		return br, pErr
+	}
+	histBr, err := src.Send(ctx, ba)
+	if err != nil {
+		// Keep the original error as the primary cause so that callers
+		// continue to see a BatchTimestampBeforeGCError.
+		log.VEventf(ctx, 2, "historical read source failed: %v", err)
+		return nil, roachpb.NewErrorWithTxn(errors.WithSecondaryError(pErr.GoError(), err), pErr.GetTxn())
+	}
+	if histBr == nil {
+		return br, pErr
+	}
+	log.VEventf(ctx, 2, "served batch below GC threshold from historical read source")
+	return histBr, nil
+}
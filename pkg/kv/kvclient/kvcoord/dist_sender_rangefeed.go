@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/admission/admissionpb"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -69,6 +70,41 @@ func maxConcurrentCatchupScans(sv *settings.Values) int {
 	return int(l)
 }
 
+// RangeFeedOption configures a RangeFeed.
+type RangeFeedOption func(*rangeFeedConfig)
+
+type rangeFeedConfig struct {
+	filter          *roachpb.RangeFeedFilter
+	admissionHeader roachpb.AdmissionHeader
+}
+
+// WithRangeFeedFilter restricts the RangeFeedValue events emitted by the
+// RangeFeed to those matching the filter. The filter is evaluated by the
+// replicas, so that the events which don't match it are not sent over the
+// network.
+func WithRangeFeedFilter(filter *roachpb.RangeFeedFilter) RangeFeedOption {
+	return func(c *rangeFeedConfig) {
+		c.filter = filter
+	}
+}
+
+// WithCatchUpScanAdmissionPriority subjects the catch-up scans of the
+// RangeFeed to admission control at the given priority. Catch-up scans of
+// RangeFeeds which don't specify a priority bypass admission control.
+func WithCatchUpScanAdmissionPriority(pri admissionpb.WorkPriority) RangeFeedOption {
+	return func(c *rangeFeedConfig) {
+		c.admissionHeader = roachpb.AdmissionHeader{
+			Priority: int32(pri),
+			// All the catch-up scans of the RangeFeed share the same create time,
+			// so that admission control orders them before the catch-up scans of
+			// RangeFeeds started later at the same priority.
+			CreateTime:               timeutil.Now().UnixNano(),
+			Source:                   roachpb.AdmissionHeader_FROM_SQL,
+			NoMemoryReservedAtSource: true,
+		}
+	}
+}
+
 // RangeFeed divides a RangeFeed request on range boundaries and establishes a
 // RangeFeed to each of the individual ranges. It streams back results on the
 // provided channel.
@@ -88,6 +124,7 @@ func (ds *DistSender) RangeFeed(
 	startAfter hlc.Timestamp, // exclusive
 	withDiff bool,
 	eventCh chan<- *roachpb.RangeFeedEvent,
+	opts ...RangeFeedOption,
 ) error {
 	timedSpans := make([]SpanTimePair, 0, len(spans))
 	for _, sp := range spans {
@@ -96,7 +133,7 @@ func (ds *DistSender) RangeFeed(
 			StartAfter: startAfter,
 		})
 	}
-	return ds.RangeFeedSpans(ctx, timedSpans, withDiff, eventCh)
+	return ds.RangeFeedSpans(ctx, timedSpans, withDiff, eventCh, opts...)
 }
 
 // SpanTimePair is a pair of span along with its starting time. The starting
@@ -110,17 +147,25 @@ type SpanTimePair struct {
 // RangeFeedSpans is similar to RangeFeed but allows specification of different
 // starting time for each span.
 func (ds *DistSender) RangeFeedSpans(
-	ctx context.Context, spans []SpanTimePair, withDiff bool, eventCh chan<- *roachpb.RangeFeedEvent,
+	ctx context.Context,
+	spans []SpanTimePair,
+	withDiff bool,
+	eventCh chan<- *roachpb.RangeFeedEvent,
+	opts ...RangeFeedOption,
 ) error {
 	if len(spans) == 0 {
 		return errors.AssertionFailedf("expected at least 1 span, got none")
 	}
+	var cfg rangeFeedConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
 	ctx = ds.AnnotateCtx(ctx)
 	ctx, sp := tracing.EnsureChildSpan(ctx, ds.AmbientContext.Tracer, "dist sender")
 	defer sp.Finish()
 
-	rr := newRangeFeedRegistry(ctx, withDiff)
+	rr := newRangeFeedRegistry(ctx, withDiff, cfg)
 	ds.activeRangeFeeds.Store(rr, nil)
 	defer ds.activeRangeFeeds.Delete(rr)
 
@@ -243,11 +288,15 @@ func (a *activeRangeFeed) onRangeEvent(
 type rangeFeedRegistry struct {
 	RangeFeedContext
 	ranges sync.Map // map[*activeRangeFeed]nil
+	cfg    rangeFeedConfig
 }
 
-func newRangeFeedRegistry(ctx context.Context, withDiff bool) *rangeFeedRegistry {
+func newRangeFeedRegistry(
+	ctx context.Context, withDiff bool, cfg rangeFeedConfig,
+) *rangeFeedRegistry {
 	rr := &rangeFeedRegistry{
 		RangeFeedContext: RangeFeedContext{WithDiff: withDiff},
+		cfg:              cfg,
 	}
 	rr.ID = *(*int64)(unsafe.Pointer(&rr))
 
@@ -338,7 +387,7 @@ func (ds *DistSender) partialRangeFeed(
 		}
 
 		// Establish a RangeFeed for a single Range.
-		maxTS, err := ds.singleRangeFeed(ctx, span, startAfter, withDiff, token.Desc(),
+		maxTS, err := ds.singleRangeFeed(ctx, span, startAfter, withDiff, &rr.cfg, token.Desc(),
 			catchupSem, eventCh, active.onRangeEvent)
 
 		// Forward the timestamp in case we end up sending it again.
@@ -409,6 +458,7 @@ func (ds *DistSender) singleRangeFeed(
 	span roachpb.Span,
 	startAfter hlc.Timestamp,
 	withDiff bool,
+	cfg *rangeFeedConfig,
 	desc *roachpb.RangeDescriptor,
 	catchupSem *limit.ConcurrentRequestLimiter,
 	eventCh chan<- *roachpb.RangeFeedEvent,
@@ -424,7 +474,9 @@ func (ds *DistSender) singleRangeFeed(
 			Timestamp: startAfter,
 			RangeID:   desc.RangeID,
 		},
-		WithDiff: withDiff,
+		WithDiff:        withDiff,
+		Filter:          cfg.filter,
+		AdmissionHeader: cfg.admissionHeader,
 	}
 
 	var latencyFn LatencyFunc
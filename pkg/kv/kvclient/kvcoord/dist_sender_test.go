@@ -5067,3 +5067,108 @@ func TestDistSenderRPCMetrics(t *testing.T) {
 	require.Equal(t, ds.metrics.ErrCounts[roachpb.NotLeaseHolderErrType].Count(), int64(1))
 	require.Equal(t, ds.metrics.ErrCounts[roachpb.ConditionFailedErrType].Count(), int64(1))
 }
+
+type testHistoricalReadSource struct {
+	calls int
+	br    *roachpb.BatchResponse
+	err   error
+}
+
+func (s *testHistoricalReadSource) Send(
+	_ context.Context, ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, error) {
+	s.calls++
+	return s.br, s.err
+}
+
+// TestDistSenderHistoricalReadSource verifies that read-only batches rejected
+// with a BatchTimestampBeforeGCError are retried against the configured
+// HistoricalReadSource, and that other batches and errors are not.
+func TestDistSenderHistoricalReadSource(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	g := makeGossip(t, stopper, rpcContext)
+
+	var replicaErr error
+	var testFn simpleSendFn = func(
+		_ context.Context, ba roachpb.BatchRequest,
+	) (*roachpb.BatchResponse, error) {
+		br := ba.CreateReply()
+		if replicaErr != nil {
+			br.Error = roachpb.NewError(replicaErr)
+		}
+		return br, nil
+	}
+	cfg := DistSenderConfig{
+		AmbientCtx: log.MakeTestingAmbientCtxWithNewTracer(),
+		Clock:      clock,
+		NodeDescs:  g,
+		RPCContext: rpcContext,
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory: adaptSimpleTransport(testFn),
+		},
+		RangeDescriptorDB: defaultMockRangeDescriptorDB,
+		NodeDialer:        nodedialer.New(rpcContext, gossip.AddressResolver(g)),
+		Settings:          cluster.MakeTestingClusterSettings(),
+	}
+	ds := NewDistSender(cfg)
+
+	gcErr := &roachpb.BatchTimestampBeforeGCError{
+		Timestamp: hlc.Timestamp{WallTime: 1},
+		Threshold: hlc.Timestamp{WallTime: 2},
+	}
+	histValue := roachpb.MakeValueFromString("historical")
+	histBr := &roachpb.BatchResponse{}
+	histBr.Add(&roachpb.GetResponse{Value: &histValue})
+
+	get := func() (roachpb.Response, *roachpb.Error) {
+		return kv.SendWrappedWith(ctx, ds, roachpb.Header{
+			Timestamp: hlc.Timestamp{WallTime: 1},
+		}, roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */))
+	}
+
+	// Without a source, the error is returned as-is.
+	replicaErr = gcErr
+	_, pErr := get()
+	require.IsType(t, &roachpb.BatchTimestampBeforeGCError{}, pErr.GetDetail())
+
+	src := &testHistoricalReadSource{br: histBr}
+	ds.SetHistoricalReadSource(src)
+
+	// A read below the GC threshold is served by the source.
+	resp, pErr := get()
+	require.Nil(t, pErr)
+	require.Equal(t, 1, src.calls)
+	require.Equal(t, &histValue, resp.(*roachpb.GetResponse).Value)
+
+	// Writes are never sent to the source.
+	_, pErr = kv.SendWrapped(ctx, ds, roachpb.NewPut(roachpb.Key("a"), roachpb.Value{}))
+	require.IsType(t, &roachpb.BatchTimestampBeforeGCError{}, pErr.GetDetail())
+	require.Equal(t, 1, src.calls)
+
+	// Other errors are not sent to the source either.
+	replicaErr = &roachpb.RangeFeedRetryError{}
+	_, pErr = get()
+	require.IsType(t, &roachpb.RangeFeedRetryError{}, pErr.GetDetail())
+	require.Equal(t, 1, src.calls)
+
+	// If the source does not cover the batch, the original error is returned.
+	replicaErr = gcErr
+	src.br = nil
+	_, pErr = get()
+	require.IsType(t, &roachpb.BatchTimestampBeforeGCError{}, pErr.GetDetail())
+	require.Equal(t, 2, src.calls)
+
+	// If the source fails, the original error is retained as the cause.
+	src.err = errors.New("boom")
+	_, pErr = get()
+	require.IsType(t, &roachpb.BatchTimestampBeforeGCError{}, pErr.GetDetail())
+	require.Contains(t, fmt.Sprintf("%+v", pErr.GoError()), "boom")
+	require.Equal(t, 3, src.calls)
+}
@@ -15,7 +15,9 @@ import (
 	"fmt"
 	"runtime/debug"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -526,6 +528,10 @@ func (tc *TxnCoordSender) Send(
 	// worrying about synchronization.
 	ba.Txn = tc.mu.txn.Clone()
 
+	if ba.IsLocking() && !tc.st.Version.IsActive(ctx, clusterversion.ReplicatedSharedLocks) {
+		downgradeLocking(&ba, tc.st.Version.IsActive(ctx, clusterversion.SharedLocks))
+	}
+
 	// Send the command through the txnInterceptor stack.
 	br, pErr := tc.interceptorStack[0].SendLocked(ctx, ba)
 
@@ -672,6 +678,52 @@ func (tc *TxnCoordSender) maybeCommitWait(ctx context.Context, deferred bool) er
 	return nil
 }
 
+// downgradeLocking makes the locking reads of the batch acquire the locks that
+// nodes could handle before replicated Shared locks were introduced. The reads
+// that acquire replicated locks acquire unreplicated ones instead and, unless
+// sharedLocks is set, the reads that acquire Shared locks acquire no locks at
+// all. It is used until the cluster versions that let nodes handle these locks
+// are active. The requests are copied before they are modified.
+func downgradeLocking(ba *roachpb.BatchRequest, sharedLocks bool) {
+	copied := false
+	for i := range ba.Requests {
+		var str lock.Strength
+		var dur lock.Durability
+		switch t := ba.Requests[i].GetInner().(type) {
+		case *roachpb.GetRequest:
+			str, dur = t.KeyLocking, t.KeyLockingDurability
+		case *roachpb.ScanRequest:
+			str, dur = t.KeyLocking, t.KeyLockingDurability
+		case *roachpb.ReverseScanRequest:
+			str, dur = t.KeyLocking, t.KeyLockingDurability
+		default:
+			continue
+		}
+		newStr, newDur := str, lock.Unreplicated
+		if str == lock.Shared && !sharedLocks {
+			newStr = lock.None
+		}
+		if newStr == str && newDur == dur {
+			continue
+		}
+		if !copied {
+			ba.Requests = append([]roachpb.RequestUnion(nil), ba.Requests...)
+			copied = true
+		}
+		switch t := ba.Requests[i].GetInner().ShallowCopy().(type) {
+		case *roachpb.GetRequest:
+			t.KeyLocking, t.KeyLockingDurability = newStr, newDur
+			ba.Requests[i].MustSetInner(t)
+		case *roachpb.ScanRequest:
+			t.KeyLocking, t.KeyLockingDurability = newStr, newDur
+			ba.Requests[i].MustSetInner(t)
+		case *roachpb.ReverseScanRequest:
+			t.KeyLocking, t.KeyLockingDurability = newStr, newDur
+			ba.Requests[i].MustSetInner(t)
+		}
+	}
+}
+
 // maybeRejectClientLocked checks whether the transaction is in a state that
 // prevents it from continuing, such as the heartbeat having detected the
 // transaction to have been aborted.
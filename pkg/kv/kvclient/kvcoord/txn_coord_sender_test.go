@@ -22,9 +22,11 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
@@ -980,6 +982,90 @@ func TestTxnMultipleCoord(t *testing.T) {
 	}
 }
 
+// TestTxnCoordSenderDowngradesSharedLocking verifies that locking reads which
+// request Shared locks acquire no locks until the SharedLocks cluster version
+// is active, and that locking reads which request replicated locks acquire
+// unreplicated locks until the ReplicatedSharedLocks cluster version is active,
+// without modifying the requests of the caller.
+func TestTxnCoordSenderDowngradesSharedLocking(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	type locking struct {
+		str lock.Strength
+		dur lock.Durability
+	}
+	for _, tc := range []struct {
+		version clusterversion.Key
+		exp     []locking
+	}{
+		{
+			version: clusterversion.SharedLocks - 1,
+			exp:     []locking{{lock.None, lock.Unreplicated}, {lock.Exclusive, lock.Unreplicated}},
+		},
+		{
+			version: clusterversion.SharedLocks,
+			exp:     []locking{{lock.Shared, lock.Unreplicated}, {lock.Exclusive, lock.Unreplicated}},
+		},
+		{
+			version: clusterversion.ReplicatedSharedLocks,
+			exp:     []locking{{lock.Shared, lock.Replicated}, {lock.Exclusive, lock.Unreplicated}},
+		},
+	} {
+		t.Run(tc.version.String(), func(t *testing.T) {
+			stopper := stop.NewStopper()
+			defer stopper.Stop(ctx)
+			clock := hlc.NewClock(timeutil.NewManualTime(timeutil.Unix(0, 123)), time.Nanosecond /* maxOffset */)
+			st := cluster.MakeTestingClusterSettingsWithVersions(
+				clusterversion.TestingBinaryVersion, clusterversion.ByKey(tc.version), true /* initializeVersion */)
+
+			var sent []locking
+			var senderFn kv.SenderFunc = func(_ context.Context, ba roachpb.BatchRequest) (
+				*roachpb.BatchResponse, *roachpb.Error) {
+				for _, ru := range ba.Requests {
+					if scan, ok := ru.GetInner().(*roachpb.ScanRequest); ok {
+						sent = append(sent, locking{scan.KeyLocking, scan.KeyLockingDurability})
+					}
+				}
+				br := ba.CreateReply()
+				br.Txn = ba.Txn.Clone()
+				return br, nil
+			}
+			ambient := log.MakeTestingAmbientCtxWithNewTracer()
+			factory := kvcoord.NewTxnCoordSenderFactory(
+				kvcoord.TxnCoordSenderFactoryConfig{
+					AmbientCtx: ambient,
+					Clock:      clock,
+					Stopper:    stopper,
+					Settings:   st,
+				},
+				senderFn,
+			)
+			db := kv.NewDB(ambient, factory, clock, stopper)
+			txn := kv.NewTxn(ctx, db, 0 /* gatewayNodeID */)
+
+			shared := &roachpb.ScanRequest{
+				RequestHeader:        roachpb.RequestHeader{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")},
+				KeyLocking:           lock.Shared,
+				KeyLockingDurability: lock.Replicated,
+			}
+			exclusive := &roachpb.ScanRequest{
+				RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("c"), EndKey: roachpb.Key("d")},
+				KeyLocking:    lock.Exclusive,
+			}
+			b := txn.NewBatch()
+			b.AddRawRequest(shared, exclusive)
+			require.NoError(t, txn.Run(ctx, b))
+			require.Equal(t, tc.exp, sent)
+
+			// The request of the caller is left untouched.
+			require.Equal(t, lock.Shared, shared.KeyLocking)
+			require.Equal(t, lock.Replicated, shared.KeyLockingDurability)
+		})
+	}
+}
+
 // TestTxnCoordSenderNoDuplicateLockSpans verifies that TxnCoordSender does not
 // generate duplicate lock spans and that it merges lock spans that have
 // overlapping ranges.
@@ -1278,6 +1278,7 @@ func (w *workerCoordinator) processSingleRangeResults(
 				gets = gets[1:]
 				newGet.req.SetSpan(*get.ResumeSpan)
 				newGet.req.KeyLocking = origRequest.KeyLocking
+				newGet.req.KeyLockingDurability = origRequest.KeyLockingDurability
 				newGet.union.Get = &newGet.req
 				resumeReq.reqs[resumeReqIdx].Value = &newGet.union
 				resumeReq.positions = append(resumeReq.positions, position)
@@ -1354,6 +1355,7 @@ func (w *workerCoordinator) processSingleRangeResults(
 				newScan.req.SetSpan(*scan.ResumeSpan)
 				newScan.req.ScanFormat = roachpb.BATCH_RESPONSE
 				newScan.req.KeyLocking = origRequest.KeyLocking
+				newScan.req.KeyLockingDurability = origRequest.KeyLockingDurability
 				newScan.union.Scan = &newScan.req
 				resumeReq.reqs[resumeReqIdx].Value = &newScan.union
 				resumeReq.positions = append(resumeReq.positions, position)
@@ -1751,8 +1751,10 @@ func (a *Allocator) TransferLeaseTarget(
 			&QPSScorerOptions{
 				StoreHealthOptions:                a.StoreHealthOptions(ctx),
 				DeprecatedRangeRebalanceThreshold: RangeRebalanceThreshold.Get(&a.StorePool.St.SV),
+				LoadDimension:                     opts.LoadDimension,
 				QPSRebalanceThreshold:             allocator.QPSRebalanceThreshold.Get(&a.StorePool.St.SV),
-				MinRequiredQPSDiff:                allocator.MinQPSDifferenceForTransfers.Get(&a.StorePool.St.SV),
+				MinRequiredQPSDiff:                opts.LoadDimension.MinDifferenceForTransfers(&a.StorePool.St.SV),
+				MinCPUThresholdDifference:         float64(allocator.MinCPUThresholdDifference.Get(&a.StorePool.St.SV)),
 			},
 		)
 
@@ -1788,13 +1790,16 @@ func (a *Allocator) TransferLeaseTarget(
 			log.VEventf(
 				ctx,
 				5,
-				"r%d: should transfer lease (qps=%0.2f) from s%d (qps=%0.2f) to s%d (qps=%0.2f)",
+				"r%d: should transfer lease (%s=%0.2f) from s%d (%s=%0.2f) to s%d (%s=%0.2f)",
 				leaseRepl.GetRangeID(),
+				opts.LoadDimension,
 				leaseReplQPS,
 				leaseRepl.StoreID(),
-				storeDescMap[leaseRepl.StoreID()].Capacity.QueriesPerSecond,
+				opts.LoadDimension,
+				opts.LoadDimension.StoreLoad(storeDescMap[leaseRepl.StoreID()].Capacity),
 				bestStore,
-				storeDescMap[bestStore].Capacity.QueriesPerSecond,
+				opts.LoadDimension,
+				opts.LoadDimension.StoreLoad(storeDescMap[bestStore].Capacity),
 			)
 		default:
 			log.Fatalf(ctx, "unknown declineReason: %v", noRebalanceReason)
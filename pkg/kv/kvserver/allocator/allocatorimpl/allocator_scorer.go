@@ -411,10 +411,18 @@ func (o *RangeCountScorerOptions) removalMaximallyConvergesScore(
 // rebalancing machinery to base its balance/convergence scores on
 // queries-per-second. This means that the resulting rebalancing decisions will
 // further the goal of converging QPS across stores in the cluster.
+//
+// Despite the name, the load dimension being converged is given by
+// LoadDimension, which may be CPU rather than QPS. QPSPerReplica,
+// MinRequiredQPSDiff and MinCPUThresholdDifference are expressed in units of
+// that dimension.
 type QPSScorerOptions struct {
 	StoreHealthOptions StoreHealthOptions
 	Deterministic      bool
 
+	// LoadDimension is the dimension of store load that is being converged.
+	LoadDimension allocator.LoadDimension
+
 	// NB: For mixed version compatibility with 21.2, we need to include the range
 	// count based rebalance threshold here. This is because in 21.2, the store
 	// rebalancer took range count into account when trying to rank candidate
@@ -423,6 +431,10 @@ type QPSScorerOptions struct {
 
 	QPSRebalanceThreshold, MinRequiredQPSDiff float64
 
+	// MinCPUThresholdDifference replaces MinQPSThresholdDifference when the
+	// LoadDimension is CPU. See allocator.MinCPUThresholdDifference.
+	MinCPUThresholdDifference float64
+
 	// QPS-based rebalancing assumes that:
 	// 1. Every replica of a range currently receives the same level of traffic.
 	// 2. Transferring this replica to another store would also transfer all of
@@ -488,21 +500,21 @@ func (o QPSScorerOptions) shouldRebalanceBasedOnThresholds(
 		)
 	case missingStatsForExistingStore:
 		metrics.LoadBasedReplicaRebalanceMetrics.MissingStatsForExistingStore.Inc(1)
-		log.VEventf(ctx, 4, "missing QPS stats for s%d", eqClass.existing.StoreID)
+		log.VEventf(ctx, 4, "missing %s stats for s%d", o.LoadDimension, eqClass.existing.StoreID)
 	case shouldRebalance:
 		metrics.LoadBasedReplicaRebalanceMetrics.ShouldRebalance.Inc(1)
 		var bestStoreQPS float64
 		for _, store := range eqClass.candidateSL.Stores {
 			if bestStore == store.StoreID {
-				bestStoreQPS = store.Capacity.QueriesPerSecond
+				bestStoreQPS = o.LoadDimension.StoreLoad(store.Capacity)
 			}
 		}
 		log.VEventf(
 			ctx, 4,
-			"should rebalance replica with %0.2f qps from s%d (qps=%0.2f) to s%d (qps=%0.2f)",
-			o.QPSPerReplica, eqClass.existing.StoreID,
-			eqClass.existing.Capacity.QueriesPerSecond,
-			bestStore, bestStoreQPS,
+			"should rebalance replica with %0.2f %s from s%d (%s=%0.2f) to s%d (%s=%0.2f)",
+			o.QPSPerReplica, o.LoadDimension, eqClass.existing.StoreID,
+			o.LoadDimension, o.LoadDimension.StoreLoad(eqClass.existing.Capacity),
+			bestStore, o.LoadDimension, bestStoreQPS,
 		)
 	default:
 		log.Fatalf(ctx, "unknown reason to decline rebalance: %v", declineReason)
@@ -514,9 +526,10 @@ func (o QPSScorerOptions) shouldRebalanceBasedOnThresholds(
 func (o *QPSScorerOptions) balanceScore(
 	sl storepool.StoreList, sc roachpb.StoreCapacity,
 ) balanceStatus {
-	maxQPS := OverfullQPSThreshold(o, sl.CandidateQueriesPerSecond.Mean)
-	minQPS := UnderfullQPSThreshold(o, sl.CandidateQueriesPerSecond.Mean)
-	curQPS := sc.QueriesPerSecond
+	mean := sl.CandidateLoad(o.LoadDimension).Mean
+	maxQPS := OverfullQPSThreshold(o, mean)
+	minQPS := UnderfullQPSThreshold(o, mean)
+	curQPS := o.LoadDimension.StoreLoad(sc)
 	if curQPS < minQPS {
 		return underfull
 	} else if curQPS >= maxQPS {
@@ -560,14 +573,14 @@ func (o *QPSScorerOptions) removalMaximallyConvergesScore(
 ) int {
 	maxQPS := float64(-1)
 	for _, store := range removalCandStoreList.Stores {
-		if store.Capacity.QueriesPerSecond > maxQPS {
-			maxQPS = store.Capacity.QueriesPerSecond
+		if load := o.LoadDimension.StoreLoad(store.Capacity); load > maxQPS {
+			maxQPS = load
 		}
 	}
 	// NB: Note that if there are multiple stores inside `removalCandStoreList`
 	// with the same (or similar) maxQPS, we will return a
 	// removalMaximallyConvergesScore of -1 for all of them.
-	if scoresAlmostEqual(maxQPS, existing.Capacity.QueriesPerSecond) {
+	if scoresAlmostEqual(maxQPS, o.LoadDimension.StoreLoad(existing.Capacity)) {
 		return -1
 	}
 	return 0
@@ -1178,14 +1191,14 @@ func bestStoreToMinimizeQPSDelta(
 	storeQPSMap := make(map[roachpb.StoreID]float64, len(candidates)+1)
 	for _, store := range candidates {
 		if desc, ok := storeDescMap[store]; ok {
-			storeQPSMap[store] = desc.Capacity.QueriesPerSecond
+			storeQPSMap[store] = options.LoadDimension.StoreLoad(desc.Capacity)
 		}
 	}
 	desc, ok := storeDescMap[existing]
 	if !ok {
 		return 0, missingStatsForExistingStore
 	}
-	storeQPSMap[existing] = desc.Capacity.QueriesPerSecond
+	storeQPSMap[existing] = options.LoadDimension.StoreLoad(desc.Capacity)
 
 	// domain defines the domain over which this function tries to minimize the
 	// QPS delta.
@@ -1223,7 +1236,7 @@ func bestStoreToMinimizeQPSDelta(
 
 	// Only proceed with rebalancing iff `existingStore` is overfull relative to
 	// the equivalence class.
-	mean := domainStoreList.CandidateQueriesPerSecond.Mean
+	mean := domainStoreList.CandidateLoad(options.LoadDimension).Mean
 	overfullThreshold := OverfullQPSThreshold(
 		options,
 		mean,
@@ -2023,12 +2036,21 @@ func underfullRangeThreshold(options *RangeCountScorerOptions, mean float64) flo
 
 // OverfullQPSThreshold computes the overfull QPS threshold.
 func OverfullQPSThreshold(options *QPSScorerOptions, mean float64) float64 {
-	return mean + math.Max(mean*options.QPSRebalanceThreshold, allocator.MinQPSThresholdDifference)
+	return mean + math.Max(mean*options.QPSRebalanceThreshold, options.minThresholdDifference())
 }
 
 // UnderfullQPSThreshold computes the underfull QPS threshold.
 func UnderfullQPSThreshold(options *QPSScorerOptions, mean float64) float64 {
-	return mean - math.Max(mean*options.QPSRebalanceThreshold, allocator.MinQPSThresholdDifference)
+	return mean - math.Max(mean*options.QPSRebalanceThreshold, options.minThresholdDifference())
+}
+
+// minThresholdDifference returns the minimum difference from the mean that a
+// store's load needs to have for it to be considered overfull or underfull.
+func (o *QPSScorerOptions) minThresholdDifference() float64 {
+	if o.LoadDimension == allocator.CPU {
+		return o.MinCPUThresholdDifference
+	}
+	return allocator.MinQPSThresholdDifference
 }
 
 func rebalanceConvergesRangeCountOnMean(
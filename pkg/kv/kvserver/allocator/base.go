@@ -136,6 +136,76 @@ var MinQPSDifferenceForTransfers = func() *settings.FloatSetting {
 	return s
 }()
 
+// MinCPUThresholdDifference is the analog of MinQPSThresholdDifference for the
+// CPU load dimension, expressed as request CPU time per second.
+var MinCPUThresholdDifference = settings.RegisterDurationSetting(
+	settings.SystemOnly,
+	"kv.allocator.min_cpu_threshold_difference",
+	"the minimum difference from the mean in request CPU time per second that a"+
+		" store needs to have for it to be considered overfull or underfull",
+	100*time.Millisecond,
+	settings.NonNegativeDuration,
+)
+
+// LoadDimension is a dimension of load that load-based rebalancing can
+// attempt to converge across stores.
+type LoadDimension int
+
+const (
+	// Queries is the number of batch requests served per second.
+	Queries LoadDimension = iota
+	// CPU is the CPU time, in nanoseconds, spent per second evaluating
+	// requests.
+	CPU
+)
+
+func (d LoadDimension) String() string {
+	switch d {
+	case Queries:
+		return "qps"
+	case CPU:
+		return "cpu"
+	default:
+		return fmt.Sprintf("unknown load dimension: %d", int(d))
+	}
+}
+
+// StoreLoad returns the load of the given store capacity in this dimension.
+func (d LoadDimension) StoreLoad(capacity roachpb.StoreCapacity) float64 {
+	if d == CPU {
+		return capacity.CPUPerSecond
+	}
+	return capacity.QueriesPerSecond
+}
+
+// RangeLoad returns the load of the given range usage in this dimension.
+func (d LoadDimension) RangeLoad(usage RangeUsageInfo) float64 {
+	if d == CPU {
+		return usage.RequestCPUNanosPerSecond
+	}
+	return usage.QueriesPerSecond
+}
+
+// MinThresholdDifference returns the minimum difference from the cluster mean
+// that a store's load needs to have in this dimension for it to be considered
+// overfull or underfull.
+func (d LoadDimension) MinThresholdDifference(sv *settings.Values) float64 {
+	if d == CPU {
+		return float64(MinCPUThresholdDifference.Get(sv))
+	}
+	return MinQPSThresholdDifference
+}
+
+// MinDifferenceForTransfers returns the minimum load difference in this
+// dimension that must exist between two stores for a lease or replica transfer
+// between them to be considered. See MinQPSDifferenceForTransfers.
+func (d LoadDimension) MinDifferenceForTransfers(sv *settings.Values) float64 {
+	if d == CPU {
+		return 2 * d.MinThresholdDifference(sv)
+	}
+	return MinQPSDifferenceForTransfers.Get(sv)
+}
+
 // transferLeaseGoal dictates whether a call to TransferLeaseTarget should
 // improve locality of access, convergence of lease counts or convergence of
 // QPS.
@@ -148,7 +218,9 @@ const (
 	// LeaseCountConvergence transfers leases such that lease counts converge
 	// across stores.
 	LeaseCountConvergence
-	// QPSConvergence transfers leases such that QPS converges across stores.
+	// QPSConvergence transfers leases such that load converges across stores.
+	// The load dimension used is given by TransferLeaseOptions.LoadDimension;
+	// despite the name, it is not necessarily QPS.
 	QPSConvergence
 )
 
@@ -164,6 +236,10 @@ type TransferLeaseOptions struct {
 	// to disregard the existing lease counts on candidates.
 	CheckCandidateFullness bool
 	DryRun                 bool
+	// LoadDimension is the dimension of load that QPSConvergence converges. The
+	// replica stats passed to TransferLeaseTarget must be in the same
+	// dimension.
+	LoadDimension LoadDimension
 }
 
 // LeaseTransferOutcome represents the result of shedLease().
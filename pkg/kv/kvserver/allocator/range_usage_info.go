@@ -13,7 +13,8 @@ package allocator
 // RangeUsageInfo contains usage information (sizes and traffic) needed by the
 // allocator to make rebalancing decisions for a given range.
 type RangeUsageInfo struct {
-	LogicalBytes     int64
-	QueriesPerSecond float64
-	WritesPerSecond  float64
+	LogicalBytes             int64
+	QueriesPerSecond         float64
+	WritesPerSecond          float64
+	RequestCPUNanosPerSecond float64
 }
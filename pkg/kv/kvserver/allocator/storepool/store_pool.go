@@ -505,11 +505,13 @@ func (sp *StorePool) UpdateLocalStoreAfterRebalance(
 // UpdateLocalStoresAfterLeaseTransfer is used to update the local copies of the
 // involved store descriptors immediately after a lease transfer.
 func (sp *StorePool) UpdateLocalStoresAfterLeaseTransfer(
-	from roachpb.StoreID, to roachpb.StoreID, rangeQPS float64,
+	from roachpb.StoreID, to roachpb.StoreID, rangeUsageInfo allocator.RangeUsageInfo,
 ) {
 	sp.DetailsMu.Lock()
 	defer sp.DetailsMu.Unlock()
 
+	rangeQPS := rangeUsageInfo.QueriesPerSecond
+	rangeCPU := rangeUsageInfo.RequestCPUNanosPerSecond
 	fromDetail := *sp.GetStoreDetailLocked(from)
 	if fromDetail.Desc != nil {
 		fromDetail.Desc.Capacity.LeaseCount--
@@ -518,6 +520,11 @@ func (sp *StorePool) UpdateLocalStoresAfterLeaseTransfer(
 		} else {
 			fromDetail.Desc.Capacity.QueriesPerSecond -= rangeQPS
 		}
+		if fromDetail.Desc.Capacity.CPUPerSecond < rangeCPU {
+			fromDetail.Desc.Capacity.CPUPerSecond = 0
+		} else {
+			fromDetail.Desc.Capacity.CPUPerSecond -= rangeCPU
+		}
 		sp.DetailsMu.StoreDetails[from] = &fromDetail
 	}
 
@@ -525,6 +532,7 @@ func (sp *StorePool) UpdateLocalStoresAfterLeaseTransfer(
 	if toDetail.Desc != nil {
 		toDetail.Desc.Capacity.LeaseCount++
 		toDetail.Desc.Capacity.QueriesPerSecond += rangeQPS
+		toDetail.Desc.Capacity.CPUPerSecond += rangeCPU
 		sp.DetailsMu.StoreDetails[to] = &toDetail
 	}
 }
@@ -768,6 +776,10 @@ type StoreList struct {
 	// are eligible to be rebalance targets.
 	CandidateQueriesPerSecond Stat
 
+	// CandidateCPU tracks request CPU time per second stats for Stores that
+	// are eligible to be rebalance targets.
+	CandidateCPU Stat
+
 	// candidateWritesPerSecond tracks writes-per-second stats for Stores that are
 	// eligible to be rebalance targets.
 	candidateWritesPerSecond Stat
@@ -788,12 +800,22 @@ func MakeStoreList(descriptors []roachpb.StoreDescriptor) StoreList {
 		sl.CandidateLeases.update(float64(desc.Capacity.LeaseCount))
 		sl.candidateLogicalBytes.update(float64(desc.Capacity.LogicalBytes))
 		sl.CandidateQueriesPerSecond.update(desc.Capacity.QueriesPerSecond)
+		sl.CandidateCPU.update(desc.Capacity.CPUPerSecond)
 		sl.candidateWritesPerSecond.update(desc.Capacity.WritesPerSecond)
 		sl.CandidateL0Sublevels.update(float64(desc.Capacity.L0Sublevels))
 	}
 	return sl
 }
 
+// CandidateLoad returns the load stats in the given dimension for Stores that
+// are eligible to be rebalance targets.
+func (sl StoreList) CandidateLoad(dim allocator.LoadDimension) Stat {
+	if dim == allocator.CPU {
+		return sl.CandidateCPU
+	}
+	return sl.CandidateQueriesPerSecond
+}
+
 func (sl StoreList) String() string {
 	var buf bytes.Buffer
 	fmt.Fprintf(&buf,
@@ -14,6 +14,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -32,6 +33,7 @@ type Simulator struct {
 
 	pacers map[state.StoreID]ReplicaPacer
 	rqs    map[state.StoreID]ReplicateQueue
+	srs    map[state.StoreID]StoreRebalancer
 
 	state    state.State
 	changer  state.Changer
@@ -40,7 +42,8 @@ type Simulator struct {
 	metrics *MetricsTracker
 }
 
-// NewSimulator constructs a valid Simulator.
+// NewSimulator constructs a valid Simulator. The store rebalancers balance
+// load in the given dimension.
 func NewSimulator(
 	start, end time.Time,
 	interval time.Duration,
@@ -49,10 +52,12 @@ func NewSimulator(
 	exchange state.Exchange,
 	changer state.Changer,
 	changeDelay time.Duration,
+	loadDimension allocator.LoadDimension,
 	metrics *MetricsTracker,
 ) *Simulator {
 	pacers := make(map[state.StoreID]ReplicaPacer)
 	rqs := make(map[state.StoreID]ReplicateQueue)
+	srs := make(map[state.StoreID]StoreRebalancer)
 	for storeID := range initialState.Stores() {
 		rqs[storeID] = NewReplicateQueue(
 			storeID,
@@ -60,6 +65,13 @@ func NewSimulator(
 			changeDelay,
 			initialState.MakeAllocator(storeID),
 		)
+		srs[storeID] = NewStoreRebalancer(
+			storeID,
+			changer,
+			changeDelay,
+			initialState.MakeAllocator(storeID),
+			loadDimension,
+		)
 		pacers[storeID] = NewScannerReplicaPacer(
 			initialState.NextReplicasFn(storeID),
 			defaultLoopInterval,
@@ -76,6 +88,7 @@ func NewSimulator(
 		state:      initialState,
 		changer:    changer,
 		rqs:        rqs,
+		srs:        srs,
 		pacers:     pacers,
 		exchange:   exchange,
 		metrics:    metrics,
@@ -130,6 +143,9 @@ func (s *Simulator) RunSim(ctx context.Context) {
 		// Simulate the replicate queue logic.
 		s.tickReplicateQueue(ctx, tick, stateForAlloc)
 
+		// Simulate the store rebalancer logic.
+		s.tickStoreRebalancers(ctx, tick, stateForAlloc)
+
 		// Print tick metrics.
 		s.tickMetrics(ctx, tick)
 	}
@@ -194,6 +210,14 @@ func (s *Simulator) tickReplicateQueue(ctx context.Context, tick time.Time, stat
 	}
 }
 
+// tickStoreRebalancers ticks the store rebalancer of each store, which moves
+// leases and replicas away from the store if it is overfull.
+func (s *Simulator) tickStoreRebalancers(ctx context.Context, tick time.Time, state state.State) {
+	for storeID := range state.Stores() {
+		s.srs[storeID].Tick(ctx, tick, state)
+	}
+}
+
 // tickMetrics prints the metrics up to the given tick.
 func (s *Simulator) tickMetrics(ctx context.Context, tick time.Time) {
 	if err := s.metrics.Tick(tick, s.state); err != nil {
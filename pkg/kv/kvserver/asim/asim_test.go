@@ -19,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
@@ -38,7 +39,7 @@ func TestRunAllocatorSimulator(t *testing.T) {
 	changer := state.NewReplicaChanger()
 	s := state.LoadConfig(state.ComplexConfig)
 
-	sim := asim.NewSimulator(start, end, interval, rwg, s, exchange, changer, interval, m)
+	sim := asim.NewSimulator(start, end, interval, rwg, s, exchange, changer, interval, allocator.Queries, m)
 	sim.RunSim(ctx)
 }
 
@@ -122,7 +123,7 @@ func TestAllocatorSimulatorSpeed(t *testing.T) {
 
 		s := state.NewTestStateReplDistribution(ranges, replicaDistribution, replsPerRange)
 		testPreGossipStores(s, exchange, preGossipStart)
-		sim := asim.NewSimulator(start, end, interval, rwg, s, exchange, changer, changeDelay, m)
+		sim := asim.NewSimulator(start, end, interval, rwg, s, exchange, changer, changeDelay, allocator.Queries, m)
 
 		startTime := timeutil.Now()
 		sim.RunSim(ctx)
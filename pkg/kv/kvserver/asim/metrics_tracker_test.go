@@ -17,6 +17,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
@@ -116,7 +117,7 @@ func Example_workload() {
 	s := state.LoadConfig(state.ComplexConfig)
 	testPreGossipStores(s, exchange, start)
 
-	sim := asim.NewSimulator(start, end, interval, rwg, s, exchange, changer, interval, m)
+	sim := asim.NewSimulator(start, end, interval, rwg, s, exchange, changer, interval, allocator.Queries, m)
 	sim.RunSim(ctx)
 	// Output:
 	//tick,c_ranges,c_write,c_write_b,c_read,c_read_b,s_ranges,s_write,s_write_b,s_read,s_read_b,c_lease_moves,c_replica_moves,c_replica_b_moves
@@ -106,6 +106,38 @@ func (rc *ReplicaChange) Delay() time.Duration {
 	return rc.Wait
 }
 
+// LeaseTransferChange contains information necessary to transfer the lease
+// for a range to another store.
+type LeaseTransferChange struct {
+	RangeID        RangeID
+	TransferTarget StoreID
+	Wait           time.Duration
+}
+
+// Apply applies a lease transfer for a range. This is an implementation of the
+// Change interface. The transfer is skipped if the target store no longer has
+// a replica of the range, or already holds its lease.
+func (lt *LeaseTransferChange) Apply(s State) {
+	if s.ValidTransfer(lt.RangeID, lt.TransferTarget) {
+		s.TransferLease(lt.RangeID, lt.TransferTarget)
+	}
+}
+
+// Target returns the recipient of the lease for a change.
+func (lt *LeaseTransferChange) Target() StoreID {
+	return lt.TransferTarget
+}
+
+// Range returns the ID the change is for.
+func (lt *LeaseTransferChange) Range() RangeID {
+	return lt.RangeID
+}
+
+// Delay returns the duration taken to complete this state change.
+func (lt *LeaseTransferChange) Delay() time.Duration {
+	return lt.Wait
+}
+
 // replicaChanger is an implementation of the changer interface, for replica
 // changes. It maintains a pending list of changes for ranges, applying changes
 // to state given the delay and other pending changes for the same receiver,
@@ -36,15 +36,17 @@ func TestingWorkloadSeed() int64 {
 	return 42
 }
 
-// NewStorePool returns a store pool with no gossip instance and default values
-// for configuration.
+// NewStorePool returns a store pool with no gossip instance, which uses the
+// cluster settings given.
 func NewStorePool(
-	nodeCountFn storepool.NodeCountFunc, nodeLivenessFn storepool.NodeLivenessFunc, hlc *hlc.Clock,
+	nodeCountFn storepool.NodeCountFunc,
+	nodeLivenessFn storepool.NodeLivenessFunc,
+	hlc *hlc.Clock,
+	st *cluster.Settings,
 ) *storepool.StorePool {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.Background())
 
-	st := cluster.MakeTestingClusterSettings()
 	ambientCtx := log.MakeTestingAmbientContext(stopper.Tracer())
 
 	// Never gossip, pass in nil values.
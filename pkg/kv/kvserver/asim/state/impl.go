@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/google/btree"
 )
@@ -34,6 +35,9 @@ type state struct {
 	clusterinfo ClusterInfo
 	usageInfo   *ClusterUsageInfo
 	clock       ManualSimClock
+	// settings are the cluster settings used by the store pool, and in turn
+	// the allocator, of every store.
+	settings *cluster.Settings
 
 	// Unique ID generators for Nodes and Stores. These are incremented
 	// pre-assignment. So that IDs start from 1.
@@ -53,6 +57,7 @@ func newState() *state {
 		load:      make(map[RangeID]ReplicaLoad),
 		ranges:    newRMap(),
 		usageInfo: newClusterUsageInfo(),
+		settings:  cluster.MakeTestingClusterSettings(),
 	}
 }
 
@@ -273,7 +278,7 @@ func (s *state) AddStore(nodeID NodeID) (Store, bool) {
 		storeID:   storeID,
 		nodeID:    nodeID,
 		desc:      roachpb.StoreDescriptor{StoreID: roachpb.StoreID(storeID), Node: node.Descriptor()},
-		storepool: NewStorePool(s.NodeCountFn(), s.NodeLivenessFn(), hlc.NewClock(&s.clock, 0)),
+		storepool: NewStorePool(s.NodeCountFn(), s.NodeLivenessFn(), hlc.NewClock(&s.clock, 0), s.settings),
 		replicas:  make(map[RangeID]ReplicaID),
 	}
 
@@ -33,10 +33,11 @@ type ReplicaLoad interface {
 // the user to be rated over time. In the future we should introduce a better
 // general pupose stucture that enables rating.
 type ReplicaLoadCounter struct {
-	WriteKeys  int64
-	WriteBytes int64
-	ReadKeys   int64
-	ReadBytes  int64
+	WriteKeys       int64
+	WriteBytes      int64
+	ReadKeys        int64
+	ReadBytes       int64
+	RequestCPUNanos int64
 }
 
 // ApplyLoad applies a load event onto a replica load counter.
@@ -48,15 +49,17 @@ func (rl *ReplicaLoadCounter) ApplyLoad(le workload.LoadEvent) {
 		rl.ReadBytes += le.Size
 		rl.ReadKeys++
 	}
+	rl.RequestCPUNanos += le.RequestCPU
 }
 
 // Load translates the recorded key accesses and size into range usage
 // information.
 func (rl *ReplicaLoadCounter) Load() allocator.RangeUsageInfo {
 	return allocator.RangeUsageInfo{
-		LogicalBytes:     rl.WriteBytes,
-		QueriesPerSecond: float64(rl.WriteKeys + rl.ReadKeys),
-		WritesPerSecond:  float64(rl.WriteKeys),
+		LogicalBytes:             rl.WriteBytes,
+		QueriesPerSecond:         float64(rl.WriteKeys + rl.ReadKeys),
+		WritesPerSecond:          float64(rl.WriteKeys),
+		RequestCPUNanosPerSecond: float64(rl.RequestCPUNanos),
 	}
 }
 
@@ -81,6 +84,7 @@ func Capacity(state State, storeID StoreID) roachpb.StoreCapacity {
 			usage := state.UsageInfo(rng.RangeID())
 			capacity.QueriesPerSecond += usage.QueriesPerSecond
 			capacity.WritesPerSecond += usage.WritesPerSecond
+			capacity.CPUPerSecond += usage.RequestCPUNanosPerSecond
 			capacity.LogicalBytes += usage.LogicalBytes
 
 			capacity.LeaseCount++
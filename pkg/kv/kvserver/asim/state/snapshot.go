@@ -0,0 +1,100 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package state
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/errors"
+)
+
+// ClusterSnapshot describes the placement of ranges in a cluster at a point in
+// time, such as one recorded from a real cluster. It is expressed in terms of
+// simulator identifiers: nodes and stores are numbered from 1, in the order
+// given, and ranges are keyed by their simulated start key.
+type ClusterSnapshot struct {
+	// StoresPerNode contains the number of stores on each node. Node i+1 has
+	// StoresPerNode[i] stores.
+	StoresPerNode []int
+	// Ranges contains the ranges in the cluster.
+	Ranges []RangeSnapshot
+	// Settings, if set, are the cluster settings used by the allocator of
+	// every store. Otherwise, the default settings are used.
+	Settings *cluster.Settings
+}
+
+// RangeSnapshot describes a single range within a ClusterSnapshot.
+type RangeSnapshot struct {
+	// StartKey is the simulated start key of the range; it must be
+	// non-negative and unique within the snapshot.
+	StartKey Key
+	// Replicas contains the stores which hold a replica of the range.
+	Replicas []StoreID
+	// Leaseholder is the store which holds the lease for the range. It must
+	// be one of Replicas.
+	Leaseholder StoreID
+	// Size is the size of the range in bytes.
+	Size int64
+}
+
+// LoadSnapshot returns a State populated with the nodes, stores and ranges
+// described by the snapshot. The cluster usage info of the returned State is
+// empty, so that the placement itself is not counted as replica or lease
+// movement.
+func LoadSnapshot(snapshot ClusterSnapshot) (State, error) {
+	s := newState()
+	if snapshot.Settings != nil {
+		s.settings = snapshot.Settings
+	}
+	for _, stores := range snapshot.StoresPerNode {
+		node := s.AddNode()
+		for i := 0; i < stores; i++ {
+			s.AddStore(node.NodeID())
+		}
+	}
+
+	// NB: All ranges are split out before any replicas are added, as
+	// splitting a range creates replicas for the right hand side on each store
+	// with a replica of the left hand side.
+	rangeIDs := make([]RangeID, len(snapshot.Ranges))
+	for i, r := range snapshot.Ranges {
+		if r.StartKey < 0 {
+			return nil, errors.Errorf("range start key %d must be non-negative", r.StartKey)
+		}
+		_, rhs, ok := s.SplitRange(r.StartKey)
+		if !ok {
+			return nil, errors.Errorf("unable to create range with start key %d", r.StartKey)
+		}
+		rangeIDs[i] = rhs.RangeID()
+	}
+
+	for i, r := range snapshot.Ranges {
+		rng, _ := s.rng(rangeIDs[i])
+		for _, storeID := range r.Replicas {
+			if _, ok := s.addReplica(rng.rangeID, storeID); !ok {
+				return nil, errors.Errorf(
+					"unable to add replica on s%d for range with start key %d", storeID, r.StartKey)
+			}
+		}
+		if r.Leaseholder != 0 {
+			if _, ok := rng.replicas[r.Leaseholder]; !ok {
+				return nil, errors.Errorf(
+					"leaseholder s%d for range with start key %d is not a replica", r.Leaseholder, r.StartKey)
+			}
+			// The first replica added already holds the lease, in which case
+			// the transfer is a no-op.
+			s.TransferLease(rng.rangeID, r.Leaseholder)
+		}
+		rng.size = r.Size
+	}
+
+	s.usageInfo = newClusterUsageInfo()
+	return s, nil
+}
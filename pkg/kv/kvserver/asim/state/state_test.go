@@ -262,3 +262,38 @@ func TestWorkloadApply(t *testing.T) {
 	expectedLoad.QueriesPerSecond *= 10
 	require.Equal(t, expectedLoad, sc3)
 }
+
+// TestLoadSnapshot asserts that loading a cluster snapshot places replicas and
+// leases as described, and that doing so is not counted as movement.
+func TestLoadSnapshot(t *testing.T) {
+	s, err := LoadSnapshot(ClusterSnapshot{
+		StoresPerNode: []int{1, 2, 1},
+		Ranges: []RangeSnapshot{
+			{StartKey: 0, Replicas: []StoreID{1, 2, 3}, Leaseholder: 2, Size: 10},
+			{StartKey: 5, Replicas: []StoreID{2, 4}, Leaseholder: 4, Size: 20},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(s.Nodes()))
+	require.Equal(t, 4, len(s.Stores()))
+	require.Equal(t, []StoreID{2, 3}, s.Nodes()[2].Stores())
+
+	r0 := s.RangeFor(0)
+	require.Len(t, r0.Replicas(), 3)
+	require.True(t, r0.Replicas()[2].HoldsLease())
+	require.Equal(t, int64(10), r0.Size())
+
+	r1 := s.RangeFor(7)
+	require.Len(t, r1.Replicas(), 2)
+	require.True(t, r1.Replicas()[4].HoldsLease())
+	require.Equal(t, int64(20), r1.Size())
+
+	require.Zero(t, s.ClusterUsageInfo().LeaseTransfers)
+
+	// The leaseholder must hold a replica of the range.
+	_, err = LoadSnapshot(ClusterSnapshot{
+		StoresPerNode: []int{1, 1},
+		Ranges:        []RangeSnapshot{{StartKey: 0, Replicas: []StoreID{1}, Leaseholder: 2}},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,251 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package asim
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator/allocatorimpl"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator/storepool"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// StoreRebalancer presents an interface to interact with the simulated store
+// rebalancer of a single store, which moves leases and then replicas away from
+// the store when its load is above the overfull threshold.
+type StoreRebalancer interface {
+	// Tick checks the load of the store once per load based rebalancing
+	// interval. When the store is overfull, lease transfers and then replica
+	// rebalances of its hottest ranges are pushed to the state changer until
+	// the store's load would be below the overfull threshold.
+	Tick(ctx context.Context, tick time.Time, state state.State)
+}
+
+// storeRebalancer is an implementation of the StoreRebalancer interface. It
+// follows the logic of the store rebalancer in kvserver, using the view of the
+// cluster in the store's StorePool.
+type storeRebalancer struct {
+	allocator    allocatorimpl.Allocator
+	storeID      state.StoreID
+	stateChanger state.Changer
+	dim          allocator.LoadDimension
+	next         time.Time
+	delay        time.Duration
+}
+
+// NewStoreRebalancer returns a new simulated store rebalancer, which balances
+// load in the given dimension.
+func NewStoreRebalancer(
+	storeID state.StoreID,
+	stateChanger state.Changer,
+	delay time.Duration,
+	allocator allocatorimpl.Allocator,
+	dim allocator.LoadDimension,
+) StoreRebalancer {
+	return &storeRebalancer{
+		allocator:    allocator,
+		storeID:      storeID,
+		stateChanger: stateChanger,
+		dim:          dim,
+		delay:        delay,
+	}
+}
+
+// Tick checks the load of the store once per load based rebalancing interval,
+// and moves leases and replicas away from the store if it is overfull.
+func (sr *storeRebalancer) Tick(ctx context.Context, tick time.Time, s state.State) {
+	sv := &sr.allocator.StorePool.St.SV
+	if sr.next.IsZero() {
+		// Like the store rebalancer, wait out the first interval so that the
+		// stores have load to compare.
+		sr.next = tick.Add(allocator.LoadBasedRebalanceInterval.Get(sv))
+		return
+	}
+	if tick.Before(sr.next) {
+		return
+	}
+	sr.next = tick.Add(allocator.LoadBasedRebalanceInterval.Get(sv))
+
+	storeList, _, _ := sr.allocator.StorePool.GetStoreList(storepool.StoreFilterSuspect)
+	storeMap := storeList.ToMap()
+	localDesc, ok := storeMap[roachpb.StoreID(sr.storeID)]
+	if !ok {
+		return
+	}
+	options := sr.scorerOptions(ctx)
+	localLoad := func() float64 { return sr.dim.StoreLoad(localDesc.Capacity) }
+	maxThreshold := allocatorimpl.OverfullQPSThreshold(options, storeList.CandidateLoad(sr.dim).Mean)
+	if !(localLoad() > maxThreshold) {
+		return
+	}
+
+	// First transfer leases away, and then consider the ranges for which no
+	// better leaseholder was found for replica rebalancing.
+	var considerForRebalance []state.Range
+	for _, rng := range sr.hottestRanges(s, localLoad()) {
+		if !(localLoad() > maxThreshold) {
+			return
+		}
+		usage := s.UsageInfo(rng.RangeID())
+		target, ok := sr.leaseTarget(rng, sr.dim.RangeLoad(usage), localDesc, storeMap, options)
+		if !ok {
+			considerForRebalance = append(considerForRebalance, rng)
+			continue
+		}
+		change := state.LeaseTransferChange{
+			RangeID:        rng.RangeID(),
+			TransferTarget: target,
+			Wait:           sr.delay,
+		}
+		if _, ok := sr.stateChanger.Push(tick, &change); !ok {
+			continue
+		}
+		log.VEventf(ctx, 1, "s%d: transferring lease for r%d to s%d to better balance %s",
+			sr.storeID, rng.RangeID(), target, sr.dim)
+		moveLoad(localDesc, storeMap[roachpb.StoreID(target)], usage)
+	}
+
+	for _, rng := range considerForRebalance {
+		if !(localLoad() > maxThreshold) {
+			return
+		}
+		usage := s.UsageInfo(rng.RangeID())
+		options.QPSPerReplica = sr.dim.RangeLoad(usage)
+		desc := rng.Descriptor()
+		add, remove, _, ok := sr.allocator.RebalanceVoter(
+			ctx,
+			rng.SpanConfig(),
+			nil, /* raftStatus */
+			desc.Replicas().VoterDescriptors(),
+			desc.Replicas().NonVoterDescriptors(),
+			usage,
+			storepool.StoreFilterSuspect,
+			options,
+		)
+		// Only rebalances which move the replica, and with it the lease, away
+		// from this store reduce its load.
+		if !ok || remove.StoreID != localDesc.StoreID {
+			continue
+		}
+		change := state.ReplicaChange{
+			RangeID: rng.RangeID(),
+			Add:     state.StoreID(add.StoreID),
+			Remove:  sr.storeID,
+			Wait:    sr.delay,
+		}
+		if _, ok := sr.stateChanger.Push(tick, &change); !ok {
+			continue
+		}
+		log.VEventf(ctx, 1, "s%d: rebalancing r%d to s%d to better balance %s",
+			sr.storeID, rng.RangeID(), add.StoreID, sr.dim)
+		moveLoad(localDesc, storeMap[add.StoreID], usage)
+	}
+}
+
+// scorerOptions returns the options used by the store rebalancer to converge
+// load in its dimension.
+func (sr *storeRebalancer) scorerOptions(ctx context.Context) *allocatorimpl.QPSScorerOptions {
+	sv := &sr.allocator.StorePool.St.SV
+	return &allocatorimpl.QPSScorerOptions{
+		StoreHealthOptions:        sr.allocator.StoreHealthOptions(ctx),
+		Deterministic:             sr.allocator.StorePool.Deterministic,
+		LoadDimension:             sr.dim,
+		QPSRebalanceThreshold:     allocator.QPSRebalanceThreshold.Get(sv),
+		MinRequiredQPSDiff:        sr.dim.MinDifferenceForTransfers(sv),
+		MinCPUThresholdDifference: float64(allocator.MinCPUThresholdDifference.Get(sv)),
+	}
+}
+
+// hottestRanges returns the ranges the store holds the lease for, in
+// decreasing order of load. Like the store rebalancer, ranges with load that is
+// too little to matter relative to the store's load are skipped.
+func (sr *storeRebalancer) hottestRanges(s state.State, storeLoad float64) []state.Range {
+	const minLoadFraction = .001
+	var ranges []state.Range
+	loads := make(map[state.RangeID]float64)
+	for _, repl := range s.Replicas(sr.storeID) {
+		if !repl.HoldsLease() {
+			continue
+		}
+		rng, ok := s.Range(repl.Range())
+		if !ok {
+			continue
+		}
+		load := sr.dim.RangeLoad(s.UsageInfo(rng.RangeID()))
+		if load < storeLoad*minLoadFraction {
+			continue
+		}
+		ranges = append(ranges, rng)
+		loads[rng.RangeID()] = load
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		a, b := ranges[i].RangeID(), ranges[j].RangeID()
+		if loads[a] == loads[b] {
+			return a < b
+		}
+		return loads[a] > loads[b]
+	})
+	return ranges
+}
+
+// leaseTarget returns the store of the voter of the range that the lease
+// should be transferred to in order to converge load. This is the least loaded
+// of the other voters, provided that its load differs from the store's by at
+// least MinRequiredQPSDiff and that the transfer would reduce the difference.
+func (sr *storeRebalancer) leaseTarget(
+	rng state.Range,
+	rangeLoad float64,
+	localDesc *roachpb.StoreDescriptor,
+	storeMap map[roachpb.StoreID]*roachpb.StoreDescriptor,
+	options *allocatorimpl.QPSScorerOptions,
+) (state.StoreID, bool) {
+	var best *roachpb.StoreDescriptor
+	for _, voter := range rng.Descriptor().Replicas().VoterDescriptors() {
+		desc, ok := storeMap[voter.StoreID]
+		if !ok || desc.StoreID == localDesc.StoreID {
+			continue
+		}
+		if best == nil || sr.dim.StoreLoad(desc.Capacity) < sr.dim.StoreLoad(best.Capacity) {
+			best = desc
+		}
+	}
+	if best == nil {
+		return 0, false
+	}
+	localLoad, bestLoad := sr.dim.StoreLoad(localDesc.Capacity), sr.dim.StoreLoad(best.Capacity)
+	if localLoad-bestLoad < options.MinRequiredQPSDiff {
+		return 0, false
+	}
+	if math.Abs((localLoad-rangeLoad)-(bestLoad+rangeLoad)) >= localLoad-bestLoad {
+		return 0, false
+	}
+	return state.StoreID(best.StoreID), true
+}
+
+// moveLoad updates the local copies of the store descriptors to reflect the
+// lease of a range with the given usage moving from one store to another, so
+// that later decisions in the same pass take it into account.
+func moveLoad(from, to *roachpb.StoreDescriptor, usage allocator.RangeUsageInfo) {
+	from.Capacity.LeaseCount--
+	from.Capacity.QueriesPerSecond -= usage.QueriesPerSecond
+	from.Capacity.CPUPerSecond -= usage.RequestCPUNanosPerSecond
+	if to != nil {
+		to.Capacity.LeaseCount++
+		to.Capacity.QueriesPerSecond += usage.QueriesPerSecond
+		to.Capacity.CPUPerSecond += usage.RequestCPUNanosPerSecond
+	}
+}
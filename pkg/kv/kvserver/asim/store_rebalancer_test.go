@@ -0,0 +1,111 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package asim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStoreRebalancer asserts that the store rebalancer transfers leases away
+// from an overfull store until its load is below the overfull threshold, and
+// does nothing when the store isn't overfull.
+func TestStoreRebalancer(t *testing.T) {
+	start := state.TestingStartTime()
+	ctx := context.Background()
+	testingDelay := 5 * time.Second
+	testingStore := state.StoreID(1)
+
+	getLeaseCounts := func(s state.State) map[int]int {
+		storeView := make(map[int]int)
+		for _, desc := range s.StoreDescriptors() {
+			storeView[int(desc.StoreID)] = int(desc.Capacity.LeaseCount)
+		}
+		return storeView
+	}
+
+	// testingState returns a state with ten ranges, which have replicas on
+	// each of the three stores and their lease on the first store. Each range
+	// is read the given number of times.
+	testingState := func(readsPerRange int) state.State {
+		var keys []state.Key
+		replicas := make(map[state.Key][]state.StoreID)
+		leaseholders := make(map[state.Key]state.StoreID)
+		for key := state.Key(1); key <= 10; key++ {
+			keys = append(keys, key)
+			replicas[key] = []state.StoreID{1, 2, 3}
+			leaseholders[key] = testingStore
+		}
+		s := state.NewTestState(3 /* nodes */, 1 /* storesPerNode */, keys, replicas, leaseholders)
+		for _, key := range keys {
+			for i := 0; i < readsPerRange; i++ {
+				s.ApplyLoad(workload.LoadEvent{Key: int64(key), Size: 1})
+			}
+		}
+		return s
+	}
+
+	testCases := []struct {
+		desc          string
+		readsPerRange int
+		expected      map[int]int
+	}{
+		{
+			// NB: s1 has 1000 QPS and the mean is 333, so the overfull threshold
+			// is 433. Leases are transferred to the least loaded of s2 and s3
+			// until s1 is below it.
+			desc:          "s1:(l=10,qps=1000) overfull, transfer leases",
+			readsPerRange: 100,
+			expected:      map[int]int{1: 4, 2: 3, 3: 3},
+		},
+		{
+			// NB: s1 has 100 QPS and the mean is 33, which is within the
+			// minimum threshold difference of 100.
+			desc:          "s1:(l=10,qps=100) not overfull, noop",
+			readsPerRange: 10,
+			expected:      map[int]int{1: 10, 2: 0, 3: 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := testingState(tc.readsPerRange)
+			changer := state.NewReplicaChanger()
+			sr := NewStoreRebalancer(
+				testingStore, changer, testingDelay, s.MakeAllocator(testingStore), allocator.Queries,
+			)
+			exchange := state.NewFixedDelayExhange(start, testingDelay, time.Second*0)
+
+			// The store rebalancer waits out the first interval, then checks the
+			// store's load. The changes it makes complete after the delay.
+			interval := allocator.LoadBasedRebalanceInterval.Default()
+			for _, tick := range []time.Time{
+				start,
+				start.Add(interval),
+				start.Add(interval + testingDelay),
+			} {
+				s.TickClock(tick)
+				changer.Tick(tick, s)
+				exchange.Put(tick, s.StoreDescriptors()...)
+				s.UpdateStorePool(testingStore, exchange.Get(tick, roachpb.StoreID(testingStore)))
+				sr.Tick(ctx, tick, s)
+			}
+			require.Equal(t, tc.expected, getLeaseCounts(s))
+		})
+	}
+}
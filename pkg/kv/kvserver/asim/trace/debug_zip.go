@@ -0,0 +1,141 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package trace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/errors"
+)
+
+// FromDebugZip returns the ClusterTrace recorded in an unzipped debug zip,
+// rooted at dir. The stores are read from each node's status.json, and the
+// ranges from each node's range reports (nodes/<id>/ranges/<range id>.json).
+//
+// A range is reported by every node with a replica for it. The descriptor,
+// lease and load of a range are taken from the report of the leaseholder,
+// which is the only replica which records the load on the range. Ranges
+// without a report from their leaseholder fall back to the report with the
+// highest descriptor generation and have no recorded load.
+//
+// Range reports don't record the CPU time spent on a range, so the ranges of
+// the returned trace have no CPU load: a replay of the trace which balances
+// allocator.CPU has no load to balance, and reports a CPU balance of
+// 1. Traces with CPU load need to be recorded as JSON.
+func FromDebugZip(dir string) (ClusterTrace, error) {
+	nodeDirs, err := filepath.Glob(filepath.Join(dir, "debug", "nodes", "*"))
+	if err != nil {
+		return ClusterTrace{}, err
+	}
+	if len(nodeDirs) == 0 {
+		return ClusterTrace{}, errors.Errorf("no nodes found in debug zip %s", dir)
+	}
+
+	var t ClusterTrace
+	reports := make(map[roachpb.RangeID]serverpb.RangeInfo)
+	for _, nodeDir := range nodeDirs {
+		if fi, err := os.Stat(nodeDir); err != nil || !fi.IsDir() {
+			// Skipped nodes are recorded as files.
+			continue
+		}
+
+		var status statuspb.NodeStatus
+		if err := readJSON(filepath.Join(nodeDir, "status.json"), &status); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return ClusterTrace{}, err
+			}
+		}
+		for _, ss := range status.StoreStatuses {
+			t.Stores = append(t.Stores, StoreTrace{
+				NodeID:  ss.Desc.Node.NodeID,
+				StoreID: ss.Desc.StoreID,
+			})
+		}
+
+		rangeFiles, err := filepath.Glob(filepath.Join(nodeDir, "ranges", "*.json"))
+		if err != nil {
+			return ClusterTrace{}, err
+		}
+		for _, rangeFile := range rangeFiles {
+			var ri serverpb.RangeInfo
+			if err := readJSON(rangeFile, &ri); err != nil {
+				return ClusterTrace{}, err
+			}
+			if ri.State.Desc == nil {
+				// The report failed to read the range's state.
+				continue
+			}
+			rangeID := ri.State.Desc.RangeID
+			if prev, ok := reports[rangeID]; !ok || preferReport(ri, prev) {
+				reports[rangeID] = ri
+			}
+		}
+	}
+
+	for _, ri := range reports {
+		t.Ranges = append(t.Ranges, rangeTraceFromReport(ri))
+	}
+	sort.Slice(t.Ranges, func(i, j int) bool { return t.Ranges[i].RangeID < t.Ranges[j].RangeID })
+	return t, nil
+}
+
+// preferReport returns whether the range report a is preferred over b.
+func preferReport(a, b serverpb.RangeInfo) bool {
+	if aLH, bLH := isLeaseholderReport(a), isLeaseholderReport(b); aLH != bLH {
+		return aLH
+	}
+	return a.State.Desc.Generation > b.State.Desc.Generation
+}
+
+func isLeaseholderReport(ri serverpb.RangeInfo) bool {
+	return ri.State.Lease != nil && ri.State.Lease.Replica.StoreID == ri.SourceStoreID
+}
+
+func rangeTraceFromReport(ri serverpb.RangeInfo) RangeTrace {
+	desc := ri.State.Desc
+	r := RangeTrace{
+		RangeID:  desc.RangeID,
+		StartKey: desc.StartKey,
+	}
+	for _, repl := range desc.Replicas().Descriptors() {
+		r.Replicas = append(r.Replicas, repl.StoreID)
+	}
+	if ri.State.Lease != nil {
+		r.Leaseholder = ri.State.Lease.Replica.StoreID
+	}
+	if ri.State.Stats != nil {
+		r.LogicalBytes = ri.State.Stats.Total()
+	}
+	if isLeaseholderReport(ri) {
+		r.ReadsPerSecond = ri.Stats.ReadsPerSecond
+		r.WritesPerSecond = ri.Stats.WritesPerSecond
+		r.ReadBytesPerSecond = ri.Stats.ReadBytesPerSecond
+		r.WriteBytesPerSecond = ri.Stats.WriteBytesPerSecond
+	}
+	return r
+}
+
+func readJSON(path string, v interface{}) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return errors.Wrapf(err, "decoding %s", path)
+	}
+	return nil
+}
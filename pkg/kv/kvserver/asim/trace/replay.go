@@ -0,0 +1,218 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+)
+
+// ReplayOptions configures the replay of a ClusterTrace.
+type ReplayOptions struct {
+	// Duration is the simulated duration of the replay.
+	Duration time.Duration
+	// Interval is the simulated duration of each tick.
+	Interval time.Duration
+	// ChangeDelay is the simulated duration taken to apply a replica change.
+	ChangeDelay time.Duration
+	// GossipDelay is the simulated delay before a store's descriptor is
+	// visible to other stores.
+	GossipDelay time.Duration
+	// LoadDimension is the dimension of load balanced by the simulated store
+	// rebalancers. Balancing CPU requires a trace with CPU load, which traces
+	// loaded from a debug zip don't have.
+	LoadDimension allocator.LoadDimension
+	// Settings, if set, are the cluster settings used by the simulated
+	// allocators. This allows evaluating a change to the allocation settings
+	// before applying it to the recorded cluster.
+	Settings *cluster.Settings
+	// Metrics, if set, receive the per tick metrics of the simulation in CSV
+	// format.
+	Metrics []io.Writer
+}
+
+// DefaultReplayOptions returns the options used to replay a trace, unless
+// overridden.
+func DefaultReplayOptions() ReplayOptions {
+	return ReplayOptions{
+		Duration:    30 * time.Minute,
+		Interval:    10 * time.Second,
+		ChangeDelay: 5 * time.Second,
+		GossipDelay: 100 * time.Millisecond,
+	}
+}
+
+// StoreBalance is the placement of replicas, leases and load on a single store.
+// The load on a store is the recorded load of the ranges it holds the lease
+// for, where the QPS of a range is the rate of keys read and written.
+type StoreBalance struct {
+	StoreID           roachpb.StoreID
+	Replicas          int
+	Leases            int
+	LogicalBytes      int64
+	QueriesPerSecond  float64
+	CPUNanosPerSecond float64
+}
+
+// Balance is the placement of replicas, leases and load across the stores in
+// a cluster, ordered by StoreID.
+type Balance []StoreBalance
+
+// MaxMeanRatio returns the ratio of the maximum to the mean of the value
+// returned for each store, or 1 if the mean is zero. A perfectly balanced
+// cluster has a ratio of 1.
+func (b Balance) MaxMeanRatio(fn func(StoreBalance) float64) float64 {
+	if len(b) == 0 {
+		return 1
+	}
+	var sum, max float64
+	for _, s := range b {
+		v := fn(s)
+		sum += v
+		if v > max {
+			max = v
+		}
+	}
+	if sum == 0 {
+		return 1
+	}
+	return max / (sum / float64(len(b)))
+}
+
+// Summary is the outcome of replaying a ClusterTrace: the movement predicted
+// by the simulated allocators and the resulting balance of the cluster.
+type Summary struct {
+	LeaseTransfers  int64
+	Rebalances      int64
+	BytesRebalanced int64
+	// Before is the balance of the recorded cluster.
+	Before Balance
+	// After is the balance of the cluster at the end of the replay.
+	After Balance
+}
+
+// String returns a table of the movement and balance in the summary.
+func (s Summary) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "lease transfers: %d\n", s.LeaseTransfers)
+	fmt.Fprintf(&buf, "replica rebalances: %d (%d bytes)\n", s.Rebalances, s.BytesRebalanced)
+
+	tw := tabwriter.NewWriter(&buf, 2, 1, 2, ' ', 0)
+	fmt.Fprintf(tw, "max/mean\tbefore\tafter\n")
+	for _, dim := range []struct {
+		name string
+		fn   func(StoreBalance) float64
+	}{
+		{"replicas", func(s StoreBalance) float64 { return float64(s.Replicas) }},
+		{"leases", func(s StoreBalance) float64 { return float64(s.Leases) }},
+		{"logical bytes", func(s StoreBalance) float64 { return float64(s.LogicalBytes) }},
+		{"qps", func(s StoreBalance) float64 { return s.QueriesPerSecond }},
+		{"cpu", func(s StoreBalance) float64 { return s.CPUNanosPerSecond }},
+	} {
+		fmt.Fprintf(tw, "%s\t%.2f\t%.2f\n", dim.name, s.Before.MaxMeanRatio(dim.fn), s.After.MaxMeanRatio(dim.fn))
+	}
+	_ = tw.Flush()
+	return buf.String()
+}
+
+// Replay replays the ClusterTrace through the allocation simulator, and
+// returns the movement and balance it predicts. The predicted movement is that
+// of the simulated replicate queues and store rebalancers.
+func Replay(ctx context.Context, t ClusterTrace, opts ReplayOptions) (Summary, error) {
+	sim, err := t.toSimulation()
+	if err != nil {
+		return Summary{}, err
+	}
+	sim.snapshot.Settings = opts.Settings
+	s, err := state.LoadSnapshot(sim.snapshot)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	// Map each simulated range back to its recorded load. The simulator
+	// doesn't split or merge ranges, so the mapping is fixed for the replay.
+	rangeLoads := make(map[state.RangeID]RangeTrace, len(sim.rangeLoads))
+	for key, r := range sim.rangeLoads {
+		rangeLoads[s.RangeFor(key).RangeID()] = r
+	}
+	before := sim.balance(s, rangeLoads)
+
+	start := state.TestingStartTime()
+	end := start.Add(opts.Duration)
+	preGossipStart := start.Add(-opts.Interval - opts.GossipDelay)
+	exchange := state.NewFixedDelayExhange(preGossipStart, opts.Interval, opts.GossipDelay)
+	// Populate the exchange with the recorded state, so that each store's
+	// allocator has a view of the cluster from the first tick.
+	exchange.Put(preGossipStart, s.StoreDescriptors()...)
+	changer := state.NewReplicaChanger()
+	generators := []workload.Generator{workload.NewReplayGenerator(start, sim.loads)}
+	metrics := asim.NewMetricsTracker(opts.Metrics...)
+
+	simulator := asim.NewSimulator(
+		start, end, opts.Interval, generators, s, exchange, changer, opts.ChangeDelay, opts.LoadDimension, metrics,
+	)
+	simulator.RunSim(ctx)
+
+	usage := s.ClusterUsageInfo()
+	return Summary{
+		LeaseTransfers:  usage.LeaseTransfers,
+		Rebalances:      usage.Rebalances,
+		BytesRebalanced: usage.BytesRebalanced,
+		Before:          before,
+		After:           sim.balance(s, rangeLoads),
+	}, nil
+}
+
+// balance returns the balance of the simulated state, in terms of the
+// recorded stores and load.
+func (sim simulation) balance(s state.State, rangeLoads map[state.RangeID]RangeTrace) Balance {
+	stores := make(map[state.StoreID]*StoreBalance, len(sim.storeIDs))
+	for simStoreID, storeID := range sim.storeIDs {
+		stores[simStoreID] = &StoreBalance{StoreID: storeID}
+	}
+	for rangeID, rng := range s.Ranges() {
+		r, ok := rangeLoads[rangeID]
+		if !ok {
+			// The first range in the simulator, which precedes every
+			// recorded range, has no recorded counterpart.
+			continue
+		}
+		for storeID, repl := range rng.Replicas() {
+			sb := stores[storeID]
+			sb.Replicas++
+			sb.LogicalBytes += rng.Size()
+			if repl.HoldsLease() {
+				sb.Leases++
+				sb.QueriesPerSecond += r.ReadsPerSecond + r.WritesPerSecond
+				sb.CPUNanosPerSecond += r.CPUNanosPerSecond
+			}
+		}
+	}
+
+	b := make(Balance, 0, len(stores))
+	for _, sb := range stores {
+		b = append(b, *sb)
+	}
+	sort.Slice(b, func(i, j int) bool { return b[i].StoreID < b[j].StoreID })
+	return b
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package trace replays the recorded state of a real cluster through the
+// allocation simulator. A ClusterTrace captures the placement of ranges, their
+// leaseholders, the capacity of each store and the load on each range at a
+// point in time. It may be loaded from a debug zip, or encoded as JSON. The
+// trace is converted into an initial simulator state along with a workload
+// which reproduces the recorded load on each range, and the simulation
+// reports the movement it predicts along with how balanced the cluster would
+// be as a result.
+//
+// Traces cannot be loaded from a tsdump, since it only records the time series
+// of nodes and stores, and not the placement of ranges or the load on them.
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/errors"
+)
+
+// ClusterTrace is a snapshot of the state and load of a cluster.
+type ClusterTrace struct {
+	Stores []StoreTrace `json:"stores"`
+	Ranges []RangeTrace `json:"ranges"`
+}
+
+// StoreTrace is the recorded state of a single store.
+type StoreTrace struct {
+	NodeID  roachpb.NodeID  `json:"node_id"`
+	StoreID roachpb.StoreID `json:"store_id"`
+}
+
+// RangeTrace is the recorded state and load of a single range.
+type RangeTrace struct {
+	RangeID     roachpb.RangeID   `json:"range_id"`
+	StartKey    roachpb.RKey      `json:"start_key"`
+	Replicas    []roachpb.StoreID `json:"replicas"`
+	Leaseholder roachpb.StoreID   `json:"leaseholder"`
+	// LogicalBytes is the size of the range.
+	LogicalBytes int64 `json:"logical_bytes"`
+	// The rates of load on the range, as recorded by the leaseholder.
+	ReadsPerSecond      float64 `json:"reads_per_second"`
+	WritesPerSecond     float64 `json:"writes_per_second"`
+	ReadBytesPerSecond  float64 `json:"read_bytes_per_second"`
+	WriteBytesPerSecond float64 `json:"write_bytes_per_second"`
+	// CPUNanosPerSecond is the CPU time spent on the range. Range reports
+	// don't include it, so it is always zero in traces loaded from a debug
+	// zip; see FromDebugZip.
+	CPUNanosPerSecond float64 `json:"cpu_nanos_per_second"`
+}
+
+// Decode decodes a JSON encoded ClusterTrace.
+func Decode(r io.Reader) (ClusterTrace, error) {
+	var t ClusterTrace
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return ClusterTrace{}, errors.Wrap(err, "decoding cluster trace")
+	}
+	return t, nil
+}
+
+// Encode encodes the ClusterTrace as JSON.
+func (t ClusterTrace) Encode(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// simulation is a ClusterTrace translated into the identifiers used by the
+// simulator.
+type simulation struct {
+	snapshot state.ClusterSnapshot
+	loads    []workload.KeyLoad
+	// storeIDs maps the simulated ID of each store to the recorded one.
+	storeIDs map[state.StoreID]roachpb.StoreID
+	// rangeLoads maps the simulated start key of each range to its recorded
+	// load.
+	rangeLoads map[state.Key]RangeTrace
+}
+
+// toSimulation translates the trace into a simulator snapshot and the
+// workload that replays the recorded load.
+//
+// The simulator numbers nodes and stores from 1, so recorded nodes and stores
+// are renumbered in ascending order of their IDs. Simulated keys are integers,
+// so each range is assigned the ordinal of its start key, amongst the recorded
+// ranges, as its simulated start key. The replayed load for a range is applied
+// to its start key.
+func (t ClusterTrace) toSimulation() (simulation, error) {
+	sim := simulation{
+		storeIDs:   make(map[state.StoreID]roachpb.StoreID),
+		rangeLoads: make(map[state.Key]RangeTrace),
+	}
+
+	stores := append([]StoreTrace(nil), t.Stores...)
+	sort.Slice(stores, func(i, j int) bool {
+		if stores[i].NodeID != stores[j].NodeID {
+			return stores[i].NodeID < stores[j].NodeID
+		}
+		return stores[i].StoreID < stores[j].StoreID
+	})
+	simStoreIDs := make(map[roachpb.StoreID]state.StoreID, len(stores))
+	for i, s := range stores {
+		if _, ok := simStoreIDs[s.StoreID]; ok {
+			return simulation{}, errors.Errorf("duplicate store s%d in trace", s.StoreID)
+		}
+		if i == 0 || stores[i-1].NodeID != s.NodeID {
+			sim.snapshot.StoresPerNode = append(sim.snapshot.StoresPerNode, 0)
+		}
+		sim.snapshot.StoresPerNode[len(sim.snapshot.StoresPerNode)-1]++
+		simStoreIDs[s.StoreID] = state.StoreID(i + 1)
+		sim.storeIDs[state.StoreID(i+1)] = s.StoreID
+	}
+
+	ranges := append([]RangeTrace(nil), t.Ranges...)
+	sort.Slice(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].StartKey, ranges[j].StartKey) < 0
+	})
+	for i, r := range ranges {
+		if i > 0 && ranges[i-1].StartKey.Equal(r.StartKey) {
+			return simulation{}, errors.Errorf(
+				"r%d and r%d have the same start key %s", ranges[i-1].RangeID, r.RangeID, r.StartKey)
+		}
+		key := state.Key(i)
+		rs := state.RangeSnapshot{
+			StartKey: key,
+			Size:     r.LogicalBytes,
+		}
+		for _, storeID := range r.Replicas {
+			simStoreID, ok := simStoreIDs[storeID]
+			if !ok {
+				return simulation{}, errors.Errorf("r%d has a replica on unknown store s%d", r.RangeID, storeID)
+			}
+			rs.Replicas = append(rs.Replicas, simStoreID)
+		}
+		if r.Leaseholder != 0 {
+			simStoreID, ok := simStoreIDs[r.Leaseholder]
+			if !ok {
+				return simulation{}, errors.Errorf("r%d has a lease on unknown store s%d", r.RangeID, r.Leaseholder)
+			}
+			rs.Leaseholder = simStoreID
+		}
+		sim.snapshot.Ranges = append(sim.snapshot.Ranges, rs)
+		sim.loads = append(sim.loads, workload.KeyLoad{
+			Key:                 int64(key),
+			ReadsPerSecond:      r.ReadsPerSecond,
+			WritesPerSecond:     r.WritesPerSecond,
+			ReadBytesPerSecond:  r.ReadBytesPerSecond,
+			WriteBytesPerSecond: r.WriteBytesPerSecond,
+			CPUNanosPerSecond:   r.CPUNanosPerSecond,
+		})
+		sim.rangeLoads[key] = r
+	}
+	return sim, nil
+}
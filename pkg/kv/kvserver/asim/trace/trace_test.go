@@ -0,0 +1,172 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/stretchr/testify/require"
+)
+
+// testImbalancedTrace returns a trace of a cluster with four stores, where
+// every range has replicas on the first three stores only and every lease is
+// on the first store.
+func testImbalancedTrace(ranges int) ClusterTrace {
+	var t ClusterTrace
+	for i := 1; i <= 4; i++ {
+		// Use non-contiguous IDs, as a recorded cluster may have.
+		t.Stores = append(t.Stores, StoreTrace{
+			NodeID:  roachpb.NodeID(10 * i),
+			StoreID: roachpb.StoreID(100 * i),
+		})
+	}
+	for i := 0; i < ranges; i++ {
+		t.Ranges = append(t.Ranges, RangeTrace{
+			RangeID:         roachpb.RangeID(ranges - i),
+			StartKey:        roachpb.RKey(fmt.Sprintf("k%04d", i)),
+			Replicas:        []roachpb.StoreID{100, 200, 300},
+			Leaseholder:     100,
+			LogicalBytes:    1 << 20,
+			ReadsPerSecond:  10,
+			WritesPerSecond: 1,
+		})
+	}
+	return t
+}
+
+func TestClusterTraceEncodeDecode(t *testing.T) {
+	trace := testImbalancedTrace(3)
+	var buf bytes.Buffer
+	require.NoError(t, trace.Encode(&buf))
+	decoded, err := Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, trace, decoded)
+}
+
+func TestClusterTraceToSimulation(t *testing.T) {
+	trace := testImbalancedTrace(3)
+	sim, err := trace.toSimulation()
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 1, 1, 1}, sim.snapshot.StoresPerNode)
+	require.Equal(t, roachpb.StoreID(300), sim.storeIDs[3])
+
+	// Ranges are ordered by their recorded start key, not range ID.
+	require.Len(t, sim.snapshot.Ranges, 3)
+	for i, r := range sim.snapshot.Ranges {
+		require.Equal(t, state.Key(i), r.StartKey)
+		require.Equal(t, []state.StoreID{1, 2, 3}, r.Replicas)
+		require.Equal(t, state.StoreID(1), r.Leaseholder)
+		require.Equal(t, roachpb.RangeID(3-i), sim.rangeLoads[r.StartKey].RangeID)
+	}
+
+	// A replica on a store which isn't in the trace is an error.
+	trace.Ranges[0].Replicas = append(trace.Ranges[0].Replicas, 500)
+	_, err = trace.toSimulation()
+	require.Error(t, err)
+}
+
+// TestReplay asserts that replaying an imbalanced trace predicts movement
+// towards the empty store, which improves the balance of the cluster.
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	summary, err := Replay(ctx, testImbalancedTrace(30), DefaultReplayOptions())
+	require.NoError(t, err)
+
+	replicas := func(s StoreBalance) float64 { return float64(s.Replicas) }
+	require.Len(t, summary.Before, 4)
+	require.Equal(t, roachpb.StoreID(400), summary.Before[3].StoreID)
+	require.Zero(t, summary.Before[3].Replicas)
+	require.Equal(t, 30, summary.Before[0].Leases)
+
+	require.Greater(t, summary.Rebalances, int64(0))
+	require.Greater(t, summary.After[3].Replicas, 0)
+	require.Less(t, summary.After.MaxMeanRatio(replicas), summary.Before.MaxMeanRatio(replicas))
+
+	// The store rebalancer of the first store moves leases away from it.
+	qps := func(s StoreBalance) float64 { return s.QueriesPerSecond }
+	require.Greater(t, summary.LeaseTransfers, int64(0))
+	require.Less(t, summary.After[0].Leases, 30)
+	require.Less(t, summary.After.MaxMeanRatio(qps), summary.Before.MaxMeanRatio(qps))
+	require.Contains(t, summary.String(), "replicas")
+}
+
+func TestFromDebugZip(t *testing.T) {
+	dir := t.TempDir()
+	writeJSON := func(path string, v interface{}) {
+		path = filepath.Join(dir, "debug", "nodes", path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, b, 0644))
+	}
+
+	desc := roachpb.RangeDescriptor{
+		RangeID:  7,
+		StartKey: roachpb.RKey("a"),
+		EndKey:   roachpb.RKey("b"),
+		InternalReplicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1, ReplicaID: 1},
+			{NodeID: 2, StoreID: 2, ReplicaID: 2},
+		},
+	}
+	lease := roachpb.Lease{Replica: desc.InternalReplicas[1]}
+	report := func(storeID roachpb.StoreID, reads float64) serverpb.RangeInfo {
+		return serverpb.RangeInfo{
+			SourceStoreID: storeID,
+			State: kvserverpb.RangeInfo{
+				ReplicaState: kvserverpb.ReplicaState{
+					Desc:  &desc,
+					Lease: &lease,
+					Stats: &enginepb.MVCCStats{KeyBytes: 10, ValBytes: 20},
+				},
+			},
+			Stats: serverpb.RangeStatistics{ReadsPerSecond: reads},
+		}
+	}
+	for i := 1; i <= 2; i++ {
+		writeJSON(fmt.Sprintf("%d/status.json", i), statuspb.NodeStatus{
+			StoreStatuses: []statuspb.StoreStatus{{
+				Desc: roachpb.StoreDescriptor{
+					StoreID: roachpb.StoreID(i),
+					Node:    roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i)},
+				},
+			}},
+		})
+		// Only the leaseholder, s2, records the load on the range.
+		writeJSON(fmt.Sprintf("%d/ranges/7.json", i), report(roachpb.StoreID(i), float64(i)))
+	}
+
+	trace, err := FromDebugZip(dir)
+	require.NoError(t, err)
+	require.Equal(t, ClusterTrace{
+		Stores: []StoreTrace{{NodeID: 1, StoreID: 1}, {NodeID: 2, StoreID: 2}},
+		Ranges: []RangeTrace{{
+			RangeID:        7,
+			StartKey:       roachpb.RKey("a"),
+			Replicas:       []roachpb.StoreID{1, 2},
+			Leaseholder:    2,
+			LogicalBytes:   30,
+			ReadsPerSecond: 2,
+		}},
+	}, trace)
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package workload
+
+import (
+	"container/list"
+	"time"
+)
+
+// KeyLoad is the rate of load recorded against a single key, such as the
+// start key of a range in a recorded cluster trace.
+type KeyLoad struct {
+	Key                 int64
+	ReadsPerSecond      float64
+	WritesPerSecond     float64
+	ReadBytesPerSecond  float64
+	WriteBytesPerSecond float64
+	// CPUNanosPerSecond is the CPU time spent evaluating requests against the
+	// key per second. It is divided evenly between the generated operations.
+	CPUNanosPerSecond float64
+}
+
+// ReplayGenerator generates operations that reproduce a fixed rate of load
+// against each of a set of keys. Unlike the RandomGenerator it is
+// deterministic: the operations generated up to some time depend only on the
+// rates given.
+type ReplayGenerator struct {
+	loads    []KeyLoad
+	lastRun  time.Time
+	reads    []float64
+	writes   []float64
+	opBuffer list.List
+}
+
+// NewReplayGenerator returns a generator that replays the rates of load given,
+// starting at start.
+func NewReplayGenerator(start time.Time, loads []KeyLoad) Generator {
+	return newReplayGenerator(start, loads)
+}
+
+func newReplayGenerator(start time.Time, loads []KeyLoad) *ReplayGenerator {
+	return &ReplayGenerator{
+		loads:   loads,
+		lastRun: start,
+		reads:   make([]float64, len(loads)),
+		writes:  make([]float64, len(loads)),
+	}
+}
+
+// GetNext is part of the Generator interface.
+func (rg *ReplayGenerator) GetNext(maxTime time.Time) (done bool, event LoadEvent) {
+	rg.maybeUpdateBuffer(maxTime)
+	if next := rg.opBuffer.Front(); next != nil {
+		rg.opBuffer.Remove(next)
+		return false, next.Value.(LoadEvent)
+	}
+	return true, LoadEvent{}
+}
+
+// maybeUpdateBuffer generates the operations for each key which are due in the
+// elapsed duration since the last run. Fractional operations are carried over
+// to the next run, so that keys with a rate below one operation per interval
+// still receive load.
+func (rg *ReplayGenerator) maybeUpdateBuffer(maxTime time.Time) {
+	elapsed := maxTime.Sub(rg.lastRun).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	for i, load := range rg.loads {
+		rg.reads[i] += load.ReadsPerSecond * elapsed
+		rg.writes[i] += load.WritesPerSecond * elapsed
+		reads, writes := int(rg.reads[i]), int(rg.writes[i])
+		rg.reads[i] -= float64(reads)
+		rg.writes[i] -= float64(writes)
+
+		var readSize, writeSize, cpu int64
+		if load.ReadsPerSecond > 0 {
+			readSize = int64(load.ReadBytesPerSecond / load.ReadsPerSecond)
+		}
+		if load.WritesPerSecond > 0 {
+			writeSize = int64(load.WriteBytesPerSecond / load.WritesPerSecond)
+		}
+		if ops := load.ReadsPerSecond + load.WritesPerSecond; ops > 0 {
+			cpu = int64(load.CPUNanosPerSecond / ops)
+		}
+		for read := 0; read < reads; read++ {
+			rg.opBuffer.PushBack(LoadEvent{
+				Key:        load.Key,
+				Size:       readSize,
+				RequestCPU: cpu,
+			})
+		}
+		for write := 0; write < writes; write++ {
+			rg.opBuffer.PushBack(LoadEvent{
+				Key:        load.Key,
+				Size:       writeSize,
+				IsWrite:    true,
+				RequestCPU: cpu,
+			})
+		}
+	}
+	rg.lastRun = maxTime
+}
@@ -23,6 +23,8 @@ type LoadEvent struct {
 	IsWrite bool
 	Size    int64
 	Key     int64
+	// RequestCPU is the CPU time, in nanoseconds, spent evaluating the op.
+	RequestCPU int64
 }
 
 // Generator generates a workload where each op contains: key,
@@ -131,3 +131,39 @@ func TestRandWorkloadGenerator(t *testing.T) {
 		require.Equal(t, math.Round(tc.readRatio*100), math.Round((float64(stats.reads)/float64(stats.reads+stats.writes))*100))
 	}
 }
+
+// TestReplayGenerator asserts that the replay generator reproduces the rates
+// of load given for each key, including rates below one operation per
+// interval.
+func TestReplayGenerator(t *testing.T) {
+	start := time.Date(2022, 03, 21, 11, 0, 0, 0, time.UTC)
+	loads := []KeyLoad{
+		{Key: 1, ReadsPerSecond: 10, WritesPerSecond: 2, ReadBytesPerSecond: 1000, WriteBytesPerSecond: 400, CPUNanosPerSecond: 1200},
+		{Key: 2, ReadsPerSecond: 0.25},
+	}
+	g := newReplayGenerator(start, loads)
+
+	reads, writes := make(map[int64]int), make(map[int64]int)
+	for tick := 1; tick <= 100; tick++ {
+		for {
+			done, event := g.GetNext(start.Add(time.Duration(tick) * time.Second))
+			if done {
+				break
+			}
+			if event.IsWrite {
+				writes[event.Key]++
+				require.Equal(t, int64(200), event.Size)
+			} else {
+				reads[event.Key]++
+				if event.Key == 1 {
+					require.Equal(t, int64(100), event.Size)
+				}
+			}
+			if event.Key == 1 {
+				require.Equal(t, int64(100), event.RequestCPU)
+			}
+		}
+	}
+	require.Equal(t, map[int64]int{1: 1000, 2: 25}, reads)
+	require.Equal(t, map[int64]int{1: 200}, writes)
+}
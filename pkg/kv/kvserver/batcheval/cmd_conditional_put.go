@@ -49,6 +49,10 @@ func ConditionalPut(
 	args := cArgs.Args.(*roachpb.ConditionalPutRequest)
 	h := cArgs.Header
 
+	if err := cArgs.EvalCtx.CheckStorageQuota(); err != nil {
+		return result.Result{}, err
+	}
+
 	var ts hlc.Timestamp
 	if !args.Inline {
 		ts = h.Timestamp
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/abortspan"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/gc"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rditer"
@@ -169,6 +170,11 @@ func declareKeysEndTxn(
 				latchSpans.AddNonMVCC(spanset.SpanReadWrite, roachpb.Span{
 					Key: keys.RangePriorReadSummaryKey(mt.LeftDesc.RangeID),
 				})
+				// Merges reconcile the downsampled retention state of the RHS
+				// with that of the LHS.
+				latchSpans.AddNonMVCC(spanset.SpanReadWrite, roachpb.Span{
+					Key: keys.RangeGCRetentionKey(mt.LeftDesc.RangeID),
+				})
 			}
 		}
 	}
@@ -1100,6 +1106,19 @@ func splitTriggerHelper(
 		if err != nil {
 			return enginepb.MVCCStats{}, result.Result{}, errors.Wrap(err, "unable to write initial Replica state")
 		}
+		// The RHS inherits the downsampled retention state of the LHS, since
+		// its history was garbage collected under the same policy.
+		gcRetention, err := sl.LoadGCRetention(ctx, batch)
+		if err != nil {
+			return enginepb.MVCCStats{}, result.Result{}, errors.Wrap(err, "unable to load GCRetention")
+		}
+		if gcRetention != nil {
+			if err := stateloader.Make(split.RightDesc.RangeID).SetGCRetention(
+				ctx, batch, h.AbsPostSplitRight(), gcRetention,
+			); err != nil {
+				return enginepb.MVCCStats{}, result.Result{}, errors.Wrap(err, "unable to write GCRetention")
+			}
+		}
 	}
 
 	var pd result.Result
@@ -1193,9 +1212,61 @@ func mergeTrigger(
 	pd.Replicated.Merge = &kvserverpb.Merge{
 		MergeTrigger: *merge,
 	}
+
+	// Reads below the GC threshold of the merged range are only served if both
+	// sides retained their timestamps, so the retention state of the LHS is
+	// reconciled with that of the RHS.
+	mergedRetention, err := mergeGCRetention(ctx, batch, merge)
+	if err != nil {
+		return result.Result{}, err
+	}
+	if mergedRetention != nil {
+		if err := stateloader.Make(merge.LeftDesc.RangeID).SetGCRetention(
+			ctx, batch, ms, mergedRetention,
+		); err != nil {
+			return result.Result{}, errors.Wrap(err, "unable to write GCRetention")
+		}
+		pd.Replicated.State = &kvserverpb.ReplicaState{GCRetention: mergedRetention}
+	}
 	return pd, nil
 }
 
+// mergeGCRetention returns the downsampled retention state of the range
+// resulting from the merge, or nil if the retention state of the LHS carries
+// over unchanged. The merged range only retains the timestamps retained by the
+// tiers of both sides above both of their retention thresholds. If the tiers
+// of the two sides differ, the retention state is dropped by recording no
+// tiers, which stops reads below the GC threshold from being served until the
+// MVCC GC queue records the tiers of the merged range.
+func mergeGCRetention(
+	ctx context.Context, reader storage.Reader, merge *roachpb.MergeTrigger,
+) (*roachpb.GCRetention, error) {
+	lhs, err := stateloader.Make(merge.LeftDesc.RangeID).LoadGCRetention(ctx, reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load LHS GCRetention")
+	}
+	rhs, err := stateloader.Make(merge.RightDesc.RangeID).LoadGCRetention(ctx, reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load RHS GCRetention")
+	}
+	if lhs == nil && rhs == nil || lhs != nil && lhs.Equal(rhs) {
+		return nil, nil
+	}
+	var merged roachpb.GCRetention
+	if lhs != nil && rhs != nil &&
+		gc.RetentionTiersSubsume(lhs.Tiers, rhs.Tiers) &&
+		gc.RetentionTiersSubsume(rhs.Tiers, lhs.Tiers) {
+		merged.Tiers = lhs.Tiers
+	}
+	if lhs != nil {
+		merged.Threshold.Forward(lhs.Threshold)
+	}
+	if rhs != nil {
+		merged.Threshold.Forward(rhs.Threshold)
+	}
+	return &merged, nil
+}
+
 func changeReplicasTrigger(
 	_ context.Context, rec EvalContext, _ storage.Batch, change *roachpb.ChangeReplicasTrigger,
 ) result.Result {
@@ -18,6 +18,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/abortspan"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/stateloader"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -1183,3 +1184,128 @@ func TestCommitWaitBeforeIntentResolutionIfCommitTrigger(t *testing.T) {
 		}
 	})
 }
+
+// TestSplitMergeTriggersGCRetention verifies that the RHS of a split inherits
+// the downsampled retention state of the LHS, and that a merge only keeps the
+// retention tiers if both sides honored the same ones.
+func TestSplitMergeTriggersGCRetention(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	repl := roachpb.ReplicaDescriptor{NodeID: 1, StoreID: 1, ReplicaID: 1}
+	desc := roachpb.RangeDescriptor{
+		RangeID:          1,
+		StartKey:         roachpb.RKey("a"),
+		EndKey:           roachpb.RKey("z"),
+		InternalReplicas: []roachpb.ReplicaDescriptor{repl},
+	}
+	leftDesc, rightDesc := desc, desc
+	leftDesc.EndKey = roachpb.RKey("m")
+	rightDesc.RangeID = 2
+	rightDesc.StartKey = roachpb.RKey("m")
+
+	ts := func(wallTime int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wallTime} }
+	hourly := []roachpb.GCRetentionTier{{IntervalSeconds: 3600, TTLSeconds: 86400}}
+	daily := []roachpb.GCRetentionTier{{IntervalSeconds: 86400, TTLSeconds: 604800}}
+	retention := &roachpb.GCRetention{Threshold: ts(10), Tiers: hourly}
+
+	t.Run("split", func(t *testing.T) {
+		db := storage.NewDefaultInMemForTesting()
+		defer db.Close()
+		batch := db.NewBatch()
+		defer batch.Close()
+
+		lsl := stateloader.Make(desc.RangeID)
+		require.NoError(t, lsl.SetLease(ctx, batch, nil, roachpb.Lease{Replica: repl}))
+		require.NoError(t, lsl.SetGCRetention(ctx, batch, nil, retention))
+
+		evalCtx := (&MockEvalCtx{
+			Desc:      &desc,
+			AbortSpan: abortspan.New(desc.RangeID),
+		}).EvalContext()
+		split := &roachpb.SplitTrigger{LeftDesc: leftDesc, RightDesc: rightDesc}
+		_, _, err := splitTrigger(ctx, evalCtx, batch, enginepb.MVCCStats{}, split, ts(100))
+		require.NoError(t, err)
+
+		rightRetention, err := stateloader.Make(rightDesc.RangeID).LoadGCRetention(ctx, batch)
+		require.NoError(t, err)
+		require.Equal(t, retention, rightRetention)
+	})
+
+	for _, tc := range []struct {
+		name         string
+		left, right  *roachpb.GCRetention
+		expChanged   bool
+		expRetention *roachpb.GCRetention
+	}{
+		{
+			name:         "no retention",
+			expRetention: nil,
+		},
+		{
+			name:         "identical",
+			left:         retention,
+			right:        retention,
+			expRetention: retention,
+		},
+		{
+			name:         "same tiers",
+			left:         retention,
+			right:        &roachpb.GCRetention{Threshold: ts(20), Tiers: hourly},
+			expChanged:   true,
+			expRetention: &roachpb.GCRetention{Threshold: ts(20), Tiers: hourly},
+		},
+		{
+			name:         "different tiers",
+			left:         &roachpb.GCRetention{Threshold: ts(30), Tiers: daily},
+			right:        retention,
+			expChanged:   true,
+			expRetention: &roachpb.GCRetention{Threshold: ts(30)},
+		},
+		{
+			name:         "left only",
+			left:         retention,
+			expChanged:   true,
+			expRetention: &roachpb.GCRetention{Threshold: ts(10)},
+		},
+		{
+			name:         "right only",
+			right:        retention,
+			expChanged:   true,
+			expRetention: &roachpb.GCRetention{Threshold: ts(10)},
+		},
+	} {
+		t.Run("merge/"+tc.name, func(t *testing.T) {
+			db := storage.NewDefaultInMemForTesting()
+			defer db.Close()
+			batch := db.NewBatch()
+			defer batch.Close()
+
+			lsl := stateloader.Make(leftDesc.RangeID)
+			if tc.left != nil {
+				require.NoError(t, lsl.SetGCRetention(ctx, batch, nil, tc.left))
+			}
+			if tc.right != nil {
+				require.NoError(t, stateloader.Make(rightDesc.RangeID).SetGCRetention(
+					ctx, batch, nil, tc.right))
+			}
+
+			evalCtx := (&MockEvalCtx{Desc: &leftDesc}).EvalContext()
+			merge := &roachpb.MergeTrigger{LeftDesc: desc, RightDesc: rightDesc}
+			var ms enginepb.MVCCStats
+			res, err := mergeTrigger(ctx, evalCtx, batch, &ms, merge, ts(100))
+			require.NoError(t, err)
+
+			if tc.expChanged {
+				require.NotNil(t, res.Replicated.State)
+				require.Equal(t, tc.expRetention, res.Replicated.State.GCRetention)
+			} else {
+				require.Nil(t, res.Replicated.State)
+			}
+			mergedRetention, err := lsl.LoadGCRetention(ctx, batch)
+			require.NoError(t, err)
+			require.Equal(t, tc.expRetention, mergedRetention)
+		})
+	}
+}
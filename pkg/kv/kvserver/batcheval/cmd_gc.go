@@ -16,6 +16,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/gc"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -44,12 +45,22 @@ func declareKeysGC(
 			latchSpans.AddMVCC(spanset.SpanReadWrite, roachpb.Span{Key: key.Key}, header.Timestamp)
 		}
 	}
+	for _, vr := range gcr.VersionRanges {
+		if keys.IsLocal(vr.Key) {
+			latchSpans.AddNonMVCC(spanset.SpanReadWrite, roachpb.Span{Key: vr.Key})
+		} else {
+			latchSpans.AddMVCC(spanset.SpanReadWrite, roachpb.Span{Key: vr.Key}, header.Timestamp)
+		}
+	}
 	// Be smart here about blocking on the threshold keys. The MVCC GC queue can
 	// send an empty request first to bump the thresholds, and then another one
 	// that actually does work but can avoid declaring these keys below.
 	if !gcr.Threshold.IsEmpty() {
 		latchSpans.AddNonMVCC(spanset.SpanReadWrite, roachpb.Span{Key: keys.RangeGCThresholdKey(rs.GetRangeID())})
 	}
+	if gcr.Retention != nil {
+		latchSpans.AddNonMVCC(spanset.SpanReadWrite, roachpb.Span{Key: keys.RangeGCRetentionKey(rs.GetRangeID())})
+	}
 	// Needed for Range bounds checks in calls to EvalContext.ContainsKey.
 	latchSpans.AddNonMVCC(spanset.SpanReadOnly, roachpb.Span{Key: keys.RangeDescriptorKey(rs.GetStartKey())})
 }
@@ -90,6 +101,16 @@ func GC(
 		return result.Result{}, errors.AssertionFailedf(
 			"GC request can set threshold or it can GC keys, but it is unsafe for it to do both")
 	}
+	// The same applies to the retention tiers, which allow reads below the
+	// threshold.
+	if (!args.Threshold.IsEmpty() || args.Retention != nil) && len(args.VersionRanges) != 0 {
+		return result.Result{}, errors.AssertionFailedf(
+			"GC request can set threshold or it can GC version ranges, but it is unsafe for it to do both")
+	}
+	if args.Retention != nil && len(args.Keys) != 0 {
+		return result.Result{}, errors.AssertionFailedf(
+			"GC request can set retention or it can GC keys, but it is unsafe for it to do both")
+	}
 
 	// All keys must be inside the current replica range. Keys outside
 	// of this range in the GC request are dropped silently, which is
@@ -119,6 +140,28 @@ func GC(
 		}
 	}
 
+	// Garbage collect the specified version ranges from the middle of the
+	// histories of their keys, which is the same as above otherwise.
+	var localVersionRanges, globalVersionRanges []roachpb.GCRequest_GCVersionRange
+	for _, vr := range args.VersionRanges {
+		if cArgs.EvalCtx.ContainsKey(vr.Key) {
+			if keys.IsLocal(vr.Key) {
+				localVersionRanges = append(localVersionRanges, vr)
+			} else {
+				globalVersionRanges = append(globalVersionRanges, vr)
+			}
+		}
+	}
+	for _, versionRanges := range [][]roachpb.GCRequest_GCVersionRange{
+		localVersionRanges, globalVersionRanges,
+	} {
+		if err := storage.MVCCGarbageCollectVersionRanges(
+			ctx, readWriter, cArgs.Stats, versionRanges,
+		); err != nil {
+			return result.Result{}, err
+		}
+	}
+
 	// Optionally bump the GC threshold timestamp.
 	var res result.Result
 	if !args.Threshold.IsEmpty() {
@@ -145,5 +188,36 @@ func GC(
 		}
 	}
 
+	// Optionally record the retention tiers which the versions below the GC
+	// threshold are subject to.
+	if args.Retention != nil {
+		sl := MakeStateLoader(cArgs.EvalCtx)
+		oldRetention, err := sl.LoadGCRetention(ctx, readWriter)
+		if err != nil {
+			return result.Result{}, err
+		}
+		// Reads below the GC threshold are only served above the retention
+		// threshold. If the new tiers retain timestamps which the old tiers did
+		// not, the versions for them may have been removed already, so the
+		// retention threshold can't be below the GC threshold until the history
+		// that was subject to the old tiers has aged out.
+		newRetention := *args.Retention
+		if oldRetention != nil && gc.RetentionTiersSubsume(oldRetention.Tiers, newRetention.Tiers) {
+			newRetention.Threshold = oldRetention.Threshold
+		} else {
+			newRetention.Threshold = cArgs.EvalCtx.GetGCThreshold()
+		}
+		newRetention.Threshold.Forward(args.Retention.Threshold)
+		if oldRetention == nil || !oldRetention.Equal(&newRetention) {
+			if err := sl.SetGCRetention(ctx, readWriter, cArgs.Stats, &newRetention); err != nil {
+				return result.Result{}, err
+			}
+			if res.Replicated.State == nil {
+				res.Replicated.State = &kvserverpb.ReplicaState{}
+			}
+			res.Replicated.State.GCRetention = &newRetention
+		}
+	}
+
 	return res, nil
 }
@@ -32,6 +32,10 @@ func Increment(
 	h := cArgs.Header
 	reply := resp.(*roachpb.IncrementResponse)
 
+	if err := cArgs.EvalCtx.CheckStorageQuota(); err != nil {
+		return result.Result{}, err
+	}
+
 	newVal, err := storage.MVCCIncrement(
 		ctx, readWriter, cArgs.Stats, args.Key, h.Timestamp, cArgs.Now, h.Txn, args.Increment)
 	reply.NewValue = newVal
@@ -32,6 +32,10 @@ func InitPut(
 	args := cArgs.Args.(*roachpb.InitPutRequest)
 	h := cArgs.Header
 
+	if err := cArgs.EvalCtx.CheckStorageQuota(); err != nil {
+		return result.Result{}, err
+	}
+
 	var err error
 	if args.Blind {
 		err = storage.MVCCBlindInitPut(
@@ -48,6 +48,10 @@ func Put(
 	h := cArgs.Header
 	ms := cArgs.Stats
 
+	if err := cArgs.EvalCtx.CheckStorageQuota(); err != nil {
+		return result.Result{}, err
+	}
+
 	var ts hlc.Timestamp
 	if !args.Inline {
 		ts = h.Timestamp
@@ -22,7 +22,7 @@ import (
 )
 
 func init() {
-	RegisterReadOnlyCommand(roachpb.Scan, DefaultDeclareIsolatedKeys, Scan)
+	RegisterReadWriteCommand(roachpb.Scan, DefaultDeclareIsolatedKeys, Scan)
 }
 
 // Scan scans the key range specified by start key through end key
@@ -30,7 +30,7 @@ func init() {
 // stores the number of scan results remaining for this batch
 // (MaxInt64 for no limit).
 func Scan(
-	ctx context.Context, reader storage.Reader, cArgs CommandArgs, resp roachpb.Response,
+	ctx context.Context, readWriter storage.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
 ) (result.Result, error) {
 	args := cArgs.Args.(*roachpb.ScanRequest)
 	h := cArgs.Header
@@ -60,14 +60,14 @@ func Scan(
 	switch args.ScanFormat {
 	case roachpb.BATCH_RESPONSE:
 		scanRes, err = storage.MVCCScanToBytes(
-			ctx, reader, args.Key, args.EndKey, h.Timestamp, opts)
+			ctx, readWriter, args.Key, args.EndKey, h.Timestamp, opts)
 		if err != nil {
 			return result.Result{}, err
 		}
 		reply.BatchResponses = scanRes.KVData
 	case roachpb.KEY_VALUES:
 		scanRes, err = storage.MVCCScan(
-			ctx, reader, args.Key, args.EndKey, h.Timestamp, opts)
+			ctx, readWriter, args.Key, args.EndKey, h.Timestamp, opts)
 		if err != nil {
 			return result.Result{}, err
 		}
@@ -90,14 +90,15 @@ func Scan(
 		// one in CollectIntentRows either so that we're guaranteed to use the
 		// same cached iterator and observe a consistent snapshot of the engine.
 		const usePrefixIter = false
-		reply.IntentRows, err = CollectIntentRows(ctx, reader, usePrefixIter, scanRes.Intents)
+		reply.IntentRows, err = CollectIntentRows(ctx, readWriter, usePrefixIter, scanRes.Intents)
 		if err != nil {
 			return result.Result{}, err
 		}
 	}
 
 	if args.KeyLocking != lock.None && h.Txn != nil {
-		err = acquireUnreplicatedLocksOnKeys(&res, h.Txn, args.ScanFormat, &scanRes)
+		err = acquireLocksOnKeys(ctx, readWriter, &res, h.Txn, args.KeyLocking,
+			args.KeyLockingDurability, args.ScanFormat, &scanRes)
 		if err != nil {
 			return result.Result{}, err
 		}
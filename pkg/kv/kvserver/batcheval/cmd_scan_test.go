@@ -17,6 +17,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
@@ -28,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -241,6 +244,64 @@ func TestScanReverseScanWholeRows(t *testing.T) {
 	})
 }
 
+// TestScanReplicatedSharedLocking checks that a locking scan acquiring
+// replicated Shared locks writes them to the engine, and that they conflict
+// with the Exclusive locks of other transactions but not with their Shared
+// locks.
+func TestScanReplicatedSharedLocking(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	ts := hlc.Timestamp{WallTime: 1}
+	k1, k2 := roachpb.Key("a"), roachpb.Key("b")
+
+	eng := storage.NewDefaultInMemForTesting()
+	defer eng.Close()
+
+	for _, k := range []roachpb.Key{k1, k2} {
+		err := storage.MVCCPut(ctx, eng, nil, k, ts, hlc.ClockTimestamp{}, roachpb.MakeValueFromString("value"), nil)
+		require.NoError(t, err)
+	}
+
+	scan := func(txn *roachpb.Transaction, str lock.Strength, dur lock.Durability) (result.Result, error) {
+		cArgs := CommandArgs{
+			EvalCtx: (&MockEvalCtx{ClusterSettings: cluster.MakeTestingClusterSettings()}).EvalContext(),
+			Args: &roachpb.ScanRequest{
+				RequestHeader:        roachpb.RequestHeader{Key: k1, EndKey: k2.Next()},
+				KeyLocking:           str,
+				KeyLockingDurability: dur,
+			},
+			Header: roachpb.Header{Timestamp: txn.ReadTimestamp, Txn: txn},
+		}
+		return Scan(ctx, eng, cArgs, &roachpb.ScanResponse{})
+	}
+
+	txn1 := roachpb.MakeTransaction("txn1", k1, roachpb.NormalUserPriority, hlc.Timestamp{WallTime: 2}, 0, 1)
+	res, err := scan(&txn1, lock.Shared, lock.Replicated)
+	require.NoError(t, err)
+	require.Len(t, res.Local.AcquiredLocks, 2)
+	for i, k := range []roachpb.Key{k1, k2} {
+		acq := res.Local.AcquiredLocks[i]
+		require.Equal(t, k, acq.Key)
+		require.Equal(t, lock.Shared, acq.LockStrength())
+		require.Equal(t, lock.Replicated, acq.Durability)
+	}
+
+	// Another transaction can acquire Shared locks on the same keys, but not
+	// Exclusive ones.
+	txn2 := roachpb.MakeTransaction("txn2", k1, roachpb.NormalUserPriority, hlc.Timestamp{WallTime: 2}, 0, 1)
+	_, err = scan(&txn2, lock.Shared, lock.Unreplicated)
+	require.NoError(t, err)
+	_, err = scan(&txn2, lock.Shared, lock.Replicated)
+	require.NoError(t, err)
+	_, err = scan(&txn2, lock.Exclusive, lock.Unreplicated)
+	wiErr := &roachpb.WriteIntentError{}
+	require.True(t, errors.As(err, &wiErr), "unexpected error: %v", err)
+	require.Equal(t, txn1.ID, wiErr.Intents[0].Txn.ID)
+	require.Equal(t, lock.Shared, wiErr.Intents[0].LockStrength())
+}
+
 // makeRowKey makes a key for a SQL row for use in tests, using the system
 // tenant with table 1 index 1, and a single column.
 func makeRowKey(t *testing.T, id int, columnFamily uint32) roachpb.Key {
@@ -134,6 +134,10 @@ type EvalContext interface {
 
 	GetMaxBytes() int64
 
+	// CheckStorageQuota returns an error if writes to the range are to be
+	// rejected because its tenant or table has reached its storage quota.
+	CheckStorageQuota() error
+
 	// GetEngineCapacity returns the store's underlying engine capacity; other
 	// StoreCapacity fields not related to engine capacity are not populated.
 	GetEngineCapacity() (roachpb.StoreCapacity, error)
@@ -181,6 +185,7 @@ type MockEvalCtx struct {
 	RevokedLeaseSeq    roachpb.LeaseSequence
 	MaxBytes           int64
 	ApproxDiskBytes    uint64
+	StorageQuotaErr    error
 }
 
 // EvalContext returns the MockEvalCtx as an EvalContext. It will reflect future
@@ -264,7 +269,7 @@ func (m *mockEvalCtxImpl) ExcludeDataFromBackup() bool {
 	return false
 }
 func (m *mockEvalCtxImpl) GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp, error) {
-	panic("unimplemented")
+	return hlc.Timestamp{}, nil
 }
 func (m *mockEvalCtxImpl) GetLease() (roachpb.Lease, roachpb.Lease) {
 	return m.Lease, roachpb.Lease{}
@@ -307,6 +312,9 @@ func (m *mockEvalCtxImpl) GetMaxBytes() int64 {
 	}
 	return math.MaxInt64
 }
+func (m *mockEvalCtxImpl) CheckStorageQuota() error {
+	return m.StorageQuotaErr
+}
 func (m *mockEvalCtxImpl) GetEngineCapacity() (roachpb.StoreCapacity, error) {
 	return roachpb.StoreCapacity{Available: 1, Capacity: 1}, nil
 }
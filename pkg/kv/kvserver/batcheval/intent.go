@@ -103,11 +103,16 @@ func readProvisionalVal(
 
 }
 
-// acquireUnreplicatedLocksOnKeys adds an unreplicated lock acquisition by the
-// transaction to the provided result.Result for each key in the scan result.
-func acquireUnreplicatedLocksOnKeys(
+// acquireLocksOnKeys acquires a lock with the given strength and durability
+// for the transaction on each key in the scan result, and adds the lock
+// acquisitions to the provided result.Result.
+func acquireLocksOnKeys(
+	ctx context.Context,
+	readWriter storage.ReadWriter,
 	res *result.Result,
 	txn *roachpb.Transaction,
+	str lock.Strength,
+	dur lock.Durability,
 	scanFmt roachpb.ScanFormat,
 	scanRes *storage.MVCCScanResult,
 ) error {
@@ -116,13 +121,21 @@ func acquireUnreplicatedLocksOnKeys(
 	case roachpb.BATCH_RESPONSE:
 		var i int
 		return storage.MVCCScanDecodeKeyValues(scanRes.KVData, func(key storage.MVCCKey, _ []byte) error {
-			res.Local.AcquiredLocks[i] = roachpb.MakeLockAcquisition(txn, copyKey(key.Key), lock.Unreplicated)
+			acq, err := acquireLockOnKey(ctx, readWriter, txn, str, dur, copyKey(key.Key))
+			if err != nil {
+				return err
+			}
+			res.Local.AcquiredLocks[i] = acq
 			i++
 			return nil
 		})
 	case roachpb.KEY_VALUES:
 		for i, row := range scanRes.KVs {
-			res.Local.AcquiredLocks[i] = roachpb.MakeLockAcquisition(txn, copyKey(row.Key), lock.Unreplicated)
+			acq, err := acquireLockOnKey(ctx, readWriter, txn, str, dur, copyKey(row.Key))
+			if err != nil {
+				return err
+			}
+			res.Local.AcquiredLocks[i] = acq
 		}
 		return nil
 	default:
@@ -130,19 +143,47 @@ func acquireUnreplicatedLocksOnKeys(
 	}
 }
 
+// acquireLockOnKey acquires a lock with the given strength and durability for
+// the transaction on the key, and returns the lock acquisition. Unreplicated
+// locks are only tracked by the lock table, but acquiring one still fails with
+// a WriteIntentError if it conflicts with the replicated locks held by other
+// transactions. Replicated locks are written to the lock table keyspace.
+func acquireLockOnKey(
+	ctx context.Context,
+	readWriter storage.ReadWriter,
+	txn *roachpb.Transaction,
+	str lock.Strength,
+	dur lock.Durability,
+	key roachpb.Key,
+) (roachpb.LockAcquisition, error) {
+	switch dur {
+	case lock.Unreplicated:
+		if err := storage.MVCCCheckForAcquireLock(ctx, readWriter, txn, str, key); err != nil {
+			return roachpb.LockAcquisition{}, err
+		}
+	case lock.Replicated:
+		if err := storage.MVCCAcquireLock(ctx, readWriter, txn, str, key); err != nil {
+			return roachpb.LockAcquisition{}, err
+		}
+	default:
+		panic("unexpected lock durability")
+	}
+	return roachpb.MakeLockAcquisition(txn, key, str, dur), nil
+}
+
 // copyKey copies the provided roachpb.Key into a new byte slice, returning the
-// copy. It is used in acquireUnreplicatedLocksOnKeys for two reasons:
+// copy. It is used in acquireLocksOnKeys for two reasons:
 // 1. the keys in an MVCCScanResult, regardless of the scan format used, point
 //    to a small number of large, contiguous byte slices. These "MVCCScan
 //    batches" contain keys and their associated values in the same backing
 //    array. To avoid holding these entire backing arrays in memory and
 //    preventing them from being garbage collected indefinitely, we copy the key
-//    slices before coupling their lifetimes to those of unreplicated locks.
+//    slices before coupling their lifetimes to those of the locks.
 // 2. the KV API has a contract that byte slices returned from KV will not be
 //    mutated by higher levels. However, we have seen cases (e.g.#64228) where
 //    this contract is broken due to bugs. To defensively guard against this
-//    class of memory aliasing bug and prevent keys associated with unreplicated
-//    locks from being corrupted, we copy them.
+//    class of memory aliasing bug and prevent keys associated with locks from
+//    being corrupted, we copy them.
 func copyKey(k roachpb.Key) roachpb.Key {
 	k2 := make([]byte, len(k))
 	copy(k2, k)
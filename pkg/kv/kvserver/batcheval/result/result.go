@@ -250,6 +250,13 @@ func (p *Result) MergeAndDestroy(q Result) error {
 			q.Replicated.State.GCThreshold = nil
 		}
 
+		if p.Replicated.State.GCRetention == nil {
+			p.Replicated.State.GCRetention = q.Replicated.State.GCRetention
+		} else if q.Replicated.State.GCRetention != nil {
+			return errors.AssertionFailedf("conflicting GCRetention")
+		}
+		q.Replicated.State.GCRetention = nil
+
 		if p.Replicated.State.Version == nil {
 			p.Replicated.State.Version = q.Replicated.State.Version
 		} else if q.Replicated.State.Version != nil {
@@ -278,6 +278,11 @@ type TransactionManager interface {
 	// transaction either directly or indirectly. The method is used to perform
 	// deadlock detection. See txnWaitQueue for more.
 	GetDependents(uuid.UUID) []uuid.UUID
+
+	// QueryPushWaiters returns the PushTxn requests waiting on transactions
+	// whose record is stored on the manager's range. The method is used to
+	// observe the waits-for graph. See txnWaitQueue for more.
+	QueryPushWaiters() []txnwait.PushWaiter
 }
 
 // RangeStateListener is concerned with observing updates to the concurrency
@@ -433,6 +438,13 @@ type Request struct {
 	// passed to SequenceReq. Only supplied to SequenceReq if the method is
 	// not also passed an exiting Guard.
 	LockSpans *spanset.SpanSet
+
+	// The strength of the locks that the request intends to acquire on the
+	// keys in the SpanReadWrite LockSpans. Locking reads may acquire Shared or
+	// Upgrade locks, which are compatible with some other locks, while writes
+	// acquire Exclusive locks. A batch that mixes the two waits as if it were
+	// acquiring the strongest of them. If unset, Exclusive is assumed.
+	LockStrength lock.Strength
 }
 
 // Guard is returned from Manager.SequenceReq. The guard is passed back in to
@@ -951,6 +963,10 @@ type txnWaitQueue interface {
 	// deadlock detection.
 	GetDependents(uuid.UUID) []uuid.UUID
 
+	// QueryPushWaiters returns the PushTxn requests currently waiting in the
+	// queue. The method is used to observe the waits-for graph.
+	QueryPushWaiters() []txnwait.PushWaiter
+
 	// MaybeWaitForPush checks whether there is a queue already established for
 	// transaction being pushed by the provided request. If not, or if the
 	// PushTxn request isn't queueable, the method returns immediately. If there
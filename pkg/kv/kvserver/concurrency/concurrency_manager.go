@@ -15,7 +15,6 @@ import (
 	"sync"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
-	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanlatch"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/txnwait"
@@ -495,7 +494,7 @@ func (m *managerImpl) HandleTransactionPushError(
 
 // OnLockAcquired implements the LockManager interface.
 func (m *managerImpl) OnLockAcquired(ctx context.Context, acq *roachpb.LockAcquisition) {
-	if err := m.lt.AcquireLock(&acq.Txn, acq.Key, lock.Exclusive, acq.Durability); err != nil {
+	if err := m.lt.AcquireLock(&acq.Txn, acq.Key, acq.LockStrength(), acq.Durability); err != nil {
 		log.Fatalf(ctx, "%v", err)
 	}
 }
@@ -524,6 +523,11 @@ func (m *managerImpl) GetDependents(txnID uuid.UUID) []uuid.UUID {
 	return m.twq.GetDependents(txnID)
 }
 
+// QueryPushWaiters implements the TransactionManager interface.
+func (m *managerImpl) QueryPushWaiters() []txnwait.PushWaiter {
+	return m.twq.QueryPushWaiters()
+}
+
 // OnRangeDescUpdated implements the RangeStateListener interface.
 func (m *managerImpl) OnRangeDescUpdated(desc *roachpb.RangeDescriptor) {
 	m.twq.OnRangeDescUpdated(desc)
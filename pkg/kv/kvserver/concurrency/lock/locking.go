@@ -17,6 +17,18 @@ import fmt "fmt"
 // MaxDurability is the maximum value in the Durability enum.
 const MaxDurability = Unreplicated
 
+// Conflicts returns whether two lock strengths conflict with each other when
+// held or desired by different transactions, as described by the compatibility
+// matrix on Strength. A non-locking read (None) is reported to conflict with an
+// Exclusive lock, though whether it actually does also depends on the timestamp
+// of the read relative to that of the lock.
+func Conflicts(s1, s2 Strength) bool {
+	if s1 == None || s2 == None {
+		return s1 == Exclusive || s2 == Exclusive
+	}
+	return s1 != Shared || s2 != Shared
+}
+
 func init() {
 	for v := range Durability_name {
 		if d := Durability(v); d > MaxDurability {
@@ -0,0 +1,36 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package lock_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConflicts checks Conflicts against the compatibility matrix documented
+// on lock.Strength.
+func TestConflicts(t *testing.T) {
+	strengths := []lock.Strength{lock.None, lock.Shared, lock.Upgrade, lock.Exclusive}
+	// conflicts[i][j] is whether strengths[i] conflicts with strengths[j].
+	conflicts := [][]bool{
+		{false, false, false, true},
+		{false, false, true, true},
+		{false, true, true, true},
+		{true, true, true, true},
+	}
+	for i, s1 := range strengths {
+		for j, s2 := range strengths {
+			require.Equal(t, conflicts[i][j], lock.Conflicts(s1, s2), "%s vs %s", s1, s2)
+		}
+	}
+}
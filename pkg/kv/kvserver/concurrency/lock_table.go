@@ -365,6 +365,10 @@ type lockTableGuardImpl struct {
 	ts                 hlc.Timestamp
 	spans              *spanset.SpanSet
 	maxWaitQueueLength int
+	// The strength of the locks that the request acquires on the keys that it
+	// accesses with SpanReadWrite. Keys accessed with SpanReadOnly are read
+	// without locking.
+	str lock.Strength
 
 	// Snapshots of the trees for which this request has some spans. Note that
 	// the lockStates in these snapshots may have been removed from
@@ -593,10 +597,28 @@ func (g *lockTableGuardImpl) isSameTxn(txn *enginepb.TxnMeta) bool {
 	return g.txn != nil && g.txn.ID == txn.ID
 }
 
+// Returns the ID of the request's transaction, or the nil UUID if the request
+// is non-transactional.
+func (g *lockTableGuardImpl) txnID() uuid.UUID {
+	if g.txn == nil {
+		return uuid.Nil
+	}
+	return g.txn.ID
+}
+
 func (g *lockTableGuardImpl) isSameTxnAsReservation(ws waitingState) bool {
 	return !ws.held && g.isSameTxn(ws.txn)
 }
 
+// Returns the lock strength with which the request accesses keys that it
+// specifies with access sa.
+func (g *lockTableGuardImpl) strengthForAccess(sa spanset.SpanAccess) lock.Strength {
+	if sa == spanset.SpanReadOnly {
+		return lock.None
+	}
+	return g.str
+}
+
 // Finds the next lock, after the current one, to actively wait at. If it
 // finds the next lock the request starts actively waiting there, else it is
 // told that it is done waiting. lockTableImpl.finalizedTxnCache is used to
@@ -740,6 +762,38 @@ func (lh *lockHolderInfo) isEmpty() bool {
 	return lh.txn == nil && lh.seqs == nil && lh.ts.IsEmpty()
 }
 
+// Information about a transaction holding a lock. We track information for
+// each durability level separately since a transaction can go through multiple
+// epochs and TxnSeq and may acquire the same lock in replicated and
+// unreplicated mode at different stages.
+type txnLockHolder [lock.MaxDurability + 1]lockHolderInfo
+
+// Returns the transaction holding the lock and the timestamp at which it is
+// held, or nil if the holder is empty.
+func (h *txnLockHolder) getTxnAndTS() (*enginepb.TxnMeta, hlc.Timestamp) {
+	// If the lock is held as both replicated and unreplicated we want to
+	// provide the lower of the two timestamps, since the lower timestamp
+	// contends with more transactions. Else we provide whichever one it is held
+	// at.
+
+	// Start with the assumption that it is held as replicated.
+	index := lock.Replicated
+	// Condition under which we prefer the unreplicated holder.
+	if h[index].txn == nil || (h[lock.Unreplicated].txn != nil &&
+		// If we are evaluating the following clause we are sure that it is held
+		// as both replicated and unreplicated.
+		h[lock.Unreplicated].ts.Less(h[lock.Replicated].ts)) {
+		index = lock.Unreplicated
+	}
+	return h[index].txn, h[index].ts
+}
+
+// Returns true iff the holder is the transaction with the given id.
+func (h *txnLockHolder) isHeldBy(id uuid.UUID) bool {
+	txn, _ := h.getTxnAndTS()
+	return txn != nil && txn.ID == id
+}
+
 // Per lock state in lockTableImpl.
 //
 // NOTE: we can't easily pool lockState objects without some form of reference
@@ -760,20 +814,28 @@ type lockState struct {
 
 	// Invariant summary (see detailed comments below):
 	// - both holder.locked and waitQ.reservation != nil cannot be true.
-	// - if holder.locked and multiple holderInfos have txn != nil: all the
-	//   txns must have the same txn.ID.
+	// - if holder.locked and multiple holderInfos of a txnLockHolder have
+	//   txn != nil: all the txns must have the same txn.ID.
+	// - len(holder.shared) > 0 => holder.strength == lock.Shared, and all the
+	//   holders hold a Shared lock.
 	// - !holder.locked => waitingReaders.Len() == 0. That is, readers wait
 	//   only if the lock is held. They do not wait for a reservation.
 	// - If reservation != nil, that request is not in queuedWriters.
 
-	// Information about whether the lock is held and the holder. We track
-	// information for each durability level separately since a transaction can
-	// go through multiple epochs and TxnSeq and may acquire the same lock in
-	// replicated and unreplicated mode at different stages.
+	// Information about whether the lock is held and the holders.
 	holder struct {
 		locked bool
-		// LockStrength is always Exclusive
-		holder [lock.MaxDurability + 1]lockHolderInfo
+		// The strength with which the lock is held by the first holder. Only
+		// Shared locks are compatible with each other, so the lock is either
+		// held by a single transaction with any strength or by multiple
+		// transactions with Shared strength.
+		strength lock.Strength
+		// The transaction holding the lock. If multiple transactions hold the
+		// lock, this is the first of them.
+		holder txnLockHolder
+		// The other transactions holding the lock, in the order in which they
+		// acquired it. They all hold a Shared lock.
+		shared []*txnLockHolder
 
 		// The start time of the lockholder being marked as held in the lock table.
 		// NB: In the case of a replicated lock that is held by a transaction, if
@@ -803,9 +865,8 @@ type lockWaitQueue struct {
 	// prevents multiple requests from racing when the lock is released. A
 	// reservation by req2 can be broken by req1 is req1 has a smaller seqNum
 	// than req2. Only requests that specify SpanReadWrite for a key can make
-	// reservations. This means a reservation can only be made when the lock is
-	// not held, since the reservation (which can acquire an Exclusive lock) and
-	// the lock holder (which is an Exclusive lock) conflict.
+	// reservations, and a reservation can only be made when the lock is not
+	// held.
 	//
 	// Read reservations are not permitted due to the complexities discussed in
 	// the review for #43740. Additionally, reads do not queue for their turn at
@@ -826,8 +887,7 @@ type lockWaitQueue struct {
 	// seqnums but at another key req2 wants to read and req1 wants to write and
 	// since req2 does not wait in the queue it acquires a read reservation
 	// before req1. See the discussion at the end of this comment section on how
-	// the behavior will extend when we start supporting Shared and Upgrade
-	// locks.
+	// the behavior extends to Shared and Upgrade locks.
 	//
 	// Non-transactional requests can do both reads and writes but cannot be
 	// depended on since they don't have a transaction that can be pushed.
@@ -889,7 +949,7 @@ type lockWaitQueue struct {
 	//   This is a deadlock caused by the lock table unless req2 partially
 	//   breaks the reservation at A.
 	//
-	// Extension for Shared and Upgrade locks:
+	// Shared and Upgrade locks:
 	// There are 3 aspects to consider: holders; reservers; the dependencies
 	// that need to be captured when waiting.
 	//
@@ -898,35 +958,29 @@ type lockWaitQueue struct {
 	//   exclusive holder, (d) one upgrade holder. Non-locking reads will
 	//   wait in waitingReaders for only an incompatible exclusive holder.
 	//
-	// - Reservers: This follows the same pattern as holders. Non-locking reads
-	//   do not wait on reservers.
+	// - Reservers: reservations are not joint, so a request desiring a Shared
+	//   lock makes, breaks and waits for reservations like any other locking
+	//   request. Once the reserver acquires a Shared lock, the requests
+	//   desiring Shared locks that are queued directly behind it no longer
+	//   need to wait. Non-locking reads do not wait on reservers.
 	//
 	// - Queueing and dependencies: All potential lockers and non-transactional
-	//   writers will wait in the same queue. A sequence of consecutive requests
-	//   that have the potential to acquire a shared lock will jointly reserve
-	//   that shared lock. Such requests cannot jump ahead of requests with a
-	//   lower seqnum just because there is currently a shared lock reservation
-	//   (this can cause lockTable induced deadlocks). Such joint reservations
-	//   can be partially broken by a waiter desiring an exclusive or upgrade
-	//   lock. Like the current code, non-transactional writes will wait for
-	//   reservations that have a lower sequence num, but not make their own
-	//   reservation. Additionally, they can partially break joint reservations.
-	//
-	//   Reservations that are (partially or fully) broken cause requests to
-	//   reenter the queue as inactive waiters. This is no different than the
-	//   current behavior. Each request can specify the same key in spans for
-	//   ReadOnly, ReadShared, ReadUpgrade, ReadWrite. The spans will be
-	//   iterated over in decreasing order of strength, to only wait at a lock
-	//   at the highest strength (this is similar to the current behavior using
-	//   accessDecreasingStrength).
+	//   writers wait in the same queue. A request desiring a Shared lock that
+	//   is compatible with the holders does not jump ahead of a queued request
+	//   from another transaction with a lower seqnum, since doing so could
+	//   starve requests desiring stronger locks. It enters the queue instead,
+	//   and is released once the requests ahead of it are gone. A request from
+	//   a transaction that holds the lock never waits behind other requests,
+	//   so a transaction that holds a Shared lock and desires a stronger one
+	//   only waits for the other holders to release their locks.
 	//
-	//   For dependencies, a waiter desiring an exclusive or upgrade lock always
-	//   conflicts with the holder(s) or reserver(s) so that is the dependency
-	//   that will be captured. A waiter desiring a shared lock may encounter a
-	//   situation where it does not conflict with the holder(s) or reserver(s)
-	//   since those are also shared lockers. In that case it will depend on the
-	//   first waiter since that waiter must be desiring a lock that is
-	//   incompatible with a shared lock.
+	//   A waiter that conflicts with a holder depends on, and pushes, that
+	//   holder. A waiter desiring a Shared lock that is only queued behind a
+	//   conflicting waiter depends on the first holder, which transitively
+	//   blocks it. When the lock is held by multiple transactions, a waiter
+	//   depends on them one at a time as they release the lock, so deadlocks
+	//   involving any of them, e.g. two Shared lock holders that both attempt
+	//   to acquire an Exclusive lock, are detected by txnwait.
 
 	reservation *lockTableGuardImpl
 
@@ -1004,11 +1058,16 @@ func (l *lockState) safeFormat(sb *redact.StringBuilder, finalizedTxnCache *txnC
 		sb.Printf("txn: %v, ts: %v, seq: %v\n",
 			redact.Safe(txn.ID), redact.Safe(ts), redact.Safe(txn.Sequence))
 	}
-	writeHolderInfo := func(sb *redact.StringBuilder, txn *enginepb.TxnMeta, ts hlc.Timestamp) {
-		sb.Printf("  holder: txn: %v, ts: %v, info: ", redact.Safe(txn.ID), redact.Safe(ts))
+	writeHolderInfo := func(sb *redact.StringBuilder, holder *txnLockHolder) {
+		txn, ts := holder.getTxnAndTS()
+		sb.Printf("  holder: txn: %v, ts: %v, ", redact.Safe(txn.ID), redact.Safe(ts))
+		if str := l.holderStrength(holder); str == lock.Shared || str == lock.Upgrade {
+			sb.Printf("str: %v, ", redact.Safe(str))
+		}
+		sb.SafeString("info: ")
 		first := true
-		for i := range l.holder.holder {
-			h := &l.holder.holder[i]
+		for i := range holder {
+			h := &holder[i]
 			if h.txn == nil {
 				continue
 			}
@@ -1042,12 +1101,14 @@ func (l *lockState) safeFormat(sb *redact.StringBuilder, finalizedTxnCache *txnC
 		}
 		sb.SafeString("\n")
 	}
-	txn, ts := l.getLockHolder()
-	if txn == nil {
+	if !l.holder.locked {
 		sb.Printf("  res: req: %d, ", l.reservation.seqNum)
 		writeResInfo(sb, l.reservation.txn, l.reservation.ts)
 	} else {
-		writeHolderInfo(sb, txn, ts)
+		writeHolderInfo(sb, &l.holder.holder)
+		for _, h := range l.holder.shared {
+			writeHolderInfo(sb, h)
+		}
 	}
 	// TODO(sumeer): Add an optional `description string` field to Request and
 	// lockTableGuardImpl that tests can set to avoid relying on the seqNum to
@@ -1072,6 +1133,8 @@ func (l *lockState) safeFormat(sb *redact.StringBuilder, finalizedTxnCache *txnC
 			sb.Printf("    active: %t req: %d, txn: ", redact.Safe(qg.active), redact.Safe(qg.guard.seqNum))
 			if g.txn == nil {
 				sb.SafeString("none\n")
+			} else if g.str == lock.Shared || g.str == lock.Upgrade {
+				sb.Printf("%v, str: %v\n", redact.Safe(g.txn.ID), redact.Safe(g.str))
 			} else {
 				sb.Printf("%v\n", redact.Safe(g.txn.ID))
 			}
@@ -1134,7 +1197,7 @@ func (l *lockState) lockStateInfo(now time.Time) roachpb.LockStateInfo {
 		lockWaiters = append(lockWaiters, lock.Waiter{
 			WaitingTxn:   l.reservation.txn,
 			ActiveWaiter: true,
-			Strength:     lock.Exclusive,
+			Strength:     l.reservation.str,
 			WaitDuration: now.Sub(l.reservation.mu.curLockWaitStart),
 		})
 		l.reservation.mu.Unlock()
@@ -1161,7 +1224,7 @@ func (l *lockState) lockStateInfo(now time.Time) roachpb.LockStateInfo {
 		lockWaiters = append(lockWaiters, lock.Waiter{
 			WaitingTxn:   writerGuard.txn,
 			ActiveWaiter: qg.active,
-			Strength:     lock.Exclusive,
+			Strength:     writerGuard.str,
 			WaitDuration: now.Sub(writerGuard.mu.curLockWaitStart),
 		})
 		writerGuard.mu.Unlock()
@@ -1262,6 +1325,9 @@ func (l *lockState) informActiveWaiters() {
 		}
 		g := qg.guard
 		state := waitForState
+		if state.held {
+			state.txn = l.waitForHolder(g)
+		}
 		if g.isSameTxnAsReservation(state) {
 			state.kind = waitSelf
 		} else {
@@ -1343,6 +1409,9 @@ func (l *lockState) isEmptyLock() bool {
 				panic("lockState with !locked but non-zero lockHolderInfo")
 			}
 		}
+		if len(l.holder.shared) > 0 {
+			panic("lockState with !locked but shared lock holders")
+		}
 		if l.waitingReaders.Len() > 0 || l.queuedWriters.Len() > 0 {
 			panic("lockState with waiters but no holder or reservation")
 		}
@@ -1395,51 +1464,231 @@ func (l *lockState) totalAndMaxWaitDuration(now time.Time) (time.Duration, time.
 // given id.
 // REQUIRES: l.mu is locked.
 func (l *lockState) isLockedBy(id uuid.UUID) bool {
-	if l.holder.locked {
-		var holderID uuid.UUID
-		if l.holder.holder[lock.Unreplicated].txn != nil {
-			holderID = l.holder.holder[lock.Unreplicated].txn.ID
-		} else {
-			holderID = l.holder.holder[lock.Replicated].txn.ID
+	return l.findHolder(id) != nil
+}
+
+// Returns the holder of the lock that is the transaction with the given id, or
+// nil if the lock is not held by that transaction.
+// REQUIRES: l.mu is locked.
+func (l *lockState) findHolder(id uuid.UUID) *txnLockHolder {
+	if !l.holder.locked {
+		return nil
+	}
+	if l.holder.holder.isHeldBy(id) {
+		return &l.holder.holder
+	}
+	for _, h := range l.holder.shared {
+		if h.isHeldBy(id) {
+			return h
+		}
+	}
+	return nil
+}
+
+// Returns whether h is one of the holders of the lock.
+// REQUIRES: l.mu is locked.
+func (l *lockState) isHolder(h *txnLockHolder) bool {
+	if !l.holder.locked {
+		return false
+	}
+	if h == &l.holder.holder {
+		return true
+	}
+	for _, sh := range l.holder.shared {
+		if sh == h {
+			return true
 		}
-		return id == holderID
 	}
 	return false
 }
 
+// Returns the strength with which the holder h holds the lock.
+// REQUIRES: l.mu is locked and h is a holder of the lock.
+func (l *lockState) holderStrength(h *txnLockHolder) lock.Strength {
+	if h == &l.holder.holder {
+		return l.holder.strength
+	}
+	return lock.Shared
+}
+
+// Merges a lock of strength str held by h into the lock's holders. h is either
+// one of the holders, as returned by findHolder, or a new holder from another
+// transaction. A transaction that already holds the lock keeps the stronger of
+// the two strengths. A lock can only be held by multiple transactions if they
+// all hold it with Shared strength, so an error is returned, and the holders
+// are left unchanged, if the merge would violate this.
+// REQUIRES: l.mu is locked and the lock is held.
+func (l *lockState) mergeHolder(h *txnLockHolder, str lock.Strength) error {
+	if !l.isHolder(h) {
+		if str != lock.Shared || l.holder.strength != lock.Shared {
+			return errors.AssertionFailedf("existing lock cannot be acquired by different transaction")
+		}
+		l.holder.shared = append(l.holder.shared, h)
+		return nil
+	}
+	if str <= l.holderStrength(h) {
+		return nil
+	}
+	// The transaction that holds the lock wants a stronger one, which requires
+	// it to be the only holder.
+	if len(l.holder.shared) > 0 {
+		return errors.AssertionFailedf(
+			"lock held with %s strength by multiple transactions cannot be acquired with %s strength",
+			l.holder.strength, str)
+	}
+	l.holder.strength = str
+	return nil
+}
+
 // Returns information about the current lock holder if the lock is held, else
-// returns nil.
+// returns nil. If the lock is held by multiple transactions, the first of them
+// is returned.
 // REQUIRES: l.mu is locked.
 func (l *lockState) getLockHolder() (*enginepb.TxnMeta, hlc.Timestamp) {
 	if !l.holder.locked {
 		return nil, hlc.Timestamp{}
 	}
+	return l.holder.holder.getTxnAndTS()
+}
 
-	// If the lock is held as both replicated and unreplicated we want to
-	// provide the lower of the two timestamps, since the lower timestamp
-	// contends with more transactions. Else we provide whichever one it is held
-	// at.
+// Returns a holder of the lock from a transaction other than that of request g
+// whose lock conflicts with a lock of strength str, or nil if there is no such
+// holder. Timestamps are not considered, so a non-locking read (str is
+// lock.None) may not actually conflict with the returned holder.
+// REQUIRES: l.mu is locked.
+func (l *lockState) conflictingHolder(g *lockTableGuardImpl, str lock.Strength) *txnLockHolder {
+	if !l.holder.locked {
+		return nil
+	}
+	if lock.Conflicts(l.holder.strength, str) && !l.holder.holder.isHeldBy(g.txnID()) {
+		return &l.holder.holder
+	}
+	if !lock.Conflicts(lock.Shared, str) {
+		return nil
+	}
+	for _, h := range l.holder.shared {
+		if !h.isHeldBy(g.txnID()) {
+			return h
+		}
+	}
+	return nil
+}
 
-	// Start with the assumption that it is held as replicated.
-	index := lock.Replicated
-	// Condition under which we prefer the unreplicated holder.
-	if l.holder.holder[index].txn == nil || (l.holder.holder[lock.Unreplicated].txn != nil &&
-		// If we are evaluating the following clause we are sure that it is held
-		// as both replicated and unreplicated.
-		l.holder.holder[lock.Unreplicated].ts.Less(l.holder.holder[lock.Replicated].ts)) {
-		index = lock.Unreplicated
+// Returns the transaction that the request g, which is queued at this held
+// lock, is waiting for: a holder whose lock conflicts with the lock desired by
+// g or, if g is only queued behind a conflicting waiter, the first holder from
+// another transaction.
+// REQUIRES: l.mu is locked and the lock is held.
+func (l *lockState) waitForHolder(g *lockTableGuardImpl) *enginepb.TxnMeta {
+	h := l.conflictingHolder(g, g.str)
+	if h == nil {
+		h = &l.holder.holder
+		if h.isHeldBy(g.txnID()) && len(l.holder.shared) > 0 {
+			h = l.holder.shared[0]
+		}
+	}
+	txn, _ := h.getTxnAndTS()
+	return txn
+}
+
+// Returns whether a request from a transaction other than that of g, with a
+// lower seqNum than g, is queued at the lock.
+// REQUIRES: l.mu is locked.
+func (l *lockState) hasEarlierWaiterFromOtherTxn(g *lockTableGuardImpl) bool {
+	for e := l.queuedWriters.Front(); e != nil; e = e.Next() {
+		qg := e.Value.(*queuedGuard)
+		if qg.guard.seqNum < g.seqNum && (qg.guard.txn == nil || !g.isSameTxn(qg.guard.txn)) {
+			return true
+		}
 	}
-	return l.holder.holder[index].txn, l.holder.holder[index].ts
+	return false
 }
 
-// Removes the current lock holder from the lock.
+// Removes the current lock holders from the lock.
 // REQUIRES: l.mu is locked.
 func (l *lockState) clearLockHolder() {
 	l.holder.locked = false
+	l.holder.strength = lock.None
 	l.holder.startTime = time.Time{}
 	for i := range l.holder.holder {
 		l.holder.holder[i] = lockHolderInfo{}
 	}
+	l.holder.shared = nil
+}
+
+// Removes the holder h from the lock's holders. Returns whether the lock is
+// still held by other transactions.
+// REQUIRES: l.mu is locked and h is a holder of the lock.
+func (l *lockState) removeHolder(h *txnLockHolder) (stillHeld bool) {
+	if h == &l.holder.holder {
+		if len(l.holder.shared) == 0 {
+			l.clearLockHolder()
+			return false
+		}
+		l.holder.holder = *l.holder.shared[0]
+		l.holder.shared = l.holder.shared[1:]
+		l.holder.strength = lock.Shared
+	} else {
+		for i := range l.holder.shared {
+			if l.holder.shared[i] == h {
+				l.holder.shared = append(l.holder.shared[:i], l.holder.shared[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(l.holder.shared) == 0 {
+		l.holder.shared = nil
+	}
+	return true
+}
+
+// The holder h no longer holds the lock. Returns whether the lockState can be
+// garbage collected.
+// REQUIRES: l.mu is locked and h is a holder of the lock.
+func (l *lockState) releaseHolder(h *txnLockHolder) (gc bool) {
+	if !l.removeHolder(h) {
+		return l.lockIsFree()
+	}
+	// Other transactions still hold the lock. Waiters that were only blocked
+	// by h no longer need to wait, and the others may now be waiting for a
+	// different holder.
+	l.releaseCompatibleWaiters()
+	l.informActiveWaiters()
+	return false
+}
+
+// Releases the queued requests that no longer need to wait at this held lock
+// since they do not conflict with any of the holders. This happens when a
+// request desiring a Shared lock was queued behind a conflicting waiter that
+// has since left the queue, or when a transaction that holds the lock desires
+// a stronger one and the other holders have released their locks. Requests
+// from transactions that hold the lock are released regardless of their
+// position in the queue, while others are only released once the requests
+// ahead of them are.
+// REQUIRES: l.mu is locked and the lock is held.
+func (l *lockState) releaseCompatibleWaiters() {
+	released := true
+	for e := l.queuedWriters.Front(); e != nil; {
+		qg := e.Value.(*queuedGuard)
+		curr := e
+		e = e.Next()
+		g := qg.guard
+		if l.conflictingHolder(g, g.str) != nil || !(released || l.isLockedBy(g.txnID())) {
+			released = false
+			continue
+		}
+		l.queuedWriters.Remove(curr)
+		if qg.active {
+			if g == l.distinguishedWaiter {
+				l.distinguishedWaiter = nil
+			}
+			g.doneWaitingAtLock(false, l)
+		} else {
+			g.mu.Lock()
+			delete(g.mu.locks, l)
+			g.mu.Unlock()
+		}
+	}
 }
 
 // Decides whether the request g with access sa should actively wait at this
@@ -1511,31 +1760,50 @@ func (l *lockState) tryActiveWait(
 		return false, false
 	}
 
-	// Lock is not empty.
-	lockHolderTxn, lockHolderTS := l.getLockHolder()
-	if lockHolderTxn != nil && g.isSameTxn(lockHolderTxn) {
-		// Already locked by this txn.
-		return false, false
-	}
-
+	// Lock is not empty. Look for a holder from another transaction whose lock
+	// conflicts with the request's access.
+	var lockHolder *txnLockHolder
 	var replicatedLockFinalizedTxn *roachpb.Transaction
-	if lockHolderTxn != nil {
-		finalizedTxn, ok := g.lt.finalizedTxnCache.get(lockHolderTxn.ID)
-		if ok {
-			if l.holder.holder[lock.Replicated].txn == nil {
-				// Only held unreplicated. Release immediately.
-				l.clearLockHolder()
-				if l.lockIsFree() {
-					// Empty lock.
-					return false, true
-				}
-				lockHolderTxn = nil
-				// There is a reservation holder, which may be the caller itself,
-				// so fall through to the processing below.
-			} else {
-				replicatedLockFinalizedTxn = finalizedTxn
-			}
+	for l.holder.locked {
+		lockHolder = l.conflictingHolder(g, g.strengthForAccess(sa))
+		if lockHolder == nil {
+			break
+		}
+		holderTxn, _ := lockHolder.getTxnAndTS()
+		finalizedTxn, ok := g.lt.finalizedTxnCache.get(holderTxn.ID)
+		if !ok {
+			break
+		}
+		if lockHolder[lock.Replicated].txn != nil {
+			replicatedLockFinalizedTxn = finalizedTxn
+			break
 		}
+		// Only held unreplicated. Release immediately.
+		h := lockHolder
+		lockHolder = nil
+		if l.releaseHolder(h) {
+			// Empty lock.
+			return false, true
+		}
+		// Either the lock is still held by other transactions, which may also
+		// conflict with the request, or there is a reservation holder, which may
+		// be the caller itself, so fall through to the processing below.
+	}
+
+	var lockHolderTxn *enginepb.TxnMeta
+	var lockHolderTS hlc.Timestamp
+	if lockHolder != nil {
+		lockHolderTxn, lockHolderTS = lockHolder.getTxnAndTS()
+	} else if l.holder.locked {
+		// The request does not conflict with any of the holders. Non-locking
+		// reads and requests from a transaction that already holds the lock
+		// proceed. A request desiring a Shared lock also proceeds, unless a
+		// request from another transaction with a lower seqNum is queued ahead of
+		// it, in which case it waits in the queue for its turn.
+		if sa == spanset.SpanReadOnly || l.isLockedBy(g.txnID()) || !l.hasEarlierWaiterFromOtherTxn(g) {
+			return false, false
+		}
+		lockHolderTxn, lockHolderTS = l.getLockHolder()
 	}
 
 	if sa == spanset.SpanReadOnly {
@@ -1726,8 +1994,7 @@ func (l *lockState) isNonConflictingLock(g *lockTableGuardImpl, sa spanset.SpanA
 		return true
 	}
 	// Lock is not empty.
-	lockHolderTxn, lockHolderTS := l.getLockHolder()
-	if lockHolderTxn == nil {
+	if !l.holder.locked {
 		// Reservation holders are non-conflicting.
 		//
 		// When optimistic evaluation holds latches, there cannot be a conflicting
@@ -1741,15 +2008,16 @@ func (l *lockState) isNonConflictingLock(g *lockTableGuardImpl, sa spanset.SpanA
 		// will retry as pessimistic.
 		return true
 	}
-	if g.isSameTxn(lockHolderTxn) {
-		// Already locked by this txn.
+	lockHolder := l.conflictingHolder(g, g.strengthForAccess(sa))
+	if lockHolder == nil {
+		// Already locked by this txn, or compatible with the holders.
 		return true
 	}
 	// NB: We do not look at the finalizedTxnCache in this optimistic evaluation
 	// path. A conflict with a finalized txn will be noticed when retrying
 	// pessimistically.
 
-	if sa == spanset.SpanReadOnly && g.ts.Less(lockHolderTS) {
+	if _, lockHolderTS := lockHolder.getTxnAndTS(); sa == spanset.SpanReadOnly && g.ts.Less(lockHolderTS) {
 		return true
 	}
 	// Conflicts.
@@ -1761,7 +2029,7 @@ func (l *lockState) isNonConflictingLock(g *lockTableGuardImpl, sa spanset.SpanA
 // that is acquiring the lock.
 // Acquires l.mu.
 func (l *lockState) acquireLock(
-	_ lock.Strength,
+	str lock.Strength,
 	durability lock.Durability,
 	txn *enginepb.TxnMeta,
 	ts hlc.Timestamp,
@@ -1771,12 +2039,31 @@ func (l *lockState) acquireLock(
 	defer l.mu.Unlock()
 	if l.holder.locked {
 		// Already held.
-		beforeTxn, beforeTs := l.getLockHolder()
-		if txn.ID != beforeTxn.ID {
-			return errors.AssertionFailedf("existing lock cannot be acquired by different transaction")
+		holder := l.findHolder(txn.ID)
+		if holder == nil {
+			holder = &txnLockHolder{}
+			holder[durability].txn = txn
+			holder[durability].ts = ts
+			holder[durability].seqs = append([]enginepb.TxnSeq(nil), txn.Sequence)
+			// Only Shared locks can be held by multiple transactions.
+			if err := l.mergeHolder(holder, str); err != nil {
+				return err
+			}
+
+			// If there are waiting requests from the same txn, they no longer need
+			// to wait, and neither do the requests desiring Shared locks that were
+			// queued behind them.
+			l.releaseWritersFromTxn(txn)
+			l.releaseCompatibleWaiters()
+			l.informActiveWaiters()
+			return nil
+		}
+		if err := l.mergeHolder(holder, str); err != nil {
+			return err
 		}
-		seqs := l.holder.holder[durability].seqs
-		if l.holder.holder[durability].txn != nil && l.holder.holder[durability].txn.Epoch < txn.Epoch {
+		_, beforeTs := l.getLockHolder()
+		seqs := holder[durability].seqs
+		if holder[durability].txn != nil && holder[durability].txn.Epoch < txn.Epoch {
 			// Clear the sequences for the older epoch.
 			seqs = seqs[:0]
 		}
@@ -1794,11 +2081,11 @@ func (l *lockState) acquireLock(
 				seqs = append(seqs, 0)
 				copy(seqs[i+1:], seqs[i:])
 				seqs[i] = txn.Sequence
-				l.holder.holder[durability].seqs = seqs
+				holder[durability].seqs = seqs
 			}
 			return nil
 		}
-		l.holder.holder[durability].txn = txn
+		holder[durability].txn = txn
 		// Forward the lock's timestamp instead of assigning to it blindly.
 		// While lock acquisition uses monotonically increasing timestamps
 		// from the perspective of the transaction's coordinator, this does
@@ -1837,8 +2124,8 @@ func (l *lockState) acquireLock(
 		// timestamp at that point, which may cause them to conflict with the
 		// lock even if they had not conflicted before. In a sense, it is no
 		// different than the first time a lock is added to the lockTable.
-		l.holder.holder[durability].ts.Forward(ts)
-		l.holder.holder[durability].seqs = append(seqs, txn.Sequence)
+		holder[durability].ts.Forward(ts)
+		holder[durability].seqs = append(seqs, txn.Sequence)
 
 		_, afterTs := l.getLockHolder()
 		if beforeTs.Less(afterTs) {
@@ -1879,6 +2166,7 @@ func (l *lockState) acquireLock(
 	}
 	l.reservation = nil
 	l.holder.locked = true
+	l.holder.strength = str
 	l.holder.holder[durability].txn = txn
 	l.holder.holder[durability].ts = ts
 	l.holder.holder[durability].seqs = append([]enginepb.TxnSeq(nil), txn.Sequence)
@@ -1886,17 +2174,23 @@ func (l *lockState) acquireLock(
 
 	// If there are waiting requests from the same txn, they no longer need to wait.
 	l.releaseWritersFromTxn(txn)
+	if str == lock.Shared {
+		// Neither do the requests desiring Shared locks that were queued directly
+		// behind this one.
+		l.releaseCompatibleWaiters()
+	}
 
 	// Inform active waiters since lock has transitioned to held.
 	l.informActiveWaiters()
 	return nil
 }
 
-// A replicated lock held by txn with timestamp ts was discovered by guard g
-// where g is trying to access this key with access sa.
+// A replicated lock of strength str held by txn with timestamp ts was
+// discovered by guard g where g is trying to access this key with access sa.
 // Acquires l.mu.
 func (l *lockState) discoveredLock(
 	txn *enginepb.TxnMeta,
+	str lock.Strength,
 	ts hlc.Timestamp,
 	g *lockTableGuardImpl,
 	sa spanset.SpanAccess,
@@ -1910,20 +2204,27 @@ func (l *lockState) discoveredLock(
 		l.notRemovable++
 	}
 	if l.holder.locked {
-		if !l.isLockedBy(txn.ID) {
-			return errors.AssertionFailedf(
-				"discovered lock by different transaction (%s) than existing lock (see issue #63592): %s",
-				txn, l)
+		// Merge the discovered lock into the holders. It can be a Shared lock held
+		// alongside the locks of other transactions, or the lock of a transaction
+		// that already holds it, possibly with a different strength or
+		// durability.
+		h := l.findHolder(txn.ID)
+		if h == nil {
+			h = &txnLockHolder{}
+			if err := l.mergeHolder(h, str); err != nil {
+				return errors.AssertionFailedf(
+					"discovered lock by different transaction (%s) than existing lock (see issue #63592): %s",
+					txn, l)
+			}
+		} else if err := l.mergeHolder(h, str); err != nil {
+			return errors.Wrapf(err, "discovered %s lock", str)
 		}
+		setDiscoveredHolder(h, txn, ts)
 	} else {
 		l.holder.locked = true
+		l.holder.strength = str
 		l.holder.startTime = clock.PhysicalTime()
-	}
-	holder := &l.holder.holder[lock.Replicated]
-	if holder.txn == nil {
-		holder.txn = txn
-		holder.ts = ts
-		holder.seqs = append(holder.seqs, txn.Sequence)
+		setDiscoveredHolder(&l.holder.holder, txn, ts)
 	}
 
 	// Queue the existing reservation holder. Note that this reservation
@@ -1994,6 +2295,17 @@ func (l *lockState) discoveredLock(
 	return nil
 }
 
+// Records the replicated lock held by txn with timestamp ts in the holder h,
+// unless it already knows about it.
+func setDiscoveredHolder(h *txnLockHolder, txn *enginepb.TxnMeta, ts hlc.Timestamp) {
+	holder := &h[lock.Replicated]
+	if holder.txn == nil {
+		holder.txn = txn
+		holder.ts = ts
+		holder.seqs = append(holder.seqs, txn.Sequence)
+	}
+}
+
 func (l *lockState) decrementNotRemovable() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -2104,22 +2416,22 @@ func (l *lockState) tryUpdateLock(up *roachpb.LockUpdate) (heldByTxn, gc bool) {
 		// tryActiveWait due to the txn being in the finalizedTxnCache.
 		return false, true
 	}
-	if !l.isLockedBy(up.Txn.ID) {
+	txnHolder := l.findHolder(up.Txn.ID)
+	if txnHolder == nil {
 		return false, false
 	}
 	if up.Status.IsFinalized() {
-		l.clearLockHolder()
-		gc = l.lockIsFree()
+		gc = l.releaseHolder(txnHolder)
 		return true, gc
 	}
 
 	txn := &up.Txn
 	ts := up.Txn.WriteTimestamp
-	_, beforeTs := l.getLockHolder()
+	_, beforeTs := txnHolder.getTxnAndTS()
 	advancedTs := beforeTs.Less(ts)
 	isLocked := false
-	for i := range l.holder.holder {
-		holder := &l.holder.holder[i]
+	for i := range txnHolder {
+		holder := &txnHolder[i]
 		if holder.txn == nil {
 			continue
 		}
@@ -2166,12 +2478,13 @@ func (l *lockState) tryUpdateLock(up *roachpb.LockUpdate) (heldByTxn, gc bool) {
 	}
 
 	if !isLocked {
-		l.clearLockHolder()
-		gc = l.lockIsFree()
+		gc = l.releaseHolder(txnHolder)
 		return true, gc
 	}
 
-	if advancedTs {
+	// Only an Exclusive lock holder, which is the sole holder of the lock, can
+	// have readers waiting on it.
+	if advancedTs && txnHolder == &l.holder.holder {
 		l.increasedLockTs(ts)
 	}
 	// Else no change for waiters. This can happen due to a race between different
@@ -2260,6 +2573,12 @@ func (l *lockState) requestDone(g *lockTableGuardImpl) (gc bool) {
 	if !doneRemoval {
 		panic("lockTable bug")
 	}
+	if l.holder.locked && l.holder.strength == lock.Shared {
+		// The requests desiring Shared locks that were queued behind g may no
+		// longer need to wait.
+		l.releaseCompatibleWaiters()
+		distinguishedRemoved = distinguishedRemoved || l.distinguishedWaiter == nil
+	}
 	if distinguishedRemoved {
 		l.tryMakeNewDistinguished()
 	}
@@ -2269,8 +2588,9 @@ func (l *lockState) requestDone(g *lockTableGuardImpl) (gc bool) {
 // tryFreeLockOnReplicatedAcquire attempts to free a write-uncontended lock
 // during the state transition from the Unreplicated durability to the
 // Replicated durability. This is possible because a Replicated lock is also
-// stored as an MVCC intent, so it does not need to also be stored in the
-// lockTable if writers are not queuing on it. This is beneficial because it
+// stored in the replicated lock table keyspace, either as an MVCC intent or as
+// a Shared lock, so it does not need to also be stored in the lockTable if
+// writers are not queuing on it. This is beneficial because it
 // serves as a mitigation for #49973. Since we aren't currently great at
 // avoiding excessive contention on limited scans when locks are in the
 // lockTable, it's better the keep locks out of the lockTable when possible.
@@ -2282,7 +2602,7 @@ func (l *lockState) requestDone(g *lockTableGuardImpl) (gc bool) {
 // concurrency discussed in #49973.
 //
 // Acquires l.mu.
-func (l *lockState) tryFreeLockOnReplicatedAcquire() bool {
+func (l *lockState) tryFreeLockOnReplicatedAcquire(txn *enginepb.TxnMeta, str lock.Strength) bool {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -2291,6 +2611,20 @@ func (l *lockState) tryFreeLockOnReplicatedAcquire() bool {
 		return false
 	}
 
+	// Bail if the lock is not held by the acquiring transaction, or is held by
+	// it with a stronger strength than the replicated lock. Dropping the lock
+	// would then forget about a lock that is not stored in the replicated
+	// keyspace.
+	if !l.holder.holder.isHeldBy(txn.ID) || str < l.holder.strength {
+		return false
+	}
+
+	// Bail if the lock is shared with other transactions. It is not
+	// uncontended.
+	if len(l.holder.shared) > 0 {
+		return false
+	}
+
 	// Bail if the lock has waiting writers. It is not uncontended.
 	if l.queuedWriters.Len() != 0 {
 		return false
@@ -2431,6 +2765,12 @@ func (t *lockTableImpl) newGuardForReq(req Request) *lockTableGuardImpl {
 	g.ts = req.Timestamp
 	g.spans = req.LockSpans
 	g.maxWaitQueueLength = req.MaxLockWaitQueueLength
+	g.str = req.LockStrength
+	if g.str == lock.None || g.txn == nil {
+		// Non-transactional requests don't acquire locks, but their writes
+		// conflict with all locks.
+		g.str = lock.Exclusive
+	}
 	g.sa = spanset.NumSpanAccess - 1
 	g.index = -1
 	return g
@@ -2571,7 +2911,8 @@ func (t *lockTableImpl) AddDiscoveredLock(
 		g.notRemovableLock = l
 		notRemovableLock = true
 	}
-	err = l.discoveredLock(&intent.Txn, intent.Txn.WriteTimestamp, g, sa, notRemovableLock, g.lt.clock)
+	err = l.discoveredLock(
+		&intent.Txn, intent.LockStrength(), intent.Txn.WriteTimestamp, g, sa, notRemovableLock, g.lt.clock)
 	// Can't release tree.mu until call l.discoveredLock() since someone may
 	// find an empty lock and remove it from the tree.
 	tree.mu.Unlock()
@@ -2591,8 +2932,8 @@ func (t *lockTableImpl) AcquireLock(
 		// If not enabled, don't track any locks.
 		return nil
 	}
-	if strength != lock.Exclusive {
-		return errors.AssertionFailedf("lock strength not Exclusive")
+	if strength != lock.Shared && strength != lock.Upgrade && strength != lock.Exclusive {
+		return errors.AssertionFailedf("invalid lock strength %s", strength)
 	}
 	ss := spanset.SpanGlobal
 	if keys.IsLocal(key) {
@@ -2628,7 +2969,7 @@ func (t *lockTableImpl) AcquireLock(
 		atomic.AddInt64(&tree.numLocks, 1)
 	} else {
 		l = iter.Cur()
-		if durability == lock.Replicated && l.tryFreeLockOnReplicatedAcquire() {
+		if durability == lock.Replicated && l.tryFreeLockOnReplicatedAcquire(txn, strength) {
 			// Don't remember uncontended replicated locks. Just like in the
 			// case where the lock is initially added as replicated, we drop
 			// replicated locks from the lockTable when being upgraded from
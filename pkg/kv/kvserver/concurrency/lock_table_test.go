@@ -63,10 +63,11 @@ new-txn txn=<name> ts=<int>[,<int>] epoch=<int> [seq=<int>]
 
  Creates a TxnMeta.
 
-new-request r=<name> txn=<name>|none ts=<int>[,<int>] spans=r|w@<start>[,<end>]+... [max-lock-wait-queue-length=<int>]
+new-request r=<name> txn=<name>|none ts=<int>[,<int>] spans=r|w@<start>[,<end>]+... [max-lock-wait-queue-length=<int>] [str=shared|upgrade|exclusive]
 ----
 
- Creates a Request.
+ Creates a Request. The optional strength is the lock strength desired for the
+ write spans, which defaults to exclusive.
 
 scan r=<name>
 ----
@@ -82,11 +83,12 @@ start-waiting: <bool>
  Calls lockTable.ScanOptimistic. The request must not have an existing guard.
  If a guard is returned, stores it for later use.
 
-acquire r=<name> k=<key> durability=r|u
+acquire r=<name> k=<key> durability=r|u [str=shared|upgrade|exclusive]
 ----
 <error string>
 
- Acquires lock for the request, using the existing guard for that request.
+ Acquires lock for the request, using the existing guard for that request. The
+ lock strength defaults to exclusive.
 
 release txn=<name> span=<start>[,<end>]
 ----
@@ -105,11 +107,12 @@ txn-finalized txn=<name> status=committed|aborted
 
  Informs the lock table that the named transaction is finalized.
 
-add-discovered r=<name> k=<key> txn=<name> [lease-seq=<seq>] [consult-finalized-txn-cache=<bool>]
+add-discovered r=<name> k=<key> txn=<name> [lease-seq=<seq>] [consult-finalized-txn-cache=<bool>] [str=shared|exclusive]
 ----
 <error string>
 
- Adds a discovered lock that is discovered by the named request.
+ Adds a discovered lock that is discovered by the named request. The lock
+ strength defaults to exclusive, which is an intent.
 
 check-opt-no-conflicts r=<name> spans=r|w@<start>[,<end>]+...
 ----
@@ -301,6 +304,7 @@ func TestLockTableBasic(t *testing.T) {
 					MaxLockWaitQueueLength: maxLockWaitQueueLength,
 					LatchSpans:             spans,
 					LockSpans:              spans,
+					LockStrength:           scanLockStrength(t, d),
 				}
 				if txnMeta != nil {
 					// Update the transaction's timestamp, if necessary. The transaction
@@ -359,7 +363,7 @@ func TestLockTableBasic(t *testing.T) {
 				if s[0] == 'r' {
 					durability = lock.Replicated
 				}
-				if err := lt.AcquireLock(&req.Txn.TxnMeta, roachpb.Key(key), lock.Exclusive, durability); err != nil {
+				if err := lt.AcquireLock(&req.Txn.TxnMeta, roachpb.Key(key), scanLockStrength(t, d), durability); err != nil {
 					return err.Error()
 				}
 				return lt.String()
@@ -448,6 +452,7 @@ func TestLockTableBasic(t *testing.T) {
 					d.Fatalf(t, "unknown txn %s", txnName)
 				}
 				intent := roachpb.MakeIntent(txnMeta, roachpb.Key(key))
+				intent.Strength = scanLockStrength(t, d)
 				seq := int(1)
 				if d.HasArg("lease-seq") {
 					d.ScanArgs(t, "lease-seq", &seq)
@@ -644,6 +649,25 @@ func scanTimestamp(t *testing.T, d *datadriven.TestData) hlc.Timestamp {
 	return ts
 }
 
+func scanLockStrength(t *testing.T, d *datadriven.TestData) lock.Strength {
+	if !d.HasArg("str") {
+		return lock.Exclusive
+	}
+	var strS string
+	d.ScanArgs(t, "str", &strS)
+	switch strS {
+	case "shared":
+		return lock.Shared
+	case "upgrade":
+		return lock.Upgrade
+	case "exclusive":
+		return lock.Exclusive
+	default:
+		d.Fatalf(t, "unknown lock strength: %s", strS)
+		return 0
+	}
+}
+
 func getSpan(t *testing.T, d *datadriven.TestData, str string) roachpb.Span {
 	parts := strings.Split(str, ",")
 	span := roachpb.Span{Key: roachpb.Key(parts[0])}
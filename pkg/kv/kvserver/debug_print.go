@@ -299,6 +299,9 @@ func tryRangeIDKey(kv storage.MVCCKeyValue) (string, error) {
 	case bytes.Equal(suffix, keys.LocalRangeGCThresholdSuffix):
 		msg = &hlc.Timestamp{}
 
+	case bytes.Equal(suffix, keys.LocalRangeGCRetentionSuffix):
+		msg = &roachpb.GCRetention{}
+
 	case bytes.Equal(suffix, keys.LocalRangeVersionSuffix):
 		msg = &roachpb.Version{}
 
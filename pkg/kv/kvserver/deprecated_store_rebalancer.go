@@ -367,7 +367,7 @@ func (sr *StoreRebalancer) getRebalanceCandidatesBasedOnQPS(
 	storeList storepool.StoreList,
 	minQPS, maxQPS float64,
 ) (finalVoterTargets, finalNonVoterTargets []roachpb.ReplicaDescriptor) {
-	options := sr.scorerOptions(ctx)
+	options := sr.scorerOptions(ctx, allocator.Queries)
 	options.QPSRebalanceThreshold = allocator.QPSRebalanceThreshold.Get(&sr.st.SV)
 
 	// Decide which voting / non-voting replicas we want to keep around and find
@@ -120,7 +120,7 @@ type Thresholder interface {
 
 // PureGCer is part of the GCer interface.
 type PureGCer interface {
-	GC(context.Context, []roachpb.GCRequest_GCKey) error
+	GC(context.Context, []roachpb.GCRequest_GCKey, []roachpb.GCRequest_GCVersionRange) error
 }
 
 // A GCer is an abstraction used by the MVCC GC queue to carry out chunked deletions.
@@ -138,12 +138,18 @@ var _ GCer = NoopGCer{}
 func (NoopGCer) SetGCThreshold(context.Context, Threshold) error { return nil }
 
 // GC implements storage.GCer.
-func (NoopGCer) GC(context.Context, []roachpb.GCRequest_GCKey) error { return nil }
+func (NoopGCer) GC(
+	context.Context, []roachpb.GCRequest_GCKey, []roachpb.GCRequest_GCVersionRange,
+) error {
+	return nil
+}
 
-// Threshold holds the key and txn span GC thresholds, respectively.
+// Threshold holds the key and txn span GC thresholds, respectively, along
+// with the downsampled retention state of the range, if any.
 type Threshold struct {
-	Key hlc.Timestamp
-	Txn hlc.Timestamp
+	Key       hlc.Timestamp
+	Txn       hlc.Timestamp
+	Retention *roachpb.GCRetention
 }
 
 // Info contains statistics and insights from a GC run.
@@ -208,6 +214,10 @@ type RunOptions struct {
 	MaxTxnsPerIntentCleanupBatch int64
 	// IntentCleanupBatchTimeout is the timeout for processing a batch of intents. 0 to disable.
 	IntentCleanupBatchTimeout time.Duration
+	// Retention, if set, is persisted along with the new GC threshold. Below the
+	// threshold, the versions visible at the timestamps retained by its tiers
+	// are not garbage.
+	Retention *roachpb.GCRetention
 }
 
 // CleanupIntentsFunc synchronously resolves the supplied intents
@@ -241,8 +251,9 @@ func Run(
 
 	txnExp := now.Add(-kvserverbase.TxnCleanupThreshold.Nanoseconds(), 0)
 	if err := gcer.SetGCThreshold(ctx, Threshold{
-		Key: newThreshold,
-		Txn: txnExp,
+		Key:       newThreshold,
+		Txn:       txnExp,
+		Retention: options.Retention,
 	}); err != nil {
 		return Info{}, errors.Wrap(err, "failed to set GC thresholds")
 	}
@@ -253,7 +264,12 @@ func Run(
 		Threshold: newThreshold,
 	}
 
-	err := processReplicatedKeyRange(ctx, desc, snap, now, newThreshold, options.IntentAgeThreshold, gcer,
+	var retentionTiers []roachpb.GCRetentionTier
+	if options.Retention != nil {
+		retentionTiers = options.Retention.Tiers
+	}
+	err := processReplicatedKeyRange(ctx, desc, snap, now, newThreshold, retentionTiers,
+		options.IntentAgeThreshold, gcer,
 		intentBatcherOptions{
 			maxIntentsPerIntentCleanupBatch:        options.MaxIntentsPerIntentCleanupBatch,
 			maxIntentKeyBytesPerIntentCleanupBatch: options.MaxIntentKeyBytesPerIntentCleanupBatch,
@@ -299,6 +315,7 @@ func processReplicatedKeyRange(
 	snap storage.Reader,
 	now hlc.Timestamp,
 	threshold hlc.Timestamp,
+	retentionTiers []roachpb.GCRetentionTier,
 	intentAgeThreshold time.Duration,
 	gcer GCer,
 	options intentBatcherOptions,
@@ -342,13 +359,31 @@ func processReplicatedKeyRange(
 	// be added with that version and the batch will be sent. When the newest
 	// version for a key has been reached, if haveGarbageForThisKey, we'll add the
 	// current key to the batch with the gcTimestampForThisKey.
+	//
+	// With retention tiers, a version which is garbage may be newer than a
+	// version which is not. Such versions are removed from the middle of the
+	// key's history: consecutive garbage versions newer than a kept version are
+	// collected into a version range, which is added to the batch once the
+	// next kept version is reached.
 	var (
 		batchGCKeys           []roachpb.GCRequest_GCKey
+		batchGCVersionRanges  []roachpb.GCRequest_GCVersionRange
 		batchGCKeysBytes      int64
 		haveGarbageForThisKey bool
 		gcTimestampForThisKey hlc.Timestamp
 		sentBatchForThisKey   bool
+		keptVersionForThisKey bool
+		haveVersionRange      bool
+		versionRange          roachpb.GCRequest_GCVersionRange
+		haveRangesForThisKey  bool
 	)
+	finishVersionRange := func(key roachpb.Key) {
+		alloc, versionRange.Key = alloc.Copy(key, 0)
+		batchGCVersionRanges = append(batchGCVersionRanges, versionRange)
+		versionRange = roachpb.GCRequest_GCVersionRange{}
+		haveVersionRange = false
+		haveRangesForThisKey = true
+	}
 	it := makeGCIterator(desc, snap)
 	defer it.close()
 	for ; ; it.step() {
@@ -369,19 +404,37 @@ func processReplicatedKeyRange(
 			continue
 		}
 		isNewest := s.curIsNewest()
-		if isGarbage(threshold, s.cur, s.next, isNewest) {
+		garbage := isGarbage(threshold, s.cur, s.next, isNewest)
+		if garbage && len(retentionTiers) > 0 {
+			garbage = isRetentionGarbage(
+				retentionTiers, now, threshold, s.cur, s.next, isNewest, keptVersionForThisKey)
+		}
+		if garbage {
 			keyBytes := int64(s.cur.Key.EncodedSize())
 			batchGCKeysBytes += keyBytes
-			haveGarbageForThisKey = true
-			gcTimestampForThisKey = s.cur.Key.Timestamp
+			if keptVersionForThisKey {
+				if !haveVersionRange {
+					versionRange.StartTimestamp = s.cur.Key.Timestamp
+					haveVersionRange = true
+				}
+				versionRange.EndTimestamp = s.cur.Key.Timestamp
+			} else {
+				haveGarbageForThisKey = true
+				gcTimestampForThisKey = s.cur.Key.Timestamp
+			}
 			info.AffectedVersionsKeyBytes += keyBytes
 			info.AffectedVersionsValBytes += int64(len(s.cur.Value))
+		} else {
+			keptVersionForThisKey = true
+			if haveVersionRange {
+				finishVersionRange(s.cur.Key.Key)
+			}
 		}
-		if affected := isNewest && (sentBatchForThisKey || haveGarbageForThisKey); affected {
+		if affected := isNewest && (sentBatchForThisKey || haveGarbageForThisKey || haveRangesForThisKey); affected {
 			info.NumKeysAffected++
 		}
 		shouldSendBatch := batchGCKeysBytes >= KeyVersionChunkBytes
-		if shouldSendBatch || isNewest && haveGarbageForThisKey {
+		if (shouldSendBatch || isNewest) && haveGarbageForThisKey {
 			alloc, s.cur.Key.Key = alloc.Copy(s.cur.Key.Key, 0)
 			batchGCKeys = append(batchGCKeys, roachpb.GCRequest_GCKey{
 				Key:       s.cur.Key.Key,
@@ -396,8 +449,17 @@ func processReplicatedKeyRange(
 			// deletion of its versions.
 			sentBatchForThisKey = shouldSendBatch && !isNewest
 		}
+		if shouldSendBatch && haveVersionRange {
+			// The version following the range is garbage as well, but it is
+			// only removed by a later batch.
+			finishVersionRange(s.cur.Key.Key)
+		}
+		if isNewest {
+			keptVersionForThisKey = false
+			haveRangesForThisKey = false
+		}
 		if shouldSendBatch {
-			if err := gcer.GC(ctx, batchGCKeys); err != nil {
+			if err := gcer.GC(ctx, batchGCKeys, batchGCVersionRanges); err != nil {
 				if errors.Is(err, ctx.Err()) {
 					return err
 				}
@@ -408,6 +470,7 @@ func processReplicatedKeyRange(
 				log.Warningf(ctx, "failed to GC a batch of keys: %v", err)
 			}
 			batchGCKeys = nil
+			batchGCVersionRanges = nil
 			batchGCKeysBytes = 0
 			alloc = bufalloc.ByteAllocator{}
 		}
@@ -419,8 +482,8 @@ func processReplicatedKeyRange(
 		}
 		log.Warningf(ctx, "failed to cleanup intents batch: %v", err)
 	}
-	if len(batchGCKeys) > 0 {
-		if err := gcer.GC(ctx, batchGCKeys); err != nil {
+	if len(batchGCKeys) > 0 || len(batchGCVersionRanges) > 0 {
+		if err := gcer.GC(ctx, batchGCKeys, batchGCVersionRanges); err != nil {
 			return err
 		}
 	}
@@ -580,6 +643,29 @@ func isGarbage(threshold hlc.Timestamp, cur, next *storage.MVCCKeyValue, isNewes
 	return isDelete || next.Key.Timestamp.LessEq(threshold)
 }
 
+// isRetentionGarbage refines isGarbage for ranges with retention tiers. A
+// version which isGarbage considers garbage is kept if it is visible at a
+// timestamp that the tiers retain as of now. Removing a deletion tombstone
+// along with all older versions of the key doesn't change what is visible at
+// any timestamp, so such a tombstone remains garbage unless an older version
+// is kept. Otherwise, the newest version is never garbage, since removing it
+// would expose an older version to reads above the threshold.
+func isRetentionGarbage(
+	tiers []roachpb.GCRetentionTier,
+	now, threshold hlc.Timestamp,
+	cur, next *storage.MVCCKeyValue,
+	isNewest, olderVersionKept bool,
+) bool {
+	if isDelete := len(cur.Value) == 0; isDelete && !olderVersionKept {
+		return true
+	}
+	if isNewest {
+		return false
+	}
+	return next.Key.Timestamp.LessEq(threshold) &&
+		!retainsAnyTimestampIn(tiers, now, cur.Key.Timestamp, next.Key.Timestamp)
+}
+
 // processLocalKeyRange scans the local range key entries, consisting of
 // transaction records, queue last processed timestamps, and range descriptors.
 //
@@ -732,7 +818,7 @@ func (b *batchingInlineGCer) FlushingAdd(ctx context.Context, key roachpb.Key) {
 }
 
 func (b *batchingInlineGCer) Flush(ctx context.Context) {
-	err := b.gcer.GC(ctx, b.gcKeys)
+	err := b.gcer.GC(ctx, b.gcKeys, nil /* versionRanges */)
 	b.gcKeys = nil
 	b.size = 0
 	if err != nil {
@@ -184,11 +184,12 @@ func BenchmarkRun(b *testing.B) {
 }
 
 type fakeGCer struct {
-	gcKeys     map[string]roachpb.GCRequest_GCKey
-	threshold  Threshold
-	intents    []roachpb.Intent
-	batches    [][]roachpb.Intent
-	txnIntents []txnIntents
+	gcKeys          map[string]roachpb.GCRequest_GCKey
+	gcVersionRanges []roachpb.GCRequest_GCVersionRange
+	threshold       Threshold
+	intents         []roachpb.Intent
+	batches         [][]roachpb.Intent
+	txnIntents      []txnIntents
 }
 
 func makeFakeGCer() fakeGCer {
@@ -204,10 +205,15 @@ func (f *fakeGCer) SetGCThreshold(ctx context.Context, t Threshold) error {
 	return nil
 }
 
-func (f *fakeGCer) GC(ctx context.Context, keys []roachpb.GCRequest_GCKey) error {
+func (f *fakeGCer) GC(
+	ctx context.Context,
+	keys []roachpb.GCRequest_GCKey,
+	versionRanges []roachpb.GCRequest_GCVersionRange,
+) error {
 	for _, k := range keys {
 		f.gcKeys[k.Key.String()] = k
 	}
+	f.gcVersionRanges = append(f.gcVersionRanges, versionRanges...)
 	return nil
 }
 
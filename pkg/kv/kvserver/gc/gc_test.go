@@ -48,7 +48,9 @@ type collectingGCer struct {
 	keys [][]roachpb.GCRequest_GCKey
 }
 
-func (c *collectingGCer) GC(_ context.Context, keys []roachpb.GCRequest_GCKey) error {
+func (c *collectingGCer) GC(
+	_ context.Context, keys []roachpb.GCRequest_GCKey, _ []roachpb.GCRequest_GCVersionRange,
+) error {
 	c.keys = append(c.keys, keys)
 	return nil
 }
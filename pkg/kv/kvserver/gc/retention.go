@@ -0,0 +1,131 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gc
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// Retention tiers downsample the MVCC history which is older than the GC TTL.
+// A tier with interval I and TTL T retains the timestamps which are multiples
+// of I (in wall time, with a zero logical component) and no older than T. GC
+// keeps the versions of a key which are visible at the retained timestamps,
+// which is all that's needed to serve reads exactly at these timestamps.
+//
+// Retained timestamps age out as time passes. GC determines them as of the
+// time it runs while reads determine them as of the time they are served,
+// which is later, so a read never expects a version that GC considered to be
+// outside of the tiers. To also tolerate clock offsets between the node that
+// runs GC and the nodes serving reads, GC keeps the timestamps of one extra
+// interval at the end of each tier.
+
+func tierInterval(tier roachpb.GCRetentionTier) int64 {
+	return int64(tier.IntervalSeconds) * time.Second.Nanoseconds()
+}
+
+func tierTTL(tier roachpb.GCRetentionTier) int64 {
+	return int64(tier.TTLSeconds) * time.Second.Nanoseconds()
+}
+
+// firstMultipleAtOrAbove returns the first multiple of interval which, as a
+// timestamp, is not below ts.
+func firstMultipleAtOrAbove(ts hlc.Timestamp, interval int64) int64 {
+	wallTime := ts.WallTime
+	if rem := wallTime % interval; rem != 0 {
+		wallTime += interval - rem
+	}
+	if (hlc.Timestamp{WallTime: wallTime}).Less(ts) {
+		wallTime += interval
+	}
+	return wallTime
+}
+
+// IsRetainedTimestamp returns whether the given retention tiers retain the
+// timestamp ts as of now.
+func IsRetainedTimestamp(tiers []roachpb.GCRetentionTier, now, ts hlc.Timestamp) bool {
+	if ts.Logical != 0 {
+		return false
+	}
+	for _, tier := range tiers {
+		interval := tierInterval(tier)
+		if interval <= 0 || ts.WallTime%interval != 0 {
+			continue
+		}
+		if !ts.Less(now.Add(-tierTTL(tier), 0)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetentionThreshold returns the timestamp at or below which the given
+// retention tiers don't retain any timestamps as of now.
+func RetentionThreshold(tiers []roachpb.GCRetentionTier, now hlc.Timestamp) hlc.Timestamp {
+	var oldest hlc.Timestamp
+	for _, tier := range tiers {
+		interval := tierInterval(tier)
+		if interval <= 0 {
+			continue
+		}
+		first := hlc.Timestamp{
+			WallTime: firstMultipleAtOrAbove(now.Add(-tierTTL(tier), 0), interval),
+		}
+		if oldest.IsEmpty() || first.Less(oldest) {
+			oldest = first
+		}
+	}
+	if oldest.IsEmpty() {
+		return now
+	}
+	return oldest.Prev()
+}
+
+// RetentionTiersSubsume returns whether every timestamp retained by the tiers
+// next is also retained by the tiers prev, at any point in time. This is the
+// case if each tier of next has a tier in prev with an interval that divides
+// its own and a TTL which is at least as long.
+func RetentionTiersSubsume(prev, next []roachpb.GCRetentionTier) bool {
+	for _, n := range next {
+		var found bool
+		for _, p := range prev {
+			if p.IntervalSeconds > 0 && n.IntervalSeconds%p.IntervalSeconds == 0 &&
+				p.TTLSeconds >= n.TTLSeconds {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// retainsAnyTimestampIn returns whether GC running at now has to keep a
+// version visible over [from, to) because the tiers retain a timestamp in it.
+func retainsAnyTimestampIn(tiers []roachpb.GCRetentionTier, now, from, to hlc.Timestamp) bool {
+	for _, tier := range tiers {
+		interval := tierInterval(tier)
+		if interval <= 0 {
+			continue
+		}
+		// Keep an extra interval at the end of the tier, see above.
+		start := now.Add(-tierTTL(tier)-interval, 0)
+		start.Forward(from)
+		if (hlc.Timestamp{WallTime: firstMultipleAtOrAbove(start, interval)}).Less(to) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package gc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionTimestamps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sec := func(s int64) hlc.Timestamp { return hlc.Timestamp{WallTime: s * time.Second.Nanoseconds()} }
+	tiers := []roachpb.GCRetentionTier{
+		{IntervalSeconds: 10, TTLSeconds: 60},
+		{IntervalSeconds: 100, TTLSeconds: 1000},
+	}
+	now := sec(1055)
+
+	for _, tc := range []struct {
+		ts       hlc.Timestamp
+		retained bool
+	}{
+		{ts: sec(1000), retained: true},
+		{ts: sec(1000).Next()},
+		{ts: sec(1005)},
+		{ts: sec(1000).Add(1, 0)},
+		// The first tier retains multiples of 10s back to 995s.
+		{ts: sec(1010), retained: true},
+		{ts: sec(990)},
+		// The second tier retains multiples of 100s back to 55s.
+		{ts: sec(900), retained: true},
+		{ts: sec(100), retained: true},
+		{ts: sec(0)},
+	} {
+		require.Equal(t, tc.retained, IsRetainedTimestamp(tiers, now, tc.ts), "%s", tc.ts)
+	}
+
+	require.Equal(t, sec(100).Prev(), RetentionThreshold(tiers, now))
+	require.Equal(t, sec(1000).Prev(), RetentionThreshold(tiers[:1], now))
+	require.Equal(t, now, RetentionThreshold(nil, now))
+
+	require.True(t, RetentionTiersSubsume(tiers, tiers))
+	require.True(t, RetentionTiersSubsume(tiers, nil))
+	require.True(t, RetentionTiersSubsume(tiers, []roachpb.GCRetentionTier{
+		{IntervalSeconds: 20, TTLSeconds: 60},
+		{IntervalSeconds: 200, TTLSeconds: 1000},
+	}))
+	require.False(t, RetentionTiersSubsume(tiers, []roachpb.GCRetentionTier{
+		{IntervalSeconds: 10, TTLSeconds: 120},
+	}))
+	require.False(t, RetentionTiersSubsume(tiers, []roachpb.GCRetentionTier{
+		{IntervalSeconds: 15, TTLSeconds: 60},
+	}))
+	require.False(t, RetentionTiersSubsume(nil, tiers))
+}
+
+// applyingGCer applies the GC requests of a run directly to an engine.
+type applyingGCer struct {
+	eng storage.Engine
+	ms  enginepb.MVCCStats
+}
+
+var _ GCer = (*applyingGCer)(nil)
+
+func (a *applyingGCer) SetGCThreshold(context.Context, Threshold) error { return nil }
+
+func (a *applyingGCer) GC(
+	ctx context.Context,
+	keys []roachpb.GCRequest_GCKey,
+	versionRanges []roachpb.GCRequest_GCVersionRange,
+) error {
+	if err := storage.MVCCGarbageCollect(ctx, a.eng, &a.ms, keys, hlc.Timestamp{}); err != nil {
+		return err
+	}
+	return storage.MVCCGarbageCollectVersionRanges(ctx, a.eng, &a.ms, versionRanges)
+}
+
+func TestRunWithRetentionTiers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	eng := storage.NewDefaultInMemForTesting()
+	defer eng.Close()
+	gcer := &applyingGCer{eng: eng}
+
+	sec := func(s int64) hlc.Timestamp { return hlc.Timestamp{WallTime: s * time.Second.Nanoseconds()} }
+	put := func(key string, s int64) {
+		require.NoError(t, storage.MVCCPut(ctx, eng, &gcer.ms, roachpb.Key(key), sec(s),
+			hlc.ClockTimestamp{}, roachpb.MakeValueFromString(fmt.Sprintf("%s@%d", key, s)), nil))
+	}
+	del := func(key string, s int64) {
+		require.NoError(t, storage.MVCCDelete(ctx, eng, &gcer.ms, roachpb.Key(key), sec(s),
+			hlc.ClockTimestamp{}, nil))
+	}
+	// A key with a version every 3s.
+	for s := int64(1); s <= 100; s += 3 {
+		put("a", s)
+	}
+	// A key whose versions are all needed for reads at retained timestamps.
+	put("b", 5)
+	del("b", 35)
+	put("b", 95)
+	// A deleted key whose versions are not needed.
+	put("c", 5)
+	del("c", 15)
+	// A key with a tombstone which is not needed.
+	put("d", 5)
+	del("d", 32)
+	put("d", 33)
+	put("d", 95)
+
+	// Full history is retained for 10s, and every 10s for 60s, which with the
+	// extra interval that GC keeps means back to 30s.
+	now := sec(100)
+	ttl := 10 * time.Second
+	threshold := CalculateThreshold(now, ttl)
+	tiers := []roachpb.GCRetentionTier{{IntervalSeconds: 10, TTLSeconds: 60}}
+
+	read := func(key string, ts hlc.Timestamp) string {
+		v, _, err := storage.MVCCGet(ctx, eng, roachpb.Key(key), ts, storage.MVCCGetOptions{})
+		require.NoError(t, err)
+		if v == nil {
+			return "<nil>"
+		}
+		b, err := v.GetBytes()
+		require.NoError(t, err)
+		return string(b)
+	}
+	var readTimestamps []hlc.Timestamp
+	for s := int64(30); s < 90; s += 10 {
+		readTimestamps = append(readTimestamps, sec(s))
+	}
+	for ts := threshold; ts.LessEq(now); ts = ts.Add(time.Second.Nanoseconds()/2, 0) {
+		readTimestamps = append(readTimestamps, ts)
+	}
+	expected := make(map[string]string)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		for _, ts := range readTimestamps {
+			expected[fmt.Sprintf("%s@%s", key, ts)] = read(key, ts)
+		}
+	}
+
+	desc := roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKey("a"),
+		EndKey:   roachpb.RKey("z"),
+	}
+	snap := eng.NewSnapshot()
+	defer snap.Close()
+	_, err := Run(ctx, &desc, snap, now, threshold,
+		RunOptions{
+			IntentAgeThreshold: intentAgeThreshold,
+			Retention:          &roachpb.GCRetention{Tiers: tiers},
+		}, ttl, gcer,
+		func(context.Context, []roachpb.Intent) error { return nil },
+		func(context.Context, *roachpb.Transaction) error { return nil })
+	require.NoError(t, err)
+
+	// Reads at the retained timestamps and above the threshold are unaffected.
+	for _, key := range []string{"a", "b", "c", "d"} {
+		for _, ts := range readTimestamps {
+			require.Equal(t, expected[fmt.Sprintf("%s@%s", key, ts)], read(key, ts), "%s@%s", key, ts)
+		}
+	}
+
+	// Only the needed versions remain.
+	versions := func(key string) []int64 {
+		var res []int64
+		kvs, err := storage.Scan(eng, roachpb.Key(key), roachpb.Key(key).Next(), 0)
+		require.NoError(t, err)
+		for _, kv := range kvs {
+			res = append(res, kv.Key.Timestamp.WallTime/time.Second.Nanoseconds())
+		}
+		return res
+	}
+	require.Equal(t, []int64{100, 97, 94, 91, 88, 79, 70, 58, 49, 40, 28}, versions("a"))
+	require.Equal(t, []int64{95, 35, 5}, versions("b"))
+	require.Empty(t, versions("c"))
+	require.Equal(t, []int64{95, 33, 5}, versions("d"))
+
+	// The stats are accurate.
+	iter := eng.NewMVCCIterator(storage.MVCCKeyAndIntentsIterKind, storage.IterOptions{
+		LowerBound: desc.StartKey.AsRawKey(),
+		UpperBound: desc.EndKey.AsRawKey(),
+	})
+	defer iter.Close()
+	expMS, err := storage.ComputeStatsForRange(
+		iter, desc.StartKey.AsRawKey(), desc.EndKey.AsRawKey(), now.WallTime)
+	require.NoError(t, err)
+	gcer.ms.AgeTo(now.WallTime)
+	require.Equal(t, expMS, gcer.ms)
+}
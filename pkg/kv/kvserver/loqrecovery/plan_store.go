@@ -0,0 +1,150 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package loqrecovery
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery/loqrecoverypb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+)
+
+const (
+	// planStoreDirName is the name of the directory under the auxiliary
+	// directory of an engine which holds plan store files.
+	planStoreDirName = "loqrecovery"
+	// stagedPlanFileName is the name of the file holding the plan staged on the
+	// node.
+	stagedPlanFileName = "staged-plan.bin"
+	// applicationResultFileName is the name of the file holding the outcome of
+	// the last plan applied on the node.
+	applicationResultFileName = "application-result.bin"
+)
+
+// PlanStore persists a recovery plan staged on a node of a running cluster
+// until the node is restarted and applies it, as well as the outcome of the
+// application. Both are kept in files rather than in a store as they must be
+// accessible before any stores are started.
+type PlanStore struct {
+	path string
+	fs   fs.FS
+}
+
+// NewPlanStore creates a PlanStore which keeps its files in the path of the
+// given filesystem.
+func NewPlanStore(path string, fs fs.FS) PlanStore {
+	return PlanStore{path: path, fs: fs}
+}
+
+// NewPlanStoreForEngine creates a PlanStore which keeps its files in the
+// auxiliary directory of the engine. A node keeps its plan store in its first
+// engine.
+func NewPlanStoreForEngine(eng storage.Engine) PlanStore {
+	return NewPlanStore(filepath.Join(eng.GetAuxiliaryDir(), planStoreDirName), eng)
+}
+
+// SavePlan stages the plan, replacing any previously staged plan.
+func (s PlanStore) SavePlan(plan loqrecoverypb.ReplicaUpdatePlan) error {
+	return errors.Wrap(s.write(stagedPlanFileName, &plan), "failed to save staged recovery plan")
+}
+
+// LoadPlan returns the staged plan, if any.
+func (s PlanStore) LoadPlan() (loqrecoverypb.ReplicaUpdatePlan, bool, error) {
+	var plan loqrecoverypb.ReplicaUpdatePlan
+	ok, err := s.read(stagedPlanFileName, &plan)
+	if err != nil {
+		return loqrecoverypb.ReplicaUpdatePlan{}, false, errors.Wrap(err,
+			"failed to load staged recovery plan")
+	}
+	return plan, ok, nil
+}
+
+// RemovePlan removes the staged plan, if any.
+func (s PlanStore) RemovePlan() error {
+	err := s.fs.Remove(filepath.Join(s.path, stagedPlanFileName))
+	if err != nil && !oserror.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove staged recovery plan")
+	}
+	return nil
+}
+
+// SaveResult records the outcome of applying a staged plan, replacing the
+// outcome of any previously applied plan.
+func (s PlanStore) SaveResult(result loqrecoverypb.PlanApplicationResult) error {
+	return errors.Wrap(s.write(applicationResultFileName, &result),
+		"failed to save recovery plan application result")
+}
+
+// LoadResult returns the outcome of the last plan applied, if any.
+func (s PlanStore) LoadResult() (loqrecoverypb.PlanApplicationResult, bool, error) {
+	var result loqrecoverypb.PlanApplicationResult
+	ok, err := s.read(applicationResultFileName, &result)
+	if err != nil {
+		return loqrecoverypb.PlanApplicationResult{}, false, errors.Wrap(err,
+			"failed to load recovery plan application result")
+	}
+	return result, ok, nil
+}
+
+// write atomically replaces the contents of the named file with the marshaled
+// message. The message is written to a temporary file which is then renamed,
+// so that a partially written file is never read back after a crash.
+func (s PlanStore) write(name string, msg protoutil.Message) error {
+	data, err := protoutil.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := s.fs.MkdirAll(s.path); err != nil {
+		return err
+	}
+	tmpName := filepath.Join(s.path, name+".tmp")
+	f, err := s.fs.Create(tmpName)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return s.fs.Rename(tmpName, filepath.Join(s.path, name))
+}
+
+// read unmarshals the contents of the named file into the message. It returns
+// false if the file doesn't exist.
+func (s PlanStore) read(name string, msg protoutil.Message) (bool, error) {
+	f, err := s.fs.Open(filepath.Join(s.path, name))
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return false, err
+	}
+	if err := protoutil.Unmarshal(data, msg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
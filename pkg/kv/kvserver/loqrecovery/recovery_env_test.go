@@ -19,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
@@ -32,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/keysutil"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/datadriven"
 	"github.com/cockroachdb/errors"
@@ -141,6 +143,9 @@ type quorumRecoveryEnv struct {
 
 	// plan to update replicas
 	plan loqrecoverypb.ReplicaUpdatePlan
+	// planCount is the number of plans made, used to assign each plan a
+	// deterministic ID.
+	planCount uint64
 }
 
 func (e *quorumRecoveryEnv) Handle(t *testing.T, d datadriven.TestData) string {
@@ -161,6 +166,14 @@ func (e *quorumRecoveryEnv) Handle(t *testing.T, d datadriven.TestData) string {
 		out = e.handleDumpStore(t, d)
 	case "apply-plan":
 		out, err = e.handleApplyPlan(t, d)
+	case "stage-plan":
+		// Stage plan on nodes as if it was distributed by a running cluster.
+		out, err = e.handleStagePlan(t, d)
+	case "apply-staged-plan":
+		// Apply plans staged on nodes as done on node restart.
+		out, err = e.handleApplyStagedPlan(t, d)
+	case "node-status":
+		out, err = e.handleNodeStatus(t, d)
 	case "dump-events":
 		out, err = e.dumpRecoveryEvents(t, d)
 	default:
@@ -403,6 +416,8 @@ func (e *quorumRecoveryEnv) handleMakePlan(t *testing.T, d datadriven.TestData)
 		return "", err
 	}
 	e.plan = plan
+	e.planCount++
+	e.plan.PlanID = uuid.FromUint128(uint128.FromInts(0, e.planCount))
 	// We only marshal actual data without container to reduce clutter.
 	out, err := yaml.Marshal(e.plan.Updates)
 	if err != nil {
@@ -588,6 +603,77 @@ func (e *quorumRecoveryEnv) handleApplyPlan(t *testing.T, d datadriven.TestData)
 	return "ok", nil
 }
 
+// recoveryServers returns a recovery server for each node with selected
+// stores, ordered by node ID. The plan store of each node is kept in the
+// engine of its lowest selected store.
+func (e *quorumRecoveryEnv) recoveryServers(t *testing.T, d datadriven.TestData) []*Server {
+	stores := e.parseStoresArg(t, d, true /* defaultToAll */)
+	sort.Slice(stores, func(i, j int) bool { return stores[i] < stores[j] })
+	nodes := e.groupStoresByNode(t, stores)
+	var servers []*Server
+	for nodeID, engines := range nodes {
+		nodeIDContainer := &base.NodeIDContainer{}
+		nodeIDContainer.Set(context.Background(), nodeID)
+		servers = append(servers, &Server{
+			nodeIDContainer: nodeIDContainer,
+			engines:         engines,
+			planStore:       NewPlanStoreForEngine(engines[0]),
+		})
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].nodeIDContainer.Get() < servers[j].nodeIDContainer.Get()
+	})
+	return servers
+}
+
+func (e *quorumRecoveryEnv) handleStagePlan(t *testing.T, d datadriven.TestData) (string, error) {
+	ctx := context.Background()
+	var force bool
+	if d.HasArg("force") {
+		d.ScanArgs(t, "force", &force)
+	}
+	for _, s := range e.recoveryServers(t, d) {
+		if err := s.stageLocalPlan(ctx, &e.plan, force); err != nil {
+			return "", err
+		}
+	}
+	return "ok", nil
+}
+
+func (e *quorumRecoveryEnv) handleApplyStagedPlan(
+	t *testing.T, d datadriven.TestData,
+) (string, error) {
+	ctx := context.Background()
+	for _, s := range e.recoveryServers(t, d) {
+		if err := ApplyStagedPlan(ctx, s.planStore, s.engines); err != nil {
+			return "", err
+		}
+	}
+	return "ok", nil
+}
+
+func (e *quorumRecoveryEnv) handleNodeStatus(t *testing.T, d datadriven.TestData) (string, error) {
+	var lines []string
+	for _, s := range e.recoveryServers(t, d) {
+		status, err := nodeRecoveryStatus(s.nodeIDContainer.Get(), s.planStore)
+		if err != nil {
+			return "", err
+		}
+		line := fmt.Sprintf("n%d:", status.NodeID)
+		if !status.PendingPlanID.Equal(uuid.Nil) {
+			line += fmt.Sprintf(" staged plan %s", status.PendingPlanID)
+		}
+		if !status.AppliedPlanID.Equal(uuid.Nil) {
+			line += fmt.Sprintf(" applied plan %s", status.AppliedPlanID)
+		}
+		if status.Error != "" {
+			line += fmt.Sprintf(" error: %s", status.Error)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func (e *quorumRecoveryEnv) cleanupStores() {
 	for _, store := range e.stores {
 		store.engine.Close()
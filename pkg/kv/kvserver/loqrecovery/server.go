@@ -0,0 +1,466 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package loqrecovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery/loqrecoverypb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// rangeHealthScanTimeout bounds the time spent reading range descriptors from
+// the meta ranges during verification. The meta ranges could have lost quorum
+// themselves, in which case the scan would otherwise block indefinitely.
+const rangeHealthScanTimeout = 30 * time.Second
+
+// Server implements loss of quorum recovery for a running cluster. Any node of
+// the cluster could coordinate the recovery: it collects replica info from all
+// reachable nodes, and stages a plan made from that info on the nodes which
+// hold the designated survivors. Nodes apply the staged plan when they are
+// restarted, see ApplyStagedPlan. The progress of the recovery is reported by
+// the recovery status of each node, and by verifying the health of all ranges
+// in the cluster.
+//
+// Nodes are discovered using gossip rather than node liveness or node status
+// records, as those could be unavailable if system ranges lost quorum.
+type Server struct {
+	nodeIDContainer *base.NodeIDContainer
+	engines         []storage.Engine
+	planStore       PlanStore
+	db              *kv.DB
+	gossip          *gossip.Gossip
+	rpcCtx          *rpc.Context
+}
+
+// NewServer creates a recovery server for the node owning the engines.
+func NewServer(
+	nodeIDContainer *base.NodeIDContainer,
+	engines []storage.Engine,
+	planStore PlanStore,
+	db *kv.DB,
+	g *gossip.Gossip,
+	rpcCtx *rpc.Context,
+) *Server {
+	return &Server{
+		nodeIDContainer: nodeIDContainer,
+		engines:         engines,
+		planStore:       planStore,
+		db:              db,
+		gossip:          g,
+		rpcCtx:          rpcCtx,
+	}
+}
+
+// ServeLocalReplicas returns the info of all replicas in the stores of the
+// local node.
+func (s *Server) ServeLocalReplicas(
+	ctx context.Context, _ *serverpb.RecoveryCollectLocalReplicaInfoRequest,
+) (*serverpb.RecoveryCollectLocalReplicaInfoResponse, error) {
+	info, err := CollectReplicaInfo(ctx, s.engines)
+	if err != nil {
+		return nil, err
+	}
+	return &serverpb.RecoveryCollectLocalReplicaInfoResponse{NodeReplicaInfo: info}, nil
+}
+
+// ServeClusterReplicas returns the info of all replicas in the stores of all
+// reachable nodes in the cluster. Nodes which could not be reached are
+// reported in the response.
+func (s *Server) ServeClusterReplicas(
+	ctx context.Context, _ *serverpb.RecoveryCollectReplicaInfoRequest,
+) (*serverpb.RecoveryCollectReplicaInfoResponse, error) {
+	resp := &serverpb.RecoveryCollectReplicaInfoResponse{}
+	if err := s.visitNodes(ctx,
+		func(nodeID roachpb.NodeID, client serverpb.AdminClient) error {
+			nodeResp, err := client.RecoveryCollectLocalReplicaInfo(ctx,
+				&serverpb.RecoveryCollectLocalReplicaInfoRequest{})
+			if err != nil {
+				return err
+			}
+			resp.Nodes = append(resp.Nodes, nodeResp.NodeReplicaInfo)
+			return nil
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			log.Warningf(ctx, "failed to collect replica info from n%d: %v", nodeID, err)
+			resp.UnreachableNodes = append(resp.UnreachableNodes, nodeID)
+		}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// StagePlan stages the plan in the request, or removes a previously staged
+// plan if the request contains none. If the request is for all nodes, the
+// plan is staged on every reachable node, and the response contains an error
+// for every node with updates in the plan on which it could not be staged.
+// Otherwise, the plan is staged on the local node only.
+func (s *Server) StagePlan(
+	ctx context.Context, req *serverpb.RecoveryStagePlanRequest,
+) (*serverpb.RecoveryStagePlanResponse, error) {
+	if !req.AllNodes {
+		if err := s.stageLocalPlan(ctx, req.Plan, req.ForcePlan); err != nil {
+			return nil, err
+		}
+		return &serverpb.RecoveryStagePlanResponse{}, nil
+	}
+
+	// Nodes which have updates in the plan must all stage it, or the recovery
+	// would only be partial. Other nodes are visited so that any plan staged on
+	// them previously is replaced, but they are expected to be unreachable if
+	// they are the dead nodes which caused the loss of quorum.
+	resp := &serverpb.RecoveryStagePlanResponse{}
+	planNodes := make(map[roachpb.NodeID]bool)
+	if req.Plan != nil {
+		for _, u := range req.Plan.Updates {
+			planNodes[u.NodeID()] = false
+		}
+	}
+	nodeReq := *req
+	nodeReq.AllNodes = false
+	if err := s.visitNodes(ctx,
+		func(nodeID roachpb.NodeID, client serverpb.AdminClient) error {
+			if _, err := client.RecoveryStagePlan(ctx, &nodeReq); err != nil {
+				resp.Errors = append(resp.Errors,
+					fmt.Sprintf("failed to stage plan on n%d: %v", nodeID, err))
+			}
+			if _, ok := planNodes[nodeID]; ok {
+				planNodes[nodeID] = true
+			}
+			return nil
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			log.Warningf(ctx, "failed to stage recovery plan on n%d: %v", nodeID, err)
+			if _, ok := planNodes[nodeID]; ok {
+				planNodes[nodeID] = true
+				resp.Errors = append(resp.Errors,
+					fmt.Sprintf("failed to stage plan on n%d: %v", nodeID, err))
+			}
+		}); err != nil {
+		return nil, err
+	}
+	for nodeID, visited := range planNodes {
+		if !visited {
+			resp.Errors = append(resp.Errors,
+				fmt.Sprintf("failed to stage plan on n%d: node not found in the cluster", nodeID))
+		}
+	}
+	sort.Strings(resp.Errors)
+	return resp, nil
+}
+
+// stageLocalPlan stages the plan on the local node if it has any updates for
+// the stores of the node. A plan with a different ID than the one already
+// staged is only accepted if forced. A nil plan removes the staged plan.
+func (s *Server) stageLocalPlan(
+	ctx context.Context, plan *loqrecoverypb.ReplicaUpdatePlan, force bool,
+) error {
+	nodeID := s.nodeIDContainer.Get()
+	if plan == nil {
+		log.Infof(ctx, "removing staged loss of quorum recovery plan")
+		return s.planStore.RemovePlan()
+	}
+
+	staged, ok, err := s.planStore.LoadPlan()
+	if err != nil {
+		return err
+	}
+	if ok && staged.PlanID != plan.PlanID && !force {
+		return errors.Errorf("plan %s is already staged on n%d", staged.PlanID, nodeID)
+	}
+
+	localStores := make(storeIDSet)
+	for _, eng := range s.engines {
+		ident, err := kvserver.ReadStoreIdent(ctx, eng)
+		if err != nil {
+			if errors.HasType(err, (*kvserver.NotBootstrappedError)(nil)) {
+				continue
+			}
+			return err
+		}
+		localStores[ident.StoreID] = struct{}{}
+	}
+	hasUpdates := false
+	for _, u := range plan.Updates {
+		if u.NodeID() != nodeID {
+			continue
+		}
+		if _, ok := localStores[u.StoreID()]; !ok {
+			return errors.Errorf("plan contains update for r%d on s%d which is not found on n%d",
+				u.RangeID, u.StoreID(), nodeID)
+		}
+		hasUpdates = true
+	}
+	if !hasUpdates {
+		// The node has nothing to do for this plan, but it may have a plan
+		// staged previously which was replaced.
+		return s.planStore.RemovePlan()
+	}
+	if err := s.planStore.SavePlan(*plan); err != nil {
+		return err
+	}
+	log.Infof(ctx, "staged loss of quorum recovery plan %s, it will be applied on node restart",
+		plan.PlanID)
+	return nil
+}
+
+// NodeStatus returns the recovery status of the local node.
+func (s *Server) NodeStatus(
+	ctx context.Context, _ *serverpb.RecoveryNodeStatusRequest,
+) (*serverpb.RecoveryNodeStatusResponse, error) {
+	status, err := nodeRecoveryStatus(s.nodeIDContainer.Get(), s.planStore)
+	if err != nil {
+		return nil, err
+	}
+	return &serverpb.RecoveryNodeStatusResponse{Status: status}, nil
+}
+
+// Verify returns the recovery status of all reachable nodes, along with all
+// ranges in the cluster which have not regained quorum. A range has quorum if
+// a majority of its voters are on nodes which could be reached.
+//
+// The health of ranges is determined from their descriptors in the meta
+// ranges. Recovery only rewrites the local descriptor of the designated
+// survivor, so a recovered range is reported as unavailable until it
+// up-replicates, which updates its meta descriptor.
+func (s *Server) Verify(
+	ctx context.Context, _ *serverpb.RecoveryVerifyRequest,
+) (*serverpb.RecoveryVerifyResponse, error) {
+	resp := &serverpb.RecoveryVerifyResponse{}
+	reachable := make(map[roachpb.NodeID]struct{})
+	if err := s.visitNodes(ctx,
+		func(nodeID roachpb.NodeID, client serverpb.AdminClient) error {
+			nodeResp, err := client.RecoveryNodeStatus(ctx, &serverpb.RecoveryNodeStatusRequest{})
+			if err != nil {
+				return err
+			}
+			reachable[nodeID] = struct{}{}
+			resp.Statuses = append(resp.Statuses, nodeResp.Status)
+			return nil
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			log.Warningf(ctx, "failed to retrieve recovery status of n%d: %v", nodeID, err)
+			resp.UnreachableNodes = append(resp.UnreachableNodes, nodeID)
+		}); err != nil {
+		return nil, err
+	}
+
+	isReachable := func(rd roachpb.ReplicaDescriptor) bool {
+		_, ok := reachable[rd.NodeID]
+		return ok
+	}
+	if err := contextutil.RunWithTimeout(ctx, "scan range descriptors", rangeHealthScanTimeout,
+		func(ctx context.Context) error {
+			kvs, err := s.db.Scan(ctx, keys.Meta2Prefix, keys.MetaMax, 0 /* maxRows */)
+			if err != nil {
+				return err
+			}
+			for _, metaKV := range kvs {
+				var desc roachpb.RangeDescriptor
+				if err := metaKV.ValueProto(&desc); err != nil {
+					return err
+				}
+				if health := checkRangeHealth(desc, isReachable); health != loqrecoverypb.RangeHealth_HEALTHY {
+					resp.UnavailableRanges = append(resp.UnavailableRanges, loqrecoverypb.RangeRecoveryStatus{
+						RangeID: desc.RangeID,
+						Span:    desc.RSpan().AsRawSpanWithNoLocals(),
+						Health:  health,
+					})
+				}
+			}
+			return nil
+		}); err != nil {
+		// The meta ranges could have lost quorum, in which case node statuses
+		// are still useful to check the progress of the recovery.
+		resp.UnavailableRanges = nil
+		resp.RangeCheckError = fmt.Sprintf("failed to read range descriptors: %v", err)
+	}
+	return resp, nil
+}
+
+// checkRangeHealth returns the health of the range with the given descriptor.
+func checkRangeHealth(
+	desc roachpb.RangeDescriptor, isLive func(roachpb.ReplicaDescriptor) bool,
+) loqrecoverypb.RangeHealth {
+	if desc.Replicas().CanMakeProgress(isLive) {
+		return loqrecoverypb.RangeHealth_HEALTHY
+	}
+	return loqrecoverypb.RangeHealth_LOSS_OF_QUORUM
+}
+
+// visitNodes calls visitor with a client for every node in the cluster known
+// to gossip, in ascending order of node ID. If a node could not be dialed or
+// visitor returns an error, onError is called instead.
+func (s *Server) visitNodes(
+	ctx context.Context,
+	visitor func(nodeID roachpb.NodeID, client serverpb.AdminClient) error,
+	onError func(nodeID roachpb.NodeID, err error),
+) error {
+	var nodes []roachpb.NodeDescriptor
+	if err := s.gossip.IterateInfos(gossip.KeyNodeIDPrefix, func(key string, i gossip.Info) error {
+		bytes, err := i.Value.GetBytes()
+		if err != nil {
+			return errors.NewAssertionErrorWithWrappedErrf(err,
+				"failed to extract bytes for key %q", key)
+		}
+		var d roachpb.NodeDescriptor
+		if err := protoutil.Unmarshal(bytes, &d); err != nil {
+			return errors.NewAssertionErrorWithWrappedErrf(err,
+				"failed to parse value for key %q", key)
+		}
+		// Node descriptors with NodeID 0 indicate that the node has been
+		// removed from the cluster.
+		if d.NodeID != 0 {
+			nodes = append(nodes, d)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeID < nodes[j].NodeID })
+
+	for _, node := range nodes {
+		var client serverpb.AdminClient
+		err := contextutil.RunWithTimeout(ctx, "dial node", base.NetworkTimeout,
+			func(ctx context.Context) error {
+				conn, err := s.rpcCtx.GRPCDialNode(node.Address.String(), node.NodeID,
+					rpc.DefaultClass).Connect(ctx)
+				if err != nil {
+					return err
+				}
+				client = serverpb.NewAdminClient(conn)
+				return nil
+			})
+		if err == nil {
+			err = visitor(node.NodeID, client)
+		}
+		if err != nil {
+			onError(node.NodeID, err)
+		}
+	}
+	return nil
+}
+
+// nodeRecoveryStatus returns the recovery status of a node from its plan
+// store.
+func nodeRecoveryStatus(
+	nodeID roachpb.NodeID, planStore PlanStore,
+) (loqrecoverypb.NodeRecoveryStatus, error) {
+	status := loqrecoverypb.NodeRecoveryStatus{NodeID: nodeID}
+	plan, ok, err := planStore.LoadPlan()
+	if err != nil {
+		return loqrecoverypb.NodeRecoveryStatus{}, err
+	}
+	if ok {
+		status.PendingPlanID = plan.PlanID
+	}
+	result, ok, err := planStore.LoadResult()
+	if err != nil {
+		return loqrecoverypb.NodeRecoveryStatus{}, err
+	}
+	if ok {
+		status.AppliedPlanID = result.AppliedPlanID
+		status.ApplyTimestamp = result.ApplyTimestamp
+		status.Error = result.Error
+	}
+	return status, nil
+}
+
+// ApplyStagedPlan applies the plan staged on the node, if any, to the replicas
+// in its engines, and records the outcome in the plan store. It must be called
+// on node startup before any stores are started.
+//
+// The staged plan is removed whether it applied successfully or not, so that
+// a plan which fails to apply doesn't prevent the node from starting. The
+// failure is reported by the recovery status of the node instead. An error is
+// only returned if the plan store could not be accessed.
+func ApplyStagedPlan(ctx context.Context, planStore PlanStore, engines []storage.Engine) error {
+	plan, ok, err := planStore.LoadPlan()
+	if err != nil || !ok {
+		return err
+	}
+
+	log.Infof(ctx, "applying staged loss of quorum recovery plan %s", plan.PlanID)
+	applyTime := timeutil.Now()
+	result := loqrecoverypb.PlanApplicationResult{
+		AppliedPlanID:  plan.PlanID,
+		ApplyTimestamp: &applyTime,
+	}
+	if err := applyPlan(ctx, plan, engines, applyTime); err != nil {
+		log.Errorf(ctx, "failed to apply staged loss of quorum recovery plan %s: %v", plan.PlanID, err)
+		result.Error = err.Error()
+	}
+	if err := planStore.SaveResult(result); err != nil {
+		return err
+	}
+	return planStore.RemovePlan()
+}
+
+func applyPlan(
+	ctx context.Context,
+	plan loqrecoverypb.ReplicaUpdatePlan,
+	engines []storage.Engine,
+	updateTime time.Time,
+) error {
+	var nodeID roachpb.NodeID
+	batches := make(map[roachpb.StoreID]storage.Batch)
+	for _, eng := range engines {
+		ident, err := kvserver.ReadStoreIdent(ctx, eng)
+		if err != nil {
+			if errors.HasType(err, (*kvserver.NotBootstrappedError)(nil)) {
+				continue
+			}
+			return err
+		}
+		nodeID = ident.NodeID
+		batch := eng.NewBatch()
+		defer batch.Close()
+		batches[ident.StoreID] = batch
+	}
+
+	report, err := PrepareUpdateReplicas(ctx, plan, uuid.DefaultGenerator, updateTime, nodeID, batches)
+	if err != nil {
+		return err
+	}
+	if len(report.MissingStores) > 0 {
+		missing := make(storeIDSet)
+		for _, id := range report.MissingStores {
+			missing[id] = struct{}{}
+		}
+		return errors.Errorf("stores %s expected on n%d are not found", joinStoreIDs(missing), nodeID)
+	}
+	for _, r := range report.SkippedReplicas {
+		log.Infof(ctx, "replica %s for range r%d is already updated", r.Replica, r.RangeID())
+	}
+	for _, r := range report.UpdatedReplicas {
+		log.Infof(ctx, "updating replica %s for range r%d to %s with peer replica(s) removed: %s",
+			r.OldReplica, r.RangeID(), r.Replica, r.RemovedReplicas)
+	}
+	_, err = CommitReplicaChanges(batches)
+	return err
+}
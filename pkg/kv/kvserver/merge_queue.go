@@ -278,7 +278,7 @@ func (mq *mergeQueue) process(
 	// Use a lower threshold for load based splitting so we don't find ourselves
 	// in a situation where we keep merging ranges that would be split soon after
 	// by a small increase in load.
-	conservativeLoadBasedSplitThreshold := 0.5 * lhsRepl.SplitByLoadQPSThreshold()
+	conservativeLoadBasedSplitThreshold := 0.5 * lhsRepl.SplitByLoadThreshold()
 	shouldSplit, _ := shouldSplitRange(ctx, mergedDesc, mergedStats,
 		lhsRepl.GetMaxBytes(), lhsRepl.shouldBackpressureWrites(), confReader)
 	if shouldSplit || mergedQPS >= conservativeLoadBasedSplitThreshold {
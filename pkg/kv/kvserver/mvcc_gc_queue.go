@@ -18,6 +18,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/gc"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/intentresolver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
@@ -479,15 +480,21 @@ func (r *replicaGCer) send(ctx context.Context, req roachpb.GCRequest) error {
 func (r *replicaGCer) SetGCThreshold(ctx context.Context, thresh gc.Threshold) error {
 	req := r.template()
 	req.Threshold = thresh.Key
+	req.Retention = thresh.Retention
 	return r.send(ctx, req)
 }
 
-func (r *replicaGCer) GC(ctx context.Context, keys []roachpb.GCRequest_GCKey) error {
-	if len(keys) == 0 {
+func (r *replicaGCer) GC(
+	ctx context.Context,
+	keys []roachpb.GCRequest_GCKey,
+	versionRanges []roachpb.GCRequest_GCVersionRange,
+) error {
+	if len(keys) == 0 && len(versionRanges) == 0 {
 		return nil
 	}
 	req := r.template()
 	req.Keys = keys
+	req.VersionRanges = versionRanges
 	return r.send(ctx, req)
 }
 
@@ -564,6 +571,20 @@ func (mgcq *mvccGCQueue) process(
 	maxIntentsPerCleanupBatch := gc.MaxIntentsPerCleanupBatch.Get(&repl.store.ClusterSettings().SV)
 	maxIntentKeyBytesPerCleanupBatch := gc.MaxIntentKeyBytesPerCleanupBatch.Get(&repl.store.ClusterSettings().SV)
 
+	// Downsample the history below the new threshold according to the retention
+	// tiers. The tiers are also recorded when they've been removed from the
+	// config, so that reads stop relying on them.
+	var retention *roachpb.GCRetention
+	tiers := conf.GCPolicy.RetentionTiers
+	prevRetention := repl.GetGCRetention()
+	if repl.ClusterSettings().Version.IsActive(ctx, clusterversion.MVCCGCRetentionTiers) &&
+		(len(tiers) > 0 || (prevRetention != nil && len(prevRetention.Tiers) > 0)) {
+		retention = &roachpb.GCRetention{
+			Threshold: gc.RetentionThreshold(tiers, gcTimestamp),
+			Tiers:     tiers,
+		}
+	}
+
 	info, err := gc.Run(ctx, desc, snap, gcTimestamp, newThreshold,
 		gc.RunOptions{
 			IntentAgeThreshold:                     intentAgeThreshold,
@@ -571,6 +592,7 @@ func (mgcq *mvccGCQueue) process(
 			MaxIntentKeyBytesPerIntentCleanupBatch: maxIntentKeyBytesPerCleanupBatch,
 			MaxTxnsPerIntentCleanupBatch:           intentresolver.MaxTxnsPerIntentCleanupBatch,
 			IntentCleanupBatchTimeout:              mvccGCQueueIntentBatchTimeout,
+			Retention:                              retention,
 		},
 		conf.TTL(),
 		&replicaGCer{
@@ -12,6 +12,7 @@ package rangefeed
 
 import (
 	"bytes"
+	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
@@ -46,21 +47,50 @@ func (i simpleCatchupIterAdapter) NextIgnoringTime() {
 
 var _ simpleCatchupIter = simpleCatchupIterAdapter{}
 
+// CatchUpPacer paces a catch-up scan so that it doesn't starve foreground
+// work of resources.
+type CatchUpPacer interface {
+	// Pace is called before the catch-up scan reads its first key, and then
+	// periodically while it runs, with the number of bytes the scan read since
+	// the previous call. It blocks until the scan is admitted to continue, which
+	// gives the scan the opportunity to yield to other work.
+	Pace(ctx context.Context, readBytes int64) error
+	// Release releases whatever was acquired by the last call to Pace. It is
+	// called before the catch-up scan blocks on sending events, so that a slow
+	// consumer doesn't hold up the work the scan was admitted ahead of, and once
+	// the scan is done. It is a no-op if nothing is held.
+	Release()
+}
+
+// catchUpScanPaceKeys is the number of keys a catch-up scan iterates over
+// between calls to CatchUpPacer.Pace.
+const catchUpScanPaceKeys = 128
+
+// catchUpScanBufferBytes is the number of bytes of events a paced catch-up scan
+// buffers before it releases its admission and sends them.
+const catchUpScanBufferBytes = 256 << 10 // 256 KiB
+
 // CatchUpIterator is an iterator for catchup-scans.
 type CatchUpIterator struct {
 	simpleCatchupIter
 	close     func()
+	pacer     CatchUpPacer
 	span      roachpb.Span
 	startTime hlc.Timestamp // exclusive
 }
 
 // NewCatchUpIterator returns a CatchUpIterator for the given Reader over the
-// given key/time span. startTime is exclusive.
+// given key/time span. startTime is exclusive. The pacer, if non-nil, is used
+// to pace the catch-up scan and is closed with the iterator.
 //
 // NB: startTime is exclusive, i.e. the first possible event will be emitted at
 // Timestamp.Next().
 func NewCatchUpIterator(
-	reader storage.Reader, span roachpb.Span, startTime hlc.Timestamp, closer func(),
+	reader storage.Reader,
+	span roachpb.Span,
+	startTime hlc.Timestamp,
+	closer func(),
+	pacer CatchUpPacer,
 ) *CatchUpIterator {
 	return &CatchUpIterator{
 		simpleCatchupIter: storage.NewMVCCIncrementalIterator(reader,
@@ -75,20 +105,35 @@ func NewCatchUpIterator(
 				IntentPolicy: storage.MVCCIncrementalIterIntentPolicyEmit,
 			}),
 		close:     closer,
+		pacer:     pacer,
 		span:      span,
 		startTime: startTime,
 	}
 }
 
-// Close closes the iterator and calls the instantiator-supplied close
-// callback.
+// Close closes the iterator and the pacer, and calls the
+// instantiator-supplied close callback.
 func (i *CatchUpIterator) Close() {
 	i.simpleCatchupIter.Close()
+	i.release()
 	if i.close != nil {
 		i.close()
 	}
 }
 
+func (i *CatchUpIterator) pace(ctx context.Context, readBytes int64) error {
+	if i.pacer == nil {
+		return nil
+	}
+	return i.pacer.Pace(ctx, readBytes)
+}
+
+func (i *CatchUpIterator) release() {
+	if i.pacer != nil {
+		i.pacer.Release()
+	}
+}
+
 // TODO(ssd): Clarify memory ownership. Currently, the memory backing
 // the RangeFeedEvents isn't modified by the caller after this
 // returns. However, we may revist this in #69596.
@@ -96,7 +141,9 @@ type outputEventFn func(e *roachpb.RangeFeedEvent) error
 
 // CatchUpScan iterates over all changes in the configured key/time span, and
 // emits them as RangeFeedEvents via outputFn in chronological order.
-func (i *CatchUpIterator) CatchUpScan(outputFn outputEventFn, withDiff bool) error {
+func (i *CatchUpIterator) CatchUpScan(
+	ctx context.Context, outputFn outputEventFn, withDiff bool,
+) error {
 	var a bufalloc.ByteAllocator
 	// MVCCIterator will encounter historical values for each key in
 	// reverse-chronological order. To output in chronological order, store
@@ -116,10 +163,30 @@ func (i *CatchUpIterator) CatchUpScan(outputFn outputEventFn, withDiff bool) err
 		}
 	}
 
+	// A paced scan doesn't send events while it is admitted, since sending may
+	// block on the consumer for arbitrarily long. Instead, it buffers them until
+	// catchUpScanBufferBytes have accumulated, and then releases its admission
+	// and sends them.
+	paced := i.pacer != nil
+	var pending []roachpb.RangeFeedEvent
+	var pendingBytes int
+	sendPending := func() error {
+		for i := range pending {
+			if err := outputFn(&pending[i]); err != nil {
+				return err
+			}
+			pending[i] = roachpb.RangeFeedEvent{} // Drop references to values to allow GC
+		}
+		pending, pendingBytes = pending[:0], 0
+		return nil
+	}
 	outputEvents := func() error {
 		for i := len(reorderBuf) - 1; i >= 0; i-- {
 			e := reorderBuf[i]
-			if err := outputFn(&e); err != nil {
+			if paced {
+				pending = append(pending, e)
+				pendingBytes += e.Size()
+			} else if err := outputFn(&e); err != nil {
 				return err
 			}
 			reorderBuf[i] = roachpb.RangeFeedEvent{} // Drop references to values to allow GC
@@ -131,6 +198,11 @@ func (i *CatchUpIterator) CatchUpScan(outputFn outputEventFn, withDiff bool) err
 	// versions of each key that are after the registration's startTS, so we
 	// can't use NextKey.
 	var meta enginepb.MVCCMetadata
+	var keysSincePace int
+	var readBytes int64
+	if err := i.pace(ctx, 0 /* readBytes */); err != nil {
+		return err
+	}
 	i.SeekGE(storage.MVCCKey{Key: i.span.Key})
 	for {
 		if ok, err := i.Valid(); err != nil {
@@ -141,6 +213,7 @@ func (i *CatchUpIterator) CatchUpScan(outputFn outputEventFn, withDiff bool) err
 
 		unsafeKey := i.UnsafeKey()
 		unsafeValRaw := i.UnsafeValue()
+		readBytes += int64(len(unsafeKey.Key) + len(unsafeValRaw))
 		if !unsafeKey.IsValue() {
 			// Found a metadata key.
 			if err := protoutil.Unmarshal(unsafeValRaw, &meta); err != nil {
@@ -197,6 +270,23 @@ func (i *CatchUpIterator) CatchUpScan(outputFn outputEventFn, withDiff bool) err
 			if err := outputEvents(); err != nil {
 				return err
 			}
+			flush := pendingBytes >= catchUpScanBufferBytes
+			if flush {
+				i.release()
+				if err := sendPending(); err != nil {
+					return err
+				}
+			}
+			// The scan must be readmitted after flushing before it reads on.
+			if keysSincePace++; flush || keysSincePace == catchUpScanPaceKeys {
+				keysSincePace = 0
+				if err := i.pace(ctx, readBytes); err != nil {
+					return err
+				}
+				readBytes = 0
+			}
+			// NB: pacing and sending may have blocked, but the iterator's position
+			// and the unsafe key and value it exposes are unaffected.
 			a, lastKey = a.Copy(unsafeKey.Key, 0)
 		}
 		key := lastKey
@@ -253,5 +343,9 @@ func (i *CatchUpIterator) CatchUpScan(outputFn outputEventFn, withDiff bool) err
 	}
 
 	// Output events for the last key encountered.
-	return outputEvents()
+	if err := outputEvents(); err != nil {
+		return err
+	}
+	i.release()
+	return sendPending()
 }
@@ -34,7 +34,8 @@ import (
 )
 
 func runCatchUpBenchmark(b *testing.B, emk engineMaker, opts benchOptions) {
-	eng, _ := setupData(context.Background(), b, emk, opts.dataOpts)
+	ctx := context.Background()
+	eng, _ := setupData(ctx, b, emk, opts.dataOpts)
 	defer eng.Close()
 	startKey := roachpb.Key(encoding.EncodeUvarintAscending([]byte("key-"), uint64(0)))
 	endKey := roachpb.Key(encoding.EncodeUvarintAscending([]byte("key-"), uint64(opts.dataOpts.numKeys)))
@@ -46,10 +47,10 @@ func runCatchUpBenchmark(b *testing.B, emk engineMaker, opts benchOptions) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		func() {
-			iter := rangefeed.NewCatchUpIterator(eng, span, opts.ts, nil)
+			iter := rangefeed.NewCatchUpIterator(eng, span, opts.ts, nil, nil)
 			defer iter.Close()
 			counter := 0
-			err := iter.CatchUpScan(func(*roachpb.RangeFeedEvent) error {
+			err := iter.CatchUpScan(ctx, func(*roachpb.RangeFeedEvent) error {
 				counter++
 				return nil
 			}, opts.withDiff)
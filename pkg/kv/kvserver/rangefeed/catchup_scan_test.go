@@ -11,7 +11,9 @@
 package rangefeed
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -94,11 +96,11 @@ func TestCatchupScan(t *testing.T) {
 	}
 	testutils.RunTrueAndFalse(t, "withDiff", func(t *testing.T, withDiff bool) {
 		span := roachpb.Span{Key: testKey1, EndKey: roachpb.KeyMax}
-		iter := NewCatchUpIterator(eng, span, ts1, nil)
+		iter := NewCatchUpIterator(eng, span, ts1, nil, nil)
 		defer iter.Close()
 		var events []roachpb.RangeFeedValue
 		// ts1 here is exclusive, so we do not want the versions at ts1.
-		require.NoError(t, iter.CatchUpScan(func(e *roachpb.RangeFeedEvent) error {
+		require.NoError(t, iter.CatchUpScan(ctx, func(e *roachpb.RangeFeedEvent) error {
 			events = append(events, *e.Val)
 			return nil
 		}, withDiff))
@@ -137,10 +139,86 @@ func TestCatchupScanInlineError(t *testing.T) {
 
 	// Run a catchup scan across the span and watch it error.
 	span := roachpb.Span{Key: keys.LocalMax, EndKey: keys.MaxKey}
-	iter := NewCatchUpIterator(eng, span, hlc.Timestamp{}, nil)
+	iter := NewCatchUpIterator(eng, span, hlc.Timestamp{}, nil, nil)
 	defer iter.Close()
 
-	err := iter.CatchUpScan(nil, false)
+	err := iter.CatchUpScan(ctx, nil, false)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "unexpected inline value")
 }
+
+type testCatchUpPacer struct {
+	paced, released int
+	readBytes       int64
+	admitted        bool
+}
+
+func (p *testCatchUpPacer) Pace(_ context.Context, readBytes int64) error {
+	p.paced++
+	p.readBytes += readBytes
+	p.admitted = true
+	return nil
+}
+
+func (p *testCatchUpPacer) Release() {
+	if p.admitted {
+		p.released++
+	}
+	p.admitted = false
+}
+
+func TestCatchupScanPacing(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name      string
+		numKeys   int
+		valueSize int
+		// expPaced and expReleased are the number of times the scan is expected
+		// to be paced and to release its admission.
+		expPaced, expReleased int
+	}{
+		// The scan is paced once before it starts, and then every
+		// catchUpScanPaceKeys keys. It only sends its events once it's done.
+		{name: "small values", numKeys: 2*catchUpScanPaceKeys + 1, valueSize: 3,
+			expPaced: 3, expReleased: 1},
+		// The scan releases its admission to send its events whenever it has
+		// buffered catchUpScanBufferBytes of them, and is paced again before it
+		// reads on.
+		{name: "large values", numKeys: 8, valueSize: 100 << 10,
+			expPaced: 3, expReleased: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := storage.NewDefaultInMemForTesting()
+			defer eng.Close()
+
+			val := roachpb.MakeValueFromBytes(bytes.Repeat([]byte("v"), tc.valueSize))
+			for i := 0; i < tc.numKeys; i++ {
+				key := roachpb.Key(fmt.Sprintf("key-%04d", i))
+				require.NoError(t, storage.MVCCPut(ctx, eng, nil, key, hlc.Timestamp{WallTime: 1},
+					hlc.ClockTimestamp{}, val, nil))
+			}
+
+			var pacer testCatchUpPacer
+			span := roachpb.Span{Key: roachpb.Key("key-"), EndKey: roachpb.Key("key-").PrefixEnd()}
+			iter := NewCatchUpIterator(eng, span, hlc.Timestamp{}, nil, &pacer)
+			var events int
+			require.NoError(t, iter.CatchUpScan(ctx, func(*roachpb.RangeFeedEvent) error {
+				// The scan must not hold on to its admission while sending.
+				require.False(t, pacer.admitted)
+				events++
+				return nil
+			}, false /* withDiff */))
+			require.Equal(t, tc.numKeys, events)
+			require.Equal(t, tc.expPaced, pacer.paced)
+			require.Equal(t, tc.expReleased, pacer.released)
+			require.Positive(t, pacer.readBytes)
+
+			// Closing the iterator releases nothing more.
+			iter.Close()
+			require.Equal(t, tc.expReleased, pacer.released)
+		})
+	}
+}
@@ -25,6 +25,18 @@ var (
 		Measurement: "Nanoseconds",
 		Unit:        metric.Unit_NANOSECONDS,
 	}
+	metaRangeFeedCatchUpScanAdmissionWaitNanos = metric.Metadata{
+		Name:        "kv.rangefeed.catchup_scan_admission_wait_nanos",
+		Help:        "Time spent by RangeFeed catchup scans queued in admission control",
+		Measurement: "Nanoseconds",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaRangeFeedCatchUpScanYields = metric.Metadata{
+		Name:        "kv.rangefeed.catchup_scan_yields",
+		Help:        "Number of times RangeFeed catchup scans yielded to other work and were readmitted by admission control",
+		Measurement: "Events",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaRangeFeedExhausted = metric.Metadata{
 		Name:        "kv.rangefeed.budget_allocation_failed",
 		Help:        "Number of times RangeFeed failed because memory budget was exceeded",
@@ -37,13 +49,29 @@ var (
 		Measurement: "Events",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRangeFeedFilteredEvents = metric.Metadata{
+		Name:        "kv.rangefeed.filtered_events",
+		Help:        "Number of RangeFeed value events not sent because they did not match the filter of their registration",
+		Measurement: "Events",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRangeFeedFilteredBytes = metric.Metadata{
+		Name:        "kv.rangefeed.filtered_bytes",
+		Help:        "Number of bytes of RangeFeed value events not sent because they did not match the filter of their registration",
+		Measurement: "Memory",
+		Unit:        metric.Unit_BYTES,
+	}
 )
 
 // Metrics are for production monitoring of RangeFeeds.
 type Metrics struct {
-	RangeFeedCatchUpScanNanos *metric.Counter
-	RangeFeedBudgetExhausted  *metric.Counter
-	RangeFeedBudgetBlocked    *metric.Counter
+	RangeFeedCatchUpScanNanos              *metric.Counter
+	RangeFeedCatchUpScanAdmissionWaitNanos *metric.Counter
+	RangeFeedCatchUpScanYields             *metric.Counter
+	RangeFeedBudgetExhausted               *metric.Counter
+	RangeFeedBudgetBlocked                 *metric.Counter
+	RangeFeedFilteredEvents                *metric.Counter
+	RangeFeedFilteredBytes                 *metric.Counter
 
 	RangeFeedSlowClosedTimestampLogN  log.EveryN
 	RangeFeedSlowClosedTimestampNudge singleflight.Group
@@ -60,11 +88,15 @@ func (*Metrics) MetricStruct() {}
 // NewMetrics makes the metrics for RangeFeeds monitoring.
 func NewMetrics() *Metrics {
 	return &Metrics{
-		RangeFeedCatchUpScanNanos:            metric.NewCounter(metaRangeFeedCatchUpScanNanos),
-		RangeFeedBudgetExhausted:             metric.NewCounter(metaRangeFeedExhausted),
-		RangeFeedBudgetBlocked:               metric.NewCounter(metaRangeFeedBudgetBlocked),
-		RangeFeedSlowClosedTimestampLogN:     log.Every(5 * time.Second),
-		RangeFeedSlowClosedTimestampNudgeSem: make(chan struct{}, 1024),
+		RangeFeedCatchUpScanNanos:              metric.NewCounter(metaRangeFeedCatchUpScanNanos),
+		RangeFeedCatchUpScanAdmissionWaitNanos: metric.NewCounter(metaRangeFeedCatchUpScanAdmissionWaitNanos),
+		RangeFeedCatchUpScanYields:             metric.NewCounter(metaRangeFeedCatchUpScanYields),
+		RangeFeedBudgetExhausted:               metric.NewCounter(metaRangeFeedExhausted),
+		RangeFeedBudgetBlocked:                 metric.NewCounter(metaRangeFeedBudgetBlocked),
+		RangeFeedFilteredEvents:                metric.NewCounter(metaRangeFeedFilteredEvents),
+		RangeFeedFilteredBytes:                 metric.NewCounter(metaRangeFeedFilteredBytes),
+		RangeFeedSlowClosedTimestampLogN:       log.Every(5 * time.Second),
+		RangeFeedSlowClosedTimestampNudgeSem:   make(chan struct{}, 1024),
 	}
 }
 
@@ -437,6 +437,9 @@ func (p *Processor) sendStop(pErr *roachpb.Error) {
 // The optionally provided "catch-up" iterator is used to read changes from the
 // engine which occurred after the provided start timestamp (exclusive).
 //
+// The optionally provided filter restricts the RangeFeedValue events sent to
+// the registration, both by the catch-up scan and afterwards.
+//
 // If the method returns false, the processor will have been stopped, so calling
 // Stop is not necessary. If the method returns true, it will also return an
 // updated operation filter that includes the operations required by the new
@@ -450,6 +453,7 @@ func (p *Processor) Register(
 	startTS hlc.Timestamp,
 	catchUpIterConstructor CatchUpIteratorConstructor,
 	withDiff bool,
+	filter *roachpb.RangeFeedFilter,
 	stream Stream,
 	errC chan<- *roachpb.Error,
 ) (bool, *Filter) {
@@ -459,7 +463,7 @@ func (p *Processor) Register(
 	p.syncEventC()
 
 	r := newRegistration(
-		span.AsRawSpanWithNoLocals(), startTS, catchUpIterConstructor, withDiff,
+		span.AsRawSpanWithNoLocals(), startTS, catchUpIterConstructor, withDiff, filter,
 		p.Config.EventChanCap, p.Metrics, stream, errC,
 	)
 	select {
@@ -77,6 +77,7 @@ type registration struct {
 	span             roachpb.Span
 	catchUpTimestamp hlc.Timestamp // exclusive
 	withDiff         bool
+	filter           *valueFilter
 	metrics          *Metrics
 
 	// catchUpIterConstructor is used to construct the catchUpIter if necessary.
@@ -122,6 +123,7 @@ func newRegistration(
 	startTS hlc.Timestamp,
 	catchUpIterConstructor CatchUpIteratorConstructor,
 	withDiff bool,
+	filter *roachpb.RangeFeedFilter,
 	bufferSz int,
 	metrics *Metrics,
 	stream Stream,
@@ -132,6 +134,7 @@ func newRegistration(
 		catchUpTimestamp:       startTS,
 		catchUpIterConstructor: catchUpIterConstructor,
 		withDiff:               withDiff,
+		filter:                 newValueFilter(filter),
 		metrics:                metrics,
 		stream:                 stream,
 		errC:                   errC,
@@ -170,6 +173,18 @@ func (r *registration) publish(
 	}
 }
 
+// filtered returns whether the event is a RangeFeedValue which does not match
+// the filter of the registration, and must thus not be sent to it.
+func (r *registration) filtered(event *roachpb.RangeFeedEvent) bool {
+	t, ok := event.GetValue().(*roachpb.RangeFeedValue)
+	if !ok || r.filter.matches(t.Key) {
+		return false
+	}
+	r.metrics.RangeFeedFilteredEvents.Inc(1)
+	r.metrics.RangeFeedFilteredBytes.Inc(int64(t.Size()))
+	return true
+}
+
 // validateEvent checks that the event contains enough information for the
 // registation.
 func (r *registration) validateEvent(event *roachpb.RangeFeedEvent) {
@@ -287,7 +302,7 @@ func (r *registration) disconnect(pErr *roachpb.Error) {
 // have been emitted.
 func (r *registration) outputLoop(ctx context.Context) error {
 	// If the registration has a catch-up scan, run it.
-	if err := r.maybeRunCatchUpScan(); err != nil {
+	if err := r.maybeRunCatchUpScan(ctx); err != nil {
 		err = errors.Wrap(err, "catch-up scan failed")
 		log.Errorf(ctx, "%v", err)
 		return err
@@ -359,7 +374,7 @@ func (r *registration) drainAllocations(ctx context.Context) {
 //
 // If the registration does not have a catchUpIteratorConstructor, this method
 // is a no-op.
-func (r *registration) maybeRunCatchUpScan() error {
+func (r *registration) maybeRunCatchUpScan(ctx context.Context) error {
 	catchUpIter := r.detachCatchUpIter()
 	if catchUpIter == nil {
 		return nil
@@ -370,7 +385,16 @@ func (r *registration) maybeRunCatchUpScan() error {
 		r.metrics.RangeFeedCatchUpScanNanos.Inc(timeutil.Since(start).Nanoseconds())
 	}()
 
-	return catchUpIter.CatchUpScan(r.stream.Send, r.withDiff)
+	outputFn := r.stream.Send
+	if r.filter != nil {
+		outputFn = func(e *roachpb.RangeFeedEvent) error {
+			if r.filtered(e) {
+				return nil
+			}
+			return r.stream.Send(e)
+		}
+	}
+	return catchUpIter.CatchUpScan(ctx, outputFn, r.withDiff)
 }
 
 // ID implements interval.Interface.
@@ -453,8 +477,9 @@ func (reg *registry) PublishToOverlapping(
 
 	reg.forOverlappingRegs(span, func(r *registration) (bool, *roachpb.Error) {
 		// Don't publish events if they are equal to or less
-		// than the registration's starting timestamp.
-		if r.catchUpTimestamp.Less(minTS) {
+		// than the registration's starting timestamp, or if they
+		// don't match the registration's filter.
+		if r.catchUpTimestamp.Less(minTS) && !r.filtered(event) {
 			r.publish(ctx, event, allocation)
 		}
 		return false, nil
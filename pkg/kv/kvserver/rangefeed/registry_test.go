@@ -119,6 +119,7 @@ func newTestRegistration(
 		ts,
 		makeCatchUpIteratorConstructor(catchup),
 		withDiff,
+		nil, /* filter */
 		5,
 		NewMetrics(),
 		s,
@@ -278,7 +279,7 @@ func TestRegistrationCatchUpScan(t *testing.T) {
 	}, hlc.Timestamp{WallTime: 4}, iter, true /* withDiff */)
 
 	require.Zero(t, r.metrics.RangeFeedCatchUpScanNanos.Count())
-	require.NoError(t, r.maybeRunCatchUpScan())
+	require.NoError(t, r.maybeRunCatchUpScan(context.Background()))
 	require.True(t, iter.closed)
 	require.NotZero(t, r.metrics.RangeFeedCatchUpScanNanos.Count())
 
@@ -557,6 +558,59 @@ func TestRegistryPublishBeneathStartTimestamp(t *testing.T) {
 	<-r.errC
 }
 
+func TestRegistryPublishFiltered(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	reg := makeRegistry()
+
+	r := newTestRegistration(spAC, hlc.Timestamp{}, nil, false)
+	r.filter = newValueFilter(&roachpb.RangeFeedFilter{KeyPrefixes: []roachpb.Key{keyB}})
+	go r.runOutputLoop(context.Background(), 0)
+	reg.Register(&r.registration)
+
+	val := roachpb.Value{RawBytes: []byte("val"), Timestamp: hlc.Timestamp{WallTime: 1}}
+	evA, evB := new(roachpb.RangeFeedEvent), new(roachpb.RangeFeedEvent)
+	evA.MustSetValue(&roachpb.RangeFeedValue{Key: keyA, Value: val})
+	evB.MustSetValue(&roachpb.RangeFeedValue{Key: keyB.Next(), Value: val})
+	evCheckpoint := new(roachpb.RangeFeedEvent)
+	evCheckpoint.MustSetValue(&roachpb.RangeFeedCheckpoint{
+		Span: spAC, ResolvedTS: hlc.Timestamp{WallTime: 1},
+	})
+
+	// Only the value matching the filter and the checkpoint are delivered.
+	reg.PublishToOverlapping(ctx, spAC, evA, nil /* allocation */)
+	reg.PublishToOverlapping(ctx, spAC, evB, nil /* allocation */)
+	reg.PublishToOverlapping(ctx, spAC, evCheckpoint, nil /* allocation */)
+	require.NoError(t, reg.waitForCaughtUp(all))
+	require.Equal(t, []*roachpb.RangeFeedEvent{evB, evCheckpoint}, r.Events())
+	require.Equal(t, int64(1), r.metrics.RangeFeedFilteredEvents.Count())
+	require.Equal(t, int64(evA.GetValue().(*roachpb.RangeFeedValue).Size()),
+		r.metrics.RangeFeedFilteredBytes.Count())
+
+	r.disconnect(nil)
+	<-r.errC
+}
+
+func TestRegistrationCatchUpScanFiltered(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := newTestRegistration(spAC, hlc.Timestamp{WallTime: 1},
+		newTestIterator([]storage.MVCCKeyValue{
+			makeKV("a", "valA", 10),
+			makeKV("b", "valB", 10),
+			makeKV("bb", "valBB", 11),
+		}, nil), false)
+	r.filter = newValueFilter(&roachpb.RangeFeedFilter{KeyPrefixes: []roachpb.Key{keyB}})
+	require.NoError(t, r.maybeRunCatchUpScan(context.Background()))
+
+	var evKeys []string
+	for _, ev := range r.Events() {
+		evKeys = append(evKeys, string(ev.Val.Key))
+	}
+	require.Equal(t, []string{"b", "bb"}, evKeys)
+	require.Equal(t, int64(1), r.metrics.RangeFeedFilteredEvents.Count())
+}
+
 func TestRegistrationString(t *testing.T) {
 	testCases := []struct {
 		r   registration
@@ -91,6 +91,8 @@ type IntentScanner interface {
 //
 //  - The EngineIterator must have an UpperBound set.
 //  - The range must be using separated intents.
+//  - The EngineIterator must only surface intents, and not the replicated
+//    Shared locks in the lock table (see storage.NewLockTableIterator).
 type SeparatedIntentScanner struct {
 	iter storage.EngineIterator
 }
@@ -0,0 +1,85 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rangefeed
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+)
+
+// valueFilter is the compiled form of the roachpb.RangeFeedFilter of a
+// registration. It determines which RangeFeedValue events are published to
+// the registration. A nil *valueFilter matches all events.
+type valueFilter struct {
+	// prefixes is sorted, and no prefix is a prefix of another, so that a key
+	// can only have the largest prefix that sorts before it.
+	prefixes []roachpb.Key
+	families map[uint32]struct{}
+}
+
+// newValueFilter compiles the provided filter, returning nil if the filter
+// matches all events.
+func newValueFilter(f *roachpb.RangeFeedFilter) *valueFilter {
+	if f == nil || (len(f.KeyPrefixes) == 0 && len(f.FamilyIDs) == 0) {
+		return nil
+	}
+	vf := &valueFilter{}
+	if len(f.KeyPrefixes) > 0 {
+		prefixes := make([]roachpb.Key, len(f.KeyPrefixes))
+		copy(prefixes, f.KeyPrefixes)
+		sort.Slice(prefixes, func(i, j int) bool {
+			return prefixes[i].Compare(prefixes[j]) < 0
+		})
+		// Drop the prefixes which are covered by a shorter one. As the prefixes
+		// are sorted, the shorter one always precedes them.
+		for _, p := range prefixes {
+			if n := len(vf.prefixes); n > 0 && bytes.HasPrefix(p, vf.prefixes[n-1]) {
+				continue
+			}
+			vf.prefixes = append(vf.prefixes, p)
+		}
+	}
+	if len(f.FamilyIDs) > 0 {
+		vf.families = make(map[uint32]struct{}, len(f.FamilyIDs))
+		for _, id := range f.FamilyIDs {
+			vf.families[id] = struct{}{}
+		}
+	}
+	return vf
+}
+
+// matches returns whether an event on the provided key passes the filter.
+func (f *valueFilter) matches(key roachpb.Key) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.prefixes) > 0 {
+		i := sort.Search(len(f.prefixes), func(i int) bool {
+			return f.prefixes[i].Compare(key) > 0
+		})
+		if i == 0 || !bytes.HasPrefix(key, f.prefixes[i-1]) {
+			return false
+		}
+	}
+	if len(f.families) > 0 {
+		// Keys which don't encode a column family, such as those of non-SQL
+		// data, are not filtered by family.
+		if famID, err := keys.DecodeFamilyKey(key); err == nil {
+			if _, ok := f.families[famID]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
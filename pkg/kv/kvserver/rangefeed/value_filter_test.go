@@ -0,0 +1,109 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rangefeed
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	codec := keys.MakeSQLCodec(roachpb.MakeTenantID(5))
+	rowKey := func(tableID uint32, famID uint32) roachpb.Key {
+		k := encoding.EncodeVarintAscending(codec.IndexPrefix(tableID, 1), 42)
+		return keys.MakeFamilyKey(k, famID)
+	}
+	// A key under an index which does not end with a column family suffix.
+	noFamilyKey := encoding.EncodeVarintAscending(codec.IndexPrefix(104, 2), 42)
+
+	for _, tc := range []struct {
+		name    string
+		filter  *roachpb.RangeFeedFilter
+		matches map[string]bool
+	}{
+		{
+			name:   "nil",
+			filter: nil,
+			matches: map[string]bool{
+				"a":                           true,
+				string(rowKey(104, 1)):        true,
+				string(noFamilyKey):           true,
+				string(codec.TablePrefix(10)): true,
+			},
+		},
+		{
+			name:   "empty",
+			filter: &roachpb.RangeFeedFilter{},
+			matches: map[string]bool{
+				"a":                    true,
+				string(rowKey(104, 1)): true,
+			},
+		},
+		{
+			name: "prefixes",
+			filter: &roachpb.RangeFeedFilter{KeyPrefixes: []roachpb.Key{
+				roachpb.Key("ca"), roachpb.Key("a"), roachpb.Key("ab"), roachpb.Key("c"), roachpb.Key("e"),
+			}},
+			matches: map[string]bool{
+				"":   false,
+				"a":  true,
+				"ab": true,
+				"ac": true,
+				"b":  false,
+				"c":  true,
+				"cb": true,
+				"d":  false,
+				"ea": true,
+				"f":  false,
+			},
+		},
+		{
+			name:   "families",
+			filter: &roachpb.RangeFeedFilter{FamilyIDs: []uint32{0, 2}},
+			matches: map[string]bool{
+				string(rowKey(104, 0)):        true,
+				string(rowKey(104, 1)):        false,
+				string(rowKey(104, 2)):        true,
+				string(rowKey(105, 3)):        false,
+				string(noFamilyKey):           true,
+				string(codec.TablePrefix(10)): true,
+				"a":                           true,
+			},
+		},
+		{
+			name: "prefixes and families",
+			filter: &roachpb.RangeFeedFilter{
+				KeyPrefixes: []roachpb.Key{codec.TablePrefix(104)},
+				FamilyIDs:   []uint32{1},
+			},
+			matches: map[string]bool{
+				string(rowKey(104, 0)): false,
+				string(rowKey(104, 1)): true,
+				string(rowKey(105, 1)): false,
+				string(noFamilyKey):    true,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newValueFilter(tc.filter)
+			for key, exp := range tc.matches {
+				require.Equal(t, exp, f.matches(roachpb.Key(key)), "%s", roachpb.Key(key))
+			}
+		})
+	}
+}
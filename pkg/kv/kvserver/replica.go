@@ -246,6 +246,11 @@ type Replica struct {
 	// loadStats tracks a sliding window of throughput on this replica.
 	loadStats *ReplicaLoad
 
+	// requestCPUSamples counts the batches evaluated on this replica, to decide
+	// which of them have their CPU time measured. Accessed atomically. See
+	// requestCPUTimer.
+	requestCPUSamples uint32
+
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
 	creatingReplica *roachpb.ReplicaDescriptor
@@ -915,6 +920,14 @@ func (r *Replica) GetGCThreshold() hlc.Timestamp {
 	return *r.mu.state.GCThreshold
 }
 
+// GetGCRetention returns the MVCC retention tiers recorded by the last GC, or
+// nil if none were ever recorded.
+func (r *Replica) GetGCRetention() *roachpb.GCRetention {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.mu.state.GCRetention
+}
+
 // ExcludeDataFromBackup returns whether the replica is to be excluded from a
 // backup.
 func (r *Replica) ExcludeDataFromBackup() bool {
@@ -1486,12 +1499,28 @@ func (r *Replica) checkGCThresholdAndLeaseRLocked(
 	// this uses the lease status no matter whether it's valid or not, and the
 	// method is set up to handle that.
 	if err := r.checkTSAboveGCThresholdRLocked(ba.EarliestActiveTimestamp(), st, ba.IsAdmin()); err != nil {
-		return kvserverpb.LeaseStatus{}, false, err
+		if !r.canServeRetainedReadRLocked(ba) {
+			return kvserverpb.LeaseStatus{}, false, err
+		}
 	}
 
 	return st, shouldExtend, nil
 }
 
+// canServeRetainedReadRLocked returns whether the batch reads exactly at a
+// timestamp below the GC threshold that the range's retention tiers retain.
+// The history below the GC threshold is downsampled such that only the values
+// visible at the retained timestamps are preserved, so only read-only batches
+// which don't observe any history before their timestamp can be served.
+func (r *Replica) canServeRetainedReadRLocked(ba *roachpb.BatchRequest) bool {
+	ret := r.mu.state.GCRetention
+	if ret == nil || !ba.IsReadOnly() || ba.EarliestActiveTimestamp() != ba.Timestamp {
+		return false
+	}
+	return ret.Threshold.Less(ba.Timestamp) &&
+		gc.IsRetainedTimestamp(ret.Tiers, r.Clock().Now(), ba.Timestamp)
+}
+
 // checkExecutionCanProceedForRangeFeed returns an error if a rangefeed request
 // cannot be executed by the Replica.
 func (r *Replica) checkExecutionCanProceedForRangeFeed(
@@ -318,6 +318,12 @@ func (r *Replica) handleGCThresholdResult(ctx context.Context, thresh *hlc.Times
 	r.mu.Unlock()
 }
 
+func (r *Replica) handleGCRetentionResult(ctx context.Context, retention *roachpb.GCRetention) {
+	r.mu.Lock()
+	r.mu.state.GCRetention = retention
+	r.mu.Unlock()
+}
+
 func (r *Replica) handleVersionResult(ctx context.Context, version *roachpb.Version) {
 	if (*version == roachpb.Version{}) {
 		log.Fatal(ctx, "not expecting empty replica version downstream of raft")
@@ -1293,6 +1293,11 @@ func (sm *replicaStateMachine) handleNonTrivialReplicatedEvalResult(
 			rResult.State.GCThreshold = nil
 		}
 
+		if newRetention := rResult.State.GCRetention; newRetention != nil {
+			sm.r.handleGCRetentionResult(ctx, newRetention)
+			rResult.State.GCRetention = nil
+		}
+
 		if newVersion := rResult.State.Version; newVersion != nil {
 			sm.r.handleVersionResult(ctx, newVersion)
 			rResult.State.Version = nil
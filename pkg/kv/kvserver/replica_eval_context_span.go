@@ -269,6 +269,11 @@ func (rec *SpanSetReplicaEvalContext) GetMaxBytes() int64 {
 	return rec.i.GetMaxBytes()
 }
 
+// CheckStorageQuota implements the batcheval.EvalContext interface.
+func (rec *SpanSetReplicaEvalContext) CheckStorageQuota() error {
+	return rec.i.CheckStorageQuota()
+}
+
 // GetEngineCapacity implements the batcheval.EvalContext interface.
 func (rec *SpanSetReplicaEvalContext) GetEngineCapacity() (roachpb.StoreCapacity, error) {
 	return rec.i.GetEngineCapacity()
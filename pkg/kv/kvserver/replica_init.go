@@ -96,9 +96,11 @@ func newUnloadedReplica(
 	r.mu.quiescent = true
 	r.mu.conf = store.cfg.DefaultSpanConfig
 	split.Init(&r.loadBasedSplitter, rand.Intn, func() float64 {
-		return float64(SplitByLoadQPSThreshold.Get(&store.cfg.Settings.SV))
+		return splitByLoadThreshold(context.Background(), store.cfg.Settings)
 	}, func() time.Duration {
 		return kvserverbase.SplitByLoadMergeDelay.Get(&store.cfg.Settings.SV)
+	}, func() split.SplitObjective {
+		return ResolveLBRebalancingObjective(context.Background(), store.cfg.Settings).ToSplitObjective()
 	})
 	r.mu.proposals = map[kvserverbase.CmdIDKey]*ProposalData{}
 	r.mu.checksums = map[uuid.UUID]replicaChecksum{}
@@ -24,6 +24,9 @@ type ReplicaLoad struct {
 	readKeys      *replicastats.ReplicaStats
 	writeBytes    *replicastats.ReplicaStats
 	readBytes     *replicastats.ReplicaStats
+	// requestCPUNanos tracks the CPU time, in nanoseconds, spent evaluating
+	// requests on the replica. See Replica.recordRequestCPU.
+	requestCPUNanos *replicastats.ReplicaStats
 }
 
 func newReplicaLoad(clock *hlc.Clock, getNodeLocality replicastats.LocalityOracle) *ReplicaLoad {
@@ -34,6 +37,8 @@ func newReplicaLoad(clock *hlc.Clock, getNodeLocality replicastats.LocalityOracl
 		readKeys:      replicastats.NewReplicaStats(clock, getNodeLocality),
 		writeBytes:    replicastats.NewReplicaStats(clock, getNodeLocality),
 		readBytes:     replicastats.NewReplicaStats(clock, getNodeLocality),
+
+		requestCPUNanos: replicastats.NewReplicaStats(clock, getNodeLocality),
 	}
 }
 
@@ -46,6 +51,7 @@ func (rl *ReplicaLoad) split(other *ReplicaLoad) {
 	rl.readKeys.SplitRequestCounts(other.readKeys)
 	rl.writeBytes.SplitRequestCounts(other.writeBytes)
 	rl.readBytes.SplitRequestCounts(other.readBytes)
+	rl.requestCPUNanos.SplitRequestCounts(other.requestCPUNanos)
 }
 
 // merge will combine the tracked load in other, into the calling struct.
@@ -56,6 +62,7 @@ func (rl *ReplicaLoad) merge(other *ReplicaLoad) {
 	rl.readKeys.MergeRequestCounts(other.readKeys)
 	rl.writeBytes.MergeRequestCounts(other.writeBytes)
 	rl.readBytes.MergeRequestCounts(other.readBytes)
+	rl.requestCPUNanos.MergeRequestCounts(other.requestCPUNanos)
 }
 
 // reset will clear all recorded history.
@@ -66,4 +73,5 @@ func (rl *ReplicaLoad) reset() {
 	rl.readKeys.ResetRequestCounts()
 	rl.writeBytes.ResetRequestCounts()
 	rl.readBytes.ResetRequestCounts()
+	rl.requestCPUNanos.ResetRequestCounts()
 }
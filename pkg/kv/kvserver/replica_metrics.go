@@ -320,6 +320,14 @@ func (r *Replica) ReadBytesPerSecond() float64 {
 	return rbps
 }
 
+// RequestCPUNanosPerSecond returns the range's average CPU time, in
+// nanoseconds, spent per second evaluating requests. See recordRequestCPU for
+// what is and isn't included.
+func (r *Replica) RequestCPUNanosPerSecond() float64 {
+	cpus, _ := r.loadStats.requestCPUNanos.AverageRatePerSecond()
+	return cpus
+}
+
 func (r *Replica) needsSplitBySizeRLocked() bool {
 	exceeded, _ := r.exceedsMultipleOfSplitSizeRLocked(1)
 	return exceeded
@@ -0,0 +1,146 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/admission/admissionpb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// catchUpScanYieldInterval controls how long a rangefeed catch-up scan which
+// is subject to admission control runs before yielding to other work.
+var catchUpScanYieldInterval = settings.RegisterDurationSetting(
+	settings.SystemOnly,
+	"kv.rangefeed.catchup_scan_yield_interval",
+	"the duration for which a rangefeed catch-up scan subject to admission control "+
+		"runs before yielding to other work and waiting to be admitted again",
+	100*time.Millisecond,
+	settings.NonNegativeDuration,
+)
+
+// catchUpScanYieldBytes controls how many bytes a rangefeed catch-up scan which
+// is subject to admission control reads before yielding to other work.
+var catchUpScanYieldBytes = settings.RegisterByteSizeSetting(
+	settings.SystemOnly,
+	"kv.rangefeed.catchup_scan_yield_bytes",
+	"the number of bytes a rangefeed catch-up scan subject to admission control "+
+		"reads before yielding to other work and waiting to be admitted again",
+	4<<20, /* 4 MiB */
+	settings.NonNegativeInt,
+)
+
+// catchUpScanPacer implements rangefeed.CatchUpPacer by subjecting a catch-up
+// scan to KV admission control. Catch-up scans are elastic work: they are
+// admitted at the priority requested by the rangefeed, but never above
+// admissionpb.BulkNormalPri, so that they can't compete with foreground
+// traffic. The scan holds on to its admission until it has run for
+// catchUpScanYieldInterval or read catchUpScanYieldBytes, whichever comes
+// first, after which it releases it and queues to be admitted again. This
+// bounds the work done under a single admission, so that a long catch-up scan
+// doesn't monopolize the CPU when foreground work is waiting. The scan releases
+// its admission whenever it blocks on sending events, see
+// rangefeed.CatchUpPacer.
+type catchUpScanPacer struct {
+	admissionController KVAdmissionController
+	settings            *cluster.Settings
+	metrics             *rangefeed.Metrics
+	tenantID            roachpb.TenantID
+	// ba carries the admission header of the rangefeed. It contains no requests,
+	// so that the scan is only subject to the KV (CPU) admission queue and not
+	// to the store (IO) admission queue, which meters writes.
+	ba roachpb.BatchRequest
+
+	admitted   bool
+	admittedAt time.Time
+	// readBytes is the number of bytes read by the scan under the current
+	// admission.
+	readBytes int64
+	handle    interface{}
+}
+
+var _ rangefeed.CatchUpPacer = (*catchUpScanPacer)(nil)
+
+// newCatchUpScanPacer returns a pacer for a catch-up scan on the replica, or
+// nil if the scan is not subject to admission control. Rangefeeds which don't
+// populate their admission header, such as those established by KV itself or
+// by the system watchers, are never throttled.
+func (r *Replica) newCatchUpScanPacer(header roachpb.AdmissionHeader) rangefeed.CatchUpPacer {
+	ac := r.store.cfg.KVAdmissionController
+	if ac == nil || header.Source == roachpb.AdmissionHeader_OTHER {
+		return nil
+	}
+	tenantID, ok := r.TenantID()
+	if !ok {
+		tenantID = roachpb.SystemTenantID
+	}
+	p := &catchUpScanPacer{
+		admissionController: ac,
+		settings:            r.store.cfg.Settings,
+		metrics:             r.store.metrics.RangeFeedMetrics,
+		tenantID:            tenantID,
+	}
+	p.ba.RangeID = r.RangeID
+	p.ba.Replica.StoreID = r.store.StoreID()
+	p.ba.AdmissionHeader = catchUpScanAdmissionHeader(header)
+	return p
+}
+
+// catchUpScanAdmissionHeader returns the admission header under which a
+// catch-up scan requested with the given header is admitted.
+func catchUpScanAdmissionHeader(header roachpb.AdmissionHeader) roachpb.AdmissionHeader {
+	if admissionpb.WorkPriority(header.Priority) > admissionpb.BulkNormalPri {
+		header.Priority = int32(admissionpb.BulkNormalPri)
+	}
+	if header.CreateTime == 0 {
+		header.CreateTime = timeutil.Now().UnixNano()
+	}
+	return header
+}
+
+// Pace implements the rangefeed.CatchUpPacer interface.
+func (p *catchUpScanPacer) Pace(ctx context.Context, readBytes int64) error {
+	if p.admitted {
+		p.readBytes += readBytes
+		if timeutil.Since(p.admittedAt) < catchUpScanYieldInterval.Get(&p.settings.SV) &&
+			p.readBytes < catchUpScanYieldBytes.Get(&p.settings.SV) {
+			return nil
+		}
+		p.Release()
+		p.metrics.RangeFeedCatchUpScanYields.Inc(1)
+	}
+	// NB: the admission header keeps the CreateTime of the rangefeed across
+	// readmissions, so that a scan which yields doesn't lose its place in the
+	// queue to scans started after it.
+	start := timeutil.Now()
+	handle, err := p.admissionController.AdmitKVWork(ctx, p.tenantID, &p.ba)
+	if err != nil {
+		return err
+	}
+	p.admitted, p.admittedAt, p.readBytes, p.handle = true, timeutil.Now(), 0, handle
+	p.metrics.RangeFeedCatchUpScanAdmissionWaitNanos.Inc(p.admittedAt.Sub(start).Nanoseconds())
+	return nil
+}
+
+// Release implements the rangefeed.CatchUpPacer interface.
+func (p *catchUpScanPacer) Release() {
+	if !p.admitted {
+		return
+	}
+	p.admissionController.AdmittedKVWorkDone(p.handle)
+	p.admitted, p.handle = false, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/admission/admissionpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/stretchr/testify/require"
+)
+
+type testKVAdmissionController struct {
+	admitted, done int
+	headers        []roachpb.AdmissionHeader
+}
+
+var _ KVAdmissionController = (*testKVAdmissionController)(nil)
+
+func (c *testKVAdmissionController) AdmitKVWork(
+	_ context.Context, _ roachpb.TenantID, ba *roachpb.BatchRequest,
+) (interface{}, error) {
+	c.admitted++
+	c.headers = append(c.headers, ba.AdmissionHeader)
+	return c.admitted, nil
+}
+
+func (c *testKVAdmissionController) AdmittedKVWorkDone(interface{}) {
+	c.done++
+}
+
+func (c *testKVAdmissionController) SetTenantWeightProvider(TenantWeightProvider, *stop.Stopper) {
+}
+
+// TestCatchUpScanPacer verifies that the catch-up scan pacer admits the scan
+// before it starts, readmits it once the yield interval has elapsed or the
+// yield bytes have been read, and releases its admission when asked to.
+func TestCatchUpScanPacer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	catchUpScanYieldInterval.Override(ctx, &st.SV, time.Hour)
+	catchUpScanYieldBytes.Override(ctx, &st.SV, 1<<20)
+
+	var ac testKVAdmissionController
+	metrics := rangefeed.NewMetrics()
+	header := roachpb.AdmissionHeader{
+		Priority:   int32(admissionpb.BulkNormalPri),
+		CreateTime: 1,
+		Source:     roachpb.AdmissionHeader_FROM_SQL,
+	}
+	p := &catchUpScanPacer{
+		admissionController: &ac,
+		settings:            st,
+		metrics:             metrics,
+		tenantID:            roachpb.SystemTenantID,
+	}
+	p.ba.AdmissionHeader = header
+
+	// The scan is admitted when it starts, and doesn't yield until it has read
+	// the yield bytes.
+	require.NoError(t, p.Pace(ctx, 0 /* readBytes */))
+	require.NoError(t, p.Pace(ctx, 1<<19))
+	require.Equal(t, 1, ac.admitted)
+	require.Equal(t, 0, ac.done)
+
+	// Once the scan has read the yield bytes, it releases its admission and is
+	// admitted again with the same header.
+	require.NoError(t, p.Pace(ctx, 1<<19))
+	require.Equal(t, 2, ac.admitted)
+	require.Equal(t, 1, ac.done)
+	require.Equal(t, int64(1), metrics.RangeFeedCatchUpScanYields.Count())
+
+	// The same is true once the yield interval has elapsed.
+	catchUpScanYieldInterval.Override(ctx, &st.SV, 0)
+	require.NoError(t, p.Pace(ctx, 1))
+	require.Equal(t, 3, ac.admitted)
+	require.Equal(t, 2, ac.done)
+	require.Equal(t, []roachpb.AdmissionHeader{header, header, header}, ac.headers)
+	require.Equal(t, int64(2), metrics.RangeFeedCatchUpScanYields.Count())
+
+	// Releasing the admission, as the scan does before sending events, is
+	// idempotent, and the next call to Pace admits the scan again without
+	// counting as a yield.
+	p.Release()
+	p.Release()
+	require.Equal(t, 3, ac.done)
+	require.NoError(t, p.Pace(ctx, 1))
+	require.Equal(t, 4, ac.admitted)
+	require.Equal(t, int64(2), metrics.RangeFeedCatchUpScanYields.Count())
+	p.Release()
+	require.Equal(t, 4, ac.done)
+}
+
+// TestCatchUpScanAdmissionHeader verifies that catch-up scans are admitted as
+// elastic work, no matter the priority requested by the rangefeed.
+func TestCatchUpScanAdmissionHeader(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		requested, expected admissionpb.WorkPriority
+	}{
+		{admissionpb.UserLowPri, admissionpb.UserLowPri},
+		{admissionpb.BulkNormalPri, admissionpb.BulkNormalPri},
+		{admissionpb.NormalPri, admissionpb.BulkNormalPri},
+		{admissionpb.HighPri, admissionpb.BulkNormalPri},
+	} {
+		header := catchUpScanAdmissionHeader(roachpb.AdmissionHeader{
+			Priority:   int32(tc.requested),
+			CreateTime: 1,
+			Source:     roachpb.AdmissionHeader_FROM_SQL,
+		})
+		require.Equal(t, int32(tc.expected), header.Priority)
+		require.Equal(t, int64(1), header.CreateTime)
+	}
+	// A missing create time is filled in.
+	header := catchUpScanAdmissionHeader(roachpb.AdmissionHeader{Source: roachpb.AdmissionHeader_FROM_SQL})
+	require.NotZero(t, header.CreateTime)
+}
@@ -13,6 +13,7 @@ package kvserver
 import (
 	"container/heap"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 )
 
@@ -24,15 +25,33 @@ const (
 type replicaWithStats struct {
 	repl *Replica
 	qps  float64
-	// TODO(aayush): Include writes-per-second and logicalBytes of storage?
+	// cpu is the request CPU time, in nanoseconds, spent per second on the
+	// replica.
+	cpu float64
+	// wps is the number of keys written per second to the replica.
+	wps float64
+	// logicalBytes is the logical size of the replica's data.
+	logicalBytes int64
 }
 
-// replicaRankings maintains top-k orderings of the replicas in a store by QPS.
+// load returns the replica's load in the given dimension.
+func (r replicaWithStats) load(dim allocator.LoadDimension) float64 {
+	if dim == allocator.CPU {
+		return r.cpu
+	}
+	return r.qps
+}
+
+// replicaRankings maintains top-k orderings of the replicas in a store by QPS,
+// by request CPU time, by writes per second and by logical bytes.
 type replicaRankings struct {
 	mu struct {
 		syncutil.Mutex
 		qpsAccumulator *rrAccumulator
 		byQPS          []replicaWithStats
+		byCPU          []replicaWithStats
+		byWPS          []replicaWithStats
+		byBytes        []replicaWithStats
 	}
 }
 
@@ -43,6 +62,9 @@ func newReplicaRankings() *replicaRankings {
 func (rr *replicaRankings) newAccumulator() *rrAccumulator {
 	res := &rrAccumulator{}
 	res.qps.val = func(r replicaWithStats) float64 { return r.qps }
+	res.cpu.val = func(r replicaWithStats) float64 { return r.cpu }
+	res.wps.val = func(r replicaWithStats) float64 { return r.wps }
+	res.bytes.val = func(r replicaWithStats) float64 { return float64(r.logicalBytes) }
 	return res
 }
 
@@ -63,6 +85,47 @@ func (rr *replicaRankings) topQPS() []replicaWithStats {
 	return rr.mu.byQPS
 }
 
+func (rr *replicaRankings) topCPU() []replicaWithStats {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	// If we have a new set of data, consume it. Otherwise, just return the most
+	// recently consumed data.
+	if rr.mu.qpsAccumulator != nil && rr.mu.qpsAccumulator.cpu.Len() > 0 {
+		rr.mu.byCPU = consumeAccumulator(&rr.mu.qpsAccumulator.cpu)
+	}
+	return rr.mu.byCPU
+}
+
+func (rr *replicaRankings) topWPS() []replicaWithStats {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	// If we have a new set of data, consume it. Otherwise, just return the most
+	// recently consumed data.
+	if rr.mu.qpsAccumulator != nil && rr.mu.qpsAccumulator.wps.Len() > 0 {
+		rr.mu.byWPS = consumeAccumulator(&rr.mu.qpsAccumulator.wps)
+	}
+	return rr.mu.byWPS
+}
+
+func (rr *replicaRankings) topLogicalBytes() []replicaWithStats {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	// If we have a new set of data, consume it. Otherwise, just return the most
+	// recently consumed data.
+	if rr.mu.qpsAccumulator != nil && rr.mu.qpsAccumulator.bytes.Len() > 0 {
+		rr.mu.byBytes = consumeAccumulator(&rr.mu.qpsAccumulator.bytes)
+	}
+	return rr.mu.byBytes
+}
+
+// topLoad returns the replicas with the highest load in the given dimension.
+func (rr *replicaRankings) topLoad(dim allocator.LoadDimension) []replicaWithStats {
+	if dim == allocator.CPU {
+		return rr.topCPU()
+	}
+	return rr.topQPS()
+}
+
 // rrAccumulator is used to update the replicas tracked by replicaRankings.
 // The typical pattern should be to call replicaRankings.newAccumulator, add
 // all the replicas you care about to the accumulator using addReplica, then
@@ -72,22 +135,17 @@ func (rr *replicaRankings) topQPS() []replicaWithStats {
 // prevents concurrent loaders of data from messing with each other -- the last
 // `update`d accumulator will win.
 type rrAccumulator struct {
-	qps rrPriorityQueue
+	qps   rrPriorityQueue
+	cpu   rrPriorityQueue
+	wps   rrPriorityQueue
+	bytes rrPriorityQueue
 }
 
 func (a *rrAccumulator) addReplica(repl replicaWithStats) {
-	// If the heap isn't full, just push the new replica and return.
-	if a.qps.Len() < numTopReplicasToTrack {
-		heap.Push(&a.qps, repl)
-		return
-	}
-
-	// Otherwise, conditionally push if the new replica is more deserving than
-	// the current tip of the heap.
-	if repl.qps > a.qps.entries[0].qps {
-		heap.Pop(&a.qps)
-		heap.Push(&a.qps, repl)
-	}
+	a.qps.add(repl)
+	a.cpu.add(repl)
+	a.wps.add(repl)
+	a.bytes.add(repl)
 }
 
 func consumeAccumulator(pq *rrPriorityQueue) []replicaWithStats {
@@ -104,6 +162,21 @@ type rrPriorityQueue struct {
 	val     func(replicaWithStats) float64
 }
 
+func (pq *rrPriorityQueue) add(repl replicaWithStats) {
+	// If the heap isn't full, just push the new replica and return.
+	if pq.Len() < numTopReplicasToTrack {
+		heap.Push(pq, repl)
+		return
+	}
+
+	// Otherwise, conditionally push if the new replica is more deserving than
+	// the current tip of the heap.
+	if pq.val(repl) > pq.val(pq.entries[0]) {
+		heap.Pop(pq)
+		heap.Push(pq, repl)
+	}
+}
+
 func (pq rrPriorityQueue) Len() int { return len(pq.entries) }
 
 func (pq rrPriorityQueue) Less(i, j int) bool {
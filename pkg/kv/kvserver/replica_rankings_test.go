@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
@@ -82,6 +83,64 @@ func TestReplicaRankings(t *testing.T) {
 	}
 }
 
+// TestReplicaRankingsByLoadDimension verifies that the rankings order replicas
+// independently by QPS and by request CPU time.
+func TestReplicaRankingsByLoadDimension(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	rr := newReplicaRankings()
+	acc := rr.newAccumulator()
+	// The replicas serving the most QPS serve the least expensive requests.
+	for i := 0; i < 5; i++ {
+		acc.addReplica(replicaWithStats{
+			repl: &Replica{RangeID: roachpb.RangeID(i)},
+			qps:  float64(i),
+			cpu:  float64(5 - i),
+		})
+	}
+	rr.update(acc)
+
+	var byQPS, byCPU []roachpb.RangeID
+	for _, r := range rr.topLoad(allocator.Queries) {
+		byQPS = append(byQPS, r.repl.RangeID)
+	}
+	for _, r := range rr.topLoad(allocator.CPU) {
+		byCPU = append(byCPU, r.repl.RangeID)
+	}
+	require.Equal(t, []roachpb.RangeID{4, 3, 2, 1, 0}, byQPS)
+	require.Equal(t, []roachpb.RangeID{0, 1, 2, 3, 4}, byCPU)
+}
+
+// TestReplicaRankingsByWritesAndBytes verifies that the rankings order
+// replicas independently by writes per second and by logical bytes.
+func TestReplicaRankingsByWritesAndBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	rr := newReplicaRankings()
+	acc := rr.newAccumulator()
+	// The replicas with the most writes are the smallest.
+	for i := 0; i < 5; i++ {
+		acc.addReplica(replicaWithStats{
+			repl:         &Replica{RangeID: roachpb.RangeID(i)},
+			wps:          float64(i),
+			logicalBytes: int64(5 - i),
+		})
+	}
+	rr.update(acc)
+
+	var byWPS, byBytes []roachpb.RangeID
+	for _, r := range rr.topWPS() {
+		byWPS = append(byWPS, r.repl.RangeID)
+	}
+	for _, r := range rr.topLogicalBytes() {
+		byBytes = append(byBytes, r.repl.RangeID)
+	}
+	require.Equal(t, []roachpb.RangeID{4, 3, 2, 1, 0}, byWPS)
+	require.Equal(t, []roachpb.RangeID{0, 1, 2, 3, 4}, byBytes)
+}
+
 // TestAddSSTQPSStat verifies that AddSSTableRequests are accounted for
 // differently, when present in a BatchRequest, with a divisor set.
 func TestAddSSTQPSStat(t *testing.T) {
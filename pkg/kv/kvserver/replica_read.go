@@ -297,9 +297,13 @@ func (r *Replica) executeReadOnlyBatchWithServersideRefreshes(
 			boundAccount.Clear(ctx)
 			log.VEventf(ctx, 2, "server-side retry of batch")
 		}
+		var cpu requestCPUTimer
+		cpu.start(&r.requestCPUSamples)
 		now := timeutil.Now()
 		br, res, pErr = evaluateBatch(ctx, kvserverbase.CmdIDKey(""), rw, rec, nil, ba, st, ui, true /* readOnly */)
-		r.store.metrics.ReplicaReadBatchEvaluationLatency.RecordValue(timeutil.Since(now).Nanoseconds())
+		evalDur := timeutil.Since(now)
+		r.store.metrics.ReplicaReadBatchEvaluationLatency.RecordValue(evalDur.Nanoseconds())
+		r.recordRequestCPU(ctx, ba, g, cpu.stop(evalDur))
 		// If we can retry, set a higher batch timestamp and continue.
 		// Allow one retry only.
 		if pErr == nil || retries > 0 || !canDoServersideRetry(ctx, pErr, ba, br, g, nil /* deadline */) {
@@ -0,0 +1,46 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/errors"
+)
+
+// checkReadOnlyMode returns an error if the batch writes user data to a range
+// whose span config puts it into read-only mode, unless the batch explicitly
+// bypasses it.
+//
+// Only requests writing to global keys are rejected: writes to range-local
+// keys, such as the range descriptor updates performed by splits and merges,
+// are still allowed, and so are requests which don't write user data, such as
+// intent resolution, transaction record updates and GC.
+func (r *Replica) checkReadOnlyMode(ba *roachpb.BatchRequest) error {
+	if ba.BypassReadOnlyMode || !r.SpanConfig().ReadOnly {
+		return nil
+	}
+	for _, ru := range ba.Requests {
+		req := ru.GetInner()
+		if !roachpb.WritesUserData(req) || keys.IsLocal(req.Header().Key) {
+			continue
+		}
+		err := errors.WithHint(
+			errors.Newf("cannot execute %s on %s: range is in read-only mode", req.Method(), r),
+			"the table was put into read-only mode with ALTER TABLE ... SET READ ONLY; "+
+				"use ALTER TABLE ... SET READ WRITE to allow writes again",
+		)
+		return pgerror.WithCandidateCode(err, pgcode.ReadOnlySQLTransaction)
+	}
+	return nil
+}
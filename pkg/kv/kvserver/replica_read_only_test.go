@@ -0,0 +1,81 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplicaReadOnlyMode verifies that a replica whose span config puts it
+// into read-only mode rejects writes to user data, but serves reads, writes
+// to range-local keys and batches which bypass read-only mode.
+func TestReplicaReadOnlyMode(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	tc.Start(ctx, t, stopper)
+
+	key := roachpb.Key("a")
+	put := putArgs(key, []byte("value"))
+	_, pErr := tc.SendWrapped(&put)
+	require.NoError(t, pErr.GoError())
+
+	conf := tc.repl.SpanConfig()
+	conf.ReadOnly = true
+	tc.repl.SetSpanConfig(conf)
+
+	// Writes to user data are rejected.
+	for _, req := range []roachpb.Request{
+		&put,
+		incrementArgs(key, 1),
+		func() roachpb.Request { del := deleteArgs(key); return &del }(),
+		func() roachpb.Request { dr := deleteRangeArgs(key, key.Next()); return &dr }(),
+	} {
+		_, pErr := tc.SendWrapped(req)
+		require.Regexp(t, `range is in read-only mode`, pErr, "%s", req.Method())
+		require.Equal(t, pgcode.ReadOnlySQLTransaction, pgerror.GetPGCode(pErr.GoError()))
+	}
+
+	// Reads are still served.
+	get := getArgs(key)
+	_, pErr = tc.SendWrapped(&get)
+	require.NoError(t, pErr.GoError())
+
+	// Writes to range-local keys are not rejected.
+	var ba roachpb.BatchRequest
+	localPut := putArgs(keys.RangeDescriptorKey(roachpb.RKey(key)), []byte("value"))
+	ba.Add(&localPut)
+	require.NoError(t, tc.repl.checkReadOnlyMode(&ba))
+
+	// Batches which bypass read-only mode are not rejected.
+	_, pErr = tc.SendWrappedWith(roachpb.Header{BypassReadOnlyMode: true}, &put)
+	require.NoError(t, pErr.GoError())
+
+	// Writes are allowed again once the range leaves read-only mode.
+	conf.ReadOnly = false
+	tc.repl.SetSpanConfig(conf)
+	_, pErr = tc.SendWrapped(&put)
+	require.NoError(t, pErr.GoError())
+}
@@ -12,10 +12,15 @@ package kvserver
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/cputime"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
@@ -35,11 +40,36 @@ var SplitByLoadQPSThreshold = settings.RegisterIntSetting(
 	2500, // 2500 req/s
 ).WithPublic()
 
+// SplitByLoadCPUThreshold wraps "kv.range_split.load_cpu_threshold".
+var SplitByLoadCPUThreshold = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"kv.range_split.load_cpu_threshold",
+	"the CPU use per second over which, the range becomes a candidate for load "+
+		"based splitting when the cpu load based rebalancing objective is in use",
+	250*time.Millisecond,
+	settings.NonNegativeDuration,
+)
+
 // SplitByLoadQPSThreshold returns the QPS request rate for a given replica.
 func (r *Replica) SplitByLoadQPSThreshold() float64 {
 	return float64(SplitByLoadQPSThreshold.Get(&r.store.cfg.Settings.SV))
 }
 
+// SplitByLoadThreshold returns the load above which a given replica becomes a
+// candidate for load based splitting. The threshold is in the units of the
+// current load based rebalancing objective: requests per second for QPS and
+// CPU nanoseconds per second for CPU.
+func (r *Replica) SplitByLoadThreshold() float64 {
+	return splitByLoadThreshold(context.Background(), r.store.cfg.Settings)
+}
+
+func splitByLoadThreshold(ctx context.Context, st *cluster.Settings) float64 {
+	if ResolveLBRebalancingObjective(ctx, st) == LBRebalancingCPU {
+		return float64(SplitByLoadCPUThreshold.Get(&st.SV).Nanoseconds())
+	}
+	return float64(SplitByLoadQPSThreshold.Get(&st.SV))
+}
+
 // SplitByLoadEnabled returns whether load based splitting is enabled.
 // Although this is a method of *Replica, the configuration is really global,
 // shared across all stores.
@@ -56,6 +86,11 @@ func (r *Replica) recordBatchForLoadBasedSplitting(
 	if !r.SplitByLoadEnabled() {
 		return
 	}
+	// Under the CPU objective, batches are recorded once evaluated, see
+	// recordRequestCPU.
+	if ResolveLBRebalancingObjective(ctx, r.store.cfg.Settings) != LBRebalancingQueries {
+		return
+	}
 	shouldInitSplit := r.loadBasedSplitter.Record(timeutil.Now(), len(ba.Requests), func() roachpb.Span {
 		return spans.BoundarySpan(spanset.SpanGlobal)
 	})
@@ -63,3 +98,76 @@ func (r *Replica) recordBatchForLoadBasedSplitting(
 		r.store.splitQueue.MaybeAddAsync(ctx, r, r.store.Clock().NowAsClockTimestamp())
 	}
 }
+
+// requestCPUSampleInterval is the number of batches evaluated on a replica per
+// batch whose CPU time is measured. Measuring the CPU time of a batch locks its
+// goroutine to an OS thread for the duration of the evaluation, and reads the
+// CPU time of the thread twice, which is too expensive to do for every batch.
+const requestCPUSampleInterval = 16
+
+// requestCPUTimer measures the CPU time spent evaluating a sample of the
+// batches evaluated on a replica.
+type requestCPUTimer struct {
+	w       cputime.Stopwatch
+	sampled bool
+}
+
+// start starts measuring the CPU time of the batch about to be evaluated if it
+// is one of every requestCPUSampleInterval batches counted by samples.
+func (t *requestCPUTimer) start(samples *uint32) {
+	if !cputime.Supported() || atomic.AddUint32(samples, 1)%requestCPUSampleInterval != 0 {
+		return
+	}
+	t.sampled = true
+	t.w.Start()
+}
+
+// stop returns the CPU time attributed to the evaluated batch. A sampled batch
+// is attributed requestCPUSampleInterval times the CPU time it spent, and the
+// other batches none, so that the CPU time recorded for a replica approximates
+// the CPU time spent by all of its batches. On platforms where the CPU time of
+// a thread is not available, the wall time spent in evaluation is returned
+// instead.
+func (t *requestCPUTimer) stop(evalDur time.Duration) time.Duration {
+	if !cputime.Supported() {
+		return evalDur
+	}
+	if !t.sampled {
+		return 0
+	}
+	return t.w.Stop() * requestCPUSampleInterval
+}
+
+// recordRequestCPU records the CPU time attributed to evaluating the batch,
+// which excludes the time spent waiting on latches, locks and replication. See
+// requestCPUTimer. Under the CPU load based rebalancing objective, the batch's
+// spans are also recorded to be considered for load based splitting, weighted
+// by that time.
+func (r *Replica) recordRequestCPU(
+	ctx context.Context, ba *roachpb.BatchRequest, g *concurrency.Guard, dur time.Duration,
+) {
+	if dur == 0 {
+		return
+	}
+	if r.loadStats != nil {
+		r.loadStats.requestCPUNanos.RecordCount(float64(dur.Nanoseconds()), 0)
+	}
+
+	if !r.SplitByLoadEnabled() {
+		return
+	}
+	if ResolveLBRebalancingObjective(ctx, r.store.cfg.Settings) != LBRebalancingCPU {
+		return
+	}
+	// The guard may be nil when evaluating outside of the usual request path,
+	// in which case there are no spans to attribute the load to.
+	if g == nil || g.LatchSpans() == nil {
+		return
+	}
+	shouldInitSplit := r.loadBasedSplitter.Record(timeutil.Now(), int(dur.Nanoseconds()), func() roachpb.Span {
+		return g.LatchSpans().BoundarySpan(spanset.SpanGlobal)
+	})
+	if shouldInitSplit {
+		r.store.splitQueue.MaybeAddAsync(ctx, r, r.store.Clock().NowAsClockTimestamp())
+	}
+}
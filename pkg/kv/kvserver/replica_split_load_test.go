@@ -0,0 +1,54 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/cputime"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestCPUTimerSamples verifies that the CPU time of one in every
+// requestCPUSampleInterval batches is measured, and scaled to all of them.
+func TestRequestCPUTimerSamples(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const evalDur = time.Second
+	var samples uint32
+	var sampled int
+	for i := 0; i < 10*requestCPUSampleInterval; i++ {
+		var cpu requestCPUTimer
+		cpu.start(&samples)
+		// Spend some CPU time.
+		for start := time.Now(); time.Since(start) < time.Millisecond; {
+		}
+		dur := cpu.stop(evalDur)
+		if !cputime.Supported() {
+			// The wall time is attributed to every batch instead.
+			require.Equal(t, evalDur, dur)
+			continue
+		}
+		if cpu.sampled {
+			sampled++
+			require.Greater(t, dur, time.Duration(0))
+		} else {
+			require.Zero(t, dur)
+		}
+	}
+	if cputime.Supported() {
+		require.Equal(t, 10, sampled)
+	}
+}
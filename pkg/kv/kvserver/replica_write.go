@@ -109,6 +109,11 @@ func (r *Replica) executeWriteBatch(
 		return nil, g, roachpb.NewError(err)
 	}
 
+	// Reject writes to ranges in read-only mode.
+	if err := r.checkReadOnlyMode(ba); err != nil {
+		return nil, g, roachpb.NewError(err)
+	}
+
 	// Compute the transaction's local uncertainty limit using observed
 	// timestamps, which can help avoid uncertainty restarts.
 	ui := uncertainty.ComputeInterval(&ba.Header, st, r.Clock().MaxOffset())
@@ -650,9 +655,13 @@ func (r *Replica) evaluateWriteBatchWrapper(
 	g *concurrency.Guard,
 ) (storage.Batch, *roachpb.BatchResponse, result.Result, *roachpb.Error) {
 	batch, opLogger := r.newBatchedEngine(ba, g)
+	var cpu requestCPUTimer
+	cpu.start(&r.requestCPUSamples)
 	now := timeutil.Now()
 	br, res, pErr := evaluateBatch(ctx, idKey, batch, rec, ms, ba, st, ui, false /* readOnly */)
-	r.store.metrics.ReplicaWriteBatchEvaluationLatency.RecordValue(timeutil.Since(now).Nanoseconds())
+	evalDur := timeutil.Since(now)
+	r.store.metrics.ReplicaWriteBatchEvaluationLatency.RecordValue(evalDur.Nanoseconds())
+	r.recordRequestCPU(ctx, ba, g, cpu.stop(evalDur))
 	if pErr == nil {
 		if opLogger != nil {
 			res.LogicalOpLog = &kvserverpb.LogicalOpLog{
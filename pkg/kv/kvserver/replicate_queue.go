@@ -1552,18 +1552,17 @@ func (rq *replicateQueue) shedLease(
 		return allocator.NoTransferDryRun, nil
 	}
 
-	avgQPS, qpsMeasurementDur := repl.leaseholderStats.AverageRatePerSecond()
-	if qpsMeasurementDur < replicastats.MinStatsDuration {
-		avgQPS = 0
-	}
-	if err := rq.transferLease(ctx, repl, target, avgQPS); err != nil {
+	if err := rq.transferLease(ctx, repl, target, rangeUsageInfoForRepl(repl)); err != nil {
 		return allocator.TransferErr, err
 	}
 	return allocator.TransferOK, nil
 }
 
 func (rq *replicateQueue) transferLease(
-	ctx context.Context, repl *Replica, target roachpb.ReplicaDescriptor, rangeQPS float64,
+	ctx context.Context,
+	repl *Replica,
+	target roachpb.ReplicaDescriptor,
+	rangeUsageInfo allocator.RangeUsageInfo,
 ) error {
 	rq.metrics.TransferLeaseCount.Inc(1)
 	log.VEventf(ctx, 1, "transferring lease to s%d", target.StoreID)
@@ -1572,7 +1571,7 @@ func (rq *replicateQueue) transferLease(
 	}
 	rq.lastLeaseTransfer.Store(timeutil.Now())
 	rq.store.cfg.StorePool.UpdateLocalStoresAfterLeaseTransfer(
-		repl.store.StoreID(), target.StoreID, rangeQPS)
+		repl.store.StoreID(), target.StoreID, rangeUsageInfo)
 	return nil
 }
 
@@ -1672,5 +1671,8 @@ func rangeUsageInfoForRepl(repl *Replica) allocator.RangeUsageInfo {
 	if writesPerSecond, dur := repl.writeStats.AverageRatePerSecond(); dur >= replicastats.MinStatsDuration {
 		info.WritesPerSecond = writesPerSecond
 	}
+	if cpuPerSecond, dur := repl.loadStats.requestCPUNanos.AverageRatePerSecond(); dur >= replicastats.MinStatsDuration {
+		info.RequestCPUNanosPerSecond = cpuPerSecond
+	}
 	return info
 }
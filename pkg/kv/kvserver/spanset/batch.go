@@ -545,7 +545,9 @@ func (s spanSetWriter) ClearIntent(
 }
 
 func (s spanSetWriter) ClearEngineKey(key storage.EngineKey) error {
-	if !s.spansOnly {
+	// Lock table keys are declared as non-MVCC spans, so they are checked
+	// without timestamps.
+	if !s.spansOnly && !key.IsLockTableKey() {
 		panic("cannot do timestamp checking for clearing EngineKey")
 	}
 	if err := s.spans.CheckAllowed(SpanReadWrite, roachpb.Span{Key: key.Key}); err != nil {
@@ -668,7 +670,9 @@ func (s spanSetWriter) PutIntent(
 }
 
 func (s spanSetWriter) PutEngineKey(key storage.EngineKey, value []byte) error {
-	if !s.spansOnly {
+	// Lock table keys are declared as non-MVCC spans, so they are checked
+	// without timestamps.
+	if !s.spansOnly && !key.IsLockTableKey() {
 		panic("cannot do timestamp checking for putting EngineKey")
 	}
 	if err := s.spans.CheckAllowed(SpanReadWrite, roachpb.Span{Key: key.Key}); err != nil {
@@ -13,6 +13,7 @@
 package split
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -23,6 +24,42 @@ import (
 const minSplitSuggestionInterval = time.Minute
 const minQueriesPerSecondSampleDuration = time.Second
 
+// SplitObjective is the type of load that a Decider measures in order to
+// decide on load-based splits.
+type SplitObjective int
+
+const (
+	// SplitQPS measures load as the number of requests per second. Each
+	// request carries equal weight when searching for a split key.
+	SplitQPS SplitObjective = iota
+	// SplitCPU measures load as the CPU time, in nanoseconds, spent per second
+	// evaluating requests. Requests are weighted by their CPU time when
+	// searching for a split key.
+	SplitCPU
+)
+
+// String implements the fmt.Stringer interface.
+func (obj SplitObjective) String() string {
+	switch obj {
+	case SplitQPS:
+		return "qps"
+	case SplitCPU:
+		return "cpu"
+	default:
+		return fmt.Sprintf("unknown split objective: %d", int(obj))
+	}
+}
+
+// LoadSplitFinder is implemented by the Finder and WeightedFinder, which
+// determine a split key from sampled request spans.
+type LoadSplitFinder interface {
+	// Ready returns whether the finder has sampled for long enough to be
+	// consulted.
+	Ready(time.Time) bool
+	// Key returns the suggested split key, or nil if there is none.
+	Key() roachpb.Key
+}
+
 // A Decider collects measurements about the activity (measured in qps) on a
 // Replica and, assuming that qps thresholds are exceeded, tries to determine a
 // split key that would approximately result in halving the load on each of the
@@ -48,14 +85,26 @@ const minQueriesPerSecondSampleDuration = time.Second
 // prevent load-based splits from being merged away until the resulting ranges
 // have consistently remained below a certain QPS threshold for a sufficiently
 // long period of time.
+//
+// Although the measurements are named after QPS, the load that is measured is
+// determined by the SplitObjective supplied to Init. Under SplitCPU, the
+// counts passed to Record are nanoseconds of CPU time, the threshold and the
+// measurements returned are in CPU nanoseconds per second, and spans are
+// weighted by their CPU time when searching for a split key. When the
+// objective changes, the Decider discards its measurements, including the
+// historical maximum, since they are not comparable across objectives.
 type Decider struct {
-	intn         func(n int) int      // supplied to Init
-	qpsThreshold func() float64       // supplied to Init
-	qpsRetention func() time.Duration // supplied to Init
+	intn         func(n int) int       // supplied to Init
+	qpsThreshold func() float64        // supplied to Init
+	qpsRetention func() time.Duration  // supplied to Init
+	objective    func() SplitObjective // supplied to Init
 
 	mu struct {
 		syncutil.Mutex
 
+		// The objective that the measurements below were recorded under.
+		objective SplitObjective
+
 		// Fields tracking the current qps sample.
 		lastQPSRollover time.Time // most recent time recorded by requests.
 		lastQPS         float64   // last reqs/s rate as of lastQPSRollover
@@ -65,8 +114,8 @@ type Decider struct {
 		maxQPS maxQPSTracker
 
 		// Fields tracking split key suggestions.
-		splitFinder         *Finder   // populated when engaged or decided
-		lastSplitSuggestion time.Time // last stipulation to client to carry out split
+		splitFinder         LoadSplitFinder // populated when engaged or decided
+		lastSplitSuggestion time.Time       // last stipulation to client to carry out split
 	}
 }
 
@@ -79,14 +128,17 @@ func Init(
 	intn func(n int) int,
 	qpsThreshold func() float64,
 	qpsRetention func() time.Duration,
+	objective func() SplitObjective,
 ) {
 	lbs.intn = intn
 	lbs.qpsThreshold = qpsThreshold
 	lbs.qpsRetention = qpsRetention
+	lbs.objective = objective
 }
 
 // Record notifies the Decider that 'n' operations are being carried out which
-// operate on the span returned by the supplied method. The closure will only
+// operate on the span returned by the supplied method. Under SplitCPU, 'n' is
+// instead the CPU time in nanoseconds spent on a single operation. The closure will only
 // be called when necessary, that is, when the Decider is considering a split
 // and is sampling key spans to determine a suitable split point.
 //
@@ -101,6 +153,7 @@ func (d *Decider) Record(now time.Time, n int, span func() roachpb.Span) bool {
 }
 
 func (d *Decider) recordLocked(now time.Time, n int, span func() roachpb.Span) bool {
+	d.maybeResetOnObjectiveChangeLocked(now)
 	d.mu.count += int64(n)
 
 	// First compute requests per second since the last check.
@@ -125,7 +178,11 @@ func (d *Decider) recordLocked(now time.Time, n int, span func() roachpb.Span) b
 		// to be used.
 		if d.mu.lastQPS >= d.qpsThreshold() {
 			if d.mu.splitFinder == nil {
-				d.mu.splitFinder = NewFinder(now)
+				if d.mu.objective == SplitCPU {
+					d.mu.splitFinder = NewWeightedFinder(now)
+				} else {
+					d.mu.splitFinder = NewFinder(now)
+				}
 			}
 		} else {
 			d.mu.splitFinder = nil
@@ -135,7 +192,12 @@ func (d *Decider) recordLocked(now time.Time, n int, span func() roachpb.Span) b
 	if d.mu.splitFinder != nil && n != 0 {
 		s := span()
 		if s.Key != nil {
-			d.mu.splitFinder.Record(span(), d.intn)
+			switch f := d.mu.splitFinder.(type) {
+			case *Finder:
+				f.Record(s, d.intn)
+			case *WeightedFinder:
+				f.Record(s, float64(n), d.intn)
+			}
 		}
 		if now.Sub(d.mu.lastSplitSuggestion) > minSplitSuggestionInterval && d.mu.splitFinder.Ready(now) && d.mu.splitFinder.Key() != nil {
 			d.mu.lastSplitSuggestion = now
@@ -152,9 +214,19 @@ func (d *Decider) RecordMax(now time.Time, qps float64) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	d.maybeResetOnObjectiveChangeLocked(now)
 	d.mu.maxQPS.record(now, d.qpsRetention(), qps)
 }
 
+// maybeResetOnObjectiveChangeLocked resets the Decider if the split objective
+// has changed since the last measurement was recorded.
+func (d *Decider) maybeResetOnObjectiveChangeLocked(now time.Time) {
+	if obj := d.objective(); obj != d.mu.objective {
+		d.resetLocked(now)
+		d.mu.objective = obj
+	}
+}
+
 // LastQPS returns the most recent QPS measurement.
 func (d *Decider) LastQPS(now time.Time) float64 {
 	d.mu.Lock()
@@ -234,6 +306,10 @@ func (d *Decider) Reset(now time.Time) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	d.resetLocked(now)
+}
+
+func (d *Decider) resetLocked(now time.Time) {
 	d.mu.lastQPSRollover = time.Time{}
 	d.mu.lastQPS = 0
 	d.mu.count = 0
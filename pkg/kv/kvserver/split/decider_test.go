@@ -11,6 +11,8 @@
 package split
 
 import (
+	"bytes"
+	"fmt"
 	"math"
 	"math/rand"
 	"testing"
@@ -38,7 +40,7 @@ func TestDecider(t *testing.T) {
 	intn := rand.New(rand.NewSource(12)).Intn
 
 	var d Decider
-	Init(&d, intn, func() float64 { return 10.0 }, func() time.Duration { return 2 * time.Second })
+	Init(&d, intn, func() float64 { return 10.0 }, func() time.Duration { return 2 * time.Second }, func() SplitObjective { return SplitQPS })
 
 	op := func(s string) func() roachpb.Span {
 		return func() roachpb.Span { return roachpb.Span{Key: roachpb.Key(s)} }
@@ -91,12 +93,10 @@ func TestDecider(t *testing.T) {
 	assert.Equal(t, ms(1200), d.mu.lastQPSRollover)
 	assertMaxQPS(1099, 0, false)
 
-	var nilFinder *Finder
-
-	assert.Equal(t, nilFinder, d.mu.splitFinder)
+	assert.Nil(t, d.mu.splitFinder)
 
 	assert.Equal(t, false, d.Record(ms(2199), 12, nil))
-	assert.Equal(t, nilFinder, d.mu.splitFinder)
+	assert.Nil(t, d.mu.splitFinder)
 
 	// 2200 is the next rollover point, and 12+1=13 qps should be computed.
 	assert.Equal(t, false, d.Record(ms(2200), 1, op("a")))
@@ -143,7 +143,7 @@ func TestDecider(t *testing.T) {
 	tick += 1000
 	assert.False(t, d.Record(ms(tick), 9, op("a")))
 	assert.Equal(t, roachpb.Key(nil), d.MaybeSplitKey(ms(tick)))
-	assert.Equal(t, nilFinder, d.mu.splitFinder)
+	assert.Nil(t, d.mu.splitFinder)
 
 	// Hammer a key with writes above threshold. There shouldn't be a split
 	// since everyone is hitting the same key and load can't be balanced.
@@ -161,7 +161,7 @@ func TestDecider(t *testing.T) {
 	}
 
 	// ... which we verify by looking at its samples directly.
-	for _, sample := range d.mu.splitFinder.samples {
+	for _, sample := range d.mu.splitFinder.(*Finder).samples {
 		assert.Equal(t, roachpb.Key("p"), sample.key)
 	}
 
@@ -194,7 +194,7 @@ func TestDecider_MaxQPS(t *testing.T) {
 	intn := rand.New(rand.NewSource(11)).Intn
 
 	var d Decider
-	Init(&d, intn, func() float64 { return 100.0 }, func() time.Duration { return 10 * time.Second })
+	Init(&d, intn, func() float64 { return 100.0 }, func() time.Duration { return 10 * time.Second }, func() SplitObjective { return SplitQPS })
 
 	assertMaxQPS := func(i int, expMaxQPS float64, expOK bool) {
 		t.Helper()
@@ -232,12 +232,74 @@ func TestDecider_MaxQPS(t *testing.T) {
 	assertMaxQPS(25000, 6, true)
 }
 
+func TestDecider_SplitObjective(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	intn := rand.New(rand.NewSource(11)).Intn
+
+	objective := SplitQPS
+	var d Decider
+	Init(&d, intn, func() float64 {
+		if objective == SplitCPU {
+			return float64(100 * time.Millisecond)
+		}
+		return 100.0
+	}, func() time.Duration { return 10 * time.Second }, func() SplitObjective { return objective })
+
+	// Establish a reliable QPS measurement, below the threshold.
+	for i := 0; i <= 12; i++ {
+		d.Record(ms(i*1000), 40, nil)
+	}
+	maxQPS, ok := d.MaxQPS(ms(12000))
+	require.True(t, ok)
+	require.Equal(t, 80.0, maxQPS)
+
+	// Switching the objective discards the measurements, since they are not
+	// comparable.
+	objective = SplitCPU
+	_, ok = d.MaxQPS(ms(12000))
+	require.False(t, ok)
+	require.Equal(t, 0.0, d.LastQPS(ms(12000)))
+
+	// Under the CPU objective, a few expensive requests to one half of the
+	// keyspace balance out many cheap requests to the other half. The weighted
+	// finder should choose a split key that balances the CPU time, rather than
+	// the number of requests.
+	heavy := func() roachpb.Span { return roachpb.Span{Key: roachpb.Key("a")} }
+	var k roachpb.Key
+	tick := 12000
+	for i := 0; i < 2*int(minSplitSuggestionInterval/time.Second) && k == nil; i++ {
+		tick += 1000
+		d.Record(ms(tick), int(200*time.Millisecond), heavy)
+		require.IsType(t, &WeightedFinder{}, d.mu.splitFinder)
+		for j := 0; j < 20; j++ {
+			key := roachpb.Key(fmt.Sprintf("b%02d", j))
+			d.Record(ms(tick), int(10*time.Millisecond), func() roachpb.Span {
+				return roachpb.Span{Key: key}
+			})
+		}
+		k = d.MaybeSplitKey(ms(tick))
+	}
+	// The heavy requests amount to as much CPU time as all of the cheap ones,
+	// so the split key must separate them near the start of the cheap keys,
+	// rather than in the middle of them as the number of requests would
+	// suggest. Split keys from b05 onwards leave at least 25% more CPU time
+	// on the left hand side than on the right.
+	require.NotNil(t, k)
+	require.True(t, bytes.Compare(k, roachpb.Key("a")) > 0, "split key %s", k)
+	require.True(t, bytes.Compare(k, roachpb.Key("b05")) < 0, "split key %s", k)
+
+	// Switching back resets the Decider again.
+	objective = SplitQPS
+	require.Nil(t, d.MaybeSplitKey(ms(tick)))
+	require.Nil(t, d.mu.splitFinder)
+}
+
 func TestDeciderCallsEnsureSafeSplitKey(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	intn := rand.New(rand.NewSource(11)).Intn
 
 	var d Decider
-	Init(&d, intn, func() float64 { return 1.0 }, func() time.Duration { return time.Second })
+	Init(&d, intn, func() float64 { return 1.0 }, func() time.Duration { return time.Second }, func() SplitObjective { return SplitQPS })
 
 	baseKey := keys.SystemSQLCodec.TablePrefix(51)
 	for i := 0; i < 4; i++ {
@@ -270,7 +332,7 @@ func TestDeciderIgnoresEnsureSafeSplitKeyOnError(t *testing.T) {
 	intn := rand.New(rand.NewSource(11)).Intn
 
 	var d Decider
-	Init(&d, intn, func() float64 { return 1.0 }, func() time.Duration { return time.Second })
+	Init(&d, intn, func() float64 { return 1.0 }, func() time.Duration { return time.Second }, func() SplitObjective { return SplitQPS })
 
 	baseKey := keys.SystemSQLCodec.TablePrefix(51)
 	for i := 0; i < 4; i++ {
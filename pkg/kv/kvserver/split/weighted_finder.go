@@ -0,0 +1,153 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package split
+
+import (
+	"bytes"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+)
+
+// Weighted load-based splitting.
+//
+// The WeightedFinder is the analog of the Finder for load which is not
+// uniform across requests, such as the CPU time spent evaluating them. Each
+// recorded span carries a weight, and the split point chosen is one that
+// approximately halves the aggregate weight, rather than the number of
+// requests, on either side.
+//
+// - Keep a sample of keys using weighted reservoir sampling (A-Chao): the
+//   n-th span, with weight w, replaces a uniformly chosen sample with
+//   probability k*w/W, where W is the aggregate weight of all spans recorded
+//   so far and k is the sample size.
+// - Each sample contains three weighted counters: left, right and contained,
+//   which are incremented by the weight of each subsequently recorded span, in
+//   the same manner as for the Finder.
+// - Each sample also counts the number of spans that contributed to its
+//   counters, so that samples which have not observed enough requests are not
+//   considered, independent of the magnitude of the weights.
+
+type weightedSample struct {
+	key                    roachpb.Key
+	left, right, contained float64
+	count                  int
+}
+
+// WeightedFinder is a structure that is used to determine the split point
+// using the weighted Reservoir Sampling method.
+type WeightedFinder struct {
+	startTime   time.Time
+	samples     [splitKeySampleSize]weightedSample
+	count       int
+	totalWeight float64
+}
+
+// NewWeightedFinder initiates a WeightedFinder with the given time.
+func NewWeightedFinder(startTime time.Time) *WeightedFinder {
+	return &WeightedFinder{
+		startTime: startTime,
+	}
+}
+
+// Ready checks if the WeightedFinder has been initialized with a sufficient
+// sample duration.
+func (f *WeightedFinder) Ready(nowTime time.Time) bool {
+	return nowTime.Sub(f.startTime) > RecordDurationThreshold
+}
+
+// Record informs the WeightedFinder about where the span lies with regard to
+// the keys in the samples. The weight is the amount of load attributed to the
+// span and must be non-negative.
+func (f *WeightedFinder) Record(span roachpb.Span, weight float64, intNFn func(int) int) {
+	if f == nil || weight < 0 {
+		return
+	}
+
+	// Unlike the Finder, every span is counted against the existing samples,
+	// including spans that go on to be sampled themselves. Heavy spans are
+	// sampled with high probability, so skipping them would bias the counters
+	// against exactly the load that matters most.
+	for i := range f.samples[:min(f.count, splitKeySampleSize)] {
+		// See Finder.Record for the reasoning behind which counter is
+		// incremented.
+		if span.ProperlyContainsKey(f.samples[i].key) {
+			f.samples[i].contained += weight
+		} else if comp := bytes.Compare(f.samples[i].key, span.Key); comp <= 0 {
+			f.samples[i].right += weight
+		} else {
+			f.samples[i].left += weight
+		}
+		f.samples[i].count++
+	}
+
+	var idx int
+	count := f.count
+	f.count++
+	f.totalWeight += weight
+	if count < splitKeySampleSize {
+		idx = count
+	} else if f.totalWeight > 0 &&
+		randFloat(intNFn) < splitKeySampleSize*weight/f.totalWeight {
+		idx = intNFn(splitKeySampleSize)
+	} else {
+		return
+	}
+
+	// Note we always use the start key of the span, as the Finder does.
+	f.samples[idx] = weightedSample{key: span.Key}
+}
+
+// Key finds an appropriate split point based on the weighted Reservoir
+// sampling method. Returns a nil key if no appropriate key was found.
+func (f *WeightedFinder) Key() roachpb.Key {
+	if f == nil {
+		return nil
+	}
+
+	var bestIdx = -1
+	var bestScore float64 = 2
+	for i, s := range f.samples {
+		if s.count < splitKeyMinCounter || s.left+s.right == 0 {
+			continue
+		}
+		balanceScore := math.Abs(s.left-s.right) / (s.left + s.right)
+		containedScore := s.contained / (s.left + s.right + s.contained)
+		finalScore := balanceScore + containedScore
+		if balanceScore >= splitKeyThreshold ||
+			containedScore >= splitKeyContainedThreshold {
+			continue
+		}
+		if finalScore < bestScore {
+			bestIdx = i
+			bestScore = finalScore
+		}
+	}
+
+	if bestIdx == -1 {
+		return nil
+	}
+	return f.samples[bestIdx].key
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// randFloat returns a pseudo-random number in [0.0,1.0) using the provided
+// integer source, which allows callers to supply a deterministic source.
+func randFloat(intNFn func(int) int) float64 {
+	return float64(intNFn(math.MaxInt32)) / math.MaxInt32
+}
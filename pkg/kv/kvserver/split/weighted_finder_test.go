@@ -0,0 +1,214 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package split
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// TestWeightedSplitFinderKey verifies the Key() method correctly finds an
+// appropriate split point for the range, weighing samples by their load.
+func TestWeightedSplitFinderKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const ReservoirKeyOffset = 1000
+	key := func(i int) roachpb.Key {
+		return keys.SystemSQLCodec.TablePrefix(uint32(ReservoirKeyOffset + i))
+	}
+
+	// Test an empty reservoir (reservoir without load).
+	basicReservoir := [splitKeySampleSize]weightedSample{}
+
+	// Test a uniform reservoir.
+	uniformReservoir := [splitKeySampleSize]weightedSample{}
+	for i := 0; i < splitKeySampleSize; i++ {
+		uniformReservoir[i] = weightedSample{
+			key:   key(i),
+			left:  1000,
+			right: 1000,
+			count: splitKeyMinCounter,
+		}
+	}
+
+	// Test a reservoir whose samples are balanced by weight but have not
+	// observed enough requests.
+	insufficientCountReservoir := uniformReservoir
+	for i := range insufficientCountReservoir {
+		insufficientCountReservoir[i].count = splitKeyMinCounter - 1
+	}
+
+	// Test a non-uniform reservoir, where the weights are balanced around the
+	// middle key. The counts are uniform and carry no weight.
+	nonUniformReservoir := [splitKeySampleSize]weightedSample{}
+	for i := 0; i < splitKeySampleSize; i++ {
+		nonUniformReservoir[i] = weightedSample{
+			key:   key(i),
+			left:  float64(1000 * i),
+			right: float64(1000 * (splitKeySampleSize - i)),
+			count: splitKeyMinCounter,
+		}
+	}
+
+	// Test a spanning reservoir where splits shouldn't occur.
+	spanningReservoir := [splitKeySampleSize]weightedSample{}
+	for i := 0; i < splitKeySampleSize; i++ {
+		spanningReservoir[i] = weightedSample{
+			key:       key(i),
+			left:      1000,
+			right:     1000,
+			contained: 2000,
+			count:     splitKeyMinCounter,
+		}
+	}
+
+	testCases := []struct {
+		reservoir      [splitKeySampleSize]weightedSample
+		splitByLoadKey roachpb.Key
+	}{
+		// Test an empty reservoir.
+		{basicReservoir, nil},
+		// Test a uniform reservoir (Splits at the first key).
+		{uniformReservoir, key(0)},
+		// Test a reservoir without sufficient requests.
+		{insufficientCountReservoir, nil},
+		// Testing a non-uniform reservoir.
+		{nonUniformReservoir, key(splitKeySampleSize / 2)},
+		// Test a spanning reservoir. Splitting will be bad here. Should avoid it.
+		{spanningReservoir, nil},
+	}
+
+	for i, test := range testCases {
+		finder := NewWeightedFinder(timeutil.Now())
+		finder.samples = test.reservoir
+		if splitByLoadKey := finder.Key(); !bytes.Equal(splitByLoadKey, test.splitByLoadKey) {
+			t.Errorf(
+				"%d: expected splitByLoadKey: %v, but got splitByLoadKey: %v",
+				i, test.splitByLoadKey, splitByLoadKey)
+		}
+	}
+}
+
+// TestWeightedSplitFinderRecorder verifies the Record() method correctly
+// records a weighted span.
+func TestWeightedSplitFinderRecorder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const ReservoirKeyOffset = 1000
+	key := func(i int) roachpb.Key {
+		return keys.SystemSQLCodec.TablePrefix(uint32(ReservoirKeyOffset + i))
+	}
+
+	// getLargest is an IntN function that returns the largest number possible
+	// in [0, n). It never leads to a replacement once the reservoir is full.
+	getLargest := func(n int) int {
+		var result int
+		if n > 0 {
+			result = n - 1
+		}
+		return result
+	}
+
+	// getZero is an IntN function that returns 0. It always leads to a
+	// replacement of the first sample once the reservoir is full, provided the
+	// recorded span carries weight.
+	getZero := func(n int) int { return 0 }
+
+	// Test recording a key query before the reservoir is full.
+	basicReservoir := [splitKeySampleSize]weightedSample{}
+	basicSpan := roachpb.Span{Key: key(0), EndKey: key(1)}
+	expectedBasicReservoir := [splitKeySampleSize]weightedSample{}
+	expectedBasicReservoir[0] = weightedSample{key: basicSpan.Key}
+
+	// Test recording a key query after the reservoir is full with replacement.
+	// The existing samples are credited with the span before one is replaced.
+	fullReservoir := [splitKeySampleSize]weightedSample{}
+	for i := 0; i < splitKeySampleSize; i++ {
+		fullReservoir[i] = weightedSample{key: key(i)}
+	}
+	replacementSpan := roachpb.Span{
+		Key:    key(splitKeySampleSize),
+		EndKey: key(splitKeySampleSize + 1),
+	}
+	expectedReplacementReservoir := fullReservoir
+	for i := 0; i < splitKeySampleSize; i++ {
+		expectedReplacementReservoir[i].right = 5
+		expectedReplacementReservoir[i].count = 1
+	}
+	expectedReplacementReservoir[0] = weightedSample{key: replacementSpan.Key}
+
+	// Test recording a key query after the reservoir is full without
+	// replacement.
+	fullSpan := roachpb.Span{Key: key(0), EndKey: key(1)}
+	expectedFullReservoir := fullReservoir
+	for i := 0; i < splitKeySampleSize; i++ {
+		expectedFullReservoir[i].left = 5
+		expectedFullReservoir[i].count = 1
+	}
+	expectedFullReservoir[0].left = 0
+	expectedFullReservoir[0].right = 5
+
+	// Test recording a spanning query.
+	spanningSpan := roachpb.Span{Key: key(-1), EndKey: key(splitKeySampleSize + 1)}
+	expectedSpanningReservoir := fullReservoir
+	for i := 0; i < splitKeySampleSize; i++ {
+		expectedSpanningReservoir[i].contained = 5
+		expectedSpanningReservoir[i].count = 1
+	}
+
+	// Test recording a query without weight, which is counted but never
+	// replaces a sample.
+	expectedNoWeightReservoir := fullReservoir
+	for i := 0; i < splitKeySampleSize; i++ {
+		expectedNoWeightReservoir[i].count = 1
+	}
+
+	testCases := []struct {
+		recordSpan        roachpb.Span
+		weight            float64
+		intNFn            func(int) int
+		currCount         int
+		currReservoir     [splitKeySampleSize]weightedSample
+		expectedReservoir [splitKeySampleSize]weightedSample
+	}{
+		// Test recording a key query before the reservoir is full.
+		{basicSpan, 5, getLargest, 0, basicReservoir, expectedBasicReservoir},
+		// Test recording a key query after the reservoir is full with replacement.
+		{replacementSpan, 5, getZero, splitKeySampleSize + 1, fullReservoir, expectedReplacementReservoir},
+		// Test recording a key query after the reservoir is full without replacement.
+		{fullSpan, 5, getLargest, splitKeySampleSize + 1, fullReservoir, expectedFullReservoir},
+		// Test recording a spanning query.
+		{spanningSpan, 5, getLargest, splitKeySampleSize + 1, fullReservoir, expectedSpanningReservoir},
+		// Test recording a query without weight.
+		{replacementSpan, 0, getZero, splitKeySampleSize + 1, fullReservoir, expectedNoWeightReservoir},
+	}
+
+	for i, test := range testCases {
+		finder := NewWeightedFinder(timeutil.Now())
+		finder.samples = test.currReservoir
+		finder.count = test.currCount
+		// Give the finder enough prior weight that the span is not sampled with
+		// certainty.
+		finder.totalWeight = 1000
+		finder.Record(test.recordSpan, test.weight, test.intNFn)
+		if !reflect.DeepEqual(finder.samples, test.expectedReservoir) {
+			t.Errorf(
+				"%d: expected reservoir: %v, but got reservoir: %v",
+				i, test.expectedReservoir, finder.samples)
+		}
+	}
+}
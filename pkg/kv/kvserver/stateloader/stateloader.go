@@ -73,6 +73,10 @@ func (rsl StateLoader) Load(
 		return kvserverpb.ReplicaState{}, err
 	}
 
+	if s.GCRetention, err = rsl.LoadGCRetention(ctx, reader); err != nil {
+		return kvserverpb.ReplicaState{}, err
+	}
+
 	as, err := rsl.LoadRangeAppliedState(ctx, reader)
 	if err != nil {
 		return kvserverpb.ReplicaState{}, err
@@ -126,6 +130,11 @@ func (rsl StateLoader) Save(
 	if err := rsl.SetGCThreshold(ctx, readWriter, ms, state.GCThreshold); err != nil {
 		return enginepb.MVCCStats{}, err
 	}
+	if state.GCRetention != nil {
+		if err := rsl.SetGCRetention(ctx, readWriter, ms, state.GCRetention); err != nil {
+			return enginepb.MVCCStats{}, err
+		}
+	}
 	if err := rsl.SetRaftTruncatedState(ctx, readWriter, state.TruncatedState); err != nil {
 		return enginepb.MVCCStats{}, err
 	}
@@ -268,6 +277,34 @@ func (rsl StateLoader) SetGCThreshold(
 		hlc.Timestamp{}, hlc.ClockTimestamp{}, nil, threshold)
 }
 
+// LoadGCRetention loads the downsampled GC retention state. It returns nil if
+// the range has never been subject to retention tiers.
+func (rsl StateLoader) LoadGCRetention(
+	ctx context.Context, reader storage.Reader,
+) (*roachpb.GCRetention, error) {
+	var r roachpb.GCRetention
+	found, err := storage.MVCCGetProto(ctx, reader, rsl.RangeGCRetentionKey(),
+		hlc.Timestamp{}, &r, storage.MVCCGetOptions{})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// SetGCRetention sets the downsampled GC retention state.
+func (rsl StateLoader) SetGCRetention(
+	ctx context.Context,
+	readWriter storage.ReadWriter,
+	ms *enginepb.MVCCStats,
+	retention *roachpb.GCRetention,
+) error {
+	if retention == nil {
+		return errors.New("cannot persist nil GCRetention")
+	}
+	return storage.MVCCPutProto(ctx, readWriter, ms, rsl.RangeGCRetentionKey(),
+		hlc.Timestamp{}, hlc.ClockTimestamp{}, nil, retention)
+}
+
 // LoadVersion loads the replica version.
 func (rsl StateLoader) LoadVersion(
 	ctx context.Context, reader storage.Reader,
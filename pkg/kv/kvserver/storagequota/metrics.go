@@ -0,0 +1,54 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storagequota
+
+import "github.com/cockroachdb/cockroach/pkg/util/metric"
+
+// Metrics is a metric.Struct for the Tracker.
+type Metrics struct {
+	TrackedTargets   *metric.Gauge
+	ExceededTargets  *metric.Gauge
+	RejectedRequests *metric.Counter
+}
+
+var _ metric.Struct = (*Metrics)(nil)
+
+var (
+	metaTrackedTargets = metric.Metadata{
+		Name:        "kv.storage_quota.tracked_targets",
+		Help:        "Number of tenants and tables with a storage quota whose usage is tracked",
+		Measurement: "Targets",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaExceededTargets = metric.Metadata{
+		Name:        "kv.storage_quota.exceeded_targets",
+		Help:        "Number of tenants and tables which have reached their storage quota",
+		Measurement: "Targets",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRejectedRequests = metric.Metadata{
+		Name:        "kv.storage_quota.rejected_requests",
+		Help:        "Number of write requests rejected because a tenant or table reached its storage quota",
+		Measurement: "Requests",
+		Unit:        metric.Unit_COUNT,
+	}
+)
+
+func makeMetrics() Metrics {
+	return Metrics{
+		TrackedTargets:   metric.NewGauge(metaTrackedTargets),
+		ExceededTargets:  metric.NewGauge(metaExceededTargets),
+		RejectedRequests: metric.NewCounter(metaRejectedRequests),
+	}
+}
+
+// MetricStruct indicates that Metrics is a metric.Struct
+func (m *Metrics) MetricStruct() {}
@@ -0,0 +1,52 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storagequota
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// Enabled controls whether writes are rejected once a tenant or table has
+// reached its storage quota. Usage is tracked regardless.
+var Enabled = settings.RegisterBoolSetting(
+	settings.SystemOnly,
+	"kv.storage_quota.enabled",
+	"if set, writes to tenants and tables which have reached their storage quota are rejected",
+	true,
+)
+
+// TenantQuota is the maximum logical bytes a secondary tenant may store. It is
+// meant to be set by the host cluster for a specific tenant with ALTER TENANT
+// <id> SET CLUSTER SETTING, or for all tenants with ALTER TENANT ALL SET
+// CLUSTER SETTING. KV only considers these overrides: the value of the setting
+// in the host cluster itself has no effect.
+var TenantQuota = settings.RegisterByteSizeSetting(
+	settings.TenantReadOnly,
+	"kv.storage_quota.tenant_logical_bytes",
+	"maximum logical bytes the tenant may store, or 0 to disable; set by the host cluster "+
+		"with ALTER TENANT ... SET CLUSTER SETTING",
+	0,
+	settings.NonNegativeInt,
+)
+
+// ReportInterval is the interval at which each store gossips the usage of
+// the ranges it holds the lease for. It bounds how quickly the quotas react
+// to a change in usage.
+var ReportInterval = settings.RegisterDurationSetting(
+	settings.SystemOnly,
+	"kv.storage_quota.usage_report_interval",
+	"the interval at which each store reports the logical bytes stored by tenants and tables "+
+		"subject to a storage quota",
+	10*time.Second,
+	settings.PositiveDuration,
+)
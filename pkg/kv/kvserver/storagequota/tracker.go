@@ -0,0 +1,335 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package storagequota caps the logical bytes stored by tenants and tables.
+//
+// The quota of a secondary tenant is set by the host cluster by overriding the
+// kv.storage_quota.tenant_logical_bytes setting for the tenant. The quota of a
+// table is the storage_quota_bytes field of its zone config, which reaches KV
+// through the span config of each of the table's ranges.
+//
+// The usage of a tenant or table is the sum of the logical bytes in the
+// MVCCStats of its ranges. Every store periodically sums the stats of the
+// ranges it holds the lease for and gossips the result, and the Tracker of
+// each store aggregates the usage gossiped by all stores. Writes to a tenant
+// or table whose usage has reached its quota are rejected at evaluation.
+//
+// Enforcement is approximate: usage lags behind writes by up to the report
+// interval, and a range whose lease moved between reports may briefly be
+// counted on two stores or on none. Quotas are meant to stop runaway growth
+// rather than to be exact. Writes to system tables are never rejected, so that
+// a tenant which has reached its quota remains operable and can delete data.
+package storagequota
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
+)
+
+// Target identifies the logical bytes a storage quota applies to: those of a
+// tenant, or those of a table within a tenant.
+type Target struct {
+	TenantID roachpb.TenantID
+	// TableID is zero if the target is the tenant as a whole.
+	TableID uint32
+}
+
+// SafeFormat implements the redact.SafeFormatter interface.
+func (t Target) SafeFormat(w redact.SafePrinter, _ rune) {
+	switch {
+	case t.TableID == 0:
+		w.Printf("tenant %d", t.TenantID.ToUint64())
+	case t.TenantID == roachpb.SystemTenantID:
+		w.Printf("table %d", t.TableID)
+	default:
+		w.Printf("table %d of tenant %d", t.TableID, t.TenantID.ToUint64())
+	}
+}
+
+func (t Target) String() string {
+	return redact.StringWithoutMarkers(t)
+}
+
+// TargetsForKey returns the tenant and table targets which the range starting
+// at the given key counts towards. It returns false if writes to the range are
+// not subject to a quota, which is the case for ranges outside of the tables
+// of a tenant and for the ranges of system tables.
+func TargetsForKey(key roachpb.RKey) (tenant, table Target, ok bool) {
+	_, tenantID, err := keys.DecodeTenantPrefix(key.AsRawKey())
+	if err != nil {
+		return Target{}, Target{}, false
+	}
+	_, tableID, err := keys.MakeSQLCodec(tenantID).DecodeTablePrefix(key.AsRawKey())
+	if err != nil || tableID <= keys.MaxReservedDescID {
+		return Target{}, Target{}, false
+	}
+	return Target{TenantID: tenantID}, Target{TenantID: tenantID, TableID: tableID}, true
+}
+
+// TenantSettingOverrides provides the cluster setting overrides which the host
+// cluster has set for tenants. It is implemented by
+// tenantsettingswatcher.Watcher.
+type TenantSettingOverrides interface {
+	GetTenantOverrides(roachpb.TenantID) (overrides []roachpb.TenantSetting, changeCh <-chan struct{})
+	GetAllTenantOverrides() (overrides []roachpb.TenantSetting, changeCh <-chan struct{})
+}
+
+// Tracker tracks the cluster-wide usage of the tenants and tables with a
+// storage quota, and checks whether writes to them are to be rejected.
+type Tracker struct {
+	st        *cluster.Settings
+	overrides TenantSettingOverrides
+	metrics   Metrics
+
+	mu struct {
+		syncutil.RWMutex
+		// usage is the cluster-wide logical bytes stored by each target.
+		usage map[Target]int64
+		// tenantQuotas is the quota of each tenant in usage, as of the last
+		// update.
+		tenantQuotas map[roachpb.TenantID]int64
+	}
+}
+
+// NewTracker constructs a Tracker. The overrides may be nil, in which case
+// tenants aren't subject to a quota.
+func NewTracker(st *cluster.Settings, overrides TenantSettingOverrides) *Tracker {
+	t := &Tracker{
+		st:        st,
+		overrides: overrides,
+		metrics:   makeMetrics(),
+	}
+	t.mu.usage = make(map[Target]int64)
+	t.mu.tenantQuotas = make(map[roachpb.TenantID]int64)
+	return t
+}
+
+// Metrics returns the metrics of the Tracker.
+func (t *Tracker) Metrics() *Metrics {
+	return &t.metrics
+}
+
+// TenantQuota returns the storage quota of the tenant, or zero if the tenant
+// isn't subject to a quota. The system tenant never is.
+func (t *Tracker) TenantQuota(tenantID roachpb.TenantID) int64 {
+	if t.overrides == nil || tenantID == roachpb.SystemTenantID {
+		return 0
+	}
+	overrides, _ := t.overrides.GetTenantOverrides(tenantID)
+	if quota, ok := quotaFromOverrides(overrides); ok {
+		return quota
+	}
+	overrides, _ = t.overrides.GetAllTenantOverrides()
+	quota, _ := quotaFromOverrides(overrides)
+	return quota
+}
+
+func quotaFromOverrides(overrides []roachpb.TenantSetting) (int64, bool) {
+	for _, o := range overrides {
+		if o.Name != TenantQuota.Key() {
+			continue
+		}
+		quota, err := TenantQuota.DecodeValue(o.Value.Value)
+		if err != nil {
+			return 0, false
+		}
+		return quota, true
+	}
+	return 0, false
+}
+
+// Update replaces the usage tracked by the Tracker with the sum of the given
+// per-store reports, and refreshes the quotas of the tracked tenants.
+func (t *Tracker) Update(reports []kvserverpb.StorageQuotaUsage) {
+	usage := make(map[Target]int64)
+	tenantQuotas := make(map[roachpb.TenantID]int64)
+	var exceeded int64
+	for _, u := range Aggregate(reports) {
+		target := Target{TenantID: u.TenantID, TableID: u.TableID}
+		usage[target] = u.LogicalBytes
+		quota := u.QuotaBytes
+		if target.TableID == 0 {
+			quota = t.TenantQuota(target.TenantID)
+			tenantQuotas[target.TenantID] = quota
+		}
+		if quota > 0 && u.LogicalBytes >= quota {
+			exceeded++
+		}
+	}
+
+	t.mu.Lock()
+	t.mu.usage = usage
+	t.mu.tenantQuotas = tenantQuotas
+	t.mu.Unlock()
+
+	t.metrics.TrackedTargets.Update(int64(len(usage)))
+	t.metrics.ExceededTargets.Update(exceeded)
+}
+
+// Check returns an error if writes to the range starting at the given key are
+// to be rejected because its tenant or table has reached its storage quota.
+// tableQuota is the quota in the span config of the range.
+func (t *Tracker) Check(startKey roachpb.RKey, tableQuota int64) error {
+	if !Enabled.Get(&t.st.SV) {
+		return nil
+	}
+	tenant, table, ok := TargetsForKey(startKey)
+	if !ok {
+		return nil
+	}
+
+	t.mu.RLock()
+	tenantUsage, tenantQuota := t.mu.usage[tenant], t.mu.tenantQuotas[tenant.TenantID]
+	tableUsage := t.mu.usage[table]
+	t.mu.RUnlock()
+
+	if tenantQuota > 0 && tenantUsage >= tenantQuota {
+		t.metrics.RejectedRequests.Inc(1)
+		return newQuotaExceededError(tenant, tenantUsage, tenantQuota)
+	}
+	if tableQuota > 0 && tableUsage >= tableQuota {
+		t.metrics.RejectedRequests.Inc(1)
+		return newQuotaExceededError(table, tableUsage, tableQuota)
+	}
+	return nil
+}
+
+func newQuotaExceededError(target Target, usage, quota int64) error {
+	err := errors.Newf("%s has reached its storage quota: %s used of %s",
+		target, humanizeutil.IBytes(usage), humanizeutil.IBytes(quota))
+	err = errors.WithHint(err, "Delete data to free up space. Deleted data counts "+
+		"towards the quota until it is garbage collected.")
+	return pgerror.WithCandidateCode(err, pgcode.ConfigurationLimitExceeded)
+}
+
+// UsageBuilder sums the logical bytes of the ranges a store holds the lease
+// for into the usage report of the store.
+type UsageBuilder struct {
+	usage map[Target]*kvserverpb.StorageQuotaTargetUsage
+}
+
+// NewUsageBuilder constructs an empty UsageBuilder.
+func NewUsageBuilder() *UsageBuilder {
+	return &UsageBuilder{usage: make(map[Target]*kvserverpb.StorageQuotaTargetUsage)}
+}
+
+// Add adds the logical bytes of the range starting at the given key to its
+// targets. tableQuota is the quota in the span config of the range. The usage
+// of a table is only reported if the table has a quota, whereas the usage of
+// every secondary tenant is reported.
+func (b *UsageBuilder) Add(startKey roachpb.RKey, logicalBytes int64, tableQuota int64) {
+	tenant, table, ok := TargetsForKey(startKey)
+	if !ok {
+		return
+	}
+	if tenant.TenantID != roachpb.SystemTenantID {
+		b.add(tenant, logicalBytes, 0)
+	}
+	if tableQuota > 0 {
+		b.add(table, logicalBytes, tableQuota)
+	}
+}
+
+func (b *UsageBuilder) add(target Target, logicalBytes int64, quota int64) {
+	u, ok := b.usage[target]
+	if !ok {
+		u = &kvserverpb.StorageQuotaTargetUsage{TenantID: target.TenantID, TableID: target.TableID}
+		b.usage[target] = u
+	}
+	u.LogicalBytes += logicalBytes
+	if quota > u.QuotaBytes {
+		u.QuotaBytes = quota
+	}
+}
+
+// Build returns the usage report of the store. The quota of each tenant is
+// looked up with tenantQuota.
+func (b *UsageBuilder) Build(
+	storeID roachpb.StoreID, tenantQuota func(roachpb.TenantID) int64,
+) kvserverpb.StorageQuotaUsage {
+	report := kvserverpb.StorageQuotaUsage{
+		StoreID: storeID,
+		Targets: make([]kvserverpb.StorageQuotaTargetUsage, 0, len(b.usage)),
+	}
+	for target, u := range b.usage {
+		if target.TableID == 0 {
+			u.QuotaBytes = tenantQuota(target.TenantID)
+		}
+		report.Targets = append(report.Targets, *u)
+	}
+	sortTargets(report.Targets)
+	return report
+}
+
+// Aggregate sums the usage of each target over the given per-store reports.
+// The result is ordered by tenant and table.
+func Aggregate(reports []kvserverpb.StorageQuotaUsage) []kvserverpb.StorageQuotaTargetUsage {
+	b := NewUsageBuilder()
+	for _, r := range reports {
+		for _, u := range r.Targets {
+			b.add(Target{TenantID: u.TenantID, TableID: u.TableID}, u.LogicalBytes, u.QuotaBytes)
+		}
+	}
+	targets := make([]kvserverpb.StorageQuotaTargetUsage, 0, len(b.usage))
+	for _, u := range b.usage {
+		targets = append(targets, *u)
+	}
+	sortTargets(targets)
+	return targets
+}
+
+func sortTargets(targets []kvserverpb.StorageQuotaTargetUsage) {
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].TenantID != targets[j].TenantID {
+			return targets[i].TenantID.ToUint64() < targets[j].TenantID.ToUint64()
+		}
+		return targets[i].TableID < targets[j].TableID
+	})
+}
+
+// UsageFromGossip returns the unexpired usage reports gossiped by the stores
+// in the cluster.
+func UsageFromGossip(g *gossip.Gossip) ([]kvserverpb.StorageQuotaUsage, error) {
+	now := timeutil.Now().UnixNano()
+	var reports []kvserverpb.StorageQuotaUsage
+	if err := g.IterateInfos(gossip.KeyStorageQuotaUsagePrefix, func(key string, i gossip.Info) error {
+		if i.TTLStamp <= now {
+			return nil
+		}
+		bytes, err := i.Value.GetBytes()
+		if err != nil {
+			return errors.NewAssertionErrorWithWrappedErrf(err,
+				"failed to extract bytes for key %q", key)
+		}
+		var report kvserverpb.StorageQuotaUsage
+		if err := protoutil.Unmarshal(bytes, &report); err != nil {
+			return errors.NewAssertionErrorWithWrappedErrf(err,
+				"failed to parse value for key %q", key)
+		}
+		reports = append(reports, report)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storagequota_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/storagequota"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOverrides is a storagequota.TenantSettingOverrides which holds the
+// quota of each tenant, and of all tenants under the zero TenantID.
+type fakeOverrides map[roachpb.TenantID]int64
+
+func (f fakeOverrides) get(tenantID roachpb.TenantID) []roachpb.TenantSetting {
+	quota, ok := f[tenantID]
+	if !ok {
+		return nil
+	}
+	return []roachpb.TenantSetting{{
+		Name: storagequota.TenantQuota.Key(),
+		Value: settings.EncodedValue{
+			Value: settings.EncodeInt(quota),
+			Type:  storagequota.TenantQuota.Typ(),
+		},
+	}}
+}
+
+func (f fakeOverrides) GetTenantOverrides(
+	tenantID roachpb.TenantID,
+) ([]roachpb.TenantSetting, <-chan struct{}) {
+	return f.get(tenantID), nil
+}
+
+func (f fakeOverrides) GetAllTenantOverrides() ([]roachpb.TenantSetting, <-chan struct{}) {
+	return f.get(roachpb.TenantID{}), nil
+}
+
+func tableKey(tenantID roachpb.TenantID, tableID uint32) roachpb.RKey {
+	return roachpb.RKey(keys.MakeSQLCodec(tenantID).TablePrefix(tableID))
+}
+
+func TestTargetsForKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ten5 := roachpb.MakeTenantID(5)
+	for _, tc := range []struct {
+		key    roachpb.RKey
+		tenant storagequota.Target
+		table  storagequota.Target
+		ok     bool
+	}{
+		{key: roachpb.RKeyMin},
+		{key: roachpb.RKey(keys.NodeLivenessPrefix)},
+		{key: tableKey(roachpb.SystemTenantID, keys.DescriptorTableID)},
+		{key: roachpb.RKey(keys.MakeTenantPrefix(ten5))},
+		{key: tableKey(ten5, keys.SqllivenessID)},
+		{
+			key:    tableKey(roachpb.SystemTenantID, 104),
+			tenant: storagequota.Target{TenantID: roachpb.SystemTenantID},
+			table:  storagequota.Target{TenantID: roachpb.SystemTenantID, TableID: 104},
+			ok:     true,
+		},
+		{
+			key:    tableKey(ten5, 104),
+			tenant: storagequota.Target{TenantID: ten5},
+			table:  storagequota.Target{TenantID: ten5, TableID: 104},
+			ok:     true,
+		},
+	} {
+		t.Run(tc.key.String(), func(t *testing.T) {
+			tenant, table, ok := storagequota.TargetsForKey(tc.key)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.tenant, tenant)
+			require.Equal(t, tc.table, table)
+		})
+	}
+}
+
+func TestTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	ten5, ten6, ten7 := roachpb.MakeTenantID(5), roachpb.MakeTenantID(6), roachpb.MakeTenantID(7)
+	// Tenant 5 has a quota of its own, tenant 6 is subject to the quota of all
+	// tenants, and tenant 7 is never reported.
+	overrides := fakeOverrides{ten5: 100, roachpb.TenantID{}: 1000}
+	tracker := storagequota.NewTracker(st, overrides)
+
+	// Two stores hold the leases for the ranges of tenant 5, table 104 of
+	// tenant 6 and table 104 of the system tenant.
+	b1 := storagequota.NewUsageBuilder()
+	b1.Add(tableKey(ten5, 104), 60, 0)
+	b1.Add(tableKey(ten6, 104), 400, 500)
+	b1.Add(tableKey(roachpb.SystemTenantID, 104), 40, 50)
+	b1.Add(tableKey(roachpb.SystemTenantID, keys.DescriptorTableID), 1000, 50)
+	b2 := storagequota.NewUsageBuilder()
+	b2.Add(tableKey(ten5, 105), 30, 0)
+	b2.Add(tableKey(ten6, 104), 50, 500)
+	b2.Add(tableKey(roachpb.SystemTenantID, 104), 5, 50)
+	reports := []kvserverpb.StorageQuotaUsage{
+		b1.Build(1, tracker.TenantQuota),
+		b2.Build(2, tracker.TenantQuota),
+	}
+
+	tenantUsage := func(tenantID roachpb.TenantID, bytes, quota int64) kvserverpb.StorageQuotaTargetUsage {
+		return kvserverpb.StorageQuotaTargetUsage{TenantID: tenantID, LogicalBytes: bytes, QuotaBytes: quota}
+	}
+	tableUsage := func(tenantID roachpb.TenantID, tableID uint32, bytes, quota int64) kvserverpb.StorageQuotaTargetUsage {
+		return kvserverpb.StorageQuotaTargetUsage{
+			TenantID: tenantID, TableID: tableID, LogicalBytes: bytes, QuotaBytes: quota,
+		}
+	}
+	require.Equal(t, []kvserverpb.StorageQuotaTargetUsage{
+		tableUsage(roachpb.SystemTenantID, 104, 45, 50),
+		tenantUsage(ten5, 90, 100),
+		tenantUsage(ten6, 450, 1000),
+		tableUsage(ten6, 104, 450, 500),
+	}, storagequota.Aggregate(reports))
+
+	// Nothing has reached its quota yet.
+	tracker.Update(reports)
+	require.Equal(t, int64(4), tracker.Metrics().TrackedTargets.Value())
+	require.Equal(t, int64(0), tracker.Metrics().ExceededTargets.Value())
+	require.NoError(t, tracker.Check(tableKey(ten5, 104), 0))
+	require.NoError(t, tracker.Check(tableKey(ten6, 104), 500))
+	require.NoError(t, tracker.Check(tableKey(ten7, 104), 0))
+	require.NoError(t, tracker.Check(tableKey(roachpb.SystemTenantID, 104), 50))
+
+	// Tenant 5 and table 104 of the system tenant reach their quotas.
+	b3 := storagequota.NewUsageBuilder()
+	b3.Add(tableKey(ten5, 106), 10, 0)
+	b3.Add(tableKey(roachpb.SystemTenantID, 104), 5, 50)
+	reports = append(reports, b3.Build(3, tracker.TenantQuota))
+	tracker.Update(reports)
+	require.Equal(t, int64(2), tracker.Metrics().ExceededTargets.Value())
+
+	err := tracker.Check(tableKey(ten5, 107), 0)
+	require.Regexp(t, `tenant 5 has reached its storage quota: 100 B used of 100 B`, err)
+	require.Equal(t, pgcode.ConfigurationLimitExceeded, pgerror.GetPGCode(err))
+	err = tracker.Check(tableKey(roachpb.SystemTenantID, 104), 50)
+	require.Regexp(t, `table 104 has reached its storage quota`, err)
+	require.Equal(t, int64(2), tracker.Metrics().RejectedRequests.Count())
+
+	// Other targets, system tables and tables without a quota are unaffected.
+	require.NoError(t, tracker.Check(tableKey(ten5, keys.SqllivenessID), 0))
+	require.NoError(t, tracker.Check(tableKey(ten6, 104), 500))
+	require.NoError(t, tracker.Check(tableKey(roachpb.SystemTenantID, 104), 0))
+	require.NoError(t, tracker.Check(tableKey(roachpb.SystemTenantID, 105), 50))
+
+	// Raising a quota takes effect on the next update.
+	overrides[ten5] = 1000
+	require.Error(t, tracker.Check(tableKey(ten5, 104), 0))
+	tracker.Update(reports)
+	require.NoError(t, tracker.Check(tableKey(ten5, 104), 0))
+
+	// Nothing is rejected while enforcement is disabled.
+	storagequota.Enabled.Override(ctx, &st.SV, false)
+	require.NoError(t, tracker.Check(tableKey(roachpb.SystemTenantID, 104), 50))
+}
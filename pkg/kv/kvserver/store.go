@@ -39,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts/sidetransport"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/idalloc"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/intentresolver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
@@ -46,6 +47,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/raftentry"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rangefeed"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/replicastats"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/storagequota"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/tenantrate"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/tscache"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/txnrecovery"
@@ -956,6 +959,10 @@ type Store struct {
 	// tenantRateLimiters manages tenantrate.Limiters
 	tenantRateLimiters *tenantrate.LimiterFactory
 
+	// storageQuota tracks the usage of the tenants and tables with a storage
+	// quota.
+	storageQuota *storagequota.Tracker
+
 	computeInitialMetrics              sync.Once
 	systemConfigUpdateQueueRateLimiter *quotapool.RateLimiter
 	spanConfigUpdateQueueRateLimiter   *quotapool.RateLimiter
@@ -1079,6 +1086,11 @@ type StoreConfig struct {
 	// KVAdmissionController is an optional field used for admission control.
 	KVAdmissionController KVAdmissionController
 
+	// TenantSettingOverrides provides the storage quotas of secondary tenants,
+	// which are set by the host cluster as tenant setting overrides. Can be nil
+	// in tests, in which case tenants aren't subject to a storage quota.
+	TenantSettingOverrides storagequota.TenantSettingOverrides
+
 	// SystemConfigProvider is used to drive replication decision-making in the
 	// mixed-version state, before the span configuration infrastructure has been
 	// bootstrapped.
@@ -1293,6 +1305,9 @@ func NewStore(
 	s.tenantRateLimiters = tenantrate.NewLimiterFactory(&cfg.Settings.SV, &cfg.TestingKnobs.TenantRateKnobs)
 	s.metrics.registry.AddMetricStruct(s.tenantRateLimiters.Metrics())
 
+	s.storageQuota = storagequota.NewTracker(cfg.Settings, cfg.TenantSettingOverrides)
+	s.metrics.registry.AddMetricStruct(s.storageQuota.Metrics())
+
 	s.systemConfigUpdateQueueRateLimiter = quotapool.NewRateLimiter(
 		"SystemConfigUpdateQueue",
 		quotapool.Limit(queueAdditionOnSystemConfigUpdateRate.Get(&cfg.Settings.SV)),
@@ -2008,6 +2023,10 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 				return
 			}
 		})
+
+		// Start reporting the usage of the tenants and tables with a storage
+		// quota.
+		s.startStorageQuotaReporter(ctx)
 	}
 
 	if !s.cfg.SpanConfigsDisabled {
@@ -2958,6 +2977,7 @@ func (s *Store) Capacity(ctx context.Context, useCached bool) (roachpb.StoreCapa
 	var l0SublevelsMax int64
 	var totalQueriesPerSecond float64
 	var totalWritesPerSecond float64
+	var totalCPUPerSecond float64
 	replicaCount := s.metrics.ReplicaCount.Value()
 	bytesPerReplica := make([]float64, 0, replicaCount)
 	writesPerReplica := make([]float64, 0, replicaCount)
@@ -2983,13 +3003,23 @@ func (s *Store) Capacity(ctx context.Context, useCached bool) (roachpb.StoreCapa
 			totalQueriesPerSecond += avgQPS
 			// TODO(a-robinson): Calculate percentiles for qps? Get rid of other percentiles?
 		}
-		if wps, dur := r.writeStats.AverageRatePerSecond(); dur >= replicastats.MinStatsDuration {
-			totalWritesPerSecond += wps
-			writesPerReplica = append(writesPerReplica, wps)
+		var wps float64
+		if avgWPS, dur := r.writeStats.AverageRatePerSecond(); dur >= replicastats.MinStatsDuration {
+			wps = avgWPS
+			totalWritesPerSecond += avgWPS
+			writesPerReplica = append(writesPerReplica, avgWPS)
+		}
+		var cpu float64
+		if avgCPU, dur := r.loadStats.requestCPUNanos.AverageRatePerSecond(); dur >= replicastats.MinStatsDuration {
+			cpu = avgCPU
+			totalCPUPerSecond += avgCPU
 		}
 		rankingsAccumulator.addReplica(replicaWithStats{
-			repl: r,
-			qps:  qps,
+			repl:         r,
+			qps:          qps,
+			cpu:          cpu,
+			wps:          wps,
+			logicalBytes: mvccStats.Total(),
 		})
 		return true
 	})
@@ -2997,6 +3027,7 @@ func (s *Store) Capacity(ctx context.Context, useCached bool) (roachpb.StoreCapa
 	capacity.LeaseCount = leaseCount
 	capacity.LogicalBytes = logicalBytes
 	capacity.QueriesPerSecond = totalQueriesPerSecond
+	capacity.CPUPerSecond = totalCPUPerSecond
 	capacity.WritesPerSecond = totalWritesPerSecond
 	capacity.L0Sublevels = l0SublevelsMax
 	capacity.BytesPerReplica = roachpb.PercentilesFromData(bytesPerReplica)
@@ -3333,6 +3364,7 @@ type HotReplicaInfo struct {
 	WriteKeysPerSecond  float64
 	WriteBytesPerSecond float64
 	ReadBytesPerSecond  float64
+	LogicalBytes        int64
 }
 
 // HottestReplicas returns the hottest replicas on a store, sorted by their
@@ -3341,20 +3373,72 @@ type HotReplicaInfo struct {
 // Note that this uses cached information, so it's cheap but may be slightly
 // out of date.
 func (s *Store) HottestReplicas() []HotReplicaInfo {
-	topQPS := s.replRankings.topQPS()
-	hotRepls := make([]HotReplicaInfo, len(topQPS))
-	for i := range topQPS {
-		hotRepls[i].Desc = topQPS[i].repl.Desc()
-		hotRepls[i].QPS = topQPS[i].qps
-		hotRepls[i].RequestsPerSecond = topQPS[i].repl.RequestsPerSecond()
-		hotRepls[i].WriteKeysPerSecond = topQPS[i].repl.WritesPerSecond()
-		hotRepls[i].ReadKeysPerSecond = topQPS[i].repl.ReadsPerSecond()
-		hotRepls[i].WriteBytesPerSecond = topQPS[i].repl.WriteBytesPerSecond()
-		hotRepls[i].ReadBytesPerSecond = topQPS[i].repl.ReadBytesPerSecond()
+	return makeHotReplicaInfos(s.replRankings.topQPS())
+}
+
+// HottestReplicasByWrites returns the replicas on a store with the most keys
+// written per second, sorted by their writes per second. Unlike
+// HottestReplicas, it includes the replicas that are not leaseholders.
+//
+// Note that this uses cached information, so it's cheap but may be slightly
+// out of date.
+func (s *Store) HottestReplicasByWrites() []HotReplicaInfo {
+	return makeHotReplicaInfos(s.replRankings.topWPS())
+}
+
+// LargestReplicas returns the replicas on a store with the most logical bytes,
+// sorted by their logical bytes.
+//
+// Note that this uses cached information, so it's cheap but may be slightly
+// out of date.
+func (s *Store) LargestReplicas() []HotReplicaInfo {
+	return makeHotReplicaInfos(s.replRankings.topLogicalBytes())
+}
+
+func makeHotReplicaInfos(repls []replicaWithStats) []HotReplicaInfo {
+	hotRepls := make([]HotReplicaInfo, len(repls))
+	for i := range repls {
+		hotRepls[i].Desc = repls[i].repl.Desc()
+		hotRepls[i].QPS = repls[i].qps
+		hotRepls[i].RequestsPerSecond = repls[i].repl.RequestsPerSecond()
+		hotRepls[i].WriteKeysPerSecond = repls[i].repl.WritesPerSecond()
+		hotRepls[i].ReadKeysPerSecond = repls[i].repl.ReadsPerSecond()
+		hotRepls[i].WriteBytesPerSecond = repls[i].repl.WriteBytesPerSecond()
+		hotRepls[i].ReadBytesPerSecond = repls[i].repl.ReadBytesPerSecond()
+		hotRepls[i].LogicalBytes = repls[i].logicalBytes
 	}
 	return hotRepls
 }
 
+// ReplicaPushWaiter is a PushTxn request waiting in the txn wait queue of a
+// replica.
+type ReplicaPushWaiter struct {
+	RangeID roachpb.RangeID
+	txnwait.PushWaiter
+}
+
+// LockWaits returns the contended locks tracked in the lock tables of the
+// replicas on the store, along with the PushTxn requests waiting in their txn
+// wait queues. Only the leaseholder of a range tracks this state, so the
+// result only covers the ranges for which the store holds the lease.
+func (s *Store) LockWaits(
+	ctx context.Context,
+) (locks []roachpb.LockStateInfo, pushes []ReplicaPushWaiter) {
+	newStoreReplicaVisitor(s).Visit(func(repl *Replica) bool {
+		concMgr := repl.GetConcurrencyManager()
+		span := repl.Desc().RSpan().AsRawSpanWithNoLocals()
+		replLocks, _ := concMgr.QueryLockTableState(ctx, span, concurrency.QueryLockTableOptions{
+			KeyScope: spanset.SpanGlobal,
+		})
+		locks = append(locks, replLocks...)
+		for _, w := range concMgr.QueryPushWaiters() {
+			pushes = append(pushes, ReplicaPushWaiter{RangeID: repl.RangeID, PushWaiter: w})
+		}
+		return true
+	})
+	return locks, pushes
+}
+
 // StoreKeySpanStats carries the result of a stats computation over a key range.
 type StoreKeySpanStats struct {
 	ReplicaCount         int
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator/allocatorimpl"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator/storepool"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/raftutil"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/split"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -91,6 +92,72 @@ const (
 	LBRebalancingLeasesAndReplicas
 )
 
+// LoadBasedRebalancingObjective controls the dimension of load that
+// load-based rebalancing and load-based splitting attempt to balance across
+// stores and ranges respectively.
+var LoadBasedRebalancingObjective = settings.RegisterEnumSetting(
+	settings.SystemOnly,
+	"kv.allocator.load_based_rebalancing.objective",
+	"what load based rebalancing and load based splitting should balance, either "+
+		"queries per second or the CPU time spent evaluating requests; the cpu "+
+		"objective only takes effect once the cluster version has been finalized",
+	"qps",
+	map[int64]string{
+		int64(LBRebalancingQueries): "qps",
+		int64(LBRebalancingCPU):     "cpu",
+	},
+)
+
+// LBRebalancingObjective is the dimension of load that load-based rebalancing
+// and load-based splitting attempt to balance.
+type LBRebalancingObjective int64
+
+const (
+	// LBRebalancingQueries balances the number of batch requests per second.
+	LBRebalancingQueries LBRebalancingObjective = iota
+	// LBRebalancingCPU balances the CPU time spent evaluating requests.
+	//
+	// NB: The CPU time attributed to requests is measured for a sample of the
+	// batches evaluated on each replica, excluding the time spent waiting on
+	// latches, locks and replication, and scaled to all of the batches. On
+	// platforms where the CPU time of a thread is not available, it is
+	// approximated by the wall time spent evaluating each batch. See
+	// Replica.recordRequestCPU.
+	LBRebalancingCPU
+)
+
+// ResolveLBRebalancingObjective returns the objective that load-based
+// rebalancing and splitting should use. The CPU objective is only used once
+// every node in the cluster gossips the CPU usage of its stores; until then,
+// the QPS objective is used regardless of the setting.
+func ResolveLBRebalancingObjective(
+	ctx context.Context, st *cluster.Settings,
+) LBRebalancingObjective {
+	objective := LBRebalancingObjective(LoadBasedRebalancingObjective.Get(&st.SV))
+	if objective == LBRebalancingCPU &&
+		!st.Version.IsActive(ctx, clusterversion.CPUBasedRebalancing) {
+		return LBRebalancingQueries
+	}
+	return objective
+}
+
+// ToDimension returns the allocator load dimension of the objective.
+func (o LBRebalancingObjective) ToDimension() allocator.LoadDimension {
+	if o == LBRebalancingCPU {
+		return allocator.CPU
+	}
+	return allocator.Queries
+}
+
+// ToSplitObjective returns the load-based splitting objective of the
+// objective.
+func (o LBRebalancingObjective) ToSplitObjective() split.SplitObjective {
+	if o == LBRebalancingCPU {
+		return split.SplitCPU
+	}
+	return split.SplitQPS
+}
+
 // StoreRebalancer is responsible for examining how the associated store's load
 // compares to the load on other stores in the cluster and transferring leases
 // or replicas away if the local store is overloaded.
@@ -172,23 +239,28 @@ func (sr *StoreRebalancer) Start(ctx context.Context, stopper *stop.Stopper) {
 				continue
 			}
 
+			objective := ResolveLBRebalancingObjective(ctx, sr.st)
 			storeList, _, _ := sr.rq.store.cfg.StorePool.GetStoreList(storepool.StoreFilterSuspect)
-			sr.rebalanceStore(ctx, mode, storeList)
+			sr.rebalanceStore(ctx, mode, objective.ToDimension(), storeList)
 		}
 	})
 }
 
-// NB: The StoreRebalancer only cares about the convergence of QPS across
-// stores, not the convergence of range count. So, we don't use the allocator's
-// `scorerOptions` here, which sets the range count rebalance threshold.
-// Instead, we use our own implementation of `scorerOptions` that promotes QPS
-// balance.
-func (sr *StoreRebalancer) scorerOptions(ctx context.Context) *allocatorimpl.QPSScorerOptions {
+// NB: The StoreRebalancer only cares about the convergence of load (QPS or
+// CPU) across stores, not the convergence of range count. So, we don't use the
+// allocator's `scorerOptions` here, which sets the range count rebalance
+// threshold. Instead, we use our own implementation of `scorerOptions` that
+// promotes load balance in the given dimension.
+func (sr *StoreRebalancer) scorerOptions(
+	ctx context.Context, dim allocator.LoadDimension,
+) *allocatorimpl.QPSScorerOptions {
 	return &allocatorimpl.QPSScorerOptions{
-		StoreHealthOptions:    sr.rq.allocator.StoreHealthOptions(ctx),
-		Deterministic:         sr.rq.store.cfg.StorePool.Deterministic,
-		QPSRebalanceThreshold: allocator.QPSRebalanceThreshold.Get(&sr.st.SV),
-		MinRequiredQPSDiff:    allocator.MinQPSDifferenceForTransfers.Get(&sr.st.SV),
+		StoreHealthOptions:        sr.rq.allocator.StoreHealthOptions(ctx),
+		Deterministic:             sr.rq.store.cfg.StorePool.Deterministic,
+		LoadDimension:             dim,
+		QPSRebalanceThreshold:     allocator.QPSRebalanceThreshold.Get(&sr.st.SV),
+		MinRequiredQPSDiff:        dim.MinDifferenceForTransfers(&sr.st.SV),
+		MinCPUThresholdDifference: float64(allocator.MinCPUThresholdDifference.Get(&sr.st.SV)),
 	}
 }
 
@@ -203,9 +275,12 @@ func (sr *StoreRebalancer) scorerOptions(ctx context.Context) *allocatorimpl.QPS
 // of all the stores in the cluster. Is this desirable? Should we be more
 // aggressive?
 func (sr *StoreRebalancer) rebalanceStore(
-	ctx context.Context, mode LBRebalancingMode, allStoresList storepool.StoreList,
+	ctx context.Context,
+	mode LBRebalancingMode,
+	dim allocator.LoadDimension,
+	allStoresList storepool.StoreList,
 ) {
-	options := sr.scorerOptions(ctx)
+	options := sr.scorerOptions(ctx, dim)
 	var localDesc *roachpb.StoreDescriptor
 	for i := range allStoresList.Stores {
 		if allStoresList.Stores[i].StoreID == sr.rq.store.StoreID() {
@@ -218,31 +293,36 @@ func (sr *StoreRebalancer) rebalanceStore(
 		return
 	}
 
+	// localLoad returns the load on the local store in the dimension being
+	// balanced. It reflects the updates made to localDesc below.
+	localLoad := func() float64 { return dim.StoreLoad(localDesc.Capacity) }
+	meanLoad := allStoresList.CandidateLoad(dim).Mean
+
 	// We only bother rebalancing stores that are fielding more than the
-	// cluster-level overfull threshold of QPS.
-	qpsMaxThreshold := allocatorimpl.OverfullQPSThreshold(options, allStoresList.CandidateQueriesPerSecond.Mean)
-	if !(localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold) {
-		log.Infof(ctx, "local QPS %.2f is below max threshold %.2f (mean=%.2f); no rebalancing needed",
-			localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold, allStoresList.CandidateQueriesPerSecond.Mean)
+	// cluster-level overfull threshold of load.
+	qpsMaxThreshold := allocatorimpl.OverfullQPSThreshold(options, meanLoad)
+	if !(localLoad() > qpsMaxThreshold) {
+		log.Infof(ctx, "local %s %.2f is below max threshold %.2f (mean=%.2f); no rebalancing needed",
+			dim, localLoad(), qpsMaxThreshold, meanLoad)
 		return
 	}
 
 	var replicasToMaybeRebalance []replicaWithStats
 	storeMap := allStoresList.ToMap()
 
-	// First check if we should transfer leases away to better balance QPS.
+	// First check if we should transfer leases away to better balance load.
 	log.Infof(ctx,
-		"considering load-based lease transfers for s%d with %.2f qps (mean=%.2f, upperThreshold=%.2f)",
-		localDesc.StoreID, localDesc.Capacity.QueriesPerSecond, allStoresList.CandidateQueriesPerSecond.Mean, qpsMaxThreshold)
-	hottestRanges := sr.replRankings.topQPS()
-	for localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold {
+		"considering load-based lease transfers for s%d with %.2f %s (mean=%.2f, upperThreshold=%.2f)",
+		localDesc.StoreID, localLoad(), dim, meanLoad, qpsMaxThreshold)
+	hottestRanges := sr.replRankings.topLoad(dim)
+	for localLoad() > qpsMaxThreshold {
 		replWithStats, target, considerForRebalance := sr.chooseLeaseToTransfer(
 			ctx,
 			&hottestRanges,
 			localDesc,
 			allStoresList,
 			storeMap,
-			sr.scorerOptions(ctx),
+			sr.scorerOptions(ctx, dim),
 		)
 		replicasToMaybeRebalance = append(replicasToMaybeRebalance, considerForRebalance...)
 		if replWithStats.repl == nil {
@@ -251,7 +331,10 @@ func (sr *StoreRebalancer) rebalanceStore(
 
 		timeout := sr.rq.processTimeoutFunc(sr.st, replWithStats.repl)
 		if err := contextutil.RunWithTimeout(ctx, "transfer lease", timeout, func(ctx context.Context) error {
-			return sr.rq.transferLease(ctx, replWithStats.repl, target, replWithStats.qps)
+			return sr.rq.transferLease(ctx, replWithStats.repl, target, allocator.RangeUsageInfo{
+				QueriesPerSecond:         replWithStats.qps,
+				RequestCPUNanosPerSecond: replWithStats.cpu,
+			})
 		}); err != nil {
 			log.Errorf(ctx, "unable to transfer lease to s%d: %+v", target.StoreID, err)
 			continue
@@ -263,45 +346,47 @@ func (sr *StoreRebalancer) rebalanceStore(
 		// up-to-date info. The StorePool copies are updated by transferLease.
 		localDesc.Capacity.LeaseCount--
 		localDesc.Capacity.QueriesPerSecond -= replWithStats.qps
+		localDesc.Capacity.CPUPerSecond -= replWithStats.cpu
 		if otherDesc := storeMap[target.StoreID]; otherDesc != nil {
 			otherDesc.Capacity.LeaseCount++
 			otherDesc.Capacity.QueriesPerSecond += replWithStats.qps
+			otherDesc.Capacity.CPUPerSecond += replWithStats.cpu
 		}
 	}
 
-	if !(localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold) {
+	if !(localLoad() > qpsMaxThreshold) {
 		log.Infof(ctx,
-			"load-based lease transfers successfully brought s%d down to %.2f qps (mean=%.2f, upperThreshold=%.2f)",
-			localDesc.StoreID, localDesc.Capacity.QueriesPerSecond, allStoresList.CandidateQueriesPerSecond.Mean, qpsMaxThreshold)
+			"load-based lease transfers successfully brought s%d down to %.2f %s (mean=%.2f, upperThreshold=%.2f)",
+			localDesc.StoreID, localLoad(), dim, meanLoad, qpsMaxThreshold)
 		return
 	}
 
 	if mode != LBRebalancingLeasesAndReplicas {
 		log.Infof(ctx,
-			"ran out of leases worth transferring and qps (%.2f) is still above desired threshold (%.2f)",
-			localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold)
+			"ran out of leases worth transferring and %s (%.2f) is still above desired threshold (%.2f)",
+			dim, localLoad(), qpsMaxThreshold)
 		return
 	}
 	log.Infof(ctx,
-		"ran out of leases worth transferring and qps (%.2f) is still above desired threshold (%.2f); considering load-based replica rebalances",
-		localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold)
+		"ran out of leases worth transferring and %s (%.2f) is still above desired threshold (%.2f); considering load-based replica rebalances",
+		dim, localLoad(), qpsMaxThreshold)
 
 	// Re-combine replicasToMaybeRebalance with what remains of hottestRanges so
 	// that we'll reconsider them for replica rebalancing.
 	replicasToMaybeRebalance = append(replicasToMaybeRebalance, hottestRanges...)
 
-	for localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold {
+	for localLoad() > qpsMaxThreshold {
 		replWithStats, voterTargets, nonVoterTargets := sr.chooseRangeToRebalance(
 			ctx,
 			&replicasToMaybeRebalance,
 			localDesc,
 			allStoresList,
-			sr.scorerOptions(ctx),
+			sr.scorerOptions(ctx, dim),
 		)
 		if replWithStats.repl == nil {
 			log.Infof(ctx,
-				"ran out of replicas worth transferring and qps (%.2f) is still above desired threshold (%.2f); will check again soon",
-				localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold)
+				"ran out of replicas worth transferring and %s (%.2f) is still above desired threshold (%.2f); will check again soon",
+				dim, localLoad(), qpsMaxThreshold)
 			return
 		}
 
@@ -309,9 +394,10 @@ func (sr *StoreRebalancer) rebalanceStore(
 		log.VEventf(
 			ctx,
 			1,
-			"rebalancing r%d (%.2f qps) to better balance load: voters from %v to %v; non-voters from %v to %v",
+			"rebalancing r%d (%.2f %s) to better balance load: voters from %v to %v; non-voters from %v to %v",
 			replWithStats.repl.RangeID,
-			replWithStats.qps,
+			replWithStats.load(dim),
+			dim,
 			descBeforeRebalance.Replicas().Voters(),
 			voterTargets,
 			descBeforeRebalance.Replicas().NonVoters(),
@@ -348,20 +434,22 @@ func (sr *StoreRebalancer) rebalanceStore(
 		}
 		localDesc.Capacity.LeaseCount--
 		localDesc.Capacity.QueriesPerSecond -= replWithStats.qps
+		localDesc.Capacity.CPUPerSecond -= replWithStats.cpu
 		for i := range voterTargets {
 			if storeDesc := storeMap[voterTargets[i].StoreID]; storeDesc != nil {
 				storeDesc.Capacity.RangeCount++
 				if i == 0 {
 					storeDesc.Capacity.LeaseCount++
 					storeDesc.Capacity.QueriesPerSecond += replWithStats.qps
+					storeDesc.Capacity.CPUPerSecond += replWithStats.cpu
 				}
 			}
 		}
 	}
 
 	log.Infof(ctx,
-		"load-based replica transfers successfully brought s%d down to %.2f qps (mean=%.2f, upperThreshold=%.2f)",
-		localDesc.StoreID, localDesc.Capacity.QueriesPerSecond, allStoresList.CandidateQueriesPerSecond.Mean, qpsMaxThreshold)
+		"load-based replica transfers successfully brought s%d down to %.2f %s (mean=%.2f, upperThreshold=%.2f)",
+		localDesc.StoreID, localLoad(), dim, meanLoad, qpsMaxThreshold)
 }
 
 func (sr *StoreRebalancer) chooseLeaseToTransfer(
@@ -390,6 +478,11 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 		)
 	}
 
+	// NB: options may be nil in tests, in which case QPS is balanced.
+	dim := allocator.Queries
+	if options != nil {
+		dim = options.LoadDimension
+	}
 	var considerForRebalance []replicaWithStats
 	now := sr.rq.store.Clock().NowAsClockTimestamp()
 	for {
@@ -409,19 +502,20 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 			continue
 		}
 
-		// Don't bother moving leases whose QPS is below some small fraction of the
-		// store's QPS. It's just unnecessary churn with no benefit to move leases
-		// responsible for, for example, 1 qps on a store with 5000 qps.
+		// Don't bother moving leases whose load is below some small fraction of
+		// the store's load. It's just unnecessary churn with no benefit to move
+		// leases responsible for, for example, 1 qps on a store with 5000 qps.
 		const minQPSFraction = .001
-		if replWithStats.qps < localDesc.Capacity.QueriesPerSecond*minQPSFraction {
-			log.VEventf(ctx, 3, "r%d's %.2f qps is too little to matter relative to s%d's %.2f total qps",
-				replWithStats.repl.RangeID, replWithStats.qps, localDesc.StoreID, localDesc.Capacity.QueriesPerSecond)
+		if replWithStats.load(dim) < dim.StoreLoad(localDesc.Capacity)*minQPSFraction {
+			log.VEventf(ctx, 3, "r%d's %.2f %s is too little to matter relative to s%d's %.2f total %s",
+				replWithStats.repl.RangeID, replWithStats.load(dim), dim, localDesc.StoreID,
+				dim.StoreLoad(localDesc.Capacity), dim)
 			continue
 		}
 
 		desc, conf := replWithStats.repl.DescAndSpanConfig()
-		log.VEventf(ctx, 3, "considering lease transfer for r%d with %.2f qps",
-			desc.RangeID, replWithStats.qps)
+		log.VEventf(ctx, 3, "considering lease transfer for r%d with %.2f %s",
+			desc.RangeID, replWithStats.load(dim), dim)
 
 		// Check all the other voting replicas in order of increasing qps.
 		// Learners or non-voters aren't allowed to become leaseholders or raft
@@ -434,16 +528,23 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 		// waiting for a snapshot).
 		candidates = allocatorimpl.FilterBehindReplicas(ctx, sr.getRaftStatusFn(replWithStats.repl), candidates)
 
+		// The replica stats passed to TransferLeaseTarget must be in the
+		// dimension being balanced.
+		loadStats := replWithStats.repl.leaseholderStats
+		if dim == allocator.CPU {
+			loadStats = replWithStats.repl.loadStats.requestCPUNanos
+		}
 		candidate := sr.rq.allocator.TransferLeaseTarget(
 			ctx,
 			conf,
 			candidates,
 			replWithStats.repl,
-			replWithStats.repl.leaseholderStats,
+			loadStats,
 			true, /* forceDecisionWithoutStats */
 			allocator.TransferLeaseOptions{
 				Goal:             allocator.QPSConvergence,
 				ExcludeLeaseRepl: false,
+				LoadDimension:    dim,
 			},
 		)
 
@@ -479,13 +580,16 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 			log.VEventf(
 				ctx,
 				1,
-				"transferring lease for r%d (qps=%.2f) to store s%d (qps=%.2f) from local store s%d (qps=%.2f)",
+				"transferring lease for r%d (%s=%.2f) to store s%d (%s=%.2f) from local store s%d (%s=%.2f)",
 				desc.RangeID,
-				replWithStats.qps,
+				dim,
+				replWithStats.load(dim),
 				targetStore.StoreID,
-				targetStore.Capacity.QueriesPerSecond,
+				dim,
+				dim.StoreLoad(targetStore.Capacity),
 				localDesc.StoreID,
-				localDesc.Capacity.QueriesPerSecond,
+				dim,
+				dim.StoreLoad(localDesc.Capacity),
 			)
 		}
 		return replWithStats, candidate, considerForRebalance
@@ -529,6 +633,7 @@ func (sr *StoreRebalancer) chooseRangeToRebalance(
 		)
 	}
 
+	dim := options.LoadDimension
 	now := sr.rq.store.Clock().NowAsClockTimestamp()
 	for {
 		if len(*hottestRanges) == 0 {
@@ -541,19 +646,21 @@ func (sr *StoreRebalancer) chooseRangeToRebalance(
 			return replicaWithStats{}, nil, nil
 		}
 
-		// Don't bother moving ranges whose QPS is below some small fraction of the
-		// store's QPS. It's just unnecessary churn with no benefit to move ranges
-		// responsible for, for example, 1 qps on a store with 5000 qps.
+		// Don't bother moving ranges whose load is below some small fraction of
+		// the store's load. It's just unnecessary churn with no benefit to move
+		// ranges responsible for, for example, 1 qps on a store with 5000 qps.
 		const minQPSFraction = .001
-		if replWithStats.qps < localDesc.Capacity.QueriesPerSecond*minQPSFraction {
+		if replWithStats.load(dim) < dim.StoreLoad(localDesc.Capacity)*minQPSFraction {
 			log.VEventf(
 				ctx,
 				5,
-				"r%d's %.2f qps is too little to matter relative to s%d's %.2f total qps",
+				"r%d's %.2f %s is too little to matter relative to s%d's %.2f total %s",
 				replWithStats.repl.RangeID,
-				replWithStats.qps,
+				replWithStats.load(dim),
+				dim,
 				localDesc.StoreID,
-				localDesc.Capacity.QueriesPerSecond,
+				dim.StoreLoad(localDesc.Capacity),
+				dim,
 			)
 			continue
 		}
@@ -603,7 +710,7 @@ func (sr *StoreRebalancer) chooseRangeToRebalance(
 		// Thus, we ideally want to base our replica rebalancing on the assumption
 		// that all of the load from the leaseholder's replica is going to shift to
 		// the new store that we end up rebalancing to.
-		options.QPSPerReplica = replWithStats.qps
+		options.QPSPerReplica = replWithStats.load(dim)
 
 		if !replWithStats.repl.OwnsValidLease(ctx, now) {
 			log.VEventf(ctx, 3, "store doesn't own the lease for r%d", replWithStats.repl.RangeID)
@@ -613,9 +720,10 @@ func (sr *StoreRebalancer) chooseRangeToRebalance(
 		log.VEventf(
 			ctx,
 			3,
-			"considering replica rebalance for r%d with %.2f qps",
+			"considering replica rebalance for r%d with %.2f %s",
 			replWithStats.repl.GetRangeID(),
-			replWithStats.qps,
+			replWithStats.load(dim),
+			dim,
 		)
 
 		targetVoterRepls, targetNonVoterRepls, foundRebalance := sr.getRebalanceTargetsBasedOnQPS(
@@ -634,7 +742,7 @@ func (sr *StoreRebalancer) chooseRangeToRebalance(
 
 		storeDescMap := allStoresList.ToMap()
 
-		// Pick the voter with the least QPS to be leaseholder;
+		// Pick the voter with the least load to be leaseholder;
 		// RelocateRange transfers the lease to the first provided target.
 		//
 		// TODO(aayush): Does this logic need to exist? This logic does not take
@@ -655,9 +763,9 @@ func (sr *StoreRebalancer) chooseRangeToRebalance(
 			}
 
 			storeDesc, ok := storeDescMap[targetVoterRepls[i].StoreID]
-			if ok && storeDesc.Capacity.QueriesPerSecond < newLeaseQPS {
+			if ok && dim.StoreLoad(storeDesc.Capacity) < newLeaseQPS {
 				newLeaseIdx = i
-				newLeaseQPS = storeDesc.Capacity.QueriesPerSecond
+				newLeaseQPS = dim.StoreLoad(storeDesc.Capacity)
 			}
 		}
 		targetVoterRepls[0], targetVoterRepls[newLeaseIdx] = targetVoterRepls[newLeaseIdx], targetVoterRepls[0]
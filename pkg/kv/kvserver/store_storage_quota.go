@@ -0,0 +1,83 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/storagequota"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// storageQuotaUsageTTLMultiple is the multiple of the report interval after
+// which the usage gossiped by a store expires, so that the usage of a store
+// which stopped reporting is eventually forgotten.
+const storageQuotaUsageTTLMultiple = 3
+
+// startStorageQuotaReporter periodically gossips the logical bytes stored by
+// the tenants and tables with a storage quota in the ranges this store holds
+// the lease for, and refreshes the storage quota tracker with the usage
+// gossiped by all stores.
+func (s *Store) startStorageQuotaReporter(ctx context.Context) {
+	_ /* err */ = s.stopper.RunAsyncTaskEx(ctx,
+		stop.TaskOpts{
+			TaskName: "storage-quota-reporter",
+			SpanOpt:  stop.SterileRootSpan,
+		}, func(ctx context.Context) {
+			timer := timeutil.NewTimer()
+			defer timer.Stop()
+			for {
+				timer.Reset(storagequota.ReportInterval.Get(&s.cfg.Settings.SV))
+				select {
+				case <-timer.C:
+					timer.Read = true
+					if err := s.reportStorageQuotaUsage(ctx); err != nil {
+						log.Warningf(ctx, "failed to report storage quota usage: %v", err)
+					}
+				case <-s.stopper.ShouldQuiesce():
+					return
+				}
+			}
+		})
+}
+
+func (s *Store) reportStorageQuotaUsage(ctx context.Context) error {
+	now := s.cfg.Clock.NowAsClockTimestamp()
+	b := storagequota.NewUsageBuilder()
+	newStoreReplicaVisitor(s).Visit(func(r *Replica) bool {
+		if r.OwnsValidLease(ctx, now) {
+			b.Add(r.Desc().StartKey, r.GetMVCCStats().Total(), r.SpanConfig().StorageQuotaBytes)
+		}
+		return true // more
+	})
+	report := b.Build(s.StoreID(), s.storageQuota.TenantQuota)
+	ttl := storageQuotaUsageTTLMultiple * storagequota.ReportInterval.Get(&s.cfg.Settings.SV)
+	if err := s.cfg.Gossip.AddInfoProto(
+		gossip.MakeStorageQuotaUsageKey(s.StoreID()), &report, ttl,
+	); err != nil {
+		return err
+	}
+	reports, err := storagequota.UsageFromGossip(s.cfg.Gossip)
+	if err != nil {
+		return err
+	}
+	s.storageQuota.Update(reports)
+	return nil
+}
+
+// CheckStorageQuota returns an error if writes to the replica are to be
+// rejected because its tenant or table has reached its storage quota.
+func (r *Replica) CheckStorageQuota() error {
+	return r.store.storageQuota.Check(r.Desc().StartKey, r.SpanConfig().StorageQuotaBytes)
+}
@@ -108,6 +108,8 @@ func createPushTxnResponse(txn *roachpb.Transaction) *roachpb.PushTxnResponse {
 // dependency cycles.
 type waitingPush struct {
 	req *roachpb.PushTxnRequest
+	// start is the time at which the push started waiting in the queue.
+	start time.Time
 	// pending channel receives updated, pushed txn or nil if queue is cleared.
 	pending chan *roachpb.Transaction
 	mu      struct {
@@ -390,6 +392,48 @@ func (q *Queue) GetDependents(txnID uuid.UUID) []uuid.UUID {
 	return nil
 }
 
+// PushWaiter describes a PushTxn request waiting in the queue for a
+// transaction to be finalized or pushed.
+type PushWaiter struct {
+	// Pusher is the transaction performing the push, or nil if the push is
+	// performed by a non-transactional request.
+	Pusher *enginepb.TxnMeta
+	// Pushee is the transaction being pushed.
+	Pushee enginepb.TxnMeta
+	// WaitDuration is the time the push has spent waiting in the queue.
+	WaitDuration time.Duration
+}
+
+// QueryPushWaiters returns the PushTxn requests currently waiting in the
+// queue. It is used to build the cluster-wide waits-for graph for
+// observability purposes.
+func (q *Queue) QueryPushWaiters() []PushWaiter {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.mu.txns == nil {
+		// Not enabled; do nothing.
+		return nil
+	}
+	now := q.cfg.Clock.PhysicalTime()
+	var waiters []PushWaiter
+	for _, pending := range q.mu.txns {
+		pushee := pending.getTxn().TxnMeta
+		for e := pending.waitingPushes.Front(); e != nil; e = e.Next() {
+			push := e.Value.(*waitingPush)
+			w := PushWaiter{
+				Pushee:       pushee,
+				WaitDuration: now.Sub(push.start),
+			}
+			if push.req.PusherTxn.ID != (uuid.UUID{}) {
+				pusher := push.req.PusherTxn.TxnMeta
+				w.Pusher = &pusher
+			}
+			waiters = append(waiters, w)
+		}
+	}
+	return waiters
+}
+
 // isTxnUpdated returns whether the transaction specified in
 // the QueryTxnRequest has had its status or priority updated
 // or whether the known set of dependent transactions has
@@ -461,6 +505,7 @@ func (q *Queue) MaybeWaitForPush(
 
 	push := &waitingPush{
 		req:     req,
+		start:   q.cfg.Clock.PhysicalTime(),
 		pending: make(chan *roachpb.Transaction, 1),
 	}
 	pushElem := pending.waitingPushes.PushBack(push)
@@ -325,3 +325,73 @@ func TestPushersReleasedAfterAnyQueryTxnFindsAbortedTxn(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// TestQueryPushWaiters verifies that the pushes waiting in the queue are
+// reported by QueryPushWaiters along with the time they have spent waiting.
+func TestQueryPushWaiters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	var mockSender kv.SenderFunc
+	cfg := makeConfig(func(
+		ctx context.Context, ba roachpb.BatchRequest,
+	) (*roachpb.BatchResponse, *roachpb.Error) {
+		return mockSender(ctx, ba)
+	}, stopper)
+	manual := timeutil.NewManualTime(timeutil.Unix(0, 123))
+	cfg.Clock = hlc.NewClock(manual, time.Nanosecond /* maxOffset */)
+	blocked := make(chan struct{}, 1)
+	cfg.Knobs.OnPusherBlocked = func(context.Context, *roachpb.PushTxnRequest) {
+		blocked <- struct{}{}
+	}
+	q := NewQueue(cfg)
+	q.Enable(1 /* leaseSeq */)
+
+	// Set an extremely high transaction liveness threshold so that the pushee
+	// is not considered expired while the push is waiting.
+	defer TestingOverrideTxnLivenessThreshold(time.Hour)()
+
+	// Enqueue pushee transaction in the queue.
+	pushee := roachpb.MakeTransaction("pushee", nil, 0, cfg.Clock.Now(), 0, 0)
+	pusher := roachpb.MakeTransaction("pusher", nil, 0, cfg.Clock.Now(), 0, 0)
+	q.EnqueueTxn(&pushee)
+	require.Empty(t, q.QueryPushWaiters())
+
+	// Mock out responses to any QueryTxn requests.
+	mockSender = func(
+		ctx context.Context, ba roachpb.BatchRequest,
+	) (*roachpb.BatchResponse, *roachpb.Error) {
+		br := ba.CreateReply()
+		resp := br.Responses[0].GetInner().(*roachpb.QueryTxnResponse)
+		resp.QueriedTxn = pushee
+		return br, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waitingRes := make(chan *roachpb.Error)
+	go func() {
+		req := roachpb.PushTxnRequest{
+			PusherTxn: pusher, PusheeTxn: pushee.TxnMeta, PushType: roachpb.PUSH_ABORT,
+		}
+		_, err := q.MaybeWaitForPush(ctx, &req)
+		waitingRes <- err
+	}()
+	<-blocked
+
+	manual.Advance(5 * time.Second)
+	waiters := q.QueryPushWaiters()
+	require.Len(t, waiters, 1)
+	require.NotNil(t, waiters[0].Pusher)
+	require.Equal(t, pusher.ID, waiters[0].Pusher.ID)
+	require.Equal(t, pushee.ID, waiters[0].Pushee.ID)
+	require.Equal(t, 5*time.Second, waiters[0].WaitDuration)
+
+	cancel()
+	require.NotNil(t, <-waitingRes)
+	require.Empty(t, q.QueryPushWaiters())
+
+	// A disabled queue has no waiters.
+	q.Clear(true /* disable */)
+	require.Nil(t, q.QueryPushWaiters())
+}
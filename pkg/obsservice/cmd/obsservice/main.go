@@ -11,12 +11,23 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/cli/exit"
 	"github.com/cockroachdb/cockroach/pkg/obsservice/obslib"
+	"github.com/cockroachdb/cockroach/pkg/obsservice/obslib/events"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/spf13/cobra"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc"
 )
 
+// retentionInterval is the interval at which expired events are deleted.
+const retentionInterval = time.Hour
+
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:   "obsservice",
@@ -33,17 +44,72 @@ from one or more CockroachDB clusters.`,
 			UICertKeyPath: uiCertKeyPath,
 		}
 
+		// Connect to the database storing the events.
+		pool, err := pgxpool.Connect(ctx, sinkPGURL)
+		if err != nil {
+			log.Fatalf(ctx, "failed to connect to %s: %s", redactedPGURL(sinkPGURL), err)
+		}
+		store := events.NewStore(pool)
+		if err := store.EnsureSchema(ctx); err != nil {
+			log.Fatalf(ctx, "%s", err)
+		}
+		go store.RunRetention(ctx, eventRetention, retentionInterval)
+
+		// Serve the events ingestion endpoint.
+		listener, err := net.Listen("tcp", otlpAddr)
+		if err != nil {
+			log.Fatalf(ctx, "%s", err)
+		}
+		grpcOpts, err := obslib.GRPCServerOptions(cfg)
+		if err != nil {
+			log.Fatalf(ctx, "%s", err)
+		}
+		if len(grpcOpts) == 0 {
+			log.Warningf(ctx, "--ui-cert is not specified; OTLP log exports are accepted "+
+				"without TLS and from unauthenticated clients, and the events query API "+
+				"refuses all requests")
+		}
+		grpcServer := grpc.NewServer(grpcOpts...)
+		collogspb.RegisterLogsServiceServer(grpcServer, events.NewIngester(store))
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatalf(ctx, "%s", err)
+			}
+		}()
+		fmt.Printf("Listening for OTLP log exports on %s.\n", otlpAddr)
+
+		proxy := obslib.NewReverseHTTPProxy(ctx, cfg)
+		proxy.HandleWithClientCert(events.QueryPath, events.NewQueryHandler(store))
+
 		// Block forever running the proxy.
-		<-obslib.NewReverseHTTPProxy(ctx, cfg).RunAsync(ctx)
+		<-proxy.RunAsync(ctx)
 	},
 }
 
+// redactedPGURL returns the given PGURL with its password, if any, redacted
+// so that it can be logged. Connection strings which are not URLs are redacted
+// entirely.
+func redactedPGURL(pgURL string) string {
+	u, err := url.Parse(pgURL)
+	if err != nil || u.Scheme == "" {
+		return "<redacted>"
+	}
+	if q := u.Query(); q.Get("password") != "" {
+		q.Set("password", "xxxxx")
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
+}
+
 // Flags.
 var (
 	httpAddr                  string
 	targetURL                 string
 	caCertPath                string
 	uiCertPath, uiCertKeyPath string
+	otlpAddr                  string
+	sinkPGURL                 string
+	eventRetention            time.Duration
 )
 
 func main() {
@@ -62,19 +128,41 @@ func main() {
 		"ca-cert",
 		"",
 		"Path to the certificate authority certificate file. If specified,"+
-			" HTTP requests are only proxied to CRDB nodes that present certificates signed by this CA."+
+			" HTTP requests are only proxied to CRDB nodes that present certificates signed by this CA,"+
+			" and OTLP log exports and events queries are only accepted from clients that present"+
+			" certificates signed by this CA."+
 			" If not specified, the system's CA list is used.")
 	RootCmd.PersistentFlags().StringVar(
 		&uiCertPath,
 		"ui-cert",
 		"",
-		"Path to the certificate used used by the Observability Service.")
+		"Path to the certificate used used by the Observability Service. If specified, both HTTP"+
+			" requests and OTLP log exports are served over TLS, and OTLP clients and clients of"+
+			" the events query API need to present a certificate; see --ca-cert. If not"+
+			" specified, the events query API is not served.")
 	RootCmd.PersistentFlags().StringVar(
 		&uiCertKeyPath,
 		"ui-cert-key",
 		"",
 		"Path to the private key used by the Observability Service. "+
 			"This is the key corresponding to the --ui-cert certificate.")
+	RootCmd.PersistentFlags().StringVar(
+		&otlpAddr,
+		"otlp-addr",
+		"localhost:4317",
+		"The address on which to listen for the structured events exported by CRDB nodes"+
+			" through OTLP log sinks.")
+	RootCmd.PersistentFlags().StringVar(
+		&sinkPGURL,
+		"sink-pgurl",
+		"postgresql://root@localhost:26257?sslmode=disable",
+		"The PGURL of the CockroachDB cluster in which the Observability Service stores"+
+			" the events it ingests.")
+	RootCmd.PersistentFlags().DurationVar(
+		&eventRetention,
+		"event-retention",
+		7*24*time.Hour,
+		"The period for which ingested events are retained.")
 
 	if err := RootCmd.Execute(); err != nil {
 		fmt.Println(err)
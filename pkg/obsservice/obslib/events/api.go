@@ -0,0 +1,117 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// QueryPath is the HTTP path on which the events query API is served.
+const QueryPath = "/obs/events"
+
+const (
+	// defaultQueryLimit is the maximum number of events returned by a query
+	// which doesn't specify a limit.
+	defaultQueryLimit = 1000
+	// maxQueryLimit is the largest limit a query can specify.
+	maxQueryLimit = 10000
+)
+
+// queryResponse is the body of a successful response of the query API.
+type queryResponse struct {
+	Events []Event `json:"events"`
+}
+
+// NewQueryHandler returns an HTTP handler serving the events query API. The
+// handler accepts GET requests with the following (optional) URL parameters:
+//
+//   - event_type: the type of the events to return. Can be repeated.
+//   - start, end: the time range of the events to return, as RFC 3339
+//     timestamps. The start is inclusive and the end exclusive.
+//   - node_id: the node which emitted the events.
+//   - tenant_id: the tenant which emitted the events.
+//   - limit: the maximum number of events to return.
+//
+// The matching events are returned as JSON, most recent first.
+func NewQueryHandler(store *Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET requests are supported", http.StatusMethodNotAllowed)
+			return
+		}
+		f, err := parseFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		events, err := store.Query(r.Context(), f)
+		if err != nil {
+			log.Warningf(r.Context(), "events query failed: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if events == nil {
+			events = []Event{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(queryResponse{Events: events}); err != nil {
+			log.Warningf(r.Context(), "failed to write events query response: %v", err)
+		}
+	})
+}
+
+// parseFilter extracts the query filter from the request's URL parameters.
+func parseFilter(r *http.Request) (Filter, error) {
+	q := r.URL.Query()
+	f := Filter{
+		EventTypes: q["event_type"],
+		Limit:      defaultQueryLimit,
+	}
+	var err error
+	if f.Start, err = parseTimeParam(q.Get("start")); err != nil {
+		return Filter{}, errors.Wrap(err, "invalid start")
+	}
+	if f.End, err = parseTimeParam(q.Get("end")); err != nil {
+		return Filter{}, errors.Wrap(err, "invalid end")
+	}
+	if f.NodeID, err = parseIntParam(q.Get("node_id")); err != nil {
+		return Filter{}, errors.Wrap(err, "invalid node_id")
+	}
+	if f.TenantID, err = parseIntParam(q.Get("tenant_id")); err != nil {
+		return Filter{}, errors.Wrap(err, "invalid tenant_id")
+	}
+	if s := q.Get("limit"); s != "" {
+		limit, err := parseIntParam(s)
+		if err != nil || limit <= 0 || limit > maxQueryLimit {
+			return Filter{}, errors.Newf("invalid limit: must be between 1 and %d", maxQueryLimit)
+		}
+		f.Limit = int(limit)
+	}
+	return f, nil
+}
+
+func parseTimeParam(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+func parseIntParam(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
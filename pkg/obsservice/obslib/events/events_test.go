@@ -0,0 +1,157 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/obsservice/obslib/events"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+// eventRecord creates an OTLP log record carrying a structured event, as
+// produced by the OTLP log sink.
+func eventRecord(ts time.Time, eventType string, nodeID, tenantID int64) *logspb.LogRecord {
+	body := fmt.Sprintf(`{"Timestamp":%d,"EventType":%q,"DatabaseName":"db%d"}`,
+		ts.UnixNano(), eventType, nodeID)
+	return &logspb.LogRecord{
+		TimeUnixNano: uint64(ts.UnixNano()),
+		Name:         eventType,
+		Body:         &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: body}},
+		Attributes: []*commonpb.KeyValue{
+			{Key: "channel", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "SQL_SCHEMA"}}},
+			{Key: "node_id", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: nodeID}}},
+			{Key: "tenant_id", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: tenantID}}},
+			{Key: "cluster_id", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "c1"}}},
+		},
+	}
+}
+
+func TestEventIngestionAndQuery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	pgURL, cleanup := sqlutils.PGUrl(
+		t, s.ServingSQLAddr(), "TestEventIngestionAndQuery", url.User(username.RootUser),
+	)
+	defer cleanup()
+	pool, err := pgxpool.Connect(ctx, pgURL.String())
+	require.NoError(t, err)
+	defer pool.Close()
+
+	store := events.NewStore(pool)
+	require.NoError(t, store.EnsureSchema(ctx))
+	// Creating the schema is idempotent.
+	require.NoError(t, store.EnsureSchema(ctx))
+
+	t0 := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	ingester := events.NewIngester(store)
+	_, err = ingester.Export(ctx, &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			InstrumentationLibraryLogs: []*logspb.InstrumentationLibraryLogs{{
+				Logs: []*logspb.LogRecord{
+					eventRecord(t0, "create_database", 1, 1),
+					eventRecord(t0.Add(time.Minute), "drop_table", 2, 1),
+					eventRecord(t0.Add(2*time.Minute), "create_database", 2, 10),
+					// Unstructured log entries are not stored.
+					{
+						TimeUnixNano: uint64(t0.UnixNano()),
+						Body:         &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "hello"}},
+					},
+				},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(events.NewQueryHandler(store))
+	defer srv.Close()
+
+	// query returns a description of the events returned by the query API for
+	// the given URL parameters.
+	query := func(params string) []string {
+		resp, err := http.Get(srv.URL + events.QueryPath + "?" + params)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var res struct {
+			Events []events.Event `json:"events"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		descs := []string{}
+		for _, ev := range res.Events {
+			var payload struct{ DatabaseName string }
+			require.NoError(t, json.Unmarshal(ev.Payload, &payload))
+			require.Equal(t, "c1", ev.ClusterID)
+			descs = append(descs, fmt.Sprintf("%s n%d t%d %s %s",
+				ev.Timestamp.UTC().Format("15:04"), ev.NodeID, ev.TenantID, ev.EventType, payload.DatabaseName))
+		}
+		return descs
+	}
+
+	all := []string{
+		"00:02 n2 t10 create_database db2",
+		"00:01 n2 t1 drop_table db2",
+		"00:00 n1 t1 create_database db1",
+	}
+	require.Equal(t, all, query(""))
+	require.Equal(t, []string{all[0], all[2]}, query("event_type=create_database"))
+	require.Equal(t, all[:2], query("event_type=create_database&event_type=drop_table&node_id=2"))
+	require.Equal(t, all[1:], query("tenant_id=1"))
+	require.Equal(t, all[1:2], query("start=2022-06-01T00:00:30Z&end=2022-06-01T00:02:00Z"))
+	require.Equal(t, all[:1], query("limit=1"))
+	require.Equal(t, []string{}, query("node_id=3"))
+
+	for _, params := range []string{"node_id=x", "start=yesterday", "limit=0"} {
+		resp, err := http.Get(srv.URL + events.QueryPath + "?" + params)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, params)
+	}
+
+	// Enforcing the retention period deletes the older events.
+	deleted, err := store.DeleteOlderThan(ctx, t0.Add(90*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), deleted)
+	require.Equal(t, all[:1], query(""))
+
+	// Events are inserted atomically, even when they span several statements.
+	var evs []events.Event
+	for i := 0; i < 250; i++ {
+		evs = append(evs, events.Event{
+			Timestamp: t0.Add(time.Hour),
+			EventType: "create_table",
+			Payload:   json.RawMessage(`{}`),
+		})
+	}
+	evs[len(evs)-1].Payload = json.RawMessage(`not json`)
+	require.Error(t, store.Insert(ctx, evs))
+	require.Equal(t, all[:1], query(""))
+}
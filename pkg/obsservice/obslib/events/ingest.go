@@ -0,0 +1,92 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Ingester is the gRPC endpoint to which CockroachDB nodes push their
+// structured events. It implements the OTLP logs service, so nodes export
+// events through an OTLP log sink.
+//
+// Only structured events are stored; other log entries are ignored. An OTLP
+// log record carries a structured event when its name is set to the event
+// type and its body is the JSON encoding of the event, as produced by the
+// OTLP log sink.
+type Ingester struct {
+	collogspb.UnimplementedLogsServiceServer
+	store *Store
+}
+
+var _ collogspb.LogsServiceServer = (*Ingester)(nil)
+
+// NewIngester creates an Ingester which persists events to the given store.
+func NewIngester(store *Store) *Ingester {
+	return &Ingester{store: store}
+}
+
+// Export implements the collogspb.LogsServiceServer interface.
+func (i *Ingester) Export(
+	ctx context.Context, req *collogspb.ExportLogsServiceRequest,
+) (*collogspb.ExportLogsServiceResponse, error) {
+	var events []Event
+	for _, rl := range req.ResourceLogs {
+		for _, ill := range rl.InstrumentationLibraryLogs {
+			for _, r := range ill.Logs {
+				if ev, ok := eventFromLogRecord(r); ok {
+					events = append(events, ev)
+				}
+			}
+		}
+	}
+	if err := i.store.Insert(ctx, events); err != nil {
+		log.Warningf(ctx, "failed to store %d events: %v", len(events), err)
+		// The error is retriable from the client's perspective, although the
+		// OTLP sinks of CRDB nodes don't retry exports and drop the events.
+		return nil, status.Errorf(codes.Unavailable, "storing events: %v", err)
+	}
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// eventFromLogRecord extracts the structured event carried by a log record.
+// It returns false if the record does not carry an event.
+func eventFromLogRecord(r *logspb.LogRecord) (Event, bool) {
+	if r.Name == "" {
+		return Event{}, false
+	}
+	body := r.Body.GetStringValue()
+	if !json.Valid([]byte(body)) {
+		return Event{}, false
+	}
+	ev := Event{
+		Timestamp: time.Unix(0, int64(r.TimeUnixNano)).UTC(),
+		EventType: r.Name,
+		Payload:   json.RawMessage(body),
+	}
+	for _, attr := range r.Attributes {
+		switch attr.Key {
+		case "cluster_id":
+			ev.ClusterID = attr.Value.GetStringValue()
+		case "node_id":
+			ev.NodeID = attr.Value.GetIntValue()
+		case "tenant_id":
+			ev.TenantID = attr.Value.GetIntValue()
+		}
+	}
+	return ev, true
+}
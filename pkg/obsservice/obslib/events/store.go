@@ -0,0 +1,251 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Package events implements the storage, ingestion and querying of the
+// structured events that CockroachDB nodes push to the Observability Service.
+//
+// Nodes export their structured log events (see pkg/util/log/eventpb) through
+// an OTLP log sink pointed at the Observability Service. The events are stored
+// in a CockroachDB database owned by the service, from which they can be
+// queried by event type, time range, node and tenant.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach-go/v2/crdb/crdbpgx"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// schema contains the statements creating the database and tables owned by
+// the Observability Service. The statements are idempotent.
+var schema = []string{
+	`CREATE DATABASE IF NOT EXISTS obsservice`,
+	`CREATE TABLE IF NOT EXISTS obsservice.events (
+  id          UUID        NOT NULL DEFAULT gen_random_uuid(),
+  timestamp   TIMESTAMPTZ NOT NULL,
+  event_type  STRING      NOT NULL,
+  cluster_id  STRING      NOT NULL DEFAULT '',
+  node_id     INT8        NOT NULL DEFAULT 0,
+  tenant_id   INT8        NOT NULL DEFAULT 0,
+  event       JSONB       NOT NULL,
+  PRIMARY KEY (timestamp, id),
+  INDEX events_event_type_timestamp_idx (event_type, timestamp)
+)`,
+}
+
+// insertBatchSize is the maximum number of events inserted by a single
+// statement.
+const insertBatchSize = 100
+
+// deleteBatchSize is the maximum number of events deleted by a single
+// statement when enforcing the retention period.
+const deleteBatchSize = 1000
+
+// Event is a structured event stored by the Observability Service.
+type Event struct {
+	// Timestamp is the time at which the event was logged.
+	Timestamp time.Time `json:"timestamp"`
+	// EventType is the type of the event, e.g. "create_database".
+	EventType string `json:"event_type"`
+	// ClusterID identifies the cluster that emitted the event, if known.
+	ClusterID string `json:"cluster_id,omitempty"`
+	// NodeID identifies the node that emitted the event, if known.
+	NodeID int64 `json:"node_id,omitempty"`
+	// TenantID identifies the tenant that emitted the event, if known.
+	TenantID int64 `json:"tenant_id,omitempty"`
+	// Payload is the JSON encoding of the event.
+	Payload json.RawMessage `json:"event"`
+}
+
+// Filter restricts the events returned by Store.Query. Zero values do not
+// restrict the results.
+type Filter struct {
+	// EventTypes, if not empty, restricts the results to the given types.
+	EventTypes []string
+	// Start, if set, is the inclusive lower bound of the event timestamps.
+	Start time.Time
+	// End, if set, is the exclusive upper bound of the event timestamps.
+	End time.Time
+	// NodeID, if set, restricts the results to the given node.
+	NodeID int64
+	// TenantID, if set, restricts the results to the given tenant.
+	TenantID int64
+	// Limit, if set, is the maximum number of events returned.
+	Limit int
+}
+
+// Store persists events in a CockroachDB database.
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+// NewStore creates a Store which persists events through the given
+// connection pool. EnsureSchema needs to be called before the store is used.
+func NewStore(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+// EnsureSchema creates the database and tables used by the store, if they
+// don't exist already.
+func (s *Store) EnsureSchema(ctx context.Context) error {
+	for _, stmt := range schema {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return errors.Wrap(err, "creating the events schema")
+		}
+	}
+	return nil
+}
+
+// Insert persists the given events. The events are inserted in a single
+// transaction, so either all of them or none of them are stored. The OTLP sinks
+// of CRDB nodes don't retry failed exports, so the events of a failed insertion
+// are lost.
+func (s *Store) Insert(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	err := crdbpgx.ExecuteTx(ctx, s.pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		for remaining := events; len(remaining) > 0; {
+			batch := remaining
+			if len(batch) > insertBatchSize {
+				batch = batch[:insertBatchSize]
+			}
+			remaining = remaining[len(batch):]
+
+			var b strings.Builder
+			b.WriteString(`INSERT INTO obsservice.events
+  (timestamp, event_type, cluster_id, node_id, tenant_id, event) VALUES `)
+			args := make([]interface{}, 0, 6*len(batch))
+			for i, ev := range batch {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				n := len(args)
+				fmt.Fprintf(&b, "($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6)
+				args = append(args,
+					ev.Timestamp, ev.EventType, ev.ClusterID, ev.NodeID, ev.TenantID, string(ev.Payload))
+			}
+			if _, err := tx.Exec(ctx, b.String(), args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "inserting events")
+	}
+	return nil
+}
+
+// Query returns the events matching the filter, most recent first.
+func (s *Store) Query(ctx context.Context, f Filter) ([]Event, error) {
+	var where []string
+	var args []interface{}
+	addCond := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if len(f.EventTypes) > 0 {
+		addCond("event_type = ANY($%d)", f.EventTypes)
+	}
+	if !f.Start.IsZero() {
+		addCond("timestamp >= $%d", f.Start)
+	}
+	if !f.End.IsZero() {
+		addCond("timestamp < $%d", f.End)
+	}
+	if f.NodeID != 0 {
+		addCond("node_id = $%d", f.NodeID)
+	}
+	if f.TenantID != 0 {
+		addCond("tenant_id = $%d", f.TenantID)
+	}
+
+	var b strings.Builder
+	b.WriteString(`SELECT timestamp, event_type, cluster_id, node_id, tenant_id, event
+  FROM obsservice.events`)
+	if len(where) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(where, " AND "))
+	}
+	b.WriteString(" ORDER BY timestamp DESC")
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		fmt.Fprintf(&b, " LIMIT $%d", len(args))
+	}
+
+	rows, err := s.pool.Query(ctx, b.String(), args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying events")
+	}
+	defer rows.Close()
+	var res []Event
+	for rows.Next() {
+		var ev Event
+		var payload []byte
+		if err := rows.Scan(
+			&ev.Timestamp, &ev.EventType, &ev.ClusterID, &ev.NodeID, &ev.TenantID, &payload,
+		); err != nil {
+			return nil, errors.Wrap(err, "reading events")
+		}
+		ev.Payload = payload
+		res = append(res, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading events")
+	}
+	return res, nil
+}
+
+// DeleteOlderThan deletes the events logged before the cutoff and returns the
+// number of deleted events. Events are deleted in batches, to avoid large
+// transactions.
+func (s *Store) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	var deleted int64
+	for {
+		tag, err := s.pool.Exec(ctx,
+			`DELETE FROM obsservice.events WHERE timestamp < $1 ORDER BY timestamp LIMIT $2`,
+			cutoff, deleteBatchSize)
+		if err != nil {
+			return deleted, errors.Wrap(err, "deleting expired events")
+		}
+		deleted += tag.RowsAffected()
+		if tag.RowsAffected() < deleteBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// RunRetention deletes, every interval, the events older than the retention
+// period. It returns when the context is canceled.
+func (s *Store) RunRetention(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		deleted, err := s.DeleteOlderThan(ctx, timeutil.Now().Add(-retention))
+		if err != nil {
+			log.Warningf(ctx, "failed to enforce the event retention period: %v", err)
+		} else if deleted > 0 {
+			log.Infof(ctx, "deleted %d events older than %s", deleted, retention)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -26,6 +26,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // ReverseHTTPProxyConfig groups the configuration for ReverseHTTPProxy.
@@ -53,6 +55,9 @@ type ReverseHTTPProxy struct {
 	listenAddr string
 	proxy      *httputil.ReverseProxy
 	certs      certificates
+	// handlers are served by the Observability Service itself instead of being
+	// forwarded to CRDB, keyed by their path pattern.
+	handlers map[string]http.Handler
 }
 
 // NewReverseHTTPProxy creates a ReverseHTTPProxy.
@@ -90,9 +95,38 @@ to trust the certificate presented by CockroachDB.`)
 		listenAddr: cfg.HTTPAddr,
 		proxy:      newProxy(url, certs.CAPool, HTTPToHTTPSErr),
 		certs:      certs,
+		handlers:   make(map[string]http.Handler),
 	}
 }
 
+// Handle registers a handler for the given path pattern. Requests matching the
+// pattern are served by the handler instead of being forwarded to CRDB. Needs
+// to be called before RunAsync().
+func (p *ReverseHTTPProxy) Handle(pattern string, handler http.Handler) {
+	p.handlers[pattern] = handler
+}
+
+// HandleWithClientCert is like Handle, except that requests are only served if
+// the client presents a certificate signed by the CA specified by CACertPath
+// (or by one of the system's CAs, if not specified). As client certificates
+// require TLS, all requests are refused if the proxy is not configured with a
+// UI certificate.
+func (p *ReverseHTTPProxy) HandleWithClientCert(pattern string, handler http.Handler) {
+	p.Handle(pattern, requireClientCert(handler))
+}
+
+// requireClientCert wraps a handler such that it refuses the requests of
+// clients which did not present a verified certificate.
+func requireClientCert(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			http.Error(w, "a client certificate is required", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
 // RunAsync runs an HTTP proxy server in a goroutine. The returned channel is
 // closed when the server terminates.
 //
@@ -115,7 +149,8 @@ func (p *ReverseHTTPProxy) RunAsync(ctx context.Context) <-chan struct{} {
 		}()
 
 		// Create the HTTP mux. Requests will generally be forwarded to p.proxy,
-		// except the /debug/pprof ones which will be served locally.
+		// except the /debug/pprof ones and the ones registered through Handle(),
+		// which will be served locally.
 		mux := http.NewServeMux()
 		mux.Handle("/", p.proxy)
 		// This seems to be the minimal set of handlers that we need to register in
@@ -126,6 +161,9 @@ func (p *ReverseHTTPProxy) RunAsync(ctx context.Context) <-chan struct{} {
 		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
 		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		for pattern, handler := range p.handlers {
+			mux.Handle(pattern, handler)
+		}
 
 		if !https {
 			// The Observability Service is not configured with certs, so it can only
@@ -151,8 +189,17 @@ func (p *ReverseHTTPProxy) RunAsync(ctx context.Context) <-chan struct{} {
 				_ = redirectServer.Serve(clearL)
 			}()
 
-			// Serve HTTPS traffic by delegating it to the proxy.
-			tlsServer := &http.Server{Handler: mux}
+			// Serve HTTPS traffic by delegating it to the proxy. Client
+			// certificates are verified if presented; the handlers registered
+			// through HandleWithClientCert() require them.
+			tlsServer := &http.Server{
+				Handler: mux,
+				TLSConfig: &tls.Config{
+					ClientAuth: tls.VerifyClientCertIfGiven,
+					ClientCAs:  p.certs.CAPool,
+					MinVersion: tls.VersionTLS12,
+				},
+			}
 			go func() {
 				_ = tlsServer.ServeTLS(tlsL, p.certs.UICertPath, p.certs.UICertKeyPath)
 			}()
@@ -172,6 +219,29 @@ func (p *ReverseHTTPProxy) RunAsync(ctx context.Context) <-chan struct{} {
 	return ch
 }
 
+// GRPCServerOptions returns the options for a gRPC server of the
+// Observability Service, which uses the same certificates as the proxy. If a
+// UI certificate is specified, the server speaks TLS with it and requires
+// clients to present a certificate signed by the CA specified by CACertPath
+// (or by one of the system's CAs, if not specified). Otherwise, like the proxy,
+// the server speaks plaintext and no options are returned.
+func GRPCServerOptions(cfg ReverseHTTPProxyConfig) ([]grpc.ServerOption, error) {
+	certs, err := loadCerts(cfg.UICertPath, cfg.UICertKeyPath, cfg.CACertPath)
+	if err != nil {
+		return nil, err
+	}
+	if certs.UICert == nil {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{*certs.UICert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    certs.CAPool,
+		MinVersion:   tls.VersionTLS12,
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, nil
+}
+
 // certificates groups together all the certificates relevant to the proxy
 // server.
 type certificates struct {
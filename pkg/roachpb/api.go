@@ -96,6 +96,7 @@ const (
 	canBackpressure                                           // commands which deserve backpressure when a Range grows too large
 	bypassesReplicaCircuitBreaker                             // commands which bypass the replica circuit breaker, i.e. opt out of fail-fast
 	requiresClosedTSOlderThanStorageSnapshot                  // commands which read a replica's closed timestamp that is older than the state of the storage engine
+	writesUserData                                            // commands which write user data, and are thus rejected by ranges in read-only mode
 )
 
 // flagDependencies specifies flag dependencies, asserted by TestFlagCombinations.
@@ -104,6 +105,7 @@ var flagDependencies = map[flag][]flag{
 	isLocking:       {isTxn},
 	isIntentWrite:   {isWrite, isLocking},
 	appliesTSCache:  {isWrite},
+	writesUserData:  {isWrite},
 	skipsLeaseCheck: {isAlone},
 }
 
@@ -159,6 +161,20 @@ func LockingDurability(args Request) lock.Durability {
 	return lock.Replicated
 }
 
+// LockingStrength returns the strength of the locks acquired by the request.
+// The function assumes that IsLocking(args).
+func LockingStrength(args Request) lock.Strength {
+	switch t := args.(type) {
+	case *GetRequest:
+		return t.KeyLocking
+	case *ScanRequest:
+		return t.KeyLocking
+	case *ReverseScanRequest:
+		return t.KeyLocking
+	}
+	return lock.Exclusive
+}
+
 // IsIntentWrite returns true if the request produces write intents at
 // the request's sequence number when used within a transaction.
 func IsIntentWrite(args Request) bool {
@@ -212,6 +228,13 @@ func BypassesReplicaCircuitBreaker(args Request) bool {
 	return (args.flags() & bypassesReplicaCircuitBreaker) != 0
 }
 
+// WritesUserData returns whether the command writes user data, as opposed to
+// the internal state of a range or its transactions. Such commands are
+// rejected by ranges in read-only mode.
+func WritesUserData(args Request) bool {
+	return (args.flags() & writesUserData) != 0
+}
+
 // Request is an interface for RPC requests.
 type Request interface {
 	protoutil.Message
@@ -1219,13 +1242,25 @@ func flagForLockStrength(l lock.Strength) flag {
 	return 0
 }
 
+// flagForLockDurability returns isWrite for locking reads that acquire
+// replicated locks, which are written to the replicated lock table and so must
+// go through Raft.
+func flagForLockDurability(l lock.Strength, d lock.Durability) flag {
+	if l != lock.None && d == lock.Replicated {
+		return isWrite
+	}
+	return 0
+}
+
 func (gr *GetRequest) flags() flag {
 	maybeLocking := flagForLockStrength(gr.KeyLocking)
-	return isRead | isTxn | maybeLocking | updatesTSCache | needsRefresh
+	maybeWrite := flagForLockDurability(gr.KeyLocking, gr.KeyLockingDurability)
+	return isRead | maybeWrite | isTxn | maybeLocking | updatesTSCache | needsRefresh
 }
 
 func (*PutRequest) flags() flag {
-	return isWrite | isTxn | isLocking | isIntentWrite | appliesTSCache | canBackpressure
+	return isWrite | isTxn | isLocking | isIntentWrite | appliesTSCache | canBackpressure |
+		writesUserData
 }
 
 // ConditionalPut effectively reads without writing if it hits a
@@ -1235,7 +1270,7 @@ func (*PutRequest) flags() flag {
 // transaction to be retried at end transaction.
 func (*ConditionalPutRequest) flags() flag {
 	return isRead | isWrite | isTxn | isLocking | isIntentWrite |
-		appliesTSCache | updatesTSCache | updatesTSCacheOnErr | canBackpressure
+		appliesTSCache | updatesTSCache | updatesTSCacheOnErr | canBackpressure | writesUserData
 }
 
 // InitPut, like ConditionalPut, effectively reads without writing if it hits a
@@ -1245,7 +1280,7 @@ func (*ConditionalPutRequest) flags() flag {
 // to be retried at end transaction.
 func (*InitPutRequest) flags() flag {
 	return isRead | isWrite | isTxn | isLocking | isIntentWrite |
-		appliesTSCache | updatesTSCache | updatesTSCacheOnErr | canBackpressure
+		appliesTSCache | updatesTSCache | updatesTSCacheOnErr | canBackpressure | writesUserData
 }
 
 // Increment reads the existing value, but always leaves an intent so
@@ -1254,17 +1289,19 @@ func (*InitPutRequest) flags() flag {
 // error immediately instead of continuing a serializable transaction
 // to be retried at end transaction.
 func (*IncrementRequest) flags() flag {
-	return isRead | isWrite | isTxn | isLocking | isIntentWrite | appliesTSCache | canBackpressure
+	return isRead | isWrite | isTxn | isLocking | isIntentWrite | appliesTSCache | canBackpressure |
+		writesUserData
 }
 
 func (*DeleteRequest) flags() flag {
-	return isWrite | isTxn | isLocking | isIntentWrite | appliesTSCache | canBackpressure
+	return isWrite | isTxn | isLocking | isIntentWrite | appliesTSCache | canBackpressure |
+		writesUserData
 }
 
 func (drr *DeleteRangeRequest) flags() flag {
 	// DeleteRangeRequest using MVCC range tombstones cannot be transactional.
 	if drr.UseExperimentalRangeTombstone {
-		return isWrite | isRange | isAlone | appliesTSCache
+		return isWrite | isRange | isAlone | appliesTSCache | writesUserData
 	}
 	// DeleteRangeRequest has different properties if the "inline" flag is set.
 	// This flag indicates that the request is deleting inline MVCC values,
@@ -1290,29 +1327,32 @@ func (drr *DeleteRangeRequest) flags() flag {
 	// that exist would not be lost (since the DeleteRange leaves intents on
 	// those keys), but deletes of "empty space" would.
 	return isRead | isWrite | isTxn | isLocking | isIntentWrite | isRange |
-		appliesTSCache | updatesTSCache | needsRefresh | canBackpressure
+		appliesTSCache | updatesTSCache | needsRefresh | canBackpressure | writesUserData
 }
 
 // Note that ClearRange commands cannot be part of a transaction as
 // they clear all MVCC versions.
 func (*ClearRangeRequest) flags() flag {
-	return isWrite | isRange | isAlone | bypassesReplicaCircuitBreaker
+	return isWrite | isRange | isAlone | bypassesReplicaCircuitBreaker | writesUserData
 }
 
 // Note that RevertRange commands cannot be part of a transaction as
 // they clear all MVCC versions above their target time.
 func (*RevertRangeRequest) flags() flag {
-	return isWrite | isRange | isAlone | bypassesReplicaCircuitBreaker
+	return isWrite | isRange | isAlone | bypassesReplicaCircuitBreaker | writesUserData
 }
 
 func (sr *ScanRequest) flags() flag {
 	maybeLocking := flagForLockStrength(sr.KeyLocking)
-	return isRead | isRange | isTxn | maybeLocking | updatesTSCache | needsRefresh
+	maybeWrite := flagForLockDurability(sr.KeyLocking, sr.KeyLockingDurability)
+	return isRead | maybeWrite | isRange | isTxn | maybeLocking | updatesTSCache | needsRefresh
 }
 
 func (rsr *ReverseScanRequest) flags() flag {
 	maybeLocking := flagForLockStrength(rsr.KeyLocking)
-	return isRead | isRange | isReverse | isTxn | maybeLocking | updatesTSCache | needsRefresh
+	maybeWrite := flagForLockDurability(rsr.KeyLocking, rsr.KeyLockingDurability)
+	return isRead | maybeWrite | isRange | isReverse | isTxn | maybeLocking | updatesTSCache |
+		needsRefresh
 }
 
 // EndTxn updates the timestamp cache to prevent replays.
@@ -1395,7 +1435,8 @@ func (*ExportRequest) flags() flag {
 func (*AdminScatterRequest) flags() flag                  { return isAdmin | isRange | isAlone }
 func (*AdminVerifyProtectedTimestampRequest) flags() flag { return isAdmin | isRange | isAlone }
 func (r *AddSSTableRequest) flags() flag {
-	flags := isWrite | isRange | isAlone | isUnsplittable | canBackpressure | bypassesReplicaCircuitBreaker
+	flags := isWrite | isRange | isAlone | isUnsplittable | canBackpressure |
+		bypassesReplicaCircuitBreaker | writesUserData
 	if r.SSTTimestampToRequestTimestamp.IsSet() {
 		flags |= appliesTSCache
 	}
@@ -1465,7 +1506,7 @@ func (m *ExternalStorage) AccessIsWithExplicitAuth() bool {
 		return true
 	case ExternalStorageProvider_null:
 		return true
-	case ExternalStorageProvider_http:
+	case ExternalStorageProvider_http, ExternalStorageProvider_sftp, ExternalStorageProvider_webdav:
 		// Arbitrary network endpoints may be accessible only via the node and thus
 		// make use of its implicit access to them.
 		return false
@@ -1679,6 +1720,16 @@ func (c *ContentionEvent) String() string {
 	return redact.StringWithoutMarkers(c)
 }
 
+// SafeFormat implements redact.SafeFormatter.
+func (e *AdmissionWaitEvent) SafeFormat(w redact.SafePrinter, _ rune) {
+	w.Printf("waited in %s queue for %.3fs", redact.SafeString(e.WorkKind), e.WaitDuration.Seconds())
+}
+
+// String implements fmt.Stringer.
+func (e *AdmissionWaitEvent) String() string {
+	return redact.StringWithoutMarkers(e)
+}
+
 // Equal returns whether the two structs are identical. Needed for compatibility
 // with proto2.
 func (c *TenantConsumption) Equal(other *TenantConsumption) bool {
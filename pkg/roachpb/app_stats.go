@@ -218,6 +218,8 @@ func (s *ExecStats) Add(other ExecStats) {
 	s.ContentionTime.Add(other.ContentionTime, execStatCollectionCount, other.Count)
 	s.NetworkMessages.Add(other.NetworkMessages, execStatCollectionCount, other.Count)
 	s.MaxDiskUsage.Add(other.MaxDiskUsage, execStatCollectionCount, other.Count)
+	s.CPUTime.Add(other.CPUTime, execStatCollectionCount, other.Count)
+	s.AdmissionWaitTime.Add(other.AdmissionWaitTime, execStatCollectionCount, other.Count)
 
 	s.Count += other.Count
 }
@@ -203,6 +203,20 @@ func (ba *BatchRequest) IsLocking() bool {
 	return ba.hasFlag(isLocking)
 }
 
+// LockingStrength returns the strongest lock strength with which the requests
+// in the BatchRequest acquire locks, or lock.None if none of them do.
+func (ba *BatchRequest) LockingStrength() lock.Strength {
+	str := lock.None
+	for _, union := range ba.Requests {
+		if req := union.GetInner(); IsLocking(req) {
+			if reqStr := LockingStrength(req); reqStr > str {
+				str = reqStr
+			}
+		}
+	}
+	return str
+}
+
 // IsIntentWrite returns true iff the BatchRequest contains an intent write.
 func (ba *BatchRequest) IsIntentWrite() bool {
 	return ba.hasFlag(isIntentWrite)
@@ -280,6 +280,24 @@ func TestLockSpanIterate(t *testing.T) {
 	}
 }
 
+func TestBatchRequestLockingStrength(t *testing.T) {
+	testCases := []struct {
+		reqs []Request
+		exp  lock.Strength
+	}{
+		{[]Request{&GetRequest{}, &ScanRequest{}}, lock.None},
+		{[]Request{&GetRequest{}, &ScanRequest{KeyLocking: lock.Shared}}, lock.Shared},
+		{[]Request{&ScanRequest{KeyLocking: lock.Shared}, &ReverseScanRequest{KeyLocking: lock.Upgrade}}, lock.Upgrade},
+		{[]Request{&GetRequest{KeyLocking: lock.Shared}, &PutRequest{}}, lock.Exclusive},
+		{[]Request{&DeleteRangeRequest{}}, lock.Exclusive},
+	}
+	for _, tc := range testCases {
+		ba := BatchRequest{}
+		ba.Add(tc.reqs...)
+		require.Equal(t, tc.exp, ba.LockingStrength(), "%s", ba.Summary())
+	}
+}
+
 func TestRefreshSpanIterate(t *testing.T) {
 	testCases := []struct {
 		req    Request
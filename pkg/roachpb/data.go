@@ -2003,6 +2003,15 @@ func MakeIntent(txn *enginepb.TxnMeta, key Key) Intent {
 	return i
 }
 
+// LockStrength returns the strength of the replicated lock, accounting for
+// write intents, which leave the Strength field unset and are Exclusive locks.
+func (i *Intent) LockStrength() lock.Strength {
+	if i.Strength == lock.None {
+		return lock.Exclusive
+	}
+	return i.Strength
+}
+
 // AsIntents takes a transaction and a slice of keys and
 // returns it as a slice of intents.
 func AsIntents(txn *enginepb.TxnMeta, keys []Key) []Intent {
@@ -2014,9 +2023,21 @@ func AsIntents(txn *enginepb.TxnMeta, keys []Key) []Intent {
 }
 
 // MakeLockAcquisition makes a lock acquisition message from the given
-// txn, key, and durability level.
-func MakeLockAcquisition(txn *Transaction, key Key, dur lock.Durability) LockAcquisition {
-	return LockAcquisition{Span: Span{Key: key}, Txn: txn.TxnMeta, Durability: dur}
+// txn, key, strength, and durability level.
+func MakeLockAcquisition(
+	txn *Transaction, key Key, str lock.Strength, dur lock.Durability,
+) LockAcquisition {
+	return LockAcquisition{Span: Span{Key: key}, Txn: txn.TxnMeta, Strength: str, Durability: dur}
+}
+
+// LockStrength returns the strength of the lock being acquired, accounting for
+// acquisitions that predate the Strength field and always acquired Exclusive
+// locks.
+func (acq *LockAcquisition) LockStrength() lock.Strength {
+	if acq.Strength == lock.None {
+		return lock.Exclusive
+	}
+	return acq.Strength
 }
 
 // MakeLockUpdate makes a lock update from the given txn and span.
@@ -68,6 +68,9 @@ func (s *SpanConfig) ValidateSystemTargetSpanConfig() error {
 	if s.GCPolicy.IgnoreStrictEnforcement {
 		return errors.AssertionFailedf("IgnoreStrictEnforcement set on system span config")
 	}
+	if len(s.GCPolicy.RetentionTiers) != 0 {
+		return errors.AssertionFailedf("RetentionTiers set on system span config")
+	}
 	if s.GlobalReads {
 		return errors.AssertionFailedf("GlobalReads set on system span config")
 	}
@@ -92,6 +95,12 @@ func (s *SpanConfig) ValidateSystemTargetSpanConfig() error {
 	if s.ExcludeDataFromBackup {
 		return errors.AssertionFailedf("ExcludeDataFromBackup set on system span config")
 	}
+	if s.StorageQuotaBytes != 0 {
+		return errors.AssertionFailedf("StorageQuotaBytes set on system span config")
+	}
+	if s.ReadOnly {
+		return errors.AssertionFailedf("ReadOnly set on system span config")
+	}
 	return nil
 }
 
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/status"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/goschedstats"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -90,6 +91,7 @@ func startSampleEnvironment(
 	var nonGoAllocProfiler *heapprofiler.NonGoAllocProfiler
 	var statsProfiler *heapprofiler.StatsProfiler
 	var queryProfiler *heapprofiler.ActiveQueryProfiler
+	var cpuProfiler *heapprofiler.CPUProfiler
 	if cfg.heapProfileDirName != "" {
 		hasValidDumpDir := true
 		if err := os.MkdirAll(cfg.heapProfileDirName, 0755); err != nil {
@@ -120,6 +122,10 @@ func startSampleEnvironment(
 			if err != nil {
 				log.Warningf(ctx, "failed to start query profiler worker: %v", err)
 			}
+			cpuProfiler, err = heapprofiler.NewCPUProfiler(ctx, cfg.heapProfileDirName, cfg.st, cfg.stopper)
+			if err != nil {
+				return errors.Wrap(err, "starting cpu profiler worker")
+			}
 		}
 	}
 
@@ -188,6 +194,9 @@ func startSampleEnvironment(
 					if queryProfiler != nil {
 						queryProfiler.MaybeDumpQueries(ctx, cfg.sessionRegistry, cfg.st)
 					}
+					if cpuProfiler != nil {
+						cpuProfiler.MaybeTakeProfile(ctx, goschedstats.RecentNormalizedRunnableGoroutines())
+					}
 				}
 			}
 		})
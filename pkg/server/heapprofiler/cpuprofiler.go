@@ -0,0 +1,203 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package heapprofiler
+
+import (
+	"context"
+	"os"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/debug"
+	"github.com/cockroachdb/cockroach/pkg/server/dumpstore"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+var (
+	cpuProfileInterval = settings.RegisterDurationSetting(
+		settings.TenantWritable,
+		"server.cpu_profile.interval",
+		"interval at which a CPU profile is taken and written to the memory profile "+
+			"directory (0 disables periodic CPU profiles)",
+		15*time.Minute,
+		settings.NonNegativeDuration,
+	).WithPublic()
+
+	cpuProfileDuration = settings.RegisterDurationSetting(
+		settings.TenantWritable,
+		"server.cpu_profile.duration",
+		"duration of the CPU profiles taken periodically or when the CPU is overloaded",
+		10*time.Second,
+		settings.PositiveDuration,
+	).WithPublic()
+
+	cpuProfileRunnableThreshold = settings.RegisterFloatSetting(
+		settings.TenantWritable,
+		"server.cpu_profile.runnable_goroutines_threshold",
+		"number of runnable goroutines per CPU above which a CPU profile is taken, "+
+			"at most once per minute (0 disables CPU profiles triggered by overload)",
+		8,
+		settings.NonNegativeFloat,
+	).WithPublic()
+)
+
+// minSpikeProfileInterval is the minimum time between the start of a
+// profile and the start of a profile triggered by a CPU spike. It prevents a
+// sustained overload from producing back-to-back profiles.
+const minSpikeProfileInterval = time.Minute
+
+const (
+	// CPUFileNamePrefix is the prefix of files containing CPU profiles.
+	CPUFileNamePrefix = "cpuprof"
+	// CPUFileNameSuffix is the suffix of files containing CPU profiles.
+	CPUFileNameSuffix = ".pprof"
+)
+
+// CPUProfiler is used to take Go CPU profiles continuously.
+//
+// MaybeTakeProfile() is supposed to be called periodically. A profile is taken
+// every server.cpu_profile.interval and whenever the number of runnable
+// goroutines per CPU exceeds server.cpu_profile.runnable_goroutines_threshold.
+// The profiles are taken with pprof labels enabled, so that the samples are
+// attributed to the statement fingerprints, jobs and tenants that used the
+// CPU. They are written next to the heap profiles, where they are GCed with
+// the same policy and picked up by `cockroach debug zip`.
+//
+// A profile is not taken if another CPU profile is already in progress, for
+// example one requested through the pprof endpoints.
+type CPUProfiler struct {
+	store   *profileStore
+	st      *cluster.Settings
+	stopper *stop.Stopper
+
+	// lastProfileTime marks the time when we started the last profile.
+	lastProfileTime time.Time
+	// profiling is 1 while a profile is being taken.
+	profiling int32
+
+	knobs testingKnobs
+}
+
+// NewCPUProfiler creates a CPUProfiler. dir is the directory in which profiles
+// are to be stored.
+func NewCPUProfiler(
+	ctx context.Context, dir string, st *cluster.Settings, stopper *stop.Stopper,
+) (*CPUProfiler, error) {
+	if dir == "" {
+		return nil, errors.AssertionFailedf("need to specify dir for NewCPUProfiler")
+	}
+
+	log.Infof(ctx, "writing cpu profiles to %s", dir)
+
+	dumpStore := dumpstore.NewStore(dir, maxCombinedFileSize, st)
+
+	cp := &CPUProfiler{
+		store:   newProfileStore(dumpStore, CPUFileNamePrefix, CPUFileNameSuffix, st),
+		st:      st,
+		stopper: stopper,
+		// The first periodic profile is taken one interval after startup rather
+		// than immediately, so as not to compete with the startup work.
+		lastProfileTime: timeutil.Now(),
+	}
+	return cp, nil
+}
+
+func (o *CPUProfiler) now() time.Time {
+	if o.knobs.now != nil {
+		return o.knobs.now()
+	}
+	return timeutil.Now()
+}
+
+// MaybeTakeProfile starts a CPU profile, in the background, if one is due.
+// runnable is the recent number of runnable goroutines per CPU.
+func (o *CPUProfiler) MaybeTakeProfile(ctx context.Context, runnable float64) {
+	now := o.now()
+	takeProfile := o.shouldTakeProfile(now, runnable)
+	if hook := o.knobs.maybeTakeProfileHook; hook != nil {
+		hook(takeProfile)
+	}
+	if !takeProfile {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&o.profiling, 0, 1) {
+		// The previous profile is still being taken.
+		return
+	}
+	o.lastProfileTime = now
+	if o.knobs.dontWriteProfiles {
+		atomic.StoreInt32(&o.profiling, 0)
+		return
+	}
+
+	// The file name records the number of runnable goroutines per CPU, in
+	// thousandths, which plays the role of the heap size for the GC policy.
+	path := o.store.makeNewFileName(now, int64(runnable*1000))
+	duration := cpuProfileDuration.Get(&o.st.SV)
+	if err := o.stopper.RunAsyncTask(ctx, "cpu-profiler", func(ctx context.Context) {
+		defer atomic.StoreInt32(&o.profiling, 0)
+		if o.takeCPUProfile(ctx, path, duration) {
+			// We only remove old files if the current profile was
+			// successful. Otherwise, the GC may remove "interesting" files
+			// from a previous crash.
+			o.store.gcProfiles(ctx, now)
+		}
+	}); err != nil {
+		atomic.StoreInt32(&o.profiling, 0)
+	}
+}
+
+// shouldTakeProfile returns whether a profile is due, either because the
+// profiling interval elapsed or because the CPU is overloaded.
+func (o *CPUProfiler) shouldTakeProfile(now time.Time, runnable float64) bool {
+	sinceLast := now.Sub(o.lastProfileTime)
+	if interval := cpuProfileInterval.Get(&o.st.SV); interval > 0 && sinceLast >= interval {
+		return true
+	}
+	threshold := cpuProfileRunnableThreshold.Get(&o.st.SV)
+	return threshold > 0 && runnable >= threshold && sinceLast >= minSpikeProfileInterval
+}
+
+// takeCPUProfile writes a CPU profile of the given duration to path. It
+// returns true if and only if the profile was taken successfully.
+func (o *CPUProfiler) takeCPUProfile(
+	ctx context.Context, path string, duration time.Duration,
+) (success bool) {
+	if err := debug.CPUProfileDo(o.st, cluster.CPUProfileWithLabels, func() error {
+		f, err := os.Create(path)
+		if err != nil {
+			return errors.Wrap(err, "creating cpu profile")
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			// Another profile was started without going through
+			// CPUProfileDo; leave it alone.
+			_ = os.Remove(path)
+			return errors.Wrap(err, "starting cpu profile")
+		}
+		defer pprof.StopCPUProfile()
+		select {
+		case <-time.After(duration):
+		case <-o.stopper.ShouldQuiesce():
+		}
+		return nil
+	}); err != nil {
+		log.Infof(ctx, "skipping cpu profile %s: %v", path, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package heapprofiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPUProfilerSchedule(t *testing.T) {
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	cpuProfileInterval.Override(ctx, &st.SV, 10*time.Minute)
+	cpuProfileRunnableThreshold.Override(ctx, &st.SV, 4)
+
+	type test struct {
+		secs     int // The measurement's timestamp.
+		runnable float64
+
+		expProfile bool
+	}
+	tests := []test{
+		{0, 1, false},   // not overloaded, interval not elapsed
+		{30, 5, false},  // overloaded, but too soon after the previous profile
+		{60, 5, true},   // overloaded
+		{90, 10, false}, // overloaded, but too soon after the previous profile
+		{300, 1, false}, // not overloaded, interval not elapsed
+		{660, 1, true},  // interval elapsed
+		{670, 1, false}, // interval not elapsed
+	}
+	var currentTime time.Time
+	now := func() time.Time {
+		return currentTime
+	}
+
+	var tookProfile bool
+	cp := &CPUProfiler{
+		st: st,
+		knobs: testingKnobs{
+			now:               now,
+			dontWriteProfiles: true,
+			maybeTakeProfileHook: func(willTakeProfile bool) {
+				tookProfile = willTakeProfile
+			},
+		},
+	}
+
+	for i, r := range tests {
+		currentTime = (time.Time{}).Add(time.Second * time.Duration(r.secs))
+
+		cp.MaybeTakeProfile(ctx, r.runnable)
+		assert.Equal(t, r.expProfile, tookProfile, i)
+	}
+
+	// Disabling both triggers disables the profiler.
+	cpuProfileInterval.Override(ctx, &st.SV, 0)
+	cpuProfileRunnableThreshold.Override(ctx, &st.SV, 0)
+	currentTime = currentTime.Add(24 * time.Hour)
+	cp.MaybeTakeProfile(ctx, 100)
+	assert.False(t, tookProfile)
+}
+
+func TestCPUProfilerWritesProfile(t *testing.T) {
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	st := cluster.MakeTestingClusterSettings()
+	dir := t.TempDir()
+
+	cp, err := NewCPUProfiler(ctx, dir, st, stopper)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "cpuprof.2022-06-01T00_00_00.000.1000.pprof")
+	require.True(t, cp.takeCPUProfile(ctx, path, 10*time.Millisecond))
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NotZero(t, fi.Size())
+	ok, _, runnable := cp.store.parseFileName(ctx, fi.Name())
+	require.True(t, ok)
+	require.Equal(t, uint64(1000), runnable)
+	// The profile type is reset once the profile has been taken.
+	require.Equal(t, cluster.CPUProfileNone, st.CPUProfileType())
+
+	// No profile is taken while another one is in progress.
+	require.NoError(t, st.SetCPUProfiling(cluster.CPUProfileDefault))
+	path = filepath.Join(dir, "cpuprof.2022-06-01T00_01_00.000.1000.pprof")
+	require.False(t, cp.takeCPUProfile(ctx, path, 10*time.Millisecond))
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+	require.Equal(t, cluster.CPUProfileDefault, st.CPUProfileType())
+}
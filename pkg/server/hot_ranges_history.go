@@ -0,0 +1,293 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+var (
+	// hotRangesHistoryInterval is the interval at which each store records its
+	// hottest and largest ranges in system.hot_ranges_history.
+	hotRangesHistoryInterval = settings.RegisterDurationSetting(
+		settings.SystemOnly,
+		"server.hot_ranges_history.interval",
+		"interval at which each store records its hottest and largest ranges in "+
+			"system.hot_ranges_history (0 disables the recording)",
+		10*time.Minute,
+		settings.NonNegativeDuration,
+	).WithPublic()
+
+	// hotRangesHistoryRangesPerStore is the number of ranges recorded for each
+	// of the rankings of a store.
+	hotRangesHistoryRangesPerStore = settings.RegisterIntSetting(
+		settings.SystemOnly,
+		"server.hot_ranges_history.ranges_per_store",
+		"number of ranges recorded in system.hot_ranges_history for each store by "+
+			"each of queries per second, writes per second and logical bytes",
+		10,
+		settings.PositiveInt,
+	).WithPublic()
+
+	// hotRangesHistoryTTL is the TTL for rows in system.hot_ranges_history.
+	hotRangesHistoryTTL = settings.RegisterDurationSetting(
+		settings.SystemOnly,
+		"server.hot_ranges_history.ttl",
+		fmt.Sprintf(
+			"if nonzero, entries in system.hot_ranges_history older than this duration "+
+				"are deleted every %s",
+			systemLogGCPeriod,
+		),
+		7*24*time.Hour, // 7 days
+		settings.NonNegativeDuration,
+	).WithPublic()
+)
+
+// hotRangesHistoryNumCols is the number of columns written for every range
+// recorded in system.hot_ranges_history.
+const hotRangesHistoryNumCols = 10
+
+// startRecordingHotRangesHistory starts a worker which periodically records
+// the hottest and largest ranges of each store in system.hot_ranges_history,
+// so that hot spots can be investigated after they have cooled down.
+func (s *Server) startRecordingHotRangesHistory(ctx context.Context) {
+	intervalChangedCh := make(chan struct{}, 1)
+	hotRangesHistoryInterval.SetOnChange(&s.st.SV, func(context.Context) {
+		select {
+		case intervalChangedCh <- struct{}{}:
+		default:
+		}
+	})
+
+	_ = s.stopper.RunAsyncTask(ctx, "hot-ranges-history", func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			var timerC <-chan time.Time
+			if interval := hotRangesHistoryInterval.Get(&s.st.SV); interval > 0 {
+				timer.Reset(interval)
+				timerC = timer.C
+			}
+			select {
+			case <-timerC:
+				timer.Read = true
+				if err := s.recordHotRangesHistory(ctx); err != nil {
+					log.Warningf(ctx, "error recording hot ranges history: %v", err)
+				}
+			case <-intervalChangedCh:
+			case <-s.stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// recordHotRangesHistory writes the hottest and largest ranges of each store
+// on this node to system.hot_ranges_history.
+func (s *Server) recordHotRangesHistory(ctx context.Context) error {
+	if !s.st.Version.IsActive(ctx, clusterversion.HotRangesHistoryTable) {
+		return nil
+	}
+	rangeReportMetas, err := s.status.hotRangeReportMetas(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.PhysicalTime()
+	limit := int(hotRangesHistoryRangesPerStore.Get(&s.st.SV))
+	var args []interface{}
+	if err := s.node.stores.VisitStores(func(store *kvserver.Store) error {
+		for _, r := range hotRangesToRecord(store, limit) {
+			var dbName, tableName, indexName interface{}
+			if names, err := s.status.lookupHotRangeNames(rangeReportMetas, r.Desc.StartKey); err == nil {
+				dbName, tableName, indexName = nullIfEmpty(names.dbName),
+					nullIfEmpty(names.tableName), nullIfEmpty(names.indexName)
+			}
+			args = append(args,
+				now,
+				s.NodeID(),
+				store.StoreID(),
+				r.Desc.RangeID,
+				r.QPS,
+				r.WriteKeysPerSecond,
+				r.LogicalBytes,
+				dbName,
+				tableName,
+				indexName,
+			)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	var query strings.Builder
+	query.WriteString(`INSERT INTO system.hot_ranges_history (
+  timestamp, node_id, store_id, range_id, queries_per_second, writes_per_second,
+  logical_bytes, database_name, table_name, index_name
+) VALUES `)
+	for i := 0; i < len(args); i += hotRangesHistoryNumCols {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteByte('(')
+		for j := 1; j <= hotRangesHistoryNumCols; j++ {
+			if j > 1 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", i+j)
+		}
+		query.WriteByte(')')
+	}
+	_, err = s.sqlServer.internalExecutor.ExecEx(
+		ctx,
+		"hot-ranges-history-insert",
+		nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: username.NodeUserName()},
+		query.String(),
+		args...,
+	)
+	return err
+}
+
+// hotRangesToRecord returns the union of the top limit replicas of the store
+// by queries per second, by writes per second and by logical bytes.
+func hotRangesToRecord(store *kvserver.Store, limit int) []kvserver.HotReplicaInfo {
+	var res []kvserver.HotReplicaInfo
+	seen := make(map[roachpb.RangeID]struct{})
+	for _, ranked := range [][]kvserver.HotReplicaInfo{
+		store.HottestReplicas(),
+		store.HottestReplicasByWrites(),
+		store.LargestReplicas(),
+	} {
+		if len(ranked) > limit {
+			ranked = ranked[:limit]
+		}
+		for _, r := range ranked {
+			if _, ok := seen[r.Desc.RangeID]; ok {
+				continue
+			}
+			seen[r.Desc.RangeID] = struct{}{}
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// HotRangesHistory returns the ranges recorded in system.hot_ranges_history.
+func (s *statusServer) HotRangesHistory(
+	ctx context.Context, req *serverpb.HotRangesHistoryRequest,
+) (_ *serverpb.HotRangesHistoryResponse, retErr error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	if err := s.privilegeChecker.requireViewActivityOrViewActivityRedactedPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	response := &serverpb.HotRangesHistoryResponse{}
+	if !s.st.Version.IsActive(ctx, clusterversion.HotRangesHistoryTable) {
+		return response, nil
+	}
+
+	var where []string
+	var args []interface{}
+	if req.StartTime != nil {
+		args = append(args, *req.StartTime)
+		where = append(where, fmt.Sprintf("timestamp >= $%d", len(args)))
+	}
+	if req.EndTime != nil {
+		args = append(args, *req.EndTime)
+		where = append(where, fmt.Sprintf("timestamp <= $%d", len(args)))
+	}
+	var whereClause string
+	if len(where) > 0 {
+		whereClause = "WHERE " + strings.Join(where, " AND ")
+	}
+	var limitClause string
+	if req.Limit > 0 {
+		args = append(args, req.Limit)
+		limitClause = fmt.Sprintf("LIMIT $%d", len(args))
+	}
+	query := fmt.Sprintf(`SELECT
+  timestamp, node_id, store_id, range_id, queries_per_second, writes_per_second,
+  logical_bytes, database_name, table_name, index_name
+FROM system.hot_ranges_history
+%s
+ORDER BY timestamp DESC, store_id, range_id
+%s`, whereClause, limitClause)
+
+	it, err := s.internalExecutor.QueryIteratorEx(ctx, "hot-ranges-history", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: username.NodeUserName()},
+		query, args...)
+	if err != nil {
+		return nil, serverError(ctx, err)
+	}
+	defer func() {
+		retErr = errors.CombineErrors(retErr, it.Close())
+	}()
+
+	var ok bool
+	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+		row := it.Cur()
+		if row.Len() != hotRangesHistoryNumCols {
+			return nil, errors.Newf("expected %d columns, received %d", hotRangesHistoryNumCols, row.Len())
+		}
+		r := serverpb.HotRangesHistoryResponse_HotRange{
+			Timestamp:       tree.MustBeDTimestamp(row[0]).Time,
+			NodeID:          roachpb.NodeID(tree.MustBeDInt(row[1])),
+			StoreID:         roachpb.StoreID(tree.MustBeDInt(row[2])),
+			RangeID:         roachpb.RangeID(tree.MustBeDInt(row[3])),
+			QPS:             float64(tree.MustBeDFloat(row[4])),
+			WritesPerSecond: float64(tree.MustBeDFloat(row[5])),
+			LogicalBytes:    int64(tree.MustBeDInt(row[6])),
+		}
+		if row[7] != tree.DNull {
+			r.DatabaseName = string(tree.MustBeDString(row[7]))
+		}
+		if row[8] != tree.DNull {
+			r.TableName = string(tree.MustBeDString(row[8]))
+		}
+		if row[9] != tree.DNull {
+			r.IndexName = string(tree.MustBeDString(row[9]))
+		}
+		response.Ranges = append(response.Ranges, r)
+	}
+	if err != nil {
+		return nil, serverError(ctx, err)
+	}
+	return response, nil
+}
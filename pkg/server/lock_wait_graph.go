@@ -0,0 +1,370 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultLongChainLength is the wait depth at or above which a transaction
+// is flagged as being at the end of a long chain of waits, unless specified
+// otherwise in the LockWaitGraphRequest.
+const defaultLongChainLength = 3
+
+// LockWaitGraph returns the graph of transactions waiting on each other,
+// built from the lock tables and txn wait queues of the ranges for which the
+// nodes hold the lease.
+func (s *statusServer) LockWaitGraph(
+	ctx context.Context, req *serverpb.LockWaitGraphRequest,
+) (*serverpb.LockWaitGraphResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	// Check permissions early to avoid fan-out to all nodes.
+	if err := s.privilegeChecker.requireViewActivityOrViewActivityRedactedPermission(ctx); err != nil {
+		// NB: not using serverError() here since the priv checker
+		// already returns a proper gRPC error status.
+		return nil, err
+	}
+
+	if len(req.NodeID) > 0 {
+		requestedNodeID, local, err := s.parseNodeID(req.NodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		if local {
+			user, isAdmin, err := s.privilegeChecker.getUserAndRole(ctx)
+			if err != nil {
+				return nil, serverError(ctx, err)
+			}
+			shouldRedactKeys := false
+			if !isAdmin {
+				shouldRedactKeys, err = s.privilegeChecker.hasRoleOption(ctx, user, roleoption.VIEWACTIVITYREDACTED)
+				if err != nil {
+					return nil, serverError(ctx, err)
+				}
+			}
+			return s.localLockWaitGraph(ctx, shouldRedactKeys), nil
+		}
+
+		statusClient, err := s.dialNode(ctx, requestedNodeID)
+		if err != nil {
+			return nil, serverError(ctx, err)
+		}
+		return statusClient.LockWaitGraph(ctx, req)
+	}
+
+	var response serverpb.LockWaitGraphResponse
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		statusClient := client.(serverpb.StatusClient)
+		return statusClient.LockWaitGraph(ctx, &serverpb.LockWaitGraphRequest{NodeID: "local"})
+	}
+	responseFn := func(_ roachpb.NodeID, nodeResp interface{}) {
+		response.Edges = append(response.Edges, nodeResp.(*serverpb.LockWaitGraphResponse).Edges...)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		errResponse := serverpb.ListActivityError{NodeID: nodeID, Message: err.Error()}
+		response.Errors = append(response.Errors, errResponse)
+	}
+	if err := s.iterateNodes(ctx, "lock wait graph", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		return nil, serverError(ctx, err)
+	}
+	// The same wait can be reported by two nodes while the lease of its range
+	// is being transferred.
+	response.Edges = dedupLockWaitEdges(response.Edges)
+
+	longChainLength := int(req.LongChainLength)
+	if longChainLength <= 0 {
+		longChainLength = defaultLongChainLength
+	}
+	response.Txns = buildLockWaitGraph(response.Edges, longChainLength)
+	if len(response.Txns) == 0 {
+		return &response, nil
+	}
+
+	// Annotate the transactions with the sessions running them. The statements
+	// are redacted by ListSessions if need be.
+	sessions, err := s.ListSessions(ctx, &serverpb.ListSessionsRequest{ExcludeClosedSessions: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range sessions.Errors {
+		response.Errors = append(response.Errors, serverpb.ListActivityError{
+			NodeID: e.NodeID, Message: e.Message,
+		})
+	}
+	annotateLockWaitGraph(response.Txns, sessions.Sessions)
+	return &response, nil
+}
+
+// localLockWaitGraph returns the waits observed by the stores of this node.
+func (s *statusServer) localLockWaitGraph(
+	ctx context.Context, shouldRedactKeys bool,
+) *serverpb.LockWaitGraphResponse {
+	nodeID := s.gossip.NodeID.Get()
+	var response serverpb.LockWaitGraphResponse
+	// The visitor never returns an error.
+	_ = s.stores.VisitStores(func(store *kvserver.Store) error {
+		locks, pushes := store.LockWaits(ctx)
+		for _, l := range locks {
+			if l.LockHolder == nil {
+				// The lock is not held. Its waiters wait for the request which
+				// reserved it rather than for a transaction.
+				continue
+			}
+			key := l.Key
+			if shouldRedactKeys {
+				key = nil
+			}
+			for _, w := range l.Waiters {
+				e := serverpb.LockWaitGraphResponse_Edge{
+					HolderTxnID:  l.LockHolder.ID,
+					RangeID:      l.RangeID,
+					Key:          key,
+					Strength:     w.Strength,
+					WaitDuration: w.WaitDuration,
+					Source:       serverpb.LockWaitGraphResponse_LOCK_TABLE,
+					NodeID:       nodeID,
+				}
+				if w.WaitingTxn != nil {
+					e.WaiterTxnID = w.WaitingTxn.ID
+				}
+				response.Edges = append(response.Edges, e)
+			}
+		}
+		for _, p := range pushes {
+			e := serverpb.LockWaitGraphResponse_Edge{
+				HolderTxnID:  p.Pushee.ID,
+				RangeID:      p.RangeID,
+				WaitDuration: p.WaitDuration,
+				Source:       serverpb.LockWaitGraphResponse_TXN_WAIT_QUEUE,
+				NodeID:       nodeID,
+			}
+			if p.Pusher != nil {
+				e.WaiterTxnID = p.Pusher.ID
+			}
+			response.Edges = append(response.Edges, e)
+		}
+		return nil
+	})
+	return &response
+}
+
+// dedupLockWaitEdges removes the edges which describe the same wait as a
+// previous edge, keeping the one with the longest wait duration.
+func dedupLockWaitEdges(
+	edges []serverpb.LockWaitGraphResponse_Edge,
+) []serverpb.LockWaitGraphResponse_Edge {
+	type edgeKey struct {
+		waiter, holder uuid.UUID
+		rangeID        roachpb.RangeID
+		key            string
+		source         serverpb.LockWaitGraphResponse_Source
+	}
+	seen := make(map[edgeKey]int, len(edges))
+	res := edges[:0]
+	for _, e := range edges {
+		k := edgeKey{e.WaiterTxnID, e.HolderTxnID, e.RangeID, string(e.Key), e.Source}
+		if i, ok := seen[k]; ok {
+			if e.WaitDuration > res[i].WaitDuration {
+				res[i] = e
+			}
+			continue
+		}
+		seen[k] = len(res)
+		res = append(res, e)
+	}
+	return res
+}
+
+// buildLockWaitGraph returns the transactions of the waits-for graph described
+// by edges, with their cycles and wait depths. The transactions are sorted by
+// decreasing wait depth.
+//
+// The cycles are the strongly connected components of the graph, found with
+// Tarjan's algorithm. The wait depth of a transaction is the length of the
+// longest path starting at it in the graph in which each cycle is condensed
+// into a single vertex; Tarjan's algorithm emits the components in reverse
+// topological order, so that it can be computed as they are found.
+func buildLockWaitGraph(
+	edges []serverpb.LockWaitGraphResponse_Edge, longChainLength int,
+) []serverpb.LockWaitGraphResponse_Txn {
+	// Sort the vertices and their successors so that the cycle IDs are
+	// deterministic.
+	succs := make(map[uuid.UUID][]uuid.UUID)
+	for _, e := range edges {
+		if e.WaiterTxnID == e.HolderTxnID {
+			continue
+		}
+		if _, ok := succs[e.HolderTxnID]; !ok {
+			succs[e.HolderTxnID] = nil
+		}
+		succs[e.WaiterTxnID] = append(succs[e.WaiterTxnID], e.HolderTxnID)
+	}
+	vertices := make([]uuid.UUID, 0, len(succs))
+	for v := range succs {
+		vertices = append(vertices, v)
+	}
+	sortUUIDs(vertices)
+	for _, v := range vertices {
+		succs[v] = sortAndDedupUUIDs(succs[v])
+	}
+
+	type vertexState struct {
+		index, lowLink int
+		onStack        bool
+		component      int
+	}
+	states := make(map[uuid.UUID]*vertexState, len(vertices))
+	var stack []uuid.UUID
+	// componentDepths and componentCycleIDs are indexed by component.
+	var componentDepths, componentCycleIDs []int
+	numCycles := 0
+
+	var strongConnect func(v uuid.UUID)
+	strongConnect = func(v uuid.UUID) {
+		vs := &vertexState{index: len(states), lowLink: len(states), onStack: true}
+		states[v] = vs
+		stack = append(stack, v)
+		for _, w := range succs[v] {
+			ws, ok := states[w]
+			if !ok {
+				strongConnect(w)
+				ws = states[w]
+				if ws.lowLink < vs.lowLink {
+					vs.lowLink = ws.lowLink
+				}
+			} else if ws.onStack && ws.index < vs.lowLink {
+				vs.lowLink = ws.index
+			}
+		}
+		if vs.lowLink != vs.index {
+			return
+		}
+		// v is the root of a component, made of the vertices above it on the
+		// stack.
+		component := len(componentDepths)
+		var members []uuid.UUID
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			states[w].onStack = false
+			states[w].component = component
+			members = append(members, w)
+			if w == v {
+				break
+			}
+		}
+		depth := 0
+		for _, m := range members {
+			for _, w := range succs[m] {
+				if c := states[w].component; c != component && componentDepths[c]+1 > depth {
+					depth = componentDepths[c] + 1
+				}
+			}
+		}
+		cycleID := 0
+		if len(members) > 1 {
+			numCycles++
+			cycleID = numCycles
+		}
+		componentDepths = append(componentDepths, depth)
+		componentCycleIDs = append(componentCycleIDs, cycleID)
+	}
+	for _, v := range vertices {
+		if _, ok := states[v]; !ok {
+			strongConnect(v)
+		}
+	}
+
+	txns := make([]serverpb.LockWaitGraphResponse_Txn, 0, len(vertices))
+	for _, v := range vertices {
+		if v == (uuid.UUID{}) {
+			// The non-transactional waiters.
+			continue
+		}
+		c := states[v].component
+		txns = append(txns, serverpb.LockWaitGraphResponse_Txn{
+			TxnID:     v,
+			CycleID:   int32(componentCycleIDs[c]),
+			WaitDepth: int32(componentDepths[c]),
+			LongChain: componentDepths[c] >= longChainLength,
+		})
+	}
+	sort.SliceStable(txns, func(i, j int) bool {
+		return txns[i].WaitDepth > txns[j].WaitDepth
+	})
+	return txns
+}
+
+func sortUUIDs(ids []uuid.UUID) {
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i].GetBytes(), ids[j].GetBytes()) < 0
+	})
+}
+
+// sortAndDedupUUIDs sorts ids and removes duplicates in place.
+func sortAndDedupUUIDs(ids []uuid.UUID) []uuid.UUID {
+	sortUUIDs(ids)
+	res := ids[:0]
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		res = append(res, id)
+	}
+	return res
+}
+
+// annotateLockWaitGraph sets the session, application and statement of the
+// transactions run by the given sessions.
+func annotateLockWaitGraph(
+	txns []serverpb.LockWaitGraphResponse_Txn, sessions []serverpb.Session,
+) {
+	byTxnID := make(map[uuid.UUID]*serverpb.LockWaitGraphResponse_Txn, len(txns))
+	for i := range txns {
+		byTxnID[txns[i].TxnID] = &txns[i]
+	}
+	for i := range sessions {
+		session := &sessions[i]
+		if session.ActiveTxn == nil {
+			continue
+		}
+		txn, ok := byTxnID[session.ActiveTxn.ID]
+		if !ok {
+			continue
+		}
+		txn.NodeID = session.NodeID
+		txn.SessionID = session.ID
+		txn.AppName = session.ApplicationName
+		for _, q := range session.ActiveQueries {
+			if q.TxnID == txn.TxnID {
+				txn.Statement = q.Sql
+				txn.StatementNoConstants = q.SqlNoConstants
+				break
+			}
+		}
+	}
+}
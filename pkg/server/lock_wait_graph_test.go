@@ -0,0 +1,167 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLockWaitGraph(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	txns := make(map[string]uuid.UUID)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		txns[name] = uuid.MakeV4()
+	}
+	edge := func(waiter, holder string, source serverpb.LockWaitGraphResponse_Source) serverpb.LockWaitGraphResponse_Edge {
+		// An empty waiter stands for a non-transactional request.
+		return serverpb.LockWaitGraphResponse_Edge{
+			WaiterTxnID: txns[waiter], HolderTxnID: txns[holder], Source: source,
+		}
+	}
+	const lockTable = serverpb.LockWaitGraphResponse_LOCK_TABLE
+	const txnWaitQueue = serverpb.LockWaitGraphResponse_TXN_WAIT_QUEUE
+	edges := []serverpb.LockWaitGraphResponse_Edge{
+		// A cycle of three transactions, and a transaction waiting on it.
+		edge("a", "b", lockTable),
+		edge("b", "c", lockTable),
+		edge("c", "a", txnWaitQueue),
+		edge("d", "a", lockTable),
+		// A chain of four transactions. The first wait is observed both in the
+		// lock table and in the txn wait queue.
+		edge("e", "f", lockTable),
+		edge("e", "f", txnWaitQueue),
+		edge("f", "g", lockTable),
+		edge("g", "h", lockTable),
+		// A non-transactional request waiting at the end of the chain.
+		edge("", "h", lockTable),
+	}
+
+	res := buildLockWaitGraph(edges, 3 /* longChainLength */)
+	byName := make(map[string]serverpb.LockWaitGraphResponse_Txn)
+	for _, txn := range res {
+		for name, id := range txns {
+			if txn.TxnID == id {
+				byName[name] = txn
+			}
+		}
+	}
+	require.Len(t, res, len(txns))
+	require.Len(t, byName, len(txns))
+
+	// The transactions are sorted by decreasing wait depth.
+	for i := 1; i < len(res); i++ {
+		require.GreaterOrEqual(t, res[i-1].WaitDepth, res[i].WaitDepth)
+	}
+
+	cycleID := byName["a"].CycleID
+	require.NotZero(t, cycleID)
+	for name, exp := range map[string]struct {
+		inCycle   bool
+		waitDepth int32
+		longChain bool
+	}{
+		"a": {inCycle: true},
+		"b": {inCycle: true},
+		"c": {inCycle: true},
+		"d": {waitDepth: 1},
+		"e": {waitDepth: 3, longChain: true},
+		"f": {waitDepth: 2},
+		"g": {waitDepth: 1},
+		"h": {},
+	} {
+		txn := byName[name]
+		if exp.inCycle {
+			require.Equal(t, cycleID, txn.CycleID, name)
+		} else {
+			require.Zero(t, txn.CycleID, name)
+		}
+		require.Equal(t, exp.waitDepth, txn.WaitDepth, name)
+		require.Equal(t, exp.longChain, txn.LongChain, name)
+	}
+
+	// No transaction is waiting.
+	require.Empty(t, buildLockWaitGraph(nil, 3 /* longChainLength */))
+}
+
+func TestDedupLockWaitEdges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	waiter, holder := uuid.MakeV4(), uuid.MakeV4()
+	edge := func(key string, d time.Duration, nodeID roachpb.NodeID) serverpb.LockWaitGraphResponse_Edge {
+		return serverpb.LockWaitGraphResponse_Edge{
+			WaiterTxnID:  waiter,
+			HolderTxnID:  holder,
+			RangeID:      1,
+			Key:          roachpb.Key(key),
+			WaitDuration: d,
+			NodeID:       nodeID,
+		}
+	}
+	res := dedupLockWaitEdges([]serverpb.LockWaitGraphResponse_Edge{
+		edge("a", time.Second, 1),
+		edge("b", time.Second, 1),
+		// The wait on key a reported by the new leaseholder.
+		edge("a", 2*time.Second, 2),
+	})
+	require.Equal(t, []serverpb.LockWaitGraphResponse_Edge{
+		edge("a", 2*time.Second, 2),
+		edge("b", time.Second, 1),
+	}, res)
+}
+
+func TestAnnotateLockWaitGraph(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	txnID, otherTxnID := uuid.MakeV4(), uuid.MakeV4()
+	txns := []serverpb.LockWaitGraphResponse_Txn{{TxnID: txnID}, {TxnID: otherTxnID}}
+	sessions := []serverpb.Session{
+		{
+			NodeID:          2,
+			ID:              []byte("session"),
+			ApplicationName: "app",
+			ActiveTxn:       &serverpb.TxnInfo{ID: txnID},
+			ActiveQueries: []serverpb.ActiveQuery{
+				{
+					TxnID:          txnID,
+					Sql:            "UPDATE t SET v = v + 1 WHERE k = 1",
+					SqlNoConstants: "UPDATE t SET v = v + _ WHERE k = _",
+				},
+			},
+		},
+		// An idle session.
+		{NodeID: 1, ID: []byte("idle"), ApplicationName: "idle"},
+	}
+	annotateLockWaitGraph(txns, sessions)
+	require.Equal(t, []serverpb.LockWaitGraphResponse_Txn{
+		{
+			TxnID:                txnID,
+			NodeID:               2,
+			SessionID:            []byte("session"),
+			AppName:              "app",
+			Statement:            "UPDATE t SET v = v + 1 WHERE k = 1",
+			StatementNoConstants: "UPDATE t SET v = v + _ WHERE k = _",
+		},
+		// The session of the transaction is not known.
+		{TxnID: otherTxnID},
+	}, txns)
+}
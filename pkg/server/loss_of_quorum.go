@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery/loqrecoverypb"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -88,3 +89,68 @@ func publishPendingLossOfQuorumRecoveryEvents(
 		}
 	})
 }
+
+// RecoveryCollectReplicaInfo implements the serverpb.AdminServer interface.
+func (s *adminServer) RecoveryCollectReplicaInfo(
+	ctx context.Context, req *serverpb.RecoveryCollectReplicaInfoRequest,
+) (*serverpb.RecoveryCollectReplicaInfoResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		// NB: not using serverError() here since the priv checker
+		// already returns a proper gRPC error status.
+		return nil, err
+	}
+	return s.server.recoveryServer.ServeClusterReplicas(ctx, req)
+}
+
+// RecoveryCollectLocalReplicaInfo implements the serverpb.AdminServer interface.
+func (s *adminServer) RecoveryCollectLocalReplicaInfo(
+	ctx context.Context, req *serverpb.RecoveryCollectLocalReplicaInfoRequest,
+) (*serverpb.RecoveryCollectLocalReplicaInfoResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		// NB: not using serverError() here since the priv checker
+		// already returns a proper gRPC error status.
+		return nil, err
+	}
+	return s.server.recoveryServer.ServeLocalReplicas(ctx, req)
+}
+
+// RecoveryStagePlan implements the serverpb.AdminServer interface.
+func (s *adminServer) RecoveryStagePlan(
+	ctx context.Context, req *serverpb.RecoveryStagePlanRequest,
+) (*serverpb.RecoveryStagePlanResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		// NB: not using serverError() here since the priv checker
+		// already returns a proper gRPC error status.
+		return nil, err
+	}
+	return s.server.recoveryServer.StagePlan(ctx, req)
+}
+
+// RecoveryNodeStatus implements the serverpb.AdminServer interface.
+func (s *adminServer) RecoveryNodeStatus(
+	ctx context.Context, req *serverpb.RecoveryNodeStatusRequest,
+) (*serverpb.RecoveryNodeStatusResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		// NB: not using serverError() here since the priv checker
+		// already returns a proper gRPC error status.
+		return nil, err
+	}
+	return s.server.recoveryServer.NodeStatus(ctx, req)
+}
+
+// RecoveryVerify implements the serverpb.AdminServer interface.
+func (s *adminServer) RecoveryVerify(
+	ctx context.Context, req *serverpb.RecoveryVerifyRequest,
+) (*serverpb.RecoveryVerifyResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		// NB: not using serverError() here since the priv checker
+		// already returns a proper gRPC error status.
+		return nil, err
+	}
+	return s.server.recoveryServer.Verify(ctx, req)
+}
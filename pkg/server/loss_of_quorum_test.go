@@ -0,0 +1,80 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TestLossOfQuorumRecoveryRequiresAdmin verifies that the loss of quorum
+// recovery endpoints reject users without the admin role.
+func TestLossOfQuorumRecoveryRequiresAdmin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	ts := s.(*TestServer)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, "CREATE USER nonadmin")
+
+	for _, tc := range []struct {
+		name string
+		call func(context.Context) error
+	}{
+		{"RecoveryCollectReplicaInfo", func(ctx context.Context) error {
+			_, err := ts.admin.RecoveryCollectReplicaInfo(ctx, &serverpb.RecoveryCollectReplicaInfoRequest{})
+			return err
+		}},
+		{"RecoveryCollectLocalReplicaInfo", func(ctx context.Context) error {
+			_, err := ts.admin.RecoveryCollectLocalReplicaInfo(ctx, &serverpb.RecoveryCollectLocalReplicaInfoRequest{})
+			return err
+		}},
+		{"RecoveryStagePlan", func(ctx context.Context) error {
+			_, err := ts.admin.RecoveryStagePlan(ctx, &serverpb.RecoveryStagePlanRequest{})
+			return err
+		}},
+		{"RecoveryNodeStatus", func(ctx context.Context) error {
+			_, err := ts.admin.RecoveryNodeStatus(ctx, &serverpb.RecoveryNodeStatusRequest{})
+			return err
+		}},
+		{"RecoveryVerify", func(ctx context.Context) error {
+			_, err := ts.admin.RecoveryVerify(ctx, &serverpb.RecoveryVerifyRequest{})
+			return err
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(ctx, metadata.New(map[string]string{"websessionuser": "nonadmin"}))
+			err := tc.call(ctx)
+			require.Error(t, err)
+			require.Equal(t, codes.PermissionDenied, status.Code(err))
+		})
+	}
+
+	// Admin users can use the endpoints.
+	adminCtx := metadata.NewIncomingContext(ctx, metadata.New(map[string]string{"websessionuser": "root"}))
+	_, err := ts.admin.RecoveryNodeStatus(adminCtx, &serverpb.RecoveryNodeStatusRequest{})
+	require.NoError(t, err)
+}
@@ -15,6 +15,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"runtime/pprof"
 	"sort"
 	"strings"
 	"time"
@@ -45,6 +46,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/buildutil"
+	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -71,6 +73,9 @@ const (
 
 	graphiteIntervalKey = "external.graphite.interval"
 	maxGraphiteInterval = 15 * time.Minute
+
+	otlpMetricsIntervalKey = "external.otlp.metrics.interval"
+	maxOTLPMetricsInterval = 15 * time.Minute
 )
 
 // Metric names.
@@ -151,6 +156,23 @@ var (
 		10*time.Second,
 		settings.NonNegativeDurationWithMaximum(maxGraphiteInterval),
 	).WithPublic()
+	// otlpMetricsEndpoint is host:port, if any, of an OpenTelemetry collector
+	// to which metrics are pushed.
+	otlpMetricsEndpoint = settings.RegisterStringSetting(
+		settings.TenantWritable,
+		"external.otlp.metrics.endpoint",
+		"if nonempty, push server metrics to the OpenTelemetry collector at the specified host:port over gRPC",
+		"",
+	).WithPublic()
+	// otlpMetricsInterval is how often metrics are pushed to the OpenTelemetry
+	// collector, if enabled.
+	otlpMetricsInterval = settings.RegisterDurationSetting(
+		settings.TenantWritable,
+		otlpMetricsIntervalKey,
+		"the interval at which metrics are pushed to the OpenTelemetry collector (if enabled)",
+		10*time.Second,
+		settings.NonNegativeDurationWithMaximum(maxOTLPMetricsInterval),
+	).WithPublic()
 )
 
 type nodeMetrics struct {
@@ -830,6 +852,46 @@ func (n *Node) startGraphiteStatsExporter(st *cluster.Settings) {
 	})
 }
 
+// startOTLPMetricsExporter begins periodically pushing the node's metrics to
+// the OpenTelemetry collector configured in external.otlp.metrics.endpoint.
+// Each push is retried until the next one is due; a push that still hasn't
+// succeeded by then is abandoned rather than queued, so a slow or unavailable
+// collector can't cause metrics to pile up in memory.
+func (n *Node) startOTLPMetricsExporter(st *cluster.Settings) {
+	ctx := logtags.AddTag(n.AnnotateCtx(context.Background()), "otlp metrics exporter", nil)
+	oe := metric.MakeOTLPExporter()
+
+	_ = n.stopper.RunAsyncTask(ctx, "otlp-metrics-exporter", func(ctx context.Context) {
+		defer func() {
+			if err := oe.Close(); err != nil {
+				log.Infof(ctx, "error closing connection to OpenTelemetry collector: %s", err)
+			}
+		}()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			interval := otlpMetricsInterval.Get(&st.SV)
+			timer.Reset(interval)
+			select {
+			case <-n.stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+				endpoint := otlpMetricsEndpoint.Get(&st.SV)
+				if endpoint == "" {
+					continue
+				}
+				if err := contextutil.RunWithTimeout(ctx, "otlp-metrics-push", interval,
+					func(ctx context.Context) error {
+						return n.recorder.ExportToOTLP(ctx, endpoint, &oe)
+					}); err != nil {
+					log.Infof(ctx, "error pushing metrics to OpenTelemetry collector: %s", err)
+				}
+			}
+		}
+	})
+}
+
 // startWriteNodeStatus begins periodically persisting status summaries for the
 // node and its stores.
 func (n *Node) startWriteNodeStatus(frequency time.Duration) error {
@@ -1004,7 +1066,15 @@ func (n *Node) batchInternal(
 		return nil, err
 	}
 	var pErr *roachpb.Error
-	br, pErr = n.stores.Send(ctx, *args)
+	if n.storeCfg.Settings.CPUProfileType() == cluster.CPUProfileWithLabels {
+		// Attribute the CPU spent evaluating the batch to the tenant which
+		// issued it.
+		pprof.Do(ctx, pprof.Labels("tenant", tenID.String()), func(ctx context.Context) {
+			br, pErr = n.stores.Send(ctx, *args)
+		})
+	} else {
+		br, pErr = n.stores.Send(ctx, *args)
+	}
 	if pErr != nil {
 		br = &roachpb.BatchResponse{}
 		log.VErrEventf(ctx, 3, "error from stores.Send: %s", pErr)
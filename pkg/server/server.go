@@ -37,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts/sidetransport"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptprovider"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptreconcile"
@@ -71,6 +72,7 @@ import (
 	_ "github.com/cockroachdb/cockroach/pkg/sql/ttl/ttlschedule"     // register schedules declared outside of pkg/sql
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/ts"
+	"github.com/cockroachdb/cockroach/pkg/ts/promql"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/goschedstats"
@@ -101,6 +103,9 @@ type Server struct {
 	clock           *hlc.Clock
 	rpcContext      *rpc.Context
 	engines         Engines
+	// recoveryPlanStore holds the loss of quorum recovery plan staged on the
+	// node, which is applied to the engines on startup.
+	recoveryPlanStore loqrecovery.PlanStore
 	// The gRPC server on which the different RPC handlers will be registered.
 	grpc             *grpcServer
 	gossip           *gossip.Gossip
@@ -127,6 +132,7 @@ type Server struct {
 	decomNodeMap    *decommissioningNodeMap
 	authentication  *authenticationServer
 	migrationServer *migrationServer
+	recoveryServer  *loqrecovery.Server
 	tsDB            *ts.DB
 	tsServer        *ts.Server
 	raftTransport   *kvserver.RaftTransport
@@ -615,6 +621,10 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		protectedTSReader = spanconfigptsreader.NewAdapter(protectedtsProvider.(*ptprovider.Provider).Cache, spanConfig.subscriber)
 	}
 
+	tenantSettingsWatcher := tenantsettingswatcher.New(
+		clock, rangeFeedFactory, stopper, st,
+	)
+
 	storeCfg := kvserver.StoreConfig{
 		DefaultSpanConfig:        cfg.DefaultZoneConfig.AsSpanConfig(),
 		Settings:                 st,
@@ -646,6 +656,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		SystemConfigProvider:     systemConfigWatcher,
 		SpanConfigSubscriber:     spanConfig.subscriber,
 		SpanConfigsDisabled:      cfg.SpanConfigsDisabled,
+		TenantSettingOverrides:   tenantSettingsWatcher,
 	}
 
 	if storeTestingKnobs := cfg.TestingKnobs.Store; storeTestingKnobs != nil {
@@ -673,10 +684,6 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	tenantUsage := NewTenantUsageServer(st, db, internalExecutor)
 	registry.AddMetricStruct(tenantUsage.Metrics())
 
-	tenantSettingsWatcher := tenantsettingswatcher.New(
-		clock, rangeFeedFactory, stopper, st,
-	)
-
 	node := NewNode(
 		storeCfg,
 		recorder,
@@ -819,6 +826,9 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	drain := newDrainServer(cfg.BaseConfig, stopper, grpcServer, sqlServer)
 	drain.setNode(node, nodeLiveness)
 
+	recoveryPlanStore := loqrecovery.NewPlanStoreForEngine(engines[0])
+	recoveryServer := loqrecovery.NewServer(nodeIDContainer, engines, recoveryPlanStore, db, g, rpcContext)
+
 	*lateBoundServer = Server{
 		nodeIDContainer:        nodeIDContainer,
 		cfg:                    cfg,
@@ -826,6 +836,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		clock:                  clock,
 		rpcContext:             rpcContext,
 		engines:                engines,
+		recoveryPlanStore:      recoveryPlanStore,
 		grpc:                   grpcServer,
 		gossip:                 g,
 		nodeDialer:             nodeDialer,
@@ -847,6 +858,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		admin:                  sAdmin,
 		status:                 sStatus,
 		drain:                  drain,
+		recoveryServer:         recoveryServer,
 		decomNodeMap:           decomNodeMap,
 		authentication:         sAuth,
 		tsDB:                   tsDB,
@@ -1022,6 +1034,13 @@ func (s *Server) PreStart(ctx context.Context) error {
 	// Filter out self from the gossip bootstrap addresses.
 	filtered := s.cfg.FilterGossipBootstrapAddresses(ctx)
 
+	// Apply the loss of quorum recovery plan staged on this node before it was
+	// restarted, if any. This must happen before the engines are inspected
+	// and the stores are started, as the plan rewrites replicas in place.
+	if err := loqrecovery.ApplyStagedPlan(ctx, s.recoveryPlanStore, s.engines); err != nil {
+		return err
+	}
+
 	// Set up the init server. We have to do this relatively early because we
 	// can't call RegisterInitServer() after `grpc.Serve`, which is called in
 	// startRPCServer (and for the loopback grpc-gw connection).
@@ -1411,6 +1430,15 @@ func (s *Server) PreStart(ctx context.Context) error {
 		}
 	})
 
+	var otlpMetricsOnce sync.Once
+	otlpMetricsEndpoint.SetOnChange(&s.st.SV, func(context.Context) {
+		if otlpMetricsEndpoint.Get(&s.st.SV) != "" {
+			otlpMetricsOnce.Do(func() {
+				s.node.startOTLPMetricsExporter(s.st)
+			})
+		}
+	})
+
 	// Start the protected timestamp subsystem. Note that this needs to happen
 	// before the modeOperational switch below, as the protected timestamps
 	// subsystem will crash if accessed before being Started (and serving general
@@ -1495,9 +1523,14 @@ func (s *Server) PreStart(ctx context.Context) error {
 	// something associated to SQL tenants.
 	s.startSystemLogsGC(ctx)
 
+	// Start recording the hottest and largest ranges of the local stores in
+	// system.hot_ranges_history.
+	s.startRecordingHotRangesHistory(ctx)
+
 	// Connect the HTTP endpoints. This also wraps the privileged HTTP
 	// endpoints served by gwMux by the HTTP cookie authentication
 	// check.
+	tsPromHandler := promql.NewHandler(s.tsServer, s.recorder.GetTimeSeriesNames)
 	if err := s.http.setupRoutes(ctx,
 		s.authentication,       /* authnServer */
 		s.adminAuthzCheck,      /* adminAuthzCheck */
@@ -1506,6 +1539,7 @@ func (s *Server) PreStart(ctx context.Context) error {
 		gwMux,                  /* handleRequestsUnauthenticated */
 		s.debug,                /* handleDebugUnauthenticated */
 		newAPIV2Server(ctx, s), /* apiServer */
+		tsPromHandler,          /* tsPromHandler */
 	); err != nil {
 		return err
 	}
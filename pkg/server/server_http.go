@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/status"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/ts"
+	"github.com/cockroachdb/cockroach/pkg/ts/promql"
 	"github.com/cockroachdb/cockroach/pkg/ui"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -92,6 +93,7 @@ func (s *httpServer) setupRoutes(
 	handleRequestsUnauthenticated http.Handler,
 	handleDebugUnauthenticated http.Handler,
 	apiServer *apiV2Server,
+	tsPromHandler http.Handler,
 ) error {
 	// OIDC Configuration must happen prior to the UI Handler being defined below so that we have
 	// the system settings initialized for it to pick up from the oidcAuthenticationServer.
@@ -148,6 +150,13 @@ func (s *httpServer) setupRoutes(
 
 	// The timeseries endpoint, used to produce graphs.
 	s.mux.Handle(ts.URLPrefix, authenticatedHandler)
+	if tsPromHandler != nil {
+		// The Prometheus-compatible query API over the timeseries database.
+		if s.cfg.RequireWebSession() {
+			tsPromHandler = newAuthenticationMux(authnServer, tsPromHandler)
+		}
+		s.mux.Handle(promql.URLPrefix, tsPromHandler)
+	}
 
 	// Exempt the 2nd health check endpoint from authentication.
 	// (This simply mirrors /health and exists for backward compatibility.)
@@ -376,6 +376,13 @@ func newRootSQLMemoryMonitor(opts monitorAndMetricsOptions) monitorAndMetrics {
 	}
 }
 
+// NewHistoricalReadSource is a hook for CCL code which implements a source of
+// historical KV data, e.g. mounted backups, that serves reads below the GC
+// threshold. It returns nil if no such source is available.
+var NewHistoricalReadSource = func(execCfg *sql.ExecutorConfig) kvcoord.HistoricalReadSource {
+	return nil
+}
+
 func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 	// NB: ValidateAddrs also fills in defaults.
 	if err := cfg.Config.ValidateAddrs(ctx); err != nil {
@@ -956,6 +963,10 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 	)
 	execCfg.StmtDiagnosticsRecorder = stmtDiagnosticsRegistry
 
+	if src := NewHistoricalReadSource(execCfg); src != nil {
+		cfg.distSender.SetHistoricalReadSource(src)
+	}
+
 	{
 		// We only need to attach a version upgrade hook if we're the system
 		// tenant. Regular tenants are disallowed from changing cluster
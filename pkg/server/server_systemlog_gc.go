@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -149,10 +150,13 @@ type systemLogGCConfig struct {
 	// It is maintained to avoid hitting tombstones during gc and is updated
 	// after every gc run.
 	timestampLowerBound time.Time
+	// minVersion, if set, is the cluster version that introduced the table.
+	// The table is not gc'ed until this version is active.
+	minVersion clusterversion.Key
 }
 
-// startSystemLogsGC starts a worker which periodically GCs system.rangelog
-// and system.eventlog.
+// startSystemLogsGC starts a worker which periodically GCs system.rangelog,
+// system.eventlog and system.hot_ranges_history.
 // The TTLs for each of these logs is retrieved from cluster settings.
 func (s *Server) startSystemLogsGC(ctx context.Context) {
 	systemLogsToGC := map[string]*systemLogGCConfig{
@@ -164,6 +168,11 @@ func (s *Server) startSystemLogsGC(ctx context.Context) {
 			ttl:                 eventLogTTL,
 			timestampLowerBound: timeutil.Unix(0, 0),
 		},
+		"hot_ranges_history": {
+			ttl:                 hotRangesHistoryTTL,
+			timestampLowerBound: timeutil.Unix(0, 0),
+			minVersion:          clusterversion.HotRangesHistoryTable,
+		},
 	}
 
 	_ = s.stopper.RunAsyncTask(ctx, "system-log-gc", func(ctx context.Context) {
@@ -179,6 +188,9 @@ func (s *Server) startSystemLogsGC(ctx context.Context) {
 			select {
 			case <-t.C:
 				for table, gcConfig := range systemLogsToGC {
+					if gcConfig.minVersion != 0 && !s.cfg.Settings.Version.IsActive(ctx, gcConfig.minVersion) {
+						continue
+					}
 					ttl := gcConfig.ttl.Get(&s.cfg.Settings.SV)
 					if ttl > 0 {
 						timestampUpperBound := timeutil.Unix(0, s.clock.PhysicalNow()-int64(ttl))
@@ -61,10 +61,11 @@ func MakeOptionalNodesStatusServer(s NodesStatusServer) OptionalNodesStatusServe
 }
 
 // NodesStatusServer is an endpoint that allows the SQL subsystem
-// to observe node descriptors.
+// to observe node descriptors and the state of the KV layer of the nodes.
 // It is unavailable to tenants.
 type NodesStatusServer interface {
 	ListNodesInternal(context.Context, *NodesRequest) (*NodesResponse, error)
+	LockWaitGraph(context.Context, *LockWaitGraphRequest) (*LockWaitGraphResponse, error)
 }
 
 // RegionsServer is the subset of the serverpb.StatusInterface that is used
@@ -2325,27 +2325,12 @@ type hotRangeReportMeta struct {
 	schemaParentID uint32
 }
 
-// HotRangesV2 returns hot ranges from all stores on requested node or all nodes in case
-// request message doesn't include specific node ID.
-func (s *statusServer) HotRangesV2(
-	ctx context.Context, req *serverpb.HotRangesRequest,
-) (*serverpb.HotRangesResponseV2, error) {
-	if err := s.privilegeChecker.requireViewActivityOrViewActivityRedactedPermission(ctx); err != nil {
-		return nil, err
-	}
-
-	size := int(req.PageSize)
-	start := paginationState{}
-
-	if len(req.PageToken) > 0 {
-		if err := start.UnmarshalText([]byte(req.PageToken)); err != nil {
-			return nil, err
-		}
-	}
-
-	rangeReportMetas := make(map[uint32]hotRangeReportMeta)
+// hotRangeReportMetas returns the metadata needed to name the database,
+// schema, table and index of hot ranges, keyed by descriptor ID.
+func (s *statusServer) hotRangeReportMetas(
+	ctx context.Context,
+) (map[uint32]hotRangeReportMeta, error) {
 	var descrs []catalog.Descriptor
-	var err error
 	if err := s.sqlServer.distSQLServer.CollectionFactory.Txn(
 		ctx, s.sqlServer.internalExecutor, s.db,
 		func(ctx context.Context, txn *kv.Txn, descriptors *descs.Collection) error {
@@ -2359,6 +2344,7 @@ func (s *statusServer) HotRangesV2(
 		return nil, err
 	}
 
+	rangeReportMetas := make(map[uint32]hotRangeReportMeta)
 	for _, desc := range descrs {
 		id := uint32(desc.GetID())
 		meta := hotRangeReportMeta{
@@ -2379,6 +2365,66 @@ func (s *statusServer) HotRangesV2(
 		}
 		rangeReportMetas[id] = meta
 	}
+	return rangeReportMetas, nil
+}
+
+// hotRangeNames holds the names of the objects a hot range belongs to.
+type hotRangeNames struct {
+	dbName     string
+	schemaName string
+	tableName  string
+	indexName  string
+}
+
+// lookupHotRangeNames returns the names of the objects containing the range
+// that starts at startKey. An error is returned if startKey is not within a
+// table.
+func (s *statusServer) lookupHotRangeNames(
+	rangeReportMetas map[uint32]hotRangeReportMeta, startKey roachpb.RKey,
+) (hotRangeNames, error) {
+	var names hotRangeNames
+	_, tableID, err := s.sqlServer.execCfg.Codec.DecodeTablePrefix(startKey.AsRawKey())
+	if err != nil {
+		return names, err
+	}
+	parent := rangeReportMetas[tableID].parentID
+	if parent != 0 {
+		names.tableName = rangeReportMetas[tableID].tableName
+		names.dbName = rangeReportMetas[parent].dbName
+	} else {
+		names.dbName = rangeReportMetas[tableID].dbName
+	}
+	schemaParent := rangeReportMetas[tableID].schemaParentID
+	names.schemaName = rangeReportMetas[schemaParent].schemaName
+	_, _, idxID, err := s.sqlServer.execCfg.Codec.DecodeIndexPrefix(startKey.AsRawKey())
+	if err == nil {
+		names.indexName = rangeReportMetas[tableID].indexNames[idxID]
+	}
+	return names, nil
+}
+
+// HotRangesV2 returns hot ranges from all stores on requested node or all nodes in case
+// request message doesn't include specific node ID.
+func (s *statusServer) HotRangesV2(
+	ctx context.Context, req *serverpb.HotRangesRequest,
+) (*serverpb.HotRangesResponseV2, error) {
+	if err := s.privilegeChecker.requireViewActivityOrViewActivityRedactedPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	size := int(req.PageSize)
+	start := paginationState{}
+
+	if len(req.PageToken) > 0 {
+		if err := start.UnmarshalText([]byte(req.PageToken)); err != nil {
+			return nil, err
+		}
+	}
+
+	rangeReportMetas, err := s.hotRangeReportMetas(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	response := &serverpb.HotRangesResponseV2{
 		ErrorsByNodeID: make(map[roachpb.NodeID]string),
@@ -2408,28 +2454,12 @@ func (s *statusServer) HotRangesV2(
 		for nodeID, hr := range resp.HotRangesByNodeID {
 			for _, store := range hr.Stores {
 				for _, r := range store.HotRanges {
-					var (
-						dbName, tableName, indexName, schemaName string
-						replicaNodeIDs                           []roachpb.NodeID
-					)
-					_, tableID, err := s.sqlServer.execCfg.Codec.DecodeTablePrefix(r.Desc.StartKey.AsRawKey())
+					var replicaNodeIDs []roachpb.NodeID
+					names, err := s.lookupHotRangeNames(rangeReportMetas, r.Desc.StartKey)
 					if err != nil {
 						log.Warningf(ctx, "cannot decode tableID for range descriptor: %s. %s", r.Desc.String(), err.Error())
 						continue
 					}
-					parent := rangeReportMetas[tableID].parentID
-					if parent != 0 {
-						tableName = rangeReportMetas[tableID].tableName
-						dbName = rangeReportMetas[parent].dbName
-					} else {
-						dbName = rangeReportMetas[tableID].dbName
-					}
-					schemaParent := rangeReportMetas[tableID].schemaParentID
-					schemaName = rangeReportMetas[schemaParent].schemaName
-					_, _, idxID, err := s.sqlServer.execCfg.Codec.DecodeIndexPrefix(r.Desc.StartKey.AsRawKey())
-					if err == nil {
-						indexName = rangeReportMetas[tableID].indexNames[idxID]
-					}
 					for _, repl := range r.Desc.Replicas().Descriptors() {
 						replicaNodeIDs = append(replicaNodeIDs, repl.NodeID)
 					}
@@ -2437,10 +2467,10 @@ func (s *statusServer) HotRangesV2(
 						RangeID:           r.Desc.RangeID,
 						NodeID:            nodeID,
 						QPS:               r.QueriesPerSecond,
-						TableName:         tableName,
-						SchemaName:        schemaName,
-						DatabaseName:      dbName,
-						IndexName:         indexName,
+						TableName:         names.tableName,
+						SchemaName:        names.schemaName,
+						DatabaseName:      names.dbName,
+						IndexName:         names.indexName,
 						ReplicaNodeIds:    replicaNodeIDs,
 						LeaseholderNodeID: r.LeaseholderNodeID,
 						StoreID:           store.StoreID,
@@ -42,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/system"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
 	"github.com/codahale/hdrhistogram"
@@ -277,6 +278,38 @@ func (mr *MetricsRecorder) ExportToGraphite(
 	return graphiteExporter.Push(ctx, endpoint)
 }
 
+// ExportToOTLP sends the current metric values of the node and its stores to
+// an OpenTelemetry collector. The metrics are attributed to the node and its
+// tenant, and store-level metrics additionally to their store.
+func (mr *MetricsRecorder) ExportToOTLP(
+	ctx context.Context, endpoint string, oe *metric.OTLPExporter,
+) error {
+	mr.mu.RLock()
+	if mr.mu.nodeRegistry == nil {
+		mr.mu.RUnlock()
+		// We haven't yet processed initialization information; do nothing.
+		if log.V(1) {
+			log.Warning(ctx, "MetricsRecorder.ExportToOTLP() called before NodeID allocation")
+		}
+		return nil
+	}
+	includeChildMetrics := childMetricsEnabled.Get(&mr.settings.SV)
+	oe.ScrapeRegistry(mr.mu.nodeRegistry, includeChildMetrics)
+	for _, reg := range mr.mu.storeRegistries {
+		oe.ScrapeRegistry(reg, includeChildMetrics)
+	}
+	resource := map[string]string{
+		"service.name": "cockroachdb",
+		"node_id":      strconv.FormatInt(int64(mr.mu.desc.NodeID), 10),
+		"tenant_id":    mr.rpcContext.TenantID.String(),
+	}
+	startedAt := timeutil.Unix(0, mr.mu.startedAt)
+	mr.mu.RUnlock()
+
+	// Don't hold the lock while talking to the collector.
+	return oe.Push(ctx, endpoint, resource, startedAt)
+}
+
 // GetTimeSeriesData serializes registered metrics for consumption by
 // CockroachDB's time series system.
 func (mr *MetricsRecorder) GetTimeSeriesData() []tspb.TimeSeriesData {
@@ -350,6 +383,31 @@ func (mr *MetricsRecorder) GetMetricsMetadata() map[string]metric.Metadata {
 	return metrics
 }
 
+// GetTimeSeriesNames returns the names of the time series recorded by
+// GetTimeSeriesData. Since all stores record the same metrics, the store-level
+// names are taken from an arbitrary store registry.
+func (mr *MetricsRecorder) GetTimeSeriesNames() []string {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	if mr.mu.nodeRegistry == nil {
+		// We haven't yet processed initialization information; do nothing.
+		return nil
+	}
+
+	var names []string
+	eachRecordableValue(mr.mu.nodeRegistry, func(name string, _ float64) {
+		names = append(names, fmt.Sprintf(nodeTimeSeriesPrefix, name))
+	})
+	for _, r := range mr.mu.storeRegistries {
+		eachRecordableValue(r, func(name string, _ float64) {
+			names = append(names, fmt.Sprintf(storeTimeSeriesPrefix, name))
+		})
+		break
+	}
+	return names
+}
+
 // getLatencies produces a map of network activity from this node to all other
 // nodes. Latencies are stored as nanos.
 func (mr *MetricsRecorder) getNetworkActivity(
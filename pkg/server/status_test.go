@@ -1001,6 +1001,45 @@ func TestHotRanges2ResponseWithViewActivityOptions(t *testing.T) {
 	}
 }
 
+func TestHotRangesHistoryResponse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	ts := startServer(t)
+	defer ts.Stopper().Stop(ctx)
+
+	// Refresh the replica rankings of the stores, which are otherwise only
+	// computed when the store capacity is gossiped, and record them.
+	require.NoError(t, ts.node.stores.VisitStores(func(s *kvserver.Store) error {
+		_, err := s.Capacity(ctx, false /* useCached */)
+		return err
+	}))
+	require.NoError(t, ts.recordHotRangesHistory(ctx))
+
+	var resp serverpb.HotRangesHistoryResponse
+	require.NoError(t, getStatusJSONProto(ts, "hotranges/history", &resp))
+	require.NotEmpty(t, resp.Ranges)
+	for _, r := range resp.Ranges {
+		require.NotZero(t, r.RangeID)
+		require.NotZero(t, r.StoreID)
+		require.Equal(t, ts.NodeID(), r.NodeID)
+		require.False(t, r.Timestamp.IsZero())
+	}
+	// The recorded ranges include the ranges of the system tables, which are
+	// named.
+	var foundTable bool
+	for _, r := range resp.Ranges {
+		if r.DatabaseName == "system" && r.TableName != "" {
+			foundTable = true
+			break
+		}
+	}
+	require.True(t, foundTable, "no range named after a system table in %v", resp.Ranges)
+
+	require.NoError(t, getStatusJSONProto(ts, "hotranges/history?limit=1", &resp))
+	require.Len(t, resp.Ranges, 1)
+}
+
 func TestRangesResponse(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
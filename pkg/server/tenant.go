@@ -281,6 +281,7 @@ func startTenantInternal(
 		gwMux,           /* handleRequestsUnauthenticated */
 		debugServer,     /* handleDebugUnauthenticated */
 		nil,             /* apiServer */
+		nil,             /* tsPromHandler */
 	); err != nil {
 		return nil, nil, nil, "", "", err
 	}
@@ -364,6 +364,9 @@ func (s *SQLTranslator) generateSpanConfigurationsForTable(
 	// backups.
 	tableSpanConfig.ExcludeDataFromBackup = table.GetExcludeDataFromBackup()
 
+	// Set whether the table is in read-only mode.
+	tableSpanConfig.ReadOnly = table.IsReadOnly()
+
 	records := make([]spanconfig.Record, 0)
 	if table.GetID() == keys.DescriptorTableID {
 		// We have some special handling for `system.descriptor` on account of
@@ -472,6 +475,7 @@ func (s *SQLTranslator) generateSpanConfigurationsForTable(
 		// SubzoneSpanConfig.
 		subzoneSpanConfig.GCPolicy.ProtectionPolicies = tableSpanConfig.GCPolicy.ProtectionPolicies[:]
 		subzoneSpanConfig.ExcludeDataFromBackup = tableSpanConfig.ExcludeDataFromBackup
+		subzoneSpanConfig.ReadOnly = tableSpanConfig.ReadOnly
 		if isSystemDesc { // same as above
 			subzoneSpanConfig.RangefeedEnabled = true
 			subzoneSpanConfig.GCPolicy.IgnoreStrictEnforcement = true
@@ -407,9 +407,22 @@ func PrintSpanConfigDiffedAgainstDefaults(conf roachpb.SpanConfig) string {
 		}
 		diffs = append(diffs, fmt.Sprintf("protection_policies=[%s]", strings.Join(protectionPolicies, " ")))
 	}
+	if !reflect.DeepEqual(conf.GCPolicy.RetentionTiers, defaultConf.GCPolicy.RetentionTiers) {
+		tiers := make([]string, 0, len(conf.GCPolicy.RetentionTiers))
+		for _, t := range conf.GCPolicy.RetentionTiers {
+			tiers = append(tiers, fmt.Sprintf("%ds:%ds", t.IntervalSeconds, t.TTLSeconds))
+		}
+		diffs = append(diffs, fmt.Sprintf("retention_tiers=[%s]", strings.Join(tiers, " ")))
+	}
 	if conf.ExcludeDataFromBackup != defaultConf.ExcludeDataFromBackup {
 		diffs = append(diffs, fmt.Sprintf("exclude_data_from_backup=%v", conf.ExcludeDataFromBackup))
 	}
+	if conf.StorageQuotaBytes != defaultConf.StorageQuotaBytes {
+		diffs = append(diffs, fmt.Sprintf("storage_quota_bytes=%d", conf.StorageQuotaBytes))
+	}
+	if conf.ReadOnly != defaultConf.ReadOnly {
+		diffs = append(diffs, fmt.Sprintf("read_only=%v", conf.ReadOnly))
+	}
 
 	return strings.Join(diffs, " ")
 }
@@ -714,6 +714,13 @@ func (n *alterTableNode) startExec(params runParams) error {
 			}
 			descriptorChanged = descriptorChanged || changed
 
+		case *tree.AlterTableSetReadOnly:
+			changed, err := params.p.setReadOnly(params.ctx, n.tableDesc, t.ReadOnly)
+			if err != nil {
+				return err
+			}
+			descriptorChanged = descriptorChanged || changed
+
 		case *tree.AlterTableInjectStats:
 			sd, ok := n.statsData[i]
 			if !ok {
@@ -918,6 +925,35 @@ func (p *planner) setAuditMode(
 	return desc.SetAuditMode(auditMode)
 }
 
+// setReadOnly puts the table into or takes it out of read-only mode, returning
+// whether the descriptor changed. The mode takes effect once the span configs
+// of the table are updated, after which its ranges reject writes.
+func (p *planner) setReadOnly(
+	ctx context.Context, desc *tabledesc.Mutable, readOnly bool,
+) (bool, error) {
+	// Nodes running an older version don't reject writes to the ranges of
+	// read-only tables.
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.ReadOnlyTables) {
+		return false, pgerror.Newf(pgcode.FeatureNotSupported,
+			"version %v must be finalized to change the read-only mode of a table",
+			clusterversion.ByKey(clusterversion.ReadOnlyTables))
+	}
+	// Read-only mode affects all the users of the table, so we require admin
+	// rather than CREATE on the table.
+	if err := p.RequireAdminRole(ctx, "change the read-only mode of a table"); err != nil {
+		return false, err
+	}
+	if catalog.IsSystemDescriptor(desc) {
+		return false, pgerror.Newf(pgcode.InvalidTableDefinition,
+			"cannot change the read-only mode of system table %s", tree.Name(desc.GetName()))
+	}
+	if desc.ReadOnly == readOnly {
+		return false, nil
+	}
+	desc.ReadOnly = readOnly
+	return true, nil
+}
+
 func (n *alterTableNode) Next(runParams) (bool, error) { return false, nil }
 func (n *alterTableNode) Values() tree.Datums          { return tree.Datums{} }
 func (n *alterTableNode) Close(context.Context)        {}
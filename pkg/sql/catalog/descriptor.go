@@ -689,6 +689,9 @@ type TableDescriptor interface {
 	// GetExcludeDataFromBackup returns true if the table's row data is configured
 	// to be excluded during backup.
 	GetExcludeDataFromBackup() bool
+	// IsReadOnly returns true if the table is in read-only mode, in which case
+	// its ranges reject writes.
+	IsReadOnly() bool
 	// GetStorageParams returns a list of storage parameters for the table.
 	GetStorageParams(spaceBetweenEqual bool) []string
 	// NoAutoStatsSettingsOverrides is true if no auto stats related settings are
@@ -414,10 +414,11 @@ CREATE TABLE system.statement_diagnostics_requests(
 	min_execution_latency INTERVAL NULL,
 	expires_at TIMESTAMPTZ NULL,
 	sampling_probability FLOAT NULL,
+	automatic BOOL NOT NULL DEFAULT FALSE,
 	CONSTRAINT "primary" PRIMARY KEY (id),
 	CONSTRAINT check_sampling_probability CHECK (sampling_probability BETWEEN 0.0 AND 1.0),
 	INDEX completed_idx (completed, id) STORING (statement_fingerprint, min_execution_latency, expires_at, sampling_probability),
-	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at, min_execution_latency, expires_at, sampling_probability)
+	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at, min_execution_latency, expires_at, sampling_probability, automatic)
 );`
 
 	StatementDiagnosticsTableSchema = `
@@ -679,6 +680,25 @@ CREATE TABLE system.span_count (
 	CONSTRAINT single_row CHECK (singleton),
 	FAMILY "primary" (singleton, span_count)
 );`
+
+	// HotRangesHistoryTableSchema stores periodic snapshots of the hottest and
+	// largest replicas on each store, so that hot spots can be investigated
+	// after the fact.
+	HotRangesHistoryTableSchema = `
+CREATE TABLE system.hot_ranges_history (
+	timestamp          TIMESTAMP NOT NULL,
+	node_id            INT8 NOT NULL,
+	store_id           INT8 NOT NULL,
+	range_id           INT8 NOT NULL,
+	queries_per_second FLOAT8 NOT NULL,
+	writes_per_second  FLOAT8 NOT NULL,
+	logical_bytes      INT8 NOT NULL,
+	database_name      STRING,
+	table_name         STRING,
+	index_name         STRING,
+	CONSTRAINT "primary" PRIMARY KEY (timestamp, store_id, range_id),
+	FAMILY "primary" (timestamp, node_id, store_id, range_id, queries_per_second, writes_per_second, logical_bytes, database_name, table_name, index_name)
+);`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -1787,12 +1807,13 @@ var (
 				{Name: "min_execution_latency", ID: 6, Type: types.Interval, Nullable: true},
 				{Name: "expires_at", ID: 7, Type: types.TimestampTZ, Nullable: true},
 				{Name: "sampling_probability", ID: 8, Type: types.Float, Nullable: true},
+				{Name: "automatic", ID: 9, Type: types.Bool, Nullable: false, DefaultExpr: &falseBoolString},
 			},
 			[]descpb.ColumnFamilyDescriptor{
 				{
 					Name:        "primary",
-					ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at", "min_execution_latency", "expires_at", "sampling_probability"},
-					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8},
+					ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at", "min_execution_latency", "expires_at", "sampling_probability", "automatic"},
+					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9},
 				},
 			},
 			pk("id"),
@@ -2408,6 +2429,52 @@ var (
 			}}
 		},
 	)
+
+	// HotRangesHistoryTable is the descriptor for the hot_ranges_history table.
+	HotRangesHistoryTable = registerSystemTable(
+		HotRangesHistoryTableSchema,
+		systemTable(
+			catconstants.HotRangesHistoryTableName,
+			descpb.InvalidID, // dynamically assigned
+			[]descpb.ColumnDescriptor{
+				{Name: "timestamp", ID: 1, Type: types.Timestamp},
+				{Name: "node_id", ID: 2, Type: types.Int},
+				{Name: "store_id", ID: 3, Type: types.Int},
+				{Name: "range_id", ID: 4, Type: types.Int},
+				{Name: "queries_per_second", ID: 5, Type: types.Float},
+				{Name: "writes_per_second", ID: 6, Type: types.Float},
+				{Name: "logical_bytes", ID: 7, Type: types.Int},
+				{Name: "database_name", ID: 8, Type: types.String, Nullable: true},
+				{Name: "table_name", ID: 9, Type: types.String, Nullable: true},
+				{Name: "index_name", ID: 10, Type: types.String, Nullable: true},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name: "primary",
+					ID:   0,
+					ColumnNames: []string{
+						"timestamp", "node_id", "store_id", "range_id",
+						"queries_per_second", "writes_per_second", "logical_bytes",
+						"database_name", "table_name", "index_name",
+					},
+					ColumnIDs:       []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+					DefaultColumnID: 0,
+				},
+			},
+			descpb.IndexDescriptor{
+				Name:           tabledesc.LegacyPrimaryKeyIndexName,
+				ID:             1,
+				Unique:         true,
+				KeyColumnNames: []string{"timestamp", "store_id", "range_id"},
+				KeyColumnDirections: []descpb.IndexDescriptor_Direction{
+					descpb.IndexDescriptor_ASC,
+					descpb.IndexDescriptor_ASC,
+					descpb.IndexDescriptor_ASC,
+				},
+				KeyColumnIDs: []descpb.ColumnID{1, 3, 4},
+				Version:      descpb.StrictIndexColumnIDGuaranteesVersion,
+			},
+		))
 )
 
 type descRefByName struct {
@@ -2565,6 +2565,11 @@ func (desc *wrapper) GetExcludeDataFromBackup() bool {
 	return desc.ExcludeDataFromBackup
 }
 
+// IsReadOnly implements the TableDescriptor interface.
+func (desc *wrapper) IsReadOnly() bool {
+	return desc.ReadOnly
+}
+
 // GetStorageParams implements the TableDescriptor interface.
 func (desc *wrapper) GetStorageParams(spaceBetweenEqual bool) []string {
 	var storageParams []string
@@ -43,6 +43,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/buildutil"
+	"github.com/cockroachdb/cockroach/pkg/util/cputime"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -436,6 +437,7 @@ func (s *vectorizedFlowCreator) makeGetStatsFnForOutbox(
 				FlowStats: execinfrapb.FlowStats{
 					MaxMemUsage:  optional.MakeUint(uint64(flowCtx.EvalCtx.Mon.MaximumBytes())),
 					MaxDiskUsage: optional.MakeUint(uint64(flowCtx.DiskMonitor.MaximumBytes())),
+					CPUTime:      optional.MakeTimeValue(time.Duration(atomic.LoadInt64(&s.cpuTimeNanos))),
 				},
 			})
 		}
@@ -549,6 +551,10 @@ type vectorizedFlowCreator struct {
 	// have been drained. When numOutboxesDrained equals numOutboxes, flow-level
 	// metadata is added to a flow-level span on the non-gateway nodes.
 	numOutboxesDrained int32
+	// cpuTimeNanos accumulates the CPU time, in nanoseconds, spent by the
+	// goroutines of the outboxes that have been drained. It must be accessed
+	// atomically.
+	cpuTimeNanos int64
 
 	// procIdxQueue is a queue of indices into processorSpecs (the argument to
 	// setupFlow), for topologically ordered processing.
@@ -681,6 +687,19 @@ func (s *vectorizedFlowCreator) setupRemoteOutputStream(
 	factory coldata.ColumnFactory,
 	getStats func() []*execinfrapb.ComponentStats,
 ) (execopnode.OpNode, error) {
+	var cpuWatch *cputime.Stopwatch
+	if getStats != nil && !s.isGatewayNode {
+		// The outbox goroutine drives the operators feeding the outbox, so its
+		// CPU time is attributed to the flow. The outbox collects its stats on
+		// its own goroutine, right before the flow-level stats are produced by
+		// the last outbox to be drained.
+		cpuWatch = &cputime.Stopwatch{}
+		outboxGetStats := getStats
+		getStats = func() []*execinfrapb.ComponentStats {
+			atomic.AddInt64(&s.cpuTimeNanos, int64(cpuWatch.Elapsed()))
+			return outboxGetStats()
+		}
+	}
 	outbox, err := s.remoteComponentCreator.newOutbox(
 		colmem.NewAllocator(ctx, s.monitorRegistry.NewStreamingMemAccount(flowCtx), factory),
 		op, outputTyps, getStats,
@@ -691,6 +710,10 @@ func (s *vectorizedFlowCreator) setupRemoteOutputStream(
 
 	atomic.AddInt32(&s.numOutboxes, 1)
 	run := func(ctx context.Context, flowCtxCancel context.CancelFunc) {
+		if cpuWatch != nil {
+			cpuWatch.Start()
+			defer cpuWatch.Stop()
+		}
 		outbox.Run(
 			ctx,
 			s.nodeDialer,
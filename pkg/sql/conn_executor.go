@@ -50,6 +50,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sessionphase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/outliers"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/persistedsqlstats"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/sslocal"
 	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
@@ -343,6 +344,10 @@ type ServerMetrics struct {
 func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 	metrics := makeMetrics(false /* internal */)
 	serverMetrics := makeServerMetrics(cfg)
+	var outliersDiagnostics outliers.DiagnosticsRequester
+	if cfg.StmtDiagnosticsRecorder != nil {
+		outliersDiagnostics = cfg.StmtDiagnosticsRecorder
+	}
 	reportedSQLStats := sslocal.New(
 		cfg.Settings,
 		sqlstats.MaxMemReportedSQLStatsStmtFingerprints,
@@ -351,6 +356,7 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 		serverMetrics.StatsMetrics.ReportedSQLStatsMemoryMaxBytesHist,
 		pool,
 		nil, /* reportedProvider */
+		outliersDiagnostics,
 		cfg.SQLStatsTestingKnobs,
 	)
 	reportedSQLStatsController :=
@@ -363,6 +369,7 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 		serverMetrics.StatsMetrics.SQLStatsMemoryMaxBytesHist,
 		pool,
 		reportedSQLStats,
+		outliersDiagnostics,
 		cfg.SQLStatsTestingKnobs,
 	)
 	s := &Server{
@@ -22,6 +22,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -277,6 +278,10 @@ func (ex *connExecutor) execStmtInOpenState(
 	defer sp.Finish()
 	ast := parserStmt.AST
 	ctx = withStatement(ctx, ast)
+	// Record the user on whose behalf the statement reads, so that reads below
+	// the GC threshold, including those of the catalog, can be served from
+	// mounted backups with the user's privileges.
+	ctx = kvcoord.ContextWithHistoricalReadUser(ctx, ex.sessionData().User())
 
 	makeErrEvent := func(err error) (fsm.Event, fsm.EventPayload, error) {
 		ev, payload := ex.makeErrEvent(err, ast)
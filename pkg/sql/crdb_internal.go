@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/storagequota"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
@@ -80,6 +81,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing/collector"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 )
 
@@ -109,6 +111,7 @@ var crdbInternal = virtualSchema{
 		catconstants.CrdbInternalClusterContentionEventsTableID:     crdbInternalClusterContentionEventsTable,
 		catconstants.CrdbInternalClusterDistSQLFlowsTableID:         crdbInternalClusterDistSQLFlowsTable,
 		catconstants.CrdbInternalClusterLocksTableID:                crdbInternalClusterLocksTable,
+		catconstants.CrdbInternalClusterLockWaitsTableID:            crdbInternalClusterLockWaitsTable,
 		catconstants.CrdbInternalClusterQueriesTableID:              crdbInternalClusterQueriesTable,
 		catconstants.CrdbInternalClusterTransactionsTableID:         crdbInternalClusterTxnsTable,
 		catconstants.CrdbInternalClusterSessionsTableID:             crdbInternalClusterSessionsTable,
@@ -132,6 +135,7 @@ var crdbInternal = virtualSchema{
 		catconstants.CrdbInternalInflightTraceSpanTableID:           crdbInternalInflightTraceSpanTable,
 		catconstants.CrdbInternalJobsTableID:                        crdbInternalJobsTable,
 		catconstants.CrdbInternalKVNodeStatusTableID:                crdbInternalKVNodeStatusTable,
+		catconstants.CrdbInternalKVStorageQuotasTableID:             crdbInternalKVStorageQuotasTable,
 		catconstants.CrdbInternalKVStoreStatusTableID:               crdbInternalKVStoreStatusTable,
 		catconstants.CrdbInternalLeasesTableID:                      crdbInternalLeasesTable,
 		catconstants.CrdbInternalLocalContentionEventsTableID:       crdbInternalLocalContentionEventsTable,
@@ -4076,6 +4080,55 @@ CREATE TABLE crdb_internal.gossip_network (
 	},
 }
 
+// crdbInternalKVStorageQuotasTable exposes the usage of the tenants and tables
+// with a storage quota, as gossiped by the stores.
+var crdbInternalKVStorageQuotasTable = virtualSchemaTable{
+	comment: "logical bytes stored by tenants and tables subject to a storage quota (RAM; gossiped by all stores)",
+	schema: `
+CREATE TABLE crdb_internal.kv_storage_quotas (
+  tenant_id       INT NOT NULL,
+  table_id        INT NULL,        -- null for the usage of the tenant as a whole
+  logical_bytes   INT NOT NULL,
+  quota_bytes     INT NULL,        -- null if the target isn't subject to a quota
+  exceeded        BOOL NOT NULL
+)
+	`,
+	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.kv_storage_quotas"); err != nil {
+			return err
+		}
+
+		g, err := p.ExecCfg().Gossip.OptionalErr(47899)
+		if err != nil {
+			return err
+		}
+
+		reports, err := storagequota.UsageFromGossip(g)
+		if err != nil {
+			return err
+		}
+		for _, u := range storagequota.Aggregate(reports) {
+			tableID, quota := tree.DNull, tree.DNull
+			if u.TableID != 0 {
+				tableID = tree.NewDInt(tree.DInt(u.TableID))
+			}
+			if u.QuotaBytes != 0 {
+				quota = tree.NewDInt(tree.DInt(u.QuotaBytes))
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(u.TenantID.ToUint64())),
+				tableID,
+				tree.NewDInt(tree.DInt(u.LogicalBytes)),
+				quota,
+				tree.MakeDBool(tree.DBool(u.QuotaBytes != 0 && u.LogicalBytes >= u.QuotaBytes)),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // addPartitioningRows adds the rows in crdb_internal.partitions for each partition.
 // None of the arguments can be nil, and it is used recursively when a list partition
 // has subpartitions. In that case, the colOffset argument is incremented to represent
@@ -6174,23 +6227,174 @@ func populateClusterLocksWithFilter(
 	return matched, err
 }
 
+// crdbInternalClusterLockWaitsTable exposes the waits-for graph between the
+// transactions of the cluster, one row per wait.
+var crdbInternalClusterLockWaitsTable = virtualSchemaTable{
+	comment: `cluster-wide waits of transactions on each other's locks and
+		transaction records. Querying this table is an expensive operation since it
+		creates a cluster-wide RPC-fanout.`,
+	schema: `
+CREATE TABLE crdb_internal.cluster_lock_waits (
+    waiting_txn_id          UUID,              -- NULL for non-transactional requests
+    blocking_txn_id         UUID NOT NULL,
+    range_id                INT NOT NULL,
+    lock_key                BYTES,             -- NULL for waits in the txn wait queue
+    lock_key_pretty         STRING,
+    lock_strength           STRING,
+    source                  STRING NOT NULL,   -- lock_table or txn_wait_queue
+    node_id                 INT NOT NULL,      -- the node on which the wait was observed
+    wait_duration           INTERVAL NOT NULL,
+    waiting_session_id      STRING,
+    waiting_app_name        STRING,
+    waiting_statement       STRING,
+    blocking_session_id     STRING,
+    blocking_app_name       STRING,
+    blocking_statement      STRING,
+    cycle_id                INT,               -- set if the wait is part of a cycle of waits
+    wait_depth              INT,               -- length of the longest chain of waits of the waiting txn
+    long_chain              BOOL
+)`,
+	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		hasAdmin, err := p.HasAdminRole(ctx)
+		if err != nil {
+			return err
+		}
+		hasViewActivityOrViewActivityRedacted, err := p.HasViewActivityOrViewActivityRedactedRole(ctx)
+		if err != nil {
+			return err
+		}
+		if !hasViewActivityOrViewActivityRedacted {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"user %s does not have %s or %s privilege", p.User(), roleoption.VIEWACTIVITY, roleoption.VIEWACTIVITYREDACTED)
+		}
+		shouldRedact := false
+		if !hasAdmin {
+			shouldRedact, err = p.HasRoleOption(ctx, roleoption.VIEWACTIVITYREDACTED)
+			if err != nil {
+				return err
+			}
+		}
+
+		ss, err := p.ExecCfg().NodesStatusServer.OptionalNodesStatusServer(
+			errorutil.FeatureNotAvailableToNonSystemTenantsIssue)
+		if err != nil {
+			return err
+		}
+		response, err := ss.LockWaitGraph(ctx, &serverpb.LockWaitGraphRequest{})
+		if err != nil {
+			return err
+		}
+		for _, rpcErr := range response.Errors {
+			log.Warningf(ctx, "%v", rpcErr.Message)
+		}
+
+		txns := make(map[uuid.UUID]*serverpb.LockWaitGraphResponse_Txn, len(response.Txns))
+		for i := range response.Txns {
+			txns[response.Txns[i].TxnID] = &response.Txns[i]
+		}
+		// txnDatums returns the session_id, app_name and statement of the given
+		// transaction.
+		txnDatums := func(txn *serverpb.LockWaitGraphResponse_Txn) (tree.Datum, tree.Datum, tree.Datum) {
+			if txn == nil || len(txn.SessionID) != 16 {
+				return tree.DNull, tree.DNull, tree.DNull
+			}
+			sessionID := tree.NewDString(clusterunique.IDFromBytes(txn.SessionID).String())
+			stmt := txn.Statement
+			if shouldRedact {
+				stmt = txn.StatementNoConstants
+			}
+			stmtDatum := tree.DNull
+			if stmt != "" {
+				stmtDatum = tree.NewDString(stmt)
+			}
+			return sessionID, tree.NewDString(txn.AppName), stmtDatum
+		}
+
+		for _, e := range response.Edges {
+			waitingTxnID := tree.DNull
+			waitingTxn := txns[e.WaiterTxnID]
+			if e.WaiterTxnID != (uuid.UUID{}) {
+				waitingTxnID = tree.NewDUuid(tree.DUuid{UUID: e.WaiterTxnID})
+			}
+			blockingTxn := txns[e.HolderTxnID]
+
+			key, keyPretty, strength := tree.DNull, tree.DNull, tree.DNull
+			source := "txn_wait_queue"
+			if e.Source == serverpb.LockWaitGraphResponse_LOCK_TABLE {
+				source = "lock_table"
+				strength = tree.NewDString(e.Strength.String())
+				if !shouldRedact {
+					k, _, _ := keys.DecodeTenantPrefix(e.Key)
+					key = tree.NewDBytes(tree.DBytes(k))
+					keyPretty = tree.NewDString(keys.PrettyPrint(nil /* valDirs */, k))
+				}
+			}
+
+			cycleID, waitDepth, longChain := tree.DNull, tree.DNull, tree.DNull
+			if waitingTxn != nil {
+				if blockingTxn != nil && waitingTxn.CycleID != 0 && waitingTxn.CycleID == blockingTxn.CycleID {
+					cycleID = tree.NewDInt(tree.DInt(waitingTxn.CycleID))
+				}
+				waitDepth = tree.NewDInt(tree.DInt(waitingTxn.WaitDepth))
+				longChain = tree.MakeDBool(tree.DBool(waitingTxn.LongChain))
+			}
+
+			waitDuration := tree.NewDInterval(
+				duration.MakeDuration(e.WaitDuration.Nanoseconds(), 0 /* days */, 0 /* months */),
+				types.DefaultIntervalTypeMetadata,
+			)
+			waitingSessionID, waitingAppName, waitingStmt := txnDatums(waitingTxn)
+			blockingSessionID, blockingAppName, blockingStmt := txnDatums(blockingTxn)
+			if err := addRow(
+				waitingTxnID, /* waiting_txn_id */
+				tree.NewDUuid(tree.DUuid{UUID: e.HolderTxnID}), /* blocking_txn_id */
+				tree.NewDInt(tree.DInt(e.RangeID)),             /* range_id */
+				key,                                            /* lock_key */
+				keyPretty,                                      /* lock_key_pretty */
+				strength,                                       /* lock_strength */
+				tree.NewDString(source),                        /* source */
+				tree.NewDInt(tree.DInt(e.NodeID)),              /* node_id */
+				waitDuration,                                   /* wait_duration */
+				waitingSessionID,                               /* waiting_session_id */
+				waitingAppName,                                 /* waiting_app_name */
+				waitingStmt,                                    /* waiting_statement */
+				blockingSessionID,                              /* blocking_session_id */
+				blockingAppName,                                /* blocking_app_name */
+				blockingStmt,                                   /* blocking_statement */
+				cycleID,                                        /* cycle_id */
+				waitDepth,                                      /* wait_depth */
+				longChain,                                      /* long_chain */
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 var crdbInternalNodeExecutionOutliersTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE crdb_internal.node_execution_outliers (
 	session_id               STRING NOT NULL,
 	transaction_id           UUID NOT NULL,
 	statement_id             STRING NOT NULL,
-	statement_fingerprint_id BYTES NOT NULL
+	statement_fingerprint_id BYTES NOT NULL,
+	diagnostics_request_id   INT
 );`,
 	populate: func(ctx context.Context, p *planner, db catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) (err error) {
 		p.extendedEvalCtx.statsProvider.IterateOutliers(ctx, func(
 			ctx context.Context, o *outliers.Outlier,
 		) {
+			diagnosticsRequestID := tree.DNull
+			if o.Statement.DiagnosticsRequestID != 0 {
+				diagnosticsRequestID = tree.NewDInt(tree.DInt(o.Statement.DiagnosticsRequestID))
+			}
 			err = errors.CombineErrors(err, addRow(
 				tree.NewDString(hex.EncodeToString(o.Session.ID)),
 				tree.NewDUuid(tree.DUuid{UUID: *o.Transaction.ID}),
 				tree.NewDString(hex.EncodeToString(o.Statement.ID)),
 				tree.NewDBytes(tree.DBytes(sqlstatsutil.EncodeUint64ToBytes(uint64(o.Statement.FingerprintID)))),
+				diagnosticsRequestID,
 			))
 		})
 		return err
@@ -64,7 +64,7 @@ import "github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 //
 // ATTENTION: When updating these fields, add a brief description of what
 // changed to the version history below.
-const Version execinfrapb.DistSQLVersion = 68
+const Version execinfrapb.DistSQLVersion = 69
 
 // MinAcceptedVersion is the oldest version that the server is compatible with.
 // A server will not accept flows with older versions.
@@ -76,6 +76,12 @@ const MinAcceptedVersion execinfrapb.DistSQLVersion = 68
 
 Please add new entries at the top.
 
+- Version: 69 (MinAcceptedVersion: 68)
+  - ExportSpec now supports the JSON and Avro file formats. A server running
+    v68 would write such exports as CSV, hence the version bump. However, a
+    server running v69 can still process all plans from servers running v68,
+    thus the MinAcceptedVersion is kept at 68.
+
 - Version: 68 (MinAcceptedVersion: 68)
   - ZigzagJoinerSpec now uses descpb.IndexFetchSpec instead of table and
     index descriptors.
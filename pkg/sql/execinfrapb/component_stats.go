@@ -276,6 +276,9 @@ func (s *ComponentStats) Union(other *ComponentStats) *ComponentStats {
 	if !result.FlowStats.MaxDiskUsage.HasValue() {
 		result.FlowStats.MaxDiskUsage = other.FlowStats.MaxDiskUsage
 	}
+	if !result.FlowStats.CPUTime.HasValue() {
+		result.FlowStats.CPUTime = other.FlowStats.CPUTime
+	}
 
 	return &result
 }
@@ -353,6 +356,9 @@ func (s *ComponentStats) MakeDeterministic() {
 	for i := range s.Inputs {
 		timeVal(&s.Inputs[i].WaitTime)
 	}
+
+	// Flow.
+	timeVal(&s.FlowStats.CPUTime)
 }
 
 // ExtractStatsFromSpans extracts all ComponentStats from a set of tracing
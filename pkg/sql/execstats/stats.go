@@ -54,6 +54,27 @@ func GetCumulativeContentionTime(ctx context.Context, recording tracingpb.Record
 	return cumulativeContentionTime
 }
 
+// GetCumulativeAdmissionWaitTime is a helper function to calculate the
+// cumulative time spent waiting in admission control queues from the given
+// recording. All admission wait events found in the trace are included, which
+// covers the queueing of both the SQL work and the KV requests it issued.
+func GetCumulativeAdmissionWaitTime(recording tracingpb.Recording) time.Duration {
+	var cumulativeWaitTime time.Duration
+	var ev roachpb.AdmissionWaitEvent
+	for i := range recording {
+		recording[i].Structured(func(any *pbtypes.Any, _ time.Time) {
+			if !pbtypes.Is(any, &ev) {
+				return
+			}
+			if err := pbtypes.UnmarshalAny(any, &ev); err != nil {
+				return
+			}
+			cumulativeWaitTime += ev.WaitDuration
+		})
+	}
+	return cumulativeWaitTime
+}
+
 // ScanStats contains statistics on the internal MVCC operators used to satisfy
 // a scan. See storage/engine.go for a more thorough discussion of the meaning
 // of each stat.
@@ -107,21 +107,24 @@ type NodeLevelStats struct {
 	KVTimeGroupedByNode           map[base.SQLInstanceID]time.Duration
 	NetworkMessagesGroupedByNode  map[base.SQLInstanceID]int64
 	ContentionTimeGroupedByNode   map[base.SQLInstanceID]time.Duration
+	CPUTimeGroupedByNode          map[base.SQLInstanceID]time.Duration
 }
 
 // QueryLevelStats returns all the query level stats that correspond to the
 // given traces and flow metadata.
 // NOTE: When adding fields to this struct, be sure to update Accumulate.
 type QueryLevelStats struct {
-	NetworkBytesSent int64
-	MaxMemUsage      int64
-	MaxDiskUsage     int64
-	KVBytesRead      int64
-	KVRowsRead       int64
-	KVTime           time.Duration
-	NetworkMessages  int64
-	ContentionTime   time.Duration
-	Regions          []string
+	NetworkBytesSent  int64
+	MaxMemUsage       int64
+	MaxDiskUsage      int64
+	KVBytesRead       int64
+	KVRowsRead        int64
+	KVTime            time.Duration
+	NetworkMessages   int64
+	ContentionTime    time.Duration
+	CPUTime           time.Duration
+	AdmissionWaitTime time.Duration
+	Regions           []string
 }
 
 // Accumulate accumulates other's stats into the receiver.
@@ -138,6 +141,8 @@ func (s *QueryLevelStats) Accumulate(other QueryLevelStats) {
 	s.KVTime += other.KVTime
 	s.NetworkMessages += other.NetworkMessages
 	s.ContentionTime += other.ContentionTime
+	s.CPUTime += other.CPUTime
+	s.AdmissionWaitTime += other.AdmissionWaitTime
 	s.Regions = util.CombineUniqueString(s.Regions, other.Regions)
 }
 
@@ -213,6 +218,7 @@ func (a *TraceAnalyzer) ProcessStats() error {
 		KVTimeGroupedByNode:           make(map[base.SQLInstanceID]time.Duration),
 		NetworkMessagesGroupedByNode:  make(map[base.SQLInstanceID]int64),
 		ContentionTimeGroupedByNode:   make(map[base.SQLInstanceID]time.Duration),
+		CPUTimeGroupedByNode:          make(map[base.SQLInstanceID]time.Duration),
 	}
 	var errs error
 
@@ -290,6 +296,7 @@ func (a *TraceAnalyzer) ProcessStats() error {
 				}
 
 			}
+			a.nodeLevelStats.CPUTimeGroupedByNode[instanceID] += v.FlowStats.CPUTime.Value()
 		}
 	}
 
@@ -331,6 +338,10 @@ func (a *TraceAnalyzer) ProcessStats() error {
 	for _, contentionTime := range a.nodeLevelStats.ContentionTimeGroupedByNode {
 		a.queryLevelStats.ContentionTime += contentionTime
 	}
+
+	for _, cpuTime := range a.nodeLevelStats.CPUTimeGroupedByNode {
+		a.queryLevelStats.CPUTime += cpuTime
+	}
 	return errs
 }
 
@@ -408,5 +419,8 @@ func GetQueryLevelStats(
 		}
 		queryLevelStats.Accumulate(analyzer.GetQueryLevelStats())
 	}
+	// Admission wait events are recorded by the admission queues rather than by
+	// the flows' components, so they are extracted from the whole trace.
+	queryLevelStats.AdmissionWaitTime = GetCumulativeAdmissionWaitTime(trace)
 	return queryLevelStats, errs
 }
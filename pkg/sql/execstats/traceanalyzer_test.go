@@ -241,37 +241,43 @@ func TestTraceAnalyzerProcessStats(t *testing.T) {
 
 func TestQueryLevelStatsAccumulate(t *testing.T) {
 	a := execstats.QueryLevelStats{
-		NetworkBytesSent: 1,
-		MaxMemUsage:      2,
-		KVBytesRead:      3,
-		KVRowsRead:       4,
-		KVTime:           5 * time.Second,
-		NetworkMessages:  6,
-		ContentionTime:   7 * time.Second,
-		MaxDiskUsage:     8,
-		Regions:          []string{"gcp-us-east1"},
+		NetworkBytesSent:  1,
+		MaxMemUsage:       2,
+		KVBytesRead:       3,
+		KVRowsRead:        4,
+		KVTime:            5 * time.Second,
+		NetworkMessages:   6,
+		ContentionTime:    7 * time.Second,
+		MaxDiskUsage:      8,
+		CPUTime:           16 * time.Second,
+		AdmissionWaitTime: 17 * time.Second,
+		Regions:           []string{"gcp-us-east1"},
 	}
 	b := execstats.QueryLevelStats{
-		NetworkBytesSent: 8,
-		MaxMemUsage:      9,
-		KVBytesRead:      10,
-		KVRowsRead:       11,
-		KVTime:           12 * time.Second,
-		NetworkMessages:  13,
-		ContentionTime:   14 * time.Second,
-		MaxDiskUsage:     15,
-		Regions:          []string{"gcp-us-west1"},
+		NetworkBytesSent:  8,
+		MaxMemUsage:       9,
+		KVBytesRead:       10,
+		KVRowsRead:        11,
+		KVTime:            12 * time.Second,
+		NetworkMessages:   13,
+		ContentionTime:    14 * time.Second,
+		MaxDiskUsage:      15,
+		CPUTime:           18 * time.Second,
+		AdmissionWaitTime: 19 * time.Second,
+		Regions:           []string{"gcp-us-west1"},
 	}
 	expected := execstats.QueryLevelStats{
-		NetworkBytesSent: 9,
-		MaxMemUsage:      9,
-		KVBytesRead:      13,
-		KVRowsRead:       15,
-		KVTime:           17 * time.Second,
-		NetworkMessages:  19,
-		ContentionTime:   21 * time.Second,
-		MaxDiskUsage:     15,
-		Regions:          []string{"gcp-us-east1", "gcp-us-west1"},
+		NetworkBytesSent:  9,
+		MaxMemUsage:       9,
+		KVBytesRead:       13,
+		KVRowsRead:        15,
+		KVTime:            17 * time.Second,
+		NetworkMessages:   19,
+		ContentionTime:    21 * time.Second,
+		MaxDiskUsage:      15,
+		CPUTime:           34 * time.Second,
+		AdmissionWaitTime: 36 * time.Second,
+		Regions:           []string{"gcp-us-east1", "gcp-us-west1"},
 	}
 
 	aCopy := a
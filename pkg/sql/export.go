@@ -77,8 +77,11 @@ const (
 	exportFilePatternPart = "%part%"
 	exportGzipCodec       = "gzip"
 	exportSnappyCodec     = "snappy"
+	exportDeflateCodec    = "deflate"
 	csvSuffix             = "csv"
 	parquetSuffix         = "parquet"
+	jsonSuffix            = "json"
+	avroSuffix            = "avro"
 )
 
 var exportOptionExpectValues = map[string]KVStringOptValidate{
@@ -128,7 +131,9 @@ func (ef *execFactory) ConstructExport(
 		return nil, errors.Errorf("EXPORT cannot be used inside a multi-statement transaction")
 	}
 
-	if fileSuffix != csvSuffix && fileSuffix != parquetSuffix {
+	switch fileSuffix {
+	case csvSuffix, parquetSuffix, jsonSuffix, avroSuffix:
+	default:
 		return nil, errors.Errorf("unsupported export format: %q", fileSuffix)
 	}
 
@@ -168,6 +173,18 @@ func (ef *execFactory) ConstructExport(
 		colNames[i] = col.Name
 		colNullability[i] = !notNullCols.Contains(i)
 	}
+	if fileSuffix == jsonSuffix || fileSuffix == avroSuffix {
+		// Columns become the fields of a JSON object or Avro record, so their
+		// names must be unique.
+		seen := make(map[string]struct{}, len(colNames))
+		for _, name := range colNames {
+			if _, ok := seen[name]; ok {
+				return nil, pgerror.Newf(pgcode.DuplicateColumn,
+					"duplicate column name %q; use AS to give exported columns unique names", name)
+			}
+			seen[name] = struct{}{}
+		}
+	}
 
 	format := roachpb.IOFileFormat{}
 	switch fileSuffix {
@@ -190,6 +207,10 @@ func (ef *execFactory) ConstructExport(
 		}
 		format.Format = roachpb.IOFileFormat_Parquet
 		format.Parquet = parquetOpts
+	case jsonSuffix:
+		format.Format = roachpb.IOFileFormat_JSON
+	case avroSuffix:
+		format.Format = roachpb.IOFileFormat_Avro
 	}
 
 	chunkRows := exportChunkRowsDefault
@@ -219,10 +240,13 @@ func (ef *execFactory) ConstructExport(
 	var codec roachpb.IOFileFormat_Compression
 	if name, ok := optVals[exportOptionCompression]; ok && len(name) != 0 {
 		switch {
-		case strings.EqualFold(name, exportGzipCodec):
+		case strings.EqualFold(name, exportGzipCodec) && fileSuffix != avroSuffix:
 			codec = roachpb.IOFileFormat_Gzip
-		case strings.EqualFold(name, exportSnappyCodec) && fileSuffix == parquetSuffix:
+		case strings.EqualFold(name, exportSnappyCodec) &&
+			(fileSuffix == parquetSuffix || fileSuffix == avroSuffix):
 			codec = roachpb.IOFileFormat_Snappy
+		case strings.EqualFold(name, exportDeflateCodec) && fileSuffix == avroSuffix:
+			codec = roachpb.IOFileFormat_Deflate
 		default:
 			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
 				"unsupported compression codec %s for %s file format", name, fileSuffix)
@@ -157,6 +157,9 @@ func clearSpanData(
 				endKey = span.EndKey
 			}
 			var b kv.Batch
+			// The data of a dropped table must be cleared even if the table was in
+			// read-only mode when it was dropped.
+			b.Header.BypassReadOnlyMode = true
 			b.AddRawRequest(&roachpb.ClearRangeRequest{
 				RequestHeader: roachpb.RequestHeader{
 					Key:    lastKey.AsRawKey(),
@@ -0,0 +1,295 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+const exportJSONFilePatternDefault = exportFilePatternPart + ".json"
+
+// jsonExporter writes rows as newline-delimited JSON objects, optionally
+// compressed, encapsulating the internals to make exporting oblivious for the
+// consumers.
+type jsonExporter struct {
+	compressor *gzip.Writer
+	buf        *bytes.Buffer
+	w          io.Writer
+	colNames   []string
+	scratch    bytes.Buffer
+}
+
+// Write appends a row to the file as a JSON object keyed by column name.
+func (c *jsonExporter) Write(row tree.Datums) error {
+	b := json.NewObjectBuilder(len(row))
+	for i, d := range row {
+		j, err := tree.AsJSON(d, sessiondatapb.DataConversionConfig{}, time.UTC)
+		if err != nil {
+			return err
+		}
+		b.Add(c.colNames[i], j)
+	}
+	c.scratch.Reset()
+	b.Build().Format(&c.scratch)
+	c.scratch.WriteByte('\n')
+	_, err := c.w.Write(c.scratch.Bytes())
+	return err
+}
+
+// Flush flushes the compressor writer if initialized.
+func (c *jsonExporter) Flush() error {
+	if c.compressor != nil {
+		return c.compressor.Flush()
+	}
+	return nil
+}
+
+// Close closes the compressor writer which appends archive footers.
+func (c *jsonExporter) Close() error {
+	if c.compressor != nil {
+		return c.compressor.Close()
+	}
+	return nil
+}
+
+// ResetBuffer resets the buffer and compressor state.
+func (c *jsonExporter) ResetBuffer() {
+	c.buf.Reset()
+	if c.compressor != nil {
+		c.compressor.Reset(c.buf)
+	}
+}
+
+// Bytes results in the slice of bytes with compressed content.
+func (c *jsonExporter) Bytes() []byte {
+	return c.buf.Bytes()
+}
+
+// Len returns length of the buffer with content.
+func (c *jsonExporter) Len() int {
+	return c.buf.Len()
+}
+
+func (c *jsonExporter) FileName(spec execinfrapb.ExportSpec, part string) string {
+	pattern := exportJSONFilePatternDefault
+	if spec.NamePattern != "" {
+		pattern = spec.NamePattern
+	}
+
+	fileName := strings.Replace(pattern, exportFilePatternPart, part, -1)
+	if c.compressor != nil {
+		fileName += ".gz"
+	}
+	return fileName
+}
+
+func newJSONExporter(sp execinfrapb.ExportSpec) *jsonExporter {
+	buf := bytes.NewBuffer([]byte{})
+	exporter := &jsonExporter{
+		buf:      buf,
+		w:        buf,
+		colNames: sp.ColNames,
+	}
+	if sp.Format.Compression == roachpb.IOFileFormat_Gzip {
+		exporter.compressor = gzip.NewWriter(buf)
+		exporter.w = exporter.compressor
+	}
+	return exporter
+}
+
+func newJSONWriterProcessor(
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec execinfrapb.ExportSpec,
+	input execinfra.RowSource,
+	output execinfra.RowReceiver,
+) (execinfra.Processor, error) {
+	c := &jsonWriter{
+		flowCtx:     flowCtx,
+		processorID: processorID,
+		spec:        spec,
+		input:       input,
+		output:      output,
+	}
+	semaCtx := tree.MakeSemaContext()
+	if err := c.out.Init(&execinfrapb.PostProcessSpec{}, c.OutputTypes(), &semaCtx, flowCtx.NewEvalCtx()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type jsonWriter struct {
+	flowCtx     *execinfra.FlowCtx
+	processorID int32
+	spec        execinfrapb.ExportSpec
+	input       execinfra.RowSource
+	out         execinfra.ProcOutputHelper
+	output      execinfra.RowReceiver
+}
+
+var _ execinfra.Processor = &jsonWriter{}
+
+func (sp *jsonWriter) OutputTypes() []*types.T {
+	res := make([]*types.T, len(colinfo.ExportColumns))
+	for i := range res {
+		res[i] = colinfo.ExportColumns[i].Typ
+	}
+	return res
+}
+
+func (sp *jsonWriter) MustBeStreaming() bool {
+	return false
+}
+
+func (sp *jsonWriter) Run(ctx context.Context) {
+	ctx, span := tracing.ChildSpan(ctx, "jsonWriter")
+	defer span.Finish()
+
+	instanceID := sp.flowCtx.EvalCtx.NodeID.SQLInstanceID()
+	uniqueID := builtins.GenerateUniqueInt(instanceID)
+
+	err := func() error {
+		typs := sp.input.OutputTypes()
+		if len(sp.spec.ColNames) != len(typs) {
+			return errors.AssertionFailedf(
+				"expected %d column names, found %d", len(typs), len(sp.spec.ColNames))
+		}
+		sp.input.Start(ctx)
+		input := execinfra.MakeNoMetadataRowSource(sp.input, sp.output)
+
+		alloc := &tree.DatumAlloc{}
+
+		writer := newJSONExporter(sp.spec)
+		jsonRow := make(tree.Datums, len(typs))
+
+		chunk := 0
+		done := false
+		for {
+			var rows int64
+			writer.ResetBuffer()
+			for {
+				// If the bytes.Buffer sink exceeds the target size of a JSON file, we
+				// flush before exporting any additional rows.
+				if int64(writer.buf.Len()) >= sp.spec.ChunkSize {
+					break
+				}
+				if sp.spec.ChunkRows > 0 && rows >= sp.spec.ChunkRows {
+					break
+				}
+				row, err := input.NextRow()
+				if err != nil {
+					return err
+				}
+				if row == nil {
+					done = true
+					break
+				}
+				rows++
+
+				for i, ed := range row {
+					if err := ed.EnsureDecoded(typs[i], alloc); err != nil {
+						return err
+					}
+					jsonRow[i] = ed.Datum
+				}
+				if err := writer.Write(jsonRow); err != nil {
+					return err
+				}
+			}
+			if rows < 1 {
+				break
+			}
+			if err := writer.Flush(); err != nil {
+				return errors.Wrap(err, "failed to flush json writer")
+			}
+
+			conf, err := cloud.ExternalStorageConfFromURI(sp.spec.Destination, sp.spec.User())
+			if err != nil {
+				return err
+			}
+			es, err := sp.flowCtx.Cfg.ExternalStorage(ctx, conf)
+			if err != nil {
+				return err
+			}
+			defer es.Close()
+
+			part := fmt.Sprintf("n%d.%d", uniqueID, chunk)
+			chunk++
+			filename := writer.FileName(sp.spec, part)
+			// Close writer to ensure buffer and any compression footer is flushed.
+			err = writer.Close()
+			if err != nil {
+				return errors.Wrapf(err, "failed to close exporting writer")
+			}
+
+			size := writer.Len()
+
+			if err := cloud.WriteFile(ctx, es, filename, bytes.NewReader(writer.Bytes())); err != nil {
+				return err
+			}
+			res := rowenc.EncDatumRow{
+				rowenc.DatumToEncDatum(
+					types.String,
+					tree.NewDString(filename),
+				),
+				rowenc.DatumToEncDatum(
+					types.Int,
+					tree.NewDInt(tree.DInt(rows)),
+				),
+				rowenc.DatumToEncDatum(
+					types.Int,
+					tree.NewDInt(tree.DInt(size)),
+				),
+			}
+
+			cs, err := sp.out.EmitRow(ctx, res, sp.output)
+			if err != nil {
+				return err
+			}
+			if cs != execinfra.NeedMoreRows {
+				return errors.New("unexpected closure of consumer")
+			}
+			if done {
+				break
+			}
+		}
+
+		return nil
+	}()
+
+	execinfra.DrainAndClose(
+		ctx, sp.output, err, func(context.Context) {} /* pushTrailingMeta */, sp.input)
+}
+
+func init() {
+	rowexec.NewJSONWriterProcessor = newJSONWriterProcessor
+}
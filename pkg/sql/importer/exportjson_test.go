@@ -0,0 +1,80 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package importer_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE foo (i INT PRIMARY KEY, s STRING, d DECIMAL, j JSONB, a INT[])`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES
+		(1, 'a', 1.50, '{"k": "v"}', ARRAY[1, 2]),
+		(2, NULL, NULL, NULL, NULL)`)
+	const expected = `{"a": [1, 2], "d": 1.50, "i": 1, "j": {"k": "v"}, "s": "a"}
+{"a": null, "d": null, "i": 2, "j": null, "s": null}
+`
+
+	t.Run("uncompressed", func(t *testing.T) {
+		sqlDB.Exec(t, `EXPORT INTO JSON 'nodelocal://0/json' FROM SELECT * FROM foo ORDER BY i`)
+		content := readFileByGlob(t, filepath.Join(dir, "json", "export*-n*.0.json"))
+		require.Equal(t, expected, string(content))
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		sqlDB.Exec(t, `EXPORT INTO JSON 'nodelocal://0/json-gz' WITH compression = gzip FROM SELECT * FROM foo ORDER BY i`)
+		compressed := readFileByGlob(t, filepath.Join(dir, "json-gz", "export*-n*.0.json.gz"))
+		r, err := gzip.NewReader(bytes.NewReader(compressed))
+		require.NoError(t, err)
+		content, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		require.Equal(t, expected, string(content))
+	})
+
+	t.Run("chunk rows", func(t *testing.T) {
+		sqlDB.CheckQueryResults(t,
+			`SELECT rows FROM [EXPORT INTO JSON 'nodelocal://0/json-chunked' WITH chunk_rows = '1' FROM SELECT * FROM foo]`,
+			[][]string{{"1"}, {"1"}})
+	})
+
+	t.Run("duplicate column names", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `duplicate column name "i"`,
+			`EXPORT INTO JSON 'nodelocal://0/json-dup' FROM SELECT i, i FROM foo`)
+	})
+
+	t.Run("unsupported compression", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `unsupported compression codec snappy for json file format`,
+			`EXPORT INTO JSON 'nodelocal://0/json-snappy' WITH compression = snappy FROM SELECT * FROM foo`)
+	})
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/buildutil"
+	"github.com/cockroachdb/cockroach/pkg/util/cputime"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
@@ -129,6 +130,10 @@ type instrumentationHelper struct {
 	// indexRecommendations is a string slice containing index recommendations for
 	// the planned statement. This is only set for EXPLAIN statements.
 	indexRecommendations []string
+
+	// cpuWatch measures the CPU time spent by the statement on the gateway. It
+	// is only running when execution statistics are collected.
+	cpuWatch cputime.Stopwatch
 }
 
 // outputMode indicates how the statement output needs to be populated (for
@@ -163,6 +168,13 @@ func (ih *instrumentationHelper) Setup(
 	implicitTxn bool,
 	collectTxnExecStats bool,
 ) (newCtx context.Context, needFinish bool) {
+	defer func() {
+		if needFinish && ih.collectExecStats {
+			// The watch is stopped in Finish, which runs on the same goroutine.
+			ih.cpuWatch.Start()
+		}
+	}()
+
 	ih.fingerprint = fingerprint
 	ih.implicitTxn = implicitTxn
 	ih.codec = cfg.Codec
@@ -254,6 +266,7 @@ func (ih *instrumentationHelper) Finish(
 	retErr error,
 ) error {
 	ctx := ih.origCtx
+	gatewayCPUTime := ih.cpuWatch.Stop()
 	if ih.sp == nil {
 		return retErr
 	}
@@ -293,6 +306,9 @@ func (ih *instrumentationHelper) Finish(
 		}
 		log.VInfof(ctx, 1, msg, ih.fingerprint, err)
 	} else {
+		// The CPU time of the remote flows is reported through the trace; add
+		// the time spent on the gateway.
+		queryLevelStats.CPUTime += gatewayCPUTime
 		stmtStatsKey := roachpb.StatementStatisticsKey{
 			Query:       ih.fingerprint,
 			ImplicitTxn: ih.implicitTxn,
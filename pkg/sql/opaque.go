@@ -343,6 +343,8 @@ func init() {
 		&tree.Import{},
 		&tree.ScheduledBackup{},
 		&tree.StreamIngestion{},
+		&tree.VerifyBackup{},
+		&tree.ScheduledVerifyBackup{},
 	} {
 		typ := optbuilder.OpaqueReadOnly
 		if tree.CanModifySchema(stmt) {
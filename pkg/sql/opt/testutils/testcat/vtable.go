@@ -124,6 +124,7 @@ var systemTables = []string{
 	systemschema.SpanConfigurationsTableSchema,
 	systemschema.TenantSettingsTableSchema,
 	systemschema.SpanCountTableSchema,
+	systemschema.HotRangesHistoryTableSchema,
 }
 
 func init() {
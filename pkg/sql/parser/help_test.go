@@ -491,6 +491,9 @@ func TestContextualHelp(t *testing.T) {
 		{`EXPORT INTO CSV 'a' ??`, `EXPORT`},
 		{`EXPORT INTO CSV 'a' FROM SELECT a ??`, `SELECT`},
 		{`CREATE SCHEDULE FOR BACKUP ??`, `CREATE SCHEDULE FOR BACKUP`},
+		{`CREATE SCHEDULE FOR VERIFY BACKUP ??`, `CREATE SCHEDULE FOR VERIFY BACKUP`},
+		{`VERIFY BACKUP ??`, `VERIFY BACKUP`},
+		{`VERIFY BACKUP FROM LATEST IN 'foo' ??`, `VERIFY BACKUP`},
 	}
 
 	// The following checks that the test definition above exercises all
@@ -820,7 +820,7 @@ func (u *sqlSymUnion) asTenantClause() tree.TenantID {
 
 %token <str> DATA DATABASE DATABASES DATE DAY DEBUG_PAUSE_ON DEC DECIMAL DEFAULT DEFAULTS
 %token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DELIMITER DESC DESTINATION DETACHED
-%token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
+%token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP DRY_RUN
 
 %token <str> ELSE ENCODING ENCRYPTED ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
 %token <str> EXISTS EXECUTE EXECUTION EXPERIMENTAL
@@ -900,7 +900,7 @@ func (u *sqlSymUnion) asTenantClause() tree.TenantID {
 %token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
 %token <str> UPDATE UPSERT UNSET UNTIL USE USER USERS USING UUID
 
-%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIEWACTIVITYREDACTED
+%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VERIFY VIEW VARYING VIEWACTIVITY VIEWACTIVITYREDACTED
 %token <str> VIEWCLUSTERSETTING VIRTUAL VISIBLE VOTERS
 
 %token <str> WHEN WHERE WINDOW WITH WITHIN WITHOUT WORK WRITE
@@ -1048,6 +1048,7 @@ func (u *sqlSymUnion) asTenantClause() tree.TenantID {
 %type <tree.Statement> create_index_stmt
 %type <tree.Statement> create_role_stmt
 %type <tree.Statement> create_schedule_for_backup_stmt
+%type <tree.Statement> create_schedule_for_verify_backup_stmt
 %type <tree.Statement> create_schema_stmt
 %type <tree.Statement> create_table_stmt
 %type <tree.Statement> create_table_as_stmt
@@ -1167,6 +1168,7 @@ func (u *sqlSymUnion) asTenantClause() tree.TenantID {
 %type <tree.Statement> truncate_stmt
 %type <tree.Statement> update_stmt
 %type <tree.Statement> upsert_stmt
+%type <tree.Statement> verify_backup_stmt
 %type <tree.Statement> use_stmt
 
 %type <tree.Statement> close_cursor_stmt
@@ -1643,6 +1645,7 @@ alter_ddl_stmt:
 //   ALTER TABLE ... RENAME [COLUMN] <colname> TO <newname>
 //   ALTER TABLE ... VALIDATE CONSTRAINT <constraintname>
 //   ALTER TABLE ... SET (storage_param = value, ...)
+//   ALTER TABLE ... SET READ { ONLY | WRITE }
 //   ALTER TABLE ... SPLIT AT <selectclause> [WITH EXPIRATION <expr>]
 //   ALTER TABLE ... UNSPLIT AT <selectclause>
 //   ALTER TABLE ... UNSPLIT ALL
@@ -2476,6 +2479,16 @@ alter_table_cmd:
   {
     $$.val = &tree.AlterTableSetAudit{Mode: $3.auditMode()}
   }
+  // ALTER TABLE <name> SET READ ONLY
+| SET READ ONLY
+  {
+    $$.val = &tree.AlterTableSetReadOnly{ReadOnly: true}
+  }
+  // ALTER TABLE <name> SET READ WRITE
+| SET READ WRITE
+  {
+    $$.val = &tree.AlterTableSetReadOnly{ReadOnly: false}
+  }
   // ALTER TABLE <name> PARTITION BY ...
 | partition_by_table
   {
@@ -3023,6 +3036,46 @@ create_schedule_for_backup_stmt:
   }
  | CREATE SCHEDULE error  // SHOW HELP: CREATE SCHEDULE FOR BACKUP
 
+// %Help: CREATE SCHEDULE FOR VERIFY BACKUP - verify a backup periodically
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE [IF NOT EXISTS]
+// [<description>]
+// FOR VERIFY BACKUP FROM <subdir> IN <collection...>
+// [WITH <option> [= <value>] [, ...]]
+// RECURRING <crontab>
+// [WITH SCHEDULE OPTIONS <schedule_option>=<value> [, ...] ]
+//
+// WITH <options>:
+//   Options specific to VERIFY BACKUP: See VERIFY BACKUP options
+//
+// RECURRING <crontab>:
+//   Schedule specified as a string in crontab format.
+//   All times in UTC.
+//
+//  SCHEDULE OPTIONS:
+//   The schedule accepts the first_run, on_execution_failure and
+//   on_previous_running options. See CREATE SCHEDULE FOR BACKUP.
+//
+// %SeeAlso: VERIFY BACKUP, CREATE SCHEDULE FOR BACKUP
+create_schedule_for_verify_backup_stmt:
+  CREATE SCHEDULE /*$3=*/schedule_label_spec FOR VERIFY BACKUP FROM /*$8=*/string_or_placeholder
+  IN /*$10=*/string_or_placeholder_opt_list /*$11=*/opt_with_options
+  /*$12=*/cron_expr /*$13=*/opt_with_schedule_options
+  {
+    $$.val = &tree.ScheduledVerifyBackup{
+      ScheduleLabelSpec: *($3.scheduleLabelSpec()),
+      Recurrence:        $12.expr(),
+      Verify: &tree.VerifyBackup{
+        Subdir:       $8.expr(),
+        InCollection: $10.stringOrPlaceholderOptList(),
+        Options:      $11.kvOptions(),
+      },
+      ScheduleOptions: $13.kvOptions(),
+    }
+  }
+| CREATE SCHEDULE schedule_label_spec FOR VERIFY error  // SHOW HELP: CREATE SCHEDULE FOR VERIFY BACKUP
+
 // sconst_or_placeholder matches a simple string, or a placeholder.
 sconst_or_placeholder:
   SCONST
@@ -3120,6 +3173,7 @@ opt_with_schedule_options:
 //    skip_localities_check: ignore difference of zone configuration between restore cluster and backup cluster
 //    debug_pause_on: describes the events that the job should pause itself on for debugging purposes.
 //    new_db_name: renames the restored database. only applies to database restores
+//    dry_run: plan and validate the restore, reporting what it would restore without writing any data
 // %SeeAlso: BACKUP, WEBDOCS/restore.html
 restore_stmt:
   RESTORE FROM list_of_string_or_placeholder_opt_list opt_as_of_clause opt_with_restore_options
@@ -3314,6 +3368,10 @@ restore_options:
   {
     $$.val = &tree.RestoreOptions{AsTenant: $3.expr()}
   }
+| DRY_RUN
+  {
+    $$.val = &tree.RestoreOptions{DryRun: true}
+  }
 
 import_format:
   name
@@ -3770,6 +3828,7 @@ create_stmt:
 | create_ddl_stmt      // help texts in sub-rule
 | create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
 | create_schedule_for_backup_stmt   // EXTEND WITH HELP: CREATE SCHEDULE FOR BACKUP
+| create_schedule_for_verify_backup_stmt   // EXTEND WITH HELP: CREATE SCHEDULE FOR VERIFY BACKUP
 | create_changefeed_stmt
 | create_extension_stmt  // EXTEND WITH HELP: CREATE EXTENSION
 | create_unsupported   {}
@@ -4423,6 +4482,7 @@ preparable_stmt:
 | truncate_stmt     // EXTEND WITH HELP: TRUNCATE
 | update_stmt       // EXTEND WITH HELP: UPDATE
 | upsert_stmt       // EXTEND WITH HELP: UPSERT
+| verify_backup_stmt // EXTEND WITH HELP: VERIFY BACKUP
 
 // These are statements that can be used as a data source using the special
 // syntax with brackets. These are a subset of preparable_stmt.
@@ -4530,6 +4590,37 @@ alter_backup_stmt:
 	}
 | ALTER BACKUP error // SHOW HELP: ALTER BACKUP
 
+// %Help: VERIFY BACKUP - check the integrity of a backup
+// %Category: CCL
+// %Text:
+// VERIFY BACKUP FROM <subdir> IN <collection...>
+//        [ WITH <option> [= <value>] [, ...] ]
+//
+// Reads every file in the backup chain ending at <subdir>, checking it against
+// the checksum recorded when it was written, and checks that the keys in the
+// files cover the spans and match the statistics recorded in the manifests.
+//
+// Collection:
+//    "[scheme]://[host]/[path to backup collection]?[parameters]"
+//
+// Options:
+//    encryption_passphrase = '...'
+//    kms = '...'
+//    incremental_location = '...'
+//    detached
+//
+// %SeeAlso: SHOW BACKUP, CREATE SCHEDULE FOR VERIFY BACKUP
+verify_backup_stmt:
+  VERIFY BACKUP FROM string_or_placeholder IN string_or_placeholder_opt_list opt_with_options
+  {
+    $$.val = &tree.VerifyBackup{
+      Subdir:       $4.expr(),
+      InCollection: $6.stringOrPlaceholderOptList(),
+      Options:      $7.kvOptions(),
+    }
+  }
+| VERIFY BACKUP error // SHOW HELP: VERIFY BACKUP
+
 alter_backup_cmds:
 	alter_backup_cmd
 	{
@@ -14117,6 +14208,7 @@ unreserved_keyword:
 | DOMAIN
 | DOUBLE
 | DROP
+| DRY_RUN
 | ENCODING
 | ENCRYPTED
 | ENCRYPTION_PASSPHRASE
@@ -14422,6 +14514,7 @@ unreserved_keyword:
 | VALIDATE
 | VALUE
 | VARYING
+| VERIFY
 | VIEW
 | VIEWACTIVITY
 | VIEWACTIVITYREDACTED
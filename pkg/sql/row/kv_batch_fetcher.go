@@ -136,6 +136,9 @@ type txnKVFetcher struct {
 	reverse bool
 	// lockStrength represents the locking mode to use when fetching KVs.
 	lockStrength lock.Strength
+	// lockDurability represents the locking durability to use when fetching
+	// KVs.
+	lockDurability lock.Durability
 	// lockWaitPolicy represents the policy to be used for handling conflicting
 	// locks held by other active transactions.
 	lockWaitPolicy lock.WaitPolicy
@@ -329,6 +332,7 @@ func makeKVBatchFetcher(ctx context.Context, args kvBatchFetcherArgs) (txnKVFetc
 		batchBytesLimit:            args.batchBytesLimit,
 		firstBatchKeyLimit:         args.firstBatchKeyLimit,
 		lockStrength:               getKeyLockingStrength(args.lockStrength),
+		lockDurability:             getKeyLockingDurability(args.lockStrength),
 		lockWaitPolicy:             GetWaitPolicy(args.lockWaitPolicy),
 		lockTimeout:                args.lockTimeout,
 		acc:                        args.acc,
@@ -382,7 +386,7 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	ba.Header.TargetBytes = int64(f.batchBytesLimit)
 	ba.Header.MaxSpanRequestKeys = int64(f.getBatchKeyLimit())
 	ba.AdmissionHeader = f.requestAdmissionHeader
-	ba.Requests = spansToRequests(f.spans.Spans, f.reverse, f.lockStrength)
+	ba.Requests = spansToRequests(f.spans.Spans, f.reverse, f.lockStrength, f.lockDurability)
 
 	if log.ExpensiveLogEnabled(ctx, 2) {
 		log.VEventf(ctx, 2, "Scan %s", f.spans)
@@ -618,7 +622,10 @@ func (f *txnKVFetcher) close(ctx context.Context) {
 // otherwise, a Scan (or ReverseScan if reverse is true) request is used with
 // BATCH_RESPONSE format.
 func spansToRequests(
-	spans roachpb.Spans, reverse bool, keyLocking lock.Strength,
+	spans roachpb.Spans,
+	reverse bool,
+	keyLocking lock.Strength,
+	keyLockingDurability lock.Durability,
 ) []roachpb.RequestUnion {
 	reqs := make([]roachpb.RequestUnion, len(spans))
 	// Detect the number of gets vs scans, so we can batch allocate all of the
@@ -647,6 +654,7 @@ func spansToRequests(
 				// single key fetch, which can be served using a GetRequest.
 				gets[curGet].req.Key = spans[i].Key
 				gets[curGet].req.KeyLocking = keyLocking
+				gets[curGet].req.KeyLockingDurability = keyLockingDurability
 				gets[curGet].union.Get = &gets[curGet].req
 				reqs[i].Value = &gets[curGet].union
 				curGet++
@@ -656,6 +664,7 @@ func spansToRequests(
 			scans[curScan].req.SetSpan(spans[i])
 			scans[curScan].req.ScanFormat = roachpb.BATCH_RESPONSE
 			scans[curScan].req.KeyLocking = keyLocking
+			scans[curScan].req.KeyLockingDurability = keyLockingDurability
 			scans[curScan].union.ReverseScan = &scans[curScan].req
 			reqs[i].Value = &scans[curScan].union
 		}
@@ -670,6 +679,7 @@ func spansToRequests(
 				// single key fetch, which can be served using a GetRequest.
 				gets[curGet].req.Key = spans[i].Key
 				gets[curGet].req.KeyLocking = keyLocking
+				gets[curGet].req.KeyLockingDurability = keyLockingDurability
 				gets[curGet].union.Get = &gets[curGet].req
 				reqs[i].Value = &gets[curGet].union
 				curGet++
@@ -679,6 +689,7 @@ func spansToRequests(
 			scans[curScan].req.SetSpan(spans[i])
 			scans[curScan].req.ScanFormat = roachpb.BATCH_RESPONSE
 			scans[curScan].req.KeyLocking = keyLocking
+			scans[curScan].req.KeyLockingDurability = keyLockingDurability
 			scans[curScan].union.Scan = &scans[curScan].req
 			reqs[i].Value = &scans[curScan].union
 		}
@@ -68,7 +68,8 @@ func NewTxnKVStreamer(
 		log.VEventf(ctx, 2, "Scan %s", spans)
 	}
 	keyLocking := getKeyLockingStrength(lockStrength)
-	reqs := spansToRequests(spans, false /* reverse */, keyLocking)
+	keyLockingDurability := getKeyLockingDurability(lockStrength)
+	reqs := spansToRequests(spans, false /* reverse */, keyLocking, keyLockingDurability)
 	if err := streamer.Enqueue(ctx, reqs); err != nil {
 		return nil, err
 	}
@@ -27,9 +27,10 @@ func getKeyLockingStrength(lockStrength descpb.ScanLockingStrength) lock.Strengt
 		// Promote to FOR_SHARE.
 		fallthrough
 	case descpb.ScanLockingStrength_FOR_SHARE:
-		// We currently perform no per-key locking when FOR_SHARE is used
-		// because Shared locks have not yet been implemented.
-		return lock.None
+		// Until the SharedLocks cluster version is active, the KV client
+		// downgrades the requests to acquire no locks, because nodes that
+		// predate Shared locks cannot handle them.
+		return lock.Shared
 
 	case descpb.ScanLockingStrength_FOR_NO_KEY_UPDATE:
 		// Promote to FOR_UPDATE.
@@ -44,6 +45,22 @@ func getKeyLockingStrength(lockStrength descpb.ScanLockingStrength) lock.Strengt
 	}
 }
 
+// getKeyLockingDurability returns the configured per-key locking durability to
+// use for key-value scans.
+func getKeyLockingDurability(lockStrength descpb.ScanLockingStrength) lock.Durability {
+	switch lockStrength {
+	case descpb.ScanLockingStrength_FOR_KEY_SHARE, descpb.ScanLockingStrength_FOR_SHARE:
+		// Shared locks are replicated so that they are not lost on lease
+		// transfers and range merges. Until the ReplicatedSharedLocks cluster
+		// version is active, the KV client downgrades the requests to acquire
+		// unreplicated locks.
+		return lock.Replicated
+
+	default:
+		return lock.Unreplicated
+	}
+}
+
 // GetWaitPolicy returns the configured lock wait policy to use for key-value
 // scans.
 func GetWaitPolicy(lockWaitPolicy descpb.ScanLockingWaitPolicy) lock.WaitPolicy {
@@ -281,8 +281,16 @@ func NewProcessor(
 			return nil, err
 		}
 
-		if core.Exporter.Format.Format == roachpb.IOFileFormat_Parquet {
+		switch core.Exporter.Format.Format {
+		case roachpb.IOFileFormat_Parquet:
 			return NewParquetWriterProcessor(flowCtx, processorID, *core.Exporter, inputs[0], outputs[0])
+		case roachpb.IOFileFormat_JSON:
+			return NewJSONWriterProcessor(flowCtx, processorID, *core.Exporter, inputs[0], outputs[0])
+		case roachpb.IOFileFormat_Avro:
+			if NewAvroWriterProcessor == nil {
+				return nil, errors.New("AvroWriter processor unimplemented")
+			}
+			return NewAvroWriterProcessor(flowCtx, processorID, *core.Exporter, inputs[0], outputs[0])
 		}
 		return NewCSVWriterProcessor(flowCtx, processorID, *core.Exporter, inputs[0], outputs[0])
 	}
@@ -397,6 +405,12 @@ var NewCSVWriterProcessor func(*execinfra.FlowCtx, int32, execinfrapb.ExportSpec
 // NewParquetWriterProcessor is implemented in the non-free (CCL) codebase and then injected here via runtime initialization.
 var NewParquetWriterProcessor func(*execinfra.FlowCtx, int32, execinfrapb.ExportSpec, execinfra.RowSource, execinfra.RowReceiver) (execinfra.Processor, error)
 
+// NewJSONWriterProcessor is implemented in the importer package and then injected here via runtime initialization.
+var NewJSONWriterProcessor func(*execinfra.FlowCtx, int32, execinfrapb.ExportSpec, execinfra.RowSource, execinfra.RowReceiver) (execinfra.Processor, error)
+
+// NewAvroWriterProcessor is implemented in the non-free (CCL) codebase and then injected here via runtime initialization.
+var NewAvroWriterProcessor func(*execinfra.FlowCtx, int32, execinfrapb.ExportSpec, execinfra.RowSource, execinfra.RowReceiver) (execinfra.Processor, error)
+
 // NewChangeAggregatorProcessor is implemented in the non-free (CCL) codebase and then injected here via runtime initialization.
 var NewChangeAggregatorProcessor func(*execinfra.FlowCtx, int32, execinfrapb.ChangeAggregatorSpec, *execinfrapb.PostProcessSpec, execinfra.RowReceiver) (execinfra.Processor, error)
 
@@ -81,6 +81,7 @@ const (
 	SpanConfigurationsTableName            SystemTableName = "span_configurations"
 	TenantSettingsTableName                SystemTableName = "tenant_settings"
 	SpanCountTableName                     SystemTableName = "span_count"
+	HotRangesHistoryTableName              SystemTableName = "hot_ranges_history"
 )
 
 // Oid for virtual database and table.
@@ -156,6 +157,8 @@ const (
 	CrdbInternalTenantUsageDetailsViewID
 	CrdbInternalPgCatalogTableIsImplementedTableID
 	CrdbInternalSuperRegions
+	CrdbInternalKVStorageQuotasTableID
+	CrdbInternalClusterLockWaitsTableID
 	InformationSchemaID
 	InformationSchemaAdministrableRoleAuthorizationsID
 	InformationSchemaApplicableRolesID
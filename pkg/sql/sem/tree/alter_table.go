@@ -68,6 +68,7 @@ func (*AlterTableSetNotNull) alterTableCmd()         {}
 func (*AlterTableRenameColumn) alterTableCmd()       {}
 func (*AlterTableRenameConstraint) alterTableCmd()   {}
 func (*AlterTableSetAudit) alterTableCmd()           {}
+func (*AlterTableSetReadOnly) alterTableCmd()        {}
 func (*AlterTableSetDefault) alterTableCmd()         {}
 func (*AlterTableSetOnUpdate) alterTableCmd()        {}
 func (*AlterTableSetVisible) alterTableCmd()         {}
@@ -88,6 +89,7 @@ var _ AlterTableCmd = &AlterTableSetNotNull{}
 var _ AlterTableCmd = &AlterTableRenameColumn{}
 var _ AlterTableCmd = &AlterTableRenameConstraint{}
 var _ AlterTableCmd = &AlterTableSetAudit{}
+var _ AlterTableCmd = &AlterTableSetReadOnly{}
 var _ AlterTableCmd = &AlterTableSetDefault{}
 var _ AlterTableCmd = &AlterTableSetOnUpdate{}
 var _ AlterTableCmd = &AlterTableSetVisible{}
@@ -589,6 +591,29 @@ func (node *AlterTableSetAudit) Format(ctx *FmtCtx) {
 	ctx.WriteString(node.Mode.String())
 }
 
+// AlterTableSetReadOnly represents an ALTER TABLE SET READ ONLY or ALTER TABLE
+// SET READ WRITE command.
+type AlterTableSetReadOnly struct {
+	ReadOnly bool
+}
+
+// TelemetryName implements the AlterTableCmd interface.
+func (node *AlterTableSetReadOnly) TelemetryName() string {
+	if node.ReadOnly {
+		return "set_read_only"
+	}
+	return "set_read_write"
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetReadOnly) Format(ctx *FmtCtx) {
+	if node.ReadOnly {
+		ctx.WriteString(" SET READ ONLY")
+	} else {
+		ctx.WriteString(" SET READ WRITE")
+	}
+}
+
 // AlterTableInjectStats represents an ALTER TABLE INJECT STATISTICS statement.
 type AlterTableInjectStats struct {
 	Stats Expr
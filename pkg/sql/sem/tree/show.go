@@ -794,6 +794,10 @@ const (
 	// ScheduledRowLevelTTLExecutor is an executor responsible for the cleanup
 	// of rows on row level TTL tables.
 	ScheduledRowLevelTTLExecutor
+
+	// ScheduledVerifyBackupExecutor is an executor responsible for the
+	// execution of scheduled backup verification.
+	ScheduledVerifyBackupExecutor
 )
 
 var scheduleExecutorInternalNames = map[ScheduledJobExecutorType]string{
@@ -801,6 +805,7 @@ var scheduleExecutorInternalNames = map[ScheduledJobExecutorType]string{
 	ScheduledBackupExecutor:             "scheduled-backup-executor",
 	ScheduledSQLStatsCompactionExecutor: "scheduled-sql-stats-compaction-executor",
 	ScheduledRowLevelTTLExecutor:        "scheduled-row-level-ttl-executor",
+	ScheduledVerifyBackupExecutor:       "scheduled-verify-backup-executor",
 }
 
 // InternalName returns an internal executor name.
@@ -818,6 +823,8 @@ func (t ScheduledJobExecutorType) UserName() string {
 		return "SQL STATISTICS"
 	case ScheduledRowLevelTTLExecutor:
 		return "ROW LEVEL TTL"
+	case ScheduledVerifyBackupExecutor:
+		return "VERIFY BACKUP"
 	}
 	return "unsupported-executor"
 }
@@ -177,6 +177,8 @@ var _ CCLOnlyStatement = &Import{}
 var _ CCLOnlyStatement = &Export{}
 var _ CCLOnlyStatement = &ScheduledBackup{}
 var _ CCLOnlyStatement = &StreamIngestion{}
+var _ CCLOnlyStatement = &VerifyBackup{}
+var _ CCLOnlyStatement = &ScheduledVerifyBackup{}
 
 // StatementReturnType implements the Statement interface.
 func (*AlterChangefeed) StatementReturnType() StatementReturnType { return Rows }
@@ -462,6 +464,19 @@ func (*ScheduledBackup) cclOnlyStatement() {}
 
 func (*ScheduledBackup) hiddenFromShowQueries() {}
 
+// StatementReturnType implements the Statement interface.
+func (*ScheduledVerifyBackup) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*ScheduledVerifyBackup) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ScheduledVerifyBackup) StatementTag() string { return "SCHEDULED VERIFY BACKUP" }
+
+func (*ScheduledVerifyBackup) cclOnlyStatement() {}
+
+func (*ScheduledVerifyBackup) hiddenFromShowQueries() {}
+
 // StatementReturnType implements the Statement interface.
 func (*BeginTransaction) StatementReturnType() StatementReturnType { return Ack }
 
@@ -1781,6 +1796,19 @@ func (*ValuesClause) StatementType() StatementType { return TypeDML }
 // StatementTag returns a short string identifying the type of statement.
 func (*ValuesClause) StatementTag() string { return "VALUES" }
 
+// StatementReturnType implements the Statement interface.
+func (*VerifyBackup) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*VerifyBackup) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*VerifyBackup) StatementTag() string { return "VERIFY BACKUP" }
+
+func (*VerifyBackup) cclOnlyStatement() {}
+
+func (*VerifyBackup) hiddenFromShowQueries() {}
+
 func (n *AlterChangefeed) String() string                { return AsString(n) }
 func (n *AlterChangefeedCmds) String() string            { return AsString(n) }
 func (n *AlterBackup) String() string                    { return AsString(n) }
@@ -1887,6 +1915,7 @@ func (n *RollbackTransaction) String() string            { return AsString(n) }
 func (n *Savepoint) String() string                      { return AsString(n) }
 func (n *Scatter) String() string                        { return AsString(n) }
 func (n *ScheduledBackup) String() string                { return AsString(n) }
+func (n *ScheduledVerifyBackup) String() string          { return AsString(n) }
 func (n *Scrub) String() string                          { return AsString(n) }
 func (n *Select) String() string                         { return AsString(n) }
 func (n *SelectClause) String() string                   { return AsString(n) }
@@ -1953,3 +1982,4 @@ func (n *Truncate) String() string                       { return AsString(n) }
 func (n *UnionClause) String() string                    { return AsString(n) }
 func (n *Update) String() string                         { return AsString(n) }
 func (n *ValuesClause) String() string                   { return AsString(n) }
+func (n *VerifyBackup) String() string                   { return AsString(n) }
@@ -0,0 +1,68 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// VerifyBackup represents a VERIFY BACKUP statement.
+type VerifyBackup struct {
+	// Subdir is the backup in the collection to verify, e.g. 'LATEST'.
+	Subdir       Expr
+	InCollection StringOrPlaceholderOptList
+	Options      KVOptions
+}
+
+var _ Statement = &VerifyBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *VerifyBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("VERIFY BACKUP FROM ")
+	ctx.FormatNode(node.Subdir)
+	ctx.WriteString(" IN ")
+	ctx.FormatNode(&node.InCollection)
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
+// ScheduledVerifyBackup represents a schedule that periodically verifies a
+// backup.
+type ScheduledVerifyBackup struct {
+	ScheduleLabelSpec ScheduleLabelSpec
+	Recurrence        Expr
+	Verify            *VerifyBackup
+	ScheduleOptions   KVOptions
+}
+
+var _ Statement = &ScheduledVerifyBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *ScheduledVerifyBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE SCHEDULE")
+
+	if node.ScheduleLabelSpec.IfNotExists {
+		ctx.WriteString(" IF NOT EXISTS")
+	}
+	if node.ScheduleLabelSpec.Label != nil {
+		ctx.WriteString(" ")
+		ctx.FormatNode(node.ScheduleLabelSpec.Label)
+	}
+
+	ctx.WriteString(" FOR ")
+	ctx.FormatNode(node.Verify)
+
+	ctx.WriteString(" RECURRING ")
+	ctx.FormatNode(node.Recurrence)
+
+	if node.ScheduleOptions != nil {
+		ctx.WriteString(" WITH SCHEDULE OPTIONS ")
+		ctx.FormatNode(&node.ScheduleOptions)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -104,6 +105,30 @@ var supportedZoneConfigOptions = map[tree.Name]struct {
 			c.GC = &zonepb.GCPolicy{TTLSeconds: int32(tree.MustBeDInt(d))}
 		},
 	},
+	"storage_quota_bytes": {
+		requiredType: types.Int,
+		setter: func(c *zonepb.ZoneConfig, d tree.Datum) {
+			c.StorageQuotaBytes = proto.Int64(int64(tree.MustBeDInt(d)))
+		},
+	},
+	"gc.retention_tiers": {
+		requiredType: types.String,
+		setter: func(c *zonepb.ZoneConfig, d tree.Datum) {
+			var retention zonepb.GCRetentionPolicy
+			if err := retention.FromString(string(tree.MustBeDString(d))); err != nil {
+				panic(pgerror.WithCandidateCode(err, pgcode.InvalidParameterValue))
+			}
+			c.GCRetention = &retention
+		},
+		checkAllowed: func(ctx context.Context, execCfg *ExecutorConfig, _ tree.Datum) error {
+			if !execCfg.Settings.Version.IsActive(ctx, clusterversion.MVCCGCRetentionTiers) {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"gc.retention_tiers requires all nodes to be upgraded to %s",
+					clusterversion.ByKey(clusterversion.MVCCGCRetentionTiers))
+			}
+			return nil
+		},
+	},
 	"constraints": {
 		requiredType: types.String,
 		setter: func(c *zonepb.ZoneConfig, d tree.Datum) {
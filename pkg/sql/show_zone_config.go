@@ -243,6 +243,14 @@ func zoneConfigToSQL(zs *tree.ZoneSpecifier, zone *zonepb.ZoneConfig) (string, e
 		maybeWriteComma(f)
 		f.Printf("\tlease_preferences = %s", lexbase.EscapeSQLString(prefs))
 	}
+	if zone.StorageQuotaBytes != nil {
+		maybeWriteComma(f)
+		f.Printf("\tstorage_quota_bytes = %d", *zone.StorageQuotaBytes)
+	}
+	if zone.GCRetention != nil {
+		maybeWriteComma(f)
+		f.Printf("\tgc.retention_tiers = %s", lexbase.EscapeSQLString(zone.GCRetention.String()))
+	}
 	return f.String(), nil
 }
 
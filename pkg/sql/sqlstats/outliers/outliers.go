@@ -35,6 +35,18 @@ var LatencyThreshold = settings.RegisterDurationSetting(
 	0,
 )
 
+// AutoDiagnostics configures whether a statement diagnostics request is armed
+// for the fingerprint of each detected outlier, so that a bundle is collected
+// for the next execution of the fingerprint. The number of requests armed
+// this way is limited cluster-wide by the stmtdiagnostics package.
+var AutoDiagnostics = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.stats.outliers.experimental.auto_diagnostics.enabled",
+	"if set, a statement diagnostics bundle is collected for the next execution "+
+		"of the fingerprint of each detected outlier",
+	false,
+)
+
 // maxCacheSize is the number of detected outliers we will retain in memory.
 // We choose a small value for the time being to allow us to iterate without
 // worrying about memory usage. See #79450.
@@ -42,11 +54,24 @@ const (
 	maxCacheSize = 10
 )
 
+// DiagnosticsRequester arms statement diagnostics requests on behalf of the
+// outliers subsystem.
+type DiagnosticsRequester interface {
+	// RequestOutlierDiagnostics asynchronously arms a request to collect
+	// diagnostics for the next execution of the given fingerprint. If the
+	// request is armed, onArmed is called with its ID. The request may be
+	// dropped, for example because of rate limiting. RequestOutlierDiagnostics
+	// must not block, and must not call onArmed synchronously.
+	RequestOutlierDiagnostics(fingerprint string, onArmed func(requestID int64))
+}
+
 // Registry is the central object in the outliers subsystem. It observes
 // statement execution to determine which statements are outliers and
 // exposes the set of currently retained outliers.
 type Registry struct {
-	detector detector
+	st          *cluster.Settings
+	detector    detector
+	diagnostics DiagnosticsRequester
 
 	// Note that this single mutex places unnecessary constraints on outlier
 	// detection and reporting. We will develop a higher-throughput system
@@ -58,15 +83,20 @@ type Registry struct {
 	}
 }
 
-// New builds a new Registry.
-func New(st *cluster.Settings) *Registry {
+// New builds a new Registry. diagnostics, if not nil, is used to collect
+// diagnostics for the fingerprints of the detected outliers.
+func New(st *cluster.Settings, diagnostics DiagnosticsRequester) *Registry {
 	config := cache.Config{
 		Policy: cache.CacheFIFO,
 		ShouldEvict: func(size int, key, value interface{}) bool {
 			return size > maxCacheSize
 		},
 	}
-	r := &Registry{detector: anyDetector{detectors: []detector{latencyThresholdDetector{st: st}}}}
+	r := &Registry{
+		st:          st,
+		detector:    anyDetector{detectors: []detector{latencyThresholdDetector{st: st}}},
+		diagnostics: diagnostics,
+	}
 	r.mu.statements = make(map[clusterunique.ID][]*Outlier_Statement)
 	r.mu.outliers = cache.NewUnorderedCache(config)
 	return r
@@ -77,6 +107,7 @@ func (r *Registry) ObserveStatement(
 	sessionID clusterunique.ID,
 	statementID clusterunique.ID,
 	statementFingerprintID roachpb.StmtFingerprintID,
+	statementFingerprint string,
 	latencyInSeconds float64,
 ) {
 	if !r.enabled() {
@@ -88,6 +119,7 @@ func (r *Registry) ObserveStatement(
 		ID:               statementID.GetBytes(),
 		FingerprintID:    statementFingerprintID,
 		LatencyInSeconds: latencyInSeconds,
+		Fingerprint:      statementFingerprint,
 	})
 }
 
@@ -101,14 +133,14 @@ func (r *Registry) ObserveTransaction(sessionID clusterunique.ID, txnID uuid.UUI
 	statements := r.mu.statements[sessionID]
 	delete(r.mu.statements, sessionID)
 
-	hasOutlier := false
+	var outlierStatements []*Outlier_Statement
 	for _, s := range statements {
 		if r.detector.isOutlier(s) {
-			hasOutlier = true
+			outlierStatements = append(outlierStatements, s)
 		}
 	}
 
-	if hasOutlier {
+	if len(outlierStatements) > 0 {
 		for _, s := range statements {
 			r.mu.outliers.Add(uint128.FromBytes(s.ID), &Outlier{
 				Session:     &Outlier_Session{ID: sessionID.GetBytes()},
@@ -116,6 +148,28 @@ func (r *Registry) ObserveTransaction(sessionID clusterunique.ID, txnID uuid.UUI
 				Statement:   s,
 			})
 		}
+		r.maybeRequestDiagnosticsLocked(outlierStatements)
+	}
+}
+
+// maybeRequestDiagnosticsLocked arms a statement diagnostics request for the
+// fingerprint of each of the given outlier statements, if enabled. Once a
+// request is armed, its ID is recorded in the outlier, linking the outlier to
+// the bundle collected for the request.
+func (r *Registry) maybeRequestDiagnosticsLocked(statements []*Outlier_Statement) {
+	if r.diagnostics == nil || !AutoDiagnostics.Get(&r.st.SV) {
+		return
+	}
+	for _, s := range statements {
+		if s.Fingerprint == "" {
+			continue
+		}
+		s := s
+		r.diagnostics.RequestOutlierDiagnostics(s.Fingerprint, func(requestID int64) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			s.DiagnosticsRequestID = requestID
+		})
 	}
 }
 
@@ -32,12 +32,13 @@ func TestOutliers(t *testing.T) {
 	txnID := uuid.FastMakeV4()
 	stmtID := clusterunique.IDFromBytes([]byte("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"))
 	stmtFptID := roachpb.StmtFingerprintID(100)
+	stmtFpt := "SELECT _"
 
 	t.Run("detection", func(t *testing.T) {
 		st := cluster.MakeTestingClusterSettings()
 		outliers.LatencyThreshold.Override(ctx, &st.SV, 1*time.Second)
-		registry := outliers.New(st)
-		registry.ObserveStatement(sessionID, stmtID, stmtFptID, 2)
+		registry := outliers.New(st, nil /* diagnostics */)
+		registry.ObserveStatement(sessionID, stmtID, stmtFptID, stmtFpt, 2)
 		registry.ObserveTransaction(sessionID, txnID)
 
 		expected := []*outliers.Outlier{{
@@ -51,6 +52,7 @@ func TestOutliers(t *testing.T) {
 				ID:               stmtID.GetBytes(),
 				FingerprintID:    stmtFptID,
 				LatencyInSeconds: 2,
+				Fingerprint:      stmtFpt,
 			},
 		}}
 		var actual []*outliers.Outlier
@@ -68,8 +70,8 @@ func TestOutliers(t *testing.T) {
 	t.Run("disabled", func(t *testing.T) {
 		st := cluster.MakeTestingClusterSettings()
 		outliers.LatencyThreshold.Override(ctx, &st.SV, 0)
-		registry := outliers.New(st)
-		registry.ObserveStatement(sessionID, stmtID, stmtFptID, 2)
+		registry := outliers.New(st, nil /* diagnostics */)
+		registry.ObserveStatement(sessionID, stmtID, stmtFptID, stmtFpt, 2)
 		registry.ObserveTransaction(sessionID, txnID)
 
 		var actual []*outliers.Outlier
@@ -85,8 +87,8 @@ func TestOutliers(t *testing.T) {
 	t.Run("too fast", func(t *testing.T) {
 		st := cluster.MakeTestingClusterSettings()
 		outliers.LatencyThreshold.Override(ctx, &st.SV, 1*time.Second)
-		registry := outliers.New(st)
-		registry.ObserveStatement(sessionID, stmtID, stmtFptID, 0.5)
+		registry := outliers.New(st, nil /* diagnostics */)
+		registry.ObserveStatement(sessionID, stmtID, stmtFptID, stmtFpt, 0.5)
 		registry.ObserveTransaction(sessionID, txnID)
 
 		var actual []*outliers.Outlier
@@ -104,12 +106,13 @@ func TestOutliers(t *testing.T) {
 		otherTxnID := uuid.FastMakeV4()
 		otherStmtID := clusterunique.IDFromBytes([]byte("dddddddddddddddddddddddddddddddd"))
 		otherStmtFptID := roachpb.StmtFingerprintID(101)
+		otherStmtFpt := "SELECT _, _"
 
 		st := cluster.MakeTestingClusterSettings()
 		outliers.LatencyThreshold.Override(ctx, &st.SV, 1*time.Second)
-		registry := outliers.New(st)
-		registry.ObserveStatement(sessionID, stmtID, stmtFptID, 2)
-		registry.ObserveStatement(otherSessionID, otherStmtID, otherStmtFptID, 3)
+		registry := outliers.New(st, nil /* diagnostics */)
+		registry.ObserveStatement(sessionID, stmtID, stmtFptID, stmtFpt, 2)
+		registry.ObserveStatement(otherSessionID, otherStmtID, otherStmtFptID, otherStmtFpt, 3)
 		registry.ObserveTransaction(sessionID, txnID)
 		registry.ObserveTransaction(otherSessionID, otherTxnID)
 
@@ -124,6 +127,7 @@ func TestOutliers(t *testing.T) {
 				ID:               stmtID.GetBytes(),
 				FingerprintID:    stmtFptID,
 				LatencyInSeconds: 2,
+				Fingerprint:      stmtFpt,
 			},
 		}, {
 			Session: &outliers.Outlier_Session{
@@ -136,6 +140,7 @@ func TestOutliers(t *testing.T) {
 				ID:               otherStmtID.GetBytes(),
 				FingerprintID:    otherStmtFptID,
 				LatencyInSeconds: 3,
+				Fingerprint:      otherStmtFpt,
 			},
 		}}
 		var actual []*outliers.Outlier
@@ -153,4 +158,52 @@ func TestOutliers(t *testing.T) {
 
 		require.Equal(t, expected, actual)
 	})
+
+	t.Run("diagnostics", func(t *testing.T) {
+		fastStmtID := clusterunique.IDFromBytes([]byte("eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"))
+		fastStmtFptID := roachpb.StmtFingerprintID(102)
+		fastStmtFpt := "SELECT _, _, _"
+
+		st := cluster.MakeTestingClusterSettings()
+		outliers.LatencyThreshold.Override(ctx, &st.SV, 1*time.Second)
+		requester := &fakeDiagnosticsRequester{}
+		registry := outliers.New(st, requester)
+
+		// No request is made unless enabled.
+		registry.ObserveStatement(sessionID, stmtID, stmtFptID, stmtFpt, 2)
+		registry.ObserveTransaction(sessionID, txnID)
+		require.Empty(t, requester.fingerprints)
+
+		// Only the outlier statement of the transaction gets a request.
+		outliers.AutoDiagnostics.Override(ctx, &st.SV, true)
+		registry.ObserveStatement(sessionID, stmtID, stmtFptID, stmtFpt, 2)
+		registry.ObserveStatement(sessionID, fastStmtID, fastStmtFptID, fastStmtFpt, 0.5)
+		registry.ObserveTransaction(sessionID, txnID)
+		require.Equal(t, []string{stmtFpt}, requester.fingerprints)
+
+		// The request is linked to the outlier once armed.
+		requester.onArmed[0](42)
+		requestIDs := make(map[string]int64)
+		registry.IterateOutliers(
+			context.Background(),
+			func(ctx context.Context, o *outliers.Outlier) {
+				requestIDs[o.Statement.Fingerprint] = o.Statement.DiagnosticsRequestID
+			},
+		)
+		require.Equal(t, map[string]int64{stmtFpt: 42, fastStmtFpt: 0}, requestIDs)
+	})
+}
+
+type fakeDiagnosticsRequester struct {
+	fingerprints []string
+	onArmed      []func(requestID int64)
+}
+
+var _ outliers.DiagnosticsRequester = &fakeDiagnosticsRequester{}
+
+func (f *fakeDiagnosticsRequester) RequestOutlierDiagnostics(
+	fingerprint string, onArmed func(requestID int64),
+) {
+	f.fingerprints = append(f.fingerprints, fingerprint)
+	f.onArmed = append(f.onArmed, onArmed)
 }
@@ -127,6 +127,8 @@ func BuildStmtMetadataJSON(statistics *roachpb.CollectedStatementStatistics) (js
 //            "contentionTime":  { "$ref": "#/definitions/numeric_stats" },
 //            "networkMsgs":     { "$ref": "#/definitions/numeric_stats" },
 //            "maxDiskUsage":    { "$ref": "#/definitions/numeric_stats" },
+//            "cpuTime":           { "$ref": "#/definitions/numeric_stats" },
+//            "admissionWaitTime": { "$ref": "#/definitions/numeric_stats" },
 //          },
 //          "required": [
 //            "cnt",
@@ -224,6 +226,8 @@ func BuildTxnMetadataJSON(statistics *roachpb.CollectedTransactionStatistics) (j
 //         "contentionTime":  { "$ref": "#/definitions/numeric_stats" },
 //         "networkMsg":      { "$ref": "#/definitions/numeric_stats" },
 //         "maxDiskUsage":    { "$ref": "#/definitions/numeric_stats" },
+//         "cpuTime":           { "$ref": "#/definitions/numeric_stats" },
+//         "admissionWaitTime": { "$ref": "#/definitions/numeric_stats" },
 //       },
 //       "required": [
 //         "cnt",
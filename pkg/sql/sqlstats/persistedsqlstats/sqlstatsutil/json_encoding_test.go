@@ -120,6 +120,14 @@ func TestSQLStatsJsonEncoding(t *testing.T) {
          "maxDiskUsage": {
            "mean": {{.Float}},
            "sqDiff": {{.Float}}
+         },
+         "cpuTime": {
+           "mean": {{.Float}},
+           "sqDiff": {{.Float}}
+         },
+         "admissionWaitTime": {
+           "mean": {{.Float}},
+           "sqDiff": {{.Float}}
          }
        }
      }
@@ -238,6 +246,14 @@ func TestSQLStatsJsonEncoding(t *testing.T) {
          "maxDiskUsage": {
            "mean": {{.Float}},
            "sqDiff": {{.Float}}
+         },
+         "cpuTime": {
+           "mean": {{.Float}},
+           "sqDiff": {{.Float}}
+         },
+         "admissionWaitTime": {
+           "mean": {{.Float}},
+           "sqDiff": {{.Float}}
          }
        }
      }
@@ -345,6 +361,14 @@ func TestSQLStatsJsonEncoding(t *testing.T) {
     "maxDiskUsage": {
       "mean": {{.Float}},
       "sqDiff": {{.Float}}
+    },
+    "cpuTime": {
+      "mean": {{.Float}},
+      "sqDiff": {{.Float}}
+    },
+    "admissionWaitTime": {
+      "mean": {{.Float}},
+      "sqDiff": {{.Float}}
     }
   }
 }
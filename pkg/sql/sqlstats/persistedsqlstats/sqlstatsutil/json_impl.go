@@ -311,6 +311,8 @@ func (e *execStats) jsonFields() jsonFields {
 		{"contentionTime", (*numericStats)(&e.ContentionTime)},
 		{"networkMsgs", (*numericStats)(&e.NetworkMessages)},
 		{"maxDiskUsage", (*numericStats)(&e.MaxDiskUsage)},
+		{"cpuTime", (*numericStats)(&e.CPUTime)},
+		{"admissionWaitTime", (*numericStats)(&e.AdmissionWaitTime)},
 	}
 }
 
@@ -78,6 +78,7 @@ func newSQLStats(
 	maxMemBytesHist *metric.Histogram,
 	parentMon *mon.BytesMonitor,
 	flushTarget Sink,
+	outliersDiagnostics outliers.DiagnosticsRequester,
 	knobs *sqlstats.TestingKnobs,
 ) *SQLStats {
 	monitor := mon.NewMonitor(
@@ -95,7 +96,7 @@ func newSQLStats(
 		uniqueTxnFingerprintLimit:  uniqueTxnFingerprintLimit,
 		flushTarget:                flushTarget,
 		knobs:                      knobs,
-		outliers:                   outliers.New(st),
+		outliers:                   outliers.New(st, outliersDiagnostics),
 	}
 	s.mu.apps = make(map[string]*ssmemstorage.Container)
 	s.mu.mon = monitor
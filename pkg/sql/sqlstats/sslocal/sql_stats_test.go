@@ -442,6 +442,7 @@ func TestExplicitTxnFingerprintAccounting(t *testing.T) {
 		nil, /* maxMemoryBytesHist */
 		monitor,
 		nil, /* reportingSink */
+		nil, /* outliersDiagnostics */
 		nil, /* knobs */
 	)
 
@@ -556,6 +557,7 @@ func TestAssociatingStmtStatsWithTxnFingerprint(t *testing.T) {
 			monitor,
 			nil,
 			nil,
+			nil,
 		)
 		appStats := sqlStats.GetApplicationStats("" /* appName */)
 		statsCollector := sslocal.NewStatsCollector(
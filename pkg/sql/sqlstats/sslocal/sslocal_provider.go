@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/outliers"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/ssmemstorage"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -39,11 +40,12 @@ func New(
 	maxMemoryBytesHist *metric.Histogram,
 	pool *mon.BytesMonitor,
 	reportingSink Sink,
+	outliersDiagnostics outliers.DiagnosticsRequester,
 	knobs *sqlstats.TestingKnobs,
 ) *SQLStats {
 	return newSQLStats(settings, maxStmtFingerprints, maxTxnFingerprints,
 		curMemoryBytesCount, maxMemoryBytesHist, pool,
-		reportingSink, knobs)
+		reportingSink, outliersDiagnostics, knobs)
 }
 
 var _ sqlstats.Provider = &SQLStats{}
@@ -159,7 +159,7 @@ func (s *Container) RecordStatement(
 		}
 	}
 
-	s.outliersRegistry.ObserveStatement(value.SessionID, value.StatementID, stmtFingerprintID, value.ServiceLatency)
+	s.outliersRegistry.ObserveStatement(value.SessionID, value.StatementID, stmtFingerprintID, key.Query, value.ServiceLatency)
 
 	return stats.ID, nil
 }
@@ -267,6 +267,8 @@ func (s *Container) RecordTransaction(
 		stats.mu.data.ExecStats.ContentionTime.Record(stats.mu.data.ExecStats.Count, value.ExecStats.ContentionTime.Seconds())
 		stats.mu.data.ExecStats.NetworkMessages.Record(stats.mu.data.ExecStats.Count, float64(value.ExecStats.NetworkMessages))
 		stats.mu.data.ExecStats.MaxDiskUsage.Record(stats.mu.data.ExecStats.Count, float64(value.ExecStats.MaxDiskUsage))
+		stats.mu.data.ExecStats.CPUTime.Record(stats.mu.data.ExecStats.Count, value.ExecStats.CPUTime.Seconds())
+		stats.mu.data.ExecStats.AdmissionWaitTime.Record(stats.mu.data.ExecStats.Count, value.ExecStats.AdmissionWaitTime.Seconds())
 	}
 
 	s.outliersRegistry.ObserveTransaction(value.SessionID, value.TransactionID)
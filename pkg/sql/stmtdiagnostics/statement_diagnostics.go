@@ -55,6 +55,19 @@ var bundleChunkSize = settings.RegisterByteSizeSetting(
 	},
 )
 
+var outlierRequestsPerHour = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.stmt_diagnostics.outlier_requests_per_hour",
+	"maximum number of statement diagnostics requests armed automatically for outliers "+
+		"in the cluster over the past hour",
+	10,
+	settings.NonNegativeInt,
+)
+
+// outlierRequestExpiration is the time after which a request armed
+// automatically for an outlier expires if its fingerprint didn't execute again.
+const outlierRequestExpiration = time.Hour
+
 // Registry maintains a view on the statement fingerprints
 // on which data is to be collected (i.e. system.statement_diagnostics_requests)
 // and provides utilities for checking a query against this list and satisfying
@@ -92,6 +105,18 @@ type Registry struct {
 	// request has been canceled. The gossip callback will not block sending on
 	// this channel.
 	gossipCancelChan chan RequestID
+	// outlierRequestChan is used to hand the requests made on behalf of
+	// outliers to the polling loop, which inserts them. Statement execution
+	// will not block sending on this channel.
+	outlierRequestChan chan outlierRequest
+}
+
+// outlierRequest is a request to collect diagnostics for the next execution
+// of the fingerprint of an outlier.
+type outlierRequest struct {
+	fingerprint string
+	// onArmed is called with the ID of the request once it has been inserted.
+	onArmed func(requestID int64)
 }
 
 // Request describes a statement diagnostics request along with some conditional
@@ -121,7 +146,10 @@ func NewRegistry(
 		gossip:           gw,
 		gossipUpdateChan: make(chan RequestID, 1),
 		gossipCancelChan: make(chan RequestID, 1),
-		st:               st,
+		// The channel is small: when outliers are detected faster than the
+		// requests are inserted, most of them would hit the rate limit anyway.
+		outlierRequestChan: make(chan outlierRequest, 8),
+		st:                 st,
 	}
 	r.mu.rand = rand.New(rand.NewSource(timeutil.Now().UnixNano()))
 
@@ -192,6 +220,11 @@ func (r *Registry) poll(ctx context.Context) {
 			// read anything of the system table to remove the request from the
 			// registry.
 			continue
+		case req := <-r.outlierRequestChan:
+			if reqID, ok := r.insertOutlierRequest(ctx, req.fingerprint); ok {
+				req.onArmed(int64(reqID))
+			}
+			continue
 		case <-timer.C:
 			timer.Read = true
 		case <-ctx.Done():
@@ -274,16 +307,24 @@ func (r *Registry) InsertRequest(
 	minExecutionLatency time.Duration,
 	expiresAfter time.Duration,
 ) error {
-	_, err := r.insertRequestInternal(ctx, stmtFingerprint, samplingProbability, minExecutionLatency, expiresAfter)
+	_, err := r.insertRequestInternal(
+		ctx, stmtFingerprint, samplingProbability, minExecutionLatency, expiresAfter,
+		false, /* automatic */
+	)
 	return err
 }
 
+// insertRequestInternal inserts a request and arms it in the local registry.
+// Requests marked automatic are those armed on behalf of outliers; they are
+// only inserted if fewer than sql.stmt_diagnostics.outlier_requests_per_hour
+// automatic requests were created in the cluster over the past hour.
 func (r *Registry) insertRequestInternal(
 	ctx context.Context,
 	stmtFingerprint string,
 	samplingProbability float64,
 	minExecutionLatency time.Duration,
 	expiresAfter time.Duration,
+	automatic bool,
 ) (RequestID, error) {
 	g, err := r.gossip.OptionalErr(48274)
 	if err != nil {
@@ -306,6 +347,11 @@ func (r *Registry) insertRequestInternal(
 			"malformed input: got non-zero sampling probability %f and empty min exec latency",
 			samplingProbability)
 	}
+	if automatic && !r.st.Version.IsActive(ctx, clusterversion.AutomaticStmtDiagReqs) {
+		return 0, errors.New(
+			"automatic requests only supported after 22.2 version migrations have completed",
+		)
+	}
 
 	var reqID RequestID
 	var expiresAt time.Time
@@ -335,9 +381,31 @@ func (r *Registry) insertRequestInternal(
 			)
 		}
 
+		if automatic {
+			row, err = r.ie.QueryRowEx(ctx, "stmt-diag-count-automatic", txn,
+				sessiondata.InternalExecutorOverride{
+					User: username.RootUserName(),
+				},
+				`SELECT count(1) FROM system.statement_diagnostics_requests
+					WHERE automatic AND requested_at > now() - INTERVAL '1 hour'`,
+			)
+			if err != nil {
+				return err
+			}
+			if row == nil {
+				return errors.New("failed to count recent automatic statement diagnostics")
+			}
+			limit := outlierRequestsPerHour.Get(&r.st.SV)
+			if count := int64(*row[0].(*tree.DInt)); count >= limit {
+				return errors.Newf(
+					"%d automatic requests were created over the past hour", count,
+				)
+			}
+		}
+
 		now := timeutil.Now()
 		insertColumns := "statement_fingerprint, requested_at"
-		qargs := make([]interface{}, 2, 5)
+		qargs := make([]interface{}, 2, 6)
 		qargs[0] = stmtFingerprint // statement_fingerprint
 		qargs[1] = now             // requested_at
 		if samplingProbability != 0 {
@@ -353,6 +421,10 @@ func (r *Registry) insertRequestInternal(
 			expiresAt = now.Add(expiresAfter)
 			qargs = append(qargs, expiresAt) // expires_at
 		}
+		if automatic {
+			insertColumns += ", automatic"
+			qargs = append(qargs, true) // automatic
+		}
 		valuesClause := "$1, $2"
 		for i := range qargs[2:] {
 			valuesClause += fmt.Sprintf(", $%d", i+3)
@@ -395,6 +467,50 @@ func (r *Registry) insertRequestInternal(
 	return reqID, nil
 }
 
+// RequestOutlierDiagnostics is part of the outliers.DiagnosticsRequester
+// interface.
+func (r *Registry) RequestOutlierDiagnostics(fingerprint string, onArmed func(requestID int64)) {
+	select {
+	case r.outlierRequestChan <- outlierRequest{fingerprint: fingerprint, onArmed: onArmed}:
+	default:
+		// Don't pile up on these requests and don't block statement execution.
+	}
+}
+
+// insertOutlierRequest arms a request to collect diagnostics for the next
+// execution of the given fingerprint, on behalf of an outlier. No request is
+// armed if one is already pending for the fingerprint, or if the number of
+// automatic requests created in the cluster over the past hour exceeds
+// sql.stmt_diagnostics.outlier_requests_per_hour. The boolean return value
+// indicates whether the request was armed.
+func (r *Registry) insertOutlierRequest(
+	ctx context.Context, fingerprint string,
+) (RequestID, bool) {
+	if outlierRequestsPerHour.Get(&r.st.SV) == 0 {
+		return 0, false
+	}
+	r.mu.Lock()
+	for _, req := range r.mu.requestFingerprints {
+		if req.fingerprint == fingerprint {
+			r.mu.Unlock()
+			return 0, false
+		}
+	}
+	r.mu.Unlock()
+
+	reqID, err := r.insertRequestInternal(
+		ctx, fingerprint, 0 /* samplingProbability */, 0, /* minExecutionLatency */
+		outlierRequestExpiration, true, /* automatic */
+	)
+	if err != nil {
+		// The request is expected to fail if a request is already pending for
+		// the fingerprint or if the hourly limit was reached.
+		log.VInfof(ctx, 1, "not collecting diagnostics for outlier %q: %s", fingerprint, err)
+		return 0, false
+	}
+	return reqID, true
+}
+
 // CancelRequest is part of the server.StmtDiagnosticsRequester interface.
 func (r *Registry) CancelRequest(ctx context.Context, requestID int64) error {
 	g, err := r.gossip.OptionalErr(48274)
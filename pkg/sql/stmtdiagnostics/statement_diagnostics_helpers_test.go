@@ -24,9 +24,21 @@ func (r *Registry) InsertRequestInternal(
 	minExecutionLatency time.Duration,
 	expiresAfter time.Duration,
 ) (int64, error) {
-	id, err := r.insertRequestInternal(ctx, fprint, samplingProbability, minExecutionLatency, expiresAfter)
+	id, err := r.insertRequestInternal(
+		ctx, fprint, samplingProbability, minExecutionLatency, expiresAfter, false, /* automatic */
+	)
 	return int64(id), err
 }
 
+// InsertOutlierRequest exposes the insertion of the requests made on behalf of
+// outliers to tests in this package.
+func (r *Registry) InsertOutlierRequest(ctx context.Context, fprint string) (int64, bool) {
+	id, ok := r.insertOutlierRequest(ctx, fprint)
+	return int64(id), ok
+}
+
+// OutlierRequestsPerHour is exposed to override in tests.
+var OutlierRequestsPerHour = outlierRequestsPerHour
+
 // PollingInterval is exposed to override in tests.
 var PollingInterval = pollingInterval
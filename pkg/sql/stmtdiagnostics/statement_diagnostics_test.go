@@ -305,6 +305,53 @@ func TestDiagnosticsRequest(t *testing.T) {
 	})
 }
 
+// Test the requests armed on behalf of outliers.
+func TestOutlierDiagnosticsRequest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+	_, err := db.Exec("CREATE TABLE test (x int PRIMARY KEY)")
+	require.NoError(t, err)
+
+	registry := s.ExecutorConfig().(sql.ExecutorConfig).StmtDiagnosticsRecorder
+	stmtdiagnostics.OutlierRequestsPerHour.Override(ctx, &s.ClusterSettings().SV, 2)
+
+	// A request is armed for the fingerprint of the outlier, but not while
+	// it is pending.
+	reqID, ok := registry.InsertOutlierRequest(ctx, "SELECT x FROM test")
+	require.True(t, ok)
+	_, ok = registry.InsertOutlierRequest(ctx, "SELECT x FROM test")
+	require.False(t, ok)
+
+	// The next execution of the fingerprint collects the bundle.
+	_, err = db.Exec("SELECT x FROM test")
+	require.NoError(t, err)
+	var completed, automatic bool
+	var diagnosticsID gosql.NullInt64
+	require.NoError(t, db.QueryRow(
+		"SELECT completed, automatic, statement_diagnostics_id FROM system.statement_diagnostics_requests WHERE id = $1",
+		reqID,
+	).Scan(&completed, &automatic, &diagnosticsID))
+	require.True(t, completed)
+	require.True(t, automatic)
+	require.True(t, diagnosticsID.Valid)
+
+	// The requests are rate limited, but the requests made by users don't count
+	// toward the limit.
+	userReqID, err := registry.InsertRequestInternal(ctx, "SELECT x FROM test WHERE x = _", 0, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, db.QueryRow(
+		"SELECT automatic FROM system.statement_diagnostics_requests WHERE id = $1", userReqID,
+	).Scan(&automatic))
+	require.False(t, automatic)
+	_, ok = registry.InsertOutlierRequest(ctx, "SELECT x FROM test WHERE x > _")
+	require.True(t, ok)
+	_, ok = registry.InsertOutlierRequest(ctx, "SELECT x FROM test WHERE x < _")
+	require.False(t, ok)
+}
+
 // Test that a different node can service a diagnostics request.
 func TestDiagnosticsRequestDifferentNode(t *testing.T) {
 	defer leaktest.AfterTest(t)()
@@ -1027,7 +1027,7 @@ func GetIntent(reader Reader, key roachpb.Key) (*roachpb.Intent, error) {
 	// used for queries.
 	lbKey, _ := keys.LockTableSingleKey(key, nil)
 
-	iter := reader.NewEngineIterator(IterOptions{Prefix: true, LowerBound: lbKey})
+	iter := newIntentIterator(reader, IterOptions{Prefix: true, LowerBound: lbKey})
 	defer iter.Close()
 
 	valid, err := iter.SeekEngineKeyGE(EngineKey{Key: lbKey})
@@ -1107,7 +1107,7 @@ func ScanIntents(
 
 	ltStart, _ := keys.LockTableSingleKey(start, nil)
 	ltEnd, _ := keys.LockTableSingleKey(end, nil)
-	iter := reader.NewEngineIterator(IterOptions{LowerBound: ltStart, UpperBound: ltEnd})
+	iter := newIntentIterator(reader, IterOptions{LowerBound: ltStart, UpperBound: ltEnd})
 	defer iter.Close()
 
 	var meta enginepb.MVCCMetadata
@@ -267,7 +267,7 @@ func (lk LockTableKey) ToEngineKey(buf []byte) (EngineKey, []byte) {
 	if len(lk.TxnUUID) != uuid.Size {
 		panic("invalid TxnUUID")
 	}
-	if lk.Strength != lock.Exclusive {
+	if lk.Strength != lock.Shared && lk.Strength != lock.Exclusive {
 		panic("unsupported lock strength")
 	}
 	// The first term in estimatedLen is for LockTableSingleKey.
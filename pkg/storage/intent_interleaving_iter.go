@@ -246,8 +246,9 @@ func newIntentInterleavingIterator(reader Reader, opts IterOptions) MVCCIterator
 		intentOpts.UpperBound = keys.LockTableSingleKeyEnd
 	}
 	// Note that we can reuse intentKeyBuf, intentLimitKeyBuf after
-	// NewEngineIterator returns.
-	intentIter := reader.NewEngineIterator(intentOpts)
+	// NewEngineIterator returns. The lock table also holds replicated Shared
+	// locks, which are skipped.
+	intentIter := newIntentIterator(reader, intentOpts)
 
 	// The creation of these iterators can race with concurrent mutations, which
 	// may make them inconsistent with each other. So we clone here, to ensure
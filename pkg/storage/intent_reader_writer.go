@@ -132,7 +132,7 @@ func (imr *intentInterleavingReader) MVCCGet(key MVCCKey) ([]byte, error) {
 	// Get is not efficient, but this function is deprecated and only used for
 	// tests, so we don't care.
 	ltKey, _ := keys.LockTableSingleKey(key.Key, nil)
-	iter := imr.wrappableReader.NewEngineIterator(IterOptions{Prefix: true, LowerBound: ltKey})
+	iter := newIntentIterator(imr.wrappableReader, IterOptions{Prefix: true, LowerBound: ltKey})
 	defer iter.Close()
 	valid, err := iter.SeekEngineKeyGE(EngineKey{Key: ltKey})
 	if !valid || err != nil {
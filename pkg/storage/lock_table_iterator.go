@@ -0,0 +1,174 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/pebble"
+)
+
+// lockTableIterator is an EngineIterator over the lock table keyspace that
+// only surfaces the locks with at least a minimum strength, skipping the
+// weaker ones.
+//
+// The lock table holds both intents, which are Exclusive locks, and replicated
+// Shared locks. Readers of intents use a lockTableIterator with a minimum
+// strength of lock.Exclusive so that they never observe Shared locks, which
+// have no provisional value, and find at most one lock per key.
+type lockTableIterator struct {
+	EngineIterator
+	// The weakest strength of the locks that are surfaced.
+	minStrength lock.Strength
+}
+
+var _ EngineIterator = &lockTableIterator{}
+
+// NewLockTableIterator returns an EngineIterator over the lock table keyspace
+// of the reader that only surfaces the locks held with at least the strength
+// minStrength. The bounds in opts must be lock table keys.
+func NewLockTableIterator(
+	reader Reader, opts IterOptions, minStrength lock.Strength,
+) EngineIterator {
+	return newLockTableIterator(reader, opts, minStrength)
+}
+
+func newLockTableIterator(
+	reader Reader, opts IterOptions, minStrength lock.Strength,
+) *lockTableIterator {
+	return &lockTableIterator{
+		EngineIterator: reader.NewEngineIterator(opts),
+		minStrength:    minStrength,
+	}
+}
+
+// newIntentIterator returns a lockTableIterator that only surfaces intents.
+func newIntentIterator(reader Reader, opts IterOptions) *lockTableIterator {
+	return newLockTableIterator(reader, opts, lock.Exclusive)
+}
+
+// SeekEngineKeyGE implements the EngineIterator interface.
+func (i *lockTableIterator) SeekEngineKeyGE(key EngineKey) (valid bool, err error) {
+	valid, err = i.EngineIterator.SeekEngineKeyGE(key)
+	return i.skipForward(valid, err)
+}
+
+// SeekEngineKeyLT implements the EngineIterator interface.
+func (i *lockTableIterator) SeekEngineKeyLT(key EngineKey) (valid bool, err error) {
+	valid, err = i.EngineIterator.SeekEngineKeyLT(key)
+	return i.skipBackward(valid, err)
+}
+
+// NextEngineKey implements the EngineIterator interface.
+func (i *lockTableIterator) NextEngineKey() (valid bool, err error) {
+	valid, err = i.EngineIterator.NextEngineKey()
+	return i.skipForward(valid, err)
+}
+
+// PrevEngineKey implements the EngineIterator interface.
+func (i *lockTableIterator) PrevEngineKey() (valid bool, err error) {
+	valid, err = i.EngineIterator.PrevEngineKey()
+	return i.skipBackward(valid, err)
+}
+
+// SeekEngineKeyGEWithLimit implements the EngineIterator interface.
+func (i *lockTableIterator) SeekEngineKeyGEWithLimit(
+	key EngineKey, limit roachpb.Key,
+) (state pebble.IterValidityState, err error) {
+	state, err = i.EngineIterator.SeekEngineKeyGEWithLimit(key, limit)
+	return i.skipForwardWithLimit(state, err, limit)
+}
+
+// SeekEngineKeyLTWithLimit implements the EngineIterator interface.
+func (i *lockTableIterator) SeekEngineKeyLTWithLimit(
+	key EngineKey, limit roachpb.Key,
+) (state pebble.IterValidityState, err error) {
+	state, err = i.EngineIterator.SeekEngineKeyLTWithLimit(key, limit)
+	return i.skipBackwardWithLimit(state, err, limit)
+}
+
+// NextEngineKeyWithLimit implements the EngineIterator interface.
+func (i *lockTableIterator) NextEngineKeyWithLimit(
+	limit roachpb.Key,
+) (state pebble.IterValidityState, err error) {
+	state, err = i.EngineIterator.NextEngineKeyWithLimit(limit)
+	return i.skipForwardWithLimit(state, err, limit)
+}
+
+// PrevEngineKeyWithLimit implements the EngineIterator interface.
+func (i *lockTableIterator) PrevEngineKeyWithLimit(
+	limit roachpb.Key,
+) (state pebble.IterValidityState, err error) {
+	state, err = i.EngineIterator.PrevEngineKeyWithLimit(limit)
+	return i.skipBackwardWithLimit(state, err, limit)
+}
+
+// Returns whether the iterator is positioned at a lock that is weaker than
+// the minimum strength.
+func (i *lockTableIterator) atWeakerLock() (bool, error) {
+	engineKey, err := i.EngineIterator.UnsafeEngineKey()
+	if err != nil {
+		return false, err
+	}
+	ltKey, err := engineKey.ToLockTableKey()
+	if err != nil {
+		return false, err
+	}
+	return ltKey.Strength < i.minStrength, nil
+}
+
+func (i *lockTableIterator) skipForward(valid bool, err error) (bool, error) {
+	for ; valid && err == nil; valid, err = i.EngineIterator.NextEngineKey() {
+		var skip bool
+		if skip, err = i.atWeakerLock(); err != nil || !skip {
+			return err == nil, err
+		}
+	}
+	return valid, err
+}
+
+func (i *lockTableIterator) skipBackward(valid bool, err error) (bool, error) {
+	for ; valid && err == nil; valid, err = i.EngineIterator.PrevEngineKey() {
+		var skip bool
+		if skip, err = i.atWeakerLock(); err != nil || !skip {
+			return err == nil, err
+		}
+	}
+	return valid, err
+}
+
+func (i *lockTableIterator) skipForwardWithLimit(
+	state pebble.IterValidityState, err error, limit roachpb.Key,
+) (pebble.IterValidityState, error) {
+	for ; state == pebble.IterValid && err == nil; state, err = i.EngineIterator.NextEngineKeyWithLimit(limit) {
+		var skip bool
+		if skip, err = i.atWeakerLock(); err != nil {
+			return pebble.IterExhausted, err
+		} else if !skip {
+			return state, nil
+		}
+	}
+	return state, err
+}
+
+func (i *lockTableIterator) skipBackwardWithLimit(
+	state pebble.IterValidityState, err error, limit roachpb.Key,
+) (pebble.IterValidityState, error) {
+	for ; state == pebble.IterValid && err == nil; state, err = i.EngineIterator.PrevEngineKeyWithLimit(limit) {
+		var skip bool
+		if skip, err = i.atWeakerLock(); err != nil {
+			return pebble.IterExhausted, err
+		} else if !skip {
+			return state, nil
+		}
+	}
+	return state, err
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/uncertainty"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -35,6 +36,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/pebble"
 )
@@ -1094,6 +1096,9 @@ func MVCCPut(
 		})
 		defer iter.Close()
 	}
+	if err := mvccCheckWriteForConflictingLocks(ctx, rw, key, timestamp, txn); err != nil {
+		return err
+	}
 	return mvccPutUsingIter(ctx, rw, iter, ms, key, timestamp, localTimestamp, value, txn, nil)
 }
 
@@ -1141,6 +1146,9 @@ func MVCCDelete(
 	})
 	defer iter.Close()
 
+	if err := mvccCheckWriteForConflictingLocks(ctx, rw, key, timestamp, txn); err != nil {
+		return err
+	}
 	return mvccPutUsingIter(ctx, rw, iter, ms, key, timestamp, localTimestamp, noValue, txn, nil)
 }
 
@@ -1828,6 +1836,10 @@ func MVCCIncrement(
 	})
 	defer iter.Close()
 
+	if err := mvccCheckWriteForConflictingLocks(ctx, rw, key, timestamp, txn); err != nil {
+		return 0, err
+	}
+
 	var int64Val int64
 	var newInt64Val int64
 	valueFn := func(value optionalValue) (roachpb.Value, error) {
@@ -1905,6 +1917,9 @@ func MVCCConditionalPut(
 	})
 	defer iter.Close()
 
+	if err := mvccCheckWriteForConflictingLocks(ctx, rw, key, timestamp, txn); err != nil {
+		return err
+	}
 	return mvccConditionalPutUsingIter(
 		ctx, rw, iter, ms, key, timestamp, localTimestamp, value, expVal, allowIfDoesNotExist, txn)
 }
@@ -1989,6 +2004,9 @@ func MVCCInitPut(
 		Prefix:   true,
 	})
 	defer iter.Close()
+	if err := mvccCheckWriteForConflictingLocks(ctx, rw, key, timestamp, txn); err != nil {
+		return err
+	}
 	return mvccInitPutUsingIter(ctx, rw, iter, ms, key, timestamp, localTimestamp, value, failOnTombstones, txn)
 }
 
@@ -2377,6 +2395,9 @@ func MVCCDeleteRange(
 
 	var keys []roachpb.Key
 	for i, kv := range res.KVs {
+		if err := mvccCheckWriteForConflictingLocks(ctx, rw, kv.Key, timestamp, txn); err != nil {
+			return nil, nil, 0, err
+		}
 		if err := mvccPutInternal(
 			ctx, rw, iter, ms, kv.Key, timestamp, localTimestamp, noValue, txn, buf, nil,
 		); err != nil {
@@ -2870,10 +2891,182 @@ func MVCCIterate(
 	return intents, nil
 }
 
+// MVCCCheckForAcquireLock checks whether the transaction txn, which is nil for
+// non-transactional requests, can acquire a lock of strength str on key
+// without conflicting with the replicated Shared locks held by other
+// transactions. If not, a WriteIntentError is returned for the conflicting
+// locks, so that the request waits for them in the lock table.
+//
+// Intents are not considered: requests that acquire locks read the key or
+// write to it first, and fail on the intents of other transactions then.
+func MVCCCheckForAcquireLock(
+	ctx context.Context, reader Reader, txn *roachpb.Transaction, str lock.Strength, key roachpb.Key,
+) error {
+	if !lock.Conflicts(lock.Shared, str) {
+		return nil
+	}
+	var txnID uuid.UUID
+	if txn != nil {
+		txnID = txn.ID
+	}
+	ltKey, _ := keys.LockTableSingleKey(key, nil)
+	iter := reader.NewEngineIterator(IterOptions{Prefix: true, LowerBound: ltKey})
+	defer iter.Close()
+
+	var meta enginepb.MVCCMetadata
+	var intents []roachpb.Intent
+	var valid bool
+	var err error
+	for valid, err = iter.SeekEngineKeyGE(EngineKey{Key: ltKey}); valid; valid, err = iter.NextEngineKey() {
+		engineKey, err := iter.UnsafeEngineKey()
+		if err != nil {
+			return err
+		}
+		lockKey, err := engineKey.ToLockTableKey()
+		if err != nil {
+			return err
+		}
+		if lockKey.Strength != lock.Shared || bytes.Equal(lockKey.TxnUUID, txnID.GetBytes()) {
+			continue
+		}
+		if err = protoutil.Unmarshal(iter.UnsafeValue(), &meta); err != nil {
+			return err
+		}
+		if meta.Txn == nil {
+			return errors.AssertionFailedf("txn is null for key %v, lock %v", key, meta)
+		}
+		intent := roachpb.MakeIntent(meta.Txn, key)
+		intent.Strength = lock.Shared
+		intents = append(intents, intent)
+	}
+	if err != nil {
+		return err
+	}
+	if len(intents) > 0 {
+		return &roachpb.WriteIntentError{Intents: intents}
+	}
+	return nil
+}
+
+// mvccCheckWriteForConflictingLocks returns a WriteIntentError if a write to
+// key by the transaction txn conflicts with the replicated Shared locks held
+// by other transactions. Inline writes are never locked. Blind writes don't
+// check for locks either, since the caller guarantees that the key has no
+// versions and only keys with versions are locked by locking reads.
+func mvccCheckWriteForConflictingLocks(
+	ctx context.Context,
+	reader Reader,
+	key roachpb.Key,
+	timestamp hlc.Timestamp,
+	txn *roachpb.Transaction,
+) error {
+	if timestamp.IsEmpty() {
+		return nil
+	}
+	return MVCCCheckForAcquireLock(ctx, reader, txn, lock.Exclusive, key)
+}
+
+// MVCCAcquireLock acquires a replicated lock of strength str on key for the
+// transaction txn, by writing it to the lock table. Only Shared locks can be
+// acquired this way, since replicated Exclusive locks are write intents.
+// Shared locks do not conflict with each other, and the caller is expected to
+// have read the key, failing on the intents of other transactions.
+//
+// Acquiring a lock that the transaction already holds in its current epoch is
+// a no-op. The lock is released by intent resolution once the transaction is
+// finalized or moves to a later epoch (see MVCCResolveWriteIntent). Replicated
+// Shared locks are not accounted for in MVCCStats.
+func MVCCAcquireLock(
+	ctx context.Context, rw ReadWriter, txn *roachpb.Transaction, str lock.Strength, key roachpb.Key,
+) error {
+	if txn == nil {
+		return errors.Errorf("%q: replicated locks cannot be acquired outside of transactions", key)
+	}
+	if str != lock.Shared {
+		return errors.AssertionFailedf("replicated %s locks are not supported", str)
+	}
+	meta, err := mvccGetSharedLock(rw, key, txn.ID)
+	if err != nil {
+		return err
+	}
+	if meta != nil && meta.Txn.Epoch >= txn.Epoch {
+		// Already held.
+		return nil
+	}
+	newMeta := enginepb.MVCCMetadata{
+		Txn:       &txn.TxnMeta,
+		Timestamp: txn.WriteTimestamp.ToLegacyTimestamp(),
+	}
+	val, err := protoutil.Marshal(&newMeta)
+	if err != nil {
+		return err
+	}
+	engineKey, _ := LockTableKey{
+		Key:      key,
+		Strength: lock.Shared,
+		TxnUUID:  txn.ID.GetBytes(),
+	}.ToEngineKey(nil)
+	return rw.PutEngineKey(engineKey, val)
+}
+
+// mvccGetSharedLock returns the metadata of the replicated Shared lock held by
+// the transaction txnID on key, or nil if there is no such lock.
+func mvccGetSharedLock(
+	reader Reader, key roachpb.Key, txnID uuid.UUID,
+) (*enginepb.MVCCMetadata, error) {
+	engineKey, _ := LockTableKey{
+		Key:      key,
+		Strength: lock.Shared,
+		TxnUUID:  txnID.GetBytes(),
+	}.ToEngineKey(nil)
+	iter := reader.NewEngineIterator(IterOptions{Prefix: true, LowerBound: engineKey.Key})
+	defer iter.Close()
+	valid, err := iter.SeekEngineKeyGE(engineKey)
+	if err != nil || !valid {
+		return nil, err
+	}
+	foundKey, err := iter.UnsafeEngineKey()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(foundKey.Version, engineKey.Version) {
+		return nil, nil
+	}
+	var meta enginepb.MVCCMetadata
+	if err := protoutil.Unmarshal(iter.UnsafeValue(), &meta); err != nil {
+		return nil, err
+	}
+	if meta.Txn == nil {
+		return nil, errors.AssertionFailedf("txn is null for key %v, lock %v", key, meta)
+	}
+	return &meta, nil
+}
+
+// mvccReleaseSharedLock releases the replicated Shared lock on key described
+// by meta if the update finalizes its transaction or moves it to a later
+// epoch. Shared locks are not released when the savepoint that acquired them
+// is rolled back, which is safe since they only hold up conflicting writers
+// until the transaction finishes. Returns whether the lock was released.
+func mvccReleaseSharedLock(
+	writer Writer, key roachpb.Key, meta *enginepb.MVCCMetadata, update roachpb.LockUpdate,
+) (bool, error) {
+	if !update.Status.IsFinalized() && update.Txn.Epoch <= meta.Txn.Epoch {
+		return false, nil
+	}
+	engineKey, _ := LockTableKey{
+		Key:      key,
+		Strength: lock.Shared,
+		TxnUUID:  meta.Txn.ID.GetBytes(),
+	}.ToEngineKey(nil)
+	return true, writer.ClearEngineKey(engineKey)
+}
+
 // MVCCResolveWriteIntent either commits, aborts (rolls back), or moves forward
 // in time an extant write intent for a given txn according to commit parameter.
-// ResolveWriteIntent will skip write intents of other txns. It returns
-// whether or not an intent was found to resolve.
+// ResolveWriteIntent will skip write intents of other txns. The replicated
+// Shared lock held by the txn on the key, if any, is released once the txn is
+// finalized or moves to a later epoch. It returns whether or not an intent or
+// a lock was found to resolve.
 //
 // Transaction epochs deserve a bit of explanation. The epoch for a
 // transaction is incremented on transaction retries. A transaction
@@ -2905,7 +3098,22 @@ func MVCCResolveWriteIntent(
 	ok, err := mvccResolveWriteIntent(ctx, rw, iterAndBuf.iter, ms, intent, iterAndBuf.buf)
 	// Using defer would be more convenient, but it is measurably slower.
 	iterAndBuf.Cleanup()
-	return ok, err
+	if err != nil {
+		return false, err
+	}
+	released, err := mvccResolveSharedLock(rw, intent)
+	return ok || released, err
+}
+
+// mvccResolveSharedLock releases the replicated Shared lock held on the key of
+// the update by its transaction, if the update calls for it (see
+// mvccReleaseSharedLock). Returns whether a lock was released.
+func mvccResolveSharedLock(rw ReadWriter, update roachpb.LockUpdate) (bool, error) {
+	meta, err := mvccGetSharedLock(rw, update.Key, update.Txn.ID)
+	if err != nil || meta == nil {
+		return false, err
+	}
+	return mvccReleaseSharedLock(rw, update.Key, meta, update)
 }
 
 // iterForKeyVersions provides a subset of the functionality of MVCCIterator.
@@ -2950,6 +3158,9 @@ type separatedIntentAndVersionIter struct {
 	engineIterValid bool
 	engineIterErr   error
 	intentKey       roachpb.Key
+	// The strength of the lock at which engineIter is positioned. The lock
+	// table holds replicated Shared locks in addition to intents.
+	lockStrength lock.Strength
 }
 
 var _ iterForKeyVersions = &separatedIntentAndVersionIter{}
@@ -2976,11 +3187,13 @@ func (s *separatedIntentAndVersionIter) initIntentKey() {
 			s.engineIterValid = false
 			return
 		}
-		if s.intentKey, err = keys.DecodeLockTableSingleKey(engineKey.Key); err != nil {
+		lockKey, err := engineKey.ToLockTableKey()
+		if err != nil {
 			s.engineIterErr = err
 			s.engineIterValid = false
 			return
 		}
+		s.intentKey, s.lockStrength = lockKey.Key, lockKey.Strength
 	}
 }
 
@@ -3592,10 +3805,12 @@ func (b IterAndBuf) Cleanup() {
 
 // MVCCResolveWriteIntentRange commits or aborts (rolls back) the range of write
 // intents specified by start and end keys for a given txn.
-// ResolveWriteIntentRange will skip write intents of other txns. A max of zero
-// means unbounded. A max of -1 means resolve nothing and returns the entire
-// intent span as the resume span. Returns the number of intents resolved and a
-// resume span if the max keys limit was exceeded.
+// ResolveWriteIntentRange will skip write intents of other txns. The replicated
+// Shared locks held by the txn in the range are released as described in
+// MVCCResolveWriteIntent. A max of zero means unbounded. A max of -1 means
+// resolve nothing and returns the entire intent span as the resume span.
+// Returns the number of intents and locks resolved and a resume span if the
+// max keys limit was exceeded.
 func MVCCResolveWriteIntentRange(
 	ctx context.Context, rw ReadWriter, ms *enginepb.MVCCStats, intent roachpb.LockUpdate, max int64,
 ) (int64, *roachpb.Span, error) {
@@ -3634,6 +3849,7 @@ func MVCCResolveWriteIntentRange(
 	intent.EndKey = nil
 
 	var lastResolvedKey roachpb.Key
+	var lastResolvedShared bool
 	num := int64(0)
 	for {
 		if valid, err := sepIter.Valid(); err != nil {
@@ -3644,7 +3860,12 @@ func MVCCResolveWriteIntentRange(
 		}
 		if max > 0 && num == max {
 			// We could also compute a tighter nextKey here if we wanted to.
-			return num, &roachpb.Span{Key: lastResolvedKey.Next(), EndKey: intentEndKey}, nil
+			resumeKey := lastResolvedKey.Next()
+			if lastResolvedShared {
+				// The intent of the txn on the same key may not be resolved yet.
+				resumeKey = lastResolvedKey
+			}
+			return num, &roachpb.Span{Key: resumeKey, EndKey: intentEndKey}, nil
 		}
 		// Parse the MVCCMetadata to see if it is a relevant intent.
 		meta := &putBuf.meta
@@ -3659,6 +3880,19 @@ func MVCCResolveWriteIntentRange(
 			sepIter.nextEngineKey()
 			continue
 		}
+		if sepIter.lockStrength == lock.Shared {
+			// A replicated Shared lock, which has no provisional value.
+			lastResolvedKey = append(lastResolvedKey[:0], sepIter.intentKey...)
+			lastResolvedShared = true
+			released, err := mvccReleaseSharedLock(rw, lastResolvedKey, meta, intent)
+			if err != nil {
+				return 0, nil, err
+			} else if released {
+				num++
+			}
+			sepIter.nextEngineKey()
+			continue
+		}
 		// Stash the parsed meta so don't need to parse it again in
 		// mvccResolveWriteIntent. This parsing can be ~10% of the resolution cost
 		// in some benchmarks.
@@ -3667,6 +3901,7 @@ func MVCCResolveWriteIntentRange(
 		// stability of the key passed to mvccResolveWriteIntent, and for the
 		// subsequent iteration to construct a resume span.
 		lastResolvedKey = append(lastResolvedKey[:0], sepIter.UnsafeKey().Key...)
+		lastResolvedShared = false
 		intent.Key = lastResolvedKey
 		ok, err := mvccResolveWriteIntent(ctx, rw, sepIter, ms, intent, putBuf)
 		if err != nil {
@@ -3906,6 +4141,154 @@ func MVCCGarbageCollect(
 	return nil
 }
 
+// MVCCGarbageCollectVersionRanges creates an iterator on the ReadWriter and
+// clears, for each of the given version ranges, the versions of its key with
+// timestamps within the range. Unlike MVCCGarbageCollect, this removes versions
+// from the middle of a key's history, so the newest version of a key must not
+// be part of a range.
+//
+// Note that this method will be sorting the version ranges.
+//
+// REQUIRES: the version ranges are either all for local keys, or all for
+// global keys, and not a mix of the two.
+func MVCCGarbageCollectVersionRanges(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	versionRanges []roachpb.GCRequest_GCVersionRange,
+) error {
+
+	var count int64
+	defer func(begin time.Time) {
+		log.Eventf(ctx, "done with GC evaluation for %d version ranges at %.2f ranges/sec. Deleted %d entries",
+			len(versionRanges), float64(len(versionRanges))*1e9/float64(timeutil.Since(begin)), count)
+	}(timeutil.Now())
+
+	if len(versionRanges) == 0 {
+		return nil
+	}
+
+	// Sort the slice to both determine the bounds and ensure that we're seeking
+	// in increasing order.
+	sort.Slice(versionRanges, func(i, j int) bool {
+		iKey := MVCCKey{Key: versionRanges[i].Key, Timestamp: versionRanges[i].EndTimestamp}
+		jKey := MVCCKey{Key: versionRanges[j].Key, Timestamp: versionRanges[j].EndTimestamp}
+		return iKey.Less(jKey)
+	})
+
+	iter := rw.NewMVCCIterator(MVCCKeyAndIntentsIterKind, IterOptions{
+		LowerBound: versionRanges[0].Key,
+		UpperBound: versionRanges[len(versionRanges)-1].Key.Next(),
+	})
+	defer iter.Close()
+	if !iter.SupportsPrev() {
+		return errors.AssertionFailedf("GC of version ranges requires reverse iteration")
+	}
+
+	decodeValue := func(raw []byte) (MVCCValue, error) {
+		v, ok, err := tryDecodeSimpleMVCCValue(raw)
+		if !ok && err == nil {
+			v, err = decodeExtendedMVCCValue(raw)
+		}
+		return v, err
+	}
+
+	meta := &enginepb.MVCCMetadata{}
+	for _, vr := range versionRanges {
+		if vr.EndTimestamp.Less(vr.StartTimestamp) {
+			return errors.Errorf("invalid GC version range [%s,%s] of %q",
+				vr.StartTimestamp, vr.EndTimestamp, vr.Key)
+		}
+		ok, _, _, err := mvccGetMetadata(iter, MakeMVCCMetadataKey(vr.Key), meta)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if meta.IsInline() {
+			return errors.Errorf("request to GC versions of inline value %q", vr.Key)
+		}
+
+		// Position the iterator on the version following the range, i.e. the
+		// oldest version newer than the end of the range. It must exist and be
+		// committed, as it keeps the garbage versions from being visible.
+		iter.SeekLT(MVCCKey{Key: vr.Key, Timestamp: vr.EndTimestamp})
+		if ok, err := iter.Valid(); err != nil {
+			return err
+		} else if !ok || !iter.UnsafeKey().Key.Equal(vr.Key) || !iter.UnsafeKey().IsValue() {
+			return errors.Errorf("request to GC latest value of %q", vr.Key)
+		}
+		nextNanos := iter.UnsafeKey().Timestamp.WallTime
+		if meta.Txn != nil && iter.UnsafeKey().Timestamp.Equal(meta.Timestamp.ToTimestamp()) {
+			return errors.Errorf("request to GC versions below intent at %q", vr.Key)
+		}
+
+		// Iterate through the garbage versions, accumulating their stats and
+		// issuing clear operations. For GCBytesAge, prevNanos tracks the
+		// timestamp of the newer neighbor of each version.
+		prevNanos := nextNanos
+		var cleared bool
+		for iter.Next(); ; iter.Next() {
+			if ok, err := iter.Valid(); err != nil {
+				return err
+			} else if !ok {
+				break
+			}
+			unsafeIterKey := iter.UnsafeKey()
+			if !unsafeIterKey.Key.Equal(vr.Key) || !unsafeIterKey.IsValue() {
+				break
+			}
+			if unsafeIterKey.Timestamp.Less(vr.StartTimestamp) {
+				// This is the version preceding the range. It used to become
+				// non-live when the oldest garbage version was written, and now
+				// becomes non-live when the version following the range was.
+				if ms != nil && cleared {
+					unsafeValRaw := iter.UnsafeValue()
+					unsafeVal, err := decodeValue(unsafeValRaw)
+					if err != nil {
+						return err
+					}
+					if !unsafeVal.IsTombstone() {
+						keySize := MVCCVersionTimestampSize
+						valSize := int64(len(unsafeValRaw))
+						ms.Add(updateStatsOnGC(vr.Key, keySize, valSize, nil, prevNanos))
+						ms.Subtract(updateStatsOnGC(vr.Key, keySize, valSize, nil, nextNanos))
+					}
+				}
+				break
+			}
+			if ms != nil {
+				unsafeValRaw := iter.UnsafeValue()
+				unsafeVal, err := decodeValue(unsafeValRaw)
+				if err != nil {
+					return err
+				}
+
+				keySize := MVCCVersionTimestampSize
+				valSize := int64(len(unsafeValRaw))
+
+				// A non-deletion becomes non-live when its newer neighbor shows up.
+				// A deletion tombstone becomes non-live right when it is created.
+				fromNS := prevNanos
+				if unsafeVal.IsTombstone() {
+					fromNS = unsafeIterKey.Timestamp.WallTime
+				}
+
+				ms.Add(updateStatsOnGC(vr.Key, keySize, valSize, nil, fromNS))
+			}
+			count++
+			cleared = true
+			if err := rw.ClearMVCC(unsafeIterKey); err != nil {
+				return err
+			}
+			prevNanos = unsafeIterKey.Timestamp.WallTime
+		}
+	}
+
+	return nil
+}
+
 // MVCCFindSplitKey finds a key from the given span such that the left side of
 // the split is roughly targetSize bytes. The returned key will never be chosen
 // from the key ranges listed in keys.NoSplitSpans.
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/bootstrap"
@@ -2794,6 +2795,74 @@ func TestMVCCResolveTxn(t *testing.T) {
 	}
 }
 
+// TestMVCCAcquireSharedLock verifies that replicated Shared locks can be held
+// by multiple transactions, that they conflict with the writes and Exclusive
+// locks of other transactions, and that they are released when their
+// transaction is resolved.
+func TestMVCCAcquireSharedLock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			// Both transactions hold a Shared lock, and acquiring it again is a
+			// no-op. Only Shared locks can be replicated.
+			require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, lock.Shared, testKey1))
+			require.NoError(t, MVCCAcquireLock(ctx, engine, txn2, lock.Shared, testKey1))
+			require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, lock.Shared, testKey1))
+			require.Error(t, MVCCAcquireLock(ctx, engine, txn1, lock.Exclusive, testKey1))
+			require.Error(t, MVCCAcquireLock(ctx, engine, nil, lock.Shared, testKey1))
+
+			// Shared locks have no provisional value, so they are invisible to
+			// non-locking reads.
+			value, intent, err := MVCCGet(ctx, engine, testKey1, txn2TS, MVCCGetOptions{})
+			require.NoError(t, err)
+			require.Nil(t, value)
+			require.Nil(t, intent)
+
+			// A Shared lock does not conflict with the Shared lock of another
+			// transaction, but does with its Exclusive lock or intent.
+			require.NoError(t, MVCCCheckForAcquireLock(ctx, engine, txn1, lock.Shared, testKey1))
+			err = MVCCCheckForAcquireLock(ctx, engine, txn1, lock.Exclusive, testKey1)
+			wiErr := &roachpb.WriteIntentError{}
+			require.True(t, errors.As(err, &wiErr), "unexpected error: %v", err)
+			require.Len(t, wiErr.Intents, 1)
+			require.Equal(t, txn2ID, wiErr.Intents[0].Txn.ID)
+			require.Equal(t, lock.Shared, wiErr.Intents[0].LockStrength())
+
+			err = MVCCPut(ctx, engine, nil, testKey1, txn1.ReadTimestamp, hlc.ClockTimestamp{}, value1, txn1)
+			require.True(t, errors.As(err, &wiErr), "unexpected error: %v", err)
+			require.Equal(t, txn2ID, wiErr.Intents[0].Txn.ID)
+
+			// Once txn2 commits, its lock is released and txn1 can write.
+			ok, err := MVCCResolveWriteIntent(ctx, engine, nil,
+				roachpb.MakeLockUpdate(txn2Commit, roachpb.Span{Key: testKey1}))
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.NoError(t, MVCCCheckForAcquireLock(ctx, engine, txn1, lock.Exclusive, testKey1))
+			require.NoError(t, MVCCPut(
+				ctx, engine, nil, testKey1, txn1.ReadTimestamp, hlc.ClockTimestamp{}, value1, txn1))
+
+			// Resolving txn1 releases both its Shared lock and its intent.
+			num, resumeSpan, err := MVCCResolveWriteIntentRange(ctx, engine, nil,
+				roachpb.MakeLockUpdate(txn1Commit, roachpb.Span{Key: testKey1, EndKey: testKey2}), 0)
+			require.NoError(t, err)
+			require.Nil(t, resumeSpan)
+			require.Equal(t, int64(2), num)
+			require.NoError(t, MVCCCheckForAcquireLock(ctx, engine, txn2, lock.Exclusive, testKey1))
+
+			value, intent, err = MVCCGet(ctx, engine, testKey1, txn2TS, MVCCGetOptions{})
+			require.NoError(t, err)
+			require.Nil(t, intent)
+			require.Equal(t, value1.RawBytes, value.RawBytes)
+		})
+	}
+}
+
 // TestMVCCResolveNewerIntent verifies that resolving a newer intent
 // than the committing transaction aborts the intent.
 func TestMVCCResolveNewerIntent(t *testing.T) {
@@ -4883,6 +4952,95 @@ func TestMVCCGarbageCollect(t *testing.T) {
 	}
 }
 
+// TestMVCCGarbageCollectVersionRanges verifies that versions can be removed
+// from the middle of a key's history while keeping the stats accurate.
+func TestMVCCGarbageCollectVersionRanges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			ms := &enginepb.MVCCStats{}
+			ts := func(sec int64) hlc.Timestamp { return hlc.Timestamp{WallTime: sec * 1e9} }
+			put := func(key string, sec int64) {
+				require.NoError(t, MVCCPut(ctx, engine, ms, roachpb.Key(key), ts(sec),
+					hlc.ClockTimestamp{}, roachpb.MakeValueFromString("value"), nil))
+			}
+			del := func(key string, sec int64) {
+				require.NoError(t, MVCCDelete(ctx, engine, ms, roachpb.Key(key), ts(sec),
+					hlc.ClockTimestamp{}, nil))
+			}
+			for sec := int64(1); sec <= 5; sec++ {
+				put("a", sec)
+			}
+			put("b", 1)
+			del("b", 2)
+			put("b", 3)
+			put("b", 4)
+			del("c", 1)
+			put("c", 2)
+			put("c", 3)
+
+			versionRanges := []roachpb.GCRequest_GCVersionRange{
+				{Key: roachpb.Key("a"), StartTimestamp: ts(2), EndTimestamp: ts(3)},
+				{Key: roachpb.Key("b"), StartTimestamp: ts(2), EndTimestamp: ts(3)},
+				{Key: roachpb.Key("c"), StartTimestamp: ts(2), EndTimestamp: ts(2)},
+				// Keys that don't exist, which should result in a no-op.
+				{Key: roachpb.Key("a-bad"), StartTimestamp: ts(1), EndTimestamp: ts(2)},
+			}
+			require.NoError(t, MVCCGarbageCollectVersionRanges(ctx, engine, ms, versionRanges))
+
+			expEncKeys := []MVCCKey{
+				mvccVersionKey(roachpb.Key("a"), ts(5)),
+				mvccVersionKey(roachpb.Key("a"), ts(4)),
+				mvccVersionKey(roachpb.Key("a"), ts(1)),
+				mvccVersionKey(roachpb.Key("b"), ts(4)),
+				mvccVersionKey(roachpb.Key("b"), ts(1)),
+				mvccVersionKey(roachpb.Key("c"), ts(3)),
+				mvccVersionKey(roachpb.Key("c"), ts(1)),
+			}
+			kvs, err := Scan(engine, localMax, keyMax, 0)
+			require.NoError(t, err)
+			require.Len(t, kvs, len(expEncKeys))
+			for i, kv := range kvs {
+				require.Equal(t, expEncKeys[i], kv.Key, "%d", i)
+			}
+
+			// Verify aggregated stats match computed stats after GC.
+			iter := engine.NewMVCCIterator(MVCCKeyAndIntentsIterKind, IterOptions{UpperBound: roachpb.KeyMax})
+			defer iter.Close()
+			for _, mvccStatsTest := range mvccStatsTests {
+				t.Run(mvccStatsTest.name, func(t *testing.T) {
+					expMS, err := mvccStatsTest.fn(iter, localMax, roachpb.KeyMax, ts(6).WallTime)
+					require.NoError(t, err)
+					ms.AgeTo(ts(6).WallTime)
+					assertEq(t, engine, "verification", ms, &expMS)
+				})
+			}
+
+			// The newest version of a key can't be removed.
+			require.Regexp(t, "request to GC latest value", MVCCGarbageCollectVersionRanges(
+				ctx, engine, ms, []roachpb.GCRequest_GCVersionRange{
+					{Key: roachpb.Key("a"), StartTimestamp: ts(4), EndTimestamp: ts(5)},
+				}))
+
+			// Nor can the versions below an intent that would be exposed if the
+			// intent was aborted.
+			txn := makeTxn(*txn1, ts(7))
+			require.NoError(t, MVCCPut(ctx, engine, nil, roachpb.Key("c"), txn.ReadTimestamp,
+				hlc.ClockTimestamp{}, roachpb.MakeValueFromString("intent"), txn))
+			require.Regexp(t, "request to GC versions below intent", MVCCGarbageCollectVersionRanges(
+				ctx, engine, nil, []roachpb.GCRequest_GCVersionRange{
+					{Key: roachpb.Key("c"), StartTimestamp: ts(1), EndTimestamp: ts(3)},
+				}))
+		})
+	}
+}
+
 // TestMVCCGarbageCollectNonDeleted verifies that the first value for
 // a key cannot be GC'd if it's not deleted.
 func TestMVCCGarbageCollectNonDeleted(t *testing.T) {
@@ -579,6 +579,13 @@ var charts = []sectionDescription{
 					"kv.rangefeed.catchup_scan_nanos",
 				},
 			},
+			{
+				Title: "Rangefeed Catchup Scan Admission",
+				Metrics: []string{
+					"kv.rangefeed.catchup_scan_admission_wait_nanos",
+					"kv.rangefeed.catchup_scan_yields",
+				},
+			},
 			{
 				Title: "Rangefeed Memory Allocations",
 				Metrics: []string{
@@ -586,6 +593,13 @@ var charts = []sectionDescription{
 					"kv.rangefeed.budget_allocation_blocked",
 				},
 			},
+			{
+				Title: "Rangefeed Filtering",
+				Metrics: []string{
+					"kv.rangefeed.filtered_events",
+					"kv.rangefeed.filtered_bytes",
+				},
+			},
 			{
 				Title: "Memory Usage",
 				Metrics: []string{
@@ -983,6 +997,30 @@ var charts = []sectionDescription{
 			},
 		},
 	},
+	{
+		Organization: [][]string{
+			{KVTransactionLayer, "Requests", "Storage Quotas"}},
+		Charts: []chartDescription{
+			{
+				Title:       "Targets Tracked",
+				Downsampler: DescribeAggregator_MAX,
+				Percentiles: false,
+				Metrics:     []string{"kv.storage_quota.tracked_targets"},
+			},
+			{
+				Title:       "Targets at Quota",
+				Downsampler: DescribeAggregator_MAX,
+				Percentiles: false,
+				Metrics:     []string{"kv.storage_quota.exceeded_targets"},
+			},
+			{
+				Title:       "Requests Rejected",
+				Downsampler: DescribeAggregator_MAX,
+				Percentiles: false,
+				Metrics:     []string{"kv.storage_quota.rejected_requests"},
+			},
+		},
+	},
 	{
 		Organization: [][]string{
 			{KVTransactionLayer, "Requests", "Slow"},
@@ -2953,6 +2991,19 @@ var charts = []sectionDescription{
 			},
 		},
 	},
+	{
+		Organization: [][]string{{Jobs, "Schedules", "Verify Backup"}},
+		Charts: []chartDescription{
+			{
+				Title: "Counts",
+				Metrics: []string{
+					"schedules.scheduled-verify-backup-executor.started",
+					"schedules.scheduled-verify-backup-executor.succeeded",
+					"schedules.scheduled-verify-backup-executor.failed",
+				},
+			},
+		},
+	},
 	{
 		Organization: [][]string{{Jobs, "Schedules", "SQL Stats"}},
 		Charts: []chartDescription{
@@ -2993,6 +3044,7 @@ var charts = []sectionDescription{
 					"jobs.auto_span_config_reconciliation.currently_running",
 					"jobs.auto_sql_stats_compaction.currently_running",
 					"jobs.stream_replication.currently_running",
+					"jobs.verify_backup.currently_running",
 				},
 			},
 			{
@@ -3013,6 +3065,7 @@ var charts = []sectionDescription{
 					"jobs.stream_ingestion.currently_idle",
 					"jobs.stream_replication.currently_idle",
 					"jobs.typedesc_schema_change.currently_idle",
+					"jobs.verify_backup.currently_idle",
 				},
 			},
 			{
@@ -3189,6 +3242,17 @@ var charts = []sectionDescription{
 					"jobs.auto_sql_stats_compaction.resume_retry_error",
 				},
 			},
+			{
+				Title: "Verify Backup",
+				Metrics: []string{
+					"jobs.verify_backup.fail_or_cancel_completed",
+					"jobs.verify_backup.fail_or_cancel_failed",
+					"jobs.verify_backup.fail_or_cancel_retry_error",
+					"jobs.verify_backup.resume_completed",
+					"jobs.verify_backup.resume_failed",
+					"jobs.verify_backup.resume_retry_error",
+				},
+			},
 		},
 	},
 	{
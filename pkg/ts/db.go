@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -49,7 +50,7 @@ var TimeseriesStorageEnabled = settings.RegisterBoolSetting(
 
 // Resolution10sStorageTTL defines the maximum age of data that will be retained
 // at he 10 second resolution. Data older than this is subject to being "rolled
-// up" into the 30 minute resolution and then deleted.
+// up" into the next coarser enabled resolution and then deleted.
 var Resolution10sStorageTTL = settings.RegisterDurationSetting(
 	settings.TenantWritable,
 	"timeseries.storage.resolution_10s.ttl",
@@ -76,17 +77,41 @@ func init() {
 	_ = deprecatedResolution30StoreDuration
 }
 
+// Resolution1mStorageTTL defines the maximum age of data that will be
+// retained at the 1 minute resolution. The resolution is disabled when this is
+// zero, which is the default.
+var Resolution1mStorageTTL = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"timeseries.storage.resolution_1m.ttl",
+	"the maximum age of time series data stored at the 1 minute resolution. Data older than this "+
+		"is subject to rollup and deletion. If zero, data is not stored at this resolution.",
+	0,
+	settings.NonNegativeDuration,
+).WithPublic()
+
 // Resolution30mStorageTTL defines the maximum age of data that will be
 // retained at he 30 minute resolution. Data older than this is subject to
-// deletion.
+// deletion. The resolution is disabled when this is zero.
 var Resolution30mStorageTTL = settings.RegisterDurationSetting(
 	settings.TenantWritable,
 	"timeseries.storage.resolution_30m.ttl",
 	"the maximum age of time series data stored at the 30 minute resolution. Data older than this "+
-		"is subject to deletion.",
+		"is subject to rollup and deletion. If zero, data is not stored at this resolution.",
 	resolution30mDefaultPruneThreshold,
 ).WithPublic()
 
+// Resolution1dStorageTTL defines the maximum age of data that will be
+// retained at the 1 day resolution. The resolution is disabled when this is
+// zero, which is the default.
+var Resolution1dStorageTTL = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"timeseries.storage.resolution_1d.ttl",
+	"the maximum age of time series data stored at the 1 day resolution. Data older than this "+
+		"is subject to deletion. If zero, data is not stored at this resolution.",
+	0,
+	settings.NonNegativeDuration,
+).WithPublic()
+
 // DB provides Cockroach's Time Series API.
 type DB struct {
 	db      *kv.DB
@@ -110,7 +135,9 @@ func NewDB(db *kv.DB, settings *cluster.Settings) *DB {
 		Resolution10s: func() int64 {
 			return Resolution10sStorageTTL.Get(&settings.SV).Nanoseconds()
 		},
+		Resolution1m:   func() int64 { return Resolution1mStorageTTL.Get(&settings.SV).Nanoseconds() },
 		Resolution30m:  func() int64 { return Resolution30mStorageTTL.Get(&settings.SV).Nanoseconds() },
+		Resolution1d:   func() int64 { return Resolution1dStorageTTL.Get(&settings.SV).Nanoseconds() },
 		resolution1ns:  func() int64 { return resolution1nsDefaultRollupThreshold.Nanoseconds() },
 		resolution50ns: func() int64 { return resolution50nsDefaultPruneThreshold.Nanoseconds() },
 	}
@@ -318,17 +345,49 @@ func (db *DB) storeKvs(ctx context.Context, kvs []roachpb.KeyValue) error {
 	return db.db.Run(ctx, b)
 }
 
-// computeThresholds returns a map of timestamps for each resolution supported
-// by the system. Data at a resolution which is older than the threshold
+// computeThresholds returns a map of timestamps for each resolution enabled
+// in the system. Data at a resolution which is older than the threshold
 // timestamp for that resolution is considered eligible for deletion.
-func (db *DB) computeThresholds(timestamp int64) map[Resolution]int64 {
+func (db *DB) computeThresholds(ctx context.Context, timestamp int64) map[Resolution]int64 {
 	result := make(map[Resolution]int64, len(db.pruneThresholdByResolution))
 	for k, v := range db.pruneThresholdByResolution {
-		result[k] = timestamp - v()
+		if db.resolutionEnabled(ctx, k) {
+			result[k] = timestamp - v()
+		}
 	}
 	return result
 }
 
+// resolutionEnabled returns true if data is currently kept at the supplied
+// resolution. Rollup resolutions are disabled by setting their TTL to zero.
+func (db *DB) resolutionEnabled(ctx context.Context, r Resolution) bool {
+	threshold, ok := db.pruneThresholdByResolution[r]
+	if !ok || (r.IsRollup() && threshold() <= 0) {
+		return false
+	}
+	switch r {
+	case Resolution1m, Resolution1d:
+		// Nodes which predate these resolutions prune their data as that of
+		// an unknown resolution, so no data is written at them until all the
+		// nodes know about them.
+		return db.st.Version.IsActive(ctx, clusterversion.TimeseriesRollupResolutions)
+	}
+	return true
+}
+
+// TargetRollupResolution returns a target resolution that data from the
+// supplied resolution should be rolled up into in lieu of deletion: the next
+// coarser resolution which is enabled. For example, Resolution10s has a target
+// rollup resolution of Resolution30m unless Resolution1m is enabled.
+func (db *DB) TargetRollupResolution(ctx context.Context, r Resolution) (Resolution, bool) {
+	for _, target := range r.coarserResolutions() {
+		if db.resolutionEnabled(ctx, target) {
+			return target, true
+		}
+	}
+	return r, false
+}
+
 // PruneThreshold returns the pruning threshold duration for this resolution,
 // expressed in nanoseconds. This duration determines how old time series data
 // must be before it is eligible for pruning.
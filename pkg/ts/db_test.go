@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -315,7 +316,7 @@ func (tm *testModelRunner) prune(nowNanos int64, timeSeries ...timeSeriesResolut
 
 	// Prune the appropriate resolution-specific series from the test model using
 	// VisitSeries.
-	thresholds := tm.DB.computeThresholds(nowNanos)
+	thresholds := tm.DB.computeThresholds(context.Background(), nowNanos)
 	for _, ts := range timeSeries {
 		tm.model.VisitSeries(
 			resolutionModelKey(ts.Name, ts.Resolution),
@@ -364,7 +365,7 @@ func (tm *testModelRunner) rollupWithMemoryContext(
 
 	// Prune the appropriate resolution-specific series from the test model using
 	// VisitSeries.
-	thresholds := tm.DB.computeThresholds(nowNanos)
+	thresholds := tm.DB.computeThresholds(context.Background(), nowNanos)
 	for _, ts := range timeSeries {
 		// Track any data series which are pruned from the original resolution -
 		// they will be recorded into the rollup resolution.
@@ -391,7 +392,7 @@ func (tm *testModelRunner) rollupWithMemoryContext(
 			},
 		)
 		for _, data := range toRecord {
-			targetResolution, _ := ts.Resolution.TargetRollupResolution()
+			targetResolution, _ := tm.DB.TargetRollupResolution(context.Background(), ts.Resolution)
 			tm.model.Record(
 				resolutionModelKey(ts.Name, targetResolution),
 				data.source,
@@ -424,7 +425,7 @@ func (tm *testModelRunner) maintain(nowNanos int64) {
 
 	// Prune the appropriate resolution-specific series from the test model using
 	// VisitSeries.
-	thresholds := tm.DB.computeThresholds(nowNanos)
+	thresholds := tm.DB.computeThresholds(context.Background(), nowNanos)
 
 	// Track any data series which has been marked for rollup, and record it into
 	// the correct target resolution.
@@ -443,7 +444,7 @@ func (tm *testModelRunner) maintain(nowNanos int64) {
 			if !ok {
 				return data, false
 			}
-			targetResolution, hasRollup := res.TargetRollupResolution()
+			targetResolution, hasRollup := tm.DB.TargetRollupResolution(context.Background(), res)
 			if hasRollup && tm.DB.WriteRollups() {
 				pruned := data.TimeSlice(thresholds[res], math.MaxInt64)
 				if len(pruned) != len(data) {
@@ -555,40 +556,35 @@ func (mq *modelQuery) queryDB() ([]tspb.TimeSeriesDatapoint, []string, error) {
 }
 
 func (mq *modelQuery) queryModel() testmodel.DataSeries {
+	ctx := context.Background()
+	resolutions := []Resolution{mq.diskResolution}
+	db := mq.modelRunner.DB
+	for r, ok := db.TargetRollupResolution(ctx, mq.diskResolution); ok; r, ok = db.TargetRollupResolution(ctx, r) {
+		if mq.verifyDiskResolution(r) == nil {
+			resolutions = append([]Resolution{r}, resolutions...)
+		}
+	}
+
 	var result testmodel.DataSeries
 	startTime := mq.StartNanos
-	if rollupResolution, ok := mq.diskResolution.TargetRollupResolution(); ok &&
-		mq.verifyDiskResolution(rollupResolution) == nil {
-		result = mq.modelRunner.model.Query(
-			resolutionModelKey(mq.Name, rollupResolution),
+	for _, resolution := range resolutions {
+		result = append(result, mq.modelRunner.model.Query(
+			resolutionModelKey(mq.Name, resolution),
 			mq.Sources,
 			mq.GetDownsampler(),
 			mq.GetSourceAggregator(),
 			mq.GetDerivative(),
-			rollupResolution.SlabDuration(),
+			resolution.SlabDuration(),
 			mq.SampleDurationNanos,
-			mq.StartNanos,
+			startTime,
 			mq.EndNanos,
 			mq.InterpolationLimitNanos,
 			mq.NowNanos,
-		)
+		)...)
 		if len(result) > 0 {
 			startTime = result[len(result)-1].TimestampNanos
 		}
 	}
-	result = append(result, mq.modelRunner.model.Query(
-		resolutionModelKey(mq.Name, mq.diskResolution),
-		mq.Sources,
-		mq.GetDownsampler(),
-		mq.GetSourceAggregator(),
-		mq.GetDerivative(),
-		mq.diskResolution.SlabDuration(),
-		mq.SampleDurationNanos,
-		startTime,
-		mq.EndNanos,
-		mq.InterpolationLimitNanos,
-		mq.NowNanos,
-	)...)
 	return result
 }
 
@@ -863,3 +859,92 @@ func TestPruneThreshold(t *testing.T) {
 		}
 	})
 }
+
+// TestTargetRollupResolution verifies that data is rolled up into the next
+// coarser resolution which is enabled.
+func TestTargetRollupResolution(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	db := NewDB(nil, st)
+
+	assertTarget := func(r Resolution, expected Resolution, expectedOK bool) {
+		t.Helper()
+		target, ok := db.TargetRollupResolution(ctx, r)
+		if ok != expectedOK || (ok && target != expected) {
+			t.Errorf("expected %s to roll up into %s (%t), got %s (%t)", r, expected, expectedOK, target, ok)
+		}
+	}
+	assertEnabled := func(expected ...Resolution) {
+		t.Helper()
+		thresholds := db.computeThresholds(ctx, 0)
+		for _, r := range []Resolution{Resolution10s, Resolution1m, Resolution30m, Resolution1d} {
+			_, enabled := thresholds[r]
+			if e := containsResolution(expected, r); e != enabled {
+				t.Errorf("expected resolution %s enabled to be %t", r, e)
+			}
+		}
+	}
+
+	// By default, only the 10s and 30m resolutions are enabled.
+	assertTarget(Resolution10s, Resolution30m, true)
+	assertTarget(Resolution30m, 0, false)
+	assertTarget(resolution1ns, resolution50ns, true)
+	assertEnabled(Resolution10s, Resolution30m)
+
+	Resolution1mStorageTTL.Override(ctx, &st.SV, 30*24*time.Hour)
+	Resolution1dStorageTTL.Override(ctx, &st.SV, 365*24*time.Hour)
+	assertTarget(Resolution10s, Resolution1m, true)
+	assertTarget(Resolution1m, Resolution30m, true)
+	assertTarget(Resolution30m, Resolution1d, true)
+	assertTarget(Resolution1d, 0, false)
+	assertEnabled(Resolution10s, Resolution1m, Resolution30m, Resolution1d)
+
+	// Disabling an intermediate resolution skips it in the rollup chain.
+	Resolution30mStorageTTL.Override(ctx, &st.SV, 0)
+	assertTarget(Resolution1m, Resolution1d, true)
+	assertEnabled(Resolution10s, Resolution1m, Resolution1d)
+}
+
+// TestRollupResolutionsVersionGate verifies that the 1m and 1d resolutions are
+// not used until all nodes know about them, as older nodes prune their data.
+func TestRollupResolutionsVersionGate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettingsWithVersions(
+		clusterversion.TestingBinaryVersion,
+		clusterversion.ByKey(clusterversion.TimeseriesRollupResolutions-1),
+		true, /* initializeVersion */
+	)
+	db := NewDB(nil, st)
+	Resolution1mStorageTTL.Override(ctx, &st.SV, 30*24*time.Hour)
+	Resolution1dStorageTTL.Override(ctx, &st.SV, 365*24*time.Hour)
+
+	target, ok := db.TargetRollupResolution(ctx, Resolution10s)
+	require.True(t, ok)
+	require.Equal(t, Resolution30m, target)
+	_, ok = db.TargetRollupResolution(ctx, Resolution30m)
+	require.False(t, ok)
+	thresholds := db.computeThresholds(ctx, 0)
+	require.NotContains(t, thresholds, Resolution1m)
+	require.NotContains(t, thresholds, Resolution1d)
+
+	require.NoError(t, st.Version.SetActiveVersion(ctx, clusterversion.ClusterVersion{
+		Version: clusterversion.ByKey(clusterversion.TimeseriesRollupResolutions),
+	}))
+	target, ok = db.TargetRollupResolution(ctx, Resolution10s)
+	require.True(t, ok)
+	require.Equal(t, Resolution1m, target)
+	thresholds = db.computeThresholds(ctx, 0)
+	require.Contains(t, thresholds, Resolution1m)
+	require.Contains(t, thresholds, Resolution1d)
+}
+
+func containsResolution(resolutions []Resolution, r Resolution) bool {
+	for _, res := range resolutions {
+		if res == r {
+			return true
+		}
+	}
+	return false
+}
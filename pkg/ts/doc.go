@@ -105,6 +105,20 @@ configurable threshold (default 10 days), the data is "rolled" up so that it
 has a single datapoint per 30 minute period. This 30-minute resolution data is
 retained for 90 days by default.
 
+Additional rollup resolutions of 1 minute and 1 day are available, and are
+enabled by giving them a non-zero TTL once the cluster version which introduces
+them is active. The resolutions form a chain (10s, 1m, 30m, 1d); data is rolled
+up from a resolution into the next coarser enabled resolution of the chain, and
+rollup resolutions can be disabled by setting their TTL to zero. For example, a
+cluster could keep 10 second data for a day, 1 minute data for a month and 1 day
+data for a year. Queries read from the coarsest enabled resolution whose sample
+duration divides the requested sample duration, and fill in more recent data
+from finer resolutions.
+
+The set of resolutions is fixed, since resolutions are persisted in the keys of
+the data and nodes prune the data of resolutions they do not know about; only
+the retention of each resolution is configurable.
+
 Note that each rolled-up datapoint contains the first, last, min, max, sum,
 count and variance of the original 10 second points used to create the 30
 minute point; this means that any downsampler that could have been used on the
@@ -0,0 +1,405 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package promql
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ts"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// URLPrefix is the prefix of the Prometheus-compatible HTTP API. A
+// Prometheus data source in Grafana can use it as its URL, e.g.
+// https://localhost:8080/ts/prometheus.
+const URLPrefix = ts.URLPrefix + "prometheus/"
+
+const (
+	// maxPointsPerSeries limits the resolution of range queries, as in
+	// Prometheus.
+	maxPointsPerSeries = 11000
+	// maxSeriesNames limits the number of metrics that a series request
+	// can match, since the sources of each of them are queried.
+	maxSeriesNames = 1000
+	// defaultSeriesWindow is the time range of series requests that do
+	// not specify one.
+	defaultSeriesWindow = time.Hour
+)
+
+// Handler serves a read-only subset of the Prometheus HTTP API over the
+// time series database:
+//
+//	/api/v1/query        evaluates an instant query.
+//	/api/v1/query_range  evaluates a range query.
+//	/api/v1/series       lists the series matching selectors.
+//
+// The supported subset of PromQL consists of vector selectors, rate()
+// and irate(), the sum, avg, min and max aggregations (either across
+// all series or by source), histogram_quantile() for the quantiles
+// recorded for each histogram, and arithmetic with scalars.
+type Handler struct {
+	querier Querier
+	names   func() []string
+	mux     *http.ServeMux
+}
+
+// NewHandler creates a Handler. names returns the names of the series
+// that can be queried, e.g. "cr.node.sql.conns".
+func NewHandler(querier Querier, names func() []string) *Handler {
+	h := &Handler{
+		querier: querier,
+		names:   names,
+		mux:     http.NewServeMux(),
+	}
+	h.mux.HandleFunc(URLPrefix+"api/v1/query", h.handleQuery)
+	h.mux.HandleFunc(URLPrefix+"api/v1/query_range", h.handleQueryRange)
+	h.mux.HandleFunc(URLPrefix+"api/v1/series", h.handleSeries)
+	return h
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) evaluator() *evaluator {
+	return &evaluator{querier: h.querier, index: makeNameIndex(h.names())}
+}
+
+func (h *Handler) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+	t := timeutil.Now().UnixNano()
+	if v := r.FormValue("time"); v != "" {
+		var err error
+		if t, err = parseTime(v); err != nil {
+			writeError(w, errorBadData, errors.Wrap(err, "invalid parameter \"time\""))
+			return
+		}
+	}
+	ev := h.evaluator()
+	q, ok := prepareQuery(w, r, ev)
+	if !ok {
+		return
+	}
+	ss, err := ev.evalInstant(r.Context(), q, t)
+	if err != nil {
+		writeExecError(r.Context(), w, err)
+		return
+	}
+
+	if q.constant != nil {
+		writeData(w, queryData{ResultType: "scalar", Result: samplePair(ss[0].points[0])})
+		return
+	}
+	res := make([]sample, len(ss))
+	for i, s := range ss {
+		res[i] = sample{Metric: s.labels, Value: samplePair(s.points[0])}
+	}
+	writeData(w, queryData{ResultType: "vector", Result: res})
+}
+
+func (h *Handler) handleQueryRange(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+	start, err := parseTime(r.FormValue("start"))
+	if err != nil {
+		writeError(w, errorBadData, errors.Wrap(err, "invalid parameter \"start\""))
+		return
+	}
+	end, err := parseTime(r.FormValue("end"))
+	if err != nil {
+		writeError(w, errorBadData, errors.Wrap(err, "invalid parameter \"end\""))
+		return
+	}
+	if end < start {
+		writeError(w, errorBadData, errors.New("end timestamp must not be before start time"))
+		return
+	}
+	step, err := parseStep(r.FormValue("step"))
+	if err != nil {
+		writeError(w, errorBadData, errors.Wrap(err, "invalid parameter \"step\""))
+		return
+	}
+	if step <= 0 {
+		writeError(w, errorBadData, errors.New("zero or negative query resolution step widths are not accepted"))
+		return
+	}
+	if (end-start)/step > maxPointsPerSeries {
+		writeError(w, errorBadData, errors.Newf(
+			"exceeded maximum resolution of %d points per timeseries; "+
+				"try decreasing the query resolution (?step=XX)", maxPointsPerSeries))
+		return
+	}
+	// The time series database can only downsample to multiples of its
+	// resolution.
+	step = roundUpToResolution(step)
+
+	ev := h.evaluator()
+	q, ok := prepareQuery(w, r, ev)
+	if !ok {
+		return
+	}
+	ss, err := ev.evalRange(r.Context(), q, start, end, step)
+	if err != nil {
+		writeExecError(r.Context(), w, err)
+		return
+	}
+	res := make([]sampleStream, len(ss))
+	for i, s := range ss {
+		res[i] = sampleStream{Metric: s.labels, Values: make([]samplePair, len(s.points))}
+		for j, p := range s.points {
+			res[i].Values[j] = samplePair(p)
+		}
+	}
+	writeData(w, queryData{ResultType: "matrix", Result: res})
+}
+
+func (h *Handler) handleSeries(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+	matches := r.Form["match[]"]
+	if len(matches) == 0 {
+		writeError(w, errorBadData, errors.New("no match[] parameter provided"))
+		return
+	}
+	end := timeutil.Now().UnixNano()
+	if v := r.FormValue("end"); v != "" {
+		var err error
+		if end, err = parseTime(v); err != nil {
+			writeError(w, errorBadData, errors.Wrap(err, "invalid parameter \"end\""))
+			return
+		}
+	}
+	start := end - defaultSeriesWindow.Nanoseconds()
+	if v := r.FormValue("start"); v != "" {
+		var err error
+		if start, err = parseTime(v); err != nil {
+			writeError(w, errorBadData, errors.Wrap(err, "invalid parameter \"start\""))
+			return
+		}
+	}
+	if end < start {
+		writeError(w, errorBadData, errors.New("end timestamp must not be before start time"))
+		return
+	}
+	// Only the presence of data matters; use a coarse step.
+	step := roundUpToResolution((end - start) / 100)
+
+	var sels []*parser.VectorSelector
+	for _, m := range matches {
+		e, err := parse(m)
+		if err != nil {
+			writeError(w, errorBadData, errors.Wrap(err, "invalid parameter \"match[]\""))
+			return
+		}
+		sel, ok := e.(*parser.VectorSelector)
+		if !ok {
+			writeError(w, errorBadData, errors.Newf("invalid parameter \"match[]\": %q is not a vector selector", m))
+			return
+		}
+		sels = append(sels, sel)
+	}
+
+	ev := h.evaluator()
+	seen := make(map[string]struct{})
+	res := []map[string]string{}
+	for _, sel := range sels {
+		labelSets, err := ev.matchSeries(r.Context(), sel, start, end, step)
+		if err != nil {
+			writeExecError(r.Context(), w, err)
+			return
+		}
+		for _, l := range labelSets {
+			key := l[nameLabel] + "\x00" + l[nodeLabel] + "\x00" + l[storeLabel]
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			res = append(res, l)
+		}
+	}
+	writeData(w, res)
+}
+
+// prepareQuery parses and plans the "query" parameter, and reports an
+// error to the client if it cannot be evaluated.
+func prepareQuery(w http.ResponseWriter, r *http.Request, ev *evaluator) (preparedQuery, bool) {
+	e, err := parse(r.FormValue("query"))
+	if err != nil {
+		writeError(w, errorBadData, errors.Wrap(err, "invalid parameter \"query\""))
+		return preparedQuery{}, false
+	}
+	q, err := ev.index.prepare(e)
+	if err != nil {
+		writeError(w, errorBadData, errors.Wrap(err, "invalid parameter \"query\""))
+		return preparedQuery{}, false
+	}
+	return q, true
+}
+
+// parseForm parses the parameters of the request, which can be passed
+// either in the URL or in the body of a POST request.
+func parseForm(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, errorBadData, errors.Wrap(err, "error parsing form values"))
+		return false
+	}
+	return true
+}
+
+// parseTime parses a timestamp, either in RFC 3339 format or as a
+// number of seconds since the Unix epoch, into nanoseconds.
+func parseTime(s string) (int64, error) {
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return int64(math.Round(secs * float64(time.Second))), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, errors.Newf("cannot parse %q to a valid timestamp", s)
+	}
+	return t.UnixNano(), nil
+}
+
+// parseStep parses a duration, either as a PromQL duration or as a
+// number of seconds, into nanoseconds.
+func parseStep(s string) (int64, error) {
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return int64(math.Round(secs * float64(time.Second))), nil
+	}
+	d, err := parseDuration(s)
+	if err != nil {
+		return 0, errors.Newf("cannot parse %q to a valid duration", s)
+	}
+	return d.Nanoseconds(), nil
+}
+
+func roundUpToResolution(step int64) int64 {
+	if step < resolution {
+		return resolution
+	}
+	if rem := step % resolution; rem != 0 {
+		step += resolution - rem
+	}
+	return step
+}
+
+// Error types reported to the client, as in Prometheus.
+type errorType string
+
+const (
+	errorBadData  errorType = "bad_data"
+	errorExec     errorType = "execution"
+	errorTimeout  errorType = "timeout"
+	errorCanceled errorType = "canceled"
+)
+
+var errorStatus = map[errorType]int{
+	errorBadData:  http.StatusBadRequest,
+	errorExec:     http.StatusUnprocessableEntity,
+	errorTimeout:  http.StatusServiceUnavailable,
+	errorCanceled: http.StatusServiceUnavailable,
+}
+
+type apiResponse struct {
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	ErrorType errorType   `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+type queryData struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+}
+
+type sample struct {
+	Metric map[string]string `json:"metric"`
+	Value  samplePair        `json:"value"`
+}
+
+type sampleStream struct {
+	Metric map[string]string `json:"metric"`
+	Values []samplePair      `json:"values"`
+}
+
+// samplePair is encoded as a pair of a timestamp in seconds and a value
+// formatted as a string.
+type samplePair tspb.TimeSeriesDatapoint
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p samplePair) MarshalJSON() ([]byte, error) {
+	ts := strconv.FormatFloat(float64(p.TimestampNanos)/float64(time.Second), 'f', -1, 64)
+	return []byte("[" + ts + "," + strconv.Quote(formatValue(p.Value)) + "]"), nil
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, http.StatusOK, apiResponse{Status: "success", Data: data})
+}
+
+func writeError(w http.ResponseWriter, typ errorType, err error) {
+	writeJSON(w, errorStatus[typ], apiResponse{Status: "error", ErrorType: typ, Error: err.Error()})
+}
+
+// writeExecError reports an error encountered while querying the time
+// series database.
+func writeExecError(ctx context.Context, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, errorTimeout, err)
+	case errors.Is(err, context.Canceled):
+		writeError(w, errorCanceled, err)
+	default:
+		log.Warningf(ctx, "error evaluating prometheus query: %v", err)
+		writeError(w, errorExec, err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp apiResponse) {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,280 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package promql
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQuerier serves queries from in-memory data, keyed by series name
+// and source. It records the queries it receives.
+type fakeQuerier struct {
+	data map[string]map[string][]tspb.TimeSeriesDatapoint
+
+	mu struct {
+		syncutil.Mutex
+		queries []tspb.Query
+		sample  []int64
+	}
+}
+
+func (f *fakeQuerier) Query(
+	_ context.Context, req *tspb.TimeSeriesQueryRequest,
+) (*tspb.TimeSeriesQueryResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &tspb.TimeSeriesQueryResponse{}
+	for _, q := range req.Queries {
+		f.mu.queries = append(f.mu.queries, q)
+		f.mu.sample = append(f.mu.sample, req.SampleNanos)
+
+		bySource := f.data[q.Name]
+		sources := q.Sources
+		if len(sources) == 0 {
+			for s := range bySource {
+				sources = append(sources, s)
+			}
+			sort.Strings(sources)
+		}
+		values := make(map[int64][]float64)
+		for _, s := range sources {
+			for _, dp := range bySource[s] {
+				values[dp.TimestampNanos] = append(values[dp.TimestampNanos], dp.Value)
+			}
+		}
+		var timestamps []int64
+		for t := range values {
+			timestamps = append(timestamps, t)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		result := tspb.TimeSeriesQueryResponse_Result{Query: q, Sources: sources}
+		for _, t := range timestamps {
+			var agg float64
+			for i, v := range values[t] {
+				switch q.GetSourceAggregator() {
+				case tspb.TimeSeriesQueryAggregator_SUM:
+					agg += v
+				case tspb.TimeSeriesQueryAggregator_MAX:
+					if i == 0 || v > agg {
+						agg = v
+					}
+				}
+			}
+			result.Datapoints = append(result.Datapoints, tspb.TimeSeriesDatapoint{TimestampNanos: t, Value: agg})
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+// lastQueries returns the queries received since the last call.
+func (f *fakeQuerier) lastQueries() ([]tspb.Query, []int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q, s := f.mu.queries, f.mu.sample
+	f.mu.queries, f.mu.sample = nil, nil
+	return q, s
+}
+
+func points(values ...float64) []tspb.TimeSeriesDatapoint {
+	res := make([]tspb.TimeSeriesDatapoint, len(values))
+	for i, v := range values {
+		res[i] = tspb.TimeSeriesDatapoint{
+			TimestampNanos: int64(i+8) * (10 * time.Second).Nanoseconds(),
+			Value:          v,
+		}
+	}
+	return res
+}
+
+func TestHandler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	q := &fakeQuerier{
+		data: map[string]map[string][]tspb.TimeSeriesDatapoint{
+			"cr.node.sql.conns": {
+				"1": points(3, 4),
+				"2": points(5, 6),
+			},
+			"cr.node.sql.service.latency-p99": {
+				"1": points(100, 200),
+				"2": points(300, 150),
+			},
+			"cr.store.replicas": {
+				"1": points(10, 11),
+				"2": points(20, 21),
+			},
+		},
+	}
+	names := func() []string {
+		return []string{
+			"cr.node.sql.conns",
+			"cr.node.sql.service.latency-p99",
+			"cr.store.replicas",
+			"other.series",
+		}
+	}
+	srv := httptest.NewServer(NewHandler(q, names))
+	defer srv.Close()
+
+	get := func(path string, params url.Values) (int, string) {
+		resp, err := http.Get(srv.URL + URLPrefix + path + "?" + params.Encode())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(b)
+	}
+
+	t.Run("instant query", func(t *testing.T) {
+		code, body := get("api/v1/query", url.Values{"query": {"sql_conns"}, "time": {"100"}})
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"sql_conns","node_id":"1"},"value":[100,"4"]},
+			{"metric":{"__name__":"sql_conns","node_id":"2"},"value":[100,"6"]}]}}`, body)
+
+		queries, sample := q.lastQueries()
+		// The sources are discovered first, then each one is queried.
+		require.Len(t, queries, 3)
+		require.Equal(t, []string{"1"}, queries[1].Sources)
+		require.Equal(t, []string{"2"}, queries[2].Sources)
+		require.Equal(t, resolution, sample[1])
+	})
+
+	t.Run("post form", func(t *testing.T) {
+		resp, err := http.PostForm(srv.URL+URLPrefix+"api/v1/query",
+			url.Values{"query": {"sum(sql_conns)"}, "time": {"2022-01-01T00:00:00Z"}})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_, _ = q.lastQueries()
+	})
+
+	t.Run("range query", func(t *testing.T) {
+		code, body := get("api/v1/query_range", url.Values{
+			"query": {"sum(rate(sql_conns[5m])) * 2"},
+			"start": {"80"},
+			"end":   {"90"},
+			"step":  {"15s"},
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{},"values":[[80,"16"],[90,"20"]]}]}}`, body)
+
+		queries, sample := q.lastQueries()
+		// Aggregating all sources does not require discovering them.
+		require.Len(t, queries, 1)
+		require.Equal(t, tspb.TimeSeriesQueryAggregator_SUM, queries[0].GetSourceAggregator())
+		require.Equal(t, tspb.TimeSeriesQueryDerivative_NON_NEGATIVE_DERIVATIVE, queries[0].GetDerivative())
+		// The step is rounded up to a multiple of the resolution.
+		require.Equal(t, (20 * time.Second).Nanoseconds(), sample[0])
+	})
+
+	t.Run("store metric", func(t *testing.T) {
+		code, body := get("api/v1/query", url.Values{
+			"query": {`max by (store) (replicas{store=~"2|3"})`},
+			"time":  {"100"},
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"store":"2"},"value":[100,"21"]}]}}`, body)
+		_, _ = q.lastQueries()
+	})
+
+	t.Run("histogram quantile", func(t *testing.T) {
+		code, body := get("api/v1/query", url.Values{
+			"query": {`histogram_quantile(0.99, sum by (le) (rate(sql_service_latency_bucket[5m])))`},
+			"time":  {"100"},
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{},"value":[100,"200"]}]}}`, body)
+
+		queries, _ := q.lastQueries()
+		require.Len(t, queries, 1)
+		require.Equal(t, "cr.node.sql.service.latency-p99", queries[0].Name)
+		require.Equal(t, tspb.TimeSeriesQueryAggregator_MAX, queries[0].GetSourceAggregator())
+		require.Equal(t, tspb.TimeSeriesQueryDerivative_NONE, queries[0].GetDerivative())
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		code, body := get("api/v1/query", url.Values{"query": {"1+1"}, "time": {"100.5"}})
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"status":"success","data":{"resultType":"scalar","result":[100.5,"2"]}}`, body)
+	})
+
+	t.Run("unknown metric", func(t *testing.T) {
+		code, body := get("api/v1/query", url.Values{"query": {"nonexistent"}, "time": {"100"}})
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"status":"success","data":{"resultType":"vector","result":[]}}`, body)
+	})
+
+	t.Run("series", func(t *testing.T) {
+		code, body := get("api/v1/series", url.Values{
+			"match[]": {`{__name__=~"sql_.*", node_id!="2"}`, `replicas`},
+			"start":   {"0"},
+			"end":     {"100"},
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.JSONEq(t, `{"status":"success","data":[
+			{"__name__":"sql_conns","node_id":"1"},
+			{"__name__":"sql_service_latency_p99","node_id":"1"},
+			{"__name__":"replicas","store":"1"},
+			{"__name__":"replicas","store":"2"}]}`, body)
+		_, _ = q.lastQueries()
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			path   string
+			params url.Values
+			err    string
+		}{
+			{"api/v1/query", url.Values{"query": {"sql_conns{"}},
+				`invalid parameter \"query\": 1:11: parse error: unexpected end of input inside braces`},
+			{"api/v1/query", url.Values{"query": {"histogram_quantile(0.42, sql_service_latency)"}},
+				`quantile 0.42 is not recorded`},
+			{"api/v1/query", url.Values{"query": {"sum by (node_id) (replicas)"}},
+				`grouping by (node_id) is not supported; cr.store.replicas can only be grouped by \"store\"`},
+			{"api/v1/query", url.Values{"query": {"sql_conns / replicas"}},
+				`binary operations between two vectors are not supported`},
+			{"api/v1/query", url.Values{"query": {"deriv(sql_conns[5m])"}},
+				`unsupported function deriv()`},
+			{"api/v1/query", url.Values{"query": {"sql_conns"}, "time": {"yesterday"}},
+				`cannot parse \"yesterday\" to a valid timestamp`},
+			{"api/v1/query_range", url.Values{"query": {"sql_conns"}, "start": {"10"}, "end": {"0"}, "step": {"10"}},
+				`end timestamp must not be before start time`},
+			{"api/v1/query_range", url.Values{"query": {"sql_conns"}, "start": {"0"}, "end": {"1000000"}, "step": {"1"}},
+				`exceeded maximum resolution`},
+			{"api/v1/series", url.Values{}, `no match[] parameter provided`},
+			{"api/v1/series", url.Values{"match[]": {"rate(sql_conns[5m])"}}, `is not a vector selector`},
+		} {
+			code, body := get(tc.path, tc.params)
+			require.Equal(t, http.StatusBadRequest, code, body)
+			require.True(t, strings.Contains(body, `"errorType":"bad_data"`), body)
+			require.Contains(t, body, tc.err)
+		}
+	})
+}
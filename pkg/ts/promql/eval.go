@@ -0,0 +1,724 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package promql
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ts"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// Prefixes of the names under which node-level and store-level metrics
+// are recorded in the time series database.
+const (
+	nodeSeriesPrefix  = "cr.node."
+	storeSeriesPrefix = "cr.store."
+)
+
+// Labels reported on the series. The labels identifying the source of
+// a series are the same as those exported by the Prometheus endpoint
+// of each node.
+const (
+	nameLabel  = "__name__"
+	nodeLabel  = "node_id"
+	storeLabel = "store"
+)
+
+// lookbackDelta is how far back an instant query looks for a sample,
+// as in Prometheus.
+const lookbackDelta = 5 * time.Minute
+
+// resolution is the resolution at which the time series are queried.
+// Query steps are rounded up to a multiple of it.
+var resolution = ts.Resolution10s.SampleDuration()
+
+// histogramQuantiles maps the quantiles recorded for each histogram to
+// the suffix of the series under which they are recorded. Histograms
+// are not stored as buckets, so these are the only quantiles that can
+// be queried.
+var histogramQuantiles = map[float64]string{
+	1:       "max",
+	0.99999: "p99.999",
+	0.9999:  "p99.99",
+	0.999:   "p99.9",
+	0.99:    "p99",
+	0.9:     "p90",
+	0.75:    "p75",
+	0.5:     "p50",
+}
+
+// seriesInfo identifies the time series recorded for a metric.
+type seriesInfo struct {
+	// name is the name of the series in the time series database.
+	name string
+	// sourceLabel is the label under which the source of each series
+	// is reported.
+	sourceLabel string
+}
+
+// nameIndex maps Prometheus metric names to time series.
+type nameIndex map[string]seriesInfo
+
+// makeNameIndex creates a nameIndex from the names of the series in
+// the time series database. The Prometheus name of a series is the
+// name of the metric as exported by the Prometheus endpoint of each
+// node, e.g. sql_conns for cr.node.sql.conns.
+func makeNameIndex(names []string) nameIndex {
+	idx := make(nameIndex, len(names))
+	for _, n := range names {
+		var info seriesInfo
+		var metric string
+		switch {
+		case strings.HasPrefix(n, nodeSeriesPrefix):
+			info = seriesInfo{name: n, sourceLabel: nodeLabel}
+			metric = strings.TrimPrefix(n, nodeSeriesPrefix)
+		case strings.HasPrefix(n, storeSeriesPrefix):
+			info = seriesInfo{name: n, sourceLabel: storeLabel}
+			metric = strings.TrimPrefix(n, storeSeriesPrefix)
+		default:
+			continue
+		}
+		idx[exportedName(metric)] = info
+	}
+	return idx
+}
+
+// plan describes how an expression is evaluated against the time
+// series database.
+type plan struct {
+	series seriesInfo
+	// sourceMatchers restrict the sources that are queried.
+	sourceMatchers []*labels.Matcher
+	// empty is set when the expression cannot match any series, for
+	// example because it selects a metric that does not exist.
+	empty       bool
+	downsampler tspb.TimeSeriesQueryAggregator
+	derivative  tspb.TimeSeriesQueryDerivative
+	// aggregator, if set, combines all the matching sources into a
+	// single series. Otherwise, one series is returned per source.
+	aggregator *tspb.TimeSeriesQueryAggregator
+	// keepName indicates whether the metric name is reported in the
+	// labels of the resulting series.
+	keepName bool
+	// ops are the arithmetic operations with a scalar applied to each
+	// value, in order.
+	ops []scalarOp
+}
+
+// scalarOp is an arithmetic operation between a series and a scalar.
+type scalarOp struct {
+	op  parser.ItemType
+	val float64
+	// scalarOnLeft is set when the scalar is the left operand.
+	scalarOnLeft bool
+}
+
+func (o scalarOp) apply(v float64) float64 {
+	if o.scalarOnLeft {
+		return arith(o.op, o.val, v)
+	}
+	return arith(o.op, v, o.val)
+}
+
+func arith(op parser.ItemType, a, b float64) float64 {
+	switch op {
+	case parser.ADD:
+		return a + b
+	case parser.SUB:
+		return a - b
+	case parser.MUL:
+		return a * b
+	case parser.DIV:
+		return a / b
+	default:
+		panic(errors.AssertionFailedf("unknown operator %s", op))
+	}
+}
+
+// isArithOp returns whether op is one of the supported arithmetic
+// operators.
+func isArithOp(op parser.ItemType) bool {
+	switch op {
+	case parser.ADD, parser.SUB, parser.MUL, parser.DIV:
+		return true
+	default:
+		return false
+	}
+}
+
+// constantValue returns the value of an expression that does not
+// depend on any time series.
+func constantValue(e parser.Expr) (float64, bool) {
+	switch e := unwrapParens(e).(type) {
+	case *parser.NumberLiteral:
+		return e.Val, true
+	case *parser.UnaryExpr:
+		v, ok := constantValue(e.Expr)
+		if !ok {
+			return 0, false
+		}
+		if e.Op == parser.SUB {
+			v = -v
+		}
+		return v, true
+	case *parser.BinaryExpr:
+		if !isArithOp(e.Op) {
+			return 0, false
+		}
+		l, ok := constantValue(e.LHS)
+		if !ok {
+			return 0, false
+		}
+		r, ok := constantValue(e.RHS)
+		if !ok {
+			return 0, false
+		}
+		return arith(e.Op, l, r), true
+	default:
+		return 0, false
+	}
+}
+
+// preparedQuery is an expression ready to be evaluated.
+type preparedQuery struct {
+	// constant is set if the expression does not depend on any time
+	// series. Otherwise, plan is set.
+	constant *float64
+	plan     *plan
+}
+
+// prepare checks that the expression is supported and plans its
+// evaluation.
+func (idx nameIndex) prepare(e parser.Expr) (preparedQuery, error) {
+	if v, ok := constantValue(e); ok {
+		return preparedQuery{constant: &v}, nil
+	}
+	p, err := idx.plan(e)
+	if err != nil {
+		return preparedQuery{}, err
+	}
+	return preparedQuery{plan: p}, nil
+}
+
+func (idx nameIndex) plan(e parser.Expr) (*plan, error) {
+	switch e := e.(type) {
+	case *parser.ParenExpr:
+		return idx.plan(e.Expr)
+
+	case *parser.VectorSelector:
+		p, err := idx.planSelector(e)
+		if err != nil {
+			return nil, err
+		}
+		p.keepName = true
+		return p, nil
+
+	case *parser.MatrixSelector:
+		return nil, errors.New("range vector selectors are only supported as the argument of rate() and irate()")
+
+	case *parser.Call:
+		switch e.Func.Name {
+		case "rate", "irate":
+			// The derivative computed by the time series database is
+			// always based on consecutive samples; the range of the
+			// selector does not influence the result. The parser checks
+			// that the argument is a range vector.
+			sel, ok := unwrapParens(e.Args[0]).(*parser.MatrixSelector)
+			if !ok {
+				return nil, errors.Newf("expected a range vector selector as the argument of %s()", e.Func.Name)
+			}
+			p, err := idx.planSelector(sel.VectorSelector.(*parser.VectorSelector))
+			if err != nil {
+				return nil, err
+			}
+			p.derivative = tspb.TimeSeriesQueryDerivative_NON_NEGATIVE_DERIVATIVE
+			return p, nil
+		case "histogram_quantile":
+			return idx.planHistogramQuantile(e)
+		default:
+			return nil, errors.Newf("unsupported function %s()", e.Func.Name)
+		}
+
+	case *parser.AggregateExpr:
+		if _, ok := aggregateOpFns[e.Op]; !ok {
+			return nil, errors.Newf("unsupported aggregation %s", e.Op)
+		}
+		if e.Without {
+			return nil, errors.New("aggregation with \"without\" is not supported")
+		}
+		p, err := idx.plan(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if p.aggregator != nil {
+			return nil, errors.New("nested aggregations are not supported")
+		}
+		if len(p.ops) > 0 {
+			return nil, errors.New("aggregating the result of an arithmetic operation is not supported")
+		}
+		if err := p.aggregate(e.Op, e.Grouping); err != nil {
+			return nil, err
+		}
+		return p, nil
+
+	case *parser.UnaryExpr:
+		p, err := idx.plan(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == parser.SUB {
+			p.ops = append(p.ops, scalarOp{op: parser.MUL, val: -1})
+			p.keepName = false
+		}
+		return p, nil
+
+	case *parser.BinaryExpr:
+		if !isArithOp(e.Op) {
+			return nil, errors.Newf("unsupported operator %s", e.Op)
+		}
+		if v, ok := constantValue(e.LHS); ok {
+			p, err := idx.plan(e.RHS)
+			if err != nil {
+				return nil, err
+			}
+			p.ops = append(p.ops, scalarOp{op: e.Op, val: v, scalarOnLeft: true})
+			p.keepName = false
+			return p, nil
+		}
+		if v, ok := constantValue(e.RHS); ok {
+			p, err := idx.plan(e.LHS)
+			if err != nil {
+				return nil, err
+			}
+			p.ops = append(p.ops, scalarOp{op: e.Op, val: v})
+			p.keepName = false
+			return p, nil
+		}
+		return nil, errors.New("binary operations between two vectors are not supported")
+
+	default:
+		return nil, errors.Newf("unsupported expression %s", e)
+	}
+}
+
+// planSelector plans the evaluation of a vector selector.
+func (idx nameIndex) planSelector(sel *parser.VectorSelector) (*plan, error) {
+	name, matchers, err := selectorName(sel)
+	if err != nil {
+		return nil, err
+	}
+	return idx.planSeries(name, matchers), nil
+}
+
+// planSeries plans the evaluation of the series of the named metric
+// which match the matchers on labels other than the name.
+func (idx nameIndex) planSeries(name string, matchers []*labels.Matcher) *plan {
+	p := &plan{downsampler: tspb.TimeSeriesQueryAggregator_AVG}
+	info, ok := idx[name]
+	if !ok {
+		// As in Prometheus, selecting an unknown metric is not an error.
+		p.empty = true
+		return p
+	}
+	p.series = info
+	for _, m := range matchers {
+		if m.Name == info.sourceLabel {
+			p.sourceMatchers = append(p.sourceMatchers, m)
+		} else if !m.Matches("") {
+			// The series do not have any other label.
+			p.empty = true
+		}
+	}
+	return p
+}
+
+// selectorName returns the metric name selected by a vector selector,
+// along with its matchers on labels other than the name.
+func selectorName(sel *parser.VectorSelector) (string, []*labels.Matcher, error) {
+	if sel.OriginalOffset != 0 || sel.Timestamp != nil || sel.StartOrEnd != 0 {
+		return "", nil, errors.New("offset and @ modifiers are not supported")
+	}
+	// The parser adds a matcher on the name of the metric if it is
+	// specified outside of the braces.
+	var name string
+	var matchers []*labels.Matcher
+	for _, m := range sel.LabelMatchers {
+		if m.Name != nameLabel {
+			matchers = append(matchers, m)
+			continue
+		}
+		if m.Type != labels.MatchEqual {
+			return "", nil, errors.Newf("only equality matchers are supported on %s in queries", nameLabel)
+		}
+		if name != "" && name != m.Value {
+			return "", nil, errors.Newf("metric name %q conflicts with matcher %s=%q", name, nameLabel, m.Value)
+		}
+		name = m.Value
+	}
+	return name, matchers, nil
+}
+
+// planHistogramQuantile plans the evaluation of histogram_quantile().
+//
+// Histograms are recorded in the time series database as one series
+// per quantile, so the expression maps onto the series of the requested
+// quantile. The histogram can be selected either by its name or by the
+// name of its buckets, and can be wrapped in rate() and in an
+// aggregation by "le", e.g.:
+//
+//	histogram_quantile(0.99, sum by (le) (rate(sql_service_latency_bucket[5m])))
+//
+// Quantiles of different sources cannot be combined exactly. When
+// sources are aggregated, the maximum of their quantiles is reported,
+// like in the DB Console.
+func (idx nameIndex) planHistogramQuantile(c *parser.Call) (*plan, error) {
+	phi, ok := constantValue(c.Args[0])
+	if !ok {
+		return nil, errors.New("the first argument of histogram_quantile() must be a number")
+	}
+	suffix, ok := histogramQuantiles[phi]
+	if !ok {
+		supported := make([]float64, 0, len(histogramQuantiles))
+		for q := range histogramQuantiles {
+			supported = append(supported, q)
+		}
+		sort.Float64s(supported)
+		return nil, errors.Newf("quantile %v is not recorded; the supported quantiles are %v", phi, supported)
+	}
+
+	inner := unwrapParens(c.Args[1])
+	agg, isAgg := inner.(*parser.AggregateExpr)
+	if isAgg {
+		if agg.Without {
+			return nil, errors.New("aggregation with \"without\" is not supported")
+		}
+		inner = unwrapParens(agg.Expr)
+	}
+	if f, ok := inner.(*parser.Call); ok && (f.Func.Name == "rate" || f.Func.Name == "irate") {
+		inner = unwrapParens(f.Args[0])
+	}
+	if m, ok := inner.(*parser.MatrixSelector); ok {
+		inner = m.VectorSelector
+	}
+	sel, ok := inner.(*parser.VectorSelector)
+	if !ok {
+		return nil, errors.New("the second argument of histogram_quantile() must select a histogram, " +
+			"optionally within rate() and an aggregation")
+	}
+	name, matchers, err := selectorName(sel)
+	if err != nil {
+		return nil, err
+	}
+	var quantileMatchers []*labels.Matcher
+	for _, m := range matchers {
+		if m.Name != "le" {
+			quantileMatchers = append(quantileMatchers, m)
+		}
+	}
+	p := idx.planSeries(strings.TrimSuffix(name, "_bucket")+"_"+exportedName(suffix), quantileMatchers)
+	p.downsampler = tspb.TimeSeriesQueryAggregator_MAX
+	if isAgg {
+		var grouping []string
+		for _, l := range agg.Grouping {
+			if l != "le" {
+				grouping = append(grouping, l)
+			}
+		}
+		if err := p.aggregate(parser.MAX, grouping); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// aggregateOpFns maps the aggregation operators to the corresponding
+// source aggregators.
+var aggregateOpFns = map[parser.ItemType]tspb.TimeSeriesQueryAggregator{
+	parser.SUM: tspb.TimeSeriesQueryAggregator_SUM,
+	parser.AVG: tspb.TimeSeriesQueryAggregator_AVG,
+	parser.MIN: tspb.TimeSeriesQueryAggregator_MIN,
+	parser.MAX: tspb.TimeSeriesQueryAggregator_MAX,
+}
+
+// aggregate applies an aggregation to the plan. Sources can only be
+// aggregated all together, or grouped by source, which leaves each
+// series as is.
+func (p *plan) aggregate(op parser.ItemType, grouping []string) error {
+	fn, ok := aggregateOpFns[op]
+	if !ok {
+		return errors.AssertionFailedf("unknown aggregation %s", op)
+	}
+	p.keepName = false
+	switch {
+	case len(grouping) == 0:
+		p.aggregator = &fn
+		return nil
+	case p.empty:
+		return nil
+	case len(grouping) == 1 && grouping[0] == p.series.sourceLabel:
+		return nil
+	default:
+		return errors.Newf("grouping by (%s) is not supported; %s can only be grouped by %q",
+			strings.Join(grouping, ", "), p.series.name, p.series.sourceLabel)
+	}
+}
+
+// series is a time series resulting from the evaluation of an
+// expression.
+type series struct {
+	labels map[string]string
+	points []tspb.TimeSeriesDatapoint
+}
+
+// Querier queries the time series database. It is implemented by
+// *ts.Server.
+type Querier interface {
+	Query(context.Context, *tspb.TimeSeriesQueryRequest) (*tspb.TimeSeriesQueryResponse, error)
+}
+
+// evaluator evaluates prepared queries.
+type evaluator struct {
+	querier Querier
+	index   nameIndex
+}
+
+// evalRange evaluates a query at each step of the time range. All
+// timestamps are in nanoseconds; the step must be a multiple of the
+// resolution.
+func (ev *evaluator) evalRange(
+	ctx context.Context, q preparedQuery, start, end, step int64,
+) ([]series, error) {
+	if q.constant != nil {
+		s := series{labels: map[string]string{}}
+		for t := start; t <= end; t += step {
+			s.points = append(s.points, tspb.TimeSeriesDatapoint{TimestampNanos: t, Value: *q.constant})
+		}
+		return []series{s}, nil
+	}
+
+	p := q.plan
+	if p.empty {
+		return nil, nil
+	}
+	var sources []string
+	if p.aggregator == nil || len(p.sourceMatchers) > 0 {
+		all, err := ev.sources(ctx, []string{p.series.name}, start, end, step)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range all[0] {
+			if matchesAll(p.sourceMatchers, s) {
+				sources = append(sources, s)
+			}
+		}
+		if len(sources) == 0 {
+			return nil, nil
+		}
+	}
+
+	makeQuery := func(sources []string) tspb.Query {
+		q := tspb.Query{
+			Name:        p.series.name,
+			Downsampler: p.downsampler.Enum(),
+			Derivative:  p.derivative.Enum(),
+			Sources:     sources,
+		}
+		if p.aggregator != nil {
+			q.SourceAggregator = p.aggregator.Enum()
+		}
+		return q
+	}
+	req := &tspb.TimeSeriesQueryRequest{
+		StartNanos:  start,
+		EndNanos:    end,
+		SampleNanos: step,
+	}
+	var seriesLabels []map[string]string
+	if p.aggregator != nil {
+		req.Queries = []tspb.Query{makeQuery(sources)}
+		seriesLabels = append(seriesLabels, map[string]string{})
+	} else {
+		for _, s := range sources {
+			req.Queries = append(req.Queries, makeQuery([]string{s}))
+			seriesLabels = append(seriesLabels, map[string]string{p.series.sourceLabel: s})
+		}
+	}
+	resp, err := ev.querier.Query(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []series
+	for i, r := range resp.Results {
+		if len(r.Datapoints) == 0 {
+			continue
+		}
+		s := series{labels: seriesLabels[i], points: r.Datapoints}
+		if p.keepName {
+			s.labels[nameLabel] = promName(p.series)
+		}
+		for j := range s.points {
+			for _, op := range p.ops {
+				s.points[j].Value = op.apply(s.points[j].Value)
+			}
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// evalInstant evaluates a query at a single point in time. For each
+// series, the most recent sample within the lookback delta is
+// reported, with the evaluation timestamp. A constant query results in
+// a single series without labels.
+func (ev *evaluator) evalInstant(ctx context.Context, q preparedQuery, t int64) ([]series, error) {
+	if q.constant != nil {
+		return []series{{
+			labels: map[string]string{},
+			points: []tspb.TimeSeriesDatapoint{{TimestampNanos: t, Value: *q.constant}},
+		}}, nil
+	}
+	ss, err := ev.evalRange(ctx, q, t-lookbackDelta.Nanoseconds(), t, resolution)
+	if err != nil {
+		return nil, err
+	}
+	res := ss[:0]
+	for _, s := range ss {
+		for i := len(s.points) - 1; i >= 0; i-- {
+			if s.points[i].TimestampNanos <= t {
+				s.points = []tspb.TimeSeriesDatapoint{{TimestampNanos: t, Value: s.points[i].Value}}
+				res = append(res, s)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// sources returns the sources which recorded data for each of the
+// named series within the time range, sorted numerically.
+func (ev *evaluator) sources(
+	ctx context.Context, names []string, start, end, step int64,
+) ([][]string, error) {
+	req := &tspb.TimeSeriesQueryRequest{
+		StartNanos:  start,
+		EndNanos:    end,
+		SampleNanos: step,
+	}
+	for _, n := range names {
+		req.Queries = append(req.Queries, tspb.Query{Name: n})
+	}
+	resp, err := ev.querier.Query(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(names) {
+		return nil, errors.AssertionFailedf("expected %d results, got %d", len(names), len(resp.Results))
+	}
+	res := make([][]string, len(names))
+	for i, r := range resp.Results {
+		res[i] = append([]string(nil), r.Sources...)
+		sortSources(res[i])
+	}
+	return res, nil
+}
+
+// matchSeries returns the label sets of the series matching a vector
+// selector within the time range.
+func (ev *evaluator) matchSeries(
+	ctx context.Context, sel *parser.VectorSelector, start, end, step int64,
+) ([]map[string]string, error) {
+	var nameMatchers, matchers []*labels.Matcher
+	for _, m := range sel.LabelMatchers {
+		if m.Name == nameLabel {
+			nameMatchers = append(nameMatchers, m)
+		} else {
+			matchers = append(matchers, m)
+		}
+	}
+
+	var names []string
+	for n := range ev.index {
+		if matchesAll(nameMatchers, n) {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if len(names) > maxSeriesNames {
+		return nil, errors.Newf("%d metrics match the selector, more than the maximum of %d", len(names), maxSeriesNames)
+	}
+	sort.Strings(names)
+
+	tsNames := make([]string, len(names))
+	for i, n := range names {
+		tsNames[i] = ev.index[n].name
+	}
+	sources, err := ev.sources(ctx, tsNames, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	var res []map[string]string
+	for i, n := range names {
+		info := ev.index[n]
+		for _, s := range sources[i] {
+			ls := map[string]string{nameLabel: n, info.sourceLabel: s}
+			matches := true
+			for _, m := range matchers {
+				if !m.Matches(ls[m.Name]) {
+					matches = false
+					break
+				}
+			}
+			if matches {
+				res = append(res, ls)
+			}
+		}
+	}
+	return res, nil
+}
+
+func matchesAll(matchers []*labels.Matcher, v string) bool {
+	for _, m := range matchers {
+		if !m.Matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortSources sorts sources, which are node or store IDs, numerically.
+func sortSources(sources []string) {
+	sort.Slice(sources, func(i, j int) bool {
+		if len(sources[i]) != len(sources[j]) {
+			return len(sources[i]) < len(sources[j])
+		}
+		return sources[i] < sources[j]
+	})
+}
+
+// promName returns the Prometheus name of a series.
+func promName(info seriesInfo) string {
+	for _, prefix := range []string{nodeSeriesPrefix, storeSeriesPrefix} {
+		if strings.HasPrefix(info.name, prefix) {
+			return exportedName(strings.TrimPrefix(info.name, prefix))
+		}
+	}
+	return exportedName(info.name)
+}
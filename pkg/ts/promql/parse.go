@@ -0,0 +1,53 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package promql
+
+import (
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// parse parses a PromQL expression with the Prometheus parser. Only a
+// subset of PromQL is supported; whether a syntactically valid
+// expression can be evaluated is decided when it is planned.
+func parse(input string) (parser.Expr, error) {
+	return parser.ParseExpr(input)
+}
+
+// unwrapParens returns the expression within any enclosing parentheses.
+func unwrapParens(e parser.Expr) parser.Expr {
+	for {
+		p, ok := e.(*parser.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.Expr
+	}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// exportedName converts a metric name to a valid Prometheus metric
+// name, the same way as the Prometheus endpoint of each node.
+func exportedName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !isIdentChar(c) || (i == 0 && !isIdentStart(c)) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package promql
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepare(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	idx := makeNameIndex([]string{"cr.node.sql.conns", "cr.store.replicas"})
+	prepare := func(input string) (preparedQuery, error) {
+		e, err := parse(input)
+		if err != nil {
+			return preparedQuery{}, err
+		}
+		return idx.prepare(e)
+	}
+
+	t.Run("constant", func(t *testing.T) {
+		q, err := prepare(`1 + 2 * 3 - -4 / (1 + 1)`)
+		require.NoError(t, err)
+		require.NotNil(t, q.constant)
+		require.Equal(t, float64(9), *q.constant)
+	})
+
+	t.Run("selector", func(t *testing.T) {
+		q, err := prepare(`sql_conns{node_id=~"1|2", job!='x'}`)
+		require.NoError(t, err)
+		p := q.plan
+		require.Equal(t, "cr.node.sql.conns", p.series.name)
+		require.False(t, p.empty)
+		require.True(t, p.keepName)
+		require.Len(t, p.sourceMatchers, 1)
+		require.True(t, matchesAll(p.sourceMatchers, "2"))
+		require.False(t, matchesAll(p.sourceMatchers, "12"))
+
+		// The series do not have labels other than their source.
+		q, err = prepare(`{__name__="sql_conns", job="x"}`)
+		require.NoError(t, err)
+		require.True(t, q.plan.empty)
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		q, err := prepare(`rate(sql_conns[1h30m])`)
+		require.NoError(t, err)
+		require.Equal(t, tspb.TimeSeriesQueryDerivative_NON_NEGATIVE_DERIVATIVE, q.plan.derivative)
+	})
+
+	t.Run("aggregation", func(t *testing.T) {
+		for _, input := range []string{
+			`sum by (store) (replicas)`,
+			`sum(replicas) by (store)`,
+		} {
+			q, err := prepare(input)
+			require.NoError(t, err, input)
+			require.Nil(t, q.plan.aggregator, input)
+			require.False(t, q.plan.keepName, input)
+		}
+		q, err := prepare(`avg(replicas)`)
+		require.NoError(t, err)
+		require.Equal(t, tspb.TimeSeriesQueryAggregator_AVG, *q.plan.aggregator)
+	})
+
+	t.Run("arithmetic", func(t *testing.T) {
+		q, err := prepare(`-(8 * sql_conns)`)
+		require.NoError(t, err)
+		require.Equal(t, []scalarOp{
+			{op: parser.MUL, val: 8, scalarOnLeft: true},
+			{op: parser.MUL, val: -1},
+		}, q.plan.ops)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			input string
+			err   string
+		}{
+			{`sql_conns{`, `parse error: unexpected end of input inside braces`},
+			{`sql_conns sql_conns`, `parse error`},
+			{`sql_conns[5m]`, `range vector selectors are only supported as the argument of rate() and irate()`},
+			{`rate(sql_conns[5m:1m])`, `expected a range vector selector as the argument of rate()`},
+			{`sql_conns offset 5m`, `offset and @ modifiers are not supported`},
+			{`{__name__=~"sql_.*"}`, `only equality matchers are supported on __name__ in queries`},
+			{`sum without (store) (replicas)`, `aggregation with "without" is not supported`},
+			{`count(replicas)`, `unsupported aggregation count`},
+			{`sql_conns > 1`, `unsupported operator >`},
+			{`"sql_conns"`, `unsupported expression "sql_conns"`},
+		} {
+			_, err := prepare(tc.input)
+			require.Error(t, err, tc.input)
+			require.Contains(t, err.Error(), tc.err, tc.input)
+		}
+	})
+}
+
+func TestExportedName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for in, out := range map[string]string{
+		"sql.conns":               "sql_conns",
+		"sql.service.latency-p99": "sql_service_latency_p99",
+		"capacity":                "capacity",
+		"9lives":                  "_lives",
+	} {
+		require.Equal(t, out, exportedName(in))
+	}
+}
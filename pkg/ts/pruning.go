@@ -40,7 +40,10 @@ type timeSeriesResolutionInfo struct {
 // intended to be called by a storage queue which can inspect the local data for
 // a single range without the need for expensive network calls.
 func (tsdb *DB) findTimeSeries(
-	snapshot storage.Reader, startKey, endKey roachpb.RKey, now hlc.Timestamp,
+	ctx context.Context,
+	snapshot storage.Reader,
+	startKey, endKey roachpb.RKey,
+	now hlc.Timestamp,
 ) ([]timeSeriesResolutionInfo, error) {
 	var results []timeSeriesResolutionInfo
 
@@ -60,7 +63,7 @@ func (tsdb *DB) findTimeSeries(
 		end = lastTS
 	}
 
-	thresholds := tsdb.computeThresholds(now.WallTime)
+	thresholds := tsdb.computeThresholds(ctx, now.WallTime)
 
 	// NB: timeseries don't have intents.
 	iter := snapshot.NewMVCCIterator(storage.MVCCKeyIterKind, storage.IterOptions{UpperBound: endKey.AsRawKey()})
@@ -114,7 +117,7 @@ func (tsdb *DB) findTimeSeries(
 func (tsdb *DB) pruneTimeSeries(
 	ctx context.Context, db *kv.DB, timeSeriesList []timeSeriesResolutionInfo, now hlc.Timestamp,
 ) error {
-	thresholds := tsdb.computeThresholds(now.WallTime)
+	thresholds := tsdb.computeThresholds(ctx, now.WallTime)
 
 	b := &kv.Batch{}
 	for _, timeSeries := range timeSeriesList {
@@ -11,6 +11,7 @@
 package ts
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
@@ -273,7 +274,7 @@ func TestFindTimeSeries(t *testing.T) {
 		},
 	} {
 		snap := e.NewSnapshot()
-		actual, err := tm.DB.findTimeSeries(snap, tcase.start, tcase.end, tcase.timestamp)
+		actual, err := tm.DB.findTimeSeries(context.Background(), snap, tcase.start, tcase.end, tcase.timestamp)
 		snap.Close()
 		if err != nil {
 			t.Fatalf("case %d: unexpected error %q", i, err)
@@ -486,3 +487,61 @@ func TestMaintainTimeSeriesNoRollups(t *testing.T) {
 	tm.assertModelCorrect()
 	tm.assertKeyCount(8)
 }
+
+func TestMaintainTimeSeriesWithRollupTiers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tm := newTestModelRunner(t)
+	tm.Start()
+	defer tm.Stop()
+
+	ctx := context.Background()
+	Resolution1mStorageTTL.Override(ctx, &tm.Cfg.Settings.SV, 30*24*time.Hour)
+
+	// Arbitrary timestamp
+	var now int64 = 1475700000 * 1e9
+
+	// Populate data: one metric, two sources, two keys.
+	for _, source := range []string{"source1", "source2"} {
+		tm.storeTimeSeriesData(Resolution10s, []tspb.TimeSeriesData{
+			{
+				Name:   "metric.a",
+				Source: source,
+				Datapoints: []tspb.TimeSeriesDatapoint{
+					{
+						TimestampNanos: now - int64(20*24*time.Hour),
+						Value:          2,
+					},
+					{
+						TimestampNanos: now,
+						Value:          1,
+					},
+				},
+			},
+		})
+	}
+	tm.assertModelCorrect()
+	tm.assertKeyCount(4)
+
+	// The old 10s data is rolled up into the 1m resolution, which is enabled
+	// and retains it.
+	tm.maintain(now)
+	tm.assertModelCorrect()
+	tm.assertKeyCount(4)
+	{
+		query := tm.makeQuery("metric.a", Resolution10s, 0, now)
+		query.SampleDurationNanos = Resolution1m.SampleDuration()
+		query.assertSuccess(2, 2)
+	}
+
+	// Once the data ages past the 1m TTL, it is rolled up again into the 30m
+	// resolution.
+	Resolution1mStorageTTL.Override(ctx, &tm.Cfg.Settings.SV, 10*24*time.Hour)
+	tm.maintain(now)
+	tm.assertModelCorrect()
+	tm.assertKeyCount(4)
+	{
+		query := tm.makeQuery("metric.a", Resolution10s, 0, now)
+		query.SampleDurationNanos = Resolution30m.SampleDuration()
+		query.assertSuccess(2, 2)
+	}
+}
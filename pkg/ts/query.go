@@ -470,10 +470,14 @@ func (db *DB) Query(
 	// Create sourceSet, which tracks unique sources seen while querying.
 	sourceSet := make(map[string]struct{})
 
+	// Data older than the TTL of a resolution is rolled up into coarser
+	// resolutions. Query the coarsest resolution compatible with the requested
+	// sample duration first, then fill in the remainder of the timespan from
+	// successively finer resolutions.
 	resolutions := []Resolution{diskResolution}
-	if rollupResolution, ok := diskResolution.TargetRollupResolution(); ok {
-		if timespan.verifyDiskResolution(rollupResolution) == nil {
-			resolutions = []Resolution{rollupResolution, diskResolution}
+	for r, ok := db.TargetRollupResolution(ctx, diskResolution); ok; r, ok = db.TargetRollupResolution(ctx, r) {
+		if timespan.verifyDiskResolution(r) == nil {
+			resolutions = append([]Resolution{r}, resolutions...)
 		}
 	}
 
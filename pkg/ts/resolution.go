@@ -25,8 +25,12 @@ func (r Resolution) String() string {
 	switch r {
 	case Resolution10s:
 		return "10s"
+	case Resolution1m:
+		return "1m"
 	case Resolution30m:
 		return "30m"
+	case Resolution1d:
+		return "1d"
 	case resolution1ns:
 		return "1ns"
 	case resolution50ns:
@@ -39,13 +43,20 @@ func (r Resolution) String() string {
 
 // Resolution enumeration values are directly serialized and persisted into
 // system keys; these values must never be altered or reordered. If new rollup
-// resolutions are added, the IsRollup() method must be modified as well.
+// resolutions are added, the IsRollup() method and rollupChains must be
+// modified as well.
 const (
 	// Resolution10s stores data with a sample resolution of 10 seconds.
 	Resolution10s Resolution = 1
 	// Resolution30m stores roll-up data from a higher resolution at a sample
 	// resolution of 30 minutes.
 	Resolution30m Resolution = 2
+	// Resolution1m stores roll-up data from a higher resolution at a sample
+	// resolution of 1 minute.
+	Resolution1m Resolution = 3
+	// Resolution1d stores roll-up data from a higher resolution at a sample
+	// resolution of 1 day.
+	Resolution1d Resolution = 4
 	// resolution1ns stores data with a sample resolution of 1 nanosecond. Used
 	// only for testing.
 	resolution1ns Resolution = 998
@@ -64,6 +75,8 @@ const (
 var sampleDurationByResolution = map[Resolution]int64{
 	Resolution10s:     int64(time.Second * 10),
 	Resolution30m:     int64(time.Minute * 30),
+	Resolution1m:      int64(time.Minute),
+	Resolution1d:      int64(time.Hour * 24),
 	resolution1ns:     1,  // 1ns resolution only for tests.
 	resolution50ns:    50, // 50ns rollup only for tests.
 	resolutionInvalid: 10, // Invalid resolution.
@@ -76,6 +89,8 @@ var sampleDurationByResolution = map[Resolution]int64{
 var slabDurationByResolution = map[Resolution]int64{
 	Resolution10s:     int64(time.Hour),
 	Resolution30m:     int64(time.Hour * 24),
+	Resolution1m:      int64(time.Hour * 6),
+	Resolution1d:      int64(time.Hour * 24 * 30),
 	resolution1ns:     10,   // 1ns resolution only for tests.
 	resolution50ns:    1000, // 50ns rollup only for tests.
 	resolutionInvalid: 11,
@@ -106,20 +121,33 @@ func (r Resolution) SlabDuration() int64 {
 // values about a large number of samples taken over a long period, such as
 // the min, max and sum.
 func (r Resolution) IsRollup() bool {
-	return r == Resolution30m || r == resolution50ns
+	switch r {
+	case Resolution1m, Resolution30m, Resolution1d, resolution50ns:
+		return true
+	}
+	return false
 }
 
-// TargetRollupResolution returns a target resolution that data from this
-// resolution should be rolled up into in lieu of deletion. For example,
-// Resolution10s has a target rollup resolution of Resolution30m.
-func (r Resolution) TargetRollupResolution() (Resolution, bool) {
-	switch r {
-	case Resolution10s:
-		return Resolution30m, true
-	case resolution1ns:
-		return resolution50ns, true
+// rollupChains lists, from finest to coarsest, the resolutions which data can
+// be successively rolled up into. Data is rolled up from a resolution into the
+// next coarser resolution of its chain which is enabled; see
+// DB.TargetRollupResolution.
+var rollupChains = [][]Resolution{
+	{Resolution10s, Resolution1m, Resolution30m, Resolution1d},
+	{resolution1ns, resolution50ns},
+}
+
+// coarserResolutions returns the resolutions of this resolution's rollup
+// chain which are coarser than it, ordered from finest to coarsest.
+func (r Resolution) coarserResolutions() []Resolution {
+	for _, chain := range rollupChains {
+		for i, res := range chain {
+			if res == r {
+				return chain[i+1:]
+			}
+		}
 	}
-	return r, false
+	return nil
 }
 
 func normalizeToPeriod(timestampNanos int64, period int64) int64 {
@@ -138,6 +166,10 @@ func ResolutionFromProto(r tspb.TimeSeriesResolution) Resolution {
 		return Resolution10s
 	case tspb.TimeSeriesResolution_RESOLUTION_30M:
 		return Resolution30m
+	case tspb.TimeSeriesResolution_RESOLUTION_1M:
+		return Resolution1m
+	case tspb.TimeSeriesResolution_RESOLUTION_1D:
+		return Resolution1d
 	default:
 	}
 	return resolutionInvalid
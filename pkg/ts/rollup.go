@@ -140,10 +140,10 @@ func (db *DB) rollupTimeSeries(
 	now hlc.Timestamp,
 	qmc QueryMemoryContext,
 ) error {
-	thresholds := db.computeThresholds(now.WallTime)
+	thresholds := db.computeThresholds(ctx, now.WallTime)
 	for _, timeSeries := range timeSeriesList {
 		// Only process rollup if this resolution has a target rollup resolution.
-		targetResolution, hasRollup := timeSeries.Resolution.TargetRollupResolution()
+		targetResolution, hasRollup := db.TargetRollupResolution(ctx, timeSeries.Resolution)
 		if !hasRollup {
 			continue
 		}
@@ -73,6 +73,7 @@ const typeOptions = [
     value: JobType.ROW_LEVEL_TTL.toString(),
     label: "Time-to-live Deletions",
   },
+  { value: JobType.VERIFY_BACKUP.toString(), label: "Backup Verifications" },
 ];
 
 export const typeSetting = new LocalSetting<AdminUIState, number>(
@@ -0,0 +1,43 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package upgrades
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/upgrade"
+)
+
+// Target schema change in the system.statement_diagnostics_requests table,
+// adding a column that marks the requests armed on behalf of outliers.
+const addAutomaticColToStmtDiagReqs = `
+ALTER TABLE system.statement_diagnostics_requests
+  ADD COLUMN automatic BOOL NOT NULL DEFAULT false FAMILY "primary"`
+
+// automaticStmtDiagReqsMigration changes the schema of the
+// system.statement_diagnostics_requests table to tell the requests armed
+// automatically apart from the ones made by users.
+func automaticStmtDiagReqsMigration(
+	ctx context.Context, cs clusterversion.ClusterVersion, d upgrade.TenantDeps, _ *jobs.Job,
+) error {
+	op := operation{
+		name:           "add-stmt-diag-reqs-automatic-column",
+		schemaList:     []string{"automatic"},
+		query:          addAutomaticColToStmtDiagReqs,
+		schemaExistsFn: hasColumn,
+	}
+	return migrateTable(ctx, cs, d, op, keys.StatementDiagnosticsRequestsTableID,
+		systemschema.StatementDiagnosticsRequestsTable)
+}
@@ -69,6 +69,9 @@ func TestSampledStmtDiagReqsMigration(t *testing.T) {
 	// Inject the old copy of the descriptor.
 	upgrades.InjectLegacyTable(ctx, t, s, systemschema.StatementDiagnosticsRequestsTable,
 		getV2StmtDiagReqsDescriptor)
+	// The table is expected to have the schema it had at this version, before
+	// later upgrades changed it.
+	expectedTable := tabledesc.NewBuilder(getV3StmtDiagReqsDescriptor()).BuildImmutableTable()
 	validateSchemaExists := func(expectExists bool) {
 		upgrades.ValidateSchemaExists(
 			ctx,
@@ -76,7 +79,7 @@ func TestSampledStmtDiagReqsMigration(t *testing.T) {
 			s,
 			sqlDB,
 			keys.StatementDiagnosticsRequestsTableID,
-			systemschema.StatementDiagnosticsRequestsTable,
+			expectedTable,
 			validationStmts,
 			validationSchemas,
 			expectExists,
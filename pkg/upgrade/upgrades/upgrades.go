@@ -137,6 +137,18 @@ var upgrades = []upgrade.Upgrade{
 		NoPrecondition,
 		sampledStmtDiagReqsMigration,
 	),
+	upgrade.NewTenantUpgrade(
+		"add the system.hot_ranges_history table",
+		toCV(clusterversion.HotRangesHistoryTable),
+		NoPrecondition,
+		hotRangesHistoryTableMigration,
+	),
+	upgrade.NewTenantUpgrade(
+		"update system.statement_diagnostics_requests to mark automatic requests",
+		toCV(clusterversion.AutomaticStmtDiagReqs),
+		NoPrecondition,
+		automaticStmtDiagReqsMigration,
+	),
 }
 
 func init() {
@@ -27,6 +27,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
 )
@@ -658,6 +660,7 @@ func (q *WorkQueue) Admit(ctx context.Context, info WorkInfo) (enabled bool, err
 		q.metrics.WaitDurationSum.Inc(waitDur.Microseconds())
 		q.metrics.WaitDurations.RecordValue(waitDur.Nanoseconds())
 		q.metrics.WaitQueueLength.Dec(1)
+		q.recordWait(ctx, waitDur)
 		deadline, _ := ctx.Deadline()
 		log.Eventf(ctx, "deadline expired, waited in %s queue for %v",
 			workKindString(q.workKind), waitDur)
@@ -673,6 +676,7 @@ func (q *WorkQueue) Admit(ctx context.Context, info WorkInfo) (enabled bool, err
 		q.metrics.WaitDurationSum.Inc(waitDur.Microseconds())
 		q.metrics.WaitDurations.RecordValue(waitDur.Nanoseconds())
 		q.metrics.WaitQueueLength.Dec(1)
+		q.recordWait(ctx, waitDur)
 		if work.heapIndex != -1 {
 			panic(errors.AssertionFailedf("grantee should be removed from heap"))
 		}
@@ -682,6 +686,20 @@ func (q *WorkQueue) Admit(ctx context.Context, info WorkInfo) (enabled bool, err
 	}
 }
 
+// recordWait records the time spent by work waiting in the queue in the trace
+// of the work, if it is being recorded. This allows the queueing time to be
+// attributed to the operation, e.g. a SQL statement, which issued the work.
+func (q *WorkQueue) recordWait(ctx context.Context, waitDur time.Duration) {
+	sp := tracing.SpanFromContext(ctx)
+	if sp == nil || sp.RecordingType() == tracingpb.RecordingOff {
+		return
+	}
+	sp.RecordStructured(&roachpb.AdmissionWaitEvent{
+		WorkKind:     string(workKindString(q.workKind)),
+		WaitDuration: waitDur,
+	})
+}
+
 // AdmittedWorkDone is used to inform the WorkQueue that some admitted work is
 // finished. It must be called iff the WorkKind of this WorkQueue uses slots
 // (not tokens), i.e., KVWork, SQLStatementLeafStartWork,
@@ -28,7 +28,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
 	"github.com/cockroachdb/datadriven"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -386,6 +389,54 @@ func TestWorkQueueTokenResetRace(t *testing.T) {
 	mu.Unlock()
 }
 
+// TestWorkQueueRecordsWait tests that the time spent waiting in the queue is
+// recorded in the trace of the admitted work.
+func TestWorkQueueRecordsWait(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	var buf builderWithMu
+	tg := &testGranter{buf: &buf}
+	st := cluster.MakeTestingClusterSettings()
+	q := makeWorkQueue(log.MakeTestingAmbientContext(tracing.NewTracer()), KVWork, tg,
+		st, makeWorkQueueOptions(KVWork)).(*WorkQueue)
+	defer q.close()
+	tg.r = q
+
+	tr := tracing.NewTracer()
+	ctx, sp := tr.StartSpanCtx(context.Background(), "test",
+		tracing.WithRecording(tracingpb.RecordingStructured))
+	admitted := make(chan error)
+	go func() {
+		_, err := q.Admit(ctx, WorkInfo{TenantID: roachpb.SystemTenantID})
+		admitted <- err
+	}()
+	// The granter refuses the work, which waits until it is granted a slot.
+	testutils.SucceedsSoon(t, func() error {
+		if !q.hasWaitingRequests() {
+			return errors.New("work not queued yet")
+		}
+		return nil
+	})
+	tg.grant(1)
+	require.NoError(t, <-admitted)
+
+	var events []roachpb.AdmissionWaitEvent
+	rec := sp.FinishAndGetConfiguredRecording()
+	for i := range rec {
+		rec[i].Structured(func(any *pbtypes.Any, _ time.Time) {
+			var ev roachpb.AdmissionWaitEvent
+			if pbtypes.Is(any, &ev) {
+				require.NoError(t, pbtypes.UnmarshalAny(any, &ev))
+				events = append(events, ev)
+			}
+		})
+	}
+	require.Len(t, events, 1)
+	require.Equal(t, "kv", events[0].WorkKind)
+	require.Greater(t, int64(events[0].WaitDuration), int64(0))
+}
+
 func TestPriorityStates(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
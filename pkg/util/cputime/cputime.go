@@ -0,0 +1,77 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package cputime measures the CPU time consumed by individual goroutines.
+//
+// The Go runtime doesn't account for the CPU time of goroutines. Instead, a
+// Stopwatch locks the measured goroutine to its OS thread while it runs and
+// reads the thread's CPU clock: since no other goroutine can run on a locked
+// thread, the thread's CPU time is the goroutine's. Locking a goroutine to its
+// thread makes blocking operations more expensive (the runtime needs to hand
+// off the thread's processor to another thread), so stopwatches are meant to
+// be used for sampled measurements only.
+package cputime
+
+import (
+	"runtime"
+	"time"
+)
+
+// Supported returns whether CPU time can be measured on this platform. If it
+// can't, stopwatches always measure zero.
+func Supported() bool {
+	return supported
+}
+
+// Stopwatch measures the CPU time consumed by the goroutine which started it.
+// Start and Stop need to be called on the same goroutine. The zero value is
+// ready to use.
+type Stopwatch struct {
+	running bool
+	// startedAt is the thread CPU time when the stopwatch was started.
+	startedAt time.Duration
+	// elapsed is the CPU time accumulated by previous Start/Stop intervals.
+	elapsed time.Duration
+}
+
+// Start starts measuring the CPU time of the calling goroutine, which is
+// locked to its OS thread until Stop is called. Start is a no-op if the
+// stopwatch is already running or if measuring is not supported.
+func (w *Stopwatch) Start() {
+	if w.running || !supported {
+		return
+	}
+	runtime.LockOSThread()
+	w.running = true
+	w.startedAt = threadTime()
+}
+
+// Stop stops the stopwatch and unlocks the calling goroutine from its OS
+// thread. It returns the total CPU time measured by the stopwatch. Stop is a
+// no-op if the stopwatch is not running.
+func (w *Stopwatch) Stop() time.Duration {
+	if !w.running {
+		return w.elapsed
+	}
+	w.elapsed += threadTime() - w.startedAt
+	w.running = false
+	runtime.UnlockOSThread()
+	return w.elapsed
+}
+
+// Elapsed returns the total CPU time measured by the stopwatch so far. If the
+// stopwatch is running, it needs to be called by the goroutine that started
+// it.
+func (w *Stopwatch) Elapsed() time.Duration {
+	if !w.running {
+		return w.elapsed
+	}
+	return w.elapsed + threadTime() - w.startedAt
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cputime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopwatch(t *testing.T) {
+	if !Supported() {
+		var w Stopwatch
+		w.Start()
+		require.Zero(t, w.Stop())
+		return
+	}
+
+	spin := func(d time.Duration) {
+		for start := timeutil.Now(); timeutil.Since(start) < d; {
+		}
+	}
+
+	// Sleeping doesn't consume CPU.
+	var w Stopwatch
+	w.Start()
+	time.Sleep(100 * time.Millisecond)
+	sleeping := w.Stop()
+	require.Less(t, int64(sleeping), int64(50*time.Millisecond))
+
+	// Spinning does.
+	w.Start()
+	spin(100 * time.Millisecond)
+	require.Greater(t, int64(w.Elapsed()), int64(sleeping))
+	total := w.Stop()
+	require.Greater(t, int64(total-sleeping), int64(50*time.Millisecond))
+
+	// The stopwatch doesn't advance while stopped.
+	spin(10 * time.Millisecond)
+	require.Equal(t, total, w.Elapsed())
+	require.Equal(t, total, w.Stop())
+}
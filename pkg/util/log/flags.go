@@ -12,6 +12,7 @@ package log
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io/fs"
 	"math"
@@ -319,6 +320,32 @@ func ApplyConfig(config logconfig.Config) (logShutdownFn func(), err error) {
 		attachSinkInfo(httpSinkInfo, &fc.Channels)
 	}
 
+	// Create the syslog sinks.
+	for _, fc := range config.Sinks.SyslogServers {
+		if fc.Filter == severity.NONE {
+			continue
+		}
+		syslogSinkInfo, err := newSyslogSinkInfo(*fc)
+		if err != nil {
+			return nil, err
+		}
+		attachBufferWrapper(syslogSinkInfo, fc.CommonSinkConfig.Buffering, closer)
+		attachSinkInfo(syslogSinkInfo, &fc.Channels)
+	}
+
+	// Create the OTLP sinks.
+	for _, fc := range config.Sinks.OTLPServers {
+		if fc.Filter == severity.NONE {
+			continue
+		}
+		otlpSinkInfo, err := newOTLPSinkInfo(*fc)
+		if err != nil {
+			return nil, err
+		}
+		attachBufferWrapper(otlpSinkInfo, fc.CommonSinkConfig.Buffering, closer)
+		attachSinkInfo(otlpSinkInfo, &fc.Channels)
+	}
+
 	// Prepend the interceptor sink to all channels.
 	// We prepend it because we want the interceptors
 	// to see every event before they make their way to disk/network.
@@ -390,6 +417,56 @@ func newHTTPSinkInfo(c logconfig.HTTPSinkConfig) (*sinkInfo, error) {
 	return info, nil
 }
 
+// newSyslogSinkInfo creates a new syslogSink and its accompanying
+// sinkInfo from the provided configuration.
+func newSyslogSinkInfo(c logconfig.SyslogSinkConfig) (*sinkInfo, error) {
+	info := &sinkInfo{}
+	if err := info.applyConfig(c.CommonSinkConfig); err != nil {
+		return nil, err
+	}
+	info.applyFilters(c.Channels)
+	// The syslog format reports the facility configured on the sink.
+	if f, ok := info.formatter.(formatSyslog); ok {
+		f.facility = c.Facility.Code()
+		info.formatter = f
+	}
+	var tlsConfig *tls.Config
+	if *c.TLS {
+		var err error
+		if tlsConfig, err = makeSinkTLSConfig(c.CACert, *c.UnsafeTLS); err != nil {
+			return nil, err
+		}
+	}
+	info.sink = newSyslogSink(c.Net, c.Address, tlsConfig)
+	return info, nil
+}
+
+// newOTLPSinkInfo creates a new otlpSink and its accompanying sinkInfo
+// from the provided configuration.
+func newOTLPSinkInfo(c logconfig.OTLPSinkConfig) (*sinkInfo, error) {
+	info := &sinkInfo{}
+	if err := info.applyConfig(c.CommonSinkConfig); err != nil {
+		return nil, err
+	}
+	info.applyFilters(c.Channels)
+	var tlsConfig *tls.Config
+	if *c.TLS {
+		var err error
+		if tlsConfig, err = makeSinkTLSConfig(c.CACert, *c.UnsafeTLS); err != nil {
+			return nil, err
+		}
+		if c.ClientCert != nil && *c.ClientCert != "" {
+			cert, err := tls.LoadX509KeyPair(*c.ClientCert, *c.ClientKey)
+			if err != nil {
+				return nil, errors.Wrap(err, "loading client certificate")
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+	info.sink = newOTLPSink(c.Address, *c.Timeout, tlsConfig)
+	return info, nil
+}
+
 // applyFilters applies the channel filters to a sinkInfo.
 func (l *sinkInfo) applyFilters(chs logconfig.ChannelFilters) {
 	for ch, threshold := range chs.ChannelFilters {
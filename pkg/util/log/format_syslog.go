@@ -0,0 +1,268 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/redact"
+)
+
+// syslogSDID is the identifier of the structured data element which
+// carries the event's metadata, tags and structured fields. The
+// private enterprise number 32473 is the one reserved for
+// documentation purposes by RFC 5612.
+const syslogSDID = "crdb@32473"
+
+// syslogMaxParamNameLen is the maximum length of a structured data
+// parameter name, per RFC 5424.
+const syslogMaxParamNameLen = 32
+
+// defaultSyslogFacility is the facility code used when the formatter
+// is not configured by a syslog sink: "user".
+const defaultSyslogFacility = 1
+
+// formatSyslog formats entries as RFC 5424 syslog messages.
+type formatSyslog struct {
+	// facility is the syslog facility code under which entries are
+	// reported.
+	facility int
+}
+
+func (formatSyslog) formatterName() string { return "syslog" }
+
+func (formatSyslog) contentType() string { return "text/plain" }
+
+func (formatSyslog) doc() string {
+	return `This format emits log entries as [RFC 5424](https://www.rfc-editor.org/rfc/rfc5424)
+syslog messages, one per line.
+
+Each entry has the form:
+
+    <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [` + syslogSDID + ` ...] MSG
+
+| Field | Description |
+|-------|-------------|
+| PRI | The facility configured on the sink and the severity of the event. INFO, WARNING, ERROR and FATAL map to the syslog severities informational, warning, error and critical. |
+| TIMESTAMP | The time at which the event was emitted, in UTC with microsecond precision. |
+| HOSTNAME | The name of the host where the event was emitted. |
+| APP-NAME | The name of the process. |
+| PROCID | The process ID. |
+| MSGID | The name of the logging channel where the event was sent. |
+| MSG | For unstructured events, the flat text payload. For structured events, the event as a JSON object. |
+
+The structured data element ` + "`" + syslogSDID + "`" + ` contains the parameters
+` + "`node_id`, `cluster_id`, `tenant_id`, `instance_id`, `version`, `goroutine`, `file`, `line`, `entry_counter` and `redactable`" + `
+as documented for the JSON formats, when they are known. Each logging context
+tag is reported as a parameter prefixed with ` + "`tag.`" + `, and each field of a
+structured event as a parameter prefixed with ` + "`event.`" + `.
+
+Newline characters in the message and parameter values are escaped as ` + "`\\n`" + `,
+so that each entry occupies exactly one line.
+
+When the entry is marked as ` + "`redactable`" + `, the tags, message, and event fields
+contain delimiters (` + string(redact.StartMarker()) + "..." + string(redact.EndMarker()) + `) around
+fields that are considered sensitive.
+`
+}
+
+// syslogSeverity maps the severity of an entry to a syslog severity.
+func syslogSeverity(sev Severity) int {
+	switch sev {
+	case severity.FATAL:
+		return 2 // critical
+	case severity.ERROR:
+		return 3 // error
+	case severity.WARNING:
+		return 4 // warning
+	default:
+		return 6 // informational
+	}
+}
+
+func (f formatSyslog) formatEntry(entry logEntry) *buffer {
+	buf := getBuffer()
+
+	// Header: <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(f.facility*8 + syslogSeverity(entry.sev)))
+	buf.WriteString(">1 ")
+	buf.WriteString(timeutil.Unix(0, entry.ts).UTC().Format("2006-01-02T15:04:05.000000Z07:00"))
+	buf.WriteByte(' ')
+	writeSyslogHeaderField(buf, fullHostName, 255)
+	buf.WriteByte(' ')
+	writeSyslogHeaderField(buf, fileNameConstants.program, 48)
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.Itoa(fileNameConstants.pid))
+	buf.WriteByte(' ')
+	if entry.header {
+		buf.WriteByte('-')
+	} else {
+		writeSyslogHeaderField(buf, entry.ch.String(), 32)
+	}
+
+	// Structured data.
+	buf.WriteString(" [")
+	buf.WriteString(syslogSDID)
+	writeParam := func(name, value string) {
+		buf.WriteByte(' ')
+		writeSyslogParamName(buf, name)
+		buf.WriteString(`="`)
+		writeSyslogEscaped(buf, value, true /* inParam */)
+		buf.WriteByte('"')
+	}
+	if entry.nodeID != "" {
+		writeParam("node_id", entry.nodeID)
+	}
+	if entry.clusterID != "" {
+		writeParam("cluster_id", entry.clusterID)
+	}
+	if entry.tenantID != "" {
+		writeParam("tenant_id", entry.tenantID)
+	}
+	if entry.sqlInstanceID != "" {
+		writeParam("instance_id", entry.sqlInstanceID)
+	}
+	if entry.version != "" {
+		writeParam("version", entry.version)
+	}
+	writeParam("goroutine", strconv.FormatInt(entry.gid, 10))
+	writeParam("file", entry.file)
+	writeParam("line", strconv.Itoa(entry.line))
+	if !entry.header {
+		writeParam("entry_counter", strconv.FormatUint(entry.counter, 10))
+	}
+	if entry.payload.redactable {
+		writeParam("redactable", "1")
+	} else {
+		writeParam("redactable", "0")
+	}
+	fi := formattableTagsIterator{tags: []byte(entry.payload.tags)}
+	for {
+		key, val, done := fi.next()
+		if done {
+			break
+		}
+		writeParam("tag."+string(key), string(val))
+	}
+	if entry.structured {
+		forEachStructuredField(entry.payload.message, func(key string, val json.RawMessage) {
+			var s string
+			if len(val) > 0 && val[0] == '"' && json.Unmarshal(val, &s) == nil {
+				writeParam("event."+key, s)
+			} else {
+				writeParam("event."+key, string(val))
+			}
+		})
+	}
+	buf.WriteByte(']')
+
+	// Message.
+	buf.WriteByte(' ')
+	if entry.structured {
+		buf.WriteByte('{')
+		writeSyslogEscaped(buf, entry.payload.message, false /* inParam */)
+		buf.WriteByte('}')
+	} else {
+		writeSyslogEscaped(buf, entry.payload.message, false /* inParam */)
+	}
+	if len(entry.stacks) > 0 {
+		writeSyslogEscaped(buf, "\n", false /* inParam */)
+		writeSyslogEscaped(buf, string(entry.stacks), false /* inParam */)
+	}
+	buf.WriteByte('\n')
+	return buf
+}
+
+// forEachStructuredField calls fn for every top-level field of the
+// payload of a structured entry, in order. The payload is the JSON
+// representation of the event without the enclosing braces.
+func forEachStructuredField(payload string, fn func(key string, val json.RawMessage)) {
+	dec := json.NewDecoder(bytes.NewReader([]byte("{" + payload + "}")))
+	if _, err := dec.Token(); err != nil {
+		return
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return
+		}
+		key, ok := t.(string)
+		if !ok {
+			return
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return
+		}
+		fn(key, val)
+	}
+}
+
+// writeSyslogHeaderField writes a header field, which must consist of
+// at most maxLen printable US-ASCII characters. Other characters are
+// replaced by underscores. An empty value is written as "-".
+func writeSyslogHeaderField(buf *buffer, s string, maxLen int) {
+	if s == "" {
+		buf.WriteByte('-')
+		return
+	}
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 33 || c > 126 {
+			c = '_'
+		}
+		buf.WriteByte(c)
+	}
+}
+
+// writeSyslogParamName writes a structured data parameter name, which
+// must consist of at most 32 printable US-ASCII characters other than
+// '=', ' ', ']' and '"'. Other characters are replaced by underscores.
+func writeSyslogParamName(buf *buffer, s string) {
+	if len(s) > syslogMaxParamNameLen {
+		s = s[:syslogMaxParamNameLen]
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		buf.WriteByte(c)
+	}
+}
+
+// writeSyslogEscaped writes s, escaping newlines so that the message
+// stays on a single line. Inside structured data parameter values, the
+// characters '"', '\' and ']' are also escaped as required by RFC 5424.
+func writeSyslogEscaped(buf *buffer, s string, inParam bool) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\n':
+			buf.WriteString(`\n`)
+		case c == '\r':
+			buf.WriteString(`\r`)
+		case inParam && (c == '"' || c == '\\' || c == ']'):
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
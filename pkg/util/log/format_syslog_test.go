@@ -0,0 +1,69 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/logtags"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogFormat(t *testing.T) {
+	ctx := context.Background()
+	ctx = logtags.AddTag(ctx, "s", "1")
+	ctx = logtags.AddTag(ctx, "client", "a]b")
+
+	header := fmt.Sprintf(`\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z \S+ \S+ %d`, fileNameConstants.pid)
+
+	testCases := []struct {
+		entry    logEntry
+		facility int
+		expected string
+	}{
+		{
+			entry:    makeUnstructuredEntry(ctx, severity.WARNING, channel.OPS, 0, true, "hello %s", "world"),
+			facility: 16, // local0
+			expected: `^<132>1 ` + header + ` OPS \[crdb@32473 .*goroutine="\d+" file="[^"]*" line="\d+" entry_counter="0" redactable="1" tag.s="‹1›" tag.client="‹a\\\]b›"\] hello ‹world›\n$`,
+		},
+		{
+			entry:    makeUnstructuredEntry(ctx, severity.INFO, channel.DEV, 0, false, "multi\nline"),
+			facility: 1, // user
+			expected: `^<14>1 ` + header + ` DEV \[crdb@32473 .* redactable="0" tag.s="1" tag.client="a\\\]b"\] multi\\nline\n$`,
+		},
+		{
+			entry: makeStructuredEntry(ctx, severity.ERROR, channel.SESSIONS, 0, &eventpb.RenameDatabase{
+				CommonEventDetails: eventpb.CommonEventDetails{
+					Timestamp: 123,
+					EventType: "rename_database",
+				},
+				DatabaseName:    "hello",
+				NewDatabaseName: "wor\"ld",
+			}),
+			facility: 4, // auth
+			expected: `^<35>1 ` + header + ` SESSIONS \[crdb@32473 .* tag.client="‹a\\\]b›" event.Timestamp="123" event.EventType="rename_database" event.DatabaseName="‹hello›" event.NewDatabaseName="‹wor\\"ld›"\] \{"Timestamp":123,"EventType":"rename_database",.*\}\n$`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			buf := formatSyslog{facility: tc.facility}.formatEntry(tc.entry)
+			defer putBuffer(buf)
+			require.Regexp(t, regexp.MustCompile(tc.expected), buf.String())
+		})
+	}
+}
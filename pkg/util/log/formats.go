@@ -51,6 +51,7 @@ var formatters = func() map[string]logFormatter {
 	r(formatFluentJSONFull{})
 	r(formatJSONCompact{})
 	r(formatJSONFull{})
+	r(formatSyslog{facility: defaultSyslogFacility})
 	return m
 }()
 
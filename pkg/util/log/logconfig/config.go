@@ -41,6 +41,14 @@ const DefaultFluentFormat = `json-fluent-compact`
 // when not specified in a configuration.
 const DefaultHTTPFormat = `json-compact`
 
+// DefaultSyslogFormat is the entry format for syslog sinks
+// when not specified in a configuration.
+const DefaultSyslogFormat = `syslog`
+
+// DefaultOTLPFormat is the entry format for OTLP sinks
+// when not specified in a configuration.
+const DefaultOTLPFormat = `json`
+
 // DefaultConfig returns a suitable default configuration when logging
 // is meant to primarily go to files.
 func DefaultConfig() (c Config) {
@@ -113,6 +121,16 @@ type Config struct {
 	// configuration value.
 	HTTPDefaults HTTPDefaults `yaml:"http-defaults,omitempty"`
 
+	// SyslogDefaults represents the default configuration for syslog
+	// sinks, inherited when a specific syslog sink config does not
+	// provide a configuration value.
+	SyslogDefaults SyslogDefaults `yaml:"syslog-defaults,omitempty"`
+
+	// OTLPDefaults represents the default configuration for OTLP sinks,
+	// inherited when a specific OTLP sink config does not provide a
+	// configuration value.
+	OTLPDefaults OTLPDefaults `yaml:"otlp-defaults,omitempty"`
+
 	// Sinks represents the sink configurations.
 	Sinks SinkConfig `yaml:",omitempty"`
 
@@ -228,6 +246,10 @@ type SinkConfig struct {
 	FluentServers map[string]*FluentSinkConfig `yaml:"fluent-servers,omitempty"`
 	// HTTPServers represents the list of configured http sinks.
 	HTTPServers map[string]*HTTPSinkConfig `yaml:"http-servers,omitempty"`
+	// SyslogServers represents the list of configured syslog sinks.
+	SyslogServers map[string]*SyslogSinkConfig `yaml:"syslog-servers,omitempty"`
+	// OTLPServers represents the list of configured OTLP sinks.
+	OTLPServers map[string]*OTLPSinkConfig `yaml:"otlp-servers,omitempty"`
 	// Stderr represents the configuration for the stderr sink.
 	Stderr StderrSinkConfig `yaml:",omitempty"`
 }
@@ -537,6 +559,166 @@ type HTTPSinkConfig struct {
 	sinkName string
 }
 
+// SyslogDefaults represents the configuration defaults for syslog sinks.
+type SyslogDefaults struct {
+	// Facility is the syslog facility under which events are reported,
+	// e.g. "user", "auth" or "local0". Defaults to "user".
+	Facility *SyslogFacility `yaml:",omitempty"`
+
+	// TLS enables TLS on the connection to the syslog server. Only
+	// supported with TCP. Defaults to false.
+	TLS *bool `yaml:"tls,omitempty"`
+
+	// CACert is the path to a PEM file containing the certificate
+	// authorities used to verify the syslog server's certificate when
+	// TLS is enabled. Defaults to the system's certificate authorities.
+	CACert *string `yaml:"ca-cert,omitempty"`
+
+	// UnsafeTLS enables certificate authentication to be bypassed.
+	// Defaults to false.
+	UnsafeTLS *bool `yaml:"unsafe-tls,omitempty"`
+
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// SyslogSinkConfig represents the configuration for one syslog sink.
+//
+// User-facing documentation follows.
+// TITLE: Output to syslog servers
+//
+// This sink type causes logging data to be sent over the network to
+// a syslog server, as [RFC 5424](https://www.rfc-editor.org/rfc/rfc5424)
+// messages.
+//
+// Over TCP, with or without TLS, each message is terminated by a
+// newline character (the "non-transparent framing" of
+// [RFC 6587](https://www.rfc-editor.org/rfc/rfc6587)). Over UDP, each
+// message is sent in its own datagram.
+//
+// The configuration key under the `sinks` key in the YAML
+// configuration is `syslog-servers`. Example configuration:
+//
+//     sinks:
+//        syslog-servers:        # syslog configurations start here
+//           audit:              # defines one sink called "audit"
+//              channels: [SENSITIVE_ACCESS, USER_ADMIN, PRIVILEGES]
+//              address: syslog.example.com:6514
+//              tls: true
+//              facility: auth
+//
+// Every new server sink configured automatically inherits the configurations set in the `syslog-defaults` section.
+//
+// The default output format for syslog sinks is `syslog`, which
+// reports the logging channel as the message ID and the event's
+// metadata, context tags and structured event fields as structured
+// data parameters.
+//
+// {{site.data.alerts.callout_info}}
+// Run `cockroach debug check-log-config` to verify the effect of defaults inheritance.
+// {{site.data.alerts.end}}
+//
+type SyslogSinkConfig struct {
+	// Channels is the list of logging channels that use this sink.
+	Channels ChannelFilters `yaml:",omitempty,flow"`
+
+	// Net is the protocol for the syslog server. Can be "tcp", "udp",
+	// "tcp4", etc. Defaults to "tcp".
+	Net string `yaml:",omitempty"`
+
+	// Address is the network address of the syslog server. The
+	// host/address and port parts are separated with a colon. IPv6
+	// numeric addresses should be included within square brackets,
+	// e.g.: [::1]:1234.
+	Address string `yaml:""`
+
+	// SyslogDefaults contains the defaultable fields of the config.
+	SyslogDefaults `yaml:",inline"`
+
+	// sinkName is populated during validation.
+	sinkName string
+}
+
+// OTLPDefaults represents the configuration defaults for OTLP sinks.
+type OTLPDefaults struct {
+	// Timeout is the timeout for each export request sent to the
+	// collector. Defaults to 5s.
+	Timeout *time.Duration `yaml:",omitempty"`
+
+	// TLS enables TLS on the connection to the collector. Defaults to
+	// false.
+	TLS *bool `yaml:"tls,omitempty"`
+
+	// CACert is the path to a PEM file containing the certificate
+	// authorities used to verify the collector's certificate when
+	// TLS is enabled. Defaults to the system's certificate authorities.
+	CACert *string `yaml:"ca-cert,omitempty"`
+
+	// ClientCert is the path to a PEM file containing the certificate
+	// presented to collectors which authenticate their clients, when TLS
+	// is enabled. Requires client-key.
+	ClientCert *string `yaml:"client-cert,omitempty"`
+
+	// ClientKey is the path to a PEM file containing the private key of
+	// the client-cert certificate.
+	ClientKey *string `yaml:"client-key,omitempty"`
+
+	// UnsafeTLS enables certificate authentication to be bypassed.
+	// Defaults to false.
+	UnsafeTLS *bool `yaml:"unsafe-tls,omitempty"`
+
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// OTLPSinkConfig represents the configuration for one OTLP sink.
+//
+// User-facing documentation follows.
+// TITLE: Output to OpenTelemetry collectors
+//
+// This sink type causes logging data to be sent over the network to
+// an [OpenTelemetry](https://opentelemetry.io) collector, as OTLP log
+// records over gRPC.
+//
+// Each logging event becomes one log record. The event's metadata
+// (channel, node and tenant identifiers, source location, etc.), its
+// context tags and, for structured events, the fields of the event
+// are reported as attributes of the log record. The body of the log
+// record is the event's message, or the entire event for structured
+// events.
+//
+// The configuration key under the `sinks` key in the YAML
+// configuration is `otlp-servers`. Example configuration:
+//
+//     sinks:
+//        otlp-servers:          # OTLP configurations start here
+//           health:             # defines one sink called "health"
+//              channels: HEALTH
+//              address: 127.0.0.1:4317
+//
+// Every new server sink configured automatically inherits the configurations set in the `otlp-defaults` section.
+//
+// OTLP sinks only support the `json` format, which is the default.
+//
+// {{site.data.alerts.callout_info}}
+// Run `cockroach debug check-log-config` to verify the effect of defaults inheritance.
+// {{site.data.alerts.end}}
+//
+type OTLPSinkConfig struct {
+	// Channels is the list of logging channels that use this sink.
+	Channels ChannelFilters `yaml:",omitempty,flow"`
+
+	// Address is the network address of the collector's OTLP/gRPC
+	// endpoint. The host/address and port parts are separated with a
+	// colon. IPv6 numeric addresses should be included within square
+	// brackets, e.g.: [::1]:4317.
+	Address string `yaml:""`
+
+	// OTLPDefaults contains the defaultable fields of the config.
+	OTLPDefaults `yaml:",inline"`
+
+	// sinkName is populated during validation.
+	sinkName string
+}
+
 // IterateDirectories calls the provided fn on every directory linked to
 // by the configuration.
 func (c *Config) IterateDirectories(fn func(d string) error) error {
@@ -1113,6 +1295,54 @@ func (hsm *HTTPSinkMethod) UnmarshalYAML(fn func(interface{}) error) error {
 	return unmarshalYAMLConstrainedString(hsm, fn)
 }
 
+// SyslogFacility is a string restricted to the syslog facility names.
+type SyslogFacility string
+
+var _ constrainedString = (*SyslogFacility)(nil)
+
+// syslogFacilities lists the facility names, in the order of their
+// numeric codes as defined by RFC 5424.
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "audit", "alert", "clock",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// Accept implements the constrainedString interface.
+func (sf *SyslogFacility) Accept(s string) {
+	*sf = SyslogFacility(s)
+}
+
+// Canonicalize implements the constrainedString interface.
+func (SyslogFacility) Canonicalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// AllowedSet implements the constrainedString interface.
+func (SyslogFacility) AllowedSet() []string {
+	return syslogFacilities
+}
+
+// Code returns the numeric code of the facility.
+func (sf SyslogFacility) Code() int {
+	for i, f := range syslogFacilities {
+		if string(sf) == f {
+			return i
+		}
+	}
+	return 1 // user
+}
+
+// MarshalYAML implements yaml.Marshaler interface.
+func (sf SyslogFacility) MarshalYAML() (interface{}, error) {
+	return string(sf), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (sf *SyslogFacility) UnmarshalYAML(fn func(interface{}) error) error {
+	return unmarshalYAMLConstrainedString(sf, fn)
+}
+
 // constrainedString is an interface to make it easy to unmarshal
 // a string constrained to a small set of accepted values.
 type constrainedString interface {
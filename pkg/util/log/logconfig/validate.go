@@ -95,14 +95,34 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		Method:            func() *HTTPSinkMethod { m := HTTPSinkMethod(http.MethodPost); return &m }(),
 		Timeout:           &zeroDuration,
 	}
+	baseSyslogDefaults := SyslogDefaults{
+		CommonSinkConfig: CommonSinkConfig{
+			Format: func() *string { s := DefaultSyslogFormat; return &s }(),
+		},
+		Facility:  func() *SyslogFacility { f := SyslogFacility("user"); return &f }(),
+		TLS:       &bf,
+		UnsafeTLS: &bf,
+	}
+	baseOTLPDefaults := OTLPDefaults{
+		CommonSinkConfig: CommonSinkConfig{
+			Format: func() *string { s := DefaultOTLPFormat; return &s }(),
+		},
+		Timeout:   func() *time.Duration { d := 5 * time.Second; return &d }(),
+		TLS:       &bf,
+		UnsafeTLS: &bf,
+	}
 
 	propagateCommonDefaults(&baseFileDefaults.CommonSinkConfig, baseCommonSinkConfig)
 	propagateCommonDefaults(&baseFluentDefaults.CommonSinkConfig, baseCommonSinkConfig)
 	propagateCommonDefaults(&baseHTTPDefaults.CommonSinkConfig, baseCommonSinkConfig)
+	propagateCommonDefaults(&baseSyslogDefaults.CommonSinkConfig, baseCommonSinkConfig)
+	propagateCommonDefaults(&baseOTLPDefaults.CommonSinkConfig, baseCommonSinkConfig)
 
 	propagateFileDefaults(&c.FileDefaults, baseFileDefaults)
 	propagateFluentDefaults(&c.FluentDefaults, baseFluentDefaults)
 	propagateHTTPDefaults(&c.HTTPDefaults, baseHTTPDefaults)
+	propagateSyslogDefaults(&c.SyslogDefaults, baseSyslogDefaults)
+	propagateOTLPDefaults(&c.OTLPDefaults, baseOTLPDefaults)
 
 	// Normalize the directory.
 	if err := normalizeDir(&c.FileDefaults.Dir); err != nil {
@@ -144,6 +164,28 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	for sinkName, fc := range c.Sinks.SyslogServers {
+		if fc == nil {
+			fc = &SyslogSinkConfig{Channels: SelectChannels()}
+			c.Sinks.SyslogServers[sinkName] = fc
+		}
+		fc.sinkName = sinkName
+		if err := c.validateSyslogSinkConfig(fc); err != nil {
+			fmt.Fprintf(&errBuf, "syslog server %q: %v\n", sinkName, err)
+		}
+	}
+
+	for sinkName, fc := range c.Sinks.OTLPServers {
+		if fc == nil {
+			fc = &OTLPSinkConfig{Channels: SelectChannels()}
+			c.Sinks.OTLPServers[sinkName] = fc
+		}
+		fc.sinkName = sinkName
+		if err := c.validateOTLPSinkConfig(fc); err != nil {
+			fmt.Fprintf(&errBuf, "otlp server %q: %v\n", sinkName, err)
+		}
+	}
+
 	// Defaults for stderr.
 	if c.Sinks.Stderr.Filter == logpb.Severity_UNKNOWN {
 		c.Sinks.Stderr.Filter = logpb.Severity_NONE
@@ -221,6 +263,32 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	for sinkName, fc := range c.Sinks.SyslogServers {
+		if len(fc.Channels.Filters) == 0 {
+			fmt.Fprintf(&errBuf, "syslog server %q: no channel selected\n", sinkName)
+			continue
+		}
+		// Propagate the sink-wide default filter to all channels that don't
+		// have a filter yet.
+		if err := fc.Channels.Validate(fc.Filter); err != nil {
+			fmt.Fprintf(&errBuf, "syslog server %q: %v\n", sinkName, err)
+			continue
+		}
+	}
+
+	for sinkName, fc := range c.Sinks.OTLPServers {
+		if len(fc.Channels.Filters) == 0 {
+			fmt.Fprintf(&errBuf, "otlp server %q: no channel selected\n", sinkName)
+			continue
+		}
+		// Propagate the sink-wide default filter to all channels that don't
+		// have a filter yet.
+		if err := fc.Channels.Validate(fc.Filter); err != nil {
+			fmt.Fprintf(&errBuf, "otlp server %q: %v\n", sinkName, err)
+			continue
+		}
+	}
+
 	// If capture-stray-errors was enabled, then perform some additional
 	// validation on it.
 	if c.CaptureFd2.Enable {
@@ -326,6 +394,22 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	// Elide all the syslog sinks where all channels have
+	// severity set to NONE.
+	for serverName, fc := range c.Sinks.SyslogServers {
+		if fc.Channels.noChannelsSelected() {
+			delete(c.Sinks.SyslogServers, serverName)
+		}
+	}
+
+	// Elide all the OTLP sinks where all channels have
+	// severity set to NONE.
+	for serverName, fc := range c.Sinks.OTLPServers {
+		if fc.Channels.noChannelsSelected() {
+			delete(c.Sinks.OTLPServers, serverName)
+		}
+	}
+
 	return nil
 }
 
@@ -439,6 +523,70 @@ func (c *Config) validateHTTPSinkConfig(hsc *HTTPSinkConfig) error {
 	return c.ValidateCommonSinkConfig(hsc.CommonSinkConfig)
 }
 
+func (c *Config) validateSyslogSinkConfig(sc *SyslogSinkConfig) error {
+	propagateSyslogDefaults(&sc.SyslogDefaults, c.SyslogDefaults)
+	sc.Net = strings.ToLower(strings.TrimSpace(sc.Net))
+	switch sc.Net {
+	case "tcp", "tcp4", "tcp6":
+	case "udp", "udp4", "udp6":
+		if *sc.TLS {
+			return errors.Newf("tls is not supported with protocol %q", sc.Net)
+		}
+	case "unix":
+	case "":
+		sc.Net = "tcp"
+	default:
+		return errors.Newf("unknown protocol: %q", sc.Net)
+	}
+	sc.Address = strings.TrimSpace(sc.Address)
+	if sc.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+	if sc.CACert != nil && *sc.CACert != "" && !*sc.TLS {
+		return errors.New("ca-cert requires tls to be enabled")
+	}
+
+	// Apply the auditable flag if set.
+	if *sc.Auditable {
+		bt := true
+		sc.Criticality = &bt
+	}
+	sc.Auditable = nil
+
+	return c.ValidateCommonSinkConfig(sc.CommonSinkConfig)
+}
+
+func (c *Config) validateOTLPSinkConfig(oc *OTLPSinkConfig) error {
+	propagateOTLPDefaults(&oc.OTLPDefaults, c.OTLPDefaults)
+	oc.Address = strings.TrimSpace(oc.Address)
+	if oc.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+	if *oc.Format != DefaultOTLPFormat {
+		return errors.Newf("unsupported format %q: only %q is supported", *oc.Format, DefaultOTLPFormat)
+	}
+	if oc.CACert != nil && *oc.CACert != "" && !*oc.TLS {
+		return errors.New("ca-cert requires tls to be enabled")
+	}
+	hasClientCert := oc.ClientCert != nil && *oc.ClientCert != ""
+	hasClientKey := oc.ClientKey != nil && *oc.ClientKey != ""
+	if hasClientCert != hasClientKey {
+		return errors.New("client-cert and client-key must be specified together")
+	}
+	if hasClientCert && !*oc.TLS {
+		return errors.New("client-cert requires tls to be enabled")
+	}
+
+	// Apply the auditable flag if set.
+	if *oc.Auditable {
+		bt := true
+		oc.Criticality = &bt
+	}
+	oc.Auditable = nil
+
+	return c.ValidateCommonSinkConfig(oc.CommonSinkConfig)
+}
+
 func normalizeDir(dir **string) error {
 	if *dir == nil {
 		return nil
@@ -473,6 +621,14 @@ func propagateHTTPDefaults(target *HTTPDefaults, source HTTPDefaults) {
 	propagateDefaults(target, source)
 }
 
+func propagateSyslogDefaults(target *SyslogDefaults, source SyslogDefaults) {
+	propagateDefaults(target, source)
+}
+
+func propagateOTLPDefaults(target *OTLPDefaults, source OTLPDefaults) {
+	propagateDefaults(target, source)
+}
+
 // propagateDefaults takes (target *T, source T) where T is a struct
 // and sets zero-valued exported fields in target to the values
 // from source (recursively for struct-valued fields).
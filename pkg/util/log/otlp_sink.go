@@ -0,0 +1,304 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// otlpSink represents an OpenTelemetry collector to which log records
+// are exported over OTLP/gRPC.
+//
+// The sink expects entries in the "json" format: each entry is decoded
+// back so that its fields can be reported as attributes of the log
+// record. Redaction has been applied by the time the entry was
+// formatted, so the attribute values carry the redaction markers (or
+// lack thereof) configured for the sink.
+type otlpSink struct {
+	address string
+	// timeout bounds each export request. Zero means no timeout.
+	timeout time.Duration
+	// tlsConfig is used to establish the connection, if set.
+	tlsConfig *tls.Config
+	// resource describes the process emitting the log records.
+	resource *resourcepb.Resource
+
+	mu struct {
+		syncutil.Mutex
+		conn   *grpc.ClientConn
+		client collectorpb.LogsServiceClient
+	}
+}
+
+func newOTLPSink(address string, timeout time.Duration, tlsConfig *tls.Config) *otlpSink {
+	return &otlpSink{
+		address:   address,
+		timeout:   timeout,
+		tlsConfig: tlsConfig,
+		resource: &resourcepb.Resource{
+			Attributes: []*commonpb.KeyValue{
+				otlpStringAttr("service.name", fileNameConstants.program),
+				otlpStringAttr("host.name", fullHostName),
+				otlpIntAttr("process.pid", int64(fileNameConstants.pid)),
+			},
+		},
+	}
+}
+
+func (l *otlpSink) String() string {
+	return fmt.Sprintf("otlp:%s", l.address)
+}
+
+// active implements the logSink interface.
+func (l *otlpSink) active() bool { return true }
+
+// attachHints implements the logSink interface.
+func (l *otlpSink) attachHints(stacks []byte) []byte {
+	return stacks
+}
+
+// exitCode implements the logSink interface.
+func (l *otlpSink) exitCode() exit.Code {
+	return exit.LoggingNetCollectorUnavailable()
+}
+
+// output implements the logSink interface.
+func (l *otlpSink) output(b []byte, opts sinkOutputOptions) error {
+	records, err := otlpLogRecords(b)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	req := &collectorpb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: l.resource,
+			InstrumentationLibraryLogs: []*logspb.InstrumentationLibraryLogs{{
+				InstrumentationLibrary: &commonpb.InstrumentationLibrary{Name: "cockroachdb"},
+				Logs:                   records,
+			}},
+		}},
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.maybeDialLocked(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+	if _, err := l.mu.client.Export(ctx, req); err != nil {
+		return errors.Wrapf(err, "exporting log records to %s", l.address)
+	}
+	return nil
+}
+
+// maybeDialLocked sets up the connection to the collector, if not
+// done already. The connection is established asynchronously by gRPC,
+// and re-established as needed.
+func (l *otlpSink) maybeDialLocked() error {
+	if l.mu.conn != nil {
+		return nil
+	}
+	creds := grpc.WithInsecure()
+	if l.tlsConfig != nil {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(l.tlsConfig))
+	}
+	conn, err := grpc.Dial(l.address, creds)
+	if err != nil {
+		return errors.Wrapf(err, "dialing %s", l.address)
+	}
+	l.mu.conn = conn
+	l.mu.client = collectorpb.NewLogsServiceClient(conn)
+	return nil
+}
+
+// otlpLogRecords decodes the entries in b, formatted with the "json"
+// format and separated by newlines, into OTLP log records.
+func otlpLogRecords(b []byte) ([]*logspb.LogRecord, error) {
+	var records []*logspb.LogRecord
+	for _, line := range bytes.Split(b, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var e JSONEntry
+		if err := dec.Decode(&e); err != nil {
+			return nil, errors.Wrap(err, "decoding log entry")
+		}
+		if e.Header != 0 {
+			// Sink headers are not logging events.
+			continue
+		}
+		r, err := e.toOTLPLogRecord()
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// toOTLPLogRecord converts a decoded JSON entry into an OTLP log
+// record.
+func (e *JSONEntry) toOTLPLogRecord() (*logspb.LogRecord, error) {
+	ts, err := fromFluent(e.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	sev := Severity(e.SeverityNumeric)
+	r := &logspb.LogRecord{
+		TimeUnixNano:   uint64(ts),
+		SeverityNumber: otlpSeverity(sev),
+		SeverityText:   sev.String(),
+	}
+
+	attrs := []*commonpb.KeyValue{
+		otlpStringAttr("channel", Channel(e.ChannelNumeric).String()),
+	}
+	if e.NodeID != 0 {
+		attrs = append(attrs, otlpIntAttr("node_id", e.NodeID))
+	}
+	if e.ClusterID != "" {
+		attrs = append(attrs, otlpStringAttr("cluster_id", e.ClusterID))
+	}
+	if e.TenantID != 0 {
+		attrs = append(attrs, otlpIntAttr("tenant_id", e.TenantID))
+	}
+	if e.InstanceID != 0 {
+		attrs = append(attrs, otlpIntAttr("instance_id", e.InstanceID))
+	}
+	if e.Version != "" {
+		attrs = append(attrs, otlpStringAttr("version", e.Version))
+	}
+	attrs = append(attrs,
+		otlpIntAttr("goroutine", e.Goroutine),
+		otlpStringAttr("file", e.File),
+		otlpIntAttr("line", e.Line),
+		otlpIntAttr("entry_counter", int64(e.EntryCounter)),
+		&commonpb.KeyValue{
+			Key:   "redactable",
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: e.Redactable == 1}},
+		},
+	)
+	for _, k := range otlpSortedKeys(e.Tags) {
+		attrs = append(attrs, &commonpb.KeyValue{Key: "tag." + k, Value: otlpValue(e.Tags[k])})
+	}
+	for _, k := range otlpSortedKeys(e.Event) {
+		attrs = append(attrs, &commonpb.KeyValue{Key: "event." + k, Value: otlpValue(e.Event[k])})
+	}
+	if e.Stacks != "" {
+		attrs = append(attrs, otlpStringAttr("exception.stacktrace", e.Stacks))
+	}
+	r.Attributes = attrs
+
+	if e.Event != nil {
+		if t, ok := e.Event["EventType"].(string); ok {
+			r.Name = t
+		}
+		body, err := json.Marshal(e.Event)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: string(body)}}
+	} else {
+		r.Body = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: e.Message}}
+	}
+	return r, nil
+}
+
+// otlpSeverity maps the severity of an entry to an OTLP severity
+// number.
+func otlpSeverity(sev Severity) logspb.SeverityNumber {
+	switch sev {
+	case severity.INFO:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case severity.WARNING:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case severity.ERROR:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	case severity.FATAL:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED
+	}
+}
+
+// otlpValue converts a value decoded from JSON into an OTLP value.
+// Objects and arrays are reported as their JSON representation.
+func otlpValue(v interface{}) *commonpb.AnyValue {
+	switch t := v.(type) {
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: t}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: t}}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: i}}
+		}
+		if f, err := t.Float64(); err == nil {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: f}}
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: t.String()}}
+	default:
+		s, err := json.Marshal(t)
+		if err != nil {
+			s = []byte(fmt.Sprint(t))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: string(s)}}
+	}
+}
+
+func otlpStringAttr(key, val string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: val}},
+	}
+}
+
+func otlpIntAttr(key string, val int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: val}},
+	}
+}
+
+func otlpSortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/logtags"
+	"github.com/stretchr/testify/require"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+func TestOTLPLogRecords(t *testing.T) {
+	ctx := context.Background()
+	ctx = logtags.AddTag(ctx, "s", "1")
+
+	header := makeUnstructuredEntry(ctx, severity.INFO, 0, 0, true, "header")
+	header.header = true
+	entries := []logEntry{
+		header,
+		makeUnstructuredEntry(ctx, severity.WARNING, channel.OPS, 0, true, "hello %s", "world"),
+		makeStructuredEntry(ctx, severity.INFO, channel.SESSIONS, 0, &eventpb.RenameDatabase{
+			CommonEventDetails: eventpb.CommonEventDetails{
+				Timestamp: 123,
+				EventType: "rename_database",
+			},
+			DatabaseName:    "hello",
+			NewDatabaseName: "world",
+		}),
+	}
+
+	// Concatenate the entries the way the buffered sink does.
+	var b bytes.Buffer
+	for _, e := range entries {
+		buf := formatJSONFull{}.formatEntry(e)
+		b.Write(buf.Bytes())
+		putBuffer(buf)
+		b.WriteByte('\n')
+	}
+
+	records, err := otlpLogRecords(b.Bytes())
+	require.NoError(t, err)
+	// The header entry is not reported.
+	require.Len(t, records, 2)
+
+	attrs := func(r *logspb.LogRecord) map[string]interface{} {
+		res := make(map[string]interface{})
+		for _, kv := range r.Attributes {
+			switch v := kv.Value.Value.(type) {
+			case *commonpb.AnyValue_StringValue:
+				res[kv.Key] = v.StringValue
+			case *commonpb.AnyValue_IntValue:
+				res[kv.Key] = v.IntValue
+			case *commonpb.AnyValue_BoolValue:
+				res[kv.Key] = v.BoolValue
+			}
+		}
+		return res
+	}
+
+	r := records[0]
+	require.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_WARN, r.SeverityNumber)
+	require.Equal(t, "WARNING", r.SeverityText)
+	require.Equal(t, uint64(entries[1].ts), r.TimeUnixNano)
+	require.Equal(t, "", r.Name)
+	require.Equal(t, "hello ‹world›", r.Body.GetStringValue())
+	a := attrs(r)
+	require.Equal(t, "OPS", a["channel"])
+	require.Equal(t, true, a["redactable"])
+	require.Equal(t, "‹1›", a["tag.s"])
+
+	r = records[1]
+	require.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_INFO, r.SeverityNumber)
+	require.Equal(t, "rename_database", r.Name)
+	require.JSONEq(t,
+		`{"Timestamp":123,"EventType":"rename_database","DatabaseName":"‹hello›","NewDatabaseName":"‹world›"}`,
+		r.Body.GetStringValue())
+	a = attrs(r)
+	require.Equal(t, "SESSIONS", a["channel"])
+	require.Equal(t, int64(123), a["event.Timestamp"])
+	require.Equal(t, "‹hello›", a["event.DatabaseName"])
+}
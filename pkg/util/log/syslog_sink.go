@@ -0,0 +1,197 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// syslogSink represents a syslog server reachable over the network.
+//
+// Messages are expected to be formatted one per line. Over stream
+// transports (TCP, with or without TLS, and unix sockets) they are
+// sent with the non-transparent framing of RFC 6587, i.e. each message
+// is terminated by a newline. Over UDP, each message is sent in its own
+// datagram.
+type syslogSink struct {
+	// The network address of the syslog server.
+	network string
+	addr    string
+	// tlsConfig is used to establish the connection, if set.
+	tlsConfig *tls.Config
+
+	mu struct {
+		syncutil.Mutex
+		// good indicates that the connection can be used.
+		good bool
+		conn net.Conn
+	}
+}
+
+const syslogDialTimeout = 5 * time.Second
+const syslogWriteTimeout = time.Second
+
+func newSyslogSink(network, addr string, tlsConfig *tls.Config) *syslogSink {
+	return &syslogSink{
+		network:   network,
+		addr:      addr,
+		tlsConfig: tlsConfig,
+	}
+}
+
+func (l *syslogSink) String() string {
+	if l.tlsConfig != nil {
+		return fmt.Sprintf("syslog:%s+tls://%s", l.network, l.addr)
+	}
+	return fmt.Sprintf("syslog:%s://%s", l.network, l.addr)
+}
+
+// active implements the logSink interface.
+func (l *syslogSink) active() bool { return true }
+
+// attachHints implements the logSink interface.
+func (l *syslogSink) attachHints(stacks []byte) []byte {
+	return stacks
+}
+
+// exitCode implements the logSink interface.
+func (l *syslogSink) exitCode() exit.Code {
+	return exit.LoggingNetCollectorUnavailable()
+}
+
+// output implements the logSink interface.
+func (l *syslogSink) output(b []byte, opts sinkOutputOptions) error {
+	// The buffered sink, if any, concatenates messages separated by
+	// newlines; the formatter also terminates every message with one.
+	// Split them back up, dropping the empty lines in between.
+	var msgs [][]byte
+	for _, m := range bytes.Split(b, []byte{'\n'}) {
+		if len(m) > 0 {
+			msgs = append(msgs, m)
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	var frames [][]byte
+	if strings.HasPrefix(l.network, "udp") {
+		frames = msgs
+	} else {
+		frames = [][]byte{append(bytes.Join(msgs, []byte{'\n'}), '\n')}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, f := range frames {
+		if err := l.writeFrameLocked(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFrameLocked writes one frame, reconnecting once if the write
+// fails.
+func (l *syslogSink) writeFrameLocked(b []byte) error {
+	// Try to write and reconnect immediately if the first write fails.
+	_ = l.tryWriteLocked(b)
+	if l.mu.good {
+		return nil
+	}
+
+	if err := l.ensureConnLocked(b); err != nil {
+		return err
+	}
+	return l.tryWriteLocked(b)
+}
+
+func (l *syslogSink) closeLocked() {
+	l.mu.good = false
+	if l.mu.conn != nil {
+		if err := l.mu.conn.Close(); err != nil {
+			fmt.Fprintf(OrigStderr, "error closing syslog connection: %v\n", err)
+		}
+		l.mu.conn = nil
+	}
+}
+
+func (l *syslogSink) ensureConnLocked(b []byte) error {
+	if l.mu.good {
+		return nil
+	}
+	l.closeLocked()
+	dialer := net.Dialer{Timeout: syslogDialTimeout}
+	var err error
+	if l.tlsConfig != nil {
+		l.mu.conn, err = tls.DialWithDialer(&dialer, l.network, l.addr, l.tlsConfig)
+	} else {
+		l.mu.conn, err = dialer.Dial(l.network, l.addr)
+	}
+	if err != nil {
+		fmt.Fprintf(OrigStderr, "%s: error dialing syslog server: %v\n%s", l, err, b)
+		return err
+	}
+	fmt.Fprintf(OrigStderr, "%s: connection to syslog server resumed\n", l)
+	l.mu.good = true
+	return nil
+}
+
+func (l *syslogSink) tryWriteLocked(b []byte) error {
+	if !l.mu.good {
+		return errNoConn
+	}
+	if err := l.mu.conn.SetWriteDeadline(timeutil.Now().Add(syslogWriteTimeout)); err != nil {
+		// An error here is suggestive of a bug in the Go runtime.
+		fmt.Fprintf(OrigStderr, "%s: set write deadline error: %v\n%s",
+			l, err, b)
+		l.mu.good = false
+		return err
+	}
+	n, err := l.mu.conn.Write(b)
+	if err != nil || n < len(b) {
+		fmt.Fprintf(OrigStderr, "%s: logging error: %v or short write (%d/%d)\n%s",
+			l, err, n, len(b), b)
+		l.mu.good = false
+	}
+	return err
+}
+
+// makeSinkTLSConfig creates the TLS configuration for a network sink.
+// If no CA certificate is configured, the system's certificate
+// authorities are used to verify the server.
+func makeSinkTLSConfig(caCertPath *string, unsafeTLS bool) (*tls.Config, error) {
+	cfg := &tls.Config{InsecureSkipVerify: unsafeTLS}
+	if caCertPath != nil && *caCertPath != "" {
+		pem, err := os.ReadFile(*caCertPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading CA certificate")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Newf("no certificate found in %s", *caCertPath)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	// The pseudo-fluent server reports newline-terminated frames, which
+	// is also how syslog messages are framed over TCP.
+	serverAddr, cleanup, syslogData := servePseudoFluent(t)
+	defer cleanup()
+
+	t.Logf("addr: %v", serverAddr)
+
+	// Set up a logging configuration with the server we've just set up
+	// as target for the OPS channel.
+	facility := logconfig.SyslogFacility("local0")
+	cfg := logconfig.DefaultConfig()
+	cfg.Sinks.SyslogServers = map[string]*logconfig.SyslogSinkConfig{
+		"ops": {
+			Address:  serverAddr,
+			Channels: logconfig.SelectChannels(channel.OPS),
+			SyslogDefaults: logconfig.SyslogDefaults{
+				Facility: &facility,
+			},
+		},
+	}
+	// Derive a full config using the same directory as the
+	// TestLogScope.
+	require.NoError(t, cfg.Validate(&sc.logDir))
+
+	// Apply the configuration.
+	TestingResetActive()
+	cleanup, err := ApplyConfig(cfg)
+	require.NoError(t, err)
+	defer cleanup()
+
+	// Send a log event on the OPS channel.
+	Ops.Infof(context.Background(), "hello world")
+
+	// Check that the event was indeed sent via the syslog sink.
+	var ev []byte
+	select {
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	case ev = <-syslogData:
+	}
+
+	// Facility local0 (16) and severity informational (6): 16*8+6 = 134.
+	require.Regexp(t,
+		`^<134>1 \S+ \S+ \S+ \d+ OPS \[crdb@32473 .*file="util/log/syslog_sink_test.go" line="\d+" entry_counter="\d+" redactable="1"\] hello world\n$`,
+		string(ev))
+}
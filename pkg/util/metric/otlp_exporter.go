@@ -0,0 +1,363 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	prometheusgo "github.com/prometheus/client_model/go"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errNoOTLPEndpoint = errors.New("external.otlp.metrics.endpoint is not set")
+
+// otlpMaxDataPointsPerRequest bounds the size of a single export request.
+// Collectors commonly limit the size of the messages they accept, so the
+// metrics of a push are split across as many requests as needed.
+const otlpMaxDataPointsPerRequest = 4096
+
+// otlpRetryOptions are the backoff options used to retry export requests
+// which failed with a transient error. Retries stop when the context passed
+// to Push is done.
+var otlpRetryOptions = retry.Options{
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+}
+
+// OTLPExporter converts the metrics contained in registries to OpenTelemetry
+// (OTLP) metrics and pushes them to an OpenTelemetry collector over gRPC.
+// Counters are exported as cumulative monotonic sums, gauges as gauges and
+// histograms as cumulative histograms with explicit bucket bounds. Registry
+// and metric labels become data point attributes.
+//
+//	oe := MakeOTLPExporter()
+//	oe.ScrapeRegistry(nodeRegistry, includeChildMetrics)
+//	oe.ScrapeRegistry(storeOneRegistry, includeChildMetrics)
+//	...
+//	oe.Push(ctx, endpoint, resourceAttrs, startTime)
+//
+// An OTLPExporter is not safe for concurrent use.
+type OTLPExporter struct {
+	// metrics holds the scraped metrics by name, in the order of names.
+	metrics map[string]*metricspb.Metric
+	names   []string
+
+	endpoint string
+	conn     *grpc.ClientConn
+	client   collectorpb.MetricsServiceClient
+}
+
+// MakeOTLPExporter returns an initialized OTLP exporter.
+func MakeOTLPExporter() OTLPExporter {
+	return OTLPExporter{metrics: map[string]*metricspb.Metric{}}
+}
+
+// ScrapeRegistry scrapes the current values of all metrics contained in the
+// registry, to be sent by the next call to Push.
+func (oe *OTLPExporter) ScrapeRegistry(registry *Registry, includeChildMetrics bool) {
+	labels := registry.getLabels()
+	now := uint64(timeutil.Now().UnixNano())
+	registry.Each(func(name string, v interface{}) {
+		prom, ok := v.(PrometheusExportable)
+		if !ok {
+			return
+		}
+		var unit Unit
+		if it, ok := v.(Iterable); ok {
+			unit = it.GetUnit()
+		}
+		m := prom.ToPrometheusMetric()
+		m.Label = append(labels, prom.GetLabels()...)
+		oe.addMetric(prom, unit, m, now)
+
+		promIter, ok := v.(PrometheusIterable)
+		if !ok || !includeChildMetrics {
+			return
+		}
+		promIter.Each(m.Label, func(child *prometheusgo.Metric) {
+			oe.addMetric(prom, unit, child, now)
+		})
+	})
+}
+
+// addMetric adds a data point for the scraped metric m, which is an instance
+// of prom, to the corresponding OTLP metric.
+func (oe *OTLPExporter) addMetric(
+	prom PrometheusExportable, unit Unit, m *prometheusgo.Metric, now uint64,
+) {
+	name := prom.GetName()
+	metric, ok := oe.metrics[name]
+	if !ok {
+		metric = &metricspb.Metric{
+			Name:        name,
+			Description: prom.GetHelp(),
+			Unit:        otlpUnit(unit),
+		}
+		typ := prometheusgo.MetricType_GAUGE
+		if t := prom.GetType(); t != nil {
+			typ = *t
+		}
+		switch typ {
+		case prometheusgo.MetricType_COUNTER:
+			metric.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+			}}
+		case prometheusgo.MetricType_HISTOGRAM:
+			metric.Data = &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			}}
+		default:
+			metric.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}}
+		}
+		oe.metrics[name] = metric
+		oe.names = append(oe.names, name)
+	}
+
+	attrs := otlpAttributes(m.Label)
+	switch data := metric.Data.(type) {
+	case *metricspb.Metric_Sum:
+		data.Sum.DataPoints = append(data.Sum.DataPoints, &metricspb.NumberDataPoint{
+			Attributes:   attrs,
+			TimeUnixNano: now,
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: m.GetCounter().GetValue()},
+		})
+	case *metricspb.Metric_Gauge:
+		value := m.GetGauge().GetValue()
+		if m.Untyped != nil {
+			value = m.GetUntyped().GetValue()
+		}
+		data.Gauge.DataPoints = append(data.Gauge.DataPoints, &metricspb.NumberDataPoint{
+			Attributes:   attrs,
+			TimeUnixNano: now,
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: value},
+		})
+	case *metricspb.Metric_Histogram:
+		data.Histogram.DataPoints = append(data.Histogram.DataPoints,
+			otlpHistogramDataPoint(m.GetHistogram(), attrs, now))
+	}
+}
+
+// otlpHistogramDataPoint converts a prometheus histogram, whose buckets hold
+// cumulative counts, to an OTLP histogram data point, whose buckets hold the
+// counts between consecutive bounds and an additional overflow bucket.
+func otlpHistogramDataPoint(
+	h *prometheusgo.Histogram, attrs []*commonpb.KeyValue, now uint64,
+) *metricspb.HistogramDataPoint {
+	dp := &metricspb.HistogramDataPoint{
+		Attributes:   attrs,
+		TimeUnixNano: now,
+		Count:        h.GetSampleCount(),
+		Sum:          h.GetSampleSum(),
+	}
+	var prev uint64
+	for _, b := range h.GetBucket() {
+		if math.IsInf(b.GetUpperBound(), +1) {
+			break
+		}
+		dp.ExplicitBounds = append(dp.ExplicitBounds, b.GetUpperBound())
+		dp.BucketCounts = append(dp.BucketCounts, b.GetCumulativeCount()-prev)
+		prev = b.GetCumulativeCount()
+	}
+	dp.BucketCounts = append(dp.BucketCounts, dp.Count-prev)
+	return dp
+}
+
+func otlpAttributes(labels []*prometheusgo.LabelPair) []*commonpb.KeyValue {
+	attrs := make([]*commonpb.KeyValue, 0, len(labels))
+	for _, l := range labels {
+		attrs = append(attrs, otlpStringAttribute(l.GetName(), l.GetValue()))
+	}
+	return attrs
+}
+
+func otlpStringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// otlpUnit returns the UCUM unit that OTLP expects for the given unit.
+func otlpUnit(unit Unit) string {
+	switch unit {
+	case Unit_BYTES:
+		return "By"
+	case Unit_CONST, Unit_PERCENT:
+		return "1"
+	case Unit_COUNT:
+		return "{count}"
+	case Unit_NANOSECONDS, Unit_TIMESTAMP_NS:
+		return "ns"
+	case Unit_SECONDS, Unit_TIMESTAMP_SEC:
+		return "s"
+	default:
+		return ""
+	}
+}
+
+// makeRequests assembles the scraped metrics into export requests, each with
+// at most otlpMaxDataPointsPerRequest data points unless a single metric has
+// more. The start time of the cumulative metrics is set to startTime.
+func (oe *OTLPExporter) makeRequests(
+	resource map[string]string, startTime time.Time,
+) []*collectorpb.ExportMetricsServiceRequest {
+	keys := make([]string, 0, len(resource))
+	for k := range resource {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	res := &resourcepb.Resource{}
+	for _, k := range keys {
+		res.Attributes = append(res.Attributes, otlpStringAttribute(k, resource[k]))
+	}
+	start := uint64(startTime.UnixNano())
+
+	var reqs []*collectorpb.ExportMetricsServiceRequest
+	var cur *metricspb.InstrumentationLibraryMetrics
+	var curDataPoints int
+	for _, name := range oe.names {
+		metric := oe.metrics[name]
+		var n int
+		switch data := metric.Data.(type) {
+		case *metricspb.Metric_Sum:
+			n = len(data.Sum.DataPoints)
+			for _, dp := range data.Sum.DataPoints {
+				dp.StartTimeUnixNano = start
+			}
+		case *metricspb.Metric_Gauge:
+			n = len(data.Gauge.DataPoints)
+		case *metricspb.Metric_Histogram:
+			n = len(data.Histogram.DataPoints)
+			for _, dp := range data.Histogram.DataPoints {
+				dp.StartTimeUnixNano = start
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		if cur == nil || (curDataPoints > 0 && curDataPoints+n > otlpMaxDataPointsPerRequest) {
+			cur = &metricspb.InstrumentationLibraryMetrics{
+				InstrumentationLibrary: &commonpb.InstrumentationLibrary{Name: "cockroachdb"},
+			}
+			curDataPoints = 0
+			reqs = append(reqs, &collectorpb.ExportMetricsServiceRequest{
+				ResourceMetrics: []*metricspb.ResourceMetrics{{
+					Resource:                      res,
+					InstrumentationLibraryMetrics: []*metricspb.InstrumentationLibraryMetrics{cur},
+				}},
+			})
+		}
+		cur.Metrics = append(cur.Metrics, metric)
+		curDataPoints += n
+	}
+	return reqs
+}
+
+// Push sends the metrics scraped since the last push to the OpenTelemetry
+// collector at the given gRPC endpoint, identifying them with the given
+// resource attributes. startTime is the time since which the cumulative
+// metrics have been accumulating.
+//
+// Requests that fail with a transient error are retried with backoff until
+// the context is done. Callers should bound the context by the push interval:
+// a collector that can't keep up then delays the next scrape rather than
+// letting pushes queue up, and the metrics that couldn't be sent are dropped
+// in favor of newer values.
+func (oe *OTLPExporter) Push(
+	ctx context.Context, endpoint string, resource map[string]string, startTime time.Time,
+) error {
+	// Regardless of whether the push errors, clear the metrics. Only the latest
+	// values are pushed.
+	defer oe.clearMetrics()
+	if endpoint == "" {
+		return errNoOTLPEndpoint
+	}
+	if err := oe.maybeDial(ctx, endpoint); err != nil {
+		return err
+	}
+	for _, req := range oe.makeRequests(resource, startTime) {
+		var err error
+		for r := retry.StartWithCtx(ctx, otlpRetryOptions); r.Next(); {
+			if _, err = oe.client.Export(ctx, req); err == nil || !isRetryableOTLPError(err) {
+				break
+			}
+		}
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			return errors.Wrapf(err, "exporting metrics to %s", endpoint)
+		}
+	}
+	return nil
+}
+
+// maybeDial connects to the endpoint unless already connected to it.
+func (oe *OTLPExporter) maybeDial(ctx context.Context, endpoint string) error {
+	if oe.conn != nil && oe.endpoint == endpoint {
+		return nil
+	}
+	if err := oe.Close(); err != nil {
+		return err
+	}
+	// Like the OTLP trace exporter, this only supports insecure connections to
+	// the collector.
+	conn, err := grpc.DialContext(ctx, endpoint, grpc.WithInsecure())
+	if err != nil {
+		return errors.Wrapf(err, "connecting to %s", endpoint)
+	}
+	oe.endpoint = endpoint
+	oe.conn = conn
+	oe.client = collectorpb.NewMetricsServiceClient(conn)
+	return nil
+}
+
+// Close closes the connection to the collector, if any.
+func (oe *OTLPExporter) Close() error {
+	if oe.conn == nil {
+		return nil
+	}
+	err := oe.conn.Close()
+	oe.endpoint, oe.conn, oe.client = "", nil, nil
+	return err
+}
+
+// isRetryableOTLPError returns whether the error returned by an export request
+// is transient, following the OTLP specification.
+func isRetryableOTLPError(err error) bool {
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+		codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// clearMetrics clears the scraped metrics for reuse.
+func (oe *OTLPExporter) clearMetrics() {
+	oe.metrics = map[string]*metricspb.Metric{}
+	oe.names = oe.names[:0]
+}
@@ -0,0 +1,171 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func otlpAttributeMap(attrs []*commonpb.KeyValue) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, kv := range attrs {
+		m[kv.Key] = kv.Value.GetStringValue()
+	}
+	return m
+}
+
+func TestOTLPExporter(t *testing.T) {
+	r1, r2 := NewRegistry(), NewRegistry()
+	r2.AddLabel("store", "2")
+
+	g := NewGauge(Metadata{Name: "one.gauge", Unit: Unit_BYTES})
+	g.Update(7)
+	r1.AddMetric(g)
+	c1 := NewCounter(Metadata{Name: "shared.counter", Unit: Unit_COUNT})
+	c1.Inc(3)
+	r1.AddMetric(c1)
+	c2 := NewCounter(Metadata{Name: "shared.counter", Unit: Unit_COUNT})
+	c2.Inc(5)
+	r2.AddMetric(c2)
+	h := NewHistogram(Metadata{Name: "latency", Unit: Unit_NANOSECONDS},
+		TestSampleInterval, 1000, 1)
+	h.RecordValue(10)
+	h.RecordValue(10)
+	h.RecordValue(1000)
+	r2.AddMetric(h)
+
+	oe := MakeOTLPExporter()
+	const includeChildMetrics = false
+	oe.ScrapeRegistry(r1, includeChildMetrics)
+	oe.ScrapeRegistry(r2, includeChildMetrics)
+
+	start := time.Unix(100, 0)
+	reqs := oe.makeRequests(map[string]string{"node_id": "1", "tenant_id": "system"}, start)
+	require.Len(t, reqs, 1)
+	require.Len(t, reqs[0].ResourceMetrics, 1)
+	rm := reqs[0].ResourceMetrics[0]
+	require.Equal(t, map[string]string{"node_id": "1", "tenant_id": "system"},
+		otlpAttributeMap(rm.Resource.Attributes))
+	require.Len(t, rm.InstrumentationLibraryMetrics, 1)
+	metrics := make(map[string]*metricspb.Metric)
+	for _, m := range rm.InstrumentationLibraryMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+	require.Len(t, metrics, 3)
+
+	gauge := metrics["one.gauge"]
+	require.Equal(t, "By", gauge.Unit)
+	require.Len(t, gauge.GetGauge().DataPoints, 1)
+	require.Equal(t, 7.0, gauge.GetGauge().DataPoints[0].GetAsDouble())
+
+	sum := metrics["shared.counter"].GetSum()
+	require.True(t, sum.IsMonotonic)
+	require.Equal(t, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		sum.AggregationTemporality)
+	require.Len(t, sum.DataPoints, 2)
+	require.Equal(t, 3.0, sum.DataPoints[0].GetAsDouble())
+	require.Empty(t, otlpAttributeMap(sum.DataPoints[0].Attributes))
+	require.Equal(t, 5.0, sum.DataPoints[1].GetAsDouble())
+	require.Equal(t, map[string]string{"store": "2"}, otlpAttributeMap(sum.DataPoints[1].Attributes))
+	require.Equal(t, uint64(start.UnixNano()), sum.DataPoints[1].StartTimeUnixNano)
+	require.NotZero(t, sum.DataPoints[1].TimeUnixNano)
+
+	hist := metrics["latency"].GetHistogram()
+	require.Equal(t, "ns", metrics["latency"].Unit)
+	require.Len(t, hist.DataPoints, 1)
+	dp := hist.DataPoints[0]
+	require.Equal(t, uint64(3), dp.Count)
+	require.Len(t, dp.BucketCounts, len(dp.ExplicitBounds)+1)
+	var total uint64
+	for _, c := range dp.BucketCounts {
+		total += c
+	}
+	require.Equal(t, dp.Count, total)
+	require.Zero(t, dp.BucketCounts[len(dp.BucketCounts)-1])
+
+	// Pushing clears the scraped metrics, even if it fails.
+	require.Equal(t, errNoOTLPEndpoint, oe.Push(context.Background(), "", nil, start))
+	require.Empty(t, oe.makeRequests(nil, start))
+}
+
+// fakeCollector is a metrics collector which rejects the first failures
+// requests as unavailable, and records the others.
+type fakeCollector struct {
+	collectorpb.UnimplementedMetricsServiceServer
+
+	mu       sync.Mutex
+	failures int
+	reqs     []*collectorpb.ExportMetricsServiceRequest
+}
+
+func (c *fakeCollector) Export(
+	_ context.Context, req *collectorpb.ExportMetricsServiceRequest,
+) (*collectorpb.ExportMetricsServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		return nil, status.Error(codes.Unavailable, "try again")
+	}
+	c.reqs = append(c.reqs, req)
+	return &collectorpb.ExportMetricsServiceResponse{}, nil
+}
+
+func TestOTLPExporterPush(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	collector := &fakeCollector{failures: 2}
+	collectorpb.RegisterMetricsServiceServer(srv, collector)
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Stop()
+
+	r := NewRegistry()
+	for _, name := range []string{"a", "b", "c"} {
+		g := NewGauge(Metadata{Name: name})
+		g.Update(1)
+		r.AddMetric(g)
+	}
+
+	oe := MakeOTLPExporter()
+	defer func() { require.NoError(t, oe.Close()) }()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// The transient failures are retried.
+	oe.ScrapeRegistry(r, false /* includeChildMetrics */)
+	require.NoError(t, oe.Push(ctx, ln.Addr().String(), nil, time.Time{}))
+	collector.mu.Lock()
+	require.Len(t, collector.reqs, 1)
+	require.Len(t, collector.reqs[0].ResourceMetrics[0].InstrumentationLibraryMetrics[0].Metrics, 3)
+	collector.mu.Unlock()
+
+	// A push that can't complete in time gives up.
+	collector.mu.Lock()
+	collector.failures = 1 << 20
+	collector.mu.Unlock()
+	oe.ScrapeRegistry(r, false /* includeChildMetrics */)
+	shortCtx, shortCancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer shortCancel()
+	require.Error(t, oe.Push(shortCtx, ln.Addr().String(), nil, time.Time{}))
+}